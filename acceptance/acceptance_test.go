@@ -30,6 +30,11 @@ type YamlTest struct {
 	ErrContains string   `yaml:"errContains,omitempty"`
 	ErrLine     int      `yaml:"errLine,omitempty"`
 	MaxSteps    int      `yaml:"maxSteps,omitempty"`
+	ScreenWidth int      `yaml:"screenWidth,omitempty"`
+	Shims       bool     `yaml:"shims,omitempty"`
+	Dialect     string   `yaml:"dialect,omitempty"`
+	Screen      []string `yaml:"screen,omitempty"`
+	Cursor      []int    `yaml:"cursor,omitempty"`
 }
 
 type YamlTestFile struct {
@@ -44,7 +49,12 @@ type AcceptanceTest struct {
 	wantErr     bool
 	errLine     int
 	errContains string
-	maxSteps    int // Custom max steps limit, 0 means use default
+	maxSteps    int      // Custom max steps limit, 0 means use default
+	screenWidth int      // Screen width for wrapping, 0 means unbounded
+	shims       bool     // Accept statements from other 8-bit dialects
+	dialect     string   // c64 or extended, "" for the default extended dialect
+	screen      []string // Expected screen rows from the top, trailing blank rows omitted; runs on the screen model
+	cursor      []int    // Expected final cursor row and column (0-based); runs on the screen model
 }
 
 // loadTestsFromYAML loads all YAML test files from testdata directory
@@ -94,6 +104,11 @@ func loadTestFile(t *testing.T, filePath string) []AcceptanceTest {
 			errLine:     yamlTest.ErrLine,
 			errContains: yamlTest.ErrContains,
 			maxSteps:    yamlTest.MaxSteps,
+			screenWidth: yamlTest.ScreenWidth,
+			shims:       yamlTest.Shims,
+			dialect:     yamlTest.Dialect,
+			screen:      yamlTest.Screen,
+			cursor:      yamlTest.Cursor,
 		}
 		tests = append(tests, test)
 	}
@@ -102,42 +117,80 @@ func loadTestFile(t *testing.T, filePath string) []AcceptanceTest {
 }
 
 // executeBasicProgramWithMaxSteps parses and executes a BASIC program string with custom max steps
-func executeBasicProgramWithMaxSteps(t *testing.T, program string, inputs []string, maxSteps int) ([]string, error) {
+func executeBasicProgramWithMaxSteps(t *testing.T, program string, inputs []string, maxSteps int, screenWidth int, shims bool, dialect string) ([]string, error) {
+	t.Helper()
+
+	// Create test runtime
+	testRuntime := runtime.NewTestRuntime()
+	if len(inputs) > 0 {
+		testRuntime.SetInput(inputs)
+	}
+	if err := executeOn(t, testRuntime, program, maxSteps, screenWidth, shims, dialect); err != nil {
+		return nil, err
+	}
+
+	// Return captured output
+	return testRuntime.GetOutput(), nil
+}
+
+// executeOnScreen runs a BASIC program on the 25x40 screen model and returns the rendered screen
+func executeOnScreen(t *testing.T, program string, inputs []string, maxSteps int, shims bool, dialect string) (*runtime.ScreenRuntime, error) {
+	t.Helper()
+
+	testRuntime := runtime.NewTestRuntime()
+	if len(inputs) > 0 {
+		testRuntime.SetInput(inputs)
+	}
+	screen := runtime.NewScreenRuntime(testRuntime)
+	return screen, executeOn(t, screen, program, maxSteps, runtime.ScreenColumns, shims, dialect)
+}
+
+// screenRows returns the rendered screen rows without the blank rows below the last text
+func screenRows(screen *runtime.ScreenRuntime) []string {
+	lines := screen.Lines()
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// executeOn parses and executes a BASIC program on the given runtime
+func executeOn(t *testing.T, rt runtime.Runtime, program string, maxSteps int, screenWidth int, shims bool, dialect string) error {
 	t.Helper()
 
 	// Parse the program
+	d := parser.DialectExtended
+	if dialect != "" {
+		d = parser.Dialect(dialect)
+	}
 	l := lexer.New(program)
+	l.SetDialect(d)
 	p := parser.New(l)
+	p.SetShims(shims)
+	p.SetDialect(d)
 	ast := p.ParseProgram()
 
 	// Check for parsing errors
 	if p.ParseError() != nil {
-		return nil, p.ParseError()
+		return p.ParseError()
 	}
 	if ast == nil {
-		return nil, fmt.Errorf("parsing returned nil AST")
+		return fmt.Errorf("parsing returned nil AST")
 	}
 
-	// Create test runtime and interpreter
-	testRuntime := runtime.NewTestRuntime()
-	if len(inputs) > 0 {
-		testRuntime.SetInput(inputs)
-	}
-	interp := interpreter.NewInterpreter(testRuntime)
+	// Create interpreter
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetSource(program)
+	interp.SetDialect(d)
 
 	// Set custom max steps if specified
 	if maxSteps > 0 {
 		interp.SetMaxSteps(maxSteps)
 	}
+	interp.SetScreenWidth(screenWidth)
 
 	// Execute the program
-	err := interp.Execute(ast)
-	if err != nil {
-		return nil, err
-	}
-
-	// Return captured output
-	return testRuntime.GetOutput(), nil
+	return interp.Execute(ast)
 }
 
 const DEFAULT_MAX_STEPS = 1000
@@ -152,7 +205,19 @@ func TestAcceptance(t *testing.T) {
 			if tt.maxSteps == 0 {
 				tt.maxSteps = DEFAULT_MAX_STEPS
 			}
-			output, err = executeBasicProgramWithMaxSteps(t, tt.program, tt.inputs, tt.maxSteps)
+			if tt.screen != nil || tt.cursor != nil {
+				screen, err := executeOnScreen(t, tt.program, tt.inputs, tt.maxSteps, tt.shims, tt.dialect)
+				require.NoError(t, err)
+				if tt.screen != nil {
+					assert.Equal(t, tt.screen, screenRows(screen))
+				}
+				if tt.cursor != nil {
+					row, col := screen.CursorPosition()
+					assert.Equal(t, tt.cursor, []int{row, col})
+				}
+				return
+			}
+			output, err = executeBasicProgramWithMaxSteps(t, tt.program, tt.inputs, tt.maxSteps, tt.screenWidth, tt.shims, tt.dialect)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -0,0 +1,359 @@
+// ABOUTME: Stable JSON form of a parsed program for external tools and golden tests, and a loader reading it back
+// ABOUTME: Nodes are objects tagged with their parser type name, then their non-zero fields in lower camel case
+
+package astjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// Version is the format version written to every document; Unmarshal rejects others
+const Version = 1
+
+// document is the top level of the JSON form
+type document struct {
+	Version int    `json:"version"`
+	Lines   []line `json:"lines"`
+}
+
+// line is one program line
+type line struct {
+	Number     int       `json:"number"`
+	Position   *Position `json:"position,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Statements any       `json:"statements"`
+}
+
+// Position is where a line's number starts in the source, both counted from 1
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// nodes lists every statement and expression type by the name written in the "type" field
+var nodes = map[string]reflect.Type{}
+
+func init() {
+	for _, node := range []any{
+		// Statements
+		&parser.ArraySetStatement{}, &parser.ClearScreenStatement{}, &parser.CloseStatement{}, &parser.ClrStatement{},
+		&parser.ConstStatement{}, &parser.DataStatement{}, &parser.DefFnStatement{}, &parser.DimStatement{},
+		&parser.DoStatement{}, &parser.EndStatement{}, &parser.ForStatement{}, &parser.GetFileStatement{},
+		&parser.GetStatement{}, &parser.GosubStatement{}, &parser.GotoStatement{}, &parser.HostStatement{},
+		&parser.IfStatement{}, &parser.InputFileStatement{}, &parser.InputStatement{}, &parser.LetStatement{},
+		&parser.ListStatement{}, &parser.LocateStatement{}, &parser.LoopStatement{}, &parser.NewStatement{},
+		&parser.NextStatement{}, &parser.OnGosubStatement{}, &parser.OnGotoStatement{}, &parser.OpenStatement{},
+		&parser.OptionStatement{}, &parser.PokeStatement{}, &parser.PrintFileStatement{}, &parser.PrintStatement{},
+		&parser.ReadStatement{}, &parser.RemStatement{}, &parser.ReturnStatement{}, &parser.RunStatement{},
+		&parser.StopStatement{}, &parser.SwapStatement{}, &parser.TimerStatement{}, &parser.TraceStatement{},
+		&parser.WendStatement{}, &parser.WhileStatement{},
+		// Expressions
+		&parser.ArrayReference{}, &parser.BinaryOperation{}, &parser.ComparisonExpression{}, &parser.Constant{},
+		&parser.FunctionCall{}, &parser.NumberLiteral{}, &parser.StringLiteral{}, &parser.UnaryOperation{},
+		&parser.VariableReference{},
+	} {
+		t := reflect.TypeOf(node).Elem()
+		nodes[t.Name()] = t
+	}
+}
+
+var valueType = reflect.TypeOf(types.Value{})
+
+// Marshal encodes a program as indented JSON. When src is the text the program was parsed from, each line also
+// records the position of its number.
+func Marshal(program *parser.Program, src string) ([]byte, error) {
+	positions := linePositions(src)
+	doc := document{Version: Version, Lines: []line{}}
+	for _, l := range program.Lines {
+		statements, err := encode(reflect.ValueOf(l.Statements))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.Number, err)
+		}
+		out := line{Number: l.Number, Source: l.Source, Statements: statements}
+		if pos, ok := positions[l.Number]; ok {
+			out.Position = &pos
+		}
+		doc.Lines = append(doc.Lines, out)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a program written by Marshal; positions are not part of the program and are dropped
+func Unmarshal(data []byte) (*parser.Program, error) {
+	var doc struct {
+		Version int               `json:"version"`
+		Lines   []json.RawMessage `json:"lines"`
+	}
+	if err := strictUnmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != Version {
+		return nil, fmt.Errorf("unsupported AST version %d, want %d", doc.Version, Version)
+	}
+	program := &parser.Program{Lines: []*parser.Line{}}
+	for n, raw := range doc.Lines {
+		var l struct {
+			Number     int             `json:"number"`
+			Position   *Position       `json:"position"`
+			Source     string          `json:"source"`
+			Statements json.RawMessage `json:"statements"`
+		}
+		if err := strictUnmarshal(raw, &l); err != nil {
+			return nil, fmt.Errorf("lines[%d]: %w", n, err)
+		}
+		decoded := &parser.Line{Number: l.Number, Source: l.Source}
+		if err := decode(l.Statements, reflect.ValueOf(&decoded.Statements).Elem()); err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.Number, err)
+		}
+		program.Lines = append(program.Lines, decoded)
+	}
+	return program, nil
+}
+
+// linePositions finds where each line number starts in src; a repeated number keeps the later line, as parsing does
+func linePositions(src string) map[int]Position {
+	positions := make(map[int]Position)
+	row, col := 1, 1
+	for _, seg := range lexer.Classify(src) {
+		if seg.Class == lexer.ClassLineNumber {
+			if number, err := strconv.Atoi(seg.Text); err == nil {
+				positions[number] = Position{Line: row, Column: col}
+			}
+		}
+		if newlines := strings.Count(seg.Text, "\n"); newlines > 0 {
+			row += newlines
+			col = len(seg.Text) - strings.LastIndexByte(seg.Text, '\n')
+		} else {
+			col += len(seg.Text)
+		}
+	}
+	return positions
+}
+
+// encode converts a value of the syntax tree to plain JSON values
+func encode(v reflect.Value) (any, error) {
+	switch {
+	case v.Type() == valueType:
+		value := v.Interface().(types.Value)
+		fields := &object{}
+		if value.IsString() {
+			fields.add("string", value.String)
+		} else {
+			fields.add("number", value.Number)
+		}
+		return fields, nil
+	case v.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		node := v.Elem()
+		if node.Kind() != reflect.Pointer || nodes[node.Type().Elem().Name()] != node.Type().Elem() {
+			return nil, fmt.Errorf("cannot encode %s", node.Type())
+		}
+		return encodeFields(node.Elem(), node.Type().Elem().Name())
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeFields(v.Elem(), "")
+	case v.Kind() == reflect.Struct:
+		return encodeFields(v, "")
+	case v.Kind() == reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		items := make([]any, v.Len())
+		for n := range items {
+			item, err := encode(v.Index(n))
+			if err != nil {
+				return nil, err
+			}
+			items[n] = item
+		}
+		return items, nil
+	}
+	return v.Interface(), nil
+}
+
+// encodeFields converts the fields of a struct that do not hold their zero value, after the node type if there is one
+func encodeFields(v reflect.Value, nodeType string) (*object, error) {
+	fields := &object{}
+	if nodeType != "" {
+		fields.add("type", nodeType)
+	}
+	for n := 0; n < v.NumField(); n++ {
+		if v.Field(n).IsZero() {
+			continue
+		}
+		field, err := encode(v.Field(n))
+		if err != nil {
+			return nil, err
+		}
+		fields.add(fieldName(v.Type().Field(n).Name), field)
+	}
+	return fields, nil
+}
+
+// object is a JSON object that keeps its keys in the order they were added
+type object struct {
+	keys   []string
+	values []any
+}
+
+func (o *object) add(key string, value any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// MarshalJSON writes the keys in order, leaving characters such as < unescaped
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for n, key := range o.keys {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := enc.Encode(o.values[n]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldName converts a Go field name to its JSON name: TargetLines becomes targetLines
+func fieldName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// decode fills v, a settable value of the syntax tree, from JSON; null leaves it at its zero value
+func decode(raw json.RawMessage, v reflect.Value) error {
+	if string(bytes.TrimSpace(raw)) == "null" {
+		return nil
+	}
+	switch {
+	case v.Type() == valueType:
+		var value struct {
+			Number *float64 `json:"number"`
+			String *string  `json:"string"`
+		}
+		if err := strictUnmarshal(raw, &value); err != nil {
+			return err
+		}
+		switch {
+		case value.String != nil && value.Number == nil:
+			v.Set(reflect.ValueOf(types.NewStringValue(*value.String)))
+		case value.Number != nil && value.String == nil:
+			v.Set(reflect.ValueOf(types.NewNumberValue(*value.Number)))
+		default:
+			return fmt.Errorf("a value needs exactly one of number and string")
+		}
+		return nil
+	case v.Kind() == reflect.Interface:
+		return decodeNode(raw, v)
+	case v.Kind() == reflect.Pointer:
+		target := reflect.New(v.Type().Elem())
+		if err := decodeFields(raw, target.Elem(), false); err != nil {
+			return err
+		}
+		v.Set(target)
+		return nil
+	case v.Kind() == reflect.Struct:
+		return decodeFields(raw, v, false)
+	case v.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for n, item := range items {
+			if err := decode(item, slice.Index(n)); err != nil {
+				return fmt.Errorf("[%d]: %w", n, err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return json.Unmarshal(raw, v.Addr().Interface())
+}
+
+// decodeNode creates the node named by the "type" field and stores it in v, a Statement or Expression
+func decodeNode(raw json.RawMessage, v reflect.Value) error {
+	var tagged struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &tagged); err != nil {
+		return err
+	}
+	t, ok := nodes[tagged.Type]
+	if !ok {
+		return fmt.Errorf("unknown node type %q", tagged.Type)
+	}
+	node := reflect.New(t)
+	if !node.Type().Implements(v.Type()) {
+		return fmt.Errorf("%s is not a %s", tagged.Type, v.Type().Name())
+	}
+	if err := decodeFields(raw, node.Elem(), true); err != nil {
+		return fmt.Errorf("%s: %w", tagged.Type, err)
+	}
+	v.Set(node)
+	return nil
+}
+
+// decodeFields fills the fields of a struct from a JSON object, rejecting names the struct lacks
+func decodeFields(raw json.RawMessage, v reflect.Value, tagged bool) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	if tagged {
+		delete(fields, "type")
+	}
+	for n := 0; n < v.NumField(); n++ {
+		name := fieldName(v.Type().Field(n).Name)
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		delete(fields, name)
+		if err := decode(field, v.Field(n)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		return fmt.Errorf("unknown field %q", name)
+	}
+	return nil
+}
+
+// strictUnmarshal decodes JSON into a struct, rejecting fields it does not have
+func strictUnmarshal(raw json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
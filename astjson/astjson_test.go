@@ -0,0 +1,131 @@
+package astjson
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/examples"
+	"basic-interpreter/lexer"
+	"basic-interpreter/optimize"
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+)
+
+// TestMarshalGolden compares against a checked-in dump; after a deliberate change to the syntax tree, regenerate it with
+// go run ./cmd/basic -dump-ast testdata/guess_number.bas > astjson/testdata/guess_number.json
+func TestMarshalGolden(t *testing.T) {
+	src, err := os.ReadFile("../testdata/guess_number.bas")
+	require.NoError(t, err)
+	want, err := os.ReadFile("testdata/guess_number.json")
+	require.NoError(t, err)
+
+	got, err := Marshal(parsertest.Parse(t, string(src)), string(src))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"empty program", "", `{"version":1,"lines":[]}`},
+		{
+			"fields in order, zero values left out",
+			"10 IF A<1 THEN 20",
+			`{"version":1,"lines":[{"number":10,"position":{"line":1,"column":1},"source":"IF A<1 THEN 20","statements":[{"type":"IfStatement",` +
+				`"condition":{"type":"ComparisonExpression","left":{"type":"VariableReference","name":"A"},"operator":"<",` +
+				`"right":{"type":"NumberLiteral","value":"1"}},"thenStmts":[{"type":"GotoStatement","targetLine":20}]}]}]}`,
+		},
+		{
+			"positions of indented and repeated lines",
+			"10 END\n\n  20 REM HI\n10 STOP",
+			`{"version":1,"lines":[{"number":10,"position":{"line":4,"column":1},"source":"STOP","statements":[{"type":"StopStatement"}]},` +
+				`{"number":20,"position":{"line":3,"column":3},"source":"REM HI","statements":[{"type":"RemStatement","text":" HI"}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(parsertest.Parse(t, tt.src), tt.src)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestMarshalWithoutSourceLeavesOutPositions(t *testing.T) {
+	got, err := Marshal(parsertest.Parse(t, "10 END"), "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"lines":[{"number":10,"source":"END","statements":[{"type":"EndStatement"}]}]}`, string(got))
+}
+
+func TestMarshalConstants(t *testing.T) {
+	program := parsertest.Parse(t, "10 PRINT 2*3;LEN(\"AB\")+1;\"A\"+\"B\";0*1")
+	optimize.Program(program)
+	data, err := Marshal(program, "")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"string": "AB"`)
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, program, decoded)
+}
+
+// TestRoundTrip loads the dump of every example and test program that parses back into the same tree
+func TestRoundTrip(t *testing.T) {
+	sources := map[string]string{}
+	for _, ex := range examples.List() {
+		sources[ex.Name] = ex.Source
+	}
+	files, err := filepath.Glob("../testdata/*.bas")
+	require.NoError(t, err)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		sources[filepath.Base(file)] = string(content)
+	}
+	require.NotEmpty(t, sources)
+
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			p := parser.New(lexer.New(src))
+			p.SetShims(true)
+			program := p.ParseProgram()
+			if p.ParseError() != nil {
+				t.Skip("uses statements this interpreter lacks")
+			}
+			data, err := Marshal(program, src)
+			require.NoError(t, err)
+			decoded, err := Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, program, decoded)
+		})
+	}
+}
+
+func TestUnmarshalRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not json", `{`, "unexpected EOF"},
+		{"wrong version", `{"version":2,"lines":[]}`, "unsupported AST version 2"},
+		{"unknown node", `{"version":1,"lines":[{"number":10,"statements":[{"type":"Teleport"}]}]}`, `unknown node type "Teleport"`},
+		{"unknown field", `{"version":1,"lines":[{"number":10,"statements":[{"type":"GotoStatement","line":5}]}]}`, `unknown field "line"`},
+		{"expression as statement", `{"version":1,"lines":[{"number":10,"statements":[{"type":"NumberLiteral","value":"1"}]}]}`, "NumberLiteral is not a Statement"},
+		{"ambiguous value", `{"version":1,"lines":[{"number":10,"statements":[{"type":"PrintStatement","expression":{"type":"Constant","value":{"number":1,"string":"A"}}}]}]}`, "exactly one of number and string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
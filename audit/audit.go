@@ -0,0 +1,139 @@
+// ABOUTME: Static security review of a listing: finds statements and functions that reach the host outside the sandbox
+// ABOUTME: Scans tokens without parsing, so listings for other BASICs are reviewed too before they run elsewhere
+
+package audit
+
+import (
+	"strconv"
+	"strings"
+
+	"basic-interpreter/lexer"
+)
+
+// Category groups host operations by what they reach
+type Category string
+
+// Categories of host operations
+const (
+	Files       Category = "files"       // Files, disks and other devices
+	Shell       Category = "shell"       // Commands, machine code and other programs
+	Network     Category = "network"     // Sockets and serial ports
+	Environment Category = "environment" // Environment variables, host memory and the host clock
+)
+
+// Support tells what this interpreter does with an operation
+type Support string
+
+// Support levels
+const (
+	Unsupported Support = "unsupported" // Not part of this BASIC: the program fails to load here
+	Emulated    Support = "emulated"    // Runs against emulated hardware and never reaches the host
+	Host        Support = "host"        // Reaches the host here too
+)
+
+// Operation describes a statement or function that touches the host
+type Operation struct {
+	Category Category
+	Effect   string
+	Support  Support
+}
+
+// operations lists host operations by keyword. Most come from other dialects and do not load here,
+// but they are reported so a listing can be reviewed before it runs on a machine where they work.
+var operations = map[string]Operation{
+	"OPEN":     {Files, "opens a file or device", Host},
+	"CLOSE":    {Files, "closes a file or device", Host},
+	"LOAD":     {Files, "loads a program from disk or tape", Unsupported},
+	"SAVE":     {Files, "writes a program to disk or tape", Unsupported},
+	"VERIFY":   {Files, "reads a program back from disk or tape", Unsupported},
+	"CMD":      {Files, "redirects output to a device", Unsupported},
+	"BLOAD":    {Files, "loads memory from a file", Unsupported},
+	"BSAVE":    {Files, "writes memory to a file", Unsupported},
+	"CHAIN":    {Files, "loads and runs another program", Unsupported},
+	"MERGE":    {Files, "merges a program file into memory", Unsupported},
+	"KILL":     {Files, "deletes a file", Unsupported},
+	"NAME":     {Files, "renames a file", Unsupported},
+	"FILES":    {Files, "lists a directory", Unsupported},
+	"CHDIR":    {Files, "changes the current directory", Unsupported},
+	"MKDIR":    {Files, "creates a directory", Unsupported},
+	"RMDIR":    {Files, "removes a directory", Unsupported},
+	"SYS":      {Shell, "runs machine code at an address", Unsupported},
+	"USR":      {Shell, "calls machine code", Unsupported},
+	"CALL":     {Shell, "calls machine code or a routine", Unsupported},
+	"SHELL":    {Shell, "runs a host command", Unsupported},
+	"SYSTEM":   {Shell, "exits to the host", Unsupported},
+	"EXEC":     {Shell, "runs a command file", Unsupported},
+	"COM":      {Network, "controls a serial port", Unsupported},
+	"ENVIRON":  {Environment, "changes a host environment variable", Unsupported},
+	"ENVIRON$": {Environment, "reads a host environment variable", Unsupported},
+	"WAIT":     {Environment, "waits on a hardware register", Unsupported},
+	"POKE":     {Environment, "writes memory, which on real hardware reconfigures the machine", Emulated},
+	"TIMER":    {Environment, "reads the host's time of day", Host},
+}
+
+// deviceStatements read or write an open file or device when followed by #, as in PRINT#1
+var deviceStatements = map[lexer.TokenType]string{
+	lexer.PRINT: "PRINT#",
+	lexer.INPUT: "INPUT#",
+	lexer.GET:   "GET#",
+}
+
+// deviceIO is the operation of PRINT#, INPUT# and GET#
+var deviceIO = Operation{Files, "reads or writes an open file or device", Host}
+
+// Finding is a host operation used by a program
+type Finding struct {
+	Line int // BASIC line number (0 when the line has none)
+	Name string
+	Operation
+}
+
+// Audit scans a listing and returns its host operations in program order; REM text and strings are skipped
+func Audit(source string) []Finding {
+	var tokens []lexer.Token
+	l := lexer.New(strings.ReplaceAll(source, "\r\n", "\n"))
+	for tok := l.NextToken(); tok.Type != lexer.EOF; tok = l.NextToken() {
+		tokens = append(tokens, tok)
+	}
+
+	// A name that is ever assigned is a variable in dialects without the keyword
+	variables := map[string]bool{}
+	for n := 0; n+1 < len(tokens); n++ {
+		if tokens[n].Type == lexer.IDENT && tokens[n+1].Type == lexer.ASSIGN {
+			variables[strings.ToUpper(tokens[n].Literal)] = true
+		}
+	}
+
+	var findings []Finding
+	line := 0
+	for n := 0; n < len(tokens); n++ {
+		tok := tokens[n]
+		var next lexer.Token
+		if n+1 < len(tokens) {
+			next = tokens[n+1]
+		}
+		switch {
+		case tok.Type == lexer.NEWLINE:
+			line = 0
+			if next.Type == lexer.NUMBER {
+				line, _ = strconv.Atoi(next.Literal)
+				n++
+			}
+		case n == 0 && tok.Type == lexer.NUMBER:
+			line, _ = strconv.Atoi(tok.Literal)
+		case tok.Type == lexer.REM:
+			// Skip the comment up to the end of the line
+			for n+1 < len(tokens) && tokens[n+1].Type != lexer.NEWLINE {
+				n++
+			}
+		case deviceStatements[tok.Type] != "" && next.Literal == "#":
+			findings = append(findings, Finding{Line: line, Name: deviceStatements[tok.Type], Operation: deviceIO})
+		case tok.Type == lexer.IDENT || tok.Type == lexer.POKE || tok.Type == lexer.OPEN || tok.Type == lexer.CLOSE:
+			name := strings.ToUpper(tok.Literal)
+			if op, ok := operations[name]; ok && !variables[name] {
+				findings = append(findings, Finding{Line: line, Name: name, Operation: op})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []Finding
+	}{
+		{
+			name:   "sandboxed program",
+			source: "10 PRINT \"SYS 49152\"\n20 REM OPEN THE DOOR\n30 INPUT A$",
+			want:   nil,
+		},
+		{
+			name:   "c64 disk and machine code",
+			source: "10 OPEN 1,8,2,\"DATA,S,W\": PRINT#1,\"X\": CLOSE 1\n20 SYS 49152: X=USR(3)",
+			want: []Finding{
+				{Line: 10, Name: "OPEN", Operation: operations["OPEN"]},
+				{Line: 10, Name: "PRINT#", Operation: deviceIO},
+				{Line: 10, Name: "CLOSE", Operation: operations["CLOSE"]},
+				{Line: 20, Name: "SYS", Operation: operations["SYS"]},
+				{Line: 20, Name: "USR", Operation: operations["USR"]},
+			},
+		},
+		{
+			name:   "shell and environment",
+			source: "100 SHELL \"DIR\"\n110 P$=ENVIRON$(\"PATH\"): T=TIMER\n120 POKE 53280,0",
+			want: []Finding{
+				{Line: 100, Name: "SHELL", Operation: Operation{Shell, "runs a host command", Unsupported}},
+				{Line: 110, Name: "ENVIRON$", Operation: Operation{Environment, "reads a host environment variable", Unsupported}},
+				{Line: 110, Name: "TIMER", Operation: Operation{Environment, "reads the host's time of day", Host}},
+				{Line: 120, Name: "POKE", Operation: operations["POKE"]},
+			},
+		},
+		{
+			name:   "assigned names are variables",
+			source: "10 NAME=1: FILES=2: PRINT NAME+FILES",
+			want:   nil,
+		},
+		{
+			name:   "lines without numbers",
+			source: "LOAD \"PROG\",8\r\nINPUT#2,A$",
+			want: []Finding{
+				{Line: 0, Name: "LOAD", Operation: operations["LOAD"]},
+				{Line: 0, Name: "INPUT#", Operation: deviceIO},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Audit(tt.source))
+		})
+	}
+}
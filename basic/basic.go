@@ -0,0 +1,511 @@
+// ABOUTME: One-call embedding API: run a BASIC program from a string or file and collect the results
+// ABOUTME: Result holds printed output lines, final variables and run statistics; failures come back as *Error
+
+package basic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/optimize"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+	"basic-interpreter/vm"
+)
+
+// Defaults matching the command-line interpreter
+const (
+	DefaultMaxSteps    = 1000
+	DefaultScreenWidth = 40
+	DefaultZoneWidth   = 10
+)
+
+// Engine selects how a program is executed
+type Engine string
+
+// Engines
+const (
+	EngineTree Engine = "tree" // Walk the AST, the default
+	EngineVM   Engine = "vm"   // Compile to bytecode and run it on the VM, falling back to the tree walker
+)
+
+// Option configures a run
+type Option func(*config)
+
+// config collects the options of one run
+type config struct {
+	runtime        runtime.Runtime
+	inputs         []string
+	maxSteps       int
+	screenWidth    int
+	zoneWidth      int
+	speed          interpreter.SpeedModel
+	encoding       charset.Encoding
+	abbreviations  bool
+	crunch         bool
+	shims          bool
+	dialect        parser.Dialect
+	precision      types.Precision
+	source         string
+	trace          *interpreter.Trace
+	coverage       *interpreter.Coverage
+	hooks          []interpreter.Hooks
+	lineTrace      io.Writer
+	traceText      bool
+	setup          []func(*interpreter.Interpreter) error // Arrays and functions registered before the run
+	engine         Engine
+	optimize       bool
+	ctx            context.Context
+	timeLimit      time.Duration
+	interrupt      <-chan struct{}
+	record         *interpreter.Journal
+	replay         *interpreter.Journal
+	files          runtime.Files
+	hostFunctions  []string // Names of Go functions, which the parser reads as calls
+	hostStatements []string // Names of Go statements, which the parser reads as statements
+}
+
+// newConfig applies options over the defaults
+func newConfig(opts []Option) config {
+	cfg := config{
+		maxSteps:    DefaultMaxSteps,
+		screenWidth: DefaultScreenWidth,
+		zoneWidth:   DefaultZoneWidth,
+		encoding:    charset.Auto,
+		engine:      EngineTree,
+		ctx:         context.Background(),
+		dialect:     parser.DialectExtended,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithRuntime runs the program against rt instead of a capturing test runtime; rt then supplies INPUT,
+// so it cannot be combined with WithInputs
+func WithRuntime(rt runtime.Runtime) Option {
+	return func(c *config) { c.runtime = rt }
+}
+
+// WithInputs supplies the lines INPUT statements read, in order, to the built-in capturing runtime.
+// Run fails with ErrInputsWithRuntime when WithRuntime gives another runtime.
+func WithInputs(inputs ...string) Option {
+	return func(c *config) { c.inputs = inputs }
+}
+
+// WithMaxSteps sets how many statements run before infinite loop protection stops the program (0 disables it)
+func WithMaxSteps(n int) Option {
+	return func(c *config) { c.maxSteps = n }
+}
+
+// WithScreenWidth sets the screen width used for wrapping and TAB (0 disables wrapping)
+func WithScreenWidth(width int) Option {
+	return func(c *config) { c.screenWidth = width }
+}
+
+// WithZoneWidth sets the width of the print zones used by commas in PRINT
+func WithZoneWidth(width int) Option {
+	return func(c *config) { c.zoneWidth = width }
+}
+
+// WithSpeed paces execution like an original machine
+func WithSpeed(model interpreter.SpeedModel) Option {
+	return func(c *config) { c.speed = model }
+}
+
+// WithEncoding sets the character set RunFile decodes the file from
+func WithEncoding(enc charset.Encoding) Option {
+	return func(c *config) { c.encoding = enc }
+}
+
+// WithAbbreviations accepts C64 keyword abbreviations such as ? for PRINT
+func WithAbbreviations() Option {
+	return func(c *config) { c.abbreviations = true }
+}
+
+// WithCrunch splits keywords written without spaces off names, as in FORI=1TO10, in the extended dialect too;
+// the c64 dialect always does
+func WithCrunch() Option {
+	return func(c *config) { c.crunch = true }
+}
+
+// WithShims accepts statements from other 8-bit dialects, such as HOME, CLS and IF ... ELSE
+func WithShims() Option {
+	return func(c *config) { c.shims = true }
+}
+
+// WithDialect selects the dialect: parser.DialectC64 rejects extensions such as WHILE, ELSE and $FF hex literals
+func WithDialect(d parser.Dialect) Option {
+	return func(c *config) { c.dialect = d }
+}
+
+// WithPrecision selects how numbers are stored and printed: types.PrecisionC64 rounds them as the C64's 5-byte
+// format does and prints 9 significant digits
+func WithPrecision(p types.Precision) Option {
+	return func(c *config) { c.precision = p }
+}
+
+// WithSource gives LIST the program text; RunString and RunFile set it themselves
+func WithSource(src string) Option {
+	return func(c *config) { c.source = src }
+}
+
+// WithTrace records an event per executed statement into t; RunString and RunFile also fill in statement text
+func WithTrace(t *interpreter.Trace) Option {
+	return func(c *config) { c.trace = t }
+}
+
+// WithLineTrace runs with TRON on from the first line, writing [10], [20], ... to w one per line;
+// with statements each number is followed by the text of its line
+func WithLineTrace(w io.Writer, statements bool) Option {
+	return func(c *config) {
+		c.lineTrace = w
+		c.traceText = statements
+	}
+}
+
+// WithCoverage counts the statements executed on each line into c
+func WithCoverage(c *interpreter.Coverage) Option {
+	return func(cfg *config) {
+		cfg.coverage = c
+	}
+}
+
+// WithHooks calls h before each statement, on the error that stops the run and on jumps
+func WithHooks(h interpreter.Hooks) Option {
+	return func(c *config) { c.hooks = append(c.hooks, h) }
+}
+
+// WithArray gives the program an array as DIM name(sizes...) would, filled from values in row-major order
+// (the last index varies fastest)
+func WithArray(name string, sizes []int, values ...types.Value) Option {
+	return func(c *config) {
+		c.setup = append(c.setup, func(interp *interpreter.Interpreter) error {
+			return interp.SetArray(name, sizes, values)
+		})
+	}
+}
+
+// WithFunction gives the program a function as DEF name(param) = body would
+func WithFunction(name, param, body string) Option {
+	return func(c *config) {
+		c.setup = append(c.setup, func(interp *interpreter.Interpreter) error {
+			return interp.DefineFunction(name, param, body)
+		})
+	}
+}
+
+// WithGoFunction makes fn callable from the program as name(args...), like a built-in function
+func WithGoFunction(name string, fn interpreter.HostFunction) Option {
+	return func(c *config) {
+		c.hostFunctions = append(c.hostFunctions, name)
+		c.setup = append(c.setup, func(interp *interpreter.Interpreter) error {
+			return interp.RegisterFunction(name, fn)
+		})
+	}
+}
+
+// WithGoStatement makes fn run for the statement name [expr[, expr...]], with the arguments evaluated
+func WithGoStatement(name string, fn interpreter.HostStatementFunc) Option {
+	return func(c *config) {
+		c.hostStatements = append(c.hostStatements, name)
+		c.setup = append(c.setup, func(interp *interpreter.Interpreter) error {
+			return interp.RegisterStatement(name, fn)
+		})
+	}
+}
+
+// WithEngine selects the execution engine. The VM runs programs faster in tight loops; programs it cannot
+// compile, and runs with a trace, coverage, hooks, line trace or speed model, use the tree walker.
+func WithEngine(engine Engine) Option {
+	return func(c *config) { c.engine = engine }
+}
+
+// WithOptimization folds constant expressions, such as 2*3+1 or LEN("ABC"), before the run; the program is
+// rewritten in place and runs with the same output, steps and errors
+func WithOptimization() Option {
+	return func(c *config) { c.optimize = true }
+}
+
+// WithContext stops the run with interpreter.ErrCanceled when ctx is canceled, or interpreter.ErrTimeLimit
+// when its deadline passes
+func WithContext(ctx context.Context) Option {
+	return func(c *config) { c.ctx = ctx }
+}
+
+// WithTimeLimit stops a run still going after limit with interpreter.ErrTimeLimit (0 disables it)
+func WithTimeLimit(limit time.Duration) Option {
+	return func(c *config) { c.timeLimit = limit }
+}
+
+// WithInterrupt stops the run before its next statement when interrupt receives, as Ctrl+C does, with an
+// interpreter.CodeBreak error reading "BREAK IN 10"
+func WithInterrupt(interrupt <-chan struct{}) Option {
+	return func(c *config) { c.interrupt = interrupt }
+}
+
+// WithRecording appends the INPUT lines, GET keys and RND draws of the run to j, for WithReplay
+func WithRecording(j *interpreter.Journal) Option {
+	return func(c *config) { c.record = j }
+}
+
+// WithReplay answers INPUT, GET and RND from a recorded journal instead of the runtime
+func WithReplay(j *interpreter.Journal) Option {
+	return func(c *config) { c.replay = j }
+}
+
+// WithFiles opens files on devices such as drive 8 through files rather than the runtime
+func WithFiles(files runtime.Files) Option {
+	return func(c *config) { c.files = files }
+}
+
+// Result describes a finished run
+type Result struct {
+	Output    []string                         // Printed lines, when the runtime captures output
+	Variables map[string]types.Value           // Final simple variables by normalized name
+	Arrays    map[string]interpreter.ArrayInfo // Final arrays by normalized name
+	Functions []interpreter.FunctionDefinition // DEF FN functions defined at the end of the run
+	Steps     int                              // Statements executed
+	Elapsed   time.Duration                    // Wall-clock run time
+	Engine    Engine                           // Engine that ran the program
+}
+
+// ErrorKind tells the stage an error came from
+type ErrorKind string
+
+// Error kinds
+const (
+	SyntaxError  ErrorKind = "syntax"
+	RuntimeError ErrorKind = "runtime"
+)
+
+// Error is a structured syntax or runtime error
+type Error struct {
+	Kind    ErrorKind
+	Line    int    // Source line for syntax errors, BASIC line number for runtime errors (-1 outside numbered lines)
+	Column  int    // Source column for syntax errors
+	Message string // Message without location, such as "expected THEN" or "?DIVISION BY ZERO ERROR IN 20"
+	Err     error  // Underlying error
+}
+
+func (e *Error) Error() string {
+	if e.Kind == SyntaxError {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList holds the syntax errors of a program in source order; errors.As finds the first as an *Error
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	case 2:
+		return fmt.Sprintf("%s (and 1 more error)", l[0])
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for n, e := range l {
+		errs[n] = e
+	}
+	return errs
+}
+
+// RunString parses and runs a program. It is the one-call entry point for source text; Run takes a program
+// already parsed, so a host can parse once with Parse and run the result many times.
+func RunString(src string, opts ...Option) (Result, error) {
+	program, err := Parse(src, opts...)
+	if err != nil {
+		return Result{}, err
+	}
+	// Copied so the caller's backing array is never written
+	runOpts := append(append([]Option(nil), opts...), WithSource(src))
+	result, err := Run(program, runOpts...)
+	if trace := newConfig(opts).trace; trace != nil {
+		AnnotateTrace(trace, src)
+	}
+	return result, err
+}
+
+// RunFile reads a program file, converting its character set to UTF-8, then parses and runs it
+func RunFile(path string, opts ...Option) (Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	src, err := charset.Decode(data, newConfig(opts).encoding)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return RunString(src, opts...)
+}
+
+// Parse parses a program, reporting every syntax error as an ErrorList
+func Parse(src string, opts ...Option) (*parser.Program, error) {
+	cfg := newConfig(opts)
+	l := lexer.New(src)
+	l.SetAbbreviations(cfg.abbreviations)
+	l.SetCrunch(cfg.crunch)
+	l.SetDialect(cfg.dialect)
+	p := parser.New(l)
+	p.SetShims(cfg.shims)
+	p.SetDialect(cfg.dialect)
+	p.SetHostFunctions(cfg.hostFunctions)
+	p.SetHostStatements(cfg.hostStatements)
+	program := p.ParseProgram()
+	if parseErrors := p.ParseErrors(); len(parseErrors) > 0 {
+		list := make(ErrorList, len(parseErrors))
+		for n, e := range parseErrors {
+			list[n] = &Error{Kind: SyntaxError, Line: e.Position.Line, Column: e.Position.Column, Message: e.Message, Err: e}
+		}
+		return nil, list
+	}
+	return program, nil
+}
+
+// ErrInputsWithRuntime reports WithInputs given together with WithRuntime, whose runtime reads its own input
+var ErrInputsWithRuntime = errors.New("basic: WithInputs cannot be used with WithRuntime; the runtime supplies INPUT")
+
+// Run executes a parsed program; RunString parses and runs source text. The Result is filled in even when
+// the program stops with a runtime error.
+func Run(program *parser.Program, opts ...Option) (Result, error) {
+	cfg := newConfig(opts)
+	rt := cfg.runtime
+	if rt != nil && cfg.inputs != nil {
+		return Result{}, ErrInputsWithRuntime
+	}
+	if rt == nil {
+		test := runtime.NewTestRuntime()
+		test.SetInput(cfg.inputs)
+		rt = test
+	}
+
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetMaxSteps(cfg.maxSteps)
+	interp.SetTimeLimit(cfg.timeLimit)
+	interp.SetInterrupt(cfg.interrupt)
+	interp.SetScreenWidth(cfg.screenWidth)
+	interp.SetDialect(cfg.dialect)
+	interp.SetPrecision(cfg.precision)
+	if cfg.zoneWidth > 0 {
+		interp.SetZoneWidth(cfg.zoneWidth)
+	}
+	interp.SetSpeed(cfg.speed)
+	interp.SetTrace(cfg.trace)
+	interp.SetCoverage(cfg.coverage)
+	interp.SetFiles(cfg.files)
+	for _, h := range cfg.hooks {
+		interp.AddHooks(h)
+	}
+	interp.SetSource(cfg.source)
+	if cfg.lineTrace != nil {
+		interp.SetTraceWriter(cfg.lineTrace)
+		interp.SetTraceStatements(cfg.traceText)
+		_ = interp.SetLineTrace(true)
+	}
+	if cfg.record != nil {
+		interp.RecordJournal(cfg.record)
+	}
+	if cfg.replay != nil {
+		if err := interp.ReplayJournal(cfg.replay); err != nil {
+			return Result{}, err
+		}
+	}
+	for _, setup := range cfg.setup {
+		if err := setup(interp); err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Files the program leaves open are closed when it stops
+	defer interp.CloseFiles()
+
+	if cfg.optimize {
+		optimize.Program(program)
+	}
+	if compiled := cfg.compile(program); compiled != nil {
+		return runVM(cfg.ctx, compiled, interp, rt)
+	}
+
+	start := time.Now()
+	err := interp.ExecuteContext(cfg.ctx, program)
+	result := Result{
+		Output:    capturedLines(rt),
+		Variables: interp.Variables(),
+		Arrays:    interp.Inspect().Arrays,
+		Functions: interp.Functions(),
+		Steps:     interp.Steps(),
+		Elapsed:   time.Since(start),
+		Engine:    EngineTree,
+	}
+	if err != nil {
+		return result, &Error{Kind: RuntimeError, Line: interp.CurrentLine(), Message: err.Error(), Err: err}
+	}
+	return result, nil
+}
+
+// compile compiles program for the VM, or returns nil when the run should use the tree walker
+func (c config) compile(program *parser.Program) *vm.Program {
+	if c.engine != EngineVM || c.trace != nil || c.coverage != nil || len(c.hooks) > 0 || c.lineTrace != nil || c.speed.StatementTime() > 0 {
+		return nil
+	}
+	compiled, err := vm.Compile(program, c.dialect)
+	if err != nil {
+		return nil
+	}
+	return compiled
+}
+
+// runVM runs a compiled program with interp holding its arrays, DATA and functions
+func runVM(ctx context.Context, compiled *vm.Program, interp *interpreter.Interpreter, rt runtime.Runtime) (Result, error) {
+	machine := vm.New(interp)
+	start := time.Now()
+	err := machine.RunContext(ctx, compiled)
+	result := Result{
+		Output:    capturedLines(rt),
+		Variables: machine.Variables(),
+		Arrays:    interp.Inspect().Arrays,
+		Functions: interp.Functions(),
+		Steps:     machine.Steps(),
+		Elapsed:   time.Since(start),
+		Engine:    EngineVM,
+	}
+	if err != nil {
+		return result, &Error{Kind: RuntimeError, Line: machine.CurrentLine(), Message: err.Error(), Err: err}
+	}
+	return result, nil
+}
+
+// capturedLines splits the output a capturing runtime collected into lines
+func capturedLines(rt runtime.Runtime) []string {
+	capture, ok := rt.(interface{ GetOutput() []string })
+	if !ok {
+		return nil
+	}
+	text := strings.Join(capture.GetOutput(), "")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,430 @@
+package basic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestRunString(t *testing.T) {
+	result, err := RunString("10 INPUT N$\n20 FOR I=1 TO 2: PRINT N$;I: NEXT I\n30 A$=\"DONE\"", WithInputs("BOB"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BOB 1", "BOB 2"}, result.Output)
+	assert.Equal(t, types.NewStringValue("DONE"), result.Variables["A$"])
+	assert.Equal(t, types.NewNumberValue(3), result.Variables["I"])
+	assert.Positive(t, result.Steps)
+}
+
+func TestRunString_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		kind    ErrorKind
+		line    int
+		message string
+	}{
+		{"syntax", "10 PRINT \"OK\"\n20 IF X PRINT", SyntaxError, 2, "line 2: "},
+		{"runtime", "10 PRINT \"A\"\n20 PRINT 1/0", RuntimeError, 20, "?DIVISION BY ZERO ERROR IN 20"},
+		{"step limit", "10 GOTO 10", RuntimeError, 10, "?INFINITE LOOP ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RunString(tt.src, WithMaxSteps(50))
+			var e *Error
+			require.True(t, errors.As(err, &e), "got %v", err)
+			assert.Equal(t, tt.kind, e.Kind)
+			assert.Equal(t, tt.line, e.Line)
+			assert.Contains(t, e.Error(), tt.message)
+		})
+	}
+}
+
+func TestRunString_ErrorCode(t *testing.T) {
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		t.Run(string(engine), func(t *testing.T) {
+			_, err := RunString("10 A=1\n20 A=2: PRINT A/0", WithEngine(engine))
+			var e *interpreter.Error
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, interpreter.CodeDivisionByZero, e.Code)
+			assert.Equal(t, 20, e.Line)
+			assert.Equal(t, 1, e.Statement)
+			assert.ErrorIs(t, err, interpreter.CodeDivisionByZero)
+		})
+	}
+}
+
+func TestParse_ReportsEverySyntaxError(t *testing.T) {
+	_, err := Parse("10 GOTO\n20 PRINT \"OK\"\n30 X=\n")
+	var list ErrorList
+	require.True(t, errors.As(err, &list), "got %v", err)
+	require.Len(t, list, 2)
+	assert.Equal(t, []int{1, 3}, []int{list[0].Line, list[1].Line})
+	assert.Equal(t, list[0].Error()+" (and 1 more error)", err.Error())
+
+	var first *Error
+	require.True(t, errors.As(err, &first))
+	assert.Same(t, list[0], first)
+}
+
+func TestRunString_ResultKeptOnRuntimeError(t *testing.T) {
+	result, err := RunString("10 A=5: PRINT \"BEFORE\"\n20 PRINT 1/0")
+	require.Error(t, err)
+	assert.Equal(t, []string{"BEFORE"}, result.Output)
+	assert.Equal(t, types.NewNumberValue(5), result.Variables["A"])
+}
+
+func TestRunString_Options(t *testing.T) {
+	result, err := RunString("10 ?\"A\",\"B\"", WithAbbreviations(), WithZoneWidth(4))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A   B"}, result.Output)
+
+	_, err = RunString("10 FOR I=1 TO 100: NEXT I", WithMaxSteps(10))
+	assert.Error(t, err)
+	_, err = RunString("10 FOR I=1 TO 100: NEXT I", WithMaxSteps(0))
+	assert.NoError(t, err)
+
+	rt := runtime.NewTestRuntime()
+	result, err = RunString("10 PRINT \"MINE\"", WithRuntime(rt), WithSpeed(interpreter.SpeedModel{}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MINE\n"}, rt.GetOutput())
+	assert.Equal(t, []string{"MINE"}, result.Output)
+}
+
+func TestRunString_InputsNeedTheBuiltInRuntime(t *testing.T) {
+	_, err := RunString("10 INPUT A$", WithRuntime(runtime.NewTestRuntime()), WithInputs("X"))
+	assert.Equal(t, ErrInputsWithRuntime, err)
+}
+
+func TestRunString_LeavesCallerOptionsAlone(t *testing.T) {
+	opts := make([]Option, 1, 2)
+	opts[0] = WithMaxSteps(0)
+	_, err := RunString("10 PRINT 1", opts...)
+	require.NoError(t, err)
+	assert.Nil(t, opts[:2][1], "RunString wrote into the spare capacity of the caller's options")
+}
+
+func TestRunString_Dialect(t *testing.T) {
+	src := "10 IF 0 THEN PRINT 1 ELSE PRINT $FF"
+	result, err := RunString(src)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"255"}, result.Output)
+
+	_, err = RunString(src, WithDialect(parser.DialectC64))
+	var list ErrorList
+	require.ErrorAs(t, err, &list)
+	assert.Equal(t, SyntaxError, list[0].Kind)
+
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		src := "10 SCORE=1: SCALE=2: PRINT SCORE"
+		result, err := RunString(src, WithEngine(engine))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, result.Output, "the extended dialect keeps whole names")
+		result, err = RunString(src, WithEngine(engine), WithDialect(parser.DialectC64))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2"}, result.Output, "only two characters count in the c64 dialect")
+		assert.Equal(t, engine, result.Engine)
+	}
+}
+
+func TestRunString_Precision(t *testing.T) {
+	src := "10 A=.1: B=A+.2: PRINT B;STR$(1/3)\n20 FOR I=1 TO 100: S=S+.1: NEXT: PRINT S"
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		result, err := RunString(src, WithEngine(engine))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0.30000000000000004 0.3333333333333333", "9.99999999999998"}, result.Output)
+		result, err = RunString(src, WithEngine(engine), WithPrecision(types.PrecisionC64))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0.3 0.333333333", "10"}, result.Output)
+		assert.Equal(t, engine, result.Engine)
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "latin1.bas")
+	require.NoError(t, os.WriteFile(path, []byte("10 PRINT \"CAF\xc9\"\n"), 0644))
+
+	result, err := RunFile(path, WithEncoding(charset.Latin1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CAFÉ"}, result.Output)
+
+	_, err = RunFile(filepath.Join(t.TempDir(), "missing.bas"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRunString_TraceHasStatementText(t *testing.T) {
+	trace := interpreter.NewTrace(interpreter.DefaultTraceLimits)
+	_, err := RunString("10 A=1: IF A THEN PRINT \"A:B\": B=2\n20 REM DONE: OK", WithTrace(trace))
+	require.NoError(t, err)
+
+	var texts []string
+	for _, event := range trace.Events {
+		texts = append(texts, event.Text)
+	}
+	assert.Equal(t, []string{"A=1", "IF A THEN", `PRINT "A:B"`, "B=2", "REM DONE: OK"}, texts)
+}
+
+func TestRunString_LineTrace(t *testing.T) {
+	var trace strings.Builder
+	result, err := RunString("10 A=1\n20 PRINT A", WithLineTrace(&trace, false))
+	require.NoError(t, err)
+	assert.Equal(t, "[10]\n[20]\n", trace.String())
+	assert.Equal(t, []string{"1"}, result.Output)
+
+	trace.Reset()
+	_, err = RunString("10 A=1\n20 PRINT A", WithLineTrace(&trace, true))
+	require.NoError(t, err)
+	assert.Equal(t, "[10] A=1\n[20] PRINT A\n", trace.String())
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"PRINT 1", []string{"PRINT 1"}},
+		{` A=1:PRINT "X:Y":B=2`, []string{"A=1", `PRINT "X:Y"`, "B=2"}},
+		{"A=1:REM X:Y", []string{"A=1", "REM X:Y"}},
+		{"IFX=1THENA=2:B=3", []string{"IFX=1THEN", "A=2", "B=3"}},
+		{"IF A GOTO 10", []string{"IF A", "GOTO 10"}},
+		{`IF A$="THEN" THEN IF B THEN 10 ELSE PRINT 1: B=2`, []string{`IF A$="THEN" THEN`, "IF B THEN", "10", "ELSE", "PRINT 1", "B=2"}},
+		{"IF A THEN REM X: ELSE", []string{"IF A THEN", "REM X: ELSE"}},
+		{"ELSE1=2", []string{"ELSE1=2"}},
+		{`PRINT "\":":B=2`, []string{`PRINT "\":"`, "B=2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitStatements(tt.body))
+		})
+	}
+}
+
+func TestRunString_ListPrintsSource(t *testing.T) {
+	result, err := RunString("20 LIST\n10 print  \"HI\"")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HI", `10 print  "HI"`, "20 LIST"}, result.Output)
+}
+
+func TestRunString_ArraysAndFunctions(t *testing.T) {
+	result, err := RunString("10 FOR I=0 TO 2: T(I)=S(I)*2: NEXT I\n20 DEF FNH(X)=X/2",
+		WithArray("S", []int{2}, types.NewNumberValue(1), types.NewNumberValue(2), types.NewNumberValue(3)),
+		WithFunction("FNZ", "X", "X+1"))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Value{types.NewNumberValue(2), types.NewNumberValue(4), types.NewNumberValue(6)},
+		result.Arrays["T"].Values[:3])
+	require.Len(t, result.Functions, 2)
+	assert.Equal(t, "FNH", result.Functions[0].Name)
+	assert.Equal(t, "FNZ", result.Functions[1].Name)
+
+	_, err = RunString("10 END", WithArray("S$", []int{1}, types.NewNumberValue(1)))
+	assert.ErrorIs(t, err, types.ErrTypeMismatch)
+}
+
+func TestRunString_Coverage(t *testing.T) {
+	coverage := interpreter.NewCoverage()
+	_, err := RunString("10 GOTO 30\n20 PRINT \"SKIPPED\"\n30 END", WithCoverage(coverage))
+	require.NoError(t, err)
+	assert.Equal(t, []int{20}, coverage.Report("10 GOTO 30\n20 PRINT \"SKIPPED\"\n30 END").Missed)
+}
+
+func TestRunString_StreamsEvents(t *testing.T) {
+	inner := runtime.NewTestRuntime()
+	inner.SetInput([]string{"3"})
+	events := make(chan runtime.Event)
+	done := make(chan error)
+	go func() {
+		_, err := RunString("10 INPUT N: FOR I=1 TO N: PRINT I;: NEXT: PRINT", WithRuntime(runtime.NewChannelRuntime(inner, events)))
+		close(events)
+		done <- err
+	}()
+
+	var got []runtime.Event
+	for e := range events {
+		got = append(got, e)
+	}
+	require.NoError(t, <-done)
+	assert.Equal(t, []runtime.Event{
+		{Kind: runtime.EventInputRequest},
+		{Kind: runtime.EventPrint, Text: "1"},
+		{Kind: runtime.EventPrint, Text: "2"},
+		{Kind: runtime.EventPrint, Text: "3"},
+		{Kind: runtime.EventPrintLine},
+	}, got)
+}
+
+// jumpLimit stops a run after max jumps
+type jumpLimit struct {
+	max, jumps int
+}
+
+func (l *jumpLimit) OnStatement(line, stmtIndex int, stmt parser.Statement) error {
+	if l.jumps > l.max {
+		return errors.New("?TOO MANY JUMPS ERROR")
+	}
+	return nil
+}
+
+func (l *jumpLimit) OnError(line int, err error) {}
+
+func (l *jumpLimit) OnJump(fromLine, toLine int) { l.jumps++ }
+
+func TestRunString_Hooks(t *testing.T) {
+	result, err := RunString("10 A=A+1: GOTO 10", WithEngine(EngineVM), WithHooks(&jumpLimit{max: 3}))
+	require.Error(t, err)
+	assert.Equal(t, "?TOO MANY JUMPS ERROR IN 10", err.Error())
+	assert.Equal(t, EngineTree, result.Engine)
+	assert.Equal(t, 4.0, result.Variables["A"].Number)
+}
+
+func TestRunString_Optimization(t *testing.T) {
+	src := "10 FOR I=1 TO 2*2: S=S+LEN(\"AB\")*I: NEXT I\n20 PRINT S: PRINT 1/0"
+	plain, plainErr := RunString(src)
+	optimized, optimizedErr := RunString(src, WithOptimization())
+	assert.Equal(t, plainErr, optimizedErr)
+	assert.Equal(t, []string{"20"}, optimized.Output)
+	assert.Equal(t, plain.Output, optimized.Output)
+	assert.Equal(t, plain.Variables, optimized.Variables)
+	assert.Equal(t, plain.Steps, optimized.Steps)
+
+	// Folded constants compile for the VM too
+	compiled, err := RunString("10 PRINT 2*3+LEN(\"ABC\")", WithOptimization(), WithEngine(EngineVM))
+	require.NoError(t, err)
+	assert.Equal(t, EngineVM, compiled.Engine)
+	assert.Equal(t, []string{"9"}, compiled.Output)
+}
+
+func TestRunString_Engine(t *testing.T) {
+	src := "10 FOR I=1 TO 3: S=S+I: NEXT I\n20 PRINT S"
+	tree, err := RunString(src)
+	require.NoError(t, err)
+	assert.Equal(t, EngineTree, tree.Engine)
+
+	compiled, err := RunString(src, WithEngine(EngineVM))
+	require.NoError(t, err)
+	assert.Equal(t, EngineVM, compiled.Engine)
+	assert.Equal(t, tree.Output, compiled.Output)
+	assert.Equal(t, tree.Variables, compiled.Variables)
+	assert.Equal(t, tree.Steps, compiled.Steps)
+
+	// WHILE is not compiled, so the program falls back to the tree walker
+	fallback, err := RunString("10 WHILE I<3: I=I+1: WEND\n20 PRINT I", WithEngine(EngineVM))
+	require.NoError(t, err)
+	assert.Equal(t, EngineTree, fallback.Engine)
+	assert.Equal(t, []string{"3"}, fallback.Output)
+
+	_, err = RunString("10 A=1\n20 GOTO 99", WithEngine(EngineVM))
+	var runErr *Error
+	require.ErrorAs(t, err, &runErr)
+	assert.Equal(t, 20, runErr.Line)
+	assert.Equal(t, "?UNDEFINED STATEMENT ERROR IN 20: NO LINE 99", runErr.Message)
+}
+
+func TestRunString_TimeLimitAndContext(t *testing.T) {
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		t.Run(string(engine), func(t *testing.T) {
+			_, err := RunString("10 GOTO 10", WithEngine(engine), WithMaxSteps(0), WithTimeLimit(10*time.Millisecond))
+			var runErr *Error
+			require.ErrorAs(t, err, &runErr)
+			assert.Equal(t, "?TIME LIMIT ERROR", runErr.Message)
+			assert.ErrorIs(t, err, interpreter.ErrTimeLimit)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = RunString("10 GOTO 10", WithEngine(engine), WithMaxSteps(0), WithContext(ctx))
+			assert.ErrorIs(t, err, interpreter.ErrCanceled)
+		})
+	}
+}
+
+func TestRunString_TimeLimitStopsWaitingInput(t *testing.T) {
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		t.Run(string(engine), func(t *testing.T) {
+			in, typist := io.Pipe()
+			defer typist.Close()
+			rt := runtime.NewStandardRuntimeIO(in, io.Discard, io.Discard)
+
+			_, err := RunString("10 INPUT A", WithEngine(engine), WithRuntime(rt), WithTimeLimit(20*time.Millisecond))
+			var runErr *Error
+			require.ErrorAs(t, err, &runErr)
+			assert.Equal(t, "?TIME LIMIT ERROR", runErr.Message)
+		})
+	}
+}
+
+func TestRunString_RecordAndReplay(t *testing.T) {
+	src := "10 INPUT A: PRINT A*RND(1)"
+	journal := &interpreter.Journal{}
+	recorded, err := RunString(src, WithInputs("3"), WithRecording(journal))
+	require.NoError(t, err)
+	require.Len(t, journal.Events, 2)
+
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		replayed, err := RunString(src, WithEngine(engine), WithReplay(journal))
+		require.NoError(t, err)
+		assert.Equal(t, recorded.Output, replayed.Output)
+	}
+}
+
+func TestSession(t *testing.T) {
+	session := New(WithInputs("4"))
+	require.NoError(t, session.Run("10 INPUT A: B=A*A: PRINT B"))
+	assert.Equal(t, []string{"16"}, session.Output())
+	assert.Equal(t, types.NewNumberValue(16), session.Variables()["B"])
+	assert.NoError(t, session.Err())
+
+	// Per-run options apply to that run only; the error is kept with the partial results
+	err := session.Run("10 INPUT A: PRINT A: PRINT 1/0", WithInputs("5"))
+	require.Error(t, err)
+	assert.Equal(t, err, session.Err())
+	assert.Equal(t, []string{"5"}, session.Output())
+	assert.Equal(t, RuntimeError, session.Err().(*Error).Kind)
+
+	require.NoError(t, session.Run("10 INPUT A: PRINT A"))
+	assert.Equal(t, []string{"4"}, session.Output())
+	assert.Equal(t, 2, session.Result().Steps)
+}
+
+func TestRunString_GoFunction(t *testing.T) {
+	double := func(args []types.Value) (types.Value, error) {
+		return types.NewNumberValue(args[0].Number * 2), nil
+	}
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		result, err := RunString("10 FOR I=1 TO 3: S=S+TWICE(I): NEXT: PRINT S", WithEngine(engine), WithGoFunction("TWICE", double))
+		require.NoError(t, err)
+		assert.Equal(t, engine, result.Engine)
+		assert.Equal(t, []string{"12"}, result.Output)
+	}
+
+	// Without registration TWICE(1) is an array element
+	result, err := RunString("10 PRINT TWICE(1)")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0"}, result.Output)
+}
+
+func TestRunString_GoStatement(t *testing.T) {
+	for _, engine := range []Engine{EngineTree, EngineVM} {
+		var total float64
+		add := func(args []types.Value) error {
+			total += args[0].Number
+			return nil
+		}
+		result, err := RunString("10 FOR I=1 TO 3: TALLY I*2: NEXT: PRINT \"DONE\"", WithEngine(engine), WithGoStatement("TALLY", add))
+		require.NoError(t, err)
+		assert.Equal(t, engine, result.Engine)
+		assert.Equal(t, []string{"DONE"}, result.Output)
+		assert.Equal(t, 12.0, total)
+	}
+}
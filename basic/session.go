@@ -0,0 +1,56 @@
+// ABOUTME: Reusable handle over the embedding API: New takes the options once and each Run keeps its results
+// ABOUTME: Output, variables and the error of the last run stay readable until the next one
+
+package basic
+
+import "basic-interpreter/types"
+
+// Session runs programs with the options given to New and keeps what the last run left behind
+type Session struct {
+	opts   []Option
+	result Result
+	err    error
+}
+
+// New creates a session whose runs use opts
+func New(opts ...Option) *Session {
+	return &Session{opts: opts}
+}
+
+// Run parses and runs a program, adding opts to the session's options for this run only.
+// It replaces the results of the previous run and returns the run's error, also kept by Err.
+func (s *Session) Run(src string, opts ...Option) error {
+	s.result, s.err = RunString(src, append(s.options(), opts...)...)
+	return s.err
+}
+
+// RunFile reads, parses and runs a program file like Run
+func (s *Session) RunFile(path string, opts ...Option) error {
+	s.result, s.err = RunFile(path, append(s.options(), opts...)...)
+	return s.err
+}
+
+// options returns a copy of the session's options that a run may append to
+func (s *Session) options() []Option {
+	return append([]Option(nil), s.opts...)
+}
+
+// Output returns the lines the last run printed, when the runtime captures output
+func (s *Session) Output() []string {
+	return s.result.Output
+}
+
+// Variables returns the simple variables the last run left, by normalized name
+func (s *Session) Variables() map[string]types.Value {
+	return s.result.Variables
+}
+
+// Err returns the syntax or runtime error that stopped the last run, nil when it finished
+func (s *Session) Err() error {
+	return s.err
+}
+
+// Result returns everything the last run reported
+func (s *Session) Result() Result {
+	return s.result
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Fills execution trace events with the source text of the statements they record
+// ABOUTME: Program lines are split the way the interpreter numbers statements: at colons outside strings and at the THEN, GOTO and ELSE of an IF
+
+package basic
+
+import (
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+)
+
+// AnnotateTrace sets the Text of each event in t from the program source src
+func AnnotateTrace(t *interpreter.Trace, src string) {
+	texts := statementTexts(src)
+	for n := range t.Events {
+		event := &t.Events[n]
+		if statements := texts[event.Line]; event.Statement < len(statements) {
+			event.Text = statements[event.Statement]
+		}
+	}
+}
+
+// statementTexts returns the source text of each statement by line number; a repeated line number replaces the earlier line
+func statementTexts(src string) map[int][]string {
+	texts := make(map[int][]string)
+	for number, body := range parser.SourceLines(src) {
+		texts[number] = splitStatements(body)
+	}
+	return texts
+}
+
+// splitStatements splits a line body into the statements the interpreter numbers: at colons outside strings,
+// after the THEN of an IF or before its GOTO, and around ELSE, which holds a position of its own. REM runs to
+// the end of the line.
+func splitStatements(body string) []string {
+	upper := strings.ToUpper(body)
+	var statements []string
+	start := 0
+	inString := false
+	branched := false // Whether an IF started a branch, after which ELSE splits
+	for n := 0; n < len(body); n++ {
+		if inString {
+			if body[n] == '\\' && n+1 < len(body) {
+				n++ // An escaped character, such as \" in the extended dialect
+			} else if body[n] == '"' {
+				inString = false
+			}
+			continue
+		}
+		current := strings.TrimSpace(upper[start:n])
+		if strings.HasPrefix(current, "REM") {
+			break
+		}
+		switch {
+		case body[n] == '"':
+			inString = true
+		case body[n] == ':':
+			statements = append(statements, strings.TrimSpace(body[start:n]))
+			start = n + 1
+		case strings.HasPrefix(current, "IF") && keywordAt(upper, n, "THEN"):
+			n += len("THEN")
+			statements = append(statements, strings.TrimSpace(body[start:n]))
+			start = n
+			n--
+			branched = true
+		case strings.HasPrefix(current, "IF") && keywordAt(upper, n, "GOTO"):
+			statements = append(statements, strings.TrimSpace(body[start:n]))
+			start = n
+			branched = true
+		case branched && keywordAt(upper, n, "ELSE"):
+			if current != "" {
+				statements = append(statements, strings.TrimSpace(body[start:n]))
+			}
+			statements = append(statements, body[n:n+len("ELSE")])
+			start = n + len("ELSE")
+			n += len("ELSE") - 1
+		}
+	}
+	return append(statements, strings.TrimSpace(body[start:]))
+}
+
+// keywordAt reports whether the upper-cased body has word at n, not as the end of a longer name
+func keywordAt(upper string, n int, word string) bool {
+	if !strings.HasPrefix(upper[n:], word) {
+		return false
+	}
+	return n == 0 || upper[n-1] < 'A' || upper[n-1] > 'Z'
+}
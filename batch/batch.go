@@ -0,0 +1,191 @@
+// ABOUTME: Batch runs of many program files for compatibility sweeps, with progress reports and resumable checkpoints
+// ABOUTME: Every program runs on the deterministic runtime; results are saved after each program so an interrupted sweep can resume
+
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// Defaults for a Runner's zero fields
+const (
+	DefaultMaxSteps    = 100000
+	DefaultReportEvery = 10000
+)
+
+// Seed is the RND seed every program runs with
+const Seed = 1
+
+// Result is the outcome of one program
+type Result struct {
+	Path  string `json:"path"`
+	Steps int    `json:"steps"`
+	Error string `json:"error,omitempty"` // Read, syntax or runtime error; "" when the program ran to the end
+}
+
+// Progress reports how far a batch has got
+type Progress struct {
+	Program  int // 1-based index of the current program
+	Total    int
+	Path     string
+	Steps    int  // Statements the current program has executed
+	MaxSteps int  // Step limit of each program (0 when unlimited)
+	Done     bool // The current program has finished
+}
+
+// String formats a progress line such as "[3/120] game.bas: 4500 steps (45% of limit)"
+func (p Progress) String() string {
+	s := fmt.Sprintf("[%d/%d] %s: %d steps", p.Program, p.Total, p.Path, p.Steps)
+	if p.MaxSteps > 0 {
+		s += fmt.Sprintf(" (%d%% of limit)", p.Steps*100/p.MaxSteps)
+	}
+	if p.Done {
+		s += ", done"
+	}
+	return s
+}
+
+// Runner runs program files one after another
+type Runner struct {
+	MaxSteps    int            // Step limit of each program (0 uses DefaultMaxSteps)
+	ReportEvery int            // Statements between progress reports while a program runs (0 uses DefaultReportEvery)
+	Progress    func(Progress) // Called as programs start, advance and finish; nil reports nothing
+	Checkpoint  string         // File results are saved to after each program and read back from on start; "" disables it
+}
+
+// checkpoint is the saved state of a batch
+type checkpoint struct {
+	Results []Result `json:"results"`
+}
+
+// Run runs every path in order and returns the results, including those read from the checkpoint.
+// Programs with a saved result are not run again. The error reports a checkpoint that cannot be read or written.
+func (r *Runner) Run(paths []string) ([]Result, error) {
+	saved, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]Result, len(saved))
+	for _, result := range saved {
+		done[result.Path] = result
+	}
+
+	results := make([]Result, 0, len(paths))
+	for n, path := range paths {
+		if result, ok := done[path]; ok {
+			results = append(results, result)
+			continue
+		}
+		result := r.runFile(path, n+1, len(paths))
+		results = append(results, result)
+		saved = append(saved, result)
+		if err := r.save(saved); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// runFile reads, parses and runs one program
+func (r *Runner) runFile(path string, index, total int) Result {
+	maxSteps := r.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	every := r.ReportEvery
+	if every <= 0 {
+		every = DefaultReportEvery
+	}
+	report := func(steps int, finished bool) {
+		if r.Progress != nil {
+			r.Progress(Progress{Program: index, Total: total, Path: path, Steps: steps, MaxSteps: maxSteps, Done: finished})
+		}
+	}
+	report(0, false)
+
+	result := Result{Path: path}
+	program, err := parseFile(path)
+	if err != nil {
+		result.Error = err.Error()
+		report(0, true)
+		return result
+	}
+
+	interp := interpreter.NewInterpreter(runtime.NewDeterministicRuntime(Seed, ""))
+	interp.SetMaxSteps(maxSteps)
+	interp.Start(program)
+	for {
+		status, err := interp.RunFor(every)
+		result.Steps = interp.Steps()
+		if status == interpreter.StatusDone {
+			if err != nil {
+				result.Error = err.Error()
+			}
+			report(result.Steps, true)
+			return result
+		}
+		report(result.Steps, false)
+	}
+}
+
+// parseFile reads a program file in any supported character set and parses it
+func parseFile(path string) (*parser.Program, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src, err := charset.Decode(data, charset.Auto)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		return nil, fmt.Errorf("line %d: %s", e.Position.Line, e.Message)
+	}
+	return program, nil
+}
+
+// load reads the results saved in the checkpoint file; a missing file means a fresh batch
+func (r *Runner) load() ([]Result, error) {
+	if r.Checkpoint == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(r.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", r.Checkpoint, err)
+	}
+	return c.Results, nil
+}
+
+// save writes the results so far to the checkpoint file, replacing it only once fully written
+func (r *Runner) save(results []Result) error {
+	if r.Checkpoint == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(checkpoint{Results: results}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.Checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.Checkpoint)
+}
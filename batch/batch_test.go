@@ -0,0 +1,91 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePrograms writes programs named by file name into a temporary directory and returns their paths in order
+func writePrograms(t *testing.T, programs ...[2]string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for _, program := range programs {
+		path := filepath.Join(dir, program[0])
+		require.NoError(t, os.WriteFile(path, []byte(program[1]), 0644))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestRunner_Results(t *testing.T) {
+	paths := writePrograms(t,
+		[2]string{"ok.bas", "10 FOR I=1 TO 3: NEXT"},
+		[2]string{"syntax.bas", "10 PRINT (1"},
+		[2]string{"runtime.bas", "10 PRINT 1/0"},
+		[2]string{"loop.bas", "10 GOTO 10"},
+	)
+	paths = append(paths, filepath.Join(t.TempDir(), "missing.bas"))
+
+	results, err := (&Runner{MaxSteps: 50}).Run(paths)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.Equal(t, Result{Path: paths[0], Steps: 4}, results[0])
+	assert.Contains(t, results[1].Error, "line 1: expected ')'")
+	assert.Equal(t, "?DIVISION BY ZERO ERROR IN 10", results[2].Error)
+	assert.Contains(t, results[3].Error, "?INFINITE LOOP ERROR")
+	assert.Contains(t, results[4].Error, "no such file")
+}
+
+func TestRunner_Progress(t *testing.T) {
+	paths := writePrograms(t,
+		[2]string{"a.bas", "10 FOR I=1 TO 10: NEXT"},
+		[2]string{"b.bas", "10 PRINT 1/0"},
+	)
+	var reports []Progress
+	runner := &Runner{MaxSteps: 100, ReportEvery: 5, Progress: func(p Progress) { reports = append(reports, p) }}
+	_, err := runner.Run(paths)
+	require.NoError(t, err)
+
+	var steps []int
+	for _, p := range reports[:4] {
+		assert.Equal(t, 1, p.Program)
+		steps = append(steps, p.Steps)
+	}
+	assert.Equal(t, []int{0, 5, 10, 11}, steps)
+	assert.True(t, reports[3].Done)
+	assert.Equal(t, Progress{Program: 2, Total: 2, Path: paths[1], Steps: 1, MaxSteps: 100, Done: true}, reports[len(reports)-1])
+	assert.Equal(t, "[2/2] "+paths[1]+": 1 steps (1% of limit), done", reports[len(reports)-1].String())
+}
+
+func TestRunner_ResumesFromCheckpoint(t *testing.T) {
+	paths := writePrograms(t,
+		[2]string{"a.bas", "10 PRINT 1"},
+		[2]string{"b.bas", "10 PRINT 2"},
+	)
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	first, err := (&Runner{Checkpoint: checkpointPath}).Run(paths[:1])
+	require.NoError(t, err)
+
+	var ran []string
+	runner := &Runner{Checkpoint: checkpointPath, Progress: func(p Progress) {
+		if p.Done {
+			ran = append(ran, p.Path)
+		}
+	}}
+	results, err := runner.Run(paths)
+	require.NoError(t, err)
+	assert.Equal(t, []string{paths[1]}, ran, "programs in the checkpoint are not run again")
+	assert.Equal(t, first[0], results[0])
+	assert.Len(t, results, 2)
+
+	require.NoError(t, os.WriteFile(checkpointPath, []byte("{"), 0644))
+	_, err = runner.Run(paths)
+	assert.ErrorContains(t, err, "checkpoint")
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Headless animation capture: runs a program on the screen model in emulated time and keeps frames of the screen
+// ABOUTME: Time advances only while paced execution waits, so captures are the same on any machine
+
+package capture
+
+import (
+	"slices"
+	"time"
+
+	"basic-interpreter/runtime"
+)
+
+// DefaultFrameJiffies is the interval between frames: 6 jiffies gives 10 frames per second
+const DefaultFrameJiffies = 6
+
+// MaxFrames bounds the frames kept from long runs; later changes to the screen are dropped
+const MaxFrames = 3000
+
+// frame is a screen snapshot and the emulated time it was taken at
+type frame struct {
+	at    time.Duration
+	cells [][]rune
+}
+
+// Recorder is a screen-model runtime whose clock is emulated. Run a program on it with a speed model
+// (interpreter.C64Speed) so execution advances the clock; every frame interval the screen is snapshotted.
+// Input and keys come from the wrapped runtime.
+type Recorder struct {
+	*runtime.ScreenRuntime
+	every     time.Duration
+	start     time.Time
+	now       time.Time
+	next      time.Time // When the next frame is due
+	frames    []frame
+	Truncated bool // MaxFrames was reached and later frames are missing
+}
+
+// NewRecorder creates a recorder snapshotting the screen every frameJiffies jiffies (DefaultFrameJiffies when 0 or less)
+func NewRecorder(inner runtime.Runtime, frameJiffies int) *Recorder {
+	if frameJiffies <= 0 {
+		frameJiffies = DefaultFrameJiffies
+	}
+	every := time.Duration(frameJiffies) * runtime.Jiffy
+	start := runtime.DeterministicEpoch
+	return &Recorder{
+		ScreenRuntime: runtime.NewScreenRuntime(inner),
+		every:         every,
+		start:         start,
+		now:           start,
+		next:          start.Add(every),
+	}
+}
+
+// Now returns the emulated time, used by TI, TI$ and TIMER
+func (r *Recorder) Now() time.Time {
+	return r.now
+}
+
+// Sleep advances the emulated time, taking the frames that fall due on the way
+func (r *Recorder) Sleep(d time.Duration) {
+	end := r.now.Add(d)
+	for !r.next.After(end) {
+		r.now = r.next
+		r.snapshot()
+		r.next = r.next.Add(r.every)
+	}
+	r.now = end
+}
+
+// Frames returns the number of distinct frames captured so far
+func (r *Recorder) Frames() int {
+	return len(r.frames)
+}
+
+// snapshot keeps the screen as a frame unless it is unchanged since the last one
+func (r *Recorder) snapshot() {
+	cells := r.Screen()
+	if n := len(r.frames); n > 0 && slices.EqualFunc(r.frames[n-1].cells, cells, slices.Equal) {
+		return
+	}
+	if len(r.frames) >= MaxFrames {
+		r.Truncated = true
+		return
+	}
+	r.frames = append(r.frames, frame{at: r.now.Sub(r.start), cells: cells})
+}
@@ -0,0 +1,76 @@
+package capture
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+func record(t *testing.T, src string, frameJiffies int) *Recorder {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	rec := NewRecorder(runtime.NewTestRuntime(), frameJiffies)
+	interp := interpreter.NewInterpreter(rec)
+	interp.SetMaxSteps(100000)
+	interp.SetSpeed(interpreter.C64Speed)
+	require.NoError(t, interp.Execute(program))
+	return rec
+}
+
+func TestRecorder_FramesFollowEmulatedTime(t *testing.T) {
+	// Each dot waits 6 jiffies on TI, so every frame shows one more dot
+	rec := record(t, "10 FOR X=1 TO 5: PRINT \".\";: T=TI\n20 IF TI-T<6 THEN 20\n30 NEXT X", 6)
+
+	var buf bytes.Buffer
+	require.NoError(t, rec.WriteGIF(&buf))
+	anim, err := gif.DecodeAll(&buf)
+	require.NoError(t, err)
+
+	assert.Len(t, anim.Image, 5)
+	assert.Equal(t, Width, anim.Image[0].Bounds().Dx())
+	assert.Equal(t, Height, anim.Image[0].Bounds().Dy())
+	for _, delay := range anim.Delay[:4] {
+		assert.InDelta(t, 10, delay, 1, "6 jiffies is a tenth of a second")
+	}
+	assert.Equal(t, 200, anim.Delay[4], "the last frame is held before looping")
+}
+
+func TestRecorder_SkipsUnchangedScreens(t *testing.T) {
+	rec := record(t, "10 PRINT \"STILL\"\n20 T=TI\n30 IF TI-T<60 THEN 30", 1)
+	assert.Equal(t, 1, rec.Frames())
+}
+
+func TestRecorder_SleepAdvancesClock(t *testing.T) {
+	rec := NewRecorder(runtime.NewTestRuntime(), 0)
+	start := rec.Now()
+	rec.Sleep(time.Second)
+	assert.Equal(t, time.Second, rec.Now().Sub(start))
+}
+
+func TestRender_DrawsGlyphs(t *testing.T) {
+	cells := make([][]rune, runtime.ScreenRows)
+	for r := range cells {
+		cells[r] = []rune(string(bytes.Repeat([]byte(" "), runtime.ScreenColumns)))
+	}
+	cells[0][0] = 'i'
+	img := render(cells)
+
+	// The middle column of I is lit on every glyph row; lowercase draws as a capital
+	for y := 0; y < glyphRows; y++ {
+		assert.Equal(t, uint8(1), img.ColorIndexAt(2, y))
+	}
+	assert.Equal(t, uint8(0), img.ColorIndexAt(0, 1))
+	assert.Equal(t, uint8(0), img.ColorIndexAt(2, cellHeight-1), "the bottom row of a cell is spacing")
+}
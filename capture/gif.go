@@ -0,0 +1,69 @@
+// ABOUTME: Encodes captured screen frames as an animated GIF drawn in the C64's blue and light blue
+// ABOUTME: Each of the 40x25 character cells is 6x8 pixels; frame delays follow the emulated time between snapshots
+
+package capture
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"basic-interpreter/runtime"
+)
+
+// Image size in pixels
+const (
+	Width  = runtime.ScreenColumns * cellWidth
+	Height = runtime.ScreenRows * cellHeight
+)
+
+// finalHold is how long the last frame shows before the animation loops
+const finalHold = 2 * time.Second
+
+// palette holds the C64 background and text colours
+var palette = color.Palette{
+	color.RGBA{0x35, 0x28, 0x79, 0xFF}, // Blue
+	color.RGBA{0x6C, 0x5E, 0xB5, 0xFF}, // Light blue
+}
+
+// WriteGIF snapshots the final screen and writes the captured frames to w as a looping animated GIF
+func (r *Recorder) WriteGIF(w io.Writer) error {
+	r.snapshot()
+	anim := &gif.GIF{}
+	for n, f := range r.frames {
+		end := f.at + finalHold
+		if n+1 < len(r.frames) {
+			end = r.frames[n+1].at
+		}
+		// Delays are whole hundredths of a second; rounding each end point keeps the total from drifting
+		delay := centiseconds(end) - centiseconds(f.at)
+		anim.Image = append(anim.Image, render(f.cells))
+		anim.Delay = append(anim.Delay, max(delay, 1))
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// centiseconds rounds a duration to hundredths of a second, the unit of GIF frame delays
+func centiseconds(d time.Duration) int {
+	return int((d + 5*time.Millisecond) / (10 * time.Millisecond))
+}
+
+// render draws a screen snapshot
+func render(cells [][]rune) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, Width, Height), palette)
+	for row, line := range cells {
+		for col, ch := range line {
+			rows := glyph(ch)
+			for y, bits := range rows {
+				for x := 0; x < 5; x++ {
+					if bits&(0x10>>x) != 0 {
+						img.SetColorIndex(col*cellWidth+x, row*cellHeight+y, 1)
+					}
+				}
+			}
+		}
+	}
+	return img
+}
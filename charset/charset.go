@@ -0,0 +1,186 @@
+// ABOUTME: Character set detection and conversion of program files to UTF-8
+// ABOUTME: Handles UTF-8, UTF-16 (with or without BOM), Latin-1 and PETSCII listings downloaded from the web
+
+package charset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names a character set a program file may be stored in
+type Encoding string
+
+// Supported encodings
+const (
+	Auto    Encoding = "auto"
+	UTF8    Encoding = "utf-8"
+	UTF16LE Encoding = "utf-16le"
+	UTF16BE Encoding = "utf-16be"
+	Latin1  Encoding = "latin1"
+	PETSCII Encoding = "petscii"
+)
+
+// Byte order marks recognised by Detect
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// aliases maps accepted --encoding spellings to encodings
+var aliases = map[string]Encoding{
+	"auto":       Auto,
+	"utf-8":      UTF8,
+	"utf8":       UTF8,
+	"utf-16":     UTF16LE,
+	"utf16":      UTF16LE,
+	"utf-16le":   UTF16LE,
+	"utf-16be":   UTF16BE,
+	"latin1":     Latin1,
+	"latin-1":    Latin1,
+	"iso-8859-1": Latin1,
+	"petscii":    PETSCII,
+}
+
+// Parse returns the encoding for a name such as "latin1" or "utf-16"; "" means Auto
+func Parse(name string) (Encoding, error) {
+	if name == "" {
+		return Auto, nil
+	}
+	if enc, ok := aliases[strings.ToLower(name)]; ok {
+		return enc, nil
+	}
+	return "", fmt.Errorf("unknown encoding %q (want auto, utf-8, utf-16, utf-16le, utf-16be, latin1 or petscii)", name)
+}
+
+// Detect guesses the encoding of raw file contents
+func Detect(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return UTF8
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return UTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return UTF16BE
+	}
+	if enc, ok := detectUTF16(data); ok {
+		return enc
+	}
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	if looksLikePETSCII(data) {
+		return PETSCII
+	}
+	return Latin1
+}
+
+// detectUTF16 recognises BOM-less UTF-16 text by the zero high bytes of ASCII characters
+func detectUTF16(data []byte) (Encoding, bool) {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return "", false
+	}
+	evenZeros, oddZeros := 0, 0
+	for n, b := range data {
+		if b != 0 {
+			continue
+		}
+		if n%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	units := len(data) / 2
+	switch {
+	case oddZeros*2 > units && evenZeros == 0:
+		return UTF16LE, true
+	case evenZeros*2 > units && oddZeros == 0:
+		return UTF16BE, true
+	}
+	return "", false
+}
+
+// looksLikePETSCII reports whether non-UTF-8 data reads as a C64 listing, whose lines end in a bare carriage return
+func looksLikePETSCII(data []byte) bool {
+	return bytes.IndexByte(data, '\r') >= 0 && bytes.IndexByte(data, '\n') < 0
+}
+
+// Decode converts raw file contents to UTF-8 text, detecting the encoding when enc is Auto
+func Decode(data []byte, enc Encoding) (string, error) {
+	if enc == Auto {
+		enc = Detect(data)
+	}
+	switch enc {
+	case UTF8:
+		data = bytes.TrimPrefix(data, bomUTF8)
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("invalid UTF-8 text")
+		}
+		return string(data), nil
+	case UTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(data, bomUTF16LE), binary.LittleEndian)
+	case UTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(data, bomUTF16BE), binary.BigEndian)
+	case Latin1:
+		runes := make([]rune, len(data))
+		for n, b := range data {
+			runes[n] = rune(b)
+		}
+		return string(runes), nil
+	case PETSCII:
+		return decodePETSCII(data), nil
+	}
+	return "", fmt.Errorf("unknown encoding %q", enc)
+}
+
+// decodeUTF16 converts UTF-16 code units in the given byte order
+func decodeUTF16(data []byte, order binary.ByteOrder) (string, error) {
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("invalid UTF-16 text: odd number of bytes")
+	}
+	units := make([]uint16, len(data)/2)
+	for n := range units {
+		units[n] = order.Uint16(data[2*n:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// decodePETSCII converts a C64 listing. Letters in either case set become upper-case ASCII,
+// the up arrow becomes the ^ power operator, and graphics characters become U+FFFD.
+func decodePETSCII(data []byte) string {
+	var sb strings.Builder
+	for n, b := range data {
+		switch {
+		case b == '\r':
+			if n+1 < len(data) && data[n+1] == '\n' {
+				continue
+			}
+			sb.WriteByte('\n')
+		case b == '\n' || b == '\t':
+			sb.WriteByte(b)
+		case b == 0x5C:
+			sb.WriteRune('£')
+		case b == 0x5E:
+			sb.WriteByte('^')
+		case b == 0x5F:
+			sb.WriteRune('←')
+		case b >= 0x20 && b <= 0x5D:
+			sb.WriteByte(b)
+		case b >= 0x61 && b <= 0x7A:
+			sb.WriteByte(b - 0x20)
+		case b >= 0xC1 && b <= 0xDA:
+			sb.WriteByte(b - 0x80)
+		case b == 0xA0:
+			sb.WriteByte(' ')
+		default:
+			sb.WriteRune(utf8.RuneError)
+		}
+	}
+	return sb.String()
+}
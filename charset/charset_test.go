@@ -0,0 +1,109 @@
+package charset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// utf16le encodes ASCII text as little-endian UTF-16
+func utf16le(s string) []byte {
+	var out []byte
+	for _, r := range s {
+		out = append(out, byte(r), 0)
+	}
+	return out
+}
+
+// utf16be encodes ASCII text as big-endian UTF-16
+func utf16be(s string) []byte {
+	var out []byte
+	for _, r := range s {
+		out = append(out, 0, byte(r))
+	}
+	return out
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want Encoding
+	}{
+		{name: "plain ASCII", data: []byte("10 PRINT \"HI\"\n"), want: UTF8},
+		{name: "UTF-8 with BOM", data: append([]byte{0xEF, 0xBB, 0xBF}, "10 END\n"...), want: UTF8},
+		{name: "UTF-16LE with BOM", data: append([]byte{0xFF, 0xFE}, utf16le("10 END\n")...), want: UTF16LE},
+		{name: "UTF-16BE with BOM", data: append([]byte{0xFE, 0xFF}, utf16be("10 END\n")...), want: UTF16BE},
+		{name: "UTF-16LE without BOM", data: utf16le("10 END\n"), want: UTF16LE},
+		{name: "UTF-16BE without BOM", data: utf16be("10 END\n"), want: UTF16BE},
+		{name: "Latin-1 pound sign", data: []byte("10 PRINT \"\xA35\"\n"), want: Latin1},
+		{name: "PETSCII carriage returns", data: []byte("10 PRINT \"\xC8I\"\r20 END\r"), want: PETSCII},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.data))
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		enc  Encoding
+		want string
+	}{
+		{name: "UTF-8 BOM stripped", data: append([]byte{0xEF, 0xBB, 0xBF}, "10 END\n"...), enc: Auto, want: "10 END\n"},
+		{name: "UTF-16LE", data: append([]byte{0xFF, 0xFE}, utf16le("10 END\n")...), enc: Auto, want: "10 END\n"},
+		{name: "UTF-16BE", data: utf16be("10 END\n"), enc: Auto, want: "10 END\n"},
+		{name: "Latin-1", data: []byte("10 PRINT \"\xA35 CAF\xC9\"\n"), enc: Auto, want: "10 PRINT \"£5 CAFÉ\"\n"},
+		{name: "PETSCII", data: []byte("10 PRINT \"\xC8\x49\xA0\x5C\"\r20 A=2\x5E3\r"), enc: Auto, want: "10 PRINT \"HI £\"\n20 A=2^3\n"},
+		{name: "PETSCII graphics become replacement characters", data: []byte("10 PRINT \"\xB0\"\r"), enc: PETSCII, want: "10 PRINT \"�\"\n"},
+		{name: "explicit Latin-1 overrides detection", data: []byte("\xC3\xA9"), enc: Latin1, want: "Ã©"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data, tt.enc)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Decode([]byte("\xA3"), UTF8)
+	assert.Error(t, err)
+
+	_, err = Decode([]byte{0x31, 0x00, 0x30}, UTF16LE)
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Encoding
+		wantErr bool
+	}{
+		{name: "", want: Auto},
+		{name: "UTF-8", want: UTF8},
+		{name: "utf-16", want: UTF16LE},
+		{name: "ISO-8859-1", want: Latin1},
+		{name: "petscii", want: PETSCII},
+		{name: "ebcdic", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
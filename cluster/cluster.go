@@ -0,0 +1,133 @@
+// ABOUTME: Host-level orchestration of several interpreter instances running concurrently
+// ABOUTME: Connected nodes exchange text over link files: PRINT# on one arrives at INPUT# on the other
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+// Cluster is a set of named BASIC programs that run concurrently and exchange text over links
+type Cluster struct {
+	nodes    map[string]*Node
+	order    []string
+	links    []*link
+	maxSteps int
+	ran      atomic.Bool
+}
+
+// ErrAlreadyRun is reported for every node when Run is called on a cluster that has run before
+var ErrAlreadyRun = errors.New("cluster has already run")
+
+// Node is one program in a cluster with its own interpreter and runtime
+type Node struct {
+	Name    string
+	program *parser.Program
+	rt      *pipeRuntime
+}
+
+// New creates an empty cluster
+func New() *Cluster {
+	return &Cluster{nodes: make(map[string]*Node), maxSteps: 1000}
+}
+
+// SetMaxSteps sets the step limit applied to every node (0 disables it)
+func (c *Cluster) SetMaxSteps(maxSteps int) {
+	c.maxSteps = maxSteps
+}
+
+// Add parses source and registers it as a node under name; names differing only in case are the same node,
+// as programs open links by name
+func (c *Cluster) Add(name string, source string) (*Node, error) {
+	for existing := range c.nodes {
+		if strings.EqualFold(existing, name) {
+			return nil, fmt.Errorf("node %q already exists", name)
+		}
+	}
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		return nil, fmt.Errorf("node %s: %w", name, e)
+	}
+
+	node := &Node{Name: name, program: program, rt: newPipeRuntime()}
+	c.nodes[name] = node
+	c.order = append(c.order, name)
+	return node, nil
+}
+
+// Connect links node from to node to. The program of from opens the link for writing on LinkDevice with the
+// name of to, as in OPEN 1,2,1,"TO", and PRINT# to it arrives at INPUT# and GET# of a file that to opens for
+// reading with the name of from, as in OPEN 1,2,0,"FROM". The reader reaches the end of the file once from finishes.
+func (c *Cluster) Connect(from, to string) error {
+	src, ok := c.nodes[from]
+	if !ok {
+		return fmt.Errorf("unknown node %q", from)
+	}
+	dst, ok := c.nodes[to]
+	if !ok {
+		return fmt.Errorf("unknown node %q", to)
+	}
+	if _, exists := src.rt.outgoing[strings.ToUpper(to)]; exists {
+		return fmt.Errorf("node %q is already connected to %q", from, to)
+	}
+	l := newLink()
+	src.rt.outgoing[strings.ToUpper(to)] = l
+	dst.rt.incoming[strings.ToUpper(from)] = l
+	c.links = append(c.links, l)
+	return nil
+}
+
+// Run executes all nodes concurrently until each finishes or ctx is cancelled; a cluster runs once, and
+// running it again reports ErrAlreadyRun for every node. It returns each node's error keyed by name; nodes
+// that finished cleanly are absent.
+func (c *Cluster) Run(ctx context.Context) map[string]error {
+	errs := make(map[string]error)
+	if !c.ran.CompareAndSwap(false, true) {
+		for _, name := range c.order {
+			errs[name] = ErrAlreadyRun
+		}
+		return errs
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, l := range c.links {
+		l.ctx = ctx
+	}
+	for _, name := range c.order {
+		node := c.nodes[name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			interp := interpreter.NewInterpreter(node.rt)
+			interp.SetMaxSteps(c.maxSteps)
+			err := interp.ExecuteContext(ctx, node.program)
+			interp.CloseFiles()
+			node.rt.finish()
+			if err != nil {
+				mu.Lock()
+				errs[node.Name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// Output returns everything the node printed, as in runtime.TestRuntime
+func (n *Node) Output() []string {
+	return n.rt.output()
+}
@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/interpreter"
+)
+
+func TestCluster_OneWayLink(t *testing.T) {
+	c := New()
+	_, err := c.Add("sender", "10 OPEN 1,2,1,\"RECEIVER\"\n20 FOR I=1 TO 3: PRINT#1,\"MSG\";I: NEXT I\n30 CLOSE 1")
+	require.NoError(t, err)
+	receiver, err := c.Add("receiver", "10 OPEN 1,2,0,\"SENDER\"\n20 FOR I=1 TO 3: INPUT#1,M$: PRINT \"GOT \"; M$: NEXT I\n30 CLOSE 1")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("sender", "receiver"))
+
+	errs := c.Run(context.Background())
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"GOT MSG 1\n", "GOT MSG 2\n", "GOT MSG 3\n"}, receiver.Output())
+}
+
+func TestCluster_RequestResponse(t *testing.T) {
+	c := New()
+	client, err := c.Add("client", "10 OPEN 1,2,1,\"SERVER\": OPEN 2,2,0,\"SERVER\"\n20 PRINT#1,7\n30 INPUT#2,R\n40 PRINT \"ANSWER\";R")
+	require.NoError(t, err)
+	_, err = c.Add("server", "10 OPEN 1,2,0,\"CLIENT\": OPEN 2,2,1,\"CLIENT\"\n20 INPUT#1,N\n30 PRINT#2,N*N")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("client", "server"))
+	require.NoError(t, c.Connect("server", "client"))
+
+	errs := c.Run(context.Background())
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"ANSWER 49\n"}, client.Output())
+}
+
+func TestCluster_LinkEndsWhenSenderFinishes(t *testing.T) {
+	c := New()
+	_, err := c.Add("a", "10 OPEN 1,2,1,\"B\": PRINT#1,\"ONLY\"")
+	require.NoError(t, err)
+	b, err := c.Add("b", "10 OPEN 1,2,0,\"A\"\n20 INPUT#1,X$\n30 GET#1,Y$\n40 PRINT X$;\"[\";Y$;\"]\";ST")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("a", "b"))
+
+	errs := c.Run(context.Background())
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"ONLY[] 64\n"}, b.Output())
+}
+
+func TestCluster_WritesToFinishedNodeAreDropped(t *testing.T) {
+	c := New()
+	_, err := c.Add("a", "10 OPEN 1,2,1,\"B\"\n20 FOR I=1 TO 200: PRINT#1,I: NEXT I")
+	require.NoError(t, err)
+	_, err = c.Add("b", "10 END")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("a", "b"))
+	c.SetMaxSteps(0)
+
+	assert.Empty(t, c.Run(context.Background()))
+}
+
+func TestCluster_OpenErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		program string
+		want    string
+	}{
+		{"node not connected", "10 OPEN 1,2,1,\"C\"", "?FILE NOT FOUND ERROR IN 10"},
+		{"reading a node that only receives", "10 OPEN 1,2,0,\"B\"", "?FILE NOT FOUND ERROR IN 10"},
+		{"other devices", "10 OPEN 1,8,0,\"B\"", "?DEVICE NOT PRESENT ERROR IN 10"},
+		{"no keyboard", "10 INPUT A", "?OUT OF DATA ERROR IN 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			_, err := c.Add("a", tt.program)
+			require.NoError(t, err)
+			_, err = c.Add("b", "10 END")
+			require.NoError(t, err)
+			require.NoError(t, c.Connect("a", "b"))
+
+			errs := c.Run(context.Background())
+			require.Contains(t, errs, "a")
+			assert.Contains(t, errs["a"].Error(), tt.want)
+		})
+	}
+}
+
+func TestCluster_DeadlockEndsWithContext(t *testing.T) {
+	c := New()
+	_, err := c.Add("a", "10 OPEN 1,2,0,\"B\": INPUT#1,X")
+	require.NoError(t, err)
+	_, err = c.Add("b", "10 OPEN 1,2,0,\"A\": INPUT#1,Y")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("a", "b"))
+	require.NoError(t, c.Connect("b", "a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	errs := c.Run(ctx)
+	assert.Len(t, errs, 2)
+}
+
+func TestCluster_BusyNodeStopsWithContext(t *testing.T) {
+	c := New()
+	c.SetMaxSteps(0)
+	_, err := c.Add("busy", "10 GOTO 10")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	errs := c.Run(ctx)
+	require.Contains(t, errs, "busy")
+	assert.ErrorIs(t, errs["busy"], interpreter.ErrTimeLimit)
+}
+
+func TestCluster_RunsOnce(t *testing.T) {
+	c := New()
+	_, err := c.Add("a", "10 OPEN 1,2,1,\"B\": PRINT#1,\"HI\"")
+	require.NoError(t, err)
+	_, err = c.Add("b", "10 OPEN 1,2,0,\"A\": INPUT#1,X$")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("a", "b"))
+
+	assert.Empty(t, c.Run(context.Background()))
+	errs := c.Run(context.Background())
+	assert.Equal(t, map[string]error{"a": ErrAlreadyRun, "b": ErrAlreadyRun}, errs)
+}
+
+func TestCluster_Errors(t *testing.T) {
+	c := New()
+	_, err := c.Add("a", "10 PRINT")
+	require.NoError(t, err)
+
+	_, err = c.Add("A", "10 END")
+	assert.Error(t, err)
+	_, err = c.Add("bad", "10 PRINT \"OOPS")
+	assert.Error(t, err)
+	assert.Error(t, c.Connect("a", "missing"))
+	_, err = c.Add("b", "10 END")
+	require.NoError(t, err)
+	require.NoError(t, c.Connect("a", "b"))
+	assert.Error(t, c.Connect("a", "b"))
+}
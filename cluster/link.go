@@ -0,0 +1,105 @@
+// ABOUTME: Links between cluster nodes: one node's PRINT# to a link file arrives at another node's INPUT# and GET#
+// ABOUTME: A link is a buffered channel of written text that ends once the sending node finishes
+
+package cluster
+
+import (
+	"context"
+	"io"
+)
+
+// LinkDevice is the device number links open on, the C64's RS-232 port
+const LinkDevice = 2
+
+// linkSize is how many writes a link holds before the sender blocks
+const linkSize = 64
+
+// link carries text from one node to another
+type link struct {
+	ctx    context.Context
+	text   chan string
+	closed chan struct{} // Closed when the sending node finishes
+	gone   chan struct{} // Closed when the receiving node finishes, so writes are dropped
+}
+
+// newLink creates an empty link
+func newLink() *link {
+	return &link{
+		ctx:    context.Background(),
+		text:   make(chan string, linkSize),
+		closed: make(chan struct{}),
+		gone:   make(chan struct{}),
+	}
+}
+
+// linkWriter is the sending end of a link opened for writing
+type linkWriter struct {
+	link *link
+}
+
+func (w *linkWriter) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+// Write sends p to the receiving node, waiting while the link is full
+func (w *linkWriter) Write(p []byte) (int, error) {
+	select {
+	case w.link.text <- string(p):
+	case <-w.link.gone:
+	case <-w.link.ctx.Done():
+		return 0, w.link.ctx.Err()
+	}
+	return len(p), nil
+}
+
+func (w *linkWriter) Close() error {
+	return nil
+}
+
+// linkReader is the receiving end of a link opened for reading
+type linkReader struct {
+	link *link
+	rest string // Received text not read yet
+}
+
+// Read waits for text from the sending node; the end of the file is reached once it has finished
+func (r *linkReader) Read(p []byte) (int, error) {
+	if r.rest == "" {
+		select {
+		case r.rest = <-r.link.text:
+		case <-r.link.closed:
+			// Drain text sent just before the sender finished
+			select {
+			case r.rest = <-r.link.text:
+			default:
+				return 0, io.EOF
+			}
+		case <-r.link.ctx.Done():
+			return 0, r.link.ctx.Err()
+		}
+	}
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+	return n, nil
+}
+
+func (r *linkReader) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func (r *linkReader) Close() error {
+	return nil
+}
+
+// Ready implements runtime.Stream
+func (r *linkReader) Ready() bool {
+	if r.rest != "" || len(r.link.text) > 0 {
+		return true
+	}
+	select {
+	case <-r.link.closed:
+		return true
+	default:
+		return false
+	}
+}
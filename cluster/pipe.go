@@ -0,0 +1,115 @@
+// ABOUTME: Runtime implementation for cluster nodes: printed text is recorded and the links are its devices
+// ABOUTME: OPEN on the link device names a connected node; nodes have no keyboard, so INPUT runs out of data
+
+package cluster
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"basic-interpreter/runtime"
+)
+
+// ErrNoMoreInput is returned by INPUT, as a node has no keyboard; nodes exchange data over links with INPUT#
+var ErrNoMoreInput = errors.New("?OUT OF DATA ERROR")
+
+// pipeRuntime implements runtime.Runtime and runtime.Files for one node
+type pipeRuntime struct {
+	outgoing map[string]*link // Links to the nodes this one sends to, by upper-case node name
+	incoming map[string]*link // Links from the nodes that send to this one, by upper-case node name
+
+	mu      sync.Mutex
+	printed []string
+	rng     *rand.Rand
+}
+
+// newPipeRuntime creates a runtime with no links
+func newPipeRuntime() *pipeRuntime {
+	return &pipeRuntime{
+		outgoing: make(map[string]*link),
+		incoming: make(map[string]*link),
+		rng:      rand.New(rand.NewSource(1)),
+	}
+}
+
+// Print records text
+func (p *pipeRuntime) Print(value string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printed = append(p.printed, value)
+	return nil
+}
+
+// PrintLine records text followed by a newline
+func (p *pipeRuntime) PrintLine(value string) error {
+	return p.Print(value + "\n")
+}
+
+// Input fails: a node has no keyboard
+func (p *pipeRuntime) Input(prompt string) (string, error) {
+	if prompt != "" {
+		if err := p.Print(prompt); err != nil {
+			return "", err
+		}
+	}
+	return "", ErrNoMoreInput
+}
+
+// OpenFile implements runtime.Files: on the link device the file name is a connected node, written to when
+// the mode writes and read from otherwise
+func (p *pipeRuntime) OpenFile(device, channel int, name string, mode runtime.FileMode) (runtime.File, error) {
+	if device != LinkDevice {
+		return nil, runtime.ErrDeviceNotPresent
+	}
+	node := strings.ToUpper(strings.TrimSpace(name))
+	if mode == runtime.FileRead {
+		if l, ok := p.incoming[node]; ok {
+			return &linkReader{link: l}, nil
+		}
+	} else if l, ok := p.outgoing[node]; ok {
+		return &linkWriter{link: l}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Clear is a no-op
+func (p *pipeRuntime) Clear() error {
+	return nil
+}
+
+// Random returns deterministic pseudo-random numbers
+func (p *pipeRuntime) Random() float64 {
+	return p.rng.Float64()
+}
+
+// Now returns the wall-clock time
+func (p *pipeRuntime) Now() time.Time {
+	return time.Now()
+}
+
+// GetKey reports that no key is pressed; nodes have no keyboard
+func (p *pipeRuntime) GetKey() (string, error) {
+	return "", nil
+}
+
+// output returns a copy of everything printed
+func (p *pipeRuntime) output() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.printed...)
+}
+
+// finish ends the links of a node whose program has finished: nodes reading from it reach the end of the
+// file, and nodes writing to it no longer wait
+func (p *pipeRuntime) finish() {
+	for _, l := range p.outgoing {
+		close(l.closed)
+	}
+	for _, l := range p.incoming {
+		close(l.gone)
+	}
+}
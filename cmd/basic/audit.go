@@ -0,0 +1,41 @@
+// ABOUTME: The `audit` subcommand listing the statements and functions of a program that would touch the host
+// ABOUTME: Usage: basic audit FILE.bas — for reviewing third-party listings before running them outside the sandbox
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/audit"
+	"basic-interpreter/charset"
+)
+
+// runAuditCommand scans one program file and prints its host operations
+func runAuditCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic audit FILE.bas")
+		return 1
+	}
+
+	content, err := readBasicFile(args[0], charset.Auto)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", args[0], err)
+		return 1
+	}
+	printAudit(os.Stdout, audit.Audit(content))
+	return 0
+}
+
+// printAudit writes one aligned row per finding: line, operation, category, what this interpreter does with it and its effect
+func printAudit(w io.Writer, findings []audit.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "No host-touching statements or functions found.")
+		return
+	}
+	fmt.Fprintf(w, "%-6s %-9s %-12s %-12s %s\n", "LINE", "NAME", "CATEGORY", "HERE", "EFFECT")
+	for _, f := range findings {
+		fmt.Fprintf(w, "%-6d %-9s %-12s %-12s %s\n", f.Line, f.Name, f.Category, f.Support, f.Effect)
+	}
+}
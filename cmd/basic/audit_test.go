@@ -0,0 +1,40 @@
+// ABOUTME: Tests for the audit subcommand
+// ABOUTME: Verifies the findings table, the empty report and argument validation
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"basic-interpreter/audit"
+)
+
+func TestPrintAudit(t *testing.T) {
+	var out bytes.Buffer
+	printAudit(&out, audit.Audit("10 OPEN 1,8,2,\"F\"\n20 T=TIMER"))
+
+	want := "LINE   NAME      CATEGORY     HERE         EFFECT\n" +
+		"10     OPEN      files        host         opens a file or device\n" +
+		"20     TIMER     environment  host         reads the host's time of day\n"
+	if out.String() != want {
+		t.Errorf("printAudit output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrintAuditWithoutFindings(t *testing.T) {
+	var out bytes.Buffer
+	printAudit(&out, nil)
+
+	if want := "No host-touching statements or functions found.\n"; out.String() != want {
+		t.Errorf("printAudit output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunAuditCommandValidation(t *testing.T) {
+	for _, args := range [][]string{{}, {"a.bas", "b.bas"}, {"does-not-exist.bas"}} {
+		if code := runAuditCommand(args); code != 1 {
+			t.Errorf("runAuditCommand(%v) = %d, want 1", args, code)
+		}
+	}
+}
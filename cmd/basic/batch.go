@@ -0,0 +1,58 @@
+// ABOUTME: The `batch` subcommand running many program files and summarizing which ran to the end
+// ABOUTME: Usage: basic batch [-progress] [-checkpoint FILE] [-max-steps N] FILE.bas...
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/batch"
+)
+
+// runBatchCommand runs every program file given and prints one result line each
+func runBatchCommand(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	maxSteps := fs.Int("max-steps", batch.DefaultMaxSteps, "Maximum number of execution steps per program")
+	progress := fs.Bool("progress", false, "Report progress (program N of M, share of the step limit used) to stderr")
+	checkpointFlag := fs.String("checkpoint", "", "Save results to this file after each program and skip programs it already lists")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: basic batch [-progress] [-checkpoint FILE] [-max-steps N] FILE.bas...")
+		return 1
+	}
+
+	runner := &batch.Runner{MaxSteps: *maxSteps, Checkpoint: *checkpointFlag}
+	if *progress {
+		runner.Progress = func(p batch.Progress) { fmt.Fprintln(os.Stderr, p) }
+	}
+	results, err := runner.Run(fs.Args())
+	failed := printBatchResults(os.Stdout, results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printBatchResults writes a line per program and a summary, and returns how many programs failed
+func printBatchResults(w io.Writer, results []batch.Result) int {
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %s\n", result.Path, result.Error)
+			continue
+		}
+		fmt.Fprintf(w, "ok   %s (%d steps)\n", result.Path, result.Steps)
+	}
+	fmt.Fprintf(w, "%d passed, %d failed\n", len(results)-failed, failed)
+	return failed
+}
@@ -0,0 +1,27 @@
+// ABOUTME: Tests for the batch subcommand
+// ABOUTME: Verifies the per-program result lines and the summary count
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"basic-interpreter/batch"
+)
+
+func TestPrintBatchResults(t *testing.T) {
+	results := []batch.Result{
+		{Path: "a.bas", Steps: 12},
+		{Path: "b.bas", Steps: 1, Error: "?DIVISION BY ZERO ERROR IN 10"},
+	}
+	var out bytes.Buffer
+
+	if failed := printBatchResults(&out, results); failed != 1 {
+		t.Errorf("printBatchResults() = %d failed, want 1", failed)
+	}
+	want := "ok   a.bas (12 steps)\nFAIL b.bas: ?DIVISION BY ZERO ERROR IN 10\n1 passed, 1 failed\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+// ABOUTME: The `build` subcommand translating a program file into a standalone Go program, optionally compiled with go build
+// ABOUTME: Usage: basic build [-o FILE.go] [-exe FILE] [-module DIR] [-screen-width N] [-shims] [-dialect c64|extended] FILE.bas
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"basic-interpreter/basic"
+	"basic-interpreter/charset"
+	"basic-interpreter/transpile"
+)
+
+// modulePath is the module generated programs import for their support library
+const modulePath = "basic-interpreter"
+
+// runBuildCommand translates one program file to Go and, with -exe, compiles it
+func runBuildCommand(args []string) int {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	output := fs.String("o", "", "Go file to write (default: the program's name ending in .go, or none with -exe)")
+	exe := fs.String("exe", "", "Compile the program with go build into this executable")
+	module := fs.String("module", "", "Directory of the "+modulePath+" module generated programs import (default: found above the current directory)")
+	screenWidth := fs.Int("screen-width", 40, "Screen width in columns for line wrapping and TAB bounds (0 disables wrapping)")
+	shims := fs.Bool("shims", false, "Accept statements from Apple II, ZX Spectrum and BBC BASIC listings")
+	dialectName := fs.String("dialect", "extended", "BASIC dialect: extended, or c64 for strict BASIC V2")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic build [-o FILE.go] [-exe FILE] [-module DIR] [-screen-width N] [-shims] [-dialect c64|extended] FILE.bas")
+		return 1
+	}
+	path := fs.Arg(0)
+	content, err := readBasicFile(path, charset.Auto)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", path, err)
+		return 1
+	}
+
+	dialect, err := parseDialect(*dialectName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	options := []basic.Option{basic.WithDialect(dialect)}
+	if *shims {
+		options = append(options, basic.WithShims())
+	}
+	program, err := basic.Parse(content, options...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	src, err := transpile.Go(program, transpile.Options{Name: filepath.Base(path), ScreenWidth: *screenWidth, Dialect: dialect})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	if *output == "" && *exe == "" {
+		*output = strings.TrimSuffix(path, filepath.Ext(path)) + ".go"
+	}
+	if *output != "" {
+		if err := os.WriteFile(*output, src, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+			return 1
+		}
+	}
+	if *exe != "" {
+		if err := buildExecutable(src, *exe, *module); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// buildExecutable compiles generated Go source into exe, in a temporary module that points at the
+// basic-interpreter module in moduleDir, or the one found above the current directory when it is empty
+func buildExecutable(src []byte, exe, moduleDir string) error {
+	if moduleDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if moduleDir, err = findModule(cwd); err != nil {
+			return err
+		}
+	}
+	moduleDir, err := filepath.Abs(moduleDir)
+	if err != nil {
+		return err
+	}
+	exe, err = filepath.Abs(exe)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "basic-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	gomod := fmt.Sprintf("module program\n\ngo 1.24\n\nrequire %s v0.0.0\n\nreplace %s => %s\n", modulePath, modulePath, moduleDir)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644); err != nil {
+		return err
+	}
+	// The module's checksums let go build verify its dependencies without network access
+	if sum, err := os.ReadFile(filepath.Join(moduleDir, "go.sum")); err == nil {
+		if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "build", "-o", exe, ".")
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go build: %w", err)
+	}
+	return nil
+}
+
+// findModule returns the directory of the basic-interpreter module containing dir
+func findModule(dir string) (string, error) {
+	for {
+		if modFile, err := os.Open(filepath.Join(dir, "go.mod")); err == nil {
+			name := moduleName(modFile)
+			modFile.Close()
+			if name == modulePath {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("cannot find the " + modulePath + " module above the current directory; pass -module DIR")
+		}
+		dir = parent
+	}
+}
+
+// moduleName returns the path declared by the module line of a go.mod file
+func moduleName(modFile *os.File) string {
+	scanner := bufio.NewScanner(modFile)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(name), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Tests for the build subcommand
+// ABOUTME: Verifies the generated Go file, unsupported programs, argument validation and finding the module
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBuildCommandWritesGoFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.bas")
+	if err := os.WriteFile(path, []byte("10 PRINT \"HELLO\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runBuildCommand([]string{path}); code != 0 {
+		t.Fatalf("runBuildCommand = %d, want 0", code)
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "hello.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"from hello.bas. DO NOT EDIT.", "package main", `m.PrintLine(types.NewStringValue("HELLO"))`} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source lacks %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestRunBuildCommandValidation(t *testing.T) {
+	dir := t.TempDir()
+	unsupported := filepath.Join(dir, "loop.bas")
+	if err := os.WriteFile(unsupported, []byte("10 WHILE 1\n20 WEND\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{}, {"a.bas", "b.bas"}, {"does-not-exist.bas"}, {unsupported}} {
+		if code := runBuildCommand(args); code != 1 {
+			t.Errorf("runBuildCommand(%v) = %d, want 1", args, code)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "loop.go")); !os.IsNotExist(err) {
+		t.Errorf("unsupported program left a Go file: %v", err)
+	}
+}
+
+func TestFindModule(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "examples", "games")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module basic-interpreter\n\ngo 1.24\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Another module in between is skipped
+	if err := os.WriteFile(filepath.Join(root, "examples", "go.mod"), []byte("module \"examples\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := findModule(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != root {
+		t.Errorf("findModule = %q, want %q", got, root)
+	}
+	if _, err := findModule(t.TempDir()); err == nil {
+		t.Error("findModule outside the module succeeded")
+	}
+}
@@ -0,0 +1,23 @@
+// ABOUTME: -capture flag support: records a headless run on the screen model and saves it as an animated GIF
+// ABOUTME: Runs in emulated C64 time unless -speed says otherwise, so animations play back at their original pace
+
+package main
+
+import (
+	"os"
+
+	"basic-interpreter/capture"
+)
+
+// writeCapture saves the frames recorded by rec to an animated GIF file at path
+func writeCapture(path string, rec *capture.Recorder) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := rec.WriteGIF(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,38 @@
+// ABOUTME: Tests for the -capture flag support
+// ABOUTME: Verifies that a recorded run is saved as a decodable animated GIF
+
+package main
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"basic-interpreter/capture"
+	"basic-interpreter/runtime"
+)
+
+func TestWriteCapture(t *testing.T) {
+	rec := capture.NewRecorder(runtime.NewTestRuntime(), 0)
+	if err := rec.PrintLine("HELLO"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "run.gif")
+
+	if err := writeCapture(path, rec); err != nil {
+		t.Fatalf("writeCapture() returned error: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("decoding capture: %v", err)
+	}
+	if len(anim.Image) != 1 {
+		t.Errorf("capture has %d frames, want 1", len(anim.Image))
+	}
+}
@@ -0,0 +1,94 @@
+// ABOUTME: The `debug` subcommand: an interactive text debugger that runs a program file under breakpoints and stepping
+// ABOUTME: Usage: basic debug [-break LINES] [-context N] [-max-steps N] FILE.bas; type help at the prompt for the commands
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/basic"
+	"basic-interpreter/charset"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/runtime"
+)
+
+// debugPrompt is shown when the debugger waits for a command
+const debugPrompt = "(debug) "
+
+// runDebugCommand debugs one program file, reading debugger commands and INPUT lines from stdin
+func runDebugCommand(args []string) int {
+	fs := flag.NewFlagSet("debug", flag.ContinueOnError)
+	breakFlag := fs.String("break", "", "Comma-separated line numbers to stop at; without breakpoints the run stops before its first line")
+	context := fs.Int("context", 2, "Program lines shown before and after the line the run stopped at")
+	maxSteps := fs.Int("max-steps", 0, "Maximum number of execution steps (0 for no limit)")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic debug [-break LINES] [-context N] [-max-steps N] FILE.bas")
+		return 1
+	}
+	breakpoints, err := parseBreakpoints(*breakFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	content, err := readBasicFile(fs.Arg(0), charset.Auto)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+
+	if err := debugProgram(content, breakpoints, *context, *maxSteps, runtime.NewStandardRuntime(), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parseBreakpoints parses a comma-separated list of line numbers
+func parseBreakpoints(list string) ([]int, error) {
+	var lines []int
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		line, err := strconv.Atoi(field)
+		if err != nil || line < 0 {
+			return nil, fmt.Errorf("invalid breakpoint %q", field)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// debugProgram runs src under a command debugger. Commands are read from rt after a prompt on out,
+// so INPUT statements and the debugger share the same input.
+func debugProgram(src string, breakpoints []int, context, maxSteps int, rt runtime.Runtime, out io.Writer) error {
+	program, err := basic.Parse(src)
+	if err != nil {
+		return err
+	}
+
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetMaxSteps(maxSteps)
+	interp.SetSource(src)
+	interp.SetDebugger(&interpreter.CommandDebugger{
+		Read: func() (string, error) {
+			fmt.Fprint(out, debugPrompt)
+			return rt.Input("")
+		},
+		Out:     out,
+		Context: context,
+	})
+	for _, line := range breakpoints {
+		interp.SetBreakpoint(line)
+	}
+	interp.SetStepping(len(breakpoints) == 0)
+	return interp.Execute(program)
+}
@@ -0,0 +1,54 @@
+// ABOUTME: Tests for the debug subcommand
+// ABOUTME: Drives a debugging session with scripted commands and checks the breakpoint parsing
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"basic-interpreter/runtime"
+)
+
+func TestDebugProgram(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	rt.SetInput([]string{"? A", "c", "quit"})
+	var out strings.Builder
+
+	err := debugProgram("10 A=1\n20 A=A+1\n30 PRINT A\n40 GOTO 20", []int{30}, 1, 0, rt, &out)
+	if err != nil {
+		t.Fatalf("debugProgram() error = %v", err)
+	}
+	want := "breakpoint at 30\n   20 A=A+1\n=> 30 PRINT A\n   40 GOTO 20\n(debug) 2\n(debug) " +
+		"breakpoint at 30\n   20 A=A+1\n=> 30 PRINT A\n   40 GOTO 20\n(debug) "
+	if got := out.String(); got != want {
+		t.Errorf("debugger output = %q, want %q", got, want)
+	}
+	if got := strings.Join(rt.GetOutput(), ""); got != "2\n" {
+		t.Errorf("program output = %q, want %q", got, "2\n")
+	}
+}
+
+func TestDebugProgram_StopsAtFirstLineWithoutBreakpoints(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	rt.SetInput([]string{"q"})
+	var out strings.Builder
+
+	if err := debugProgram("10 PRINT 1\n20 END", nil, 0, 0, rt, &out); err != nil {
+		t.Fatalf("debugProgram() error = %v", err)
+	}
+	if got, want := out.String(), "step at 10: PRINT 1\n(debug) "; got != want {
+		t.Errorf("debugger output = %q, want %q", got, want)
+	}
+}
+
+func TestParseBreakpoints(t *testing.T) {
+	lines, err := parseBreakpoints("10, 200,")
+	if err != nil || !reflect.DeepEqual(lines, []int{10, 200}) {
+		t.Errorf("parseBreakpoints() = %v, %v; want [10 200]", lines, err)
+	}
+	if _, err := parseBreakpoints("10,X"); err == nil {
+		t.Error("parseBreakpoints(\"10,X\") succeeded, want an error")
+	}
+}
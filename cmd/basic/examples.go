@@ -0,0 +1,83 @@
+// ABOUTME: The `examples` subcommand listing and running the embedded example programs
+// ABOUTME: Usage: basic examples list | basic examples run [-i inputs] NAME
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/examples"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// runExamplesCommand dispatches `examples list` and `examples run NAME`
+func runExamplesCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: basic examples list | basic examples run [-i inputs] NAME")
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		listExamples(os.Stdout)
+		return 0
+	case "run":
+		return runExample(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown examples command %q (want list or run)\n", args[0])
+		return 1
+	}
+}
+
+// listExamples writes one line per embedded example: its name and title
+func listExamples(w io.Writer) {
+	for _, example := range examples.List() {
+		fmt.Fprintf(w, "%-12s %s\n", example.Name, example.Title)
+	}
+}
+
+// runExample parses and runs one embedded example on the console
+func runExample(args []string) int {
+	fs := flag.NewFlagSet("examples run", flag.ContinueOnError)
+	inputsFlag := fs.String("i", "", "Comma-separated inputs for INPUT statements")
+	maxSteps := fs.Int("max-steps", 100000, "Maximum number of execution steps (0 disables the limit)")
+	screenWidth := fs.Int("screen-width", 40, "Screen width in columns (0 disables wrapping)")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic examples run [-i inputs] NAME")
+		return 1
+	}
+
+	example, ok := examples.Get(fs.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown example %q; run `basic examples list` to see them\n", fs.Arg(0))
+		return 1
+	}
+
+	p := parser.New(lexer.New(example.Source))
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Position.Line, e.Message)
+		return 1
+	}
+
+	rt := newRuntime(*inputsFlag, runtime.DefaultCRLF, os.Stdout)
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetScreenWidth(*screenWidth)
+	interp.SetMaxSteps(*maxSteps)
+	err := interp.Execute(program)
+	printCapturedOutput(os.Stdout, rt, runtime.DefaultCRLF)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Runtime error: %v\n", err)
+		return 1
+	}
+	return 0
+}
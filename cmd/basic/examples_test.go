@@ -0,0 +1,44 @@
+// ABOUTME: Tests for the examples subcommand
+// ABOUTME: Verifies listing and argument validation of the embedded example gallery
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestListExamples(t *testing.T) {
+	var out bytes.Buffer
+	listExamples(&out)
+
+	if !strings.Contains(out.String(), "sieve        SIEVE OF ERATOSTHENES\n") {
+		t.Errorf("listing does not include the sieve example:\n%s", out.String())
+	}
+}
+
+func TestRunExampleValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing name", []string{}},
+		{"unknown example", []string{"nope"}},
+		{"too many names", []string{"sieve", "guess"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := runExample(tt.args); code != 1 {
+				t.Errorf("runExample(%v) = %d, want 1", tt.args, code)
+			}
+		})
+	}
+}
+
+func TestRunExamplesCommandUnknown(t *testing.T) {
+	if code := runExamplesCommand([]string{"bogus"}); code != 1 {
+		t.Errorf("runExamplesCommand(bogus) = %d, want 1", code)
+	}
+}
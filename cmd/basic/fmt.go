@@ -0,0 +1,66 @@
+// ABOUTME: The `fmt` subcommand pretty-printing program files with canonical spacing and upper-case keywords
+// ABOUTME: Usage: basic fmt [-w] [-shims] [-abbrev] [-crunch] [-dialect c64|extended] FILE.bas... prints the result, or rewrites the files with -w
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/format"
+)
+
+// runFmtCommand formats each program file to stdout, or in place with -w
+func runFmtCommand(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := fs.Bool("w", false, "Write the result back to each file instead of printing it")
+	shims := fs.Bool("shims", false, "Accept statements from Apple II, ZX Spectrum and BBC BASIC listings")
+	abbrev := fs.Bool("abbrev", false, "Accept C64 keyword abbreviations such as ? for PRINT")
+	crunch := fs.Bool("crunch", false, "Split keywords written without spaces, as in FORI=1TO10 (always on in the c64 dialect)")
+	dialectName := fs.String("dialect", "extended", "BASIC dialect: extended, or c64 for strict BASIC V2")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: basic fmt [-w] [-shims] [-abbrev] [-dialect c64|extended] FILE.bas...")
+		return 1
+	}
+
+	dialect, err := parseDialect(*dialectName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	opts := format.Options{Shims: *shims, Abbreviations: *abbrev, Crunch: *crunch, Dialect: dialect}
+	code := 0
+	for _, path := range fs.Args() {
+		if err := formatFile(path, opts, *write, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// formatFile formats one program file, writing the result to out or, with write set, back to the file when it changed
+func formatFile(path string, opts format.Options, write bool, out io.Writer) error {
+	content, err := readBasicFile(path, charset.Auto)
+	if err != nil {
+		return err
+	}
+	formatted, err := format.Source(content, opts)
+	if err != nil {
+		return err
+	}
+	if !write {
+		_, err = io.WriteString(out, formatted)
+		return err
+	}
+	if formatted == content {
+		return nil
+	}
+	return os.WriteFile(path, []byte(formatted), 0o644)
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Tests for the fmt subcommand
+// ABOUTME: Verifies printing, rewriting in place and reporting files that do not parse
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"basic-interpreter/format"
+)
+
+func TestFormatFilePrints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prog.bas")
+	if err := os.WriteFile(path, []byte("100 end\n5 print  \"HI\" ; x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := formatFile(path, format.Options{}, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "  5 PRINT \"HI\";x\n100 END\n"; out.String() != want {
+		t.Errorf("formatFile printed %q, want %q", out.String(), want)
+	}
+}
+
+func TestFormatFileWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prog.bas")
+	if err := os.WriteFile(path, []byte("10 goto 10\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := formatFile(path, format.Options{}, true, &out); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "10 GOTO 10\n" || out.Len() != 0 {
+		t.Errorf("file holds %q and %q was printed, want the formatted file and no output", content, out.String())
+	}
+}
+
+func TestRunFmtCommandValidation(t *testing.T) {
+	bad := filepath.Join(t.TempDir(), "bad.bas")
+	if err := os.WriteFile(bad, []byte("10 FOR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{}, {"does-not-exist.bas"}, {bad}} {
+		if code := runFmtCommand(args); code != 1 {
+			t.Errorf("runFmtCommand(%v) = %d, want 1", args, code)
+		}
+	}
+}
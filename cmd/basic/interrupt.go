@@ -0,0 +1,39 @@
+// ABOUTME: Ctrl+C handling: SIGINT becomes an interrupt the interpreter checks between statements
+// ABOUTME: A running program stops with BREAK IN as a C64 does on RUN/STOP instead of the process being killed
+
+package main
+
+import (
+	"os"
+	"os/signal"
+)
+
+// exitInterrupted is the exit status of a program stopped by Ctrl+C, as shells report SIGINT
+const exitInterrupted = 130
+
+// notifyInterrupt turns Ctrl+C into sends on the returned channel until stop is called. A second Ctrl+C before the
+// program takes the first, such as while INPUT waits for a line, ends the process.
+func notifyInterrupt() (interrupt <-chan struct{}, stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	sends := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				select {
+				case sends <- struct{}{}:
+				default:
+					os.Exit(exitInterrupted)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return sends, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
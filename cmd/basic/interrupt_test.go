@@ -0,0 +1,28 @@
+// ABOUTME: Tests for Ctrl+C handling in the command line tool
+// ABOUTME: Verifies SIGINT reaches the interpreter as an interrupt instead of killing the process
+
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNotifyInterrupt(t *testing.T) {
+	interrupt, stop := notifyInterrupt()
+	defer stop()
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("FindProcess: %v", err)
+	}
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send os.Interrupt on this platform: %v", err)
+	}
+	select {
+	case <-interrupt:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Ctrl+C did not send an interrupt")
+	}
+}
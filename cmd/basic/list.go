@@ -0,0 +1,65 @@
+// ABOUTME: The `list` subcommand printing a program file, or a range of its lines, in line-number order
+// ABOUTME: Usage: basic list FILE.bas [RANGE] where RANGE is N, N-M, -M or N-
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/highlight"
+	"basic-interpreter/parser"
+)
+
+// runListCommand lists one program file, highlighted when stdout is a color-capable terminal
+func runListCommand(args []string) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Usage: basic list FILE.bas [RANGE]")
+		return 1
+	}
+	r := parser.AllLines
+	if len(args) == 2 {
+		var err error
+		if r, err = parser.ParseLineRange(args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	content, err := readBasicFile(args[0], charset.Auto)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", args[0], err)
+		return 1
+	}
+	var listing strings.Builder
+	writeListing(&listing, content, r)
+	text := listing.String()
+	if highlight.Enabled(os.Stdout) {
+		text = highlight.Source(text)
+	}
+	fmt.Print(text)
+	return 0
+}
+
+// writeListing writes the numbered lines of src that fall in r, ordered by line number
+func writeListing(w io.Writer, src string, r parser.LineRange) {
+	lines := parser.SourceLines(src)
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		if r.Contains(n) {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	for _, n := range numbers {
+		if lines[n] == "" {
+			fmt.Fprintln(w, n)
+			continue
+		}
+		fmt.Fprintf(w, "%d %s\n", n, lines[n])
+	}
+}
@@ -0,0 +1,29 @@
+// ABOUTME: Tests for the list subcommand
+// ABOUTME: Verifies line ordering and range selection of listings
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"basic-interpreter/parser"
+)
+
+func TestWriteListing(t *testing.T) {
+	src := "30 END\n10 PRINT \"A\"\n20\n"
+	tests := []struct {
+		lines parser.LineRange
+		want  string
+	}{
+		{parser.AllLines, "10 PRINT \"A\"\n20\n30 END\n"},
+		{parser.LineRange{From: 15, To: 30}, "20\n30 END\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		writeListing(&out, src, tt.lines)
+		if out.String() != tt.want {
+			t.Errorf("writeListing(%v) = %q, want %q", tt.lines, out.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// ABOUTME: The `lsp` subcommand running the language server over stdin and stdout for editors
+// ABOUTME: Usage: basic lsp
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/lsp"
+)
+
+// runLSPCommand serves one editor session on stdin and stdout
+func runLSPCommand(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: basic lsp")
+		return 1
+	}
+	return serveLSP(os.Stdin, os.Stdout, os.Stderr)
+}
+
+// serveLSP runs the language server until the client exits, returning the exit code
+func serveLSP(in io.Reader, out, errOut io.Writer) int {
+	if err := lsp.NewServer(in, out).Serve(); err != nil {
+		fmt.Fprintf(errOut, "lsp: %v\n", err)
+		return 1
+	}
+	return 0
+}
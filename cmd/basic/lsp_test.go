@@ -0,0 +1,37 @@
+// ABOUTME: Tests for the lsp subcommand
+// ABOUTME: Verifies argument validation and the exit codes of a session
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestServeLSP(t *testing.T) {
+	session := func(messages ...string) string {
+		var in strings.Builder
+		for _, msg := range messages {
+			fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+		}
+		return in.String()
+	}
+	shutdown := `{"jsonrpc":"2.0","id":1,"method":"shutdown"}`
+	exit := `{"jsonrpc":"2.0","method":"exit"}`
+
+	var out, errOut bytes.Buffer
+	if code := serveLSP(strings.NewReader(session(shutdown, exit)), &out, &errOut); code != 0 {
+		t.Errorf("serveLSP after shutdown = %d, want 0 (%s)", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), `"id":1`) {
+		t.Errorf("shutdown was not answered: %q", out.String())
+	}
+	if code := serveLSP(strings.NewReader(session(exit)), &out, &errOut); code != 1 {
+		t.Errorf("serveLSP without shutdown = %d, want 1", code)
+	}
+	if code := runLSPCommand([]string{"extra"}); code != 1 {
+		t.Errorf("runLSPCommand with an argument = %d, want 1", code)
+	}
+}
@@ -4,25 +4,102 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"basic-interpreter/astjson"
+	"basic-interpreter/basic"
+	"basic-interpreter/capture"
+	"basic-interpreter/charset"
+	"basic-interpreter/highlight"
 	"basic-interpreter/interpreter"
-	"basic-interpreter/lexer"
 	"basic-interpreter/parser"
+	"basic-interpreter/prg"
+	"basic-interpreter/repl"
 	"basic-interpreter/runtime"
+	"basic-interpreter/storage"
+	"basic-interpreter/types"
 )
 
+// subcommands maps the first command-line argument to a handler returning the exit code
+var subcommands = map[string]func(args []string) int{
+	"audit":     runAuditCommand,
+	"batch":     runBatchCommand,
+	"build":     runBuildCommand,
+	"debug":     runDebugCommand,
+	"examples":  runExamplesCommand,
+	"fmt":       runFmtCommand,
+	"list":      runListCommand,
+	"lsp":       runLSPCommand,
+	"reference": runReferenceCommand,
+	"renum":     runRenumCommand,
+	"run":       runPackCommand,
+	"stats":     runStatsCommand,
+	"vet":       runVetCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
 	// Define command-line flags
 	maxSteps := flag.Int("max-steps", 1000, "Maximum number of execution steps before infinite loop protection triggers")
+	timeoutFlag := flag.Duration("timeout", 0, "Stop a program still running after this long, such as 5s (0 for no limit)")
 	executeFlag := flag.String("e", "", "Execute BASIC program directly from command line")
 	inputsFlag := flag.String("i", "", "Comma-separated inputs for INPUT statements")
+	screenWidth := flag.Int("screen-width", 40, "Screen width in columns for line wrapping and TAB bounds (0 disables wrapping)")
+	zoneWidth := flag.Int("zone-width", 10, "Width of the print zones used by commas in PRINT")
+	speedFlag := flag.String("speed", "", "Pace execution like an original machine: c64 (default: full speed)")
+	cyclesFlag := flag.Float64("cycles-per-statement", 0, "Average CPU cycles per statement for -speed (0 uses the machine's default)")
+	encodingFlag := flag.String("encoding", "auto", "Character set of the program file: auto, utf-8, utf-16, utf-16le, utf-16be, latin1 or petscii")
+	abbrevFlag := flag.Bool("abbrev", false, "Accept C64 keyword abbreviations: ? for PRINT and shifted-letter forms such as gO for GOTO")
+	crunchFlag := flag.Bool("crunch", false, "Split keywords written without spaces off names, as in crunched listings like FORI=1TO10 (always on with -dialect c64)")
+	bannerFlag := flag.Bool("banner", false, "Start with the C64 startup banner and READY. prompt, and report a runtime error on screen followed by READY.")
+	keepVarsFlag := flag.Bool("keep-vars", false, "Interactive mode: keep variables across RUN, LOAD and line edits so a failed run can be inspected and the next run seeded")
+	dialectFlag := flag.String("dialect", string(parser.DialectExtended), "BASIC dialect: extended adds WHILE, ELSE, $FF hex literals and other extensions, c64 is strict BASIC V2")
+	precisionFlag := flag.String("precision", "double", "Number precision: double keeps float64, c64 rounds to the C64's 5-byte format and prints 9 significant digits")
+	shimsFlag := flag.Bool("shims", false, "Accept statements from Apple II, ZX Spectrum and BBC BASIC listings (HOME, CLS, IF ... ELSE) and name the ones with no equivalent")
+	traceFlag := flag.String("trace-json", "", "Write a JSON trace of the run (statements, variable changes, output) to this file")
+	recordFlag := flag.String("record", "", "Write the run's INPUT lines, GET keys and RND draws to this journal file, for -replay")
+	replayFlag := flag.String("replay", "", "Answer INPUT, GET and RND from a journal written by -record, reproducing that run")
+	coverageFlag := flag.String("coverage", "", "Write a coverage report to this file: the listing with the statements executed on each line, then a summary")
+	tronFlag := flag.Bool("trace", false, "Print the number of each line as it runs to stderr, as TRON does")
+	tronTextFlag := flag.Bool("trace-statements", false, "With -trace, print the text of each line after its number")
+	captureFlag := flag.String("capture", "", "Run headless on a 40x25 screen in emulated time and write an animated GIF of it to this file")
+	captureEvery := flag.Int("capture-every", capture.DefaultFrameJiffies, "Jiffies (1/60 s) of emulated time between -capture frames")
+	crlfFlag := flag.Bool("crlf", runtime.DefaultCRLF, "End output lines with CRLF; newlines and CHR$(13) both become CRLF (default on Windows)")
+	engineFlag := flag.String("engine", string(basic.EngineTree), "Execution engine: tree walks the syntax tree, vm compiles to bytecode first (programs the VM cannot run use tree)")
+	optimizeFlag := flag.Bool("optimize", false, "Fold constant expressions such as 2*3+1 or LEN(\"ABC\") before running")
+	outputFlag := flag.String("output", "", "Write the program's output to this file instead of stdout")
+	dumpASTFlag := flag.Bool("dump-ast", false, "Print the parsed program as JSON with line positions instead of running it")
+	diskFlag := flag.String("disk", ".", "Directory serving as disk drive 8 for LOAD, SAVE and OPEN; names cannot reach outside it")
+	diskModeFlag := flag.String("disk-mode", "read-write", "Access to the -disk directory: read-write, or read-only to refuse SAVE and files opened for writing")
+	listFlag := flag.Bool("list", false, "Print the program listing (syntax highlighted on a terminal unless NO_COLOR is set) instead of running it")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options] <filename.bas>\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "   or: %s [options] -e \"BASIC program\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s [options]              (interactive mode)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s examples list|run NAME\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s list <filename.bas> [RANGE]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s fmt [-w] <filename.bas>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s renum [-start N] [-step N] [-w] <filename.bas>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s stats <filename.bas>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s vet <filename.bas>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s audit <filename.bas>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s build [-o FILE.go] [-exe FILE] [-module DIR] <filename.bas>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s run <pack.bpk>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s batch [-progress] [-checkpoint FILE] <filename.bas>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s debug [-break LINES] <filename.bas>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "   or: %s lsp                    (language server on stdin/stdout)\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
 	}
@@ -35,96 +112,430 @@ func main() {
 	if *executeFlag != "" && flag.NArg() > 0 {
 		exitWithError("Cannot specify both -e flag and filename")
 	}
+	disk, err := newDisk(*diskFlag, *diskModeFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	if *executeFlag == "" && flag.NArg() == 0 {
+		runInteractive(*maxSteps, *keepVarsFlag, *bannerFlag, disk)
+		return
+	}
 	if *executeFlag == "" && flag.NArg() != 1 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	var snippet oneLiner
 	if *executeFlag != "" {
-		content = *executeFlag
+		snippet = prepareOneLiner(*executeFlag)
+		content = snippet.program
 	} else {
 		filename := flag.Arg(0)
-		content, err = readBasicFile(filename)
+		encoding, err := charset.Parse(*encodingFlag)
+		if err != nil {
+			exitWithError("%v", err)
+		}
+		content, err = readBasicFile(filename, encoding)
 		if err != nil {
 			exitWithError("Error reading file %s: %v", filename, err)
 		}
 	}
 
+	if *listFlag {
+		printListing(content)
+		return
+	}
+
+	dialect, err := parseDialect(*dialectFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	precision, err := parsePrecision(*precisionFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	options := []basic.Option{basic.WithScreenWidth(*screenWidth), basic.WithSource(content), basic.WithDialect(dialect), basic.WithPrecision(precision), basic.WithFiles(disk)}
+	if *abbrevFlag {
+		options = append(options, basic.WithAbbreviations())
+	}
+	if *crunchFlag {
+		options = append(options, basic.WithCrunch())
+	}
+	if *shimsFlag {
+		options = append(options, basic.WithShims())
+	}
+	if *zoneWidth > 0 {
+		options = append(options, basic.WithZoneWidth(*zoneWidth))
+	}
+	if *maxSteps > 0 {
+		options = append(options, basic.WithMaxSteps(*maxSteps))
+	}
+	if *timeoutFlag > 0 {
+		options = append(options, basic.WithTimeLimit(*timeoutFlag))
+	}
+	engine, err := parseEngine(*engineFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	options = append(options, basic.WithEngine(engine))
+	if *optimizeFlag {
+		options = append(options, basic.WithOptimization())
+	}
+	speed, err := speedModel(*speedFlag, *cyclesFlag)
+	if err != nil {
+		exitWithError("%v", err)
+	}
+	if *captureFlag != "" && *speedFlag == "" {
+		// Emulated time only advances while execution is paced
+		speed, _ = speedModel("c64", *cyclesFlag)
+	}
+	options = append(options, basic.WithSpeed(speed))
+
 	// Parse the BASIC program
-	l := lexer.New(content)
-	p := parser.New(l)
-	program := p.ParseProgram()
-
-	// Check for parsing error
-	if e := p.ParseError(); e != nil {
-		// Prepare source lines for context printing (1-based indexing)
-		// Normalize newlines in case of Windows files
-		normalized := strings.ReplaceAll(content, "\r\n", "\n")
-		lines := strings.Split(normalized, "\n")
-
-		// Print offending source line if available (line numbers are 1-based)
-		if e.Position.Line >= 1 && e.Position.Line <= len(lines) {
-			offending := lines[e.Position.Line-1]
-			fmt.Fprintf(os.Stderr, "%s\n", offending)
-		}
-		fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Position.Line, e.Message)
+	program, err := basic.Parse(content, options...)
+	var syntaxErrs basic.ErrorList
+	if errors.As(err, &syntaxErrs) {
+		printSyntaxErrors(os.Stderr, content, snippet, syntaxErrs)
 		os.Exit(1)
 	}
 
+	if *dumpASTFlag {
+		dump, dumpErr := astjson.Marshal(program, content)
+		if dumpErr != nil {
+			exitWithError("Error encoding syntax tree: %v", dumpErr)
+		}
+		os.Stdout.Write(dump)
+		return
+	}
+
 	// Execute the program
-	if *executeFlag == "" {
+	if *executeFlag == "" && !*bannerFlag {
 		fmt.Printf("Program loaded: %s\n", flag.Arg(0))
 		fmt.Println("Executing program:")
 		fmt.Println()
 	}
 
-	// Create runtime and interpreter
-	var rt runtime.Runtime
-	if *inputsFlag != "" {
-		// Use test runtime with predefined inputs
-		testRuntime := runtime.NewTestRuntime()
-		inputs := strings.Split(*inputsFlag, ",")
-		for i := range inputs {
-			inputs[i] = strings.TrimSpace(inputs[i])
+	var trace *interpreter.Trace
+	if *traceFlag != "" {
+		trace = interpreter.NewTrace(interpreter.DefaultTraceLimits)
+		options = append(options, basic.WithTrace(trace))
+	}
+
+	var coverage *interpreter.Coverage
+	if *coverageFlag != "" {
+		coverage = interpreter.NewCoverage()
+		options = append(options, basic.WithCoverage(coverage))
+	}
+
+	if *tronFlag {
+		options = append(options, basic.WithLineTrace(os.Stderr, *tronTextFlag))
+	}
+
+	var journal *interpreter.Journal
+	if *recordFlag != "" {
+		journal = &interpreter.Journal{}
+		options = append(options, basic.WithRecording(journal))
+	}
+	if *replayFlag != "" {
+		replay, replayErr := readJournal(*replayFlag)
+		if replayErr != nil {
+			exitWithError("Error reading journal %s: %v", *replayFlag, replayErr)
 		}
-		testRuntime.SetInput(inputs)
-		rt = testRuntime
-	} else {
-		rt = runtime.NewStandardRuntime()
+		options = append(options, basic.WithReplay(replay))
 	}
-	interp := interpreter.NewInterpreter(rt)
 
-	// Configure infinite loop protection
-	if *maxSteps > 0 {
-		interp.SetMaxSteps(*maxSteps)
+	out := io.Writer(os.Stdout)
+	if *outputFlag != "" {
+		file, createErr := os.Create(*outputFlag)
+		if createErr != nil {
+			exitWithError("Error creating output %s: %v", *outputFlag, createErr)
+		}
+		defer file.Close()
+		out = file
+	}
+	rt := newRuntime(*inputsFlag, *crlfFlag, out)
+	var recorder *capture.Recorder
+	if *captureFlag != "" {
+		recorder = capture.NewRecorder(rt, *captureEvery)
+		rt = recorder
+	}
+	if *bannerFlag {
+		_ = rt.Print(repl.Banner + "READY.\nRUN\n")
+	}
+	interrupt, stopInterrupt := notifyInterrupt()
+	_, err = basic.Run(program, append(options, basic.WithRuntime(rt), basic.WithInterrupt(interrupt))...)
+	stopInterrupt()
+	interrupted := errors.Is(err, interpreter.CodeBreak)
+	if *bannerFlag {
+		// A C64 shows the error on screen and returns to READY.
+		if err != nil {
+			_ = rt.PrintLine(err.Error())
+		}
+		_ = rt.PrintLine("READY.")
+	} else if interrupted {
+		_ = rt.PrintLine(err.Error())
+	}
+	if recorder != nil {
+		if captureErr := writeCapture(*captureFlag, recorder); captureErr != nil {
+			exitWithError("Error writing capture %s: %v", *captureFlag, captureErr)
+		}
+	}
+	if trace != nil {
+		basic.AnnotateTrace(trace, content)
+		if traceErr := writeTrace(*traceFlag, trace); traceErr != nil {
+			exitWithError("Error writing trace %s: %v", *traceFlag, traceErr)
+		}
+	}
+	if journal != nil {
+		if journalErr := writeJournal(*recordFlag, journal); journalErr != nil {
+			exitWithError("Error writing journal %s: %v", *recordFlag, journalErr)
+		}
+	}
+	if coverage != nil {
+		if coverageErr := writeCoverage(*coverageFlag, coverage, content); coverageErr != nil {
+			exitWithError("Error writing coverage %s: %v", *coverageFlag, coverageErr)
+		}
+	}
+	if err != nil && !*bannerFlag && !interrupted {
+		exitWithError("Runtime error: %s", snippet.describeRuntimeError(err))
 	}
 
-	// Execute the program
-	err = interp.Execute(program)
+	printCapturedOutput(out, rt, *crlfFlag)
+	if interrupted {
+		os.Exit(exitInterrupted)
+	}
 	if err != nil {
-		exitWithError("Runtime error: %v", err)
+		os.Exit(1)
 	}
+}
 
-	// If using test runtime with -i flag, output the captured results to stdout
+// newRuntime returns a console runtime printing to out, or a test runtime fed from comma-separated inputs when given
+func newRuntime(inputsFlag string, crlf bool, out io.Writer) runtime.Runtime {
+	if inputsFlag == "" {
+		std := runtime.NewStandardRuntimeIO(os.Stdin, out, os.Stderr)
+		std.SetCRLF(crlf)
+		return std
+	}
+	testRuntime := runtime.NewTestRuntime()
+	inputs := strings.Split(inputsFlag, ",")
+	for i := range inputs {
+		inputs[i] = strings.TrimSpace(inputs[i])
+	}
+	testRuntime.SetInput(inputs)
+	return testRuntime
+}
+
+// newDisk returns the store for drive 8 named by -disk and -disk-mode
+func newDisk(dir, mode string) (*storage.DiskStore, error) {
+	disk := storage.NewDiskStore(dir)
+	switch strings.ToLower(mode) {
+	case "read-write":
+	case "read-only":
+		disk.ReadOnly = true
+	default:
+		return nil, fmt.Errorf("unknown disk mode %q (want read-write or read-only)", mode)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("disk directory %s is not a directory", dir)
+	}
+	return disk, nil
+}
+
+// speedModel returns the pacing model for a -speed name, optionally overriding its cycles per statement
+func speedModel(name string, cyclesPerStatement float64) (interpreter.SpeedModel, error) {
+	var model interpreter.SpeedModel
+	switch strings.ToLower(name) {
+	case "", "max":
+		return model, nil
+	case "c64":
+		model = interpreter.C64Speed
+	default:
+		return model, fmt.Errorf("unknown speed %q (want c64 or max)", name)
+	}
+	if cyclesPerStatement > 0 {
+		model.CyclesPerStatement = cyclesPerStatement
+	}
+	return model, nil
+}
+
+// parseEngine returns the execution engine named by -engine
+func parseEngine(name string) (basic.Engine, error) {
+	switch engine := basic.Engine(strings.ToLower(name)); engine {
+	case basic.EngineTree, basic.EngineVM:
+		return engine, nil
+	}
+	return "", fmt.Errorf("unknown engine %q (want tree or vm)", name)
+}
+
+// parseDialect converts a -dialect flag value to a dialect
+func parseDialect(name string) (parser.Dialect, error) {
+	switch dialect := parser.Dialect(strings.ToLower(name)); dialect {
+	case parser.DialectC64, parser.DialectExtended:
+		return dialect, nil
+	}
+	return "", fmt.Errorf("unknown dialect %q (want c64 or extended)", name)
+}
+
+// parsePrecision converts a -precision flag value to a precision
+func parsePrecision(name string) (types.Precision, error) {
+	switch strings.ToLower(name) {
+	case "double":
+		return types.PrecisionDouble, nil
+	case "c64":
+		return types.PrecisionC64, nil
+	}
+	return 0, fmt.Errorf("unknown precision %q (want double or c64)", name)
+}
+
+// writeTrace writes an execution trace to path as indented JSON
+func writeTrace(path string, trace *interpreter.Trace) error {
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// writeJournal writes a journal recorded by -record to path as JSON
+func writeJournal(path string, journal *interpreter.Journal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// readJournal reads a journal written by -record
+func readJournal(path string) (*interpreter.Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var journal interpreter.Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, err
+	}
+	return &journal, nil
+}
+
+// writeCoverage writes the annotated listing and coverage summary of a run to path
+func writeCoverage(path string, coverage *interpreter.Coverage, src string) error {
+	var report strings.Builder
+	if err := coverage.Annotate(&report, src); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(report.String()), 0644)
+}
+
+// printCapturedOutput writes output captured by a test runtime (used with -i) to stdout, with CRLF line endings if crlf is set
+func printCapturedOutput(w io.Writer, rt runtime.Runtime, crlf bool) {
 	if testRuntime, ok := rt.(*runtime.TestRuntime); ok {
-		output := testRuntime.GetOutput()
-		for _, line := range output {
-			fmt.Print(line)
+		for _, line := range testRuntime.GetOutput() {
+			if crlf {
+				line = runtime.CRLFLines(line)
+			}
+			fmt.Fprint(w, line)
+		}
+	}
+}
+
+// runInteractive starts the REPL on the terminal, persisting command history and an auto-saved program in the user's home directory
+func runInteractive(maxSteps int, keepVars, banner bool, disk *storage.DiskStore) {
+	history := repl.NewHistory(repl.DefaultHistorySize)
+	historyPath := ""
+	recoveryPath := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		historyPath = filepath.Join(home, ".basic_history")
+		recoveryPath = filepath.Join(home, ".basic_recovery.bas")
+		_ = history.Load(historyPath)
+	}
+
+	editor := repl.NewEditor(os.Stdin, os.Stdout, history)
+	session := repl.New(editor, os.Stdout, history)
+	session.SetMaxSteps(maxSteps)
+	session.SetKeepVars(keepVars)
+	session.SetBanner(banner)
+	session.SetRecoveryFile(recoveryPath)
+	session.SetStorage(storage.NewDefault(disk))
+	session.SetFiles(disk)
+	session.SetColor(highlight.Enabled(os.Stdout))
+	interrupt, stopInterrupt := notifyInterrupt()
+	session.SetInterrupt(interrupt)
+	err := session.Run()
+	stopInterrupt()
+
+	if historyPath != "" {
+		_ = history.Save(historyPath)
+	}
+	if err != nil {
+		exitWithError("Error: %v", err)
+	}
+}
+
+// printListing writes the program source to stdout, highlighted when stdout is a color-capable terminal
+func printListing(content string) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if highlight.Enabled(os.Stdout) {
+		content = highlight.Source(content)
+	}
+	fmt.Print(content)
+}
+
+// printSyntaxErrors shows each syntax error under its source line with a caret at the offending column. Errors in
+// a -e snippet point into the snippet the user typed rather than the generated program.
+func printSyntaxErrors(w io.Writer, content string, snippet oneLiner, syntaxErrs basic.ErrorList) {
+	// Normalize newlines in case of Windows files; source lines are 1-based
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for _, syntaxErr := range syntaxErrs {
+		if statement, ok := snippet.statementForSourceLine(syntaxErr.Line); ok {
+			fmt.Fprintf(w, "%s\nstatement %d: %s\n", snippet.caret(statement, syntaxErr.Column), statement+1, syntaxErr.Message)
+			continue
 		}
+		if syntaxErr.Line >= 1 && syntaxErr.Line <= len(lines) {
+			offending := lines[syntaxErr.Line-1]
+			fmt.Fprintf(w, "%s\n", offending)
+			if syntaxErr.Column > 0 {
+				fmt.Fprintln(w, caretUnder(offending, syntaxErr.Column))
+			}
+		}
+		fmt.Fprintln(w, syntaxErr)
 	}
 }
 
+// caretUnder returns a line with a caret under the given 1-based column of text, keeping its tabs so the caret lines up
+func caretUnder(text string, column int) string {
+	var sb strings.Builder
+	for n, r := range []rune(text) {
+		if n >= column-1 {
+			break
+		}
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	sb.WriteByte('^')
+	return sb.String()
+}
+
 // exitWithError prints an error message and exits with code 1
 func exitWithError(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
 	os.Exit(1)
 }
 
-// readBasicFile reads the contents of a BASIC program file
-func readBasicFile(filename string) (string, error) {
+// readBasicFile reads a BASIC program file and converts it from the given encoding to UTF-8; .prg files are detokenized
+func readBasicFile(filename string, encoding charset.Encoding) (string, error) {
 	content, err := os.ReadFile(filename)
 	if err != nil {
 		return "", err
 	}
-	return string(content), nil
+	if strings.EqualFold(filepath.Ext(filename), ".prg") {
+		return prg.Decode(content)
+	}
+	return charset.Decode(content, encoding)
 }
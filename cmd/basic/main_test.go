@@ -9,6 +9,12 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"basic-interpreter/basic"
+	"basic-interpreter/charset"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
 )
 
 func TestReadBasicFile(t *testing.T) {
@@ -25,7 +31,7 @@ func TestReadBasicFile(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	content, err := readBasicFile(testFile)
+	content, err := readBasicFile(testFile, charset.Auto)
 	if err != nil {
 		t.Errorf("readBasicFile() returned error: %v", err)
 	}
@@ -35,9 +41,32 @@ func TestReadBasicFile(t *testing.T) {
 	}
 }
 
+func TestReadBasicFileConvertsEncoding(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "utf16.bas")
+	data := []byte{0xFF, 0xFE}
+	for _, r := range "10 END\n" {
+		data = append(data, byte(r), 0)
+	}
+	if err := os.WriteFile(testFile, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	content, err := readBasicFile(testFile, charset.Auto)
+	if err != nil {
+		t.Fatalf("readBasicFile() returned error: %v", err)
+	}
+	if content != "10 END\n" {
+		t.Errorf("readBasicFile() = %q, want %q", content, "10 END\n")
+	}
+
+	if _, err := readBasicFile(testFile, charset.UTF8); err == nil {
+		t.Error("readBasicFile() should reject UTF-16 data read as UTF-8")
+	}
+}
+
 func TestReadBasicFileNotFound(t *testing.T) {
 	// Test reading a non-existent file
-	_, err := readBasicFile("nonexistent.bas")
+	_, err := readBasicFile("nonexistent.bas", charset.Auto)
 	if err == nil {
 		t.Error("readBasicFile() should return error for non-existent file")
 	}
@@ -62,7 +91,7 @@ func TestReadBasicFilePermissionDenied(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	_, err = readBasicFile(testFile)
+	_, err = readBasicFile(testFile, charset.Auto)
 	if err == nil {
 		t.Error("readBasicFile() should return error for permission denied")
 	}
@@ -180,3 +209,169 @@ func TestFlagValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestSpeedModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		speed   string
+		cycles  float64
+		want    interpreter.SpeedModel
+		wantErr bool
+	}{
+		{name: "default is full speed", speed: "", want: interpreter.SpeedModel{}},
+		{name: "max is full speed", speed: "max", want: interpreter.SpeedModel{}},
+		{name: "c64 preset", speed: "C64", want: interpreter.C64Speed},
+		{name: "c64 with custom cycles", speed: "c64", cycles: 2000, want: interpreter.SpeedModel{ClockHz: interpreter.C64Speed.ClockHz, CyclesPerStatement: 2000}},
+		{name: "unknown machine", speed: "vic20", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := speedModel(tt.speed, tt.cycles)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("speedModel(%q) error = %v, wantErr %v", tt.speed, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("speedModel(%q) = %+v, want %+v", tt.speed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    basic.Engine
+		wantErr bool
+	}{
+		{name: "tree", want: basic.EngineTree},
+		{name: "VM", want: basic.EngineVM},
+		{name: "jit", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEngine(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEngine(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseEngine(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    parser.Dialect
+		wantErr bool
+	}{
+		{name: "c64", want: parser.DialectC64},
+		{name: "Extended", want: parser.DialectExtended},
+		{name: "amiga", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDialect(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDialect(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDialect(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    types.Precision
+		wantErr bool
+	}{
+		{name: "double", want: types.PrecisionDouble},
+		{name: "C64", want: types.PrecisionC64},
+		{name: "single", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrecision(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePrecision(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePrecision(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTrace(t *testing.T) {
+	trace := interpreter.NewTrace(interpreter.DefaultTraceLimits)
+	trace.Events = append(trace.Events, interpreter.TraceEvent{Step: 1, Line: 10, Text: "A=1", Changes: map[string]any{"A": 1.0}})
+	path := filepath.Join(t.TempDir(), "trace.json")
+
+	if err := writeTrace(path, trace); err != nil {
+		t.Fatalf("writeTrace() returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading trace: %v", err)
+	}
+	for _, want := range []string{`"line": 10`, `"text": "A=1"`, `"A": 1`, `"truncated": false`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("trace %s does not contain %s", data, want)
+		}
+	}
+}
+
+func TestNewRuntimeWritesToOutput(t *testing.T) {
+	for _, inputs := range []string{"", "1"} {
+		var out strings.Builder
+		rt := newRuntime(inputs, false, &out)
+		if err := rt.PrintLine("HELLO"); err != nil {
+			t.Fatal(err)
+		}
+		printCapturedOutput(&out, rt, false)
+		if out.String() != "HELLO\n" {
+			t.Errorf("newRuntime(%q) wrote %q, want %q", inputs, out.String(), "HELLO\n")
+		}
+	}
+}
+
+func TestCaretUnder(t *testing.T) {
+	tests := []struct {
+		text   string
+		column int
+		want   string
+	}{
+		{"10 PRINT )", 10, "         ^"},
+		{"10\tX=1 +* 2", 9, "  \t     ^"},
+		{"10 PRINT \"π\" )", 14, "             ^"},
+		{"10 GOTO", 1, "^"},
+	}
+	for _, tt := range tests {
+		if got := caretUnder(tt.text, tt.column); got != tt.want {
+			t.Errorf("caretUnder(%q, %d) = %q, want %q", tt.text, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestPrintSyntaxErrors(t *testing.T) {
+	content := "10 GOTO\r\n20 PRINT \"OK\"\r\n30 X=(1\r\n"
+	_, err := basic.Parse(content)
+	list, ok := err.(basic.ErrorList)
+	if !ok {
+		t.Fatalf("basic.Parse returned %v, want an ErrorList", err)
+	}
+	var out strings.Builder
+	printSyntaxErrors(&out, content, oneLiner{}, list)
+	want := "10 GOTO\n       ^\nline 1: " + list[0].Message + "\n30 X=(1\n       ^\nline 3: " + list[1].Message + "\n"
+	if out.String() != want {
+		t.Errorf("printSyntaxErrors wrote %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Support for -e one-liners: auto-numbering snippets written without line numbers
+// ABOUTME: Remembers where each generated line came from so errors can point into the snippet with a caret
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"basic-interpreter/lexer"
+)
+
+// oneLinerStep is the gap between the line numbers given to unnumbered statements
+const oneLinerStep = 10
+
+// runtimeErrorLine extracts the BASIC line number from a runtime error message
+var runtimeErrorLine = regexp.MustCompile(` IN (\d+)`)
+
+// oneLiner is a -e snippet prepared for parsing
+type oneLiner struct {
+	snippet string
+	program string
+	starts  []int // Offset in snippet of each generated line; nil when the snippet had its own line numbers
+}
+
+// prepareOneLiner numbers the statements of a snippet that has no line numbers.
+// Statements are split on ':' and newlines, except after IF (the rest of the line is its THEN branch) and REM.
+func prepareOneLiner(snippet string) oneLiner {
+	trimmed := strings.TrimSpace(snippet)
+	if trimmed == "" || (trimmed[0] >= '0' && trimmed[0] <= '9') {
+		return oneLiner{snippet: snippet, program: snippet}
+	}
+
+	o := oneLiner{snippet: snippet}
+	var sb strings.Builder
+	add := func(start, end int) {
+		text := strings.TrimSpace(snippet[start:end])
+		if text == "" {
+			return
+		}
+		start += strings.Index(snippet[start:end], text)
+		o.starts = append(o.starts, start)
+		fmt.Fprintf(&sb, "%d %s\n", len(o.starts)*oneLinerStep, text)
+	}
+
+	lineStart := 0
+	for _, line := range strings.SplitAfter(snippet, "\n") {
+		start, pos := lineStart, lineStart
+		for _, seg := range lexer.Classify(line) {
+			if seg.Class == lexer.ClassKeyword && (strings.EqualFold(seg.Text, "IF") || strings.EqualFold(seg.Text, "REM")) {
+				break
+			}
+			if seg.Class == lexer.ClassPlain && seg.Text == ":" {
+				add(start, pos)
+				start = pos + 1
+			}
+			pos += len(seg.Text)
+		}
+		lineStart += len(line)
+		add(start, lineStart)
+	}
+	o.program = sb.String()
+	return o
+}
+
+// numbered reports whether line numbers were generated for the snippet
+func (o oneLiner) numbered() bool {
+	return o.starts != nil
+}
+
+// statementForLine maps a generated BASIC line number back to its 0-based statement index
+func (o oneLiner) statementForLine(number int) (int, bool) {
+	idx := number/oneLinerStep - 1
+	if !o.numbered() || number%oneLinerStep != 0 || idx < 0 || idx >= len(o.starts) {
+		return 0, false
+	}
+	return idx, true
+}
+
+// statementForSourceLine maps a 1-based line of the generated program to its 0-based statement index
+func (o oneLiner) statementForSourceLine(line int) (int, bool) {
+	return o.statementForLine(line * oneLinerStep)
+}
+
+// caret returns the snippet line holding a statement with a caret under column, the 1-based character column in
+// the statement's generated line; columns before the statement text, such as 0, point at its start
+func (o oneLiner) caret(statement, column int) string {
+	offset := o.starts[statement]
+	lineStart := strings.LastIndexByte(o.snippet[:offset], '\n') + 1
+	lineEnd := len(o.snippet)
+	if end := strings.IndexByte(o.snippet[offset:], '\n'); end >= 0 {
+		lineEnd = offset + end
+	}
+	line := o.snippet[lineStart:lineEnd]
+	prefix := len(strconv.Itoa((statement+1)*oneLinerStep)) + 1 // The line number and space before the statement
+	at := utf8.RuneCountInString(o.snippet[lineStart:offset]) + max(column-prefix, 1)
+	return line + "\n" + caretUnder(line, at)
+}
+
+// describeRuntimeError adds the offending statement to a runtime error raised by a numbered snippet
+func (o oneLiner) describeRuntimeError(err error) string {
+	msg := err.Error()
+	match := runtimeErrorLine.FindStringSubmatch(msg)
+	if match == nil {
+		return msg
+	}
+	number, _ := strconv.Atoi(match[1])
+	statement, ok := o.statementForLine(number)
+	if !ok {
+		return msg
+	}
+	return msg + "\n" + o.caret(statement, 0)
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Tests for -e one-liner preparation: auto-numbering and caret error reports
+// ABOUTME: Verifies statement splitting respects strings, IF and REM and that carets point into the snippet
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"basic-interpreter/basic"
+)
+
+func TestPrepareOneLiner(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{name: "numbered snippet is unchanged", snippet: `10 PRINT "TEST": 20 END`, want: `10 PRINT "TEST": 20 END`},
+		{name: "statements split on colons", snippet: `A=1: PRINT A`, want: "10 A=1\n20 PRINT A\n"},
+		{name: "statements split on newlines", snippet: "A=1\nPRINT A\n", want: "10 A=1\n20 PRINT A\n"},
+		{name: "colon inside string kept", snippet: `PRINT "A:B": END`, want: "10 PRINT \"A:B\"\n20 END\n"},
+		{name: "rest of line after IF stays together", snippet: "X=1: IF X THEN PRINT 1: PRINT 2\nEND", want: "10 X=1\n20 IF X THEN PRINT 1: PRINT 2\n30 END\n"},
+		{name: "REM keeps colons", snippet: "REM A: B", want: "10 REM A: B\n"},
+		{name: "empty statements skipped", snippet: "PRINT 1::\n\nPRINT 2:", want: "10 PRINT 1\n20 PRINT 2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prepareOneLiner(tt.snippet).program; got != tt.want {
+				t.Errorf("prepareOneLiner(%q) = %q, want %q", tt.snippet, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOneLinerCaret(t *testing.T) {
+	o := prepareOneLiner("PRINT 1\nA=1:  PRINT (")
+
+	statement, ok := o.statementForSourceLine(3)
+	if !ok || statement != 2 {
+		t.Fatalf("statementForSourceLine(3) = %d, %v; want 2, true", statement, ok)
+	}
+	want := "A=1:  PRINT (\n      ^"
+	if got := o.caret(statement, 0); got != want {
+		t.Errorf("caret = %q, want %q", got, want)
+	}
+	// Column 10 of the generated "30 PRINT (" is the parenthesis
+	want = "A=1:  PRINT (\n            ^"
+	if got := o.caret(statement, 10); got != want {
+		t.Errorf("caret at column 10 = %q, want %q", got, want)
+	}
+	if _, ok := o.statementForSourceLine(4); ok {
+		t.Error("statementForSourceLine(4) should be out of range")
+	}
+}
+
+func TestDescribeRuntimeError(t *testing.T) {
+	o := prepareOneLiner("PRINT 1: PRINT 1/0")
+	got := o.describeRuntimeError(errors.New("?DIVISION BY ZERO ERROR IN 20"))
+	want := "?DIVISION BY ZERO ERROR IN 20\nPRINT 1: PRINT 1/0\n         ^"
+	if got != want {
+		t.Errorf("describeRuntimeError = %q, want %q", got, want)
+	}
+
+	numbered := prepareOneLiner("10 PRINT 1/0")
+	if got := numbered.describeRuntimeError(errors.New("?DIVISION BY ZERO ERROR IN 10")); got != "?DIVISION BY ZERO ERROR IN 10" {
+		t.Errorf("numbered snippet error = %q, want it unchanged", got)
+	}
+}
+
+func TestSyntaxErrorCaretInsideStatement(t *testing.T) {
+	o := prepareOneLiner("A=1: PRINT A+")
+	_, err := basic.Parse(o.program)
+	var syntaxErrs basic.ErrorList
+	if !errors.As(err, &syntaxErrs) {
+		t.Fatalf("Parse error = %v, want an ErrorList", err)
+	}
+
+	var out strings.Builder
+	printSyntaxErrors(&out, o.program, o, syntaxErrs)
+	want := "A=1: PRINT A+\n             ^\n"
+	if got := out.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("printSyntaxErrors = %q, want it to start with %q", got, want)
+	}
+}
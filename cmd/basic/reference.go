@@ -0,0 +1,45 @@
+// ABOUTME: The `reference` subcommand printing the statement and function registry as JSON
+// ABOUTME: Usage: basic reference [-dialect c64|extended]; feeds the docs site and editor tooling
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/parser"
+)
+
+// runReferenceCommand writes the reference registry, optionally limited to one dialect
+func runReferenceCommand(args []string) int {
+	fs := flag.NewFlagSet("reference", flag.ContinueOnError)
+	dialect := fs.String("dialect", "", "Only list entries available in this dialect (c64 or extended)")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: basic reference [-dialect c64|extended]")
+		return 1
+	}
+	if err := writeReference(os.Stdout, parser.Dialect(*dialect)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// writeReference encodes the registry entries of a dialect ("" for all) as indented JSON
+func writeReference(w io.Writer, dialect parser.Dialect) error {
+	if dialect != "" && dialect != parser.DialectC64 && dialect != parser.DialectExtended {
+		return fmt.Errorf("unknown dialect %q (want c64 or extended)", dialect)
+	}
+	entries := []parser.Reference{}
+	for _, ref := range parser.References() {
+		if dialect == "" || ref.InDialect(dialect) {
+			entries = append(entries, ref)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
@@ -0,0 +1,50 @@
+// ABOUTME: Tests for the reference subcommand
+// ABOUTME: Verifies the JSON layout and dialect filtering
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"basic-interpreter/parser"
+)
+
+func TestWriteReference(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeReference(&out, ""); err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(entries) != len(parser.References()) {
+		t.Errorf("got %d entries, want %d", len(entries), len(parser.References()))
+	}
+	first := entries[0]
+	if first["name"] != "PRINT" || first["kind"] != "statement" || first["token"] != "PRINT" {
+		t.Errorf("unexpected first entry: %v", first)
+	}
+}
+
+func TestWriteReferenceFiltersDialect(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeReference(&out, parser.DialectC64); err != nil {
+		t.Fatal(err)
+	}
+	var entries []parser.Reference
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range entries {
+		if ref.Name == "WHILE" {
+			t.Error("WHILE is not part of C64 BASIC")
+		}
+	}
+
+	if err := writeReference(&out, "qbasic"); err == nil {
+		t.Error("expected an error for an unknown dialect")
+	}
+}
@@ -0,0 +1,53 @@
+// ABOUTME: The `renum` subcommand renumbering a program file and the line numbers its jumps refer to
+// ABOUTME: Usage: basic renum [-start 10] [-step 10] [-w] FILE.bas prints the result, or rewrites the file with -w
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/renum"
+)
+
+// runRenumCommand renumbers a program file to stdout, or in place with -w
+func runRenumCommand(args []string) int {
+	fs := flag.NewFlagSet("renum", flag.ContinueOnError)
+	start := fs.Int("start", renum.DefaultStart, "Number of the first line")
+	step := fs.Int("step", renum.DefaultStep, "Gap between line numbers")
+	write := fs.Bool("w", false, "Write the result back to the file instead of printing it")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic renum [-start 10] [-step 10] [-w] FILE.bas")
+		return 1
+	}
+
+	path := fs.Arg(0)
+	if err := renumberFile(path, *start, *step, *write, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
+
+// renumberFile renumbers one program file, writing the result to out or, with write set, back to the file
+func renumberFile(path string, start, step int, write bool, out io.Writer) error {
+	content, err := readBasicFile(path, charset.Auto)
+	if err != nil {
+		return err
+	}
+	renumbered, err := renum.Source(content, start, step)
+	if err != nil {
+		return err
+	}
+	if !write {
+		_, err = io.WriteString(out, renumbered)
+		return err
+	}
+	return os.WriteFile(path, []byte(renumbered), 0o644)
+}
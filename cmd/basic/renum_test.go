@@ -0,0 +1,55 @@
+// ABOUTME: Tests for the renum subcommand
+// ABOUTME: Verifies printing, rewriting in place and rejecting bad arguments
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenumberFilePrints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prog.bas")
+	if err := os.WriteFile(path, []byte("5 GOSUB 7\n6 END\n7 RETURN\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := renumberFile(path, 100, 5, false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "100 GOSUB 110\n105 END\n110 RETURN\n"; out.String() != want {
+		t.Errorf("renumberFile printed %q, want %q", out.String(), want)
+	}
+}
+
+func TestRenumberFileWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prog.bas")
+	if err := os.WriteFile(path, []byte("1 GOTO 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := renumberFile(path, 10, 10, true, &out); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "10 GOTO 10\n" || out.Len() != 0 {
+		t.Errorf("file holds %q and %q was printed, want the renumbered file and no output", content, out.String())
+	}
+}
+
+func TestRunRenumCommandValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prog.bas")
+	if err := os.WriteFile(path, []byte("1 END\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{}, {"does-not-exist.bas"}, {"-step", "0", path}, {path, path}} {
+		if code := runRenumCommand(args); code != 1 {
+			t.Errorf("runRenumCommand(%v) = %d, want 1", args, code)
+		}
+	}
+}
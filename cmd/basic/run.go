@@ -0,0 +1,39 @@
+// ABOUTME: The `run` subcommand running a resource pack (.bpk) with the configuration in its manifest
+// ABOUTME: Usage: basic run PACK.bpk; output is printed once the run finishes
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/pack"
+)
+
+// runPackCommand runs one resource pack and prints its output
+func runPackCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic run PACK.bpk")
+		return 1
+	}
+	p, err := pack.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading pack %v\n", err)
+		return 1
+	}
+	return runPack(os.Stdout, os.Stderr, p)
+}
+
+// runPack runs a pack, writing its output to stdout and any error to stderr, and returns the exit code
+func runPack(stdout, stderr io.Writer, p *pack.Pack) int {
+	result, err := p.Run()
+	for _, line := range result.Output {
+		fmt.Fprintln(stdout, line)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", p.Manifest.Program, err)
+		return 1
+	}
+	return 0
+}
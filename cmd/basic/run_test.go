@@ -0,0 +1,29 @@
+// ABOUTME: Tests for the run subcommand
+// ABOUTME: Verifies that a resource pack runs with its manifest's input and reports runtime errors
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"basic-interpreter/pack"
+)
+
+func TestRunPack(t *testing.T) {
+	p := &pack.Pack{
+		Manifest: pack.Manifest{Program: "main.bas", Input: "ADA\n"},
+		Source:   "10 INPUT \"NAME\";N$\n20 PRINT \"HI \";N$\n30 PRINT 1/0\n",
+	}
+	var stdout, stderr bytes.Buffer
+
+	if code := runPack(&stdout, &stderr, p); code != 1 {
+		t.Errorf("runPack() = %d, want 1", code)
+	}
+	if got, want := stdout.String(), "NAMEHI ADA\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if got, want := stderr.String(), "main.bas: ?DIVISION BY ZERO ERROR IN 30\n"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
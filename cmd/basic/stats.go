@@ -0,0 +1,55 @@
+// ABOUTME: The `stats` subcommand printing size, structure and C64 memory statistics for a program file
+// ABOUTME: Usage: basic stats FILE.bas
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/stats"
+)
+
+// runStatsCommand analyzes one program file and prints its statistics
+func runStatsCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: basic stats FILE.bas")
+		return 1
+	}
+
+	content, err := readBasicFile(args[0], charset.Auto)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", args[0], err)
+		return 1
+	}
+	report, err := stats.Analyze(content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	printStats(os.Stdout, report)
+	return 0
+}
+
+// printStats writes a report as aligned label/value lines
+func printStats(w io.Writer, report stats.Report) {
+	depth := fmt.Sprint(report.GosubDepth)
+	if report.Recursive {
+		depth += "+ (recursive)"
+	}
+	fmt.Fprintf(w, "%-16s %d\n", "Lines:", report.Lines)
+	fmt.Fprintf(w, "%-16s %d bytes\n", "Program size:", report.TokenizedBytes)
+	fmt.Fprintf(w, "%-16s %d\n", "Variables:", report.Variables)
+	fmt.Fprintf(w, "%-16s %d\n", "Arrays:", report.Arrays)
+	fmt.Fprintf(w, "%-16s %s\n", "GOSUB depth:", depth)
+	fmt.Fprintf(w, "%-16s %d\n", "Jumps:", report.Jumps)
+	fmt.Fprintf(w, "%-16s %d\n", "Complexity:", report.Complexity)
+	fmt.Fprintf(w, "%-16s %d of %d bytes (%d%%): program %d, variables %d, arrays %d, strings %d\n",
+		"Memory estimate:", report.MemoryBytes(), stats.C64MemoryBytes, report.MemoryBytes()*100/stats.C64MemoryBytes,
+		report.TokenizedBytes, report.VariableBytes, report.ArrayBytes, report.StringBytes)
+	if report.ExceedsC64() {
+		fmt.Fprintf(w, "WARNING: exceeds the %d bytes free on a C64; it would stop with ?OUT OF MEMORY ERROR on real hardware\n", stats.C64MemoryBytes)
+	}
+}
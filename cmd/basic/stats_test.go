@@ -0,0 +1,46 @@
+// ABOUTME: Tests for the stats subcommand
+// ABOUTME: Verifies the report layout and argument validation
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"basic-interpreter/stats"
+)
+
+func TestPrintStats(t *testing.T) {
+	var out bytes.Buffer
+	printStats(&out, stats.Report{Lines: 3, TokenizedBytes: 43, Variables: 1, GosubDepth: 2, Recursive: true, Jumps: 2, Complexity: 2, VariableBytes: 7})
+
+	want := "Lines:           3\n" +
+		"Program size:    43 bytes\n" +
+		"Variables:       1\n" +
+		"Arrays:          0\n" +
+		"GOSUB depth:     2+ (recursive)\n" +
+		"Jumps:           2\n" +
+		"Complexity:      2\n" +
+		"Memory estimate: 50 of 38911 bytes (0%): program 43, variables 7, arrays 0, strings 0\n"
+	if out.String() != want {
+		t.Errorf("printStats output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrintStatsWarnsWhenOverC64Memory(t *testing.T) {
+	var out bytes.Buffer
+	printStats(&out, stats.Report{TokenizedBytes: 1000, ArrayBytes: 40000})
+
+	if !strings.Contains(out.String(), "WARNING: exceeds the 38911 bytes free on a C64") {
+		t.Errorf("expected a memory warning, got:\n%s", out.String())
+	}
+}
+
+func TestRunStatsCommandValidation(t *testing.T) {
+	for _, args := range [][]string{{}, {"a.bas", "b.bas"}, {"does-not-exist.bas"}} {
+		if code := runStatsCommand(args); code != 1 {
+			t.Errorf("runStatsCommand(%v) = %d, want 1", args, code)
+		}
+	}
+}
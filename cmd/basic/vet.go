@@ -0,0 +1,70 @@
+// ABOUTME: The `vet` subcommand reporting likely mistakes in a program file without running it
+// ABOUTME: Usage: basic vet [-shims] [-dialect c64|extended] FILE.bas... exits with 1 when any file has findings, like go vet
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"basic-interpreter/basic"
+	"basic-interpreter/charset"
+	"basic-interpreter/vet"
+)
+
+// runVetCommand checks each program file and prints its findings
+func runVetCommand(args []string) int {
+	fs := flag.NewFlagSet("vet", flag.ContinueOnError)
+	shims := fs.Bool("shims", false, "Accept statements from Apple II, ZX Spectrum and BBC BASIC listings")
+	dialectName := fs.String("dialect", "extended", "BASIC dialect: extended, or c64 for strict BASIC V2")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: basic vet [-shims] [-dialect c64|extended] FILE.bas...")
+		return 1
+	}
+
+	dialect, err := parseDialect(*dialectName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	options := []basic.Option{basic.WithDialect(dialect)}
+	if *shims {
+		options = append(options, basic.WithShims())
+	}
+	code := 0
+	for _, path := range fs.Args() {
+		content, err := readBasicFile(path, charset.Auto)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", path, err)
+			code = 1
+			continue
+		}
+		program, err := basic.Parse(content, options...)
+		var syntaxErrs basic.ErrorList
+		if errors.As(err, &syntaxErrs) {
+			for _, syntaxErr := range syntaxErrs {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", path, syntaxErr)
+			}
+			code = 1
+			continue
+		}
+		if printFindings(os.Stdout, path, vet.Program(program, dialect)) {
+			code = 1
+		}
+	}
+	return code
+}
+
+// printFindings writes one line per finding prefixed by the file name and reports whether there were any
+func printFindings(w io.Writer, path string, findings []vet.Finding) bool {
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s: %s\n", path, f)
+	}
+	return len(findings) > 0
+}
@@ -0,0 +1,50 @@
+// ABOUTME: Tests for the vet subcommand
+// ABOUTME: Verifies the finding lines and the exit codes for clean, suspicious and unreadable files
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"basic-interpreter/vet"
+)
+
+func TestPrintFindings(t *testing.T) {
+	var out bytes.Buffer
+	found := printFindings(&out, "game.bas", []vet.Finding{{Line: 20, Check: vet.UndefinedTarget, Message: "GOTO 90: there is no line 90"}})
+
+	want := "game.bas: line 20: GOTO 90: there is no line 90 (undefined-target)\n"
+	if !found || out.String() != want {
+		t.Errorf("printFindings = %v, %q; want true, %q", found, out.String(), want)
+	}
+}
+
+func TestRunVetCommandExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.bas")
+	if err := os.WriteFile(clean, []byte("10 PRINT \"HI\"\n20 END\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(dir, "bad.bas")
+	if err := os.WriteFile(bad, []byte("10 GOTO 90\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{clean}, 0},
+		{[]string{clean, bad}, 1},
+		{[]string{}, 1},
+		{[]string{"does-not-exist.bas"}, 1},
+	}
+	for _, tt := range tests {
+		if code := runVetCommand(tt.args); code != tt.want {
+			t.Errorf("runVetCommand(%v) = %d, want %d", tt.args, code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+// ABOUTME: Support library for Go programs generated by basic build: GOSUB and FOR stacks, DATA, arrays, I/O and builtins
+// ABOUTME: Generated code keeps variables and control flow native and calls here for the rest; BASIC errors unwind as panics
+
+package compiled
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// maxDepth bounds open FOR loops and GOSUB calls, like the interpreter
+const maxDepth = 100
+
+// failure carries a BASIC error up to Run
+type failure struct {
+	err error
+}
+
+// Function is a DEF FN function compiled to Go
+type Function func(m *Machine, arg types.Value) types.Value
+
+// call is an open GOSUB
+type call struct {
+	resume int // Return point to continue at
+	line   int
+}
+
+// loop is an open FOR loop
+type loop struct {
+	variable *types.Value
+	name     string
+	end      types.Value
+	step     types.Value
+	body     int // Loop body to continue at
+	line     int
+}
+
+// Machine is the state of a compiled program that is not a variable: output, arrays and builtins live in an
+// interpreter, which never runs the program itself
+type Machine struct {
+	Line      int // BASIC line being executed, for error messages
+	interp    *interpreter.Interpreter
+	calls     []call
+	loops     []loop
+	closed    interpreter.ClosedLoops // Loops that left loops, explaining NEXT WITHOUT FOR like the interpreter
+	data      []types.Value
+	next      int
+	functions map[string]Function
+}
+
+// New creates a machine printing and reading through rt
+func New(rt runtime.Runtime) *Machine {
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetMaxSteps(0)
+	return &Machine{interp: interp, functions: make(map[string]Function)}
+}
+
+// SetScreenWidth sets the screen width used for line wrapping and TAB bounds (0 disables wrapping)
+func (m *Machine) SetScreenWidth(width int) {
+	m.interp.SetScreenWidth(width)
+}
+
+// SetDialect selects the dialect the interpreter names clock variables, arrays and functions in
+func (m *Machine) SetDialect(d parser.Dialect) {
+	m.interp.SetDialect(d)
+}
+
+// Run runs a compiled program, returning the BASIC error that stopped it with its line number
+func (m *Machine) Run(program func(m *Machine)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			f, ok := r.(failure)
+			if !ok {
+				panic(r)
+			}
+			err = interpreter.AtLine(f.err, m.Line, -1)
+		}
+	}()
+	program(m)
+	return nil
+}
+
+// check stops the program when err is set
+func check(err error) {
+	if err != nil {
+		panic(failure{err})
+	}
+}
+
+// checked returns value, or stops the program when err is set
+func checked(value types.Value, err error) types.Value {
+	check(err)
+	return value
+}
+
+// Fail stops the program with a BASIC error message; it is typed as a value to stand in for expressions
+func (m *Machine) Fail(message string) types.Value {
+	panic(failure{errors.New(message)})
+}
+
+// NoLine stops the program for a jump to a line that does not exist
+func (m *Machine) NoLine(line int) {
+	check(fmt.Errorf("%w: NO LINE %d", interpreter.ErrUndefinedStatement, line))
+}
+
+// Stop ends the program as STOP does, reporting where
+func (m *Machine) Stop() {
+	check(m.interp.PrintLine(fmt.Sprintf("BREAK IN %d", m.Line)))
+}
+
+// Number checks that value can be stored in a numeric variable
+func Number(value types.Value) types.Value {
+	if value.Type != types.NumberType {
+		check(types.ErrTypeMismatch)
+	}
+	return value
+}
+
+// String checks that value can be stored in a string variable
+func String(value types.Value) types.Value {
+	if value.Type != types.StringType {
+		check(types.ErrTypeMismatch)
+	}
+	return value
+}
+
+// Gosub records a return point before the generated code jumps to the subroutine
+func (m *Machine) Gosub(resume int) {
+	if len(m.calls) >= maxDepth {
+		check(interpreter.ErrStackOverflow)
+	}
+	m.calls = append(m.calls, call{resume: resume, line: m.Line})
+}
+
+// Return closes the innermost GOSUB and returns its return point
+func (m *Machine) Return() int {
+	if len(m.calls) == 0 {
+		check(interpreter.ErrReturnWithoutGosub)
+	}
+	c := m.calls[len(m.calls)-1]
+	m.calls = m.calls[:len(m.calls)-1]
+	m.Line = c.line
+	return c.resume
+}
+
+// On returns the 1-based entry an ON selector picks out of n, or 0 when it is out of range
+func (m *Machine) On(selector types.Value, n int) int {
+	if selector.Type != types.NumberType {
+		check(types.ErrTypeMismatch)
+	}
+	idx := int(selector.Number)
+	if idx <= 0 || idx > n {
+		return 0
+	}
+	return idx
+}
+
+// OnGosub is On for ON GOSUB: it records the return point when the selector picks an entry
+func (m *Machine) OnGosub(selector types.Value, n, resume int) int {
+	idx := m.On(selector, n)
+	if idx > 0 {
+		m.Gosub(resume)
+	}
+	return idx
+}
+
+// For sets a loop variable and opens a loop whose body is body. Like the interpreter a FOR on a variable
+// whose loop is still open replaces that loop and those inside it.
+func (m *Machine) For(variable *types.Value, name string, start, end, step types.Value, body int) {
+	if step.Type != types.NumberType {
+		check(fmt.Errorf("TYPE MISMATCH ERROR"))
+	}
+	if strings.HasSuffix(name, "$") {
+		start = String(start)
+	} else {
+		start = Number(start)
+	}
+	*variable = start
+	if step.Number == 0 {
+		check(fmt.Errorf("%w: FOR %s WITH STEP 0", interpreter.ErrIllegalQuantity, name))
+	}
+	for n := len(m.loops) - 1; n >= 0; n-- {
+		if m.loops[n].variable == variable {
+			m.loops = m.loops[:n]
+			break
+		}
+	}
+	if len(m.loops) >= maxDepth {
+		check(fmt.Errorf("%w: TOO MANY OPEN FOR LOOPS AT FOR %s", interpreter.ErrStackOverflow, name))
+	}
+	m.loops = append(m.loops, loop{variable: variable, name: name, end: end, step: step, body: body, line: m.Line})
+	m.closed.Open(name)
+}
+
+// Next steps the loop of variable, named name (nil and "" for the innermost), dropping loops opened inside it.
+// It reports whether the loop goes round again; Loop then tells where its body starts.
+func (m *Machine) Next(variable *types.Value, name string) bool {
+	n := len(m.loops) - 1
+	if variable != nil {
+		for n >= 0 && m.loops[n].variable != variable {
+			n--
+		}
+	}
+	if n < 0 {
+		check(m.closed.NextWithoutFor(name))
+	}
+	for k := len(m.loops) - 1; k > n; k-- {
+		m.closed.Close(m.loops[k].name, m.loops[k].line, m.loops[n].name, m.Line)
+	}
+	m.loops = m.loops[:n+1]
+	l := m.loops[n]
+
+	value := checked(l.variable.Add(l.step))
+	cmp := "<="
+	if l.step.Number < 0 {
+		cmp = ">="
+	}
+	more, err := value.Compare(l.end, cmp)
+	check(err)
+	// The variable keeps the stepped value even when the loop ends
+	*l.variable = value
+	if !more {
+		m.closed.Close(l.name, l.line, "", m.Line)
+		m.loops = m.loops[:n]
+	}
+	return more
+}
+
+// Loop returns the body of the innermost loop and moves back to its line
+func (m *Machine) Loop() int {
+	l := m.loops[len(m.loops)-1]
+	m.Line = l.line
+	return l.body
+}
+
+// Data sets the values READ takes in order
+func (m *Machine) Data(values ...types.Value) {
+	m.data = values
+	m.next = 0
+}
+
+// Read returns the next DATA value
+func (m *Machine) Read() types.Value {
+	if m.next >= len(m.data) {
+		check(interpreter.ErrOutOfData)
+	}
+	m.next++
+	return m.data[m.next-1]
+}
+
+// Dim declares an array with the given upper bounds
+func (m *Machine) Dim(name string, sizes ...types.Value) {
+	dims := make([]int, len(sizes))
+	for n, size := range sizes {
+		if size.Type != types.NumberType {
+			check(types.ErrTypeMismatch)
+		}
+		if size.Number < 0 || float64(int(size.Number)) != size.Number {
+			check(fmt.Errorf("?ILLEGAL QUANTITY ERROR"))
+		}
+		dims[n] = int(size.Number)
+	}
+	check(m.interp.DeclareArray(name, dims, strings.HasSuffix(name, "$")))
+}
+
+// Indexes converts array indexes, which must be whole numbers of zero or more
+func (m *Machine) Indexes(values ...types.Value) []int {
+	idxs := make([]int, len(values))
+	for n, value := range values {
+		if value.Type != types.NumberType {
+			check(types.ErrTypeMismatch)
+		}
+		if value.Number < 0 || float64(int(value.Number)) != value.Number {
+			check(fmt.Errorf("?ILLEGAL QUANTITY ERROR"))
+		}
+		idxs[n] = int(value.Number)
+	}
+	return idxs
+}
+
+// Element reads an array element
+func (m *Machine) Element(name string, idxs []int) types.Value {
+	return checked(m.interp.GetArrayElement(name, idxs))
+}
+
+// SetElement assigns an array element
+func (m *Machine) SetElement(name string, idxs []int, value types.Value) {
+	check(m.interp.SetArrayElement(name, idxs, value))
+}
+
+// Variable reads a clock variable (TI, TI$ or TIMER)
+func (m *Machine) Variable(name string) types.Value {
+	return checked(m.interp.GetVariable(name))
+}
+
+// SetVariable assigns a clock variable
+func (m *Machine) SetVariable(name string, value types.Value) {
+	check(m.interp.SetVariable(name, value))
+}
+
+// Print prints PRINT items with their separators
+func (m *Machine) Print(separators []string, noNewline bool, values ...types.Value) {
+	check(parser.PrintValues(m.interp, values, separators, noNewline))
+}
+
+// PrintLine prints a value followed by a newline
+func (m *Machine) PrintLine(value types.Value) {
+	check(m.interp.PrintLine(value.ToString()))
+}
+
+// Input reads one value for each of the named targets as INPUT does
+func (m *Machine) Input(prompt string, names ...string) []types.Value {
+	values, err := parser.InputValues(m.interp, prompt, names)
+	check(err)
+	return values
+}
+
+// Key reads a keystroke for GET into the named target
+func (m *Machine) Key(name string) types.Value {
+	return checked(parser.KeyValue(m.interp, name))
+}
+
+// Poke writes emulated memory
+func (m *Machine) Poke(address, value types.Value) {
+	idxs := m.Indexes(address, value)
+	check(m.interp.Poke(idxs[0], idxs[1]))
+}
+
+// Locate moves the cursor; origin is the number of the top row and left column
+func (m *Machine) Locate(row, column types.Value, origin int) {
+	pos := m.Indexes(row, column)
+	check(m.interp.LocateCursor(pos[0]-origin, pos[1]-origin))
+}
+
+// ClearScreen clears the screen
+func (m *Machine) ClearScreen() {
+	check(m.interp.ClearScreen())
+}
+
+// Define defines or redefines a DEF FN function
+func (m *Machine) Define(name string, fn Function) {
+	m.functions[strings.ToUpper(name)] = fn
+}
+
+// CallFn calls a DEF FN function
+func (m *Machine) CallFn(name string, args ...types.Value) types.Value {
+	fn, ok := m.functions[strings.ToUpper(name)]
+	if !ok {
+		check(fmt.Errorf("?SYNTAX ERROR: undefined function %s", name))
+	}
+	if len(args) != 1 {
+		check(fmt.Errorf("?SYNTAX ERROR: %s expects 1 argument", name))
+	}
+	return Number(fn(m, args[0]))
+}
+
+// Call calls a built-in function
+func (m *Machine) Call(name string, args ...types.Value) types.Value {
+	return checked(m.interp.CallFunction(name, args))
+}
+
+// Add adds numbers or concatenates strings
+func Add(left, right types.Value) types.Value {
+	return checked(left.Add(right))
+}
+
+// Subtract subtracts numbers
+func Subtract(left, right types.Value) types.Value {
+	return checked(left.Subtract(right))
+}
+
+// Multiply multiplies numbers
+func Multiply(left, right types.Value) types.Value {
+	return checked(left.Multiply(right))
+}
+
+// Divide divides numbers
+func Divide(left, right types.Value) types.Value {
+	return checked(left.Divide(right))
+}
+
+// Power raises left to the power of right
+func Power(left, right types.Value) types.Value {
+	return checked(left.Power(right))
+}
+
+// And is bitwise AND of whole numbers
+func And(left, right types.Value) types.Value {
+	ln, rn := checkedNumber(left), checkedNumber(right)
+	return types.NewNumberValue(float64(int(ln) & int(rn)))
+}
+
+// Or is bitwise OR of whole numbers
+func Or(left, right types.Value) types.Value {
+	ln, rn := checkedNumber(left), checkedNumber(right)
+	return types.NewNumberValue(float64(int(ln) | int(rn)))
+}
+
+// checkedNumber converts an operand of AND or OR
+func checkedNumber(value types.Value) float64 {
+	n, err := value.ToNumber()
+	check(err)
+	return n
+}
+
+// Compare applies a comparison operator, giving BASIC's 1 or 0
+func Compare(left, right types.Value, operator string) types.Value {
+	result, err := left.Compare(right, operator)
+	check(err)
+	if result {
+		return types.NewNumberValue(1)
+	}
+	return types.NewNumberValue(0)
+}
+
+// Negate is unary minus
+func Negate(operand types.Value) types.Value {
+	if operand.Type != types.NumberType {
+		check(fmt.Errorf("cannot negate non-numeric value"))
+	}
+	return types.NewNumberValue(-operand.Number)
+}
+
+// Plus is unary plus
+func Plus(operand types.Value) types.Value {
+	if operand.Type != types.NumberType {
+		check(fmt.Errorf("cannot apply unary plus to non-numeric value"))
+	}
+	return operand
+}
+
+// Not is bitwise NOT
+func Not(operand types.Value) types.Value {
+	if operand.Type != types.NumberType {
+		check(types.ErrTypeMismatch)
+	}
+	return types.NewNumberValue(float64(^int(operand.Number)))
+}
@@ -0,0 +1,101 @@
+package compiled
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestRunReportsErrorsAtLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		program func(m *Machine)
+		want    string
+	}{
+		{"type mismatch", func(m *Machine) { m.Line = 20; String(types.NewNumberValue(1)) }, "?TYPE MISMATCH ERROR IN 20"},
+		{"division by zero", func(m *Machine) { m.Line = 30; Divide(types.NewNumberValue(1), types.NewNumberValue(0)) }, "?DIVISION BY ZERO ERROR IN 30"},
+		{"out of data", func(m *Machine) { m.Line = 40; m.Read() }, "?OUT OF DATA ERROR IN 40"},
+		{"return without gosub", func(m *Machine) { m.Line = 50; m.Return() }, "?RETURN WITHOUT GOSUB ERROR IN 50"},
+		{"missing line", func(m *Machine) { m.Line = 60; m.NoLine(999) }, "?UNDEFINED STATEMENT ERROR IN 60: NO LINE 999"},
+		{"next for loop closed by outer next", func(m *Machine) {
+			var i, j types.Value
+			m.Line = 10
+			m.For(&i, "I", types.NewNumberValue(1), types.NewNumberValue(2), types.NewNumberValue(1), 1)
+			m.Line = 20
+			m.For(&j, "J", types.NewNumberValue(1), types.NewNumberValue(2), types.NewNumberValue(1), 2)
+			m.Line = 30
+			m.Next(&i, "I")
+			m.Line = 40
+			m.Next(&j, "J")
+		}, "?NEXT WITHOUT FOR ERROR IN 40: FOR J AT LINE 20 WAS CLOSED BY NEXT I AT LINE 30"},
+		{"bare next after loop finished", func(m *Machine) {
+			var k types.Value
+			m.Line = 10
+			m.For(&k, "K", types.NewNumberValue(1), types.NewNumberValue(1), types.NewNumberValue(1), 1)
+			m.Line = 20
+			m.Next(nil, "")
+			m.Line = 30
+			m.Next(nil, "")
+		}, "?NEXT WITHOUT FOR ERROR IN 30: FOR K AT LINE 10 HAS ALREADY FINISHED"},
+		{"no error", func(m *Machine) {}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(runtime.NewTestRuntime()).Run(tt.program)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestForNextSteps(t *testing.T) {
+	m := New(runtime.NewTestRuntime())
+	var i, j types.Value
+	var bodies []int
+	require.NoError(t, m.Run(func(m *Machine) {
+		m.For(&i, "I", types.NewNumberValue(1), types.NewNumberValue(2), types.NewNumberValue(1), 1)
+		m.For(&j, "J", types.NewNumberValue(5), types.NewNumberValue(1), types.NewNumberValue(-2), 2)
+		for m.Next(&j, "J") {
+			bodies = append(bodies, m.Loop())
+		}
+		// J has finished, so a NEXT without a variable steps I
+		for m.Next(nil, "") {
+			bodies = append(bodies, m.Loop())
+		}
+	}))
+	assert.Equal(t, []int{2, 2, 1}, bodies)
+	assert.Equal(t, 3.0, i.Number)
+	assert.Equal(t, -1.0, j.Number)
+}
+
+func TestGosubRestoresLine(t *testing.T) {
+	m := New(runtime.NewTestRuntime())
+	require.NoError(t, m.Run(func(m *Machine) {
+		m.Line = 10
+		m.Gosub(7)
+		m.Line = 100
+		assert.Equal(t, 7, m.Return())
+		assert.Equal(t, 10, m.Line)
+	}))
+}
+
+func TestPrintAndInput(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	rt.SetInput([]string{"ADA,36"})
+	m := New(rt)
+	require.NoError(t, m.Run(func(m *Machine) {
+		in := m.Input("WHO", "N$", "A")
+		m.Print([]string{";", ","}, true, in[0], in[1])
+		m.PrintLine(types.NewStringValue("!"))
+	}))
+	assert.Equal(t, "WHOADA 36    !\n", strings.Join(rt.GetOutput(), ""))
+}
@@ -0,0 +1,63 @@
+// ABOUTME: Curated classic BASIC programs embedded in the binary
+// ABOUTME: Serves the `examples` CLI subcommand and doubles as a smoke-test corpus
+
+package examples
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed programs/*.bas
+var programs embed.FS
+
+// Example is one embedded program
+type Example struct {
+	Name   string // File name without extension, used to select the example
+	Title  string // Taken from the program's leading REM line
+	Source string // Program text
+}
+
+// List returns all embedded examples sorted by name
+func List() []Example {
+	entries, err := programs.ReadDir("programs")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".bas"))
+	}
+	sort.Strings(names)
+
+	list := make([]Example, 0, len(names))
+	for _, name := range names {
+		if example, ok := Get(name); ok {
+			list = append(list, example)
+		}
+	}
+	return list
+}
+
+// Get returns the example with the given name (case-insensitive)
+func Get(name string) (Example, bool) {
+	name = strings.ToLower(name)
+	data, err := programs.ReadFile(path.Join("programs", name+".bas"))
+	if err != nil {
+		return Example{}, false
+	}
+	source := string(data)
+	return Example{Name: name, Title: title(source), Source: source}, true
+}
+
+// title extracts the text of a REM on the first line, falling back to an empty string
+func title(source string) string {
+	first, _, _ := strings.Cut(source, "\n")
+	_, rem, ok := strings.Cut(first, "REM")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(rem)
+}
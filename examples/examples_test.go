@@ -0,0 +1,69 @@
+package examples
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+func TestList(t *testing.T) {
+	var names []string
+	for _, example := range List() {
+		names = append(names, example.Name)
+		assert.NotEmpty(t, example.Title, example.Name)
+	}
+	assert.Equal(t, []string{"bubblesort", "guess", "sieve", "stars"}, names)
+}
+
+func TestGet(t *testing.T) {
+	example, ok := Get("SIEVE")
+	require.True(t, ok)
+	assert.Equal(t, "SIEVE OF ERATOSTHENES", example.Title)
+
+	_, ok = Get("missing")
+	assert.False(t, ok)
+}
+
+// TestExamplesRun is a smoke test: every embedded program must parse and run to completion
+func TestExamplesRun(t *testing.T) {
+	inputs := map[string][]string{
+		"guess": {"50", "25", "75", "12", "88", "6", "94", "N"},
+	}
+
+	for _, example := range List() {
+		t.Run(example.Name, func(t *testing.T) {
+			p := parser.New(lexer.New(example.Source))
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+
+			rt := runtime.NewTestRuntime()
+			rt.SetInput(inputs[example.Name])
+			interp := interpreter.NewInterpreter(rt)
+			interp.SetMaxSteps(100000)
+			require.NoError(t, interp.Execute(program))
+			assert.NotEmpty(t, rt.GetOutput())
+		})
+	}
+}
+
+func TestExamples_BubbleSortOutput(t *testing.T) {
+	example, ok := Get("bubblesort")
+	require.True(t, ok)
+
+	p := parser.New(lexer.New(example.Source))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	rt := runtime.NewTestRuntime()
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetMaxSteps(100000)
+	require.NoError(t, interp.Execute(program))
+	assert.Contains(t, strings.Join(rt.GetOutput(), ""), "AFTER: 1 3 7 19 23 42 56 88\n")
+}
@@ -0,0 +1,18 @@
+10 REM BUBBLE SORT
+20 N = 8
+30 DIM A(8)
+40 FOR I = 1 TO N: READ A(I): NEXT I
+50 PRINT "BEFORE:";
+60 GOSUB 200
+70 FOR I = 1 TO N-1
+80 FOR J = 1 TO N-I
+90 IF A(J) > A(J+1) THEN T = A(J): A(J) = A(J+1): A(J+1) = T
+100 NEXT J
+110 NEXT I
+120 PRINT "AFTER:";
+130 GOSUB 200
+140 END
+200 FOR K = 1 TO N: PRINT " ";A(K);: NEXT K
+210 PRINT
+220 RETURN
+300 DATA 42, 7, 19, 3, 88, 23, 1, 56
@@ -0,0 +1,13 @@
+10 REM GUESS THE NUMBER
+20 PRINT "I AM THINKING OF A NUMBER FROM 1 TO 100."
+30 N = INT(RND(1)*100)+1
+40 FOR T = 1 TO 7
+50 INPUT "YOUR GUESS"; G
+60 IF G = N THEN PRINT "RIGHT! YOU GOT IT IN";T;"TRIES.": GOTO 120
+70 IF G < N THEN PRINT "HIGHER"
+80 IF G > N THEN PRINT "LOWER"
+90 NEXT T
+100 PRINT "OUT OF GUESSES. IT WAS";N
+120 INPUT "PLAY AGAIN (Y/N)"; A$
+130 IF A$ = "Y" THEN 30
+140 END
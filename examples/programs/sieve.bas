@@ -0,0 +1,10 @@
+10 REM SIEVE OF ERATOSTHENES
+20 M = 100
+30 DIM P(100)
+40 FOR I = 2 TO M
+50 IF P(I) THEN 90
+60 PRINT I;" ";
+70 J = I*I
+80 IF J <= M THEN P(J) = 1: J = J+I: GOTO 80
+90 NEXT I
+100 PRINT
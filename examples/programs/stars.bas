@@ -0,0 +1,8 @@
+10 REM STAR FIELD
+20 FOR R = 1 TO 12
+30 C = INT(RND(1)*30)
+40 S$ = "*"
+50 IF RND(1) > 0.7 THEN S$ = "+"
+60 IF RND(1) > 0.9 THEN S$ = "."
+70 PRINT TAB(C); S$
+80 NEXT R
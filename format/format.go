@@ -0,0 +1,482 @@
+// ABOUTME: Source formatter for basic fmt: parses a program and prints its AST back as canonical source
+// ABOUTME: Keywords are upper case, line numbers right-aligned and parentheses kept only where precedence needs them
+
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// Options configure how the program is parsed
+type Options struct {
+	Shims         bool           // Accept statements from other 8-bit dialects, as parser.SetShims
+	Abbreviations bool           // Accept C64 keyword abbreviations such as ? for PRINT
+	Crunch        bool           // Split keywords written without spaces off names, as lexer.SetCrunch
+	Dialect       parser.Dialect // Dialect to parse, "" for the default extended dialect; hex literals print in decimal
+}
+
+// Source returns src pretty-printed: one statement list per line in line-number order, statements joined by ": ",
+// no spaces inside expressions except around AND, OR and NOT. A program that does not parse returns its *parser.ParseError.
+func Source(src string, opts Options) (string, error) {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	l := lexer.New(src)
+	l.SetAbbreviations(opts.Abbreviations)
+	l.SetCrunch(opts.Crunch)
+	if opts.Dialect != "" {
+		l.SetDialect(opts.Dialect)
+	}
+	p := parser.New(l)
+	p.SetShims(opts.Shims)
+	dialect := parser.DialectExtended
+	if opts.Dialect != "" {
+		p.SetDialect(opts.Dialect)
+		dialect = opts.Dialect
+	}
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		return "", e
+	}
+
+	width := 0
+	for _, line := range program.Lines {
+		width = max(width, len(strconv.Itoa(line.Number)))
+	}
+	var sb strings.Builder
+	for _, line := range program.Lines {
+		pr := &printer{dialect: dialect}
+		text := pr.statements(line.Statements)
+		if pr.err != nil {
+			return "", fmt.Errorf("line %d: %w", line.Number, pr.err)
+		}
+		fmt.Fprintf(&sb, "%*d", width, line.Number)
+		if text != "" {
+			sb.WriteString(" " + text)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// Operator precedences, as the parser assigns them
+var precedences = map[string]int{
+	"OR":  int(parser.LOGICAL_OR),
+	"AND": int(parser.LOGICAL_AND),
+	"=":   int(parser.COMPARE),
+	"<>":  int(parser.COMPARE),
+	"<":   int(parser.COMPARE),
+	">":   int(parser.COMPARE),
+	"<=":  int(parser.COMPARE),
+	">=":  int(parser.COMPARE),
+	"+":   int(parser.SUM),
+	"-":   int(parser.SUM),
+	"*":   int(parser.PRODUCT),
+	"/":   int(parser.PRODUCT),
+	"^":   int(parser.POWER),
+}
+
+// printer renders the statements of one line
+type printer struct {
+	dialect parser.Dialect // Decides how string literals are quoted
+	err     error          // First node that could not be printed
+}
+
+// statements joins statements with ": "
+func (pr *printer) statements(stmts []parser.Statement) string {
+	parts := make([]string, len(stmts))
+	for n, stmt := range stmts {
+		parts[n] = pr.statement(stmt)
+	}
+	return strings.Join(parts, ": ")
+}
+
+// branch prints the statements of an IF branch, a leading GOTO as the bare line number
+func (pr *printer) branch(stmts []parser.Statement) string {
+	if len(stmts) > 0 {
+		if g, ok := stmts[0].(*parser.GotoStatement); ok {
+			rest := pr.statements(stmts[1:])
+			if rest == "" {
+				return strconv.Itoa(g.TargetLine)
+			}
+			return strconv.Itoa(g.TargetLine) + ": " + rest
+		}
+	}
+	return pr.statements(stmts)
+}
+
+func (pr *printer) statement(stmt parser.Statement) string {
+	switch s := stmt.(type) {
+	case *parser.PrintStatement:
+		return pr.print(s)
+	case *parser.LetStatement:
+		if s.Declared {
+			return "LET " + s.Variable + "=" + pr.expression(s.Expression)
+		}
+		return s.Variable + "=" + pr.expression(s.Expression)
+	case *parser.ArraySetStatement:
+		return s.Name + "(" + pr.list(s.Indexes) + ")=" + pr.expression(s.Expression)
+	case *parser.ConstStatement:
+		return "CONST " + s.Name + "=" + pr.expression(s.Expression)
+	case *parser.OptionStatement:
+		return "OPTION " + s.Name
+	case *parser.InputStatement:
+		if s.Prompt != "" {
+			return "INPUT " + pr.quote(s.Prompt) + ";" + pr.targets(s.Targets)
+		}
+		return "INPUT " + pr.targets(s.Targets)
+	case *parser.GetStatement:
+		return "GET " + pr.targets(s.Targets)
+	case *parser.OpenStatement:
+		return pr.open(s)
+	case *parser.CloseStatement:
+		return "CLOSE " + pr.expression(s.File)
+	case *parser.PrintFileStatement:
+		if len(s.Items) == 0 {
+			return "PRINT#" + pr.expression(s.File)
+		}
+		return "PRINT#" + pr.expression(s.File) + "," + pr.items(s.Items, s.Separators, s.NoNewline)
+	case *parser.InputFileStatement:
+		return "INPUT#" + pr.expression(s.File) + "," + pr.targets(s.Targets)
+	case *parser.GetFileStatement:
+		return "GET#" + pr.expression(s.File) + "," + pr.targets(s.Targets)
+	case *parser.ReadStatement:
+		return "READ " + pr.targets(s.Targets)
+	case *parser.SwapStatement:
+		return "SWAP " + pr.targets([]parser.ReadTarget{s.Left, s.Right})
+	case *parser.DataStatement:
+		items := make([]string, len(s.Values))
+		for n, v := range s.Values {
+			items[n] = pr.dataItem(v)
+		}
+		return "DATA " + strings.Join(items, ",")
+	case *parser.DimStatement:
+		decls := make([]string, len(s.Declarations))
+		for n, d := range s.Declarations {
+			decls[n] = d.Name + "(" + pr.list(d.Sizes) + ")"
+		}
+		return "DIM " + strings.Join(decls, ",")
+	case *parser.DefFnStatement:
+		return "DEF " + s.Name + "(" + s.Param + ")=" + pr.expression(s.Body)
+	case *parser.IfStatement:
+		text := "IF " + pr.expression(s.Condition) + " THEN " + pr.branch(s.ThenStmts)
+		if len(s.ElseStmts) > 0 {
+			text += " ELSE " + pr.branch(s.ElseStmts)
+		}
+		return text
+	case *parser.GotoStatement:
+		return "GOTO " + strconv.Itoa(s.TargetLine)
+	case *parser.GosubStatement:
+		return "GOSUB " + strconv.Itoa(s.TargetLine)
+	case *parser.OnGotoStatement:
+		return "ON " + pr.expression(s.Selector) + " GOTO " + lineList(s.TargetLines)
+	case *parser.OnGosubStatement:
+		return "ON " + pr.expression(s.Selector) + " GOSUB " + lineList(s.TargetLines)
+	case *parser.ReturnStatement:
+		return "RETURN"
+	case *parser.ForStatement:
+		text := "FOR " + s.Variable + "=" + pr.expression(s.StartValue) + " TO " + pr.expression(s.EndValue)
+		if s.StepValue != nil {
+			text += " STEP " + pr.expression(s.StepValue)
+		}
+		return text
+	case *parser.NextStatement:
+		if s.Variable != "" {
+			return "NEXT " + s.Variable
+		}
+		return "NEXT"
+	case *parser.WhileStatement:
+		return "WHILE " + pr.expression(s.Condition)
+	case *parser.WendStatement:
+		return "WEND"
+	case *parser.DoStatement:
+		return "DO"
+	case *parser.LoopStatement:
+		switch {
+		case s.Condition == nil:
+			return "LOOP"
+		case s.Until:
+			return "LOOP UNTIL " + pr.expression(s.Condition)
+		}
+		return "LOOP WHILE " + pr.expression(s.Condition)
+	case *parser.LocateStatement:
+		return "LOCATE " + pr.expression(s.Row) + "," + pr.expression(s.Column)
+	case *parser.PokeStatement:
+		return "POKE " + pr.expression(s.Address) + "," + pr.expression(s.Value)
+	case *parser.TimerStatement:
+		text := "AFTER "
+		if s.Repeat {
+			text = "EVERY "
+		}
+		text += pr.expression(s.Ticks)
+		if s.Timer != nil {
+			text += "," + pr.expression(s.Timer)
+		}
+		return text + " GOSUB " + strconv.Itoa(s.TargetLine)
+	case *parser.TraceStatement:
+		if s.On {
+			return "TRON"
+		}
+		return "TROFF"
+	case *parser.RunStatement:
+		if s.HasStart {
+			return "RUN " + strconv.Itoa(s.StartLine)
+		}
+		return "RUN"
+	case *parser.ListStatement:
+		return listStatement(s.Range)
+	case *parser.HostStatement:
+		if len(s.Arguments) > 0 {
+			return s.Name + " " + pr.list(s.Arguments)
+		}
+		return s.Name
+	case *parser.RemStatement:
+		if s.Text == "" || strings.HasPrefix(s.Text, " ") || strings.HasPrefix(s.Text, "\t") {
+			return "REM" + s.Text
+		}
+		return "REM " + s.Text
+	case *parser.ClearScreenStatement:
+		return "CLS"
+	case *parser.EndStatement:
+		return "END"
+	case *parser.StopStatement:
+		return "STOP"
+	case *parser.ClrStatement:
+		return "CLR"
+	case *parser.NewStatement:
+		return "NEW"
+	}
+	pr.fail(stmt)
+	return ""
+}
+
+// print renders a PRINT statement; items are joined by their separators, ';' unless a ',' was written
+func (pr *printer) print(s *parser.PrintStatement) string {
+	text := "PRINT"
+	if s.At != nil {
+		text += " AT " + pr.expression(s.At.Row) + "," + pr.expression(s.At.Column)
+	}
+	if len(s.Items) == 0 {
+		if lit, ok := s.Expression.(*parser.StringLiteral); ok && lit.Value == "" {
+			return text
+		}
+		if s.At != nil {
+			return text + ";" + pr.expression(s.Expression)
+		}
+		return text + " " + pr.expression(s.Expression)
+	}
+	if lit, ok := s.Items[0].(*parser.StringLiteral); ok && s.At != nil && len(s.Items) == 1 && lit.Value == "" && s.NoNewline {
+		return text + ";"
+	}
+
+	if s.At != nil {
+		text += ";"
+	} else {
+		text += " "
+	}
+	return text + pr.items(s.Items, s.Separators, s.NoNewline)
+}
+
+// items prints the items of PRINT or PRINT# with their separators
+func (pr *printer) items(items []parser.Expression, separators []string, noNewline bool) string {
+	var text string
+	for n, item := range items {
+		text += pr.expression(item)
+		if n < len(items)-1 || noNewline {
+			text += separator(separators, n)
+		}
+	}
+	return text
+}
+
+// separator returns the separator written after PRINT item n
+func separator(separators []string, n int) string {
+	if n < len(separators) && separators[n] != "" {
+		return separators[n]
+	}
+	return ";"
+}
+
+// open prints OPEN with the arguments it was given
+func (pr *printer) open(s *parser.OpenStatement) string {
+	args := []parser.Expression{s.File}
+	for _, arg := range []parser.Expression{s.Device, s.Channel, s.Name} {
+		if arg == nil {
+			break
+		}
+		args = append(args, arg)
+	}
+	return "OPEN " + pr.list(args)
+}
+
+// listStatement renders LIST with its range in the shortest form that parses back to it
+func listStatement(r parser.LineRange) string {
+	switch {
+	case r == parser.AllLines:
+		return "LIST"
+	case r.From == r.To:
+		return fmt.Sprintf("LIST %d", r.From)
+	case r.From == 0:
+		return fmt.Sprintf("LIST -%d", r.To)
+	case r.To == parser.MaxLineNumber:
+		return fmt.Sprintf("LIST %d-", r.From)
+	}
+	return fmt.Sprintf("LIST %d-%d", r.From, r.To)
+}
+
+// targets renders the variables and array elements of INPUT, GET, READ and SWAP
+func (pr *printer) targets(targets []parser.ReadTarget) string {
+	parts := make([]string, len(targets))
+	for n, t := range targets {
+		parts[n] = t.Name
+		if len(t.Indices) > 0 {
+			parts[n] += "(" + pr.list(t.Indices) + ")"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// list joins expressions with commas
+func (pr *printer) list(exprs []parser.Expression) string {
+	parts := make([]string, len(exprs))
+	for n, e := range exprs {
+		parts[n] = pr.expression(e)
+	}
+	return strings.Join(parts, ",")
+}
+
+// lineList joins line numbers with commas
+func lineList(lines []int) string {
+	parts := make([]string, len(lines))
+	for n, line := range lines {
+		parts[n] = strconv.Itoa(line)
+	}
+	return strings.Join(parts, ",")
+}
+
+// dataItem renders a DATA constant, leaving strings unquoted when the lexer reads them back unchanged
+func (pr *printer) dataItem(expr parser.Expression) string {
+	switch v := expr.(type) {
+	case *parser.NumberLiteral:
+		return v.Value
+	case *parser.StringLiteral:
+		if v.Value == "" || strings.ContainsAny(v.Value, ",:\"\n") || strings.TrimSpace(v.Value) != v.Value || looksNumeric(v.Value) {
+			return pr.quote(v.Value)
+		}
+		return v.Value
+	}
+	return ""
+}
+
+// looksNumeric reports whether an unquoted DATA item would be read as a number: digits with an optional sign and point
+func looksNumeric(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	digits, dots := 0, 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+			dots++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && dots <= 1
+}
+
+// quote renders a string literal, with escape sequences in the extended dialect
+func (pr *printer) quote(s string) string {
+	return lexer.Quote(s, pr.dialect)
+}
+
+// expression renders an expression with the fewest parentheses that parse back to the same tree
+func (pr *printer) expression(expr parser.Expression) string {
+	switch e := expr.(type) {
+	case *parser.NumberLiteral:
+		return e.Value
+	case *parser.StringLiteral:
+		return pr.quote(e.Value)
+	case *parser.Constant:
+		return pr.constant(e.Value)
+	case *parser.VariableReference:
+		return e.Name
+	case *parser.ArrayReference:
+		return e.Name + "(" + pr.list(e.Indices) + ")"
+	case *parser.FunctionCall:
+		name := e.FunctionName
+		if ref, ok := parser.LookupReference(strings.ToUpper(name)); ok && ref.Kind == parser.KindFunction {
+			name = ref.Name
+		}
+		return name + "(" + pr.list(e.Arguments) + ")"
+	case *parser.UnaryOperation:
+		operand := pr.expression(e.Right)
+		if prec, ok := binaryPrecedence(e.Right); ok && prec <= int(parser.PREFIX) {
+			operand = "(" + operand + ")"
+		}
+		if e.Operator == "NOT" {
+			return "NOT " + operand
+		}
+		return e.Operator + operand
+	case *parser.BinaryOperation:
+		return pr.binary(e.Left, e.Operator, e.Right)
+	case *parser.ComparisonExpression:
+		return pr.binary(e.Left, e.Operator, e.Right)
+	}
+	pr.fail(expr)
+	return ""
+}
+
+// binary renders an operation; ^ groups to the right, every other operator to the left
+func (pr *printer) binary(left parser.Expression, op string, right parser.Expression) string {
+	prec := precedences[op]
+	l, r := pr.expression(left), pr.expression(right)
+	if child, ok := binaryPrecedence(left); ok && (child < prec || child == prec && op == "^") {
+		l = "(" + l + ")"
+	} else if _, unary := left.(*parser.UnaryOperation); unary && op == "^" {
+		l = "(" + l + ")"
+	}
+	if child, ok := binaryPrecedence(right); ok && (child < prec || child == prec && op != "^") {
+		r = "(" + r + ")"
+	}
+	if op == "AND" || op == "OR" {
+		return l + " " + op + " " + r
+	}
+	return l + op + r
+}
+
+// binaryPrecedence returns the precedence of a binary or comparison operation
+func binaryPrecedence(expr parser.Expression) (int, bool) {
+	switch e := expr.(type) {
+	case *parser.BinaryOperation:
+		return precedences[e.Operator], true
+	case *parser.ComparisonExpression:
+		return precedences[e.Operator], true
+	}
+	return 0, false
+}
+
+// constant renders a value folded by the optimizer; negative numbers are parenthesized as they parse as a unary minus
+func (pr *printer) constant(v types.Value) string {
+	if v.Type == types.StringType {
+		return pr.quote(v.String)
+	}
+	text := strconv.FormatFloat(v.Number, 'f', -1, 64)
+	if v.Number < 0 {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// fail records a node the printer does not know
+func (pr *printer) fail(node any) {
+	if pr.err == nil {
+		pr.err = fmt.Errorf("cannot format %T", node)
+	}
+}
@@ -0,0 +1,113 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/examples"
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"keywords and spacing", "10 print   \"A\" ; b , c;\n20 let x = 1 : y=2", "10 PRINT \"A\";b,c;\n20 LET x=1: y=2\n"},
+		{"line numbers aligned", "5 END\n1000 END\n20 END", "   5 END\n  20 END\n1000 END\n"},
+		{"empty line", "10\n20 end", "10\n20 END\n"},
+		{"redundant parentheses", "10 X=(A+B)+(C*D)-(E-F)", "10 X=A+B+C*D-(E-F)\n"},
+		{"needed parentheses", "10 X=(A+B)*C/(D*E)", "10 X=(A+B)*C/(D*E)\n"},
+		{"power groups right", "10 X=(2^3)^2+2^(3^2)", "10 X=(2^3)^2+2^3^2\n"},
+		{"unary minus", "10 X=-2^2+(-2)^2-(-A)", "10 X=-2^2+(-2)^2--A\n"},
+		{"logic", "10 IF NOT (A=1) or b and c THEN 50", "10 IF NOT (A=1) OR b AND c THEN 50\n"},
+		{"functions", "10 x=len(a$)+fna(mid$(b$,2,1))", "10 x=LEN(a$)+fna(MID$(b$,2,1))\n"},
+		{"goto after then", "10 IF A GOTO 100\n20 IF B THEN GOTO 200: PRINT", "10 IF A THEN 100\n20 IF B THEN 200: PRINT\n"},
+		{"else", "10 IF A THEN PRINT 1:PRINT 2 ELSE 30", "10 IF A THEN PRINT 1: PRINT 2 ELSE 30\n"},
+		{"rem text kept", "10 x=1:rem  keep   this  \n20 REM:X\n30 REM\n40 END", "10 x=1: REM  keep   this\n20 REM :X\n30 REM\n40 END\n"},
+		{"data", "10 DATA 1, RED ,\"A,B\",\"\",\" X\", \"12\" ,-3.5", "10 DATA 1,RED,\"A,B\",\"\",\" X\",\"12\",-3.5\n"},
+		{"input and arrays", "10 DIM A(3), B$(2,2)\n20 INPUT \"N\";N,A(1)\n30 READ B$(1,2): A(2)=3", "10 DIM A(3),B$(2,2)\n20 INPUT \"N\";N,A(1)\n30 READ B$(1,2): A(2)=3\n"},
+		{"loops", "10 for i=1 to 9 step 2:next\n20 do:loop until x\n30 while x:wend", "10 FOR i=1 TO 9 STEP 2: NEXT\n20 DO: LOOP UNTIL x\n30 WHILE x: WEND\n"},
+		{"print at", "10 PRINT AT 1,2;\"HI\";\n20 PRINT AT 0,0;\n30 print at 3,4", "10 PRINT AT 1,2;\"HI\";\n20 PRINT AT 0,0;\n30 PRINT AT 3,4\n"},
+		{"jumps", "10 on x goto 10,20:gosub 100:every 50,1 gosub 200:after 9 gosub 300", "10 ON x GOTO 10,20: GOSUB 100: EVERY 50,1 GOSUB 200: AFTER 9 GOSUB 300\n"},
+		{"list ranges", "10 LIST:LIST 5:LIST -50:LIST 5-:LIST 5-50", "10 LIST: LIST 5: LIST -50: LIST 5-: LIST 5-50\n"},
+		{"files", "10 open 1,8,2,\"F,S,W\":print# 1,a$,b;:print#1:close 1\n20 OPEN F:INPUT #F,A$,N(1):GET#F,C$", "10 OPEN 1,8,2,\"F,S,W\": PRINT#1,a$,b;: PRINT#1: CLOSE 1\n20 OPEN F: INPUT#F,A$,N(1): GET#F,C$\n"},
+		{"shims", "10 HOME", "10 CLS\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source(tt.src, Options{Shims: true})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceReportsParseErrors(t *testing.T) {
+	_, err := Source("10 PRINT \"A\"\n20 FOR", Options{})
+	var parseErr *parser.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Position.Line)
+}
+
+func TestSourceStringEscapes(t *testing.T) {
+	got, err := Source(`10 PRINT "SAY \"HI\"\x0D":DATA "A\nB"`, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, `10 PRINT "SAY \"HI\"\x0D": DATA "A\nB"`+"\n", got)
+
+	got, err = Source(`10 PRINT "A\n"`, Options{Dialect: parser.DialectC64})
+	require.NoError(t, err)
+	assert.Equal(t, `10 PRINT "A\n"`+"\n", got)
+}
+
+func TestSourceAbbreviations(t *testing.T) {
+	got, err := Source("10 ?\"HI\"", Options{Abbreviations: true})
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT \"HI\"\n", got)
+}
+
+// TestSourcePreservesPrograms formats every example and test program that parses: the result parses to the same
+// tree and formatting it again changes nothing
+func TestSourcePreservesPrograms(t *testing.T) {
+	sources := map[string]string{}
+	for _, ex := range examples.List() {
+		sources[ex.Name] = ex.Source
+	}
+	files, err := filepath.Glob("../testdata/*.bas")
+	require.NoError(t, err)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		sources[filepath.Base(file)] = string(content)
+	}
+	require.NotEmpty(t, sources)
+
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			formatted, err := Source(src, Options{Shims: true})
+			if _, ok := err.(*parser.ParseError); ok {
+				t.Skipf("uses statements this interpreter lacks: %v", err)
+			}
+			require.NoError(t, err)
+			assert.Equal(t, statements(parsertest.Parse(t, src)), statements(parsertest.Parse(t, formatted)))
+
+			again, err := Source(formatted, Options{Shims: true})
+			require.NoError(t, err)
+			assert.Equal(t, formatted, again)
+		})
+	}
+}
+
+// statements clears the source text of parsed lines: formatting changes the text of each line but not its statements
+func statements(program *parser.Program) *parser.Program {
+	for _, line := range program.Lines {
+		line.Source = ""
+	}
+	return program
+}
@@ -0,0 +1,51 @@
+// ABOUTME: ANSI syntax highlighting for BASIC program listings
+// ABOUTME: Colors source text using the lexer's token classes and honors the NO_COLOR convention
+
+package highlight
+
+import (
+	"os"
+	"strings"
+
+	"basic-interpreter/lexer"
+)
+
+const reset = "\x1b[0m"
+
+// colors maps token classes to ANSI SGR sequences; unlisted classes are left uncolored
+var colors = map[lexer.TokenClass]string{
+	lexer.ClassKeyword:    "\x1b[1;34m", // bold blue
+	lexer.ClassNumber:     "\x1b[35m",   // magenta
+	lexer.ClassString:     "\x1b[32m",   // green
+	lexer.ClassLineNumber: "\x1b[33m",   // yellow
+	lexer.ClassComment:    "\x1b[2m",    // dim
+	lexer.ClassIllegal:    "\x1b[31m",   // red
+}
+
+// Source returns source with ANSI color codes around keywords, strings, numbers, line numbers and comments
+func Source(source string) string {
+	var sb strings.Builder
+	for _, seg := range lexer.Classify(source) {
+		color, ok := colors[seg.Class]
+		if !ok {
+			sb.WriteString(seg.Text)
+			continue
+		}
+		sb.WriteString(color)
+		sb.WriteString(seg.Text)
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+// Enabled reports whether output to f should be colored: f must be a terminal and NO_COLOR must be unset or empty
+func Enabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,24 @@
+package highlight
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource(t *testing.T) {
+	got := Source(`10 PRINT "A";X`)
+	assert.Equal(t, "\x1b[33m10\x1b[0m \x1b[1;34mPRINT\x1b[0m \x1b[32m\"A\"\x1b[0m;X", got)
+}
+
+func TestEnabled(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.False(t, Enabled(f), "regular files are never colored")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, Enabled(os.Stdout))
+}
@@ -0,0 +1,61 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestInterpreter_DeclareArrayND_DistinctElements(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.DeclareArray("C", []int{2, 3, 1, 4}, false))
+
+	// Every index tuple must map to its own element
+	n := 0.0
+	for a := 0; a <= 2; a++ {
+		for b := 0; b <= 3; b++ {
+			for c := 0; c <= 1; c++ {
+				for d := 0; d <= 4; d++ {
+					require.NoError(t, interp.SetArrayElement("C", []int{a, b, c, d}, types.NewNumberValue(n)))
+					n++
+				}
+			}
+		}
+	}
+	v, err := interp.GetArrayElement("C", []int{2, 3, 1, 4})
+	require.NoError(t, err)
+	assert.Equal(t, n-1, v.Number)
+	v, err = interp.GetArrayElement("C", []int{1, 0, 1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1*4*2*5+0*2*5+1*5+2), v.Number)
+}
+
+func TestInterpreter_DeclareArrayND_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sizes   []int
+		indices []int
+		wantErr error
+	}{
+		{name: "index above its dimension", sizes: []int{1, 1, 1}, indices: []int{0, 2, 0}, wantErr: ErrBadSubscript},
+		{name: "too few subscripts", sizes: []int{1, 1, 1}, indices: []int{0, 0}, wantErr: ErrBadSubscript},
+		{name: "too many subscripts", sizes: []int{1, 1}, indices: []int{0, 0, 0}, wantErr: ErrBadSubscript},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := NewInterpreter(runtime.NewTestRuntime())
+			require.NoError(t, interp.DeclareArray("A", tt.sizes, false))
+			_, err := interp.GetArrayElement("A", tt.indices)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	assert.ErrorIs(t, interp.DeclareArray("B", []int{1000, 1000, 1000}, false), ErrStackOverflow)
+	assert.ErrorIs(t, interp.DeclareArray("D", make([]int, MaxArrayDimensions+1), false), ErrBadSubscript)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"basic-interpreter/runtime"
+	"basic-interpreter/types"
 )
 
 func TestInterpreter_DeclareArray(t *testing.T) {
@@ -26,3 +27,20 @@ func TestInterpreter_DeclareArray(t *testing.T) {
 	err = interp.DeclareArray("B", []int{-1}, false)
 	assert.Error(t, err)
 }
+
+func TestInterpreter_ImplicitArrayDimension(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+
+	v, err := interp.GetArrayElement("A", []int{DefaultArraySize})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, v.Number)
+
+	require.NoError(t, interp.SetArrayElement("S$", []int{1, 2}, types.NewStringValue("X")))
+	v, err = interp.GetArrayElement("S$", []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, "X", v.String)
+
+	_, err = interp.GetArrayElement("A", []int{DefaultArraySize + 1})
+	assert.ErrorIs(t, err, ErrBadSubscript)
+	assert.ErrorIs(t, interp.DeclareArray("A", []int{20}, false), ErrRedimArray)
+}
@@ -0,0 +1,116 @@
+// ABOUTME: Background execution for hosts such as GUIs and servers: RunAsync runs a program on its own goroutine
+// ABOUTME: The returned Execution pauses and resumes it between statements, stops it, and reports when it is done
+
+package interpreter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"basic-interpreter/parser"
+)
+
+// Execution is a program running on its own goroutine, started by RunAsync. Its methods are safe to call from any
+// goroutine; the interpreter itself may only be used while the program is paused or after Done is closed.
+type Execution struct {
+	stop      context.CancelFunc
+	stopped   <-chan struct{} // Closed by Stop or when the run's context is done
+	done      chan struct{}
+	err       error
+	requested atomic.Bool // Pause was called and Resume has not been since
+
+	mu     sync.Mutex
+	resume chan struct{} // Closed by Resume; nil while running
+	paused bool          // The program is waiting between statements
+}
+
+// RunAsync starts program on a new goroutine like ExecuteContext and returns at once
+func (i *Interpreter) RunAsync(ctx context.Context, program *parser.Program) *Execution {
+	ctx, stop := context.WithCancel(ctx)
+	e := &Execution{stop: stop, stopped: ctx.Done(), done: make(chan struct{})}
+	i.execution = e
+	go func() {
+		defer close(e.done)
+		defer stop()
+		e.err = i.ExecuteContext(ctx, program)
+		i.execution = nil
+	}()
+	return e
+}
+
+// Pause asks the program to wait before its next statement; Paused reports when it does. A program waiting for
+// INPUT pauses once the input arrives.
+func (e *Execution) Pause() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.resume == nil {
+		e.resume = make(chan struct{})
+		e.requested.Store(true)
+	}
+}
+
+// Resume lets a paused program continue
+func (e *Execution) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.resume != nil {
+		close(e.resume)
+		e.resume = nil
+		e.requested.Store(false)
+	}
+}
+
+// Stop ends the program before its next statement, paused or not; Err then reports ErrCanceled
+func (e *Execution) Stop() {
+	e.stop()
+}
+
+// Paused reports whether the program is waiting between statements after Pause
+func (e *Execution) Paused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.paused
+}
+
+// Done is closed when the program has finished, failed or been stopped
+func (e *Execution) Done() <-chan struct{} {
+	return e.done
+}
+
+// Err returns the error the program ended with; it is nil until Done is closed
+func (e *Execution) Err() error {
+	select {
+	case <-e.done:
+		return e.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the program ends and returns its error
+func (e *Execution) Wait() error {
+	<-e.done
+	return e.err
+}
+
+// checkpoint blocks the program between statements while a pause is requested, until Resume or Stop
+func (e *Execution) checkpoint() {
+	if !e.requested.Load() {
+		return
+	}
+	e.mu.Lock()
+	resume := e.resume
+	e.paused = resume != nil
+	e.mu.Unlock()
+	if resume == nil {
+		return
+	}
+	select {
+	case <-resume:
+	case <-e.stopped:
+	}
+	e.mu.Lock()
+	e.paused = false
+	e.mu.Unlock()
+}
@@ -0,0 +1,67 @@
+package interpreter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// loopForever counts in A until stopped
+const loopForever = "10 A=A+1: GOTO 10"
+
+func startAsync(t *testing.T, src string) (*Interpreter, *Execution) {
+	t.Helper()
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetMaxSteps(0)
+	return interp, interp.RunAsync(context.Background(), parser.New(lexer.New(src)).ParseProgram())
+}
+
+func TestRunAsyncFinishes(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	e := interp.RunAsync(context.Background(), parser.New(lexer.New("10 PRINT 1+1: PRINT 1/0")).ParseProgram())
+	require.EqualError(t, e.Wait(), "?DIVISION BY ZERO ERROR IN 10")
+	assert.EqualError(t, e.Err(), "?DIVISION BY ZERO ERROR IN 10")
+	assert.Equal(t, []string{"2\n"}, rt.GetOutput())
+}
+
+func TestRunAsyncPauseAndResume(t *testing.T) {
+	interp, e := startAsync(t, loopForever)
+	e.Pause()
+	require.Eventually(t, e.Paused, time.Second, time.Millisecond)
+	assert.Nil(t, e.Err())
+
+	// While paused the program makes no progress and its state can be read
+	steps := interp.Steps()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, steps, interp.Steps())
+	a, err := interp.GetVariable("A")
+	require.NoError(t, err)
+	assert.Equal(t, float64((steps+1)/2), a.Number)
+
+	e.Resume()
+	require.Eventually(t, func() bool { return !e.Paused() }, time.Second, time.Millisecond)
+	e.Stop()
+	assert.ErrorIs(t, e.Wait(), ErrCanceled)
+	assert.Greater(t, interp.Steps(), steps)
+}
+
+func TestRunAsyncStopWhilePaused(t *testing.T) {
+	_, e := startAsync(t, loopForever)
+	e.Pause()
+	require.Eventually(t, e.Paused, time.Second, time.Millisecond)
+	e.Stop()
+	select {
+	case <-e.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stopped program did not end")
+	}
+	assert.ErrorIs(t, e.Err(), ErrCanceled)
+}
@@ -0,0 +1,63 @@
+// ABOUTME: Lays the statements of IF branches out inline so every statement of a line has its own position
+// ABOUTME: FOR, GOSUB, WHILE and DO inside THEN or ELSE record positions within the branch and resume there
+
+package interpreter
+
+import "basic-interpreter/parser"
+
+// slot is one position of a line laid out for execution: a statement, or the end of a THEN branch
+// whose ELSE statements are skipped (stmt is nil). An IF is followed by its THEN statements.
+type slot struct {
+	stmt   parser.Statement
+	elseAt int // For an IF, where a false condition continues: its ELSE statements or the end of the line
+	skipTo int // For the end of a THEN branch, the position after the ELSE statements
+}
+
+// positions returns the positions of a line's statements, laid out once per loaded program line
+func (i *Interpreter) positions(line *parser.Line) []slot {
+	if slots, ok := i.layouts[line]; ok {
+		return slots
+	}
+	slots := layoutStatements(make([]slot, 0, len(line.Statements)), line.Statements)
+	if line.Number != immediateLine {
+		i.layouts[line] = slots
+	}
+	return slots
+}
+
+// layoutStatements appends stmts to out with IF branches inline. An IF runs to the end of its line,
+// so the end of its branches is the end of the list holding it.
+func layoutStatements(out []slot, stmts []parser.Statement) []slot {
+	for _, stmt := range stmts {
+		at := len(out)
+		out = append(out, slot{stmt: stmt})
+		is, ok := stmt.(*parser.IfStatement)
+		if !ok {
+			continue
+		}
+		out = layoutStatements(out, is.ThenStmts)
+		if len(is.ElseStmts) == 0 {
+			out[at].elseAt = len(out)
+			continue
+		}
+		skip := len(out)
+		out = append(out, slot{})
+		out[at].elseAt = len(out)
+		out = layoutStatements(out, is.ElseStmts)
+		out[skip].skipTo = len(out)
+	}
+	return out
+}
+
+// branch evaluates the condition of an IF laid out inline and returns the position to continue at: its THEN
+// statements, or else its ELSE statements or the end of the line
+func (i *Interpreter) branch(is *parser.IfStatement, elseAt int) (int, error) {
+	condition, err := is.Condition.Evaluate(i)
+	if err != nil {
+		return 0, err
+	}
+	if !condition.IsTrue() {
+		return elseAt, nil
+	}
+	return i.stmtIndex + 1, nil
+}
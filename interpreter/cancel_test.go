@@ -0,0 +1,63 @@
+package interpreter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+func TestExecuteContextStopsRun(t *testing.T) {
+	expired, cancelExpired := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		limit time.Duration
+		want  error
+	}{
+		{"canceled context", canceled, 0, ErrCanceled},
+		{"context deadline", expired, 0, ErrTimeLimit},
+		{"time limit", context.Background(), 10 * time.Millisecond, ErrTimeLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parser.New(lexer.New("10 A=A+1: GOTO 10")).ParseProgram()
+			interp := NewInterpreter(runtime.NewTestRuntime())
+			interp.SetMaxSteps(0)
+			interp.SetTimeLimit(tt.limit)
+
+			err := interp.ExecuteContext(tt.ctx, program)
+			require.ErrorIs(t, err, tt.want)
+			assert.Equal(t, 10, interp.CurrentLine())
+		})
+	}
+}
+
+func TestTimeLimitAppliesToExecute(t *testing.T) {
+	program := parser.New(lexer.New("10 GOTO 10")).ParseProgram()
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetMaxSteps(0)
+	interp.SetTimeLimit(10 * time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, interp.TimeLimit())
+	assert.ErrorIs(t, interp.Execute(program), ErrTimeLimit)
+	assert.ErrorIs(t, interp.ExecuteFrom(program, 10), ErrTimeLimit)
+}
+
+func TestExecuteContextFinishesWithinLimit(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	program := parser.New(lexer.New("10 FOR I=1 TO 3: NEXT: PRINT I")).ParseProgram()
+	interp := NewInterpreter(rt)
+	interp.SetTimeLimit(time.Minute)
+	require.NoError(t, interp.ExecuteContext(context.Background(), program))
+	assert.Equal(t, []string{"4\n"}, rt.GetOutput())
+}
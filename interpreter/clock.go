@@ -0,0 +1,65 @@
+// ABOUTME: Reserved clock variables TI, TI$ and TIMER backed by the runtime's clock
+// ABOUTME: TI counts jiffies since start-up, TI$ is the settable HHMMSS clock, TIMER is seconds since midnight (TI in c64)
+
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// IsClockVariable reports whether name refers to TI, TI$ or TIMER in a dialect; in the c64 dialect, where names are
+// significant to two characters, that is any name starting with TI
+func IsClockVariable(name string, d parser.Dialect) bool {
+	switch strings.ToUpper(VariableName(name, d)) {
+	case "TI", "TI$", "TIMER":
+		return true
+	}
+	return false
+}
+
+// clockVariable returns the value of a clock variable, or false when name is an ordinary variable
+func (i *Interpreter) clockVariable(name string) (types.Value, bool) {
+	if !IsClockVariable(name, i.dialect) {
+		return types.Value{}, false
+	}
+	now := i.runtime.Now()
+	upper := strings.ToUpper(name)
+	switch {
+	case upper == "TIMER" && i.dialect == parser.DialectExtended:
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return types.NewNumberValue(now.Sub(midnight).Seconds()), true
+	case strings.HasSuffix(upper, "$"):
+		elapsed := now.Sub(i.clockStart) % (24 * time.Hour)
+		h, m, s := int(elapsed.Hours()), int(elapsed.Minutes())%60, int(elapsed.Seconds())%60
+		return types.NewStringValue(fmt.Sprintf("%02d%02d%02d", h, m, s)), true
+	default:
+		return types.NewNumberValue(float64(now.Sub(i.clockStart) / runtime.Jiffy)), true
+	}
+}
+
+// setClock implements TI$ = "HHMMSS"; TI and TIMER are read-only
+func (i *Interpreter) setClock(name string, value types.Value) error {
+	if !strings.HasSuffix(name, "$") {
+		return fmt.Errorf("?SYNTAX ERROR")
+	}
+	digits := value.String
+	if len(digits) != 6 || strings.Trim(digits, "0123456789") != "" {
+		return ErrIllegalQuantity
+	}
+	h, _ := strconv.Atoi(digits[0:2])
+	m, _ := strconv.Atoi(digits[2:4])
+	s, _ := strconv.Atoi(digits[4:6])
+	if h > 23 || m > 59 || s > 59 {
+		return ErrIllegalQuantity
+	}
+	elapsed := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	i.clockStart = i.runtime.Now().Add(-elapsed)
+	return nil
+}
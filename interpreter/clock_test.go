@@ -0,0 +1,84 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+func runDeterministic(t *testing.T, src string, seed int64, script string) ([]string, error) {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+	rt := runtime.NewDeterministicRuntime(seed, script)
+	interp := NewInterpreter(rt)
+	interp.SetMaxSteps(10000)
+	err := interp.Execute(program)
+	return rt.GetOutput(), err
+}
+
+func TestInterpreter_ClockVariables(t *testing.T) {
+	t.Run("TI counts jiffies on the virtual clock", func(t *testing.T) {
+		out, err := runDeterministic(t, "10 T=TI\n20 IF TI<T+10 THEN 20\n30 PRINT TI-T", 1, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"11\n"}, out)
+	})
+
+	t.Run("TI$ can be set and read back", func(t *testing.T) {
+		out, err := runDeterministic(t, "10 TI$=\"123456\"\n20 PRINT TI$", 1, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"123456\n"}, out)
+	})
+
+	t.Run("TIMER is seconds since midnight", func(t *testing.T) {
+		out, err := runDeterministic(t, "10 PRINT INT(TIMER)", 1, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0\n"}, out)
+	})
+
+	t.Run("TIMER is TI in the c64 dialect", func(t *testing.T) {
+		src := "10 IF TI<600 THEN 10\n20 PRINT TIMER>=600"
+		p := parser.New(lexer.New(src))
+		program := p.ParseProgram()
+		require.Nil(t, p.ParseError())
+		for dialect, want := range map[parser.Dialect]string{parser.DialectC64: "1\n", parser.DialectExtended: "0\n"} {
+			rt := runtime.NewDeterministicRuntime(1, "")
+			interp := NewInterpreter(rt)
+			interp.SetMaxSteps(10000)
+			interp.SetDialect(dialect)
+			require.NoError(t, interp.Execute(program))
+			assert.Equal(t, []string{want}, rt.GetOutput(), dialect)
+		}
+	})
+
+	t.Run("TI is read-only and TI$ needs six digits", func(t *testing.T) {
+		_, err := runDeterministic(t, "10 TI=5", 1, "")
+		assert.ErrorContains(t, err, "?SYNTAX ERROR")
+		_, err = runDeterministic(t, "10 TI$=\"1234\"", 1, "")
+		assert.ErrorContains(t, err, "?ILLEGAL QUANTITY ERROR")
+	})
+}
+
+func TestInterpreter_DeterministicReplay(t *testing.T) {
+	// A tiny game loop: poll keys each frame, move on L/R, roll a die on SPACE
+	src := `10 X=5
+20 GET K$: IF K$="" THEN 20
+30 IF K$="L" THEN X=X-1
+40 IF K$="R" THEN X=X+1
+50 IF K$=" " THEN PRINT "ROLL";INT(RND(1)*6)+1
+60 IF K$="Q" THEN PRINT "X=";X;"AT";TI: END
+70 GOTO 20`
+
+	first, err := runDeterministic(t, src, 99, "RR L Q")
+	require.NoError(t, err)
+	second, err := runDeterministic(t, src, 99, "RR L Q")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, []string{"ROLL 2\n", "ROLL 4\n", "X= 6 AT 1\n"}, first)
+}
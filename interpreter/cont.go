@@ -0,0 +1,92 @@
+// ABOUTME: CONT support: STOP and Ctrl+C record where the program halted so execution can resume there
+// ABOUTME: Variables and FOR/WHILE/DO/GOSUB stacks stay in place; editing the program or an error makes CONT fail
+
+package interpreter
+
+import (
+	"fmt"
+
+	"basic-interpreter/parser"
+)
+
+// ErrCantContinue reports CONT without a stopped program, or after the program changed
+var ErrCantContinue = fmt.Errorf("?CAN'T CONTINUE ERROR")
+
+// resumePoint is the statement after a STOP
+type resumePoint struct {
+	program   *parser.Program // Program that stopped; CONT with any other program fails
+	pc        int
+	stmtIndex int
+	line      int // Line number of the STOP
+}
+
+// RequestStop halts the program and remembers the statement after STOP for CONT
+func (i *Interpreter) RequestStop() error {
+	i.halted = true
+	i.stopped = true
+	i.resume = nil
+	if line := i.currentLineNumber(); line != immediateLine && i.program != nil {
+		i.resume = &resumePoint{program: i.program, pc: i.pc, stmtIndex: i.stmtIndex + 1, line: line}
+	}
+	return nil
+}
+
+// SetInterrupt sets the channel a host sends on when the user presses Ctrl+C, the C64's RUN/STOP. The program stops
+// before its next statement with a CodeBreak *Error reading "BREAK IN 10", and CONT runs that statement.
+// Interrupts sent while no program runs are dropped when the next run starts.
+func (i *Interpreter) SetInterrupt(interrupt <-chan struct{}) {
+	i.interrupt = interrupt
+}
+
+// Interrupt returns the channel set by SetInterrupt, nil when there is none
+func (i *Interpreter) Interrupt() <-chan struct{} {
+	return i.interrupt
+}
+
+// clearInterrupt drops an interrupt sent while no program ran
+func (i *Interpreter) clearInterrupt() {
+	select {
+	case <-i.interrupt:
+	default:
+	}
+}
+
+// breakAt halts the program before the current statement for an interrupt, remembering it for CONT
+func (i *Interpreter) breakAt(line int) error {
+	i.halted = true
+	i.resume = nil
+	if line != immediateLine && i.program != nil {
+		i.resume = &resumePoint{program: i.program, pc: i.pc, stmtIndex: i.stmtIndex, line: line}
+	}
+	return i.wrapErrorWithLine(CodeBreak, line, i.stmtIndex)
+}
+
+// BreakLine reports the line of the STOP that ended the last run, for a BREAK IN message
+func (i *Interpreter) BreakLine() (int, bool) {
+	if !i.stopped || i.resume == nil {
+		return 0, false
+	}
+	return i.resume.line, true
+}
+
+// Continue resumes program after the statement where STOP halted it, or at the one an interrupt stopped before,
+// keeping variables and stacks.
+// It fails with ErrCantContinue when nothing stopped, an error occurred since, or program is not the one that stopped.
+func (i *Interpreter) Continue(program *parser.Program) error {
+	i.stopped = false
+	r := i.resume
+	if r == nil || r.program != program || i.program != program {
+		return ErrCantContinue
+	}
+	i.resume = nil
+	i.stepCount = 0
+	i.clearInterrupt()
+	i.halted = false
+	i.jumped = false
+	i.erased = false
+
+	i.pc, i.stmtIndex = r.pc, r.stmtIndex
+	i.stmtJumped = true // Start inside the line rather than at its first statement
+	_, err := i.run(program, -1)
+	return err
+}
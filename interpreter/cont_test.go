@@ -0,0 +1,114 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+func TestInterpreter_ContinueKeepsStacks(t *testing.T) {
+	program := parsertest.Parse(t, "10 GOSUB 100: PRINT \"BACK\"\n20 END\n100 FOR I=1 TO 2: WHILE I<3\n110 STOP: PRINT I: I=I+5: WEND: NEXT I: RETURN")
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+
+	require.NoError(t, interp.Execute(program))
+	line, ok := interp.BreakLine()
+	assert.True(t, ok)
+	assert.Equal(t, 110, line)
+
+	require.NoError(t, interp.Continue(program))
+	_, ok = interp.BreakLine()
+	assert.False(t, ok)
+	assert.Equal(t, []string{"1\n", "BACK\n"}, rt.GetOutput())
+	assert.Equal(t, ErrCantContinue, interp.Continue(program), "a finished program cannot continue")
+}
+
+func TestInterpreter_CantContinue(t *testing.T) {
+	tests := []struct {
+		name  string
+		after func(*Interpreter, *parser.Program) *parser.Program
+	}{
+		{"different program", func(_ *Interpreter, _ *parser.Program) *parser.Program {
+			return parsertest.Parse(t, "10 STOP\n20 PRINT 1")
+		}},
+		{"error after STOP", func(i *Interpreter, p *parser.Program) *parser.Program {
+			assert.Error(t, i.ExecuteImmediate(p, parseImmediate(t, "PRINT 1/0")))
+			return p
+		}},
+		{"RUN after STOP", func(i *Interpreter, p *parser.Program) *parser.Program {
+			i.Reset()
+			return p
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parsertest.Parse(t, "10 STOP\n20 PRINT 1")
+			interp := NewInterpreter(runtime.NewTestRuntime())
+			require.NoError(t, interp.Execute(program))
+			assert.Equal(t, ErrCantContinue, interp.Continue(tt.after(interp, program)))
+		})
+	}
+}
+
+// interrupter sends on interrupt until stopped, so an interrupt dropped as a run starts is sent again
+func interrupter() (chan struct{}, func()) {
+	interrupt, stop, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case interrupt <- struct{}{}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return interrupt, func() { close(stop); <-done }
+}
+
+func TestInterpreter_InterruptBreaksAndContinues(t *testing.T) {
+	program := parsertest.Parse(t, "10 IF S=0 THEN 10\n20 PRINT \"DONE\"")
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	interp.SetMaxSteps(0)
+	interrupt, stop := interrupter()
+	interp.SetInterrupt(interrupt)
+
+	err := interp.Execute(program)
+	stop()
+	require.ErrorIs(t, err, CodeBreak)
+	assert.EqualError(t, err, "BREAK IN 10")
+	_, ok := interp.BreakLine()
+	assert.False(t, ok, "only STOP reports a break line")
+
+	require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "S=1")))
+	require.NoError(t, interp.Continue(program))
+	assert.Equal(t, []string{"DONE\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_InterruptImmediate(t *testing.T) {
+	program := parsertest.Parse(t, "10 PRINT 1")
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetMaxSteps(0)
+	interrupt, stop := interrupter()
+	interp.SetInterrupt(interrupt)
+
+	err := interp.ExecuteImmediate(program, parseImmediate(t, "DO: LOOP"))
+	stop()
+	require.ErrorIs(t, err, CodeBreak)
+	assert.EqualError(t, err, "BREAK")
+	assert.Equal(t, ErrCantContinue, interp.Continue(program))
+}
+
+func TestInterpreter_InterruptBeforeRunIsDropped(t *testing.T) {
+	interrupt := make(chan struct{}, 1)
+	interrupt <- struct{}{}
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetInterrupt(interrupt)
+	assert.NoError(t, interp.Execute(parsertest.Parse(t, "10 PRINT 1")))
+}
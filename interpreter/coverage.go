@@ -0,0 +1,104 @@
+// ABOUTME: Program coverage: counts the statements executed on each line and reports the lines never reached
+// ABOUTME: Annotate writes the listing with a count per line, marking unexecuted lines with #####
+
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"basic-interpreter/parser"
+)
+
+// unexecutedMark stands for the count of a line that never ran in an annotated listing
+const unexecutedMark = "#####"
+
+// Coverage counts the statements executed on each program line across one or more runs
+type Coverage struct {
+	Hits map[int]int // Statements executed, by BASIC line number
+}
+
+// NewCoverage creates an empty coverage record
+func NewCoverage() *Coverage {
+	return &Coverage{Hits: make(map[int]int)}
+}
+
+// SetCoverage counts executed statements into c; nil stops counting
+func (i *Interpreter) SetCoverage(c *Coverage) {
+	var hook Hooks
+	if c != nil {
+		hook = &coverageHook{coverage: c}
+	}
+	i.coverageHook = i.replaceHooks(i.coverageHook, hook)
+	i.coverage = c
+}
+
+// CoverageReport summarizes the coverage of a program
+type CoverageReport struct {
+	Lines    int   // Numbered lines in the program
+	Executed int   // Lines with at least one statement executed
+	Missed   []int // Lines never executed, in order
+}
+
+// Percent returns the share of lines executed; an empty program is fully covered
+func (r CoverageReport) Percent() float64 {
+	if r.Lines == 0 {
+		return 100
+	}
+	return 100 * float64(r.Executed) / float64(r.Lines)
+}
+
+func (r CoverageReport) String() string {
+	s := fmt.Sprintf("%d of %d lines executed (%.1f%%)", r.Executed, r.Lines, r.Percent())
+	if len(r.Missed) > 0 {
+		missed := make([]string, len(r.Missed))
+		for n, line := range r.Missed {
+			missed[n] = fmt.Sprint(line)
+		}
+		s += "; not executed: " + strings.Join(missed, ", ")
+	}
+	return s
+}
+
+// Report compares the counts with the numbered lines of the program text src
+func (c *Coverage) Report(src string) CoverageReport {
+	var r CoverageReport
+	for _, line := range sortedLines(parser.SourceLines(src)) {
+		r.Lines++
+		if c.Hits[line] > 0 {
+			r.Executed++
+		} else {
+			r.Missed = append(r.Missed, line)
+		}
+	}
+	return r
+}
+
+// Annotate writes the listing of src with the statements executed on each line in front of it,
+// followed by the report
+func (c *Coverage) Annotate(w io.Writer, src string) error {
+	lines := parser.SourceLines(src)
+	for _, line := range sortedLines(lines) {
+		count := unexecutedMark
+		if hits := c.Hits[line]; hits > 0 {
+			count = fmt.Sprint(hits)
+		}
+		if _, err := fmt.Fprintf(w, "%8s  %d %s\n", count, line, lines[line]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, c.Report(src))
+	return err
+}
+
+// sortedLines returns the line numbers of a listing in order
+func sortedLines(lines map[int]string) []int {
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
@@ -0,0 +1,53 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+func TestInterpreter_Coverage(t *testing.T) {
+	src := "10 FOR I=1 TO 3: PRINT I: NEXT I\n20 IF I>5 THEN 40\n30 END\n40 PRINT \"NO\""
+	coverage := NewCoverage()
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetCoverage(coverage)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, src)))
+
+	assert.Equal(t, map[int]int{10: 7, 20: 1, 30: 1}, coverage.Hits)
+	report := coverage.Report(src)
+	assert.Equal(t, CoverageReport{Lines: 4, Executed: 3, Missed: []int{40}}, report)
+	assert.Equal(t, "3 of 4 lines executed (75.0%); not executed: 40", report.String())
+
+	var listing strings.Builder
+	require.NoError(t, coverage.Annotate(&listing, src))
+	assert.Equal(t, strings.Join([]string{
+		"       7  10 FOR I=1 TO 3: PRINT I: NEXT I",
+		"       1  20 IF I>5 THEN 40",
+		"       1  30 END",
+		"   #####  40 PRINT \"NO\"",
+		"3 of 4 lines executed (75.0%); not executed: 40",
+		"",
+	}, "\n"), listing.String())
+}
+
+func TestInterpreter_CoverageAcrossRuns(t *testing.T) {
+	src := "10 INPUT A\n20 IF A THEN 40\n30 PRINT \"ZERO\": END\n40 PRINT \"NOT ZERO\""
+	program := parsertest.Parse(t, src)
+	coverage := NewCoverage()
+	for _, input := range []string{"0", "1"} {
+		rt := runtime.NewTestRuntime()
+		rt.SetInput([]string{input})
+		interp := NewInterpreter(rt)
+		interp.SetCoverage(coverage)
+		require.NoError(t, interp.Execute(program))
+	}
+
+	report := coverage.Report(src)
+	assert.Equal(t, 4, report.Executed)
+	assert.Equal(t, "4 of 4 lines executed (100.0%)", report.String())
+}
@@ -0,0 +1,173 @@
+// ABOUTME: The debugger command set: print, watch, vars, list, break, step, next, continue and quit, as text commands
+// ABOUTME: CommandDebugger reads commands at each pause, so a terminal front-end or a test script can drive a run
+
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// listContextLines is how many lines the list command shows before and after the current line
+const listContextLines = 5
+
+// debugHelp lists the debugger commands
+const debugHelp = `print EXPR (? EXPR)  evaluate an expression
+watch [EXPR]         add a watch, or list the watches
+unwatch N            remove watch N
+vars                 list variables, arrays and the loop and GOSUB stacks
+list (l)             show the program around the current line
+break N / clear N    set or remove a breakpoint at line N
+breaks               list the breakpoints
+step (s)             run one statement, going into subroutines
+next (n)             run one statement, running GOSUBs through
+continue (c)         run to the next breakpoint
+quit (q)             end the run
+`
+
+// DebugCommand runs one debugger command while paused and returns its output. Commands that resume the
+// run (step, next, continue, quit) return their action and true instead.
+func (i *Interpreter) DebugCommand(line string) (string, DebugAction, bool) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(command, "?") {
+		command, arg = "?", strings.TrimSpace(strings.TrimSpace(line)[1:])
+	}
+
+	switch strings.ToLower(command) {
+	case "step", "s":
+		return "", DebugStep, true
+	case "next", "n":
+		return "", DebugStepOver, true
+	case "continue", "c":
+		return "", DebugContinue, true
+	case "quit", "q":
+		return "", DebugQuit, true
+	case "print", "p", "?":
+		value, err := i.EvaluateExpression(arg)
+		if err != nil {
+			return err.Error() + "\n", 0, false
+		}
+		return formatValue(value) + "\n", 0, false
+	case "watch":
+		if arg == "" {
+			return i.formatWatches(), 0, false
+		}
+		if err := i.AddWatch(arg); err != nil {
+			return err.Error() + "\n", 0, false
+		}
+		return "", 0, false
+	case "unwatch":
+		n, err := strconv.Atoi(arg)
+		if err != nil || i.RemoveWatch(n-1) != nil {
+			return "?ILLEGAL QUANTITY ERROR\n", 0, false
+		}
+		return "", 0, false
+	case "vars":
+		return i.Inspect().Format(), 0, false
+	case "list", "l":
+		return i.ListContext(i.CurrentLine(), listContextLines), 0, false
+	case "break", "clear":
+		line, err := strconv.Atoi(arg)
+		if err != nil {
+			return "?SYNTAX ERROR\n", 0, false
+		}
+		if strings.EqualFold(command, "break") {
+			i.SetBreakpoint(line)
+		} else {
+			i.ClearBreakpoint(line)
+		}
+		return "", 0, false
+	case "breaks":
+		var b strings.Builder
+		for _, line := range i.Breakpoints() {
+			fmt.Fprintln(&b, line)
+		}
+		return b.String(), 0, false
+	case "help", "h":
+		return debugHelp, 0, false
+	default:
+		return fmt.Sprintf("unknown command %q; type help\n", command), 0, false
+	}
+}
+
+// formatWatches lists the watches numbered from 1 with their current values
+func (i *Interpreter) formatWatches() string {
+	var b strings.Builder
+	for n, watch := range i.Watches() {
+		value := formatValue(watch.Value)
+		if watch.Err != nil {
+			value = watch.Err.Error()
+		}
+		fmt.Fprintf(&b, "%d: %s = %s\n", n+1, watch.Source, value)
+	}
+	return b.String()
+}
+
+// ListContext lists the program lines from context lines before line to context lines after it,
+// marking line with "=>"
+func (i *Interpreter) ListContext(line, context int) string {
+	if i.program == nil {
+		return ""
+	}
+	var numbers []int
+	at := -1
+	for _, l := range i.program.Lines {
+		if l.Number == immediateLine {
+			continue
+		}
+		if l.Number == line {
+			at = len(numbers)
+		}
+		numbers = append(numbers, l.Number)
+	}
+	if at < 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, n := range numbers[max(at-context, 0):min(at+context+1, len(numbers))] {
+		marker := "  "
+		if n == line {
+			marker = "=>"
+		}
+		fmt.Fprintf(&b, "%s %d %s\n", marker, n, i.listing[n])
+	}
+	return b.String()
+}
+
+// CommandDebugger is a Debugger driven by text commands. At each pause it writes the location, the source
+// of the line and the watches to Out, then runs commands from Read until one resumes the run.
+type CommandDebugger struct {
+	Read    func() (string, error) // Returns the next command; an error (such as io.EOF) quits the run
+	Out     io.Writer
+	Context int // Lines of the program shown before and after the paused line; 0 shows only its source
+}
+
+// Pause implements Debugger
+func (d *CommandDebugger) Pause(interp *Interpreter, event DebugEvent) DebugAction {
+	fmt.Fprintf(d.Out, "%s at %d", event.Reason, event.Line)
+	if event.Statement > 0 {
+		fmt.Fprintf(d.Out, " statement %d", event.Statement+1)
+	}
+	if d.Context > 0 {
+		fmt.Fprintln(d.Out)
+		io.WriteString(d.Out, interp.ListContext(event.Line, d.Context))
+	} else {
+		fmt.Fprintf(d.Out, ": %s\n", interp.listing[event.Line])
+	}
+	io.WriteString(d.Out, interp.formatWatches())
+	for {
+		line, err := d.Read()
+		if err != nil {
+			return DebugQuit
+		}
+		output, action, resume := interp.DebugCommand(line)
+		io.WriteString(d.Out, output)
+		if resume {
+			return action
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Debug mode: breakpoints by line number, pausing on hit, single-stepping, stepping over GOSUB and continuing
+// ABOUTME: A Debugger decides what happens at each pause, so a CLI front-end and tests drive runs the same way
+
+package interpreter
+
+import (
+	"math"
+	"sort"
+)
+
+// PauseReason tells why a run paused
+type PauseReason string
+
+// Pause reasons
+const (
+	PauseBreakpoint PauseReason = "breakpoint"
+	PauseStep       PauseReason = "step"
+)
+
+// DebugAction tells a paused run how to go on
+type DebugAction int
+
+// Debug actions
+const (
+	DebugContinue DebugAction = iota // Run until the next breakpoint
+	DebugStep                        // Pause before the next statement
+	DebugStepOver                    // Pause before the next statement outside any GOSUB the current one starts
+	DebugQuit                        // End the run without executing the statement
+)
+
+// DebugEvent describes where a run paused
+type DebugEvent struct {
+	Line      int // Line number of the statement about to run
+	Statement int // Statement index within the line (0-based)
+	Reason    PauseReason
+	Depth     int // Active GOSUB calls
+}
+
+// Debugger controls a run in debug mode. Pause is called before a statement runs when a breakpoint
+// is hit or a step ends; it may inspect the interpreter, for example with Variables, before answering.
+type Debugger interface {
+	Pause(interp *Interpreter, event DebugEvent) DebugAction
+}
+
+// debugState holds the breakpoints and stepping mode of debug mode
+type debugState struct {
+	debugger    Debugger
+	breakpoints map[int]bool
+	stepping    bool
+	stepDepth   int      // Stepping pauses only at this GOSUB depth or shallower
+	watches     []string // Watch expressions, see inspect.go
+}
+
+// SetDebugger turns on debug mode controlled by d; nil turns it off. Breakpoints are kept.
+func (i *Interpreter) SetDebugger(d Debugger) {
+	i.debug.debugger = d
+	i.debug.stepping = false
+}
+
+// SetBreakpoint pauses runs when the given line starts executing
+func (i *Interpreter) SetBreakpoint(line int) {
+	if i.debug.breakpoints == nil {
+		i.debug.breakpoints = make(map[int]bool)
+	}
+	i.debug.breakpoints[line] = true
+}
+
+// ClearBreakpoint removes the breakpoint at a line
+func (i *Interpreter) ClearBreakpoint(line int) {
+	delete(i.debug.breakpoints, line)
+}
+
+// Breakpoints returns the lines with breakpoints, in order
+func (i *Interpreter) Breakpoints() []int {
+	lines := make([]int, 0, len(i.debug.breakpoints))
+	for line := range i.debug.breakpoints {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// SetStepping makes the next run pause before its first statement, as if stepping into it
+func (i *Interpreter) SetStepping(enabled bool) {
+	i.debug.stepping = enabled
+	i.debug.stepDepth = math.MaxInt
+}
+
+// debugPause asks the debugger what to do before the statement at line and statement index runs.
+// It reports false when the run should end.
+func (i *Interpreter) debugPause(line, statement int) bool {
+	d := &i.debug
+	depth := i.callStack.Size()
+	var reason PauseReason
+	switch {
+	case statement == 0 && d.breakpoints[line]:
+		reason = PauseBreakpoint
+	case d.stepping && depth <= d.stepDepth:
+		reason = PauseStep
+	default:
+		return true
+	}
+
+	action := d.debugger.Pause(i, DebugEvent{Line: line, Statement: statement, Reason: reason, Depth: depth})
+	d.stepping = action == DebugStep || action == DebugStepOver
+	d.stepDepth = math.MaxInt
+	if action == DebugStepOver {
+		d.stepDepth = depth
+	}
+	return action != DebugQuit
+}
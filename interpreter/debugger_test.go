@@ -0,0 +1,131 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+// scriptedDebugger answers pauses with a fixed list of actions, then continues
+type scriptedDebugger struct {
+	actions []DebugAction
+	events  []DebugEvent
+	values  []float64 // Value of I at each pause
+}
+
+func (d *scriptedDebugger) Pause(interp *Interpreter, event DebugEvent) DebugAction {
+	d.events = append(d.events, event)
+	d.values = append(d.values, interp.Variables()["I"].Number)
+	if len(d.actions) == 0 {
+		return DebugContinue
+	}
+	action := d.actions[0]
+	d.actions = d.actions[1:]
+	return action
+}
+
+const debugProgram = "10 I=1\n20 GOSUB 100: I=I+1\n30 PRINT I\n40 END\n100 I=I*10\n110 RETURN"
+
+func TestInterpreter_Debugger(t *testing.T) {
+	tests := []struct {
+		name        string
+		breakpoints []int
+		stepping    bool
+		actions     []DebugAction
+		want        []DebugEvent
+		output      []string
+	}{
+		{
+			name:        "breakpoint pauses when its line starts",
+			breakpoints: []int{20, 110},
+			want: []DebugEvent{
+				{Line: 20, Reason: PauseBreakpoint},
+				{Line: 110, Reason: PauseBreakpoint, Depth: 1},
+			},
+			output: []string{"11\n"},
+		},
+		{
+			name:        "step goes into the subroutine",
+			breakpoints: []int{20},
+			actions:     []DebugAction{DebugStep, DebugStep, DebugStep, DebugContinue},
+			want: []DebugEvent{
+				{Line: 20, Reason: PauseBreakpoint},
+				{Line: 100, Reason: PauseStep, Depth: 1},
+				{Line: 110, Reason: PauseStep, Depth: 1},
+				{Line: 20, Statement: 1, Reason: PauseStep},
+			},
+			output: []string{"11\n"},
+		},
+		{
+			name:        "step over runs the subroutine without pausing",
+			breakpoints: []int{20},
+			actions:     []DebugAction{DebugStepOver, DebugStepOver, DebugContinue},
+			want: []DebugEvent{
+				{Line: 20, Reason: PauseBreakpoint},
+				{Line: 20, Statement: 1, Reason: PauseStep},
+				{Line: 30, Reason: PauseStep},
+			},
+			output: []string{"11\n"},
+		},
+		{
+			name:     "stepping from the start",
+			stepping: true,
+			actions:  []DebugAction{DebugStep, DebugContinue},
+			want: []DebugEvent{
+				{Line: 10, Reason: PauseStep},
+				{Line: 20, Reason: PauseStep},
+			},
+			output: []string{"11\n"},
+		},
+		{
+			name:        "quit ends the run before the statement",
+			breakpoints: []int{30},
+			actions:     []DebugAction{DebugQuit},
+			want:        []DebugEvent{{Line: 30, Reason: PauseBreakpoint}},
+			output:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := runtime.NewTestRuntime()
+			interp := NewInterpreter(rt)
+			debugger := &scriptedDebugger{actions: tt.actions}
+			interp.SetDebugger(debugger)
+			for _, line := range tt.breakpoints {
+				interp.SetBreakpoint(line)
+			}
+			interp.SetStepping(tt.stepping)
+
+			require.NoError(t, interp.Execute(parsertest.Parse(t, debugProgram)))
+			assert.Equal(t, tt.want, debugger.events)
+			assert.Equal(t, tt.output, rt.GetOutput())
+		})
+	}
+}
+
+func TestInterpreter_DebuggerInspectsVariables(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	debugger := &scriptedDebugger{}
+	interp.SetDebugger(debugger)
+	interp.SetBreakpoint(30)
+	interp.SetBreakpoint(110)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, debugProgram)))
+	assert.Equal(t, []float64{10, 11}, debugger.values)
+}
+
+func TestInterpreter_Breakpoints(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetBreakpoint(30)
+	interp.SetBreakpoint(10)
+	interp.SetBreakpoint(20)
+	interp.ClearBreakpoint(20)
+	assert.Equal(t, []int{10, 30}, interp.Breakpoints())
+
+	// Without a debugger breakpoints are ignored
+	require.NoError(t, interp.Execute(parsertest.Parse(t, debugProgram)))
+}
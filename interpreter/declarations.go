@@ -0,0 +1,72 @@
+// ABOUTME: Read-only constants (CONST) and the OPTION EXPLICIT mode requiring declared variables
+// ABOUTME: Under OPTION EXPLICIT variables must be introduced by LET, DIM, CONST or DEF FN before use
+
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"basic-interpreter/types"
+)
+
+// Errors raised by declaration checks
+var (
+	ErrConstant          = fmt.Errorf("?CONSTANT ERROR")
+	ErrUndefinedVariable = fmt.Errorf("?UNDEFINED VARIABLE ERROR")
+	ErrUnknownOption     = fmt.Errorf("?SYNTAX ERROR: unknown OPTION")
+)
+
+// optionExplicit is the OPTION requiring variables to be declared
+const optionExplicit = "EXPLICIT"
+
+// DeclareVariable implements LET: the variable becomes usable under OPTION EXPLICIT
+func (i *Interpreter) DeclareVariable(name string) error {
+	i.declared[i.NormalizeVariableName(name)] = true
+	return nil
+}
+
+// DefineConstant implements CONST. Running the same CONST again with an equal value is allowed.
+func (i *Interpreter) DefineConstant(name string, value types.Value) error {
+	norm := i.NormalizeVariableName(name)
+	if IsReservedVariable(name, i.dialect) {
+		return ErrConstant
+	}
+	if i.constants[norm] {
+		if current := i.variables[norm]; current == value {
+			return nil
+		}
+		return ErrConstant
+	}
+	i.declared[norm] = true
+	if err := i.SetVariable(name, value); err != nil {
+		return err
+	}
+	i.constants[norm] = true
+	return nil
+}
+
+// SetOption implements OPTION; EXPLICIT is the only option
+func (i *Interpreter) SetOption(name string) error {
+	if strings.ToUpper(name) != optionExplicit {
+		return ErrUnknownOption
+	}
+	i.explicit = true
+	return nil
+}
+
+// checkReadable reports an undeclared variable under OPTION EXPLICIT
+func (i *Interpreter) checkReadable(norm string) error {
+	if i.explicit && !i.declared[norm] {
+		return ErrUndefinedVariable
+	}
+	return nil
+}
+
+// checkAssignable rejects assignments to constants and, under OPTION EXPLICIT, to undeclared variables
+func (i *Interpreter) checkAssignable(norm string) error {
+	if i.constants[norm] {
+		return ErrConstant
+	}
+	return i.checkReadable(norm)
+}
@@ -0,0 +1,51 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestInterpreter_Constants(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+
+	require.NoError(t, interp.DefineConstant("PI", types.NewNumberValue(3.14)))
+	require.NoError(t, interp.DefineConstant("PI", types.NewNumberValue(3.14)), "same value may be defined again")
+	assert.ErrorIs(t, interp.DefineConstant("PI", types.NewNumberValue(3)), ErrConstant)
+	assert.ErrorIs(t, interp.SetVariable("PI", types.NewNumberValue(1)), ErrConstant)
+	assert.ErrorIs(t, interp.DefineConstant("TI$", types.NewStringValue("000000")), ErrConstant)
+	assert.ErrorIs(t, interp.DefineConstant("S$", types.NewNumberValue(1)), types.ErrTypeMismatch)
+
+	// CLR forgets constants along with all other variables
+	require.NoError(t, interp.ClearVariables())
+	assert.NoError(t, interp.SetVariable("PI", types.NewNumberValue(1)))
+}
+
+func TestInterpreter_OptionExplicit(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.SetOption("explicit"))
+	assert.ErrorIs(t, interp.SetOption("BASE"), ErrUnknownOption)
+
+	_, err := interp.GetVariable("A")
+	assert.ErrorIs(t, err, ErrUndefinedVariable)
+	assert.ErrorIs(t, interp.SetVariable("A", types.NewNumberValue(1)), ErrUndefinedVariable)
+
+	require.NoError(t, interp.DeclareVariable("A"))
+	require.NoError(t, interp.SetVariable("A", types.NewNumberValue(1)))
+	v, err := interp.GetVariable("A")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, v.Number)
+
+	// Clock variables are always available
+	_, err = interp.GetVariable("TI")
+	assert.NoError(t, err)
+
+	// A new run starts permissive again
+	interp.Reset()
+	_, err = interp.GetVariable("B")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,197 @@
+// ABOUTME: Runtime errors as values: Error carries an ErrorCode, the line and statement it stopped at, and its cause
+// ABOUTME: Error text is the C64's canonical "?<NAME> ERROR IN <line>", with any detail after a colon
+
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NoLine is the Line of an error raised outside a numbered line: in immediate mode, or by a run limit
+const NoLine = -1
+
+// ErrorCode identifies the kind of a runtime error. C64 errors have the numbers the C64 ROM gives them; errors of
+// the extensions follow from 128. A code is itself an error, so errors.Is(err, CodeDivisionByZero) tests a kind.
+type ErrorCode int
+
+// Error codes
+const (
+	CodeUnknown ErrorCode = iota // An error not in C64 style, such as one from the host
+	CodeTooManyFiles
+	CodeFileOpen
+	CodeFileNotOpen
+	CodeFileNotFound
+	CodeDeviceNotPresent
+	CodeNotInputFile
+	CodeNotOutputFile
+	CodeMissingFileName
+	CodeIllegalDeviceNumber
+	CodeNextWithoutFor
+	CodeSyntax
+	CodeReturnWithoutGosub
+	CodeOutOfData
+	CodeIllegalQuantity
+	CodeOverflow
+	CodeOutOfMemory
+	CodeUndefinedStatement
+	CodeBadSubscript
+	CodeRedimArray
+	CodeDivisionByZero
+	CodeIllegalDirect
+	CodeTypeMismatch
+	CodeStringTooLong
+	CodeFileData
+	CodeFormulaTooComplex
+	CodeCantContinue
+	CodeUndefinedFunction
+	CodeVerify
+	CodeLoad
+	CodeBreak
+)
+
+// Error codes of the extensions
+const (
+	CodeWhileWithoutWend ErrorCode = 128 + iota
+	CodeWendWithoutWhile
+	CodeLoopWithoutDo
+	CodeUndefinedVariable
+	CodeConstant
+	CodeWriteProtect
+	CodeReplay
+	CodeInfiniteLoop
+	CodeTimeLimit
+	CodeCanceled
+)
+
+// codeNames are the names errors print with, between "?" and " ERROR"
+var codeNames = map[ErrorCode]string{
+	CodeTooManyFiles:        "TOO MANY FILES",
+	CodeFileOpen:            "FILE OPEN",
+	CodeFileNotOpen:         "FILE NOT OPEN",
+	CodeFileNotFound:        "FILE NOT FOUND",
+	CodeDeviceNotPresent:    "DEVICE NOT PRESENT",
+	CodeNotInputFile:        "NOT INPUT FILE",
+	CodeNotOutputFile:       "NOT OUTPUT FILE",
+	CodeMissingFileName:     "MISSING FILE NAME",
+	CodeIllegalDeviceNumber: "ILLEGAL DEVICE NUMBER",
+	CodeNextWithoutFor:      "NEXT WITHOUT FOR",
+	CodeSyntax:              "SYNTAX",
+	CodeReturnWithoutGosub:  "RETURN WITHOUT GOSUB",
+	CodeOutOfData:           "OUT OF DATA",
+	CodeIllegalQuantity:     "ILLEGAL QUANTITY",
+	CodeOverflow:            "OVERFLOW",
+	CodeOutOfMemory:         "OUT OF MEMORY",
+	CodeUndefinedStatement:  "UNDEFINED STATEMENT",
+	CodeBadSubscript:        "BAD SUBSCRIPT",
+	CodeRedimArray:          "REDIM'D ARRAY",
+	CodeDivisionByZero:      "DIVISION BY ZERO",
+	CodeIllegalDirect:       "ILLEGAL DIRECT",
+	CodeTypeMismatch:        "TYPE MISMATCH",
+	CodeStringTooLong:       "STRING TOO LONG",
+	CodeFileData:            "FILE DATA",
+	CodeFormulaTooComplex:   "FORMULA TOO COMPLEX",
+	CodeCantContinue:        "CAN'T CONTINUE",
+	CodeUndefinedFunction:   "UNDEF'D FUNCTION",
+	CodeVerify:              "VERIFY",
+	CodeLoad:                "LOAD",
+	CodeBreak:               "BREAK",
+	CodeWhileWithoutWend:    "WHILE WITHOUT WEND",
+	CodeWendWithoutWhile:    "WEND WITHOUT WHILE",
+	CodeLoopWithoutDo:       "LOOP WITHOUT DO",
+	CodeUndefinedVariable:   "UNDEFINED VARIABLE",
+	CodeConstant:            "CONSTANT",
+	CodeWriteProtect:        "WRITE PROTECT",
+	CodeReplay:              "REPLAY",
+	CodeInfiniteLoop:        "INFINITE LOOP",
+	CodeTimeLimit:           "TIME LIMIT",
+	CodeCanceled:            "CANCELED",
+}
+
+// codesByName looks codes up by the name in an error message
+var codesByName = func() map[string]ErrorCode {
+	codes := make(map[string]ErrorCode, len(codeNames))
+	for code, name := range codeNames {
+		codes[name] = code
+	}
+	return codes
+}()
+
+// String returns the error's name, such as "DIVISION BY ZERO"
+func (c ErrorCode) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("ErrorCode(%d)", int(c))
+}
+
+// Error returns the message of the code, such as "?DIVISION BY ZERO ERROR"
+func (c ErrorCode) Error() string {
+	return "?" + c.String() + " ERROR"
+}
+
+// Error is a runtime error that stopped a program
+type Error struct {
+	Code      ErrorCode // Kind of error, from the name in Err's message
+	Line      int       // BASIC line the error stopped at, or NoLine
+	Statement int       // Index of the statement within the line, -1 when unknown
+	Err       error     // The error raised, such as types.ErrDivisionByZero
+}
+
+// Error reads "?DIVISION BY ZERO ERROR IN 10", or "?TYPE MISMATCH ERROR IN 10: LEN requires string argument" when
+// the error carries a detail. Errors not in C64 style read "?ERROR IN 10: " and their message; without a line, an
+// error reads as the message of Err. A break reads as the C64 shows it, "BREAK IN 10" or "BREAK".
+func (e *Error) Error() string {
+	if e.Code == CodeBreak {
+		if e.Line == NoLine {
+			return "BREAK"
+		}
+		return fmt.Sprintf("BREAK IN %d", e.Line)
+	}
+	if e.Line == NoLine {
+		return e.Err.Error()
+	}
+	name, detail := splitErrorMessage(e.Err.Error())
+	text := fmt.Sprintf("%s IN %d", name, e.Line)
+	if detail != "" {
+		text += ": " + detail
+	}
+	return text
+}
+
+// Unwrap returns the error raised
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the error's code
+func (e *Error) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code == e.Code
+}
+
+// AtLine returns err as an *Error raised at a statement of a BASIC line; statement is -1 when not known. An error
+// that is already an *Error is returned unchanged, keeping the first line named.
+func AtLine(err error, line, statement int) error {
+	var named *Error
+	if errors.As(err, &named) {
+		return err
+	}
+	name, _ := splitErrorMessage(err.Error())
+	code := codesByName[strings.TrimSuffix(strings.TrimPrefix(name, "?"), " ERROR")]
+	return &Error{Code: code, Line: line, Statement: statement, Err: err}
+}
+
+// splitErrorMessage separates "?NAME ERROR" from the detail that may follow it
+func splitErrorMessage(msg string) (name, detail string) {
+	if !strings.HasPrefix(msg, "?") {
+		return "?ERROR", msg
+	}
+	end := strings.Index(msg, " ERROR")
+	if end < 0 {
+		return msg, ""
+	}
+	end += len(" ERROR")
+	return msg[:end], strings.TrimLeft(msg[end:], ": ")
+}
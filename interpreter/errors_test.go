@@ -0,0 +1,69 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestAtLine(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+		code ErrorCode
+	}{
+		{"plain", types.ErrDivisionByZero, "?DIVISION BY ZERO ERROR IN 10", CodeDivisionByZero},
+		{"detail", errors.New("?TYPE MISMATCH ERROR: LEN requires string argument"), "?TYPE MISMATCH ERROR IN 10: LEN requires string argument", CodeTypeMismatch},
+		{"wrapped detail", fmt.Errorf("%w: NO LINE 99", ErrUndefinedStatement), "?UNDEFINED STATEMENT ERROR IN 10: NO LINE 99", CodeUndefinedStatement},
+		{"extension", errors.New("?WEND WITHOUT WHILE ERROR"), "?WEND WITHOUT WHILE ERROR IN 10", CodeWendWithoutWhile},
+		{"not c64 style", errors.New("disk full"), "?ERROR IN 10: disk full", CodeUnknown},
+		{"no error word", errors.New("?REDO FROM START"), "?REDO FROM START IN 10", CodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AtLine(tt.err, 10, 2)
+			assert.EqualError(t, err, tt.want)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorIs(t, err, tt.code)
+			assert.Same(t, err, AtLine(err, 20, 0), "the first line named is kept")
+
+			var e *Error
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, Error{Code: tt.code, Line: 10, Statement: 2, Err: tt.err}, *e)
+		})
+	}
+}
+
+func TestAtLine_NoLine(t *testing.T) {
+	err := AtLine(ErrInfiniteLoop, NoLine, 0)
+	assert.EqualError(t, err, "?INFINITE LOOP ERROR")
+	assert.ErrorIs(t, err, CodeInfiniteLoop)
+	assert.NotErrorIs(t, err, CodeTimeLimit)
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, ErrorCode(20), CodeDivisionByZero, "C64 codes keep the ROM's numbers")
+	assert.Equal(t, "?BREAK ERROR", CodeBreak.Error())
+	assert.Equal(t, "ErrorCode(99)", ErrorCode(99).String())
+}
+
+func TestExecute_ErrorCarriesCodeAndStatement(t *testing.T) {
+	program := parser.New(lexer.New("10 A=1\n20 A=2: B=A/0: A=3")).ParseProgram()
+	err := NewInterpreter(runtime.NewTestRuntime()).Execute(program)
+
+	var e *Error
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, CodeDivisionByZero, e.Code)
+	assert.Equal(t, 20, e.Line)
+	assert.Equal(t, 1, e.Statement)
+	assert.ErrorIs(t, err, types.ErrDivisionByZero)
+}
@@ -0,0 +1,263 @@
+// ABOUTME: Logical files for OPEN, CLOSE, PRINT#, INPUT# and GET#: a registry of file numbers open on devices
+// ABOUTME: The keyboard (0) and screen (3) are built in, other devices open through runtime.Files; ST holds the status
+
+package interpreter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// File errors
+var (
+	ErrTooManyFiles    = fmt.Errorf("?TOO MANY FILES ERROR")
+	ErrFileOpen        = fmt.Errorf("?FILE OPEN ERROR")
+	ErrFileNotOpen     = fmt.Errorf("?FILE NOT OPEN ERROR")
+	ErrFileNotFound    = fmt.Errorf("?FILE NOT FOUND ERROR")
+	ErrNotInputFile    = fmt.Errorf("?NOT INPUT FILE ERROR")
+	ErrNotOutputFile   = fmt.Errorf("?NOT OUTPUT FILE ERROR")
+	ErrMissingFileName = fmt.Errorf("?MISSING FILE NAME ERROR")
+	ErrWriteProtect    = fmt.Errorf("?WRITE PROTECT ERROR")
+)
+
+// Limits of OPEN, as on the C64
+const (
+	maxOpenFiles  = 10
+	maxFileNumber = 255
+	maxDevice     = 30
+	maxChannel    = 31
+)
+
+// Devices the interpreter provides itself
+const (
+	keyboardDevice = 0
+	screenDevice   = 3
+)
+
+// statusEndOfFile is the ST bit set once a read reaches the end of a file
+const statusEndOfFile = 64
+
+// logicalFile is a file number opened by OPEN
+type logicalFile struct {
+	device  int
+	mode    runtime.FileMode
+	file    runtime.File  // nil on the keyboard and the screen
+	reader  *bufio.Reader // Reads file when it was opened for reading
+	afterCR bool          // A CR ended the last line read from a stream, so a LF starting the next one belongs to it
+}
+
+// IsStatusVariable reports whether name refers to ST, the status of the last file operation; in the c64 dialect
+// that is any name starting with ST
+func IsStatusVariable(name string, d parser.Dialect) bool {
+	return strings.ToUpper(VariableName(name, d)) == "ST"
+}
+
+// IsReservedVariable reports whether name is a variable the interpreter keeps rather than the program: a clock
+// variable or ST
+func IsReservedVariable(name string, d parser.Dialect) bool {
+	return IsClockVariable(name, d) || IsStatusVariable(name, d)
+}
+
+// SetFiles sets the devices OPEN reaches beyond the keyboard and screen, in place of the runtime's own; nil
+// returns to the runtime's
+func (i *Interpreter) SetFiles(files runtime.Files) {
+	i.devices = files
+}
+
+// OpenFile implements OPEN: file numbers 1-255 open on a device with a secondary address (channel) and a name
+func (i *Interpreter) OpenFile(file, device, channel int, name string) error {
+	if file < 1 || file > maxFileNumber || device > maxDevice || channel > maxChannel {
+		return ErrIllegalQuantity
+	}
+	if i.files[file] != nil {
+		return ErrFileOpen
+	}
+	if len(i.files) >= maxOpenFiles {
+		return ErrTooManyFiles
+	}
+	lf := &logicalFile{device: device}
+	switch device {
+	case keyboardDevice:
+		lf.mode = runtime.FileRead
+	case screenDevice:
+		lf.mode = runtime.FileWrite
+	default:
+		if device >= runtime.DiskDevice && name == "" {
+			return ErrMissingFileName
+		}
+		files, ok := i.devices, i.devices != nil
+		if !ok {
+			files, ok = i.runtime.(runtime.Files)
+		}
+		if !ok {
+			return ErrDeviceNotPresent
+		}
+		path, mode := runtime.ParseFileName(name, channel)
+		f, err := files.OpenFile(device, channel, path, mode)
+		if err != nil {
+			return fileError(err)
+		}
+		lf.file, lf.mode = f, mode
+		if mode == runtime.FileRead {
+			lf.reader = bufio.NewReader(f)
+		}
+	}
+	if i.files == nil {
+		i.files = make(map[int]*logicalFile)
+	}
+	i.files[file] = lf
+	i.status = 0
+	return nil
+}
+
+// fileError returns the C64 error for a failure to open a file
+func fileError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrFileNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrWriteProtect
+	case errors.Is(err, runtime.ErrDeviceNotPresent):
+		return ErrDeviceNotPresent
+	}
+	return err
+}
+
+// CloseFile implements CLOSE; closing a file number that is not open does nothing
+func (i *Interpreter) CloseFile(file int) error {
+	lf, ok := i.files[file]
+	if !ok {
+		return nil
+	}
+	delete(i.files, file)
+	if lf.file == nil {
+		return nil
+	}
+	return lf.file.Close()
+}
+
+// CloseFiles closes every open file, as CLR, NEW and RUN do. Hosts call it once a run ends.
+func (i *Interpreter) CloseFiles() error {
+	var errs []error
+	for file := range i.files {
+		errs = append(errs, i.CloseFile(file))
+	}
+	return errors.Join(errs...)
+}
+
+// WriteFile implements PRINT#
+func (i *Interpreter) WriteFile(file int, text string) error {
+	lf, ok := i.files[file]
+	if !ok {
+		return ErrFileNotOpen
+	}
+	if lf.mode == runtime.FileRead {
+		return ErrNotOutputFile
+	}
+	i.status = 0
+	if lf.device == screenDevice {
+		if line, ok := strings.CutSuffix(text, "\n"); ok {
+			return i.PrintLine(line)
+		}
+		return i.Print(text)
+	}
+	_, err := io.WriteString(lf.file, text)
+	return err
+}
+
+// ReadFileLine implements INPUT#: it reads up to the next CR, LF or CR LF
+func (i *Interpreter) ReadFileLine(file int) (string, error) {
+	lf, err := i.inputFile(file)
+	if err != nil {
+		return "", err
+	}
+	if lf.device == keyboardDevice {
+		return i.ReadInput("")
+	}
+	var line strings.Builder
+	for {
+		b, err := lf.reader.ReadByte()
+		if err != nil {
+			return line.String(), i.readStatus(lf, err)
+		}
+		if b == '\n' && lf.afterCR && line.Len() == 0 {
+			lf.afterCR = false
+			continue
+		}
+		lf.afterCR = false
+		if b == '\r' && lf.waiting() {
+			lf.afterCR = true
+		} else if b == '\r' {
+			if next, err := lf.reader.Peek(1); err == nil && next[0] == '\n' {
+				lf.reader.Discard(1)
+			}
+		}
+		if b == '\r' || b == '\n' {
+			return line.String(), i.readStatus(lf, nil)
+		}
+		line.WriteByte(b)
+	}
+}
+
+// ReadFileChar implements GET#: it reads one character, "" at the end of the file
+func (i *Interpreter) ReadFileChar(file int) (string, error) {
+	lf, err := i.inputFile(file)
+	if err != nil {
+		return "", err
+	}
+	if lf.device == keyboardDevice {
+		return i.ReadKey()
+	}
+	lf.afterCR = false
+	b, err := lf.reader.ReadByte()
+	if err != nil {
+		return "", i.readStatus(lf, err)
+	}
+	return string(rune(b)), i.readStatus(lf, nil)
+}
+
+// inputFile returns an open file that can be read
+func (i *Interpreter) inputFile(file int) (*logicalFile, error) {
+	lf, ok := i.files[file]
+	if !ok {
+		return nil, ErrFileNotOpen
+	}
+	if lf.mode != runtime.FileRead {
+		return nil, ErrNotInputFile
+	}
+	return lf, nil
+}
+
+// waiting reports whether looking ahead in a file would wait: it is a stream with no data arrived yet
+func (lf *logicalFile) waiting() bool {
+	s, ok := lf.file.(runtime.Stream)
+	return ok && lf.reader.Buffered() == 0 && !s.Ready()
+}
+
+// readStatus sets ST after a read that failed with err, or succeeded when err is nil: end of file once nothing is
+// left to read. A stream still waiting for data is not at its end.
+func (i *Interpreter) readStatus(lf *logicalFile, err error) error {
+	if err == nil && lf.waiting() {
+		i.status = 0
+		return nil
+	}
+	if err == nil {
+		_, err = lf.reader.Peek(1)
+	}
+	switch {
+	case err == nil:
+		i.status = 0
+	case errors.Is(err, io.EOF):
+		i.status = statusEndOfFile
+	default:
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package interpreter
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+func TestInterpreter_FilesRoundTrip(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	src := `10 OPEN 2,8,2,"0:SCORES,S,W"
+20 FOR I=1 TO 3: PRINT#2,"P";I;",";I*10: NEXT
+30 CLOSE 2
+40 OPEN 2,8,2,"SCORES"
+50 INPUT#2,N$,S: PRINT N$;S;ST: IF ST=0 THEN 50
+60 CLOSE 2`
+	require.NoError(t, NewInterpreter(rt).Execute(parsertest.Parse(t, src)))
+
+	content, ok := rt.FileContent("SCORES")
+	require.True(t, ok)
+	assert.Equal(t, "P 1, 10\nP 2, 20\nP 3, 30\n", content)
+	assert.Equal(t, []string{"P 1 10 0\n", "P 2 20 0\n", "P 3 30 64\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_GetFile(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	rt.SetFile("F", "AB\r\nC")
+	src := `10 OPEN 1,8,0,"F"
+20 GET#1,C$: PRINT ASC(C$);: IF ST=0 THEN 20
+30 GET#1,C$: PRINT LEN(C$);ST
+40 OPEN 2,8,0,"F": INPUT#2,A$,B$: PRINT A$;"/";B$;"/";ST`
+	require.NoError(t, NewInterpreter(rt).Execute(parsertest.Parse(t, src)))
+	assert.Equal(t, []string{"65", "66", "13", "10", "67", "0 64\n", "AB/C/ 64\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_FileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{name: "not open", src: `10 PRINT#1,"X"`, err: "?FILE NOT OPEN ERROR IN 10"},
+		{name: "not found", src: `10 OPEN 1,8,2,"NOPE"`, err: "?FILE NOT FOUND ERROR IN 10"},
+		{name: "already open", src: "10 OPEN 1,3: OPEN 1,3", err: "?FILE OPEN ERROR IN 10"},
+		{name: "too many", src: "10 FOR I=1 TO 11: OPEN I,3: NEXT", err: "?TOO MANY FILES ERROR IN 10"},
+		{name: "read the screen", src: "10 OPEN 1,3: INPUT#1,A$", err: "?NOT INPUT FILE ERROR IN 10"},
+		{name: "write a file opened to read", src: `10 OPEN 1,8,2,"F": PRINT#1,"X"`, err: "?NOT OUTPUT FILE ERROR IN 10"},
+		{name: "no name on the disk", src: "10 OPEN 1,8,2", err: "?MISSING FILE NAME ERROR IN 10"},
+		{name: "device the runtime lacks", src: "10 OPEN 1,4", err: "?DEVICE NOT PRESENT ERROR IN 10"},
+		{name: "file number 0", src: "10 OPEN 0,3", err: "?ILLEGAL QUANTITY ERROR IN 10"},
+		{name: "CLR closes files", src: "10 OPEN 1,3: CLR: PRINT#1", err: "?FILE NOT OPEN ERROR IN 10"},
+		{name: "bad number", src: `10 OPEN 1,8,2,"F": INPUT#1,N`, err: "?FILE DATA ERROR IN 10"},
+		{name: "ST is read-only", src: "10 ST=1", err: "?SYNTAX ERROR IN 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := runtime.NewTestRuntime()
+			rt.SetFile("F", "TEXT\n")
+			err := NewInterpreter(rt).Execute(parsertest.Parse(t, tt.src))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestInterpreter_FilesWithoutStorage(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(plainRuntime{rt})
+	err := interp.Execute(parsertest.Parse(t, `10 OPEN 1,3: PRINT#1,"SCREEN": OPEN 2,8,2,"F"`))
+	assert.EqualError(t, err, "?DEVICE NOT PRESENT ERROR IN 10")
+	assert.Equal(t, []string{"SCREEN\n"}, rt.GetOutput())
+	require.NoError(t, interp.CloseFiles())
+}
+
+func TestInterpreter_SetFiles(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	disk := &runtime.Disk{}
+	interp := NewInterpreter(plainRuntime{rt})
+	interp.SetFiles(disk)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, `10 OPEN 1,8,1,"F": PRINT#1,"DISK": CLOSE 1`)))
+
+	content, ok := disk.FileContent("F")
+	require.True(t, ok)
+	assert.Equal(t, "DISK\n", content)
+
+	interp.SetFiles(readOnlyFiles{})
+	err := interp.Execute(parsertest.Parse(t, `10 OPEN 1,8,1,"F"`))
+	assert.EqualError(t, err, "?WRITE PROTECT ERROR IN 10")
+}
+
+// readOnlyFiles refuses every file as a host directory without write permission does
+type readOnlyFiles struct{}
+
+func (readOnlyFiles) OpenFile(device, channel int, name string, mode runtime.FileMode) (runtime.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestInterpreter_StreamsAreNotReadAhead(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	link := &streamFile{chunks: []string{"HELLO\r", "\nBYE\n"}}
+	interp.SetFiles(streamFiles{link})
+	src := `10 OPEN 1,2,0,"PEER": INPUT#1,A$: PRINT A$;ST
+20 INPUT#1,B$: PRINT "[";B$;"]";ST`
+	require.NoError(t, interp.Execute(parsertest.Parse(t, src)))
+	assert.Equal(t, []string{"HELLO 0\n", "[BYE] 0\n"}, rt.GetOutput())
+}
+
+// streamFiles opens its stream on any device
+type streamFiles struct{ stream *streamFile }
+
+func (s streamFiles) OpenFile(device, channel int, name string, mode runtime.FileMode) (runtime.File, error) {
+	return s.stream, nil
+}
+
+// streamFile hands out one chunk per read and is never ready, so reading past the chunks is a look-ahead that
+// would wait
+type streamFile struct{ chunks []string }
+
+func (s *streamFile) Read(p []byte) (int, error) {
+	if len(s.chunks) == 0 {
+		return 0, errors.New("read would wait")
+	}
+	n := copy(p, s.chunks[0])
+	s.chunks = s.chunks[1:]
+	return n, nil
+}
+
+func (s *streamFile) Write(p []byte) (int, error) { return len(p), nil }
+func (s *streamFile) Close() error                { return nil }
+func (s *streamFile) Ready() bool                 { return false }
@@ -0,0 +1,78 @@
+// ABOUTME: Diagnostics for FOR/NEXT misuse that name the FOR statement behind the error
+// ABOUTME: Remembers recently closed loops so a late NEXT explains which NEXT closed it and where the FOR was
+
+package interpreter
+
+import "fmt"
+
+// closedLoop records how a FOR loop left the stack
+type closedLoop struct {
+	ForLine  int    // BASIC line of the FOR statement
+	ClosedBy string // Variable of the outer NEXT that discarded the loop, "" when the loop finished
+	NextLine int    // BASIC line of that NEXT
+}
+
+// ClosedLoops remembers FOR loops that recently left the stack, by normalized variable, so a NEXT without an
+// open loop can name the FOR it most likely belonged to. The zero value is empty; the VM and compiled
+// programs keep one too, so every engine explains NEXT WITHOUT FOR alike.
+type ClosedLoops struct {
+	loops map[string]closedLoop
+	last  string
+}
+
+// Close remembers the loop of variable, opened by a FOR on forLine, leaving the stack at a NEXT on nextLine;
+// closedBy is the variable of the outer NEXT that discarded it, or "" when the loop finished
+func (c *ClosedLoops) Close(variable string, forLine int, closedBy string, nextLine int) {
+	if c.loops == nil {
+		c.loops = make(map[string]closedLoop)
+	}
+	c.loops[variable] = closedLoop{ForLine: forLine, ClosedBy: closedBy, NextLine: nextLine}
+	c.last = variable
+}
+
+// Open forgets the closed loop of variable, as a FOR has opened it again
+func (c *ClosedLoops) Open(variable string) {
+	delete(c.loops, variable)
+}
+
+// NextWithoutFor explains a NEXT of variable, or a bare NEXT when variable is "", that has no open loop
+func (c *ClosedLoops) NextWithoutFor(variable string) error {
+	if variable == "" {
+		variable = c.last
+	}
+	closed, ok := c.loops[variable]
+	if !ok {
+		return ErrNextWithoutFor
+	}
+	if closed.ClosedBy != "" {
+		return fmt.Errorf("%w: FOR %s AT LINE %d WAS CLOSED BY NEXT %s AT LINE %d",
+			ErrNextWithoutFor, variable, closed.ForLine, closed.ClosedBy, closed.NextLine)
+	}
+	return fmt.Errorf("%w: FOR %s AT LINE %d HAS ALREADY FINISHED", ErrNextWithoutFor, variable, closed.ForLine)
+}
+
+// currentLineNumber returns the BASIC line number being executed
+func (i *Interpreter) currentLineNumber() int {
+	if i.running == nil || i.pc < 0 || i.pc >= len(i.running.Lines) {
+		return immediateLine
+	}
+	return i.running.Lines[i.pc].Number
+}
+
+// recordClosedLoop remembers a loop leaving the stack so a later NEXT for it can be explained
+func (i *Interpreter) recordClosedLoop(loop ForLoopContext, closedBy string) {
+	i.closedLoops.Close(loop.Variable, loop.ForLine, closedBy, i.currentLineNumber())
+}
+
+// nextWithoutFor explains a NEXT that has no open loop, naming the FOR it most likely belonged to
+func (i *Interpreter) nextWithoutFor(variable string) error {
+	if variable == "" {
+		return i.closedLoops.NextWithoutFor("")
+	}
+	return i.closedLoops.NextWithoutFor(i.NormalizeVariableName(variable))
+}
+
+// forStackOverflow explains running out of FOR stack, usually from jumping out of loops without finishing them
+func (i *Interpreter) forStackOverflow(variable string) error {
+	return fmt.Errorf("%w: TOO MANY OPEN FOR LOOPS AT FOR %s", ErrStackOverflow, i.NormalizeVariableName(variable))
+}
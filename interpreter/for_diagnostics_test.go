@@ -0,0 +1,39 @@
+package interpreter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestInterpreter_ForStackOverflowNamesTheLoop(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	one := types.NewNumberValue(1)
+
+	var err error
+	for n := 0; n < 200 && err == nil; n++ {
+		err = interp.BeginFor(fmt.Sprintf("%c%d", 'A'+n/10, n%10), one, one)
+	}
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStackOverflow)
+	assert.Contains(t, err.Error(), "TOO MANY OPEN FOR LOOPS AT FOR K0")
+}
+
+func TestInterpreter_ClosedLoopsForgottenByCLR(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	one := types.NewNumberValue(1)
+
+	require.NoError(t, interp.SetVariable("I", one))
+	require.NoError(t, interp.BeginFor("I", one, one))
+	require.NoError(t, interp.IterateFor("I"))
+	require.Error(t, interp.IterateFor("I"))
+	assert.Contains(t, interp.IterateFor("I").Error(), "HAS ALREADY FINISHED")
+
+	require.NoError(t, interp.ClearVariables())
+	assert.Equal(t, ErrNextWithoutFor, interp.IterateFor("I"))
+}
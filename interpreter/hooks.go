@@ -0,0 +1,106 @@
+// ABOUTME: Execution hooks: instrumentation called before each statement, on runtime errors and on jumps
+// ABOUTME: The trace and coverage recorders are hooks too; a hook failing a statement stops the run, as limiters need
+
+package interpreter
+
+import (
+	"slices"
+
+	"basic-interpreter/parser"
+)
+
+// Hooks observes a run of the tree walker. Line is the BASIC line number, -1 for an immediate line.
+type Hooks interface {
+	// OnStatement is called before a statement executes; an error stops the run as if the statement failed
+	OnStatement(line, stmtIndex int, stmt parser.Statement) error
+	// OnError is called with the error, line number included, that stops the run
+	OnError(line int, err error)
+	// OnJump is called when control moves anywhere but the next statement: GOTO, GOSUB, RETURN, loops and timers
+	OnJump(fromLine, toLine int)
+}
+
+// AddHooks calls h on the following runs, after the hooks added before it
+func (i *Interpreter) AddHooks(h Hooks) {
+	i.hooks = append(i.hooks, h)
+}
+
+// RemoveHooks stops calling h
+func (i *Interpreter) RemoveHooks(h Hooks) {
+	i.hooks = slices.DeleteFunc(i.hooks, func(other Hooks) bool { return other == h })
+}
+
+// beforeStatement calls OnStatement of each hook, stopping at the first error
+func (i *Interpreter) beforeStatement(line int, stmt parser.Statement) error {
+	for _, h := range i.hooks {
+		if err := h.OnStatement(line, i.stmtIndex, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterError calls OnError of each hook
+func (i *Interpreter) afterError(line int, err error) {
+	for _, h := range i.hooks {
+		h.OnError(line, err)
+	}
+}
+
+// afterJump calls OnJump of each hook when the jump lands on a program line
+func (i *Interpreter) afterJump(program *parser.Program, fromLine int) {
+	if len(i.hooks) == 0 || i.pc >= len(program.Lines) {
+		return
+	}
+	to := program.Lines[i.pc].Number
+	for _, h := range i.hooks {
+		h.OnJump(fromLine, to)
+	}
+}
+
+// traceHook records a run into a Trace
+type traceHook struct {
+	interp *Interpreter
+	trace  *Trace
+}
+
+func (h *traceHook) OnStatement(line, stmtIndex int, stmt parser.Statement) error {
+	h.trace.begin(h.interp.stepCount, line, stmtIndex)
+	return nil
+}
+
+func (h *traceHook) OnError(line int, err error) { h.trace.fail(err) }
+
+func (h *traceHook) OnJump(fromLine, toLine int) {}
+
+// coverageHook counts the statements executed on each program line
+type coverageHook struct {
+	coverage *Coverage
+}
+
+func (h *coverageHook) OnStatement(line, stmtIndex int, stmt parser.Statement) error {
+	if line != immediateLine {
+		h.coverage.Hits[line]++
+	}
+	return nil
+}
+
+func (h *coverageHook) OnError(line int, err error) {}
+
+func (h *coverageHook) OnJump(fromLine, toLine int) {}
+
+// replaceHooks swaps the built-in hook current, if any, for next, if any, keeping its place among the hooks; it returns next
+func (i *Interpreter) replaceHooks(current, next Hooks) Hooks {
+	n := -1
+	if current != nil {
+		n = slices.Index(i.hooks, current)
+	}
+	switch {
+	case n >= 0 && next != nil:
+		i.hooks[n] = next
+	case n >= 0:
+		i.hooks = slices.Delete(i.hooks, n, n+1)
+	case next != nil:
+		i.hooks = append(i.hooks, next)
+	}
+	return next
+}
@@ -0,0 +1,113 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+// recordingHooks logs every hook call, failing the statement at failAt when set
+type recordingHooks struct {
+	events []string
+	failAt int
+}
+
+func (h *recordingHooks) OnStatement(line, stmtIndex int, stmt parser.Statement) error {
+	h.events = append(h.events, fmt.Sprintf("%d:%d", line, stmtIndex))
+	if line == h.failAt {
+		return errors.New("?LIMIT ERROR")
+	}
+	return nil
+}
+
+func (h *recordingHooks) OnError(line int, err error) {
+	h.events = append(h.events, fmt.Sprintf("error %d %v", line, err))
+}
+
+func (h *recordingHooks) OnJump(fromLine, toLine int) {
+	h.events = append(h.events, fmt.Sprintf("jump %d>%d", fromLine, toLine))
+}
+
+func TestHooks_Events(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		failAt int
+		events []string
+		err    string
+	}{
+		{
+			name:   "statements and gosub",
+			src:    "10 GOSUB 30: PRINT 1\n20 END\n30 RETURN",
+			events: []string{"10:0", "jump 10>30", "30:0", "jump 30>10", "10:1", "20:0"},
+		},
+		{
+			name:   "loop",
+			src:    "10 FOR I=1 TO 2: NEXT",
+			events: []string{"10:0", "10:1", "jump 10>10", "10:1"},
+		},
+		{
+			name:   "runtime error",
+			src:    "10 GOTO 20\n20 PRINT 1/0",
+			events: []string{"10:0", "jump 10>20", "20:0", "error 20 ?DIVISION BY ZERO ERROR IN 20"},
+			err:    "?DIVISION BY ZERO ERROR IN 20",
+		},
+		{
+			name:   "hook stops the run",
+			src:    "10 A=1\n20 A=2",
+			failAt: 20,
+			events: []string{"10:0", "20:0", "error 20 ?LIMIT ERROR IN 20"},
+			err:    "?LIMIT ERROR IN 20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := &recordingHooks{failAt: tt.failAt}
+			interp := NewInterpreter(runtime.NewTestRuntime())
+			interp.AddHooks(hooks)
+			err := interp.Execute(parsertest.Parse(t, tt.src))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.events, hooks.events)
+		})
+	}
+}
+
+func TestHooks_StopFailedStatement(t *testing.T) {
+	hooks := &recordingHooks{failAt: 10}
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.AddHooks(hooks)
+	require.Error(t, interp.Execute(parsertest.Parse(t, "10 A=1")))
+	assert.Equal(t, 0.0, interp.Variables()["A"].Number)
+}
+
+func TestHooks_RemoveAndBuiltins(t *testing.T) {
+	hooks := &recordingHooks{}
+	coverage := NewCoverage()
+	trace := NewTrace(TraceLimits{})
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetCoverage(coverage)
+	interp.AddHooks(hooks)
+	interp.SetTrace(trace)
+	interp.SetCoverage(NewCoverage()) // Replacing keeps a single coverage hook
+	interp.SetCoverage(coverage)
+	assert.Len(t, interp.hooks, 3)
+
+	interp.RemoveHooks(hooks)
+	interp.SetTrace(nil)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, "10 A=1: B=2")))
+	assert.Empty(t, hooks.events)
+	assert.Empty(t, trace.Events)
+	assert.Equal(t, map[int]int{10: 2}, coverage.Hits)
+	assert.Len(t, interp.hooks, 1)
+}
@@ -0,0 +1,99 @@
+// ABOUTME: Host extensions: Go functions and statements an embedder registers so BASIC programs can use them
+// ABOUTME: The parser must be told the names too (SetHostFunctions, SetHostStatements), or it reads them as variables
+
+package interpreter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// HostFunction is a Go function callable from BASIC. Functions named with $ must return a string, others a number.
+type HostFunction func(args []types.Value) (types.Value, error)
+
+// HostStatementFunc runs a statement the host registered, with its arguments evaluated
+type HostStatementFunc func(args []types.Value) error
+
+// RegisterFunction makes fn callable from BASIC as name(args...), replacing any function registered under that name.
+// The name must read as a single identifier and cannot be a built-in function or start with FN.
+func (i *Interpreter) RegisterFunction(name string, fn HostFunction) error {
+	upper := strings.ToUpper(name)
+	if err := checkHostName(upper, "("); err != nil {
+		return err
+	}
+	if strings.HasPrefix(upper, "FN") || slices.Contains(parser.BuiltinFunctions(), upper) {
+		return fmt.Errorf("?SYNTAX ERROR: %s is reserved for built-in and DEF FN functions", upper)
+	}
+	if i.hostFunctions == nil {
+		i.hostFunctions = make(map[string]HostFunction)
+	}
+	i.hostFunctions[upper] = fn
+	return nil
+}
+
+// HostFunctions returns the names of the registered functions, sorted, for Parser.SetHostFunctions
+func (i *Interpreter) HostFunctions() []string {
+	return sortedKeys(i.hostFunctions)
+}
+
+// RegisterStatement makes fn run for statements NAME [expr[, expr...]], replacing any statement registered under
+// that name. The name must read as a single identifier, so keywords cannot be redefined.
+func (i *Interpreter) RegisterStatement(name string, fn HostStatementFunc) error {
+	upper := strings.ToUpper(name)
+	if err := checkHostName(upper, " "); err != nil {
+		return err
+	}
+	if i.hostStatements == nil {
+		i.hostStatements = make(map[string]HostStatementFunc)
+	}
+	i.hostStatements[upper] = fn
+	return nil
+}
+
+// HostStatements returns the names of the registered statements, sorted, for Parser.SetHostStatements
+func (i *Interpreter) HostStatements() []string {
+	return sortedKeys(i.hostStatements)
+}
+
+// CallStatement runs a registered statement
+func (i *Interpreter) CallStatement(name string, args []types.Value) error {
+	fn, ok := i.hostStatements[strings.ToUpper(name)]
+	if !ok {
+		return fmt.Errorf("?SYNTAX ERROR: unknown statement %s", name)
+	}
+	return fn(args)
+}
+
+// callHost calls a registered function, checking its result against the $ suffix of its name
+func (i *Interpreter) callHost(name string, fn HostFunction, args []types.Value) (types.Value, error) {
+	result, err := fn(args)
+	if err != nil {
+		return types.Value{}, err
+	}
+	if want := strings.HasSuffix(name, "$"); (result.Type == types.StringType) != want {
+		kind := "A NUMBER"
+		if want {
+			kind = "A STRING"
+		}
+		return types.Value{}, fmt.Errorf("%w: %s MUST RETURN %s", types.ErrTypeMismatch, name, kind)
+	}
+	return i.interned.InternValue(result), nil
+}
+
+// checkHostName reports a name the lexer would not read back as one identifier before follow in every dialect,
+// such as a keyword or, as the c64 dialect splits crunched keywords off, TOX
+func checkHostName(name, follow string) error {
+	for _, d := range []lexer.Dialect{lexer.DialectExtended, lexer.DialectC64} {
+		l := lexer.New(name + follow)
+		l.SetDialect(d)
+		if tok := l.NextToken(); tok.Type != lexer.IDENT || tok.Literal != name {
+			return fmt.Errorf("?SYNTAX ERROR: %q is not a valid name", name)
+		}
+	}
+	return nil
+}
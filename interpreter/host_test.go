@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// hostInterpreter registers UPPER$ and ADD, the latter failing on negative arguments
+func hostInterpreter(t *testing.T) (*Interpreter, *runtime.TestRuntime) {
+	t.Helper()
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.RegisterFunction("upper$", func(args []types.Value) (types.Value, error) {
+		return types.NewStringValue(strings.ToUpper(args[0].String)), nil
+	}))
+	require.NoError(t, interp.RegisterFunction("ADD", func(args []types.Value) (types.Value, error) {
+		sum := 0.0
+		for _, arg := range args {
+			if arg.Number < 0 {
+				return types.Value{}, errors.New("?ILLEGAL QUANTITY ERROR")
+			}
+			sum += arg.Number
+		}
+		return types.NewNumberValue(sum), nil
+	}))
+	require.NoError(t, interp.RegisterFunction("WRONG", func(args []types.Value) (types.Value, error) {
+		return types.NewStringValue("X"), nil
+	}))
+	return interp, rt
+}
+
+func TestRegisterFunctionCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{"string function", `10 PRINT UPPER$("abc")+"!"`, "ABC!\n", ""},
+		{"any argument count", "10 PRINT ADD(1,2,3);ADD()", "6 0\n", ""},
+		{"nested in expressions", `10 A(2)=5: PRINT ADD(A(2),LEN(UPPER$("xy")))`, "7\n", ""},
+		{"host error gets the line", "10 PRINT ADD(-1)", "", "?ILLEGAL QUANTITY ERROR IN 10"},
+		{"result must match the name", "10 PRINT WRONG()", "", "?TYPE MISMATCH ERROR IN 10: WRONG MUST RETURN A NUMBER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp, rt := hostInterpreter(t)
+			p := parser.New(lexer.New(tt.src))
+			p.SetHostFunctions(interp.HostFunctions())
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+
+			err := interp.Execute(program)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, strings.Join(rt.GetOutput(), ""))
+		})
+	}
+}
+
+func TestRegisterFunctionRejectsNames(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	fn := func(args []types.Value) (types.Value, error) { return types.NewNumberValue(0), nil }
+	for _, name := range []string{"LEN", "FNX", "PRINT", "TOX", "A B", "", "1X"} {
+		assert.Error(t, interp.RegisterFunction(name, fn), name)
+	}
+	assert.Empty(t, interp.HostFunctions())
+}
+
+func TestRegisterStatementRuns(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	var calls [][]types.Value
+	require.NoError(t, interp.RegisterStatement("beep", func(args []types.Value) error {
+		calls = append(calls, args)
+		if len(args) > 0 && args[0].Number < 0 {
+			return errors.New("?ILLEGAL QUANTITY ERROR")
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"BEEP"}, interp.HostStatements())
+
+	p := parser.New(lexer.New("10 FOR I=1 TO 2: BEEP I*10, \"X\": NEXT: BEEP\n20 BEEP -1"))
+	p.SetHostStatements(interp.HostStatements())
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	assert.EqualError(t, interp.Execute(program), "?ILLEGAL QUANTITY ERROR IN 20")
+	assert.Equal(t, [][]types.Value{
+		{types.NewNumberValue(10), types.NewStringValue("X")},
+		{types.NewNumberValue(20), types.NewStringValue("X")},
+		{},
+		{types.NewNumberValue(-1)},
+	}, calls)
+}
+
+func TestRegisterStatementRejectsNames(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	fn := func(args []types.Value) error { return nil }
+	for _, name := range []string{"PRINT", "GOTO", "A B", "", "1X"} {
+		assert.Error(t, interp.RegisterStatement(name, fn), name)
+	}
+	assert.Empty(t, interp.HostStatements())
+	assert.EqualError(t, interp.CallStatement("BEEP", nil), "?SYNTAX ERROR: unknown statement BEEP")
+}
@@ -0,0 +1,133 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+func parseImmediate(t *testing.T, src string) []parser.Statement {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	stmts := p.ParseStatements()
+	require.Nil(t, p.ParseError())
+	return stmts
+}
+
+func TestInterpreter_ExecuteImmediate(t *testing.T) {
+	p := parser.New(lexer.New("10 A=5\n20 PRINT \"LINE\";A\n30 DATA 1,2"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	t.Run("statements share variables with the program", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		interp := NewInterpreter(rt)
+		require.NoError(t, interp.Execute(program))
+		require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "PRINT A*2")))
+		assert.Equal(t, []string{"LINE 5\n", "10\n"}, rt.GetOutput())
+	})
+
+	t.Run("loops run within the immediate line", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		interp := NewInterpreter(rt)
+		require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "FOR I=1 TO 3: PRINT I;: NEXT")))
+		assert.Equal(t, []string{"1", "2", "3"}, rt.GetOutput())
+	})
+
+	t.Run("GOTO continues into the program and stops at its end", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		interp := NewInterpreter(rt)
+		require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "A=7: GOTO 20")))
+		assert.Equal(t, []string{"LINE 7\n"}, rt.GetOutput())
+	})
+
+	t.Run("DATA pointer persists between immediate lines", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		interp := NewInterpreter(rt)
+		require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "READ X: PRINT X")))
+		require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "READ X: PRINT X")))
+		assert.Equal(t, []string{"1\n", "2\n"}, rt.GetOutput())
+	})
+
+	t.Run("errors carry no line number", func(t *testing.T) {
+		interp := NewInterpreter(runtime.NewTestRuntime())
+		err := interp.ExecuteImmediate(program, parseImmediate(t, "PRINT 1/0"))
+		require.Error(t, err)
+		assert.Equal(t, "?DIVISION BY ZERO ERROR", err.Error())
+	})
+}
+
+func TestInterpreter_Reset(t *testing.T) {
+	p := parser.New(lexer.New("10 A=5: DIM B(3): DEF FNF(X)=X\n20 READ D: PRINT A;D\n30 DATA 9"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.Execute(program))
+
+	interp.Reset()
+	require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "PRINT A: READ D: PRINT D: DIM B(1)")))
+	assert.Equal(t, []string{"5 9\n", "0\n", "9\n"}, rt.GetOutput())
+	assert.Error(t, interp.ExecuteImmediate(program, parseImmediate(t, "PRINT FNF(1)")))
+}
+
+func TestInterpreter_RestartKeepsSimpleVariables(t *testing.T) {
+	p := parser.New(lexer.New("10 A=A+5: DIM B(3): B(1)=2: READ D\n20 DATA 9"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.Execute(program))
+
+	interp.Restart()
+	require.NoError(t, interp.Execute(program))
+	require.NoError(t, interp.ExecuteImmediate(program, parseImmediate(t, "PRINT A;B(1);D")))
+	assert.Equal(t, []string{"10 2 9\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_ExecuteFrom(t *testing.T) {
+	p := parser.New(lexer.New("10 PRINT \"A\"\n20 PRINT \"B\"\n30 READ D: PRINT D\n40 DATA 7"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.ExecuteFrom(program, 20))
+	assert.Equal(t, []string{"B\n", "7\n"}, rt.GetOutput())
+	assert.ErrorIs(t, interp.ExecuteFrom(program, 25), ErrUndefinedStatement)
+}
+
+func TestInterpreter_NewErasesProgram(t *testing.T) {
+	p := parser.New(lexer.New("10 A=5: NEW: PRINT \"NOT REACHED\""))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.Execute(program))
+	assert.True(t, interp.ProgramErased())
+	assert.Empty(t, rt.GetOutput())
+
+	require.NoError(t, interp.ExecuteImmediate(&parser.Program{}, parseImmediate(t, "PRINT A")))
+	assert.False(t, interp.ProgramErased())
+	assert.Equal(t, []string{"0\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_UndefinedGosubLeavesNoReturnAddress(t *testing.T) {
+	program := &parser.Program{}
+	interp := NewInterpreter(runtime.NewTestRuntime())
+
+	err := interp.ExecuteImmediate(program, parseImmediate(t, "GOSUB 50"))
+	require.ErrorIs(t, err, ErrUndefinedStatement)
+	assert.EqualError(t, err, "?UNDEFINED STATEMENT ERROR: NO LINE 50")
+
+	err = interp.ExecuteImmediate(program, parseImmediate(t, "RETURN"))
+	assert.ErrorIs(t, err, ErrReturnWithoutGosub)
+}
@@ -0,0 +1,159 @@
+// ABOUTME: Variable inspector and watch expressions for debug mode
+// ABOUTME: Evaluates BASIC expressions against the live state and lists variables, arrays and the loop and GOSUB stacks
+
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// Inspection is a copy of the program state a paused run shows
+type Inspection struct {
+	Variables map[string]types.Value
+	Arrays    map[string]ArrayInfo
+	Gosubs    []int      // Lines of the GOSUBs awaiting RETURN, outermost first
+	ForLoops  []ForFrame // Open FOR loops, outermost first
+	Whiles    []int      // Lines of the open WHILE loops, outermost first
+	Dos       []int      // Lines of the open DO loops, outermost first
+}
+
+// ForFrame is an open FOR loop
+type ForFrame struct {
+	Variable string
+	Line     int         // Line of the FOR statement
+	End      types.Value // Final value
+	Step     types.Value
+}
+
+// Watch is a watch expression and its value at the last pause
+type Watch struct {
+	Source string
+	Value  types.Value
+	Err    error // Why the expression could not be evaluated
+}
+
+// EvaluateExpression parses and evaluates a BASIC expression against the current variables, as a debugger does while paused
+func (i *Interpreter) EvaluateExpression(src string) (types.Value, error) {
+	p := parser.New(lexer.New(src))
+	expr := p.ParseExpression()
+	if e := p.ParseError(); e != nil {
+		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: %s", e.Message)
+	}
+	return expr.Evaluate(i)
+}
+
+// AddWatch adds an expression evaluated by Watches; it reports a syntax error without adding it
+func (i *Interpreter) AddWatch(src string) error {
+	p := parser.New(lexer.New(src))
+	if p.ParseExpression(); p.ParseError() != nil {
+		return fmt.Errorf("?SYNTAX ERROR: %s", p.ParseError().Message)
+	}
+	i.debug.watches = append(i.debug.watches, src)
+	return nil
+}
+
+// RemoveWatch removes the watch at a 0-based position in the list Watches returns
+func (i *Interpreter) RemoveWatch(n int) error {
+	if n < 0 || n >= len(i.debug.watches) {
+		return ErrIllegalQuantity
+	}
+	i.debug.watches = append(i.debug.watches[:n], i.debug.watches[n+1:]...)
+	return nil
+}
+
+// Watches evaluates the watch expressions in the order they were added
+func (i *Interpreter) Watches() []Watch {
+	watches := make([]Watch, len(i.debug.watches))
+	for n, src := range i.debug.watches {
+		value, err := i.EvaluateExpression(src)
+		watches[n] = Watch{Source: src, Value: value, Err: err}
+	}
+	return watches
+}
+
+// Inspect copies the variables, arrays and stacks of the program
+func (i *Interpreter) Inspect() Inspection {
+	in := Inspection{Variables: i.Variables(), Arrays: make(map[string]ArrayInfo, len(i.arrays))}
+	for name, array := range i.arrays {
+		array.Values = append([]types.Value(nil), array.Values...)
+		in.Arrays[name] = array
+	}
+	for _, call := range i.callStack.items {
+		in.Gosubs = append(in.Gosubs, i.lineNumberAt(call.ReturnLineIndex))
+	}
+	for _, loop := range i.forStack.items {
+		in.ForLoops = append(in.ForLoops, ForFrame{Variable: loop.Variable, Line: loop.ForLine, End: loop.EndValue, Step: loop.StepValue})
+	}
+	for _, loop := range i.whileStack.items {
+		in.Whiles = append(in.Whiles, i.lineNumberAt(loop.LineIndex))
+	}
+	for _, loop := range i.doStack.items {
+		in.Dos = append(in.Dos, i.lineNumberAt(loop.LineIndex))
+	}
+	return in
+}
+
+// lineNumberAt returns the BASIC line number at a line index of the loaded program
+func (i *Interpreter) lineNumberAt(index int) int {
+	if i.program == nil || index < 0 || index >= len(i.program.Lines) {
+		return immediateLine
+	}
+	return i.program.Lines[index].Number
+}
+
+// Format writes the variables, then the arrays, then the stacks, one per line in sorted order
+func (in Inspection) Format() string {
+	var b strings.Builder
+	for _, name := range sortedKeys(in.Variables) {
+		fmt.Fprintf(&b, "%s = %s\n", name, formatValue(in.Variables[name]))
+	}
+	for _, name := range sortedKeys(in.Arrays) {
+		array := in.Arrays[name]
+		sizes := make([]string, len(array.Sizes))
+		for n, size := range array.Sizes {
+			sizes[n] = fmt.Sprint(size)
+		}
+		values := make([]string, len(array.Values))
+		for n, value := range array.Values {
+			values[n] = formatValue(value)
+		}
+		fmt.Fprintf(&b, "%s(%s) = %s\n", name, strings.Join(sizes, ","), strings.Join(values, ", "))
+	}
+	for _, line := range in.Gosubs {
+		fmt.Fprintf(&b, "GOSUB from %d\n", line)
+	}
+	for _, loop := range in.ForLoops {
+		fmt.Fprintf(&b, "FOR %s in %d: TO %s STEP %s\n", loop.Variable, loop.Line, formatValue(loop.End), formatValue(loop.Step))
+	}
+	for _, line := range in.Whiles {
+		fmt.Fprintf(&b, "WHILE in %d\n", line)
+	}
+	for _, line := range in.Dos {
+		fmt.Fprintf(&b, "DO in %d\n", line)
+	}
+	return b.String()
+}
+
+// formatValue shows a number as PRINT does without its padding and a string in quotes
+func formatValue(v types.Value) string {
+	if v.Type == types.StringType {
+		return fmt.Sprintf("%q", v.String)
+	}
+	return strings.TrimSpace(v.ToString())
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,174 @@
+package interpreter
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// inspectingDebugger runs a function at the first pause, then continues
+type inspectingDebugger struct {
+	inspect func(interp *Interpreter)
+	paused  bool
+}
+
+func (d *inspectingDebugger) Pause(interp *Interpreter, event DebugEvent) DebugAction {
+	if !d.paused {
+		d.paused = true
+		d.inspect(interp)
+	}
+	return DebugContinue
+}
+
+const inspectProgram = "10 DIM A(2): A(1)=5: N$=\"HI\"\n20 FOR I=1 TO 3\n30 GOSUB 100\n40 NEXT I\n50 END\n100 WHILE 1\n110 RETURN"
+
+func TestInterpreter_EvaluateExpressionWhilePaused(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetBreakpoint(110)
+	var value types.Value
+	var syntaxErr error
+	interp.SetDebugger(&inspectingDebugger{inspect: func(interp *Interpreter) {
+		var err error
+		value, err = interp.EvaluateExpression("A(1)*I+LEN(N$)")
+		require.NoError(t, err)
+		_, syntaxErr = interp.EvaluateExpression("A(1")
+	}})
+	require.NoError(t, interp.Execute(parsertest.Parse(t, inspectProgram)))
+
+	assert.Equal(t, types.NewNumberValue(7), value)
+	assert.ErrorContains(t, syntaxErr, "?SYNTAX ERROR")
+}
+
+func TestInterpreter_InspectWhilePaused(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetBreakpoint(110)
+	var in Inspection
+	interp.SetDebugger(&inspectingDebugger{inspect: func(interp *Interpreter) { in = interp.Inspect() }})
+	require.NoError(t, interp.Execute(parsertest.Parse(t, inspectProgram)))
+
+	assert.Equal(t, []int{30}, in.Gosubs)
+	assert.Equal(t, []int{100}, in.Whiles)
+	assert.Empty(t, in.Dos)
+	require.Len(t, in.ForLoops, 1)
+	assert.Equal(t, "I", in.ForLoops[0].Variable)
+	assert.Equal(t, 20, in.ForLoops[0].Line)
+
+	assert.Equal(t, strings.Join([]string{
+		`I = 1`,
+		`N$ = "HI"`,
+		`A(2) = 0, 5, 0`,
+		`GOSUB from 30`,
+		`FOR I in 20: TO 3 STEP 1`,
+		`WHILE in 100`,
+		``,
+	}, "\n"), in.Format())
+}
+
+func TestInterpreter_Watches(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.AddWatch("I*2"))
+	require.NoError(t, interp.AddWatch("1/I"))
+	assert.Error(t, interp.AddWatch("I*"))
+
+	watches := interp.Watches()
+	require.Len(t, watches, 2)
+	assert.Equal(t, types.NewNumberValue(0), watches[0].Value)
+	assert.EqualError(t, watches[1].Err, "?DIVISION BY ZERO ERROR")
+
+	require.NoError(t, interp.RemoveWatch(0))
+	assert.Equal(t, "1/I", interp.Watches()[0].Source)
+	assert.Equal(t, ErrIllegalQuantity, interp.RemoveWatch(1))
+}
+
+func TestCommandDebugger(t *testing.T) {
+	commands := []string{"watch I", "? A(1)*10", "vars", "break 50", "bogus", "next", "print I", "c", "s", "quit"}
+	var out strings.Builder
+	debugger := &CommandDebugger{
+		Read: func() (string, error) {
+			if len(commands) == 0 {
+				return "", io.EOF
+			}
+			command := commands[0]
+			commands = commands[1:]
+			return command, nil
+		},
+		Out: &out,
+	}
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	interp.SetSource(inspectProgram)
+	interp.SetDebugger(debugger)
+	interp.SetBreakpoint(30)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, inspectProgram)))
+
+	want := strings.Join([]string{
+		"breakpoint at 30: GOSUB 100",
+		"50",
+		"I = 1",
+		`N$ = "HI"`,
+		"A(2) = 0, 5, 0",
+		"FOR I in 20: TO 3 STEP 1",
+		`unknown command "bogus"; type help`,
+		"step at 40: NEXT I",
+		"1: I = 1",
+		"1",
+		"breakpoint at 30: GOSUB 100",
+		"1: I = 2",
+		"step at 100: WHILE 1",
+		"1: I = 2",
+		"",
+	}, "\n")
+	assert.Equal(t, want, out.String())
+	assert.Empty(t, commands)
+}
+
+func TestCommandDebugger_Context(t *testing.T) {
+	commands := []string{"list", "quit"}
+	var out strings.Builder
+	debugger := &CommandDebugger{
+		Read: func() (string, error) {
+			command := commands[0]
+			commands = commands[1:]
+			return command, nil
+		},
+		Out:     &out,
+		Context: 1,
+	}
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetSource(inspectProgram)
+	interp.SetDebugger(debugger)
+	interp.SetBreakpoint(100)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, inspectProgram)))
+
+	want := strings.Join([]string{
+		"breakpoint at 100",
+		"   50 END",
+		"=> 100 WHILE 1",
+		"   110 RETURN",
+		`   10 DIM A(2): A(1)=5: N$="HI"`,
+		"   20 FOR I=1 TO 3",
+		"   30 GOSUB 100",
+		"   40 NEXT I",
+		"   50 END",
+		"=> 100 WHILE 1",
+		"   110 RETURN",
+		"",
+	}, "\n")
+	assert.Equal(t, want, out.String())
+}
+
+func TestInterpreter_ListContext(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetSource(inspectProgram)
+	interp.Start(parsertest.Parse(t, inspectProgram))
+
+	assert.Equal(t, "=> 10 DIM A(2): A(1)=5: N$=\"HI\"\n   20 FOR I=1 TO 3\n", interp.ListContext(10, 1))
+	assert.Empty(t, interp.ListContext(60, 1))
+}
@@ -0,0 +1,64 @@
+package interpreter
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// textHeavyProgram mimics an adventure game: room names read from DATA, compared and joined into messages
+const textHeavyProgram = `10 DIM R$(20), D$(20)
+20 FOR I = 1 TO 20: READ R$(I), D$(I): NEXT I
+30 FOR T = 1 TO 50
+40 FOR I = 1 TO 20
+50 IF R$(I) = "CAVE" THEN C = C + 1
+60 M$ = "YOU ARE IN THE " + R$(I) + ". " + D$(I)
+70 IF LEFT$(M$, 7) = "YOU ARE" THEN K = K + 1
+80 NEXT I
+90 NEXT T
+100 PRINT C; K
+200 DATA CAVE, "A DARK CAVE", HALL, "A LONG HALL", CAVE, "A DARK CAVE", ROOM, "A SMALL ROOM"
+210 DATA CAVE, "A DARK CAVE", HALL, "A LONG HALL", CAVE, "A DARK CAVE", ROOM, "A SMALL ROOM"
+220 DATA CAVE, "A DARK CAVE", HALL, "A LONG HALL", CAVE, "A DARK CAVE", ROOM, "A SMALL ROOM"
+230 DATA CAVE, "A DARK CAVE", HALL, "A LONG HALL", CAVE, "A DARK CAVE", ROOM, "A SMALL ROOM"
+240 DATA CAVE, "A DARK CAVE", HALL, "A LONG HALL", CAVE, "A DARK CAVE", ROOM, "A SMALL ROOM"
+`
+
+func runTextHeavy(tb testing.TB) *Interpreter {
+	p := parser.New(lexer.New(textHeavyProgram))
+	program := p.ParseProgram()
+	require.Nil(tb, p.ParseError())
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetMaxSteps(0)
+	require.NoError(tb, interp.Execute(program))
+	return interp
+}
+
+func TestInterpreter_StoredStringsAreInterned(t *testing.T) {
+	interp := runTextHeavy(t)
+
+	first, err := interp.GetArrayElement("R$", []int{1})
+	require.NoError(t, err)
+	third, err := interp.GetArrayElement("R$", []int{3})
+	require.NoError(t, err)
+	assert.Equal(t, "CAVE", first.String)
+	assert.Same(t, unsafe.StringData(first.String), unsafe.StringData(third.String))
+
+	require.NoError(t, interp.SetVariable("A$", first))
+	a, err := interp.GetVariable("A$")
+	require.NoError(t, err)
+	assert.Same(t, unsafe.StringData(first.String), unsafe.StringData(a.String))
+}
+
+func BenchmarkInterpreter_TextHeavy(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		runTextHeavy(b)
+	}
+}
@@ -4,9 +4,13 @@
 package interpreter
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"strings"
+	"time"
 
 	"basic-interpreter/lexer"
 	"basic-interpreter/parser"
@@ -23,20 +27,57 @@ var (
 	ErrStackOverflow      = fmt.Errorf("?OUT OF MEMORY ERROR")
 	ErrOutOfData          = fmt.Errorf("?OUT OF DATA ERROR")
 	ErrRedimArray         = fmt.Errorf("?REDIM'D ARRAY ERROR")
+	ErrBadSubscript       = fmt.Errorf("?BAD SUBSCRIPT ERROR")
+	ErrWendWithoutWhile   = fmt.Errorf("?WEND WITHOUT WHILE ERROR")
+	ErrWhileWithoutWend   = fmt.Errorf("?WHILE WITHOUT WEND ERROR")
+	ErrLoopWithoutDo      = fmt.Errorf("?LOOP WITHOUT DO ERROR")
+	ErrDeviceNotPresent   = fmt.Errorf("?DEVICE NOT PRESENT ERROR")
+
+	// Run limits, stopping a run from outside the program; their *Error names no line
+	ErrInfiniteLoop = fmt.Errorf("?INFINITE LOOP ERROR") // The step limit passed
+	ErrCanceled     = fmt.Errorf("?CANCELED ERROR")      // The run's context was canceled
+	ErrTimeLimit    = fmt.Errorf("?TIME LIMIT ERROR")    // The time limit or the context's deadline passed
 )
 
+// Array limits: DefaultArraySize is the highest index per dimension of an array used without DIM;
+// larger arrays than MaxArrayElements report ?OUT OF MEMORY
+const (
+	DefaultArraySize   = 10
+	MaxArrayDimensions = 255
+	MaxArrayElements   = 1 << 22
+)
+
+// immediateLine is the line number given to statements entered in immediate mode
+const immediateLine = -1
+
 // ForLoopContext represents an active FOR loop state
 type ForLoopContext struct {
-	Variable          string      // Normalized loop variable name
-	EndValue          types.Value // Target end value
-	StepValue         types.Value // Step value (default 1)
-	AfterForLineIndex int         // Target line index to jump back to
-	AfterForStmtIndex int         // Target statement index within the line (for colon-separated statements)
+	Variable          string      `json:"variable"`  // Normalized loop variable name
+	EndValue          types.Value `json:"end"`       // Target end value
+	StepValue         types.Value `json:"step"`      // Step value (default 1)
+	AfterForLineIndex int         `json:"lineIndex"` // Target line index to jump back to
+	AfterForStmtIndex int         `json:"stmtIndex"` // Target statement index within the line (for colon-separated statements)
+	ForLine           int         `json:"forLine"`   // BASIC line of the FOR statement, for diagnostics
+}
+
+// WhileLoopContext represents an active WHILE loop state
+type WhileLoopContext struct {
+	LineIndex int `json:"lineIndex"` // Line index of the WHILE statement
+	StmtIndex int `json:"stmtIndex"` // Statement index of the WHILE statement within its line
+}
+
+// DoLoopContext represents an active DO loop state
+type DoLoopContext struct {
+	LineIndex int `json:"lineIndex"` // Line index of the DO statement
+	StmtIndex int `json:"stmtIndex"` // Statement index of the DO statement within its line
 }
 
 // CallContext represents an active GOSUB call state
 type CallContext struct {
-	ReturnLineIndex int // Line index to return to after RETURN
+	ReturnLineIndex int  `json:"lineIndex"`       // Line index to return to after RETURN
+	ReturnStmtIndex int  `json:"stmtIndex"`       // Statement index within that line to resume at (after the GOSUB)
+	DoDepth         int  `json:"doDepth"`         // DO loop stack depth at the time of the GOSUB
+	Timer           bool `json:"timer,omitempty"` // The call is an EVERY or AFTER handler
 }
 
 // RuntimeError represents an error that occurred during program execution
@@ -53,19 +94,38 @@ func (re *RuntimeError) Error() string {
 // Interpreter executes BASIC programs by walking the AST
 type Interpreter struct {
 	runtime      runtime.Runtime
-	variables    map[string]types.Value // Variable storage using proper Value types
-	lineIndex    map[int]*parser.Line   // Maps line numbers to Line nodes for GOTO
-	linePos      map[int]int            // Maps line numbers to their index position
-	forStack     *Stack[ForLoopContext] // Stack of active FOR loops for nested loop support
-	callStack    *Stack[CallContext]    // Stack of active GOSUB calls for nested subroutine support
-	maxSteps     int                    // Maximum number of execution steps before infinite loop protection kicks in
-	maxCallDepth int                    // Maximum call stack depth before stack overflow error
-	stepCount    int                    // Current step count during execution
-	pc           int                    // Program counter: current line index
-	stmtIndex    int                    // Current statement index within current line
-	jumped       bool                   // Indicates a jump occurred during statement execution
-	halted       bool                   // Indicates END/STOP was requested
-	stmtJumped   bool                   // Indicates a statement-level jump occurred (for FOR loop completion)
+	program      *parser.Program          // Program whose line index and DATA are currently loaded
+	running      *parser.Program          // Program currently being executed (includes the immediate line)
+	variables    map[string]types.Value   // Variable storage using proper Value types
+	lineIndex    map[int]*parser.Line     // Maps line numbers to Line nodes for GOTO
+	linePos      map[int]int              // Maps line numbers to their index position
+	layouts      map[*parser.Line][]slot  // Statement positions of each program line, IF branches inline
+	forStack     *Stack[ForLoopContext]   // Stack of active FOR loops for nested loop support
+	whileStack   *Stack[WhileLoopContext] // Stack of active WHILE loops
+	doStack      *Stack[DoLoopContext]    // Stack of active DO loops
+	callStack    *Stack[CallContext]      // Stack of active GOSUB calls for nested subroutine support
+	maxSteps     int                      // Maximum number of execution steps before infinite loop protection kicks in
+	maxCallDepth int                      // Maximum call stack depth before stack overflow error
+	timeLimit    time.Duration            // Wall-clock limit of each Execute (0 for none)
+	ctx          context.Context          // Context of the current ExecuteContext, nil outside one
+	done         <-chan struct{}          // ctx.Done(), checked before each statement
+	interrupt    <-chan struct{}          // Receives when the user presses Ctrl+C, checked before each statement
+	execution    *Execution               // Background run started by RunAsync, nil otherwise
+	stepCount    int                      // Current step count during execution
+	pc           int                      // Program counter: current line index
+	stmtIndex    int                      // Current statement index within current line
+	jumped       bool                     // Indicates a jump occurred during statement execution
+	halted       bool                     // Indicates END/STOP was requested
+	stmtJumped   bool                     // Indicates a statement-level jump occurred (for FOR loop completion)
+	finished     bool                     // Indicates the program driven by RunFor has completed
+	erased       bool                     // Indicates NEW ran; the caller should delete its stored program
+	stopped      bool                     // Indicates STOP ended the last run
+	resume       *resumePoint             // Where CONT continues, nil when it cannot
+
+	// Output layout state
+	screenWidth int // Screen width in columns for wrapping and TAB bounds (0 = unbounded)
+	zoneWidth   int // Width of PRINT comma zones
+	column      int // Current output column
 
 	// DATA/READ state
 	dataValues  []types.Value // Collected DATA values
@@ -78,13 +138,78 @@ type Interpreter struct {
 
 	// User-defined functions: map FNNAME -> {param, body}
 	userFunctions map[string]UserFunction
+
+	// Go functions and statements registered by the host, by upper-case name (nil until one is registered)
+	hostFunctions  map[string]HostFunction
+	hostStatements map[string]HostStatementFunc
+
+	// FOR loops that recently left the stack, by variable, for NEXT WITHOUT FOR diagnostics
+	closedLoops ClosedLoops
+
+	// Declarations: CONST names, and names introduced by LET, DIM, CONST or DEF FN for OPTION EXPLICIT
+	constants map[string]bool
+	declared  map[string]bool
+	explicit  bool
+
+	// Shared copies of variable names and stored strings, so repeated text is kept once
+	interned *types.Interner
+
+	// Clock state: TI counts jiffies since clockStart
+	clockStart time.Time
+
+	// Dialect whose extensions run; DialectC64 emulates BASIC V2 strictly
+	dialect parser.Dialect
+
+	// Precision numbers are stored and printed with
+	precision types.Precision
+
+	// Optional pacing to emulate the original machine's speed (nil runs at full speed)
+	pacer *pacer
+
+	// Optional execution trace (nil when not tracing) and the hook recording into it
+	trace     *Trace
+	traceHook Hooks
+
+	// EVERY and AFTER timers
+	timers timerState
+
+	// Optional coverage counts (nil when not measuring coverage) and the hook counting them
+	coverage     *Coverage
+	coverageHook Hooks
+
+	// Hooks called before each statement, on errors and on jumps, in order
+	hooks []Hooks
+
+	// TRON line tracing, printed on the screen or to traceWriter when set
+	lineTrace       bool
+	traceWriter     io.Writer
+	traceStatements bool
+
+	// Debug mode: breakpoints and stepping, active while a Debugger is set
+	debug debugState
+
+	// Journal of INPUT, GET and RND values being recorded or replayed
+	journal journalState
+
+	// Files opened by OPEN, by file number, and ST, the status of the last file operation. OPEN reaches devices
+	// through devices when set, otherwise through the runtime.
+	files   map[int]*logicalFile
+	status  int
+	devices runtime.Files
+
+	// Memory for PEEK and POKE when the runtime has none (allocated on first use)
+	ram *runtime.RAM
+
+	// Program source text by line number, printed by LIST
+	listing map[int]string
 }
 
 // ArrayInfo holds metadata and storage for declared arrays
 type ArrayInfo struct {
-	IsString bool
-	Sizes    []int         // maximum index per dimension (inclusive)
-	Values   []types.Value // flattened storage
+	IsString bool          `json:"isString,omitempty"`
+	Sizes    []int         `json:"sizes"`   // maximum index per dimension (inclusive)
+	Strides  []int         `json:"strides"` // distance in Values between consecutive indexes of each dimension
+	Values   []types.Value `json:"values"`  // flattened storage
 }
 
 // UserFunction stores definition of a DEF FN
@@ -101,7 +226,10 @@ func NewInterpreter(rt runtime.Runtime) *Interpreter {
 		variables:     make(map[string]types.Value),
 		lineIndex:     make(map[int]*parser.Line),
 		linePos:       make(map[int]int),
+		layouts:       make(map[*parser.Line][]slot),
 		forStack:      NewStack[ForLoopContext](maxCallDepth), // Use same limit for FOR loops
+		whileStack:    NewStack[WhileLoopContext](maxCallDepth),
+		doStack:       NewStack[DoLoopContext](maxCallDepth),
 		callStack:     NewStack[CallContext](maxCallDepth),
 		maxSteps:      1000, // Default maximum steps
 		maxCallDepth:  maxCallDepth,
@@ -111,16 +239,149 @@ func NewInterpreter(rt runtime.Runtime) *Interpreter {
 		jumped:        false,
 		halted:        false,
 		stmtJumped:    false,
+		zoneWidth:     10,
 		arrays:        make(map[string]ArrayInfo),
 		userFunctions: make(map[string]UserFunction),
+		interned:      types.NewInterner(),
+		constants:     make(map[string]bool),
+		declared:      make(map[string]bool),
+		clockStart:    rt.Now(),
+		dialect:       parser.DialectExtended,
 	}
 }
 
+// Reset returns the interpreter to its freshly created program state, keeping configuration such as
+// the step limit and screen layout. Variables, arrays, functions, the DATA pointer and all stacks are cleared.
+func (i *Interpreter) Reset() {
+	i.clearState()
+	i.stepCount = 0
+	i.pc = 0
+	i.stmtIndex = 0
+	i.jumped = false
+	i.halted = false
+	i.stmtJumped = false
+	i.explicit = false
+	i.resume = nil
+}
+
+// Restart rewinds execution like Reset but keeps simple variables and constants from the previous run,
+// so a debugging session can seed the next RUN from them; arrays and user functions are still cleared
+func (i *Interpreter) Restart() {
+	variables, constants, declared := i.variables, i.constants, i.declared
+	i.Reset()
+	i.variables, i.constants, i.declared = variables, constants, declared
+}
+
+// ClearVariables implements CLR
+func (i *Interpreter) ClearVariables() error {
+	i.clearState()
+	return nil
+}
+
+// NewProgram implements NEW: it forgets all variables and the program's source text and ends the run.
+// Callers holding the program text delete it when ProgramErased reports true.
+func (i *Interpreter) NewProgram() error {
+	i.clearState()
+	i.listing = nil
+	i.erased = true
+	i.lineTrace = false
+	return i.RequestEnd()
+}
+
+// ProgramErased reports whether NEW ran during the last Execute, ExecuteImmediate or Start
+func (i *Interpreter) ProgramErased() bool {
+	return i.erased
+}
+
+// clearState forgets all variables, constants, arrays, user functions, loop/call stacks and timers, rewinds DATA
+// and closes all files
+func (i *Interpreter) clearState() {
+	i.variables = make(map[string]types.Value)
+	i.arrays = make(map[string]ArrayInfo)
+	i.userFunctions = make(map[string]UserFunction)
+	i.closedLoops = ClosedLoops{}
+	i.constants = make(map[string]bool)
+	i.declared = make(map[string]bool)
+	i.dataPointer = 0
+	i.forStack.Truncate(0)
+	i.whileStack.Truncate(0)
+	i.doStack.Truncate(0)
+	i.callStack.Truncate(0)
+	i.timers = timerState{}
+	i.CloseFiles()
+	i.status = 0
+}
+
 // SetMaxSteps sets the maximum number of execution steps before infinite loop protection
 func (i *Interpreter) SetMaxSteps(maxSteps int) {
 	i.maxSteps = maxSteps
 }
 
+// MaxSteps returns the statement limit of infinite loop protection (0 when disabled)
+func (i *Interpreter) MaxSteps() int {
+	return i.maxSteps
+}
+
+// SetTimeLimit bounds the wall-clock time of each Execute; a run over it stops with ErrTimeLimit (0 disables it).
+// Time spent waiting for INPUT counts but the wait itself is not interrupted.
+func (i *Interpreter) SetTimeLimit(limit time.Duration) {
+	i.timeLimit = limit
+}
+
+// TimeLimit returns the wall-clock limit of each Execute (0 when disabled)
+func (i *Interpreter) TimeLimit() time.Duration {
+	return i.timeLimit
+}
+
+// LimitContext returns ctx bounded by the time limit, for engines running programs on the interpreter's behalf
+func (i *Interpreter) LimitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if i.timeLimit <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, i.timeLimit)
+}
+
+// ContextError returns the error stopping a run whose context is done: ErrTimeLimit after a deadline,
+// ErrCanceled otherwise
+func ContextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeLimit
+	}
+	return ErrCanceled
+}
+
+// SetScreenWidth sets the screen width used for line wrapping and TAB bounds (0 disables wrapping)
+func (i *Interpreter) SetScreenWidth(width int) {
+	i.screenWidth = width
+}
+
+// SetZoneWidth sets the width of the print zones used by commas in PRINT
+func (i *Interpreter) SetZoneWidth(width int) {
+	i.zoneWidth = width
+}
+
+// SetDialect selects the dialect: under DialectC64 only the first two characters of a name count, so TIMER is TI.
+// Set it before the program runs; variables already stored keep the names of the previous dialect.
+func (i *Interpreter) SetDialect(d parser.Dialect) {
+	i.dialect = d
+}
+
+// Dialect returns the dialect set with SetDialect
+func (i *Interpreter) Dialect() parser.Dialect {
+	return i.dialect
+}
+
+// SetPrecision selects how numbers are stored and printed; under types.PrecisionC64 each number assigned to a
+// variable or array element is rounded to the C64's 32-bit mantissa and printed to 9 significant digits
+func (i *Interpreter) SetPrecision(p types.Precision) {
+	i.precision = p
+}
+
+// Precision returns the precision set with SetPrecision
+func (i *Interpreter) Precision() types.Precision {
+	return i.precision
+}
+
 // pushForLoop pushes a new FOR loop context onto the stack
 func (i *Interpreter) pushForLoop(variable string, endValue types.Value, stepValue types.Value, afterForLineIndex int, afterForStmtIndex int) error {
 	norm := i.NormalizeVariableName(variable)
@@ -130,8 +391,13 @@ func (i *Interpreter) pushForLoop(variable string, endValue types.Value, stepVal
 		StepValue:         stepValue,
 		AfterForLineIndex: afterForLineIndex,
 		AfterForStmtIndex: afterForStmtIndex,
+		ForLine:           i.currentLineNumber(),
+	}
+	if err := i.forStack.Push(forLoop); err != nil {
+		return i.forStackOverflow(variable)
 	}
-	return i.forStack.Push(forLoop)
+	i.closedLoops.Open(norm)
+	return nil
 }
 
 // popForLoop removes the top FOR loop from the stack
@@ -144,18 +410,26 @@ func (i *Interpreter) peekForLoop() *ForLoopContext {
 	return i.forStack.Peek()
 }
 
-// findForLoopByVariable finds a FOR loop on the stack by variable name
-func (i *Interpreter) findForLoopByVariable(variable string) *ForLoopContext {
+// unwindForLoopTo finds a FOR loop on the stack by variable name and discards the loops nested inside it,
+// remembering them as closed by that NEXT
+func (i *Interpreter) unwindForLoopTo(variable string) *ForLoopContext {
 	norm := i.NormalizeVariableName(variable)
-	return i.forStack.FindByPredicate(func(ctx ForLoopContext) bool {
-		return ctx.Variable == norm
-	})
+	if i.forStack.FindByPredicate(func(ctx ForLoopContext) bool { return ctx.Variable == norm }) == nil {
+		return nil
+	}
+	for top := i.peekForLoop(); top.Variable != norm; top = i.peekForLoop() {
+		i.recordClosedLoop(*top, norm)
+		i.popForLoop()
+	}
+	return i.peekForLoop()
 }
 
 // pushCallContext pushes a new call context onto the call stack
-func (i *Interpreter) pushCallContext(returnLineIndex int) error {
+func (i *Interpreter) pushCallContext(returnLineIndex int, returnStmtIndex int) error {
 	callContext := CallContext{
 		ReturnLineIndex: returnLineIndex,
+		ReturnStmtIndex: returnStmtIndex,
+		DoDepth:         i.doStack.Size(),
 	}
 	return i.callStack.Push(callContext)
 }
@@ -167,19 +441,133 @@ func (i *Interpreter) popCallContext() *CallContext {
 
 // Execute runs a BASIC program
 func (i *Interpreter) Execute(program *parser.Program) error {
+	return i.ExecuteContext(context.Background(), program)
+}
+
+// ExecuteContext runs a BASIC program like Execute, stopping it with ErrCanceled when ctx is canceled and
+// ErrTimeLimit when its deadline or the time limit passes
+func (i *Interpreter) ExecuteContext(ctx context.Context, program *parser.Program) error {
+	return i.Within(ctx, func(context.Context) error {
+		i.begin(program)
+
+		// Execute program with program counter for GOTO support
+		return i.executeWithProgramCounter(program)
+	})
+}
+
+// ExecuteFrom runs a BASIC program starting at startLine, as RUN n does
+func (i *Interpreter) ExecuteFrom(program *parser.Program, startLine int) error {
+	return i.Within(context.Background(), func(context.Context) error {
+		i.begin(program)
+		target, err := i.resolveTarget(startLine)
+		if err != nil {
+			return err
+		}
+		i.pc = target
+		i.stmtIndex = 0
+		_, err = i.run(program, -1)
+		return err
+	})
+}
+
+// Within runs execute with ctx bounded by the time limit. The tree walker checks it before each statement and
+// INPUT stops waiting when it is done; engines running programs on the interpreter's behalf check it themselves.
+func (i *Interpreter) Within(ctx context.Context, execute func(ctx context.Context) error) error {
+	ctx, cancel := i.LimitContext(ctx)
+	defer cancel()
+	// A nested run restores the outer context when it ends
+	outer, outerDone := i.ctx, i.done
+	defer func() { i.ctx, i.done = outer, outerDone }()
+	i.ctx, i.done = ctx, ctx.Done()
+	return execute(ctx)
+}
+
+// begin prepares a new run of program
+func (i *Interpreter) begin(program *parser.Program) {
 	// Reset step counter for new execution
 	i.stepCount = 0
 	i.halted = false
 	i.jumped = false
+	i.stmtJumped = false
+	i.erased = false
+	i.stopped = false
+	i.resume = nil
+	i.clearInterrupt()
+
+	// Build line number index for GOTO statements and collect DATA values
+	i.load(program)
+}
 
-	// Build line number index for GOTO statements
-	i.buildLineIndex(program)
+// ExecuteImmediate runs statements entered without a line number against the given program.
+// Variables and the DATA pointer are preserved, and a GOTO continues into the program.
+func (i *Interpreter) ExecuteImmediate(program *parser.Program, stmts []parser.Statement) error {
+	i.stepCount = 0
+	i.halted = false
+	i.jumped = false
+	i.stmtJumped = false
+	i.erased = false
+	i.stopped = false
+	i.clearInterrupt()
 
-	// Collect DATA values before execution
-	i.collectData(program)
+	if i.program != program {
+		i.load(program)
+	}
+
+	// Run the immediate statements as a virtual line after the program
+	lines := make([]*parser.Line, len(program.Lines), len(program.Lines)+1)
+	copy(lines, program.Lines)
+	lines = append(lines, &parser.Line{Number: immediateLine, Statements: stmts})
+	i.pc = len(program.Lines)
+	i.stmtIndex = 0
+	_, err := i.run(&parser.Program{Lines: lines}, -1)
+	return err
+}
+
+// RunStatus reports the state of a program driven by RunFor
+type RunStatus int
+
+const (
+	StatusRunning RunStatus = iota // The step budget ran out; call RunFor again to continue
+	StatusDone                     // The program ended, stopped, failed or ran past its last line
+)
+
+// Start loads a program for cooperative execution with RunFor without running any of it
+func (i *Interpreter) Start(program *parser.Program) {
+	i.stepCount = 0
+	i.halted = false
+	i.jumped = false
+	i.stmtJumped = false
+	i.erased = false
+	i.stopped = false
+	i.resume = nil
+	i.clearInterrupt()
+	i.load(program)
+	i.running = program
+	i.pc = 0
+	i.stmtIndex = 0
+	i.finished = false
+}
+
+// RunFor executes at most n statements of the program given to Start and returns whether more remain.
+// Hosts with their own event loop can call it once per frame; the step limit still applies overall.
+func (i *Interpreter) RunFor(n int) (RunStatus, error) {
+	if i.running == nil || i.finished {
+		return StatusDone, nil
+	}
+	finished, err := i.run(i.running, n)
+	if finished {
+		i.finished = true
+		return StatusDone, err
+	}
+	return StatusRunning, nil
+}
 
-	// Execute program with program counter for GOTO support
-	return i.executeWithProgramCounter(program)
+// load builds the line index and collects DATA values for a program
+func (i *Interpreter) load(program *parser.Program) {
+	i.program = program
+	i.listLines(program)
+	i.buildLineIndex(program)
+	i.collectData(program)
 }
 
 // collectData scans the program and collects all DATA values in order
@@ -204,6 +592,7 @@ func (i *Interpreter) collectData(program *parser.Program) {
 func (i *Interpreter) buildLineIndex(program *parser.Program) {
 	i.lineIndex = make(map[int]*parser.Line)
 	i.linePos = make(map[int]int)
+	i.layouts = make(map[*parser.Line][]slot)
 	for idx, line := range program.Lines {
 		i.lineIndex[line.Number] = line
 		i.linePos[line.Number] = idx
@@ -220,6 +609,14 @@ func (i *Interpreter) executeWithProgramCounter(program *parser.Program) error {
 	i.pc = 0
 	i.stmtIndex = 0
 
+	_, err := i.run(program, -1)
+	return err
+}
+
+// run executes program lines starting from the current program counter.
+// At most budget statements are executed (negative means no limit); it reports whether the program finished.
+func (i *Interpreter) run(program *parser.Program, budget int) (bool, error) {
+	i.running = program
 	for i.pc < len(program.Lines) {
 		line := program.Lines[i.pc]
 
@@ -231,57 +628,128 @@ func (i *Interpreter) executeWithProgramCounter(program *parser.Program) error {
 			i.stmtIndex = 0
 		}
 
-		for i.stmtIndex < len(line.Statements) {
-			stmt := line.Statements[i.stmtIndex]
+		stmts := i.positions(line)
+		for i.stmtIndex < len(stmts) {
+			if i.timers.armed > 0 && line.Number != immediateLine {
+				fired, err := i.fireTimer()
+				if err != nil {
+					return true, i.wrapErrorWithLine(err, line.Number, i.stmtIndex)
+				}
+				if fired {
+					i.afterJump(program, line.Number)
+					goto nextLine
+				}
+			}
+			at := stmts[i.stmtIndex]
+			if at.stmt == nil {
+				// The end of a THEN branch skips its ELSE statements
+				i.stmtIndex = at.skipTo
+				continue
+			}
+			stmt := at.stmt
+
+			// Pause before this statement once the budget is spent; resuming continues here
+			if budget == 0 {
+				i.stmtJumped = true
+				return false, nil
+			}
+			budget--
+
+			if i.debug.debugger != nil && line.Number != immediateLine && !i.debugPause(line.Number, i.stmtIndex) {
+				i.halted = true
+				return true, nil
+			}
+
+			if i.execution != nil {
+				i.execution.checkpoint()
+			}
+
+			if i.interrupt != nil {
+				select {
+				case <-i.interrupt:
+					return true, i.breakAt(line.Number)
+				default:
+				}
+			}
 
 			// Increment step counter and check for infinite loop protection
 			i.stepCount++
 			if i.maxSteps > 0 && i.stepCount > i.maxSteps {
-				return fmt.Errorf("?INFINITE LOOP ERROR")
+				return true, AtLine(ErrInfiniteLoop, NoLine, i.stmtIndex)
+			}
+			if i.done != nil {
+				select {
+				case <-i.done:
+					return true, AtLine(ContextError(i.ctx), NoLine, i.stmtIndex)
+				default:
+				}
 			}
 
-			// Polymorphic dispatch - AST node executes itself using double dispatch
-			err := stmt.Execute(i)
+			if i.pacer != nil {
+				i.pacer.step()
+			}
+			if i.lineTrace && i.stmtIndex == 0 && line.Number != immediateLine {
+				if err := i.traceLine(line.Number); err != nil {
+					return true, i.wrapErrorWithLine(err, line.Number, i.stmtIndex)
+				}
+			}
+			next := i.stmtIndex + 1
+			err := i.beforeStatement(line.Number, stmt)
+			if is, ok := stmt.(*parser.IfStatement); ok && err == nil {
+				// The branch statements follow the IF, so it only picks where the line continues
+				next, err = i.branch(is, at.elseAt)
+			} else if err == nil {
+				// Polymorphic dispatch - AST node executes itself using double dispatch
+				err = stmt.Execute(i)
+			}
 			if err != nil {
-				// Regular error - wrap with line number
-				return i.wrapErrorWithLine(err, line.Number)
+				if errors.Is(err, ErrCanceled) || errors.Is(err, ErrTimeLimit) {
+					// A run limit that passed while INPUT waited, reported like one between statements
+					err = AtLine(err, NoLine, i.stmtIndex)
+				} else {
+					// Regular error - wrap with line number
+					err = i.wrapErrorWithLine(err, line.Number, i.stmtIndex)
+				}
+				i.resume = nil
+				i.afterError(line.Number, err)
+				return true, err
 			}
 
 			// After successful execution, check for END/STOP or GOTO performed via ops
 			if i.halted {
-				return nil
+				return true, nil
 			}
 			if i.jumped {
 				i.jumped = false
+				i.afterJump(program, line.Number)
 				goto nextLine
 			}
 			if i.stmtJumped {
+				i.afterJump(program, line.Number)
 				goto nextLine // Continue from the jumped-to position
 			}
 
 			// Move to next statement
-			i.stmtIndex++
+			i.stmtIndex = next
 		}
 
-		// Move to next line
+		// Move to next line; falling off the program never re-enters the immediate line
 		i.pc++
+		if i.pc < len(program.Lines) && program.Lines[i.pc].Number == immediateLine {
+			break
+		}
 	nextLine:
 	}
 
-	return nil
+	return true, nil
 }
 
-// wrapErrorWithLine wraps an error with C64 BASIC format including line number
-func (i *Interpreter) wrapErrorWithLine(err error, lineNumber int) error {
-	msg := err.Error()
-	if len(msg) > 0 && msg[0] == '?' {
-		// If already C64-style, append line if not present
-		if strings.Contains(msg, " IN ") {
-			return err
-		}
-		return fmt.Errorf("%s IN %d", msg, lineNumber)
+// wrapErrorWithLine returns an error raised at a statement as an *Error; immediate mode has no line to name
+func (i *Interpreter) wrapErrorWithLine(err error, lineNumber, statement int) error {
+	if lineNumber == immediateLine {
+		lineNumber = NoLine
 	}
-	return fmt.Errorf("?ERROR IN %d: %s", lineNumber, msg)
+	return AtLine(err, lineNumber, statement)
 }
 
 // InterpreterOperations interface implementation
@@ -289,7 +757,16 @@ func (i *Interpreter) wrapErrorWithLine(err error, lineNumber int) error {
 
 // GetVariable retrieves a variable value by name
 func (i *Interpreter) GetVariable(name string) (types.Value, error) {
+	if value, ok := i.clockVariable(name); ok {
+		return value, nil
+	}
+	if IsStatusVariable(name, i.dialect) {
+		return types.NewNumberValue(float64(i.status)), nil
+	}
 	normalizedName := i.NormalizeVariableName(name)
+	if err := i.checkReadable(normalizedName); err != nil {
+		return types.Value{}, err
+	}
 	if value, exists := i.variables[normalizedName]; exists {
 		return value, nil
 	}
@@ -301,6 +778,25 @@ func (i *Interpreter) GetVariable(name string) (types.Value, error) {
 	return types.NewNumberValue(0), nil
 }
 
+// Variables returns a copy of the simple variables by normalized name
+func (i *Interpreter) Variables() map[string]types.Value {
+	vars := make(map[string]types.Value, len(i.variables))
+	for name, value := range i.variables {
+		vars[name] = value
+	}
+	return vars
+}
+
+// Steps returns the number of statements executed by the last run
+func (i *Interpreter) Steps() int {
+	return i.stepCount
+}
+
+// CurrentLine returns the BASIC line being executed, or the line execution stopped on; -1 outside numbered lines
+func (i *Interpreter) CurrentLine() int {
+	return i.currentLineNumber()
+}
+
 // SetVariable sets a variable value with type checking
 func (i *Interpreter) SetVariable(name string, value types.Value) error {
 	// Type check: string variables can only hold strings, numeric variables can only hold numbers
@@ -311,25 +807,136 @@ func (i *Interpreter) SetVariable(name string, value types.Value) error {
 	if !isStringVariable && value.Type != types.NumberType {
 		return types.ErrTypeMismatch
 	}
+	if IsClockVariable(name, i.dialect) {
+		return i.setClock(name, value)
+	}
+	if IsStatusVariable(name, i.dialect) {
+		return fmt.Errorf("?SYNTAX ERROR")
+	}
 
 	normalizedName := i.NormalizeVariableName(name)
-	i.variables[normalizedName] = value
+	if err := i.checkAssignable(normalizedName); err != nil {
+		return err
+	}
+	value = i.round(value)
+	i.variables[i.interned.Intern(normalizedName)] = i.interned.InternValue(value)
+	if i.trace != nil {
+		i.trace.assign(normalizedName, value)
+	}
 	return nil
 }
 
+// round stores a number with the interpreter's precision
+func (i *Interpreter) round(value types.Value) types.Value {
+	if value.Type == types.NumberType {
+		value.Number = i.precision.Round(value.Number)
+	}
+	return value
+}
+
 // PrintLine outputs text to the runtime environment
 func (i *Interpreter) PrintLine(text string) error {
+	text = i.layout(text)
+	i.column = 0
+	if i.trace != nil {
+		i.trace.output(text + "\n")
+	}
 	return i.runtime.PrintLine(text)
 }
 
 // Print outputs text without a newline
 func (i *Interpreter) Print(text string) error {
+	text = i.layout(text)
+	if i.trace != nil {
+		i.trace.output(text)
+	}
 	return i.runtime.Print(text)
 }
 
 // ReadInput reads input from the runtime environment
 func (i *Interpreter) ReadInput(prompt string) (string, error) {
-	return i.runtime.Input(prompt)
+	// The user's RETURN key leaves the cursor at the start of a new line
+	i.column = 0
+	return i.journalInput(prompt)
+}
+
+// input reads a line from the runtime, giving up with ErrCanceled or ErrTimeLimit when the run's context is done
+// and the runtime can stop waiting
+func (i *Interpreter) input(prompt string) (string, error) {
+	in, ok := i.runtime.(runtime.ContextInput)
+	if !ok || i.ctx == nil {
+		return i.runtime.Input(prompt)
+	}
+	line, err := in.InputContext(i.ctx, prompt)
+	if err != nil && i.ctx.Err() != nil {
+		return "", ContextError(i.ctx)
+	}
+	return line, err
+}
+
+// ReadKey returns the next key pressed, or "" when none is waiting
+func (i *Interpreter) ReadKey() (string, error) {
+	return i.journalKey()
+}
+
+// ClearScreen clears the runtime's screen and returns the cursor to the first column
+func (i *Interpreter) ClearScreen() error {
+	i.column = 0
+	return i.runtime.Clear()
+}
+
+// LocateCursor moves the cursor for LOCATE and PRINT AT on runtimes that have a screen
+func (i *Interpreter) LocateCursor(row, col int) error {
+	cursor, ok := i.runtime.(runtime.Cursor)
+	if !ok {
+		return ErrDeviceNotPresent
+	}
+	if row < 0 || col < 0 {
+		return ErrIllegalQuantity
+	}
+	if err := cursor.Locate(row, col); err != nil {
+		return err
+	}
+	i.column = col
+	return nil
+}
+
+// PrintZoneWidth returns the width of PRINT comma zones
+func (i *Interpreter) PrintZoneWidth() int {
+	return i.zoneWidth
+}
+
+// OutputColumn returns the column where the next printed character will appear
+func (i *Interpreter) OutputColumn() int {
+	return i.column
+}
+
+// layout tracks the output column and wraps text at the screen width, if one is set
+func (i *Interpreter) layout(text string) string {
+	if i.screenWidth <= 0 {
+		if nl := strings.LastIndexByte(text, '\n'); nl >= 0 {
+			i.column = len(text) - nl - 1
+		} else {
+			i.column += len(text)
+		}
+		return text
+	}
+	var sb strings.Builder
+	for idx := 0; idx < len(text); idx++ {
+		c := text[idx]
+		if c == '\n' {
+			i.column = 0
+			sb.WriteByte(c)
+			continue
+		}
+		if i.column >= i.screenWidth {
+			sb.WriteByte('\n')
+			i.column = 0
+		}
+		sb.WriteByte(c)
+		i.column++
+	}
+	return sb.String()
 }
 
 // GetNextData returns the next DATA value, or error if none remain
@@ -344,12 +951,11 @@ func (i *Interpreter) GetNextData() (types.Value, error) {
 
 // GetArrayElement retrieves an element from a declared array with bounds/type checks
 func (i *Interpreter) GetArrayElement(name string, indices []int) (types.Value, error) {
-	norm := i.NormalizeVariableName(name)
-	arr, ok := i.arrays[norm]
-	if !ok {
-		return types.Value{}, fmt.Errorf("?UNDEFINED ARRAY ERROR")
+	arr, err := i.lookupArray(name, len(indices))
+	if err != nil {
+		return types.Value{}, err
 	}
-	off, err := flattenIndex(arr.Sizes, indices)
+	off, err := arr.offset(indices)
 	if err != nil {
 		return types.Value{}, err
 	}
@@ -358,12 +964,11 @@ func (i *Interpreter) GetArrayElement(name string, indices []int) (types.Value,
 
 // SetArrayElement sets an element in a declared array with bounds/type checks
 func (i *Interpreter) SetArrayElement(name string, indices []int, value types.Value) error {
-	norm := i.NormalizeVariableName(name)
-	arr, ok := i.arrays[norm]
-	if !ok {
-		return fmt.Errorf("?UNDEFINED ARRAY ERROR")
+	arr, err := i.lookupArray(name, len(indices))
+	if err != nil {
+		return err
 	}
-	off, err := flattenIndex(arr.Sizes, indices)
+	off, err := arr.offset(indices)
 	if err != nil {
 		return err
 	}
@@ -373,11 +978,33 @@ func (i *Interpreter) SetArrayElement(name string, indices []int, value types.Va
 	if !arr.IsString && value.Type != types.NumberType {
 		return types.ErrTypeMismatch
 	}
-	arr.Values[off] = value
-	i.arrays[norm] = arr
+	value = i.round(value)
+	arr.Values[off] = i.interned.InternValue(value)
+	if i.trace != nil {
+		i.trace.assign(elementName(i.NormalizeVariableName(name), indices), value)
+	}
 	return nil
 }
 
+// lookupArray returns an array, dimensioning it with DefaultArraySize per index on first use as the C64 does
+func (i *Interpreter) lookupArray(name string, dimensions int) (ArrayInfo, error) {
+	norm := i.NormalizeVariableName(name)
+	if arr, ok := i.arrays[norm]; ok {
+		return arr, nil
+	}
+	if i.explicit {
+		return ArrayInfo{}, ErrUndefinedVariable
+	}
+	sizes := make([]int, dimensions)
+	for n := range sizes {
+		sizes[n] = DefaultArraySize
+	}
+	if err := i.DeclareArray(name, sizes, strings.HasSuffix(name, "$")); err != nil {
+		return ArrayInfo{}, err
+	}
+	return i.arrays[norm], nil
+}
+
 // DeclareArray declares a new array with given size (highest index). Size must be >=0.
 func (i *Interpreter) DeclareArray(name string, sizes []int, isString bool) error {
 	if len(sizes) == 0 {
@@ -388,17 +1015,22 @@ func (i *Interpreter) DeclareArray(name string, sizes []int, isString bool) erro
 			return ErrIllegalQuantity
 		}
 	}
+	if len(sizes) > MaxArrayDimensions {
+		return ErrBadSubscript
+	}
 	norm := i.NormalizeVariableName(name)
 	if _, exists := i.arrays[norm]; exists {
 		return ErrRedimArray
 	}
-	// Compute total count as product of (size+1) per dimension
+	// Row-major strides: the last index varies fastest
+	strides := make([]int, len(sizes))
 	count := 1
-	extents := make([]int, len(sizes))
-	for i, s := range sizes {
-		e := s + 1
-		extents[i] = s
-		count *= e
+	for d := len(sizes) - 1; d >= 0; d-- {
+		strides[d] = count
+		if sizes[d]+1 > MaxArrayElements/count {
+			return ErrStackOverflow
+		}
+		count *= sizes[d] + 1
 	}
 	vals := make([]types.Value, count)
 	if isString {
@@ -410,30 +1042,21 @@ func (i *Interpreter) DeclareArray(name string, sizes []int, isString bool) erro
 			vals[idx] = types.NewNumberValue(0)
 		}
 	}
-	i.arrays[norm] = ArrayInfo{IsString: isString, Sizes: sizes, Values: vals}
+	i.arrays[norm] = ArrayInfo{IsString: isString, Sizes: sizes, Strides: strides, Values: vals}
 	return nil
 }
 
-// flattenIndex converts multi-dimensional indices into a flat offset using row-major order.
-func flattenIndex(sizes []int, indices []int) (int, error) {
-	if len(indices) != len(sizes) {
-		return 0, fmt.Errorf("?ARRAY BOUNDS EXCEEDED ERROR")
-	}
-	// Precompute strides: stride[d-1]=1; stride[i]=stride[i+1]*(sizes[i+1]+1)
-	d := len(sizes)
-	strides := make([]int, d)
-	stride := 1
-	for i := d - 1; i >= 0; i-- {
-		strides[i] = stride
-		stride *= (sizes[i] + 1)
+// offset converts indices into a position in Values, checking each against its dimension
+func (a ArrayInfo) offset(indices []int) (int, error) {
+	if len(indices) != len(a.Sizes) {
+		return 0, ErrBadSubscript
 	}
 	off := 0
-	for i := 0; i < d; i++ {
-		idx := indices[i]
-		if idx < 0 || idx > sizes[i] {
-			return 0, fmt.Errorf("?ARRAY BOUNDS EXCEEDED ERROR")
+	for d, idx := range indices {
+		if idx < 0 || idx > a.Sizes[d] {
+			return 0, ErrBadSubscript
 		}
-		off += idx * strides[i]
+		off += idx * a.Strides[d]
 	}
 	return off, nil
 }
@@ -450,8 +1073,51 @@ func (i *Interpreter) EvaluateFunction(functionName string, args []parser.Expres
 		argValues[idx] = val
 	}
 
-	// Dispatch to specific function implementation
-	switch strings.ToUpper(functionName) {
+	upper := strings.ToUpper(functionName)
+	if !strings.HasPrefix(upper, "FN") {
+		return i.CallFunction(functionName, argValues)
+	}
+
+	// User-defined functions FN*
+	uf, ok := i.userFunctions[upper]
+	if !ok {
+		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: undefined function %s", functionName)
+	}
+	// Expect exactly one argument
+	if len(argValues) != 1 {
+		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: %s expects 1 argument", functionName)
+	}
+	// Save previous value of parameter (if any)
+	normParam := i.NormalizeVariableName(uf.Param)
+	prevVal, hadPrev := i.variables[normParam]
+	// Bind argument to parameter
+	if err := i.SetVariable(uf.Param, argValues[0]); err != nil {
+		return types.Value{}, err
+	}
+	// Evaluate body
+	result, err := uf.Body.Evaluate(i)
+	// Restore previous value
+	if hadPrev {
+		i.variables[normParam] = prevVal
+	} else {
+		delete(i.variables, normParam)
+	}
+	if err != nil {
+		return types.Value{}, err
+	}
+	if result.Type != types.NumberType {
+		return types.Value{}, types.ErrTypeMismatch
+	}
+	return result, nil
+}
+
+// CallFunction calls a host-registered or built-in function with evaluated arguments; FN functions go through EvaluateFunction
+func (i *Interpreter) CallFunction(functionName string, argValues []types.Value) (types.Value, error) {
+	upper := strings.ToUpper(functionName)
+	if fn, ok := i.hostFunctions[upper]; ok {
+		return i.callHost(upper, fn, argValues)
+	}
+	switch upper {
 	case "LEN":
 		return i.evaluateLenFunction(argValues)
 	case "LEFT$":
@@ -490,41 +1156,9 @@ func (i *Interpreter) EvaluateFunction(functionName string, args []parser.Expres
 		return i.evaluateAtnFunction(argValues)
 	case "TAB":
 		return i.evaluateTabFunction(argValues)
+	case "PEEK":
+		return i.evaluatePeekFunction(argValues)
 	default:
-		// Check user-defined functions FN*
-		upper := strings.ToUpper(functionName)
-		if strings.HasPrefix(upper, "FN") {
-			uf, ok := i.userFunctions[upper]
-			if !ok {
-				return types.Value{}, fmt.Errorf("?SYNTAX ERROR: undefined function %s", functionName)
-			}
-			// Expect exactly one argument
-			if len(argValues) != 1 {
-				return types.Value{}, fmt.Errorf("?SYNTAX ERROR: %s expects 1 argument", functionName)
-			}
-			// Save previous value of parameter (if any)
-			normParam := i.NormalizeVariableName(uf.Param)
-			prevVal, hadPrev := i.variables[normParam]
-			// Bind argument to parameter
-			if err := i.SetVariable(uf.Param, argValues[0]); err != nil {
-				return types.Value{}, err
-			}
-			// Evaluate body
-			result, err := uf.Body.Evaluate(i)
-			// Restore previous value
-			if hadPrev {
-				i.variables[normParam] = prevVal
-			} else {
-				delete(i.variables, normParam)
-			}
-			if err != nil {
-				return types.Value{}, err
-			}
-			if result.Type != types.NumberType {
-				return types.Value{}, types.ErrTypeMismatch
-			}
-			return result, nil
-		}
 		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: unknown function %s", functionName)
 	}
 }
@@ -533,38 +1167,71 @@ func (i *Interpreter) EvaluateFunction(functionName string, args []parser.Expres
 func (i *Interpreter) DefineUserFunction(name string, param string, body parser.Expression) error {
 	upper := strings.ToUpper(name)
 	i.userFunctions[upper] = UserFunction{Param: param, Body: body}
+	i.declared[i.NormalizeVariableName(param)] = true
 	return nil
 }
 
-// RequestGoto requests a GOTO control flow change
-func (i *Interpreter) RequestGoto(targetLine int) error {
-	// Resolve target line to index and set jump state
+// resolveTarget finds the line index for a GOTO, GOSUB, ON or THEN target; the caller's line is added when the error is wrapped
+func (i *Interpreter) resolveTarget(targetLine int) (int, error) {
 	targetLineIndex, found := i.linePos[targetLine]
 	if !found {
-		// We don't have the source line number here; the caller's line will wrap this error
-		return ErrUndefinedStatement
+		return 0, fmt.Errorf("%w: NO LINE %d", ErrUndefinedStatement, targetLine)
+	}
+	return targetLineIndex, nil
+}
+
+// RequestGoto requests a GOTO control flow change
+func (i *Interpreter) RequestGoto(targetLine int) error {
+	targetLineIndex, err := i.resolveTarget(targetLine)
+	if err != nil {
+		return err
 	}
+	// Always enter the target at its first statement, even when it is the line currently running
 	i.pc = targetLineIndex
+	i.stmtIndex = 0
+	i.stmtJumped = false
 	i.jumped = true
 	return nil
 }
 
-// RequestEnd requests program termination
-func (i *Interpreter) RequestEnd() error {
-	i.halted = true
+// RequestRun implements RUN: it forgets all variables and restarts at startLine, or at the first line when startLine is negative
+func (i *Interpreter) RequestRun(startLine int) error {
+	target := 0
+	if startLine >= 0 {
+		var err error
+		if target, err = i.resolveTarget(startLine); err != nil {
+			return err
+		}
+	} else if i.program == nil || len(i.program.Lines) == 0 {
+		// Nothing to run, e.g. RUN typed as an immediate statement with no program
+		i.clearState()
+		return i.RequestEnd()
+	}
+	i.clearState()
+	i.explicit = false
+	i.pc = target
+	i.stmtIndex = 0
+	i.stmtJumped = false
+	i.jumped = true
 	return nil
 }
 
-// RequestStop requests program stop
-func (i *Interpreter) RequestStop() error {
+// RequestEnd requests program termination
+func (i *Interpreter) RequestEnd() error {
 	i.halted = true
 	return nil
 }
 
 // RequestGosub requests a GOSUB jump to a target line
 func (i *Interpreter) RequestGosub(targetLine int) error {
-	// First, push current position + 1 to call stack for RETURN
-	if err := i.pushCallContext(i.pc + 1); err != nil {
+	// Resolve the target first so a bad GOSUB leaves no call context behind
+	if _, err := i.resolveTarget(targetLine); err != nil {
+		return err
+	}
+
+	// Push the statement after the GOSUB to the call stack for RETURN; inside THEN or ELSE it is the
+	// next statement of the branch, as branches are laid out inline
+	if err := i.pushCallContext(i.pc, i.stmtIndex+1); err != nil {
 		return err
 	}
 
@@ -580,25 +1247,56 @@ func (i *Interpreter) RequestReturn() error {
 		return ErrReturnWithoutGosub
 	}
 
-	// Jump back to the return address
+	// DO loops left open by the subroutine are abandoned
+	i.doStack.Truncate(callContext.DoDepth)
+	if callContext.Timer {
+		i.timers.handling, i.timers.resuming = false, true
+	}
+
+	// Jump back to the statement after the GOSUB, which may be later on the same line
 	i.pc = callContext.ReturnLineIndex
-	i.jumped = true
+	i.stmtIndex = callContext.ReturnStmtIndex
+	i.stmtJumped = true
 	return nil
 }
 
-// NormalizeVariableName truncates variable name to first 2 characters (C64 BASIC behavior)
+// ControlTransferred reports whether the current statement requested a jump, END or STOP
+func (i *Interpreter) ControlTransferred() bool {
+	return i.jumped || i.stmtJumped || i.halted
+}
+
+// NormalizeVariableName returns the name a variable is stored under in the interpreter's dialect
 func (i *Interpreter) NormalizeVariableName(name string) string {
-	if len(name) > 2 {
-		return name[:2]
+	return VariableName(name, i.dialect)
+}
+
+// VariableName returns the name a variable is stored under: the whole name in the extended dialect; in the c64
+// dialect only the first two characters count, keeping the $ of a string name, so SCORE and SCALE are both SC
+func VariableName(name string, d parser.Dialect) string {
+	if d != parser.DialectC64 {
+		return name
+	}
+	base, isString := strings.CutSuffix(name, "$")
+	if len(base) > 2 {
+		base = base[:2]
+	}
+	if isString {
+		return base + "$"
 	}
-	return name
+	return base
 }
 
 // BeginFor starts a FOR loop by pushing a loop context
 func (i *Interpreter) BeginFor(variable string, end types.Value, step types.Value) error {
 	// Validate step (cannot be zero)
 	if step.Type != types.NumberType || step.Number == 0 {
-		return ErrIllegalQuantity
+		return fmt.Errorf("%w: FOR %s WITH STEP 0", ErrIllegalQuantity, i.NormalizeVariableName(variable))
+	}
+	// Like C64 BASIC, running a FOR whose variable already has a loop open (e.g. after a GOTO back to it)
+	// replaces that stale loop and the loops opened inside it instead of stacking another one
+	norm := i.NormalizeVariableName(variable)
+	if i.forStack.UnwindTo(func(ctx ForLoopContext) bool { return ctx.Variable == norm }) != nil {
+		i.popForLoop()
 	}
 	// Jump back target is the next statement after the FOR statement on the same line
 	return i.pushForLoop(variable, end, step, i.pc, i.stmtIndex+1)
@@ -609,16 +1307,16 @@ func (i *Interpreter) IterateFor(variableName string) error {
 	// Find the appropriate FOR loop context
 	var forLoop *ForLoopContext
 	if variableName != "" {
-		// NEXT with variable name - find specific loop
-		forLoop = i.findForLoopByVariable(variableName)
+		// NEXT with variable name - find specific loop; like C64 BASIC, inner loops still open are dropped
+		forLoop = i.unwindForLoopTo(variableName)
 		if forLoop == nil {
-			return ErrNextWithoutFor
+			return i.nextWithoutFor(variableName)
 		}
 	} else {
 		// NEXT without variable name - use most recent loop
 		forLoop = i.peekForLoop()
 		if forLoop == nil {
-			return ErrNextWithoutFor
+			return i.nextWithoutFor("")
 		}
 	}
 
@@ -633,6 +1331,7 @@ func (i *Interpreter) IterateFor(variableName string) error {
 	if err != nil {
 		return err
 	}
+	newValue = i.round(newValue) // Compared as stored, as the C64 does
 
 	// Determine comparison based on step direction
 	cmpOp := "<="
@@ -644,12 +1343,13 @@ func (i *Interpreter) IterateFor(variableName string) error {
 		return err
 	}
 
+	// Like C64 BASIC, the variable keeps the stepped value even when the loop ends (FOR I=1 TO 3 leaves I=4)
+	err = i.SetVariable(forLoop.Variable, newValue)
+	if err != nil {
+		return err
+	}
+
 	if shouldContinue {
-		// Update loop variable and jump back to the statement after FOR
-		err = i.SetVariable(forLoop.Variable, newValue)
-		if err != nil {
-			return err
-		}
 		// Signal statement-level jump to AfterForLineIndex:AfterForStmtIndex
 		i.pc = forLoop.AfterForLineIndex
 		i.stmtIndex = forLoop.AfterForStmtIndex
@@ -658,10 +1358,129 @@ func (i *Interpreter) IterateFor(variableName string) error {
 	}
 
 	// Loop finished - pop the loop from stack and continue normally
+	i.recordClosedLoop(*forLoop, "")
 	i.popForLoop()
 	return nil
 }
 
+// BeginWhile enters a WHILE loop when condition holds, otherwise skips past the matching WEND
+func (i *Interpreter) BeginWhile(condition bool) error {
+	current := WhileLoopContext{LineIndex: i.pc, StmtIndex: i.stmtIndex}
+	top := i.whileStack.Peek()
+	reentered := top != nil && *top == current
+
+	if condition {
+		if reentered {
+			return nil // WEND jumped back here; the loop is already on the stack
+		}
+		return i.whileStack.Push(current)
+	}
+
+	if reentered {
+		i.whileStack.Pop()
+	}
+	lineIndex, stmtIndex, ok := i.findMatchingWend(current)
+	if !ok {
+		return ErrWhileWithoutWend
+	}
+	i.pc = lineIndex
+	i.stmtIndex = stmtIndex + 1
+	i.stmtJumped = true
+	return nil
+}
+
+// EndWhile jumps back to the innermost WHILE so its condition is tested again
+func (i *Interpreter) EndWhile() error {
+	loop := i.whileStack.Peek()
+	if loop == nil {
+		return ErrWendWithoutWhile
+	}
+	i.pc = loop.LineIndex
+	i.stmtIndex = loop.StmtIndex
+	i.stmtJumped = true
+	return nil
+}
+
+// findMatchingWend scans forward from a WHILE statement for its WEND, honoring nesting
+func (i *Interpreter) findMatchingWend(start WhileLoopContext) (int, int, bool) {
+	depth := 0
+	stmtIndex := start.StmtIndex + 1
+	for lineIndex := start.LineIndex; lineIndex < len(i.running.Lines); lineIndex++ {
+		stmts := i.positions(i.running.Lines[lineIndex])
+		for ; stmtIndex < len(stmts); stmtIndex++ {
+			switch stmts[stmtIndex].stmt.(type) {
+			case *parser.WhileStatement:
+				depth++
+			case *parser.WendStatement:
+				if depth == 0 {
+					return lineIndex, stmtIndex, true
+				}
+				depth--
+			}
+		}
+		stmtIndex = 0
+	}
+	return 0, 0, false
+}
+
+// BeginDo enters a DO loop; re-entering a DO (e.g. via GOTO) discards its earlier context
+func (i *Interpreter) BeginDo() error {
+	current := DoLoopContext{LineIndex: i.pc, StmtIndex: i.stmtIndex}
+	if i.doStack.UnwindTo(func(ctx DoLoopContext) bool { return ctx == current }) != nil {
+		i.doStack.Pop()
+	}
+	return i.doStack.Push(current)
+}
+
+// EndDo closes the DO loop matching the current LOOP, jumping back to its body when repeat is set.
+// Loops nested inside it that were left by GOTO are discarded.
+func (i *Interpreter) EndDo(repeat bool) error {
+	start, ok := i.findMatchingDo()
+	if !ok {
+		return ErrLoopWithoutDo
+	}
+	if i.doStack.UnwindTo(func(ctx DoLoopContext) bool { return ctx == start }) == nil {
+		return ErrLoopWithoutDo
+	}
+
+	if !repeat {
+		i.doStack.Pop()
+		return nil
+	}
+	i.pc = start.LineIndex
+	i.stmtIndex = start.StmtIndex + 1
+	i.stmtJumped = true
+	return nil
+}
+
+// findMatchingDo scans backward from the current LOOP statement for its DO, honoring nesting
+func (i *Interpreter) findMatchingDo() (DoLoopContext, bool) {
+	depth := 0
+	stmtIndex := i.stmtIndex - 1
+	for lineIndex := i.pc; lineIndex >= 0; lineIndex-- {
+		stmts := i.positions(i.running.Lines[lineIndex])
+		if stmtIndex >= len(stmts) {
+			stmtIndex = len(stmts) - 1
+		}
+		for ; stmtIndex >= 0; stmtIndex-- {
+			switch stmts[stmtIndex].stmt.(type) {
+			case *parser.LoopStatement:
+				depth++
+			case *parser.DoStatement:
+				if depth == 0 {
+					return DoLoopContext{LineIndex: lineIndex, StmtIndex: stmtIndex}, true
+				}
+				depth--
+			}
+		}
+		if lineIndex == 0 || i.running.Lines[lineIndex].Number == immediateLine {
+			break
+		}
+		stmtIndex = len(i.positions(i.running.Lines[lineIndex-1])) - 1
+	}
+	return DoLoopContext{}, false
+}
+
 // Built-in function implementations
 
 // evaluateLenFunction implements the LEN function
@@ -834,7 +1653,7 @@ func (i *Interpreter) evaluateStrFunction(args []types.Value) (types.Value, erro
 	if arg.Type != types.NumberType {
 		return types.Value{}, fmt.Errorf("?TYPE MISMATCH ERROR: STR$ requires numeric argument")
 	}
-	return types.NewStringValue(arg.ToString()), nil
+	return types.NewStringValue(i.precision.Format(arg)), nil
 }
 
 // evaluateValFunction implements the VAL function
@@ -867,7 +1686,11 @@ func (i *Interpreter) evaluateRndFunction(args []types.Value) (types.Value, erro
 	if args[0].Type != types.NumberType {
 		return types.Value{}, types.ErrTypeMismatch
 	}
-	return types.NewNumberValue(i.runtime.Random()), nil
+	n, err := i.journalRandom()
+	if err != nil {
+		return types.Value{}, err
+	}
+	return types.NewNumberValue(n), nil
 }
 
 // evaluateAbsFunction implements the ABS function
@@ -924,7 +1747,10 @@ func (i *Interpreter) evaluateExpFunction(args []types.Value) (types.Value, erro
 	if arg.Type != types.NumberType {
 		return types.Value{}, types.ErrTypeMismatch
 	}
-	return types.NewNumberValue(math.Exp(arg.Number)), nil
+	if result := math.Exp(arg.Number); result <= types.MaxMFLPT {
+		return types.NewNumberValue(result), nil
+	}
+	return types.Value{}, types.ErrOverflow
 }
 
 // evaluateLogFunction implements the LOG function (natural logarithm)
@@ -992,6 +1818,7 @@ func (i *Interpreter) evaluateAtnFunction(args []types.Value) (types.Value, erro
 
 // evaluateTabFunction implements the TAB function used in PRINT formatting.
 // For our purposes, TAB(n) returns a string of n spaces (n floored, min 0, capped for safety).
+// When a screen width is set, columns past the right edge wrap around like the C64 cursor.
 func (i *Interpreter) evaluateTabFunction(args []types.Value) (types.Value, error) {
 	if len(args) != 1 {
 		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: TAB requires exactly 1 argument")
@@ -1004,7 +1831,9 @@ func (i *Interpreter) evaluateTabFunction(args []types.Value) (types.Value, erro
 	if n <= 0 {
 		return types.NewStringValue(""), nil
 	}
-	if n > 4096 { // cap to avoid excessive allocation
+	if i.screenWidth > 0 {
+		n %= i.screenWidth
+	} else if n > 4096 { // cap to avoid excessive allocation
 		n = 4096
 	}
 	return types.NewStringValue(strings.Repeat(" ", n)), nil
@@ -173,6 +173,31 @@ func TestInterpreter_StringVariables(t *testing.T) {
 	}
 }
 
+func TestVariableName(t *testing.T) {
+	tests := []struct {
+		name     string
+		extended string
+		c64      string
+	}{
+		{"A", "A", "A"},
+		{"A$", "A$", "A$"},
+		{"SCORE", "SCORE", "SC"},
+		{"NAME$", "NAME$", "NA$"},
+		{"X1Y", "X1Y", "X1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.extended, VariableName(tt.name, parser.DialectExtended))
+			assert.Equal(t, tt.c64, VariableName(tt.name, parser.DialectC64))
+		})
+	}
+
+	for name, want := range map[string]bool{"TI": true, "TIMER": true, "ti$": true, "TITLE$": false, "TIME": false} {
+		assert.Equal(t, want, IsClockVariable(name, parser.DialectExtended), name)
+		assert.True(t, IsClockVariable(name, parser.DialectC64), name)
+	}
+}
+
 func TestInterpreter_ArithmeticExpressions(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,140 @@
+// ABOUTME: Record and replay of runs: INPUT lines, GET keys and RND draws are written to a journal as they happen
+// ABOUTME: Replaying the journal feeds them back in the same order, so an interactive run can be reproduced exactly
+
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrReplay reports a replayed run asking for something its journal does not have next
+var ErrReplay = fmt.Errorf("?REPLAY ERROR")
+
+// JournalVersion is the version of the journal format written by recording
+const JournalVersion = 1
+
+// Kinds of journal events
+const (
+	JournalInput = "input" // A line read by INPUT
+	JournalKey   = "key"   // A key read by GET, "" when none was waiting
+	JournalRnd   = "rnd"   // A number drawn by RND
+)
+
+// JournalEvent is one value a run read from outside the program
+type JournalEvent struct {
+	Kind   string  `json:"kind"`
+	Text   string  `json:"text,omitempty"`   // INPUT line or GET key
+	Number float64 `json:"number,omitempty"` // RND draw
+	Repeat int     `json:"repeat,omitempty"` // Further identical GET events, so polling loops stay short
+}
+
+// Journal is the sequence of values a run read, in order. It encodes as JSON.
+type Journal struct {
+	Version int            `json:"version"`
+	Events  []JournalEvent `json:"events"`
+}
+
+// journalState is the journal being recorded or replayed, and the replay position
+type journalState struct {
+	journal *Journal
+	replay  bool
+	next    int // Event to replay next
+	used    int // Repeats of that event already replayed
+}
+
+// RecordJournal appends the INPUT lines, GET keys and RND draws of the following runs to j; nil stops recording
+func (i *Interpreter) RecordJournal(j *Journal) {
+	if j != nil {
+		j.Version = JournalVersion
+	}
+	i.journal = journalState{journal: j}
+}
+
+// ReplayJournal answers INPUT, GET and RND from j instead of the runtime, in the order they were recorded;
+// a run asking for anything else fails with ErrReplay. nil stops replaying.
+func (i *Interpreter) ReplayJournal(j *Journal) error {
+	if j != nil && j.Version != JournalVersion {
+		return fmt.Errorf("journal version %d is not supported", j.Version)
+	}
+	i.journal = journalState{journal: j, replay: j != nil}
+	return nil
+}
+
+// record appends an event, folding repeated GET events into the previous one
+func (s *journalState) record(event JournalEvent) {
+	events := s.journal.Events
+	if n := len(events); n > 0 && event.Kind == JournalKey {
+		if last := &events[n-1]; last.Kind == JournalKey && last.Text == event.Text {
+			last.Repeat++
+			return
+		}
+	}
+	s.journal.Events = append(events, event)
+}
+
+// take returns the next recorded event, which must be of the given kind
+func (s *journalState) take(kind string) (JournalEvent, error) {
+	if s.next >= len(s.journal.Events) {
+		return JournalEvent{}, fmt.Errorf("%w: JOURNAL HAS NO MORE EVENTS FOR %s", ErrReplay, strings.ToUpper(kind))
+	}
+	event := s.journal.Events[s.next]
+	if event.Kind != kind {
+		return JournalEvent{}, fmt.Errorf("%w: EXPECTED %s, JOURNAL HAS %s AT EVENT %d", ErrReplay, strings.ToUpper(kind), strings.ToUpper(event.Kind), s.next+1)
+	}
+	if s.used < event.Repeat {
+		s.used++
+	} else {
+		s.next++
+		s.used = 0
+	}
+	return event, nil
+}
+
+// journalInput reads an INPUT line from the runtime or the journal
+func (i *Interpreter) journalInput(prompt string) (string, error) {
+	if !i.journal.replay {
+		line, err := i.input(prompt)
+		if err == nil && i.journal.journal != nil {
+			i.journal.record(JournalEvent{Kind: JournalInput, Text: line})
+		}
+		return line, err
+	}
+	event, err := i.journal.take(JournalInput)
+	if err != nil {
+		return "", err
+	}
+	// The runtime shows the prompt while it waits for a line, so a replay prints it too
+	if prompt != "" {
+		if err := i.runtime.Print(prompt); err != nil {
+			return "", err
+		}
+	}
+	return event.Text, nil
+}
+
+// journalKey reads a GET key from the runtime or the journal
+func (i *Interpreter) journalKey() (string, error) {
+	if !i.journal.replay {
+		key, err := i.runtime.GetKey()
+		if err == nil && i.journal.journal != nil {
+			i.journal.record(JournalEvent{Kind: JournalKey, Text: key})
+		}
+		return key, err
+	}
+	event, err := i.journal.take(JournalKey)
+	return event.Text, err
+}
+
+// journalRandom draws an RND number from the runtime or the journal
+func (i *Interpreter) journalRandom() (float64, error) {
+	if !i.journal.replay {
+		n := i.runtime.Random()
+		if i.journal.journal != nil {
+			i.journal.record(JournalEvent{Kind: JournalRnd, Number: n})
+		}
+		return n, nil
+	}
+	event, err := i.journal.take(JournalRnd)
+	return event.Number, err
+}
@@ -0,0 +1,87 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// journalProgram polls GET, then reads a line and draws random numbers
+const journalProgram = `10 GET K$: IF K$="" THEN 10
+20 INPUT "NAME";N$
+30 PRINT K$;N$;INT(RND(1)*1000);INT(RND(1)*1000)`
+
+// keyRuntime hands out scripted GET keys, "" meaning no key waiting
+type keyRuntime struct {
+	*runtime.TestRuntime
+	keys []string
+}
+
+func (k *keyRuntime) GetKey() (string, error) {
+	if len(k.keys) == 0 {
+		return "", nil
+	}
+	key := k.keys[0]
+	k.keys = k.keys[1:]
+	return key, nil
+}
+
+func TestJournalReplaysRecordedRun(t *testing.T) {
+	program := parser.New(lexer.New(journalProgram)).ParseProgram()
+	rt := &keyRuntime{TestRuntime: runtime.NewTestRuntime(), keys: []string{"", "", "", "X"}}
+	rt.SetInput([]string{"ADA"})
+	live := NewInterpreter(rt)
+	journal := &Journal{}
+	live.RecordJournal(journal)
+	require.NoError(t, live.Execute(program))
+
+	require.Len(t, journal.Events, 5)
+	assert.Equal(t, JournalEvent{Kind: JournalKey, Repeat: 2}, journal.Events[0])
+	assert.Equal(t, JournalEvent{Kind: JournalKey, Text: "X"}, journal.Events[1])
+	assert.Equal(t, JournalEvent{Kind: JournalInput, Text: "ADA"}, journal.Events[2])
+
+	blob, err := json.Marshal(journal)
+	require.NoError(t, err)
+	var decoded Journal
+	require.NoError(t, json.Unmarshal(blob, &decoded))
+
+	// A runtime with another RND seed and no keys or input reproduces the run from the journal alone
+	replayRT := runtime.NewDeterministicRuntime(99, "")
+	replay := NewInterpreter(replayRT)
+	require.NoError(t, replay.ReplayJournal(&decoded))
+	require.NoError(t, replay.Execute(program))
+	assert.Equal(t, strings.Join(rt.GetOutput(), ""), strings.Join(replayRT.GetOutput(), ""))
+	assert.Equal(t, live.Variables(), replay.Variables())
+	assert.Equal(t, live.Steps(), replay.Steps())
+}
+
+func TestJournalReplayMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		journal Journal
+		want    string
+	}{
+		{"wrong kind", "10 PRINT RND(1)", Journal{Version: JournalVersion, Events: []JournalEvent{{Kind: JournalInput, Text: "A"}}},
+			"?REPLAY ERROR IN 10: EXPECTED RND, JOURNAL HAS INPUT AT EVENT 1"},
+		{"used up", "10 GET A$: GET B$", Journal{Version: JournalVersion, Events: []JournalEvent{{Kind: JournalKey, Text: "A"}}},
+			"?REPLAY ERROR IN 10: JOURNAL HAS NO MORE EVENTS FOR KEY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := NewInterpreter(runtime.NewTestRuntime())
+			require.NoError(t, interp.ReplayJournal(&tt.journal))
+			err := interp.Execute(parser.New(lexer.New(tt.src)).ParseProgram())
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	assert.EqualError(t, NewInterpreter(runtime.NewTestRuntime()).ReplayJournal(&Journal{Version: 9}), "journal version 9 is not supported")
+}
@@ -0,0 +1,47 @@
+// ABOUTME: LIST support: the interpreter keeps each line's source text, as parsed or set, and prints ranges of it
+// ABOUTME: Lines without stored text list as their bare number; LIST ends the program as on the C64
+
+package interpreter
+
+import (
+	"fmt"
+
+	"basic-interpreter/parser"
+)
+
+// SetSource gives LIST the program text the running program was parsed from, for lines that do not carry their own
+func (i *Interpreter) SetSource(src string) {
+	i.listing = parser.SourceLines(src)
+}
+
+// listLines takes the text LIST prints from the program lines that kept their source when parsed
+func (i *Interpreter) listLines(program *parser.Program) {
+	for _, line := range program.Lines {
+		if line.Source == "" {
+			continue
+		}
+		if i.listing == nil {
+			i.listing = make(map[int]string)
+		}
+		i.listing[line.Number] = line.Source
+	}
+}
+
+// ListProgram prints the program lines in range from the stored source text, then ends the program
+func (i *Interpreter) ListProgram(r parser.LineRange) error {
+	if i.program != nil {
+		for _, line := range i.program.Lines {
+			if line.Number == immediateLine || !r.Contains(line.Number) {
+				continue
+			}
+			text := fmt.Sprint(line.Number)
+			if source := i.listing[line.Number]; source != "" {
+				text += " " + source
+			}
+			if err := i.PrintLine(text); err != nil {
+				return err
+			}
+		}
+	}
+	return i.RequestEnd()
+}
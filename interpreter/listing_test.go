@@ -0,0 +1,28 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+func TestInterpreter_ListPrintsParsedLineText(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, "10 print  \"A\" : REM  keep  me\n20 LIST")))
+	assert.Equal(t, []string{"A\n", "10 print  \"A\" : REM  keep  me\n", "20 LIST\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_ListFallsBackToSetSource(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	interp.SetSource("10 LIST")
+	program := &parser.Program{Lines: []*parser.Line{{Number: 10, Statements: []parser.Statement{&parser.ListStatement{Range: parser.AllLines}}}}}
+	require.NoError(t, interp.Execute(program))
+	assert.Equal(t, []string{"10 LIST\n"}, rt.GetOutput())
+}
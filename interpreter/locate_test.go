@@ -0,0 +1,42 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+func TestInterpreter_LocateDrawsOnScreen(t *testing.T) {
+	screen := runtime.NewScreenRuntime(runtime.NewTestRuntime())
+	src := "10 LOCATE 1,3: PRINT \"MENU\"\n20 PRINT AT 3,2;\"1 PLAY\"\n30 PRINT AT 4,2;\"2 QUIT\";"
+	require.NoError(t, NewInterpreter(screen).Execute(parsertest.Parse(t, src)))
+
+	lines := screen.Lines()
+	assert.Equal(t, "  MENU", lines[0])
+	assert.Equal(t, "  1 PLAY", lines[3])
+	assert.Equal(t, "  2 QUIT", lines[4])
+}
+
+func TestInterpreter_LocateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rt   runtime.Runtime
+		src  string
+		want string
+	}{
+		{"no screen", runtime.NewTestRuntime(), "10 LOCATE 1,1", "?DEVICE NOT PRESENT ERROR IN 10"},
+		{"LOCATE 0", runtime.NewScreenRuntime(runtime.NewTestRuntime()), "10 LOCATE 0,1", "?ILLEGAL QUANTITY ERROR IN 10"},
+		{"off the bottom", runtime.NewScreenRuntime(runtime.NewTestRuntime()), "10 PRINT AT 25,0;", "?ILLEGAL QUANTITY ERROR IN 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewInterpreter(tt.rt).Execute(parsertest.Parse(t, tt.src))
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
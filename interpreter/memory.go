@@ -0,0 +1,46 @@
+// ABOUTME: PEEK and POKE on the runtime's emulated memory, or on memory of the interpreter's own when the runtime has none
+// ABOUTME: Addresses run from 0 to 65535 and values from 0 to 255; anything else is ?ILLEGAL QUANTITY
+
+package interpreter
+
+import (
+	"fmt"
+
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// memory returns the runtime's memory, falling back to the interpreter's own
+func (i *Interpreter) memory() runtime.Memory {
+	if m, ok := i.runtime.(runtime.Memory); ok {
+		return m
+	}
+	if i.ram == nil {
+		i.ram = &runtime.RAM{}
+	}
+	return i.ram
+}
+
+// Poke implements POKE address, value
+func (i *Interpreter) Poke(address, value int) error {
+	if address < 0 || address >= runtime.MemorySize || value < 0 || value > 255 {
+		return ErrIllegalQuantity
+	}
+	i.memory().Poke(address, byte(value))
+	return nil
+}
+
+// evaluatePeekFunction implements PEEK(address)
+func (i *Interpreter) evaluatePeekFunction(args []types.Value) (types.Value, error) {
+	if len(args) != 1 {
+		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: PEEK requires exactly 1 argument")
+	}
+	if args[0].Type != types.NumberType {
+		return types.Value{}, types.ErrTypeMismatch
+	}
+	address := int(args[0].Number)
+	if args[0].Number < 0 || address >= runtime.MemorySize {
+		return types.Value{}, ErrIllegalQuantity
+	}
+	return types.NewNumberValue(float64(i.memory().Peek(address))), nil
+}
@@ -0,0 +1,67 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+// plainRuntime hides the emulated memory of the runtime it wraps
+type plainRuntime struct {
+	runtime.Runtime
+}
+
+func TestInterpreter_PokeDrawsInScreenRAM(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	src := "10 FOR I=0 TO 4: POKE 1024+40*I+I,1+I: NEXT\n20 PRINT PEEK(1024);PEEK(1065)"
+	require.NoError(t, NewInterpreter(rt).Execute(parsertest.Parse(t, src)))
+
+	text := rt.ScreenText()
+	assert.Equal(t, []string{"A", " B", "  C", "   D", "    E", ""}, text[:6])
+	assert.Equal(t, []string{"1 2\n"}, rt.GetOutput())
+}
+
+func TestInterpreter_PeekPoke(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(*runtime.TestRuntime) runtime.Runtime // nil runs on the test runtime itself
+		src  string
+		want []string
+		err  string
+	}{
+		{name: "memory of the runtime", src: "10 POKE 53280,6: PRINT PEEK(53280);PEEK(1024)", want: []string{"6 32\n"}},
+		{name: "memory of the interpreter", wrap: func(rt *runtime.TestRuntime) runtime.Runtime { return plainRuntime{rt} }, src: "10 POKE 828,255: PRINT PEEK(828)", want: []string{"255\n"}},
+		{name: "address too large", src: "10 POKE 65536,1", err: "?ILLEGAL QUANTITY ERROR IN 10"},
+		{name: "value too large", src: "10 POKE 1024,256", err: "?ILLEGAL QUANTITY ERROR IN 10"},
+		{name: "negative PEEK", src: "10 PRINT PEEK(-1)", err: "?ILLEGAL QUANTITY ERROR IN 10"},
+		{name: "string address", src: "10 A$=\"A\": PRINT PEEK(A$+\"\")", err: "?TYPE MISMATCH ERROR IN 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := runtime.NewTestRuntime()
+			var rt runtime.Runtime = test
+			if tt.wrap != nil {
+				rt = tt.wrap(test)
+			}
+			err := NewInterpreter(rt).Execute(parsertest.Parse(t, tt.src))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, test.GetOutput())
+		})
+	}
+}
+
+func TestInterpreter_PeekReadsPrintedScreen(t *testing.T) {
+	screen := runtime.NewScreenRuntime(runtime.NewTestRuntime())
+	interp := NewInterpreter(screen)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, "10 PRINT \"HI\"\n20 X=PEEK(1024)+PEEK(1025)")))
+	assert.Equal(t, float64(8+9), interp.Variables()["X"].Number)
+}
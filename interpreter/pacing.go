@@ -0,0 +1,78 @@
+// ABOUTME: Optional pacing that slows execution to the statement rate of an original machine
+// ABOUTME: A cycles-per-statement model gives each statement a duration; the interpreter sleeps off any lead
+
+package interpreter
+
+import (
+	"time"
+
+	"basic-interpreter/runtime"
+)
+
+// SpeedModel approximates how quickly the original machine interpreted BASIC statements
+type SpeedModel struct {
+	ClockHz            float64 // CPU clock frequency in Hz
+	CyclesPerStatement float64 // Average CPU cycles spent interpreting one statement
+}
+
+// C64Speed approximates a PAL Commodore 64: a 985 kHz 6510 spending about 1000 cycles per statement
+var C64Speed = SpeedModel{ClockHz: 985248, CyclesPerStatement: 1000}
+
+// StatementTime returns how long one statement takes under the model (0 when the model is unset)
+func (m SpeedModel) StatementTime() time.Duration {
+	if m.ClockHz <= 0 || m.CyclesPerStatement <= 0 {
+		return 0
+	}
+	return time.Duration(m.CyclesPerStatement / m.ClockHz * float64(time.Second))
+}
+
+// Pacing thresholds: short leads are batched into one sleep, long stalls (e.g. waiting for INPUT) are forgiven
+const (
+	minPaceSleep = 10 * time.Millisecond
+	maxPaceLag   = 50 * time.Millisecond
+)
+
+// pacer keeps the statement rate at or below the model's by sleeping whenever execution runs ahead
+type pacer struct {
+	perStatement time.Duration
+	start        time.Time
+	statements   int64
+	now          func() time.Time
+	sleep        func(time.Duration)
+}
+
+// newPacer creates a pacer for the given per-statement duration
+func newPacer(perStatement time.Duration) *pacer {
+	return &pacer{perStatement: perStatement, now: time.Now, sleep: time.Sleep}
+}
+
+// step accounts for one executed statement and sleeps if execution is ahead of the model
+func (p *pacer) step() {
+	now := p.now()
+	// Far behind, typically after blocking on input: start a new baseline instead of racing to catch up
+	if p.statements == 0 || p.lead(now) < -maxPaceLag {
+		p.start = now
+		p.statements = 0
+	}
+	p.statements++
+	if lead := p.lead(now); lead >= minPaceSleep {
+		p.sleep(lead)
+	}
+}
+
+// lead returns how far execution is ahead of the model at the given time
+func (p *pacer) lead(now time.Time) time.Duration {
+	return time.Duration(p.statements)*p.perStatement - now.Sub(p.start)
+}
+
+// SetSpeed paces execution to the given model; the zero SpeedModel runs at full speed.
+// Runtimes implementing runtime.Sleeper supply the clock, so a headless run can pace in emulated time.
+func (i *Interpreter) SetSpeed(model SpeedModel) {
+	i.pacer = nil
+	if d := model.StatementTime(); d > 0 {
+		i.pacer = newPacer(d)
+		if sleeper, ok := i.runtime.(runtime.Sleeper); ok {
+			i.pacer.now, i.pacer.sleep = i.runtime.Now, sleeper.Sleep
+		}
+	}
+}
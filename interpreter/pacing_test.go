@@ -0,0 +1,39 @@
+package interpreter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpeedModel_StatementTime(t *testing.T) {
+	assert.Equal(t, time.Millisecond, SpeedModel{ClockHz: 1000000, CyclesPerStatement: 1000}.StatementTime())
+	assert.InDelta(t, float64(time.Millisecond), float64(C64Speed.StatementTime()), float64(50*time.Microsecond))
+	assert.Zero(t, SpeedModel{}.StatementTime())
+}
+
+func TestPacer_SleepsOffLeadAndForgivesStalls(t *testing.T) {
+	clock := time.Unix(0, 0)
+	var slept []time.Duration
+	p := newPacer(time.Millisecond)
+	p.now = func() time.Time { return clock }
+	p.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		clock = clock.Add(d)
+	}
+
+	// Instant statements: one 10ms sleep per 10 statements keeps the rate at 1000/s
+	for n := 0; n < 30; n++ {
+		p.step()
+	}
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond}, slept)
+
+	// A long stall (e.g. INPUT) is not made up by running flat out afterwards
+	clock = clock.Add(time.Second)
+	slept = nil
+	for n := 0; n < 20; n++ {
+		p.step()
+	}
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}, slept)
+}
@@ -0,0 +1,55 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+func runLayoutProgram(t *testing.T, src string, configure func(*Interpreter)) []string {
+	t.Helper()
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	configure(interp)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, src)))
+	return rt.GetOutput()
+}
+
+func TestInterpreter_PrintZones(t *testing.T) {
+	t.Run("custom zone width", func(t *testing.T) {
+		out := runLayoutProgram(t, `10 PRINT "A","B"`, func(i *Interpreter) { i.SetZoneWidth(4) })
+		assert.Equal(t, []string{"A   B\n"}, out)
+	})
+
+	t.Run("zones account for text printed earlier on the line", func(t *testing.T) {
+		out := runLayoutProgram(t, "10 PRINT \"ABC\";\n20 PRINT \"D\",\"E\"", func(i *Interpreter) {})
+		assert.Equal(t, []string{"ABC", "D      E\n"}, out)
+	})
+}
+
+func TestInterpreter_ScreenWidthWrapping(t *testing.T) {
+	t.Run("no wrapping by default", func(t *testing.T) {
+		out := runLayoutProgram(t, `10 PRINT "ABCDEFGHIJKL"`, func(i *Interpreter) {})
+		assert.Equal(t, []string{"ABCDEFGHIJKL\n"}, out)
+	})
+
+	t.Run("wrapping continues across PRINT statements", func(t *testing.T) {
+		out := runLayoutProgram(t, "10 PRINT \"ABCD\";\n20 PRINT \"EFGH\"", func(i *Interpreter) { i.SetScreenWidth(6) })
+		assert.Equal(t, []string{"ABCD", "EF\nGH\n"}, out)
+	})
+
+	t.Run("input resets the output column", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		rt.SetInput([]string{"1"})
+		interp := NewInterpreter(rt)
+		interp.SetScreenWidth(4)
+		require.NoError(t, interp.Print("ABC"))
+		_, err := interp.ReadInput("")
+		require.NoError(t, err)
+		assert.Equal(t, 0, interp.OutputColumn())
+	})
+}
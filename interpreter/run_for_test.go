@@ -0,0 +1,68 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+func TestInterpreter_RunFor(t *testing.T) {
+	parse := func(src string) *parser.Program {
+		p := parser.New(lexer.New(src))
+		program := p.ParseProgram()
+		require.Nil(t, p.ParseError())
+		return program
+	}
+
+	t.Run("resumes mid-line and inside loops", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		interp := NewInterpreter(rt)
+		interp.Start(parse("10 FOR I=1 TO 3: PRINT I;: NEXT I\n20 PRINT \"!\""))
+
+		var frames int
+		for {
+			status, err := interp.RunFor(2)
+			require.NoError(t, err)
+			frames++
+			if status == StatusDone {
+				break
+			}
+		}
+		assert.Equal(t, []string{"1", "2", "3", "!\n"}, rt.GetOutput())
+		assert.Equal(t, 4, frames) // 8 statements in frames of 2
+	})
+
+	t.Run("no statements run until RunFor is called", func(t *testing.T) {
+		rt := runtime.NewTestRuntime()
+		interp := NewInterpreter(rt)
+		interp.Start(parse("10 PRINT \"A\"\n20 PRINT \"B\""))
+		assert.Empty(t, rt.GetOutput())
+
+		status, err := interp.RunFor(1)
+		require.NoError(t, err)
+		assert.Equal(t, StatusRunning, status)
+		assert.Equal(t, []string{"A\n"}, rt.GetOutput())
+	})
+
+	t.Run("END and errors finish the program", func(t *testing.T) {
+		interp := NewInterpreter(runtime.NewTestRuntime())
+		interp.Start(parse("10 END\n20 PRINT 1"))
+		status, err := interp.RunFor(10)
+		require.NoError(t, err)
+		assert.Equal(t, StatusDone, status)
+
+		interp.Start(parse("10 PRINT 1/0"))
+		status, err = interp.RunFor(10)
+		assert.Equal(t, StatusDone, status)
+		assert.ErrorContains(t, err, "IN 10")
+
+		status, err = interp.RunFor(10)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusDone, status)
+	})
+}
@@ -0,0 +1,260 @@
+// ABOUTME: Save-states: Snapshot copies where a stopped or paused program is and its variables, arrays and stacks
+// ABOUTME: Restore loads a snapshot, encoded as JSON, into an interpreter for the same program so CONT or RunFor resume it
+
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot
+const SnapshotVersion = 2
+
+// Snapshot is the state of a program stopped by STOP or paused between statements. It encodes as JSON;
+// EVERY and AFTER timers are not kept.
+type Snapshot struct {
+	Version   int                    `json:"version"`
+	Line      int                    `json:"line"`      // BASIC line execution resumes on, checked against the program
+	PC        int                    `json:"pc"`        // Index of that line in the program
+	Statement int                    `json:"statement"` // Statement execution resumes at within the line
+	Variables map[string]types.Value `json:"variables"`
+	Arrays    map[string]ArrayInfo   `json:"arrays"`
+	Functions []SnapshotFunction     `json:"functions,omitempty"`
+	Constants []string               `json:"constants,omitempty"`
+	Declared  []string               `json:"declared,omitempty"`
+	Explicit  bool                   `json:"explicit,omitempty"`
+	ForLoops  []ForLoopContext       `json:"for,omitempty"`
+	Whiles    []WhileLoopContext     `json:"while,omitempty"`
+	Dos       []DoLoopContext        `json:"do,omitempty"`
+	Calls     []CallContext          `json:"gosub,omitempty"`
+	Data      int                    `json:"data"`   // DATA pointer: values READ so far
+	Column    int                    `json:"column"` // Output column
+}
+
+// SnapshotFunction is a DEF FN function, found again on restore by the line of its DEF statement
+type SnapshotFunction struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// Snapshot copies the state of the loaded program, which must be stopped by STOP or paused between statements.
+// It fails with ErrCantContinue when no program can resume, and for functions the host defined.
+func (i *Interpreter) Snapshot() (*Snapshot, error) {
+	pc, stmt := i.pc, i.stmtIndex
+	if i.resume != nil {
+		pc, stmt = i.resume.pc, i.resume.stmtIndex
+	}
+	if i.program == nil || pc < 0 || pc >= len(i.program.Lines) {
+		return nil, ErrCantContinue
+	}
+
+	s := &Snapshot{
+		Version:   SnapshotVersion,
+		Line:      i.program.Lines[pc].Number,
+		PC:        pc,
+		Statement: stmt,
+		Variables: i.Variables(),
+		Arrays:    make(map[string]ArrayInfo, len(i.arrays)),
+		Constants: sortedKeys(i.constants),
+		Declared:  sortedKeys(i.declared),
+		Explicit:  i.explicit,
+		ForLoops:  append([]ForLoopContext(nil), i.forStack.items...),
+		Whiles:    append([]WhileLoopContext(nil), i.whileStack.items...),
+		Dos:       append([]DoLoopContext(nil), i.doStack.items...),
+		Calls:     append([]CallContext(nil), i.callStack.items...),
+		Data:      i.dataPointer,
+		Column:    i.column,
+	}
+	for name, array := range i.arrays {
+		array.Values = append([]types.Value(nil), array.Values...)
+		s.Arrays[name] = array
+	}
+	for _, name := range sortedKeys(i.userFunctions) {
+		line, ok := definingLine(i.program, i.userFunctions[name].Body)
+		if !ok {
+			return nil, fmt.Errorf("function %s is not defined by the program", name)
+		}
+		s.Functions = append(s.Functions, SnapshotFunction{Name: name, Line: line})
+	}
+	return s, nil
+}
+
+// Restore replaces the program state with a snapshot taken while running program. Afterwards Continue(program)
+// resumes the run where the snapshot was taken, and so does RunFor.
+func (i *Interpreter) Restore(program *parser.Program, s *Snapshot) error {
+	if s.Version != SnapshotVersion {
+		return fmt.Errorf("snapshot version %d is not supported", s.Version)
+	}
+	if s.PC < 0 || s.PC >= len(program.Lines) || program.Lines[s.PC].Number != s.Line {
+		return fmt.Errorf("snapshot was taken at line %d of a different program", s.Line)
+	}
+	if err := s.check(program); err != nil {
+		return err
+	}
+
+	i.Reset()
+	i.load(program)
+	for _, f := range s.Functions {
+		def, ok := findDefFn(program, f.Line, f.Name)
+		if !ok {
+			return fmt.Errorf("snapshot function %s is not defined on line %d", f.Name, f.Line)
+		}
+		if err := i.DefineUserFunction(def.Name, def.Param, def.Body); err != nil {
+			return err
+		}
+	}
+	for name, value := range s.Variables {
+		i.variables[name] = i.interned.InternValue(value)
+	}
+	for name, array := range s.Arrays {
+		array.Values = append([]types.Value(nil), array.Values...)
+		i.arrays[name] = array
+	}
+	for _, name := range s.Constants {
+		i.constants[name] = true
+	}
+	for _, name := range s.Declared {
+		i.declared[name] = true
+	}
+	i.explicit = s.Explicit
+	i.forStack.items = append(i.forStack.items, s.ForLoops...)
+	i.whileStack.items = append(i.whileStack.items, s.Whiles...)
+	i.doStack.items = append(i.doStack.items, s.Dos...)
+	i.callStack.items = append(i.callStack.items, s.Calls...)
+	i.dataPointer = min(s.Data, len(i.dataValues))
+	i.column = s.Column
+
+	i.running = program
+	i.finished = false
+	i.pc, i.stmtIndex = s.PC, s.Statement
+	i.stmtJumped = true
+	i.resume = &resumePoint{program: program, pc: s.PC, stmtIndex: s.Statement, line: s.Line}
+	return nil
+}
+
+// check rejects a snapshot whose positions are outside program or whose values do not fit their variables,
+// so a damaged or edited snapshot fails here rather than when the run resumes
+func (s *Snapshot) check(program *parser.Program) error {
+	if s.Data < 0 {
+		return fmt.Errorf("snapshot DATA pointer %d is negative", s.Data)
+	}
+	if !validPosition(program, s.PC, s.Statement) {
+		return fmt.Errorf("snapshot statement %d is not on line %d", s.Statement, s.Line)
+	}
+	for _, f := range s.ForLoops {
+		if !validPosition(program, f.AfterForLineIndex, f.AfterForStmtIndex) {
+			return fmt.Errorf("snapshot FOR %s resumes outside the program", f.Variable)
+		}
+		if !f.EndValue.IsNumber() || !f.StepValue.IsNumber() {
+			return fmt.Errorf("snapshot FOR %s has a string limit or step", f.Variable)
+		}
+	}
+	for _, w := range s.Whiles {
+		if !validPosition(program, w.LineIndex, w.StmtIndex) {
+			return errors.New("snapshot WHILE is outside the program")
+		}
+	}
+	for _, d := range s.Dos {
+		if !validPosition(program, d.LineIndex, d.StmtIndex) {
+			return errors.New("snapshot DO is outside the program")
+		}
+	}
+	for _, c := range s.Calls {
+		if !validPosition(program, c.ReturnLineIndex, c.ReturnStmtIndex) {
+			return errors.New("snapshot GOSUB returns outside the program")
+		}
+		if c.DoDepth < 0 || c.DoDepth > len(s.Dos) {
+			return fmt.Errorf("snapshot GOSUB DO depth %d is out of range", c.DoDepth)
+		}
+	}
+	for name, value := range s.Variables {
+		if value.IsString() != strings.HasSuffix(name, "$") {
+			return fmt.Errorf("snapshot variable %s has the wrong type", name)
+		}
+	}
+	for name, array := range s.Arrays {
+		if err := checkArray(array); err != nil {
+			return fmt.Errorf("snapshot array %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validPosition reports whether a statement index is on the line at lineIndex of program, or just past its
+// last statement. Indexes count the statements of IF branches, as laid out by layoutStatements.
+func validPosition(program *parser.Program, lineIndex, stmtIndex int) bool {
+	if lineIndex < 0 || lineIndex >= len(program.Lines) || stmtIndex < 0 {
+		return false
+	}
+	return stmtIndex <= len(layoutStatements(nil, program.Lines[lineIndex].Statements))
+}
+
+// checkArray checks that an array's dimensions are row-major as DIM lays them out and hold exactly its values
+func checkArray(array ArrayInfo) error {
+	if len(array.Sizes) == 0 || len(array.Sizes) > MaxArrayDimensions || len(array.Strides) != len(array.Sizes) {
+		return errors.New("dimensions do not match")
+	}
+	count := 1
+	for d := len(array.Sizes) - 1; d >= 0; d-- {
+		if array.Sizes[d] < 0 || array.Strides[d] != count || array.Sizes[d]+1 > MaxArrayElements/count {
+			return errors.New("dimensions do not match")
+		}
+		count *= array.Sizes[d] + 1
+	}
+	if len(array.Values) != count {
+		return fmt.Errorf("has %d values for %d elements", len(array.Values), count)
+	}
+	for _, value := range array.Values {
+		if value.IsString() != array.IsString {
+			return errors.New("holds a value of the wrong type")
+		}
+	}
+	return nil
+}
+
+// definingLine returns the line of the DEF FN statement whose body is body
+func definingLine(program *parser.Program, body parser.Expression) (int, bool) {
+	for _, line := range program.Lines {
+		if def := matchDefFn(line.Statements, func(d *parser.DefFnStatement) bool { return d.Body == body }); def != nil {
+			return line.Number, true
+		}
+	}
+	return 0, false
+}
+
+// findDefFn returns the DEF FN statement defining name on a line of program
+func findDefFn(program *parser.Program, lineNumber int, name string) (*parser.DefFnStatement, bool) {
+	for _, line := range program.Lines {
+		if line.Number != lineNumber {
+			continue
+		}
+		def := matchDefFn(line.Statements, func(d *parser.DefFnStatement) bool { return strings.EqualFold(d.Name, name) })
+		return def, def != nil
+	}
+	return nil, false
+}
+
+// matchDefFn returns the first DEF FN statement matching match, looking inside IF branches
+func matchDefFn(stmts []parser.Statement, match func(*parser.DefFnStatement) bool) *parser.DefFnStatement {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *parser.DefFnStatement:
+			if match(s) {
+				return s
+			}
+		case *parser.IfStatement:
+			if def := matchDefFn(s.ThenStmts, match); def != nil {
+				return def
+			}
+			if def := matchDefFn(s.ElseStmts, match); def != nil {
+				return def
+			}
+		}
+	}
+	return nil
+}
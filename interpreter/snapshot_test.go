@@ -0,0 +1,143 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// snapshotProgram stops inside a FOR loop inside a subroutine, with an array, a function and DATA part read
+const snapshotProgram = `10 DIM A$(2): DEF FNS(X)=X*X: CONST K=3
+20 READ D: GOSUB 100
+30 PRINT "BACK";D;A$(1);FNS(K)
+40 END
+100 FOR I=1 TO 2
+110 A$(I)="A"+STR$(I): IF I=1 THEN STOP
+120 NEXT I: READ D: RETURN
+200 DATA 5,7`
+
+func TestSnapshotRestoresStoppedRun(t *testing.T) {
+	program := parser.New(lexer.New(snapshotProgram)).ParseProgram()
+	first := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, first.Execute(program))
+
+	snap, err := first.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, 110, snap.Line)
+	blob, err := json.Marshal(snap)
+	require.NoError(t, err)
+
+	// The uninterrupted run continues in the first interpreter, the restored one in a fresh interpreter
+	require.NoError(t, first.Continue(program))
+	var decoded Snapshot
+	require.NoError(t, json.Unmarshal(blob, &decoded))
+	rt := runtime.NewTestRuntime()
+	second := NewInterpreter(rt)
+	require.NoError(t, second.Restore(program, &decoded))
+	require.NoError(t, second.Continue(program))
+
+	assert.Equal(t, "BACK 7 A1 9\n", strings.Join(rt.GetOutput(), ""))
+	assert.Equal(t, first.Variables(), second.Variables())
+	assert.Equal(t, first.Inspect().Arrays, second.Inspect().Arrays)
+	assert.Equal(t, first.constants, second.constants)
+}
+
+func TestSnapshotResumesWithRunFor(t *testing.T) {
+	program := parser.New(lexer.New("10 FOR I=1 TO 4: PRINT I;: NEXT I")).ParseProgram()
+	first := NewInterpreter(runtime.NewTestRuntime())
+	first.Start(program)
+	_, err := first.RunFor(5)
+	require.NoError(t, err)
+	snap, err := first.Snapshot()
+	require.NoError(t, err)
+
+	rt := runtime.NewTestRuntime()
+	second := NewInterpreter(rt)
+	require.NoError(t, second.Restore(program, snap))
+	status, err := second.RunFor(100)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDone, status)
+	assert.Equal(t, "34", strings.Join(rt.GetOutput(), ""))
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	program := parser.New(lexer.New("10 STOP\n20 PRINT 1")).ParseProgram()
+	other := parser.New(lexer.New("5 REM\n10 STOP\n20 PRINT 1")).ParseProgram()
+
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	_, err := interp.Snapshot()
+	assert.Equal(t, ErrCantContinue, err)
+
+	require.NoError(t, interp.Execute(program))
+	snap, err := interp.Snapshot()
+	require.NoError(t, err)
+	assert.EqualError(t, NewInterpreter(runtime.NewTestRuntime()).Restore(other, snap), "snapshot was taken at line 10 of a different program")
+
+	require.NoError(t, interp.DefineFunction("FNH", "X", "X+1"))
+	_, err = interp.Snapshot()
+	assert.EqualError(t, err, "function FNH is not defined by the program")
+}
+
+func TestSnapshotRestoreRejectsDamagedState(t *testing.T) {
+	program := parser.New(lexer.New("10 DIM A(2,1): GOSUB 30\n20 END\n30 FOR I=1 TO 2: STOP: NEXT I: RETURN")).ParseProgram()
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.Execute(program))
+
+	tests := []struct {
+		name     string
+		damage   func(s *Snapshot)
+		expected string
+	}{
+		{"statement past the line", func(s *Snapshot) { s.Statement = 9 }, "snapshot statement 9 is not on line 30"},
+		{"array missing values", func(s *Snapshot) {
+			a := s.Arrays["A"]
+			a.Values = a.Values[:4]
+			s.Arrays["A"] = a
+		}, "snapshot array A: has 4 values for 6 elements"},
+		{"array strides", func(s *Snapshot) {
+			a := s.Arrays["A"]
+			a.Strides = []int{1, 3}
+			s.Arrays["A"] = a
+		}, "snapshot array A: dimensions do not match"},
+		{"array value type", func(s *Snapshot) {
+			a := s.Arrays["A"]
+			a.Values = append([]types.Value{types.NewStringValue("X")}, a.Values[1:]...)
+			s.Arrays["A"] = a
+		}, "snapshot array A: holds a value of the wrong type"},
+		{"variable type", func(s *Snapshot) { s.Variables["I"] = types.NewStringValue("1") }, "snapshot variable I has the wrong type"},
+		{"FOR line", func(s *Snapshot) { s.ForLoops[0].AfterForLineIndex = 3 }, "snapshot FOR I resumes outside the program"},
+		{"GOSUB line", func(s *Snapshot) { s.Calls[0].ReturnLineIndex = -1 }, "snapshot GOSUB returns outside the program"},
+		{"GOSUB statement", func(s *Snapshot) { s.Calls[0].ReturnStmtIndex = 5 }, "snapshot GOSUB returns outside the program"},
+		{"GOSUB DO depth", func(s *Snapshot) { s.Calls[0].DoDepth = 1 }, "snapshot GOSUB DO depth 1 is out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, err := interp.Snapshot()
+			require.NoError(t, err)
+			tt.damage(snap)
+			assert.EqualError(t, NewInterpreter(runtime.NewTestRuntime()).Restore(program, snap), tt.expected)
+		})
+	}
+}
+
+func TestSnapshotJSONFieldNames(t *testing.T) {
+	program := parser.New(lexer.New("10 DIM A$(1): A$(1)=\"X\": FOR I=1 TO 2: STOP: NEXT I")).ParseProgram()
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.Execute(program))
+	snap, err := interp.Snapshot()
+	require.NoError(t, err)
+	blob, err := json.Marshal(snap)
+	require.NoError(t, err)
+
+	encoded := string(blob)
+	assert.Contains(t, encoded, `"arrays":{"A$":{"isString":true,"sizes":[1],"strides":[1],"values":[{"type":"string"},{"type":"string","string":"X"}]}}`)
+	assert.Contains(t, encoded, `"for":[{"variable":"I","end":{"type":"number","number":2},"step":{"type":"number","number":1},"lineIndex":0,"stmtIndex":3,"forLine":10}]`)
+}
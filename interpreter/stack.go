@@ -1,5 +1,5 @@
 // ABOUTME: Generic stack data structure for managing interpreter state stacks
-// ABOUTME: Provides type-safe stack operations with bounds checking for FOR loops and GOSUB calls
+// ABOUTME: Provides type-safe stack operations with bounds checking for FOR/DO loops and GOSUB calls
 
 package interpreter
 
@@ -67,3 +67,22 @@ func (s *Stack[T]) FindByPredicate(predicate func(T) bool) *T {
 	}
 	return nil
 }
+
+// UnwindTo pops items above the topmost item matching the predicate and returns a pointer to it
+// Returns nil and leaves the stack unchanged if no item matches
+func (s *Stack[T]) UnwindTo(predicate func(T) bool) *T {
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if predicate(s.items[i]) {
+			s.items = s.items[:i+1]
+			return &s.items[i]
+		}
+	}
+	return nil
+}
+
+// Truncate discards items so that at most size remain
+func (s *Stack[T]) Truncate(size int) {
+	if size >= 0 && size < len(s.items) {
+		s.items = s.items[:size]
+	}
+}
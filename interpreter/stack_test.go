@@ -126,3 +126,34 @@ func TestStack_BasicOperations(t *testing.T) {
 		t.Error("Expected nil when popping empty stack")
 	}
 }
+
+func TestStack_UnwindToAndTruncate(t *testing.T) {
+	stack := NewStack[int](10)
+	for _, v := range []int{1, 2, 3, 4} {
+		_ = stack.Push(v)
+	}
+
+	if stack.UnwindTo(func(v int) bool { return v == 9 }) != nil {
+		t.Error("Expected nil when no item matches")
+	}
+	if stack.Size() != 4 {
+		t.Errorf("Expected size unchanged at 4, got %d", stack.Size())
+	}
+
+	found := stack.UnwindTo(func(v int) bool { return v == 2 })
+	if found == nil || *found != 2 {
+		t.Errorf("Expected 2, got %v", found)
+	}
+	if stack.Size() != 2 {
+		t.Errorf("Expected size 2 after unwinding, got %d", stack.Size())
+	}
+
+	stack.Truncate(5)
+	if stack.Size() != 2 {
+		t.Errorf("Expected truncate to larger size to be a no-op, got %d", stack.Size())
+	}
+	stack.Truncate(0)
+	if !stack.IsEmpty() {
+		t.Error("Expected stack to be empty after truncate")
+	}
+}
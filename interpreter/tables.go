@@ -0,0 +1,113 @@
+// ABOUTME: Host access to arrays and DEF FN functions: listing them after a run and registering them before one
+// ABOUTME: Lets a host hand tables of data to a program and read results back without generating DATA statements
+
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// ArrayDeclaration describes a declared array
+type ArrayDeclaration struct {
+	Name     string // Normalized name
+	Sizes    []int  // Highest index of each dimension
+	IsString bool
+}
+
+// FunctionDefinition describes a DEF FN function
+type FunctionDefinition struct {
+	Name  string // Name including FN, in upper case, such as FNA
+	Param string
+	Body  parser.Expression
+}
+
+// Arrays lists the declared arrays, including those dimensioned on first use, sorted by name
+func (i *Interpreter) Arrays() []ArrayDeclaration {
+	arrays := make([]ArrayDeclaration, 0, len(i.arrays))
+	for _, name := range sortedKeys(i.arrays) {
+		array := i.arrays[name]
+		arrays = append(arrays, ArrayDeclaration{Name: name, Sizes: append([]int(nil), array.Sizes...), IsString: array.IsString})
+	}
+	return arrays
+}
+
+// ArrayValues returns a copy of an array's elements in row-major order (the last index varies fastest);
+// it reports false when the array is not declared
+func (i *Interpreter) ArrayValues(name string) ([]types.Value, bool) {
+	array, ok := i.arrays[i.NormalizeVariableName(name)]
+	if !ok {
+		return nil, false
+	}
+	return append([]types.Value(nil), array.Values...), true
+}
+
+// SetArray declares an array as DIM name(sizes...) does, replacing any array of that name, and fills it
+// from values in row-major order. Elements past the end of values keep their default.
+func (i *Interpreter) SetArray(name string, sizes []int, values []types.Value) error {
+	isString := strings.HasSuffix(name, "$")
+	for _, value := range values {
+		if (value.Type == types.StringType) != isString {
+			return types.ErrTypeMismatch
+		}
+	}
+
+	norm := i.NormalizeVariableName(name)
+	previous, existed := i.arrays[norm]
+	delete(i.arrays, norm)
+	err := i.DeclareArray(name, sizes, isString)
+	if n := len(i.arrays[norm].Values); err == nil && len(values) > n {
+		err = fmt.Errorf("%w: %d values for the %d elements of %s", ErrIllegalQuantity, len(values), n, name)
+	}
+	if err != nil {
+		delete(i.arrays, norm)
+		if existed {
+			i.arrays[norm] = previous
+		}
+		return err
+	}
+
+	array := i.arrays[norm]
+	for n, value := range values {
+		array.Values[n] = i.interned.InternValue(value)
+	}
+	i.declared[norm] = true
+	return nil
+}
+
+// Functions lists the DEF FN functions, sorted by name
+func (i *Interpreter) Functions() []FunctionDefinition {
+	functions := make([]FunctionDefinition, 0, len(i.userFunctions))
+	for _, name := range sortedKeys(i.userFunctions) {
+		function := i.userFunctions[name]
+		functions = append(functions, FunctionDefinition{Name: name, Param: function.Param, Body: function.Body})
+	}
+	return functions
+}
+
+// LookupFunction returns the DEF FN function called name, such as FNA
+func (i *Interpreter) LookupFunction(name string) (FunctionDefinition, bool) {
+	upper := strings.ToUpper(name)
+	function, ok := i.userFunctions[upper]
+	if !ok {
+		return FunctionDefinition{}, false
+	}
+	return FunctionDefinition{Name: upper, Param: function.Param, Body: function.Body}, true
+}
+
+// DefineFunction registers a function as DEF name(param) = body does; body is the source of a BASIC expression
+func (i *Interpreter) DefineFunction(name, param, body string) error {
+	if !strings.HasPrefix(strings.ToUpper(name), "FN") || len(name) <= 2 {
+		return fmt.Errorf("?SYNTAX ERROR: function name %q must start with FN", name)
+	}
+	p := parser.New(lexer.New(body))
+	expr := p.ParseExpression()
+	if e := p.ParseError(); e != nil {
+		return fmt.Errorf("?SYNTAX ERROR: %s", e.Message)
+	}
+	return i.DefineUserFunction(name, param, expr)
+}
@@ -0,0 +1,66 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func TestInterpreter_ArraysAndFunctionsAfterRun(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	src := "10 DIM M(1,2), N$(3)\n20 M(1,2)=7: B(1)=1\n30 DEF FNS(X)=X*X"
+	require.NoError(t, interp.Execute(parsertest.Parse(t, src)))
+
+	assert.Equal(t, []ArrayDeclaration{
+		{Name: "B", Sizes: []int{DefaultArraySize}},
+		{Name: "M", Sizes: []int{1, 2}},
+		{Name: "N$", Sizes: []int{3}, IsString: true},
+	}, interp.Arrays())
+
+	values, ok := interp.ArrayValues("M")
+	require.True(t, ok)
+	assert.Equal(t, types.NewNumberValue(7), values[5])
+	_, ok = interp.ArrayValues("Z")
+	assert.False(t, ok)
+
+	functions := interp.Functions()
+	require.Len(t, functions, 1)
+	assert.Equal(t, "FNS", functions[0].Name)
+	assert.Equal(t, "X", functions[0].Param)
+}
+
+func TestInterpreter_PreRegisteredArraysAndFunctions(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.SetArray("P", []int{1, 1}, []types.Value{
+		types.NewNumberValue(1), types.NewNumberValue(2), types.NewNumberValue(3),
+	}))
+	require.NoError(t, interp.SetArray("N$", []int{1}, []types.Value{types.NewStringValue("AB")}))
+	require.NoError(t, interp.DefineFunction("FNT", "X", "X*10"))
+
+	src := "10 OPTION EXPLICIT\n20 PRINT P(1,0);P(1,1);N$(0);FNT(2)\n30 P(1,1)=9"
+	require.NoError(t, interp.Execute(parsertest.Parse(t, src)))
+	assert.Equal(t, "3 0 AB 20\n", strings.Join(rt.GetOutput(), ""))
+
+	values, _ := interp.ArrayValues("P")
+	assert.Equal(t, types.NewNumberValue(9), values[3])
+}
+
+func TestInterpreter_SetArrayErrors(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.SetArray("A", []int{1}, nil))
+
+	assert.ErrorIs(t, interp.SetArray("A", []int{1}, []types.Value{types.NewStringValue("X")}), types.ErrTypeMismatch)
+	assert.ErrorIs(t, interp.SetArray("A", []int{0}, []types.Value{types.NewNumberValue(1), types.NewNumberValue(2)}), ErrIllegalQuantity)
+	assert.ErrorIs(t, interp.SetArray("A", []int{-1}, nil), ErrIllegalQuantity)
+	assert.Equal(t, []ArrayDeclaration{{Name: "A", Sizes: []int{1}}}, interp.Arrays(), "a failed SetArray keeps the previous array")
+
+	assert.Error(t, interp.DefineFunction("SQ", "X", "X*X"))
+	assert.Error(t, interp.DefineFunction("FNQ", "X", "X*"))
+}
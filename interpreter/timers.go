@@ -0,0 +1,95 @@
+// ABOUTME: EVERY and AFTER: timers that call a subroutine on jiffy ticks of the runtime's clock, as in Locomotive BASIC
+// ABOUTME: Due timers are checked between statements; a handler runs like a GOSUB and no other timer interrupts it
+
+package interpreter
+
+import (
+	"time"
+
+	"basic-interpreter/runtime"
+)
+
+// MaxTimers is the number of EVERY/AFTER timers, numbered from 0; a higher number has the higher priority
+const MaxTimers = 4
+
+// MaxTimerTicks bounds the jiffies of an EVERY or AFTER interval
+const MaxTimerTicks = 65535
+
+// timer is one EVERY or AFTER schedule
+type timer struct {
+	armed    bool
+	target   int           // Line number the handler starts at
+	interval time.Duration // Time between calls for EVERY, 0 for AFTER
+	due      time.Time
+}
+
+// timerState holds the timers and whether a handler is running
+type timerState struct {
+	timers   [MaxTimers]timer
+	armed    int  // Timers armed
+	handling bool // A handler is running; no timer fires until it returns
+	resuming bool // A handler just returned; the statement it interrupted runs before the next call
+}
+
+// SetTimer implements EVERY and AFTER: GOSUB targetLine after ticks jiffies, repeatedly when repeat is set.
+// Setting a timer replaces its previous schedule; 0 ticks stops it.
+func (i *Interpreter) SetTimer(number, ticks int, repeat bool, targetLine int) error {
+	if number < 0 || number >= MaxTimers || ticks < 0 || ticks > MaxTimerTicks {
+		return ErrIllegalQuantity
+	}
+	t := &i.timers.timers[number]
+	if t.armed {
+		i.timers.armed--
+	}
+	*t = timer{}
+	if ticks == 0 {
+		return nil
+	}
+	if _, err := i.resolveTarget(targetLine); err != nil {
+		return err
+	}
+
+	interval := time.Duration(ticks) * runtime.Jiffy
+	*t = timer{armed: true, target: targetLine, due: i.runtime.Now().Add(interval)}
+	if repeat {
+		t.interval = interval
+	}
+	i.timers.armed++
+	return nil
+}
+
+// fireTimer calls the handler of the highest-priority due timer, as a GOSUB made before the statement at
+// the current position; the handler's RETURN resumes that statement. It reports whether a handler was called.
+func (i *Interpreter) fireTimer() (bool, error) {
+	if i.timers.handling || i.timers.resuming {
+		i.timers.resuming = false
+		return false, nil
+	}
+	now := i.runtime.Now()
+	for n := MaxTimers - 1; n >= 0; n-- {
+		t := &i.timers.timers[n]
+		if !t.armed || now.Before(t.due) {
+			continue
+		}
+		target, err := i.resolveTarget(t.target)
+		if err != nil {
+			return false, err
+		}
+		if t.interval == 0 {
+			t.armed = false
+			i.timers.armed--
+		} else if t.due = t.due.Add(t.interval); !t.due.After(now) {
+			t.due = now.Add(t.interval) // Calls missed while the program was busy are dropped
+		}
+
+		if err := i.callStack.Push(CallContext{ReturnLineIndex: i.pc, ReturnStmtIndex: i.stmtIndex, DoDepth: i.doStack.Size(), Timer: true}); err != nil {
+			return false, err
+		}
+		i.timers.handling = true
+		i.pc = target
+		i.stmtIndex = 0
+		i.stmtJumped = true
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,95 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// runTimerProgram runs src on the deterministic runtime, whose clock advances a jiffy each time it is read
+func runTimerProgram(t *testing.T, src string) (*Interpreter, string, error) {
+	t.Helper()
+	rt := runtime.NewDeterministicRuntime(1, "")
+	interp := NewInterpreter(rt)
+	err := interp.Execute(parsertest.Parse(t, src))
+	return interp, strings.Join(rt.GetOutput(), ""), err
+}
+
+func TestInterpreter_Timers(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		output string
+	}{
+		{
+			name:   "EVERY repeats until the main loop ends",
+			src:    "10 EVERY 3 GOSUB 100\n20 IF N<3 THEN 20\n30 PRINT \"DONE\": END\n100 N=N+1: PRINT \"TICK\";N: RETURN",
+			output: "TICK 1\nTICK 2\nTICK 3\nDONE\n",
+		},
+		{
+			name:   "AFTER fires once",
+			src:    "10 AFTER 2 GOSUB 100\n20 FOR I=1 TO 20: NEXT I\n30 PRINT C: END\n100 C=C+1: RETURN",
+			output: "1\n",
+		},
+		{
+			name:   "zero ticks stops a timer",
+			src:    "10 EVERY 1 GOSUB 100\n20 FOR I=1 TO 10: NEXT I\n30 EVERY 0 GOSUB 100: X=C\n40 FOR I=1 TO 10: NEXT I\n50 PRINT X>0;C=X: END\n100 C=C+1: RETURN",
+			output: "1 1\n",
+		},
+		{
+			name:   "higher timer numbers go first",
+			src:    "10 AFTER 5,0 GOSUB 100: AFTER 3,3 GOSUB 200\n20 FOR I=1 TO 10: NEXT I: END\n100 PRINT \"ZERO\": RETURN\n200 PRINT \"THREE\": RETURN",
+			output: "THREE\nZERO\n",
+		},
+		{
+			name:   "handler resumes the interrupted statement",
+			src:    "10 AFTER 2 GOSUB 100\n20 A=1: B=2: C=3: D=4: PRINT A;B;C;D;H: END\n100 H=9: RETURN",
+			output: "1 2 3 4 9\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := runTimerProgram(t, tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestInterpreter_TimerHandlersAreNotInterrupted(t *testing.T) {
+	src := "10 EVERY 1 GOSUB 100\n20 FOR I=1 TO 30: NEXT I: END\n100 D=D+1: IF D>M THEN M=D\n110 FOR J=1 TO 5: NEXT J\n120 D=D-1: RETURN"
+	interp, _, err := runTimerProgram(t, src)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewNumberValue(1), interp.Variables()["M"])
+}
+
+func TestInterpreter_TimerErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{src: "10 AFTER 5,4 GOSUB 100\n100 RETURN", err: "?ILLEGAL QUANTITY ERROR IN 10"},
+		{src: "10 EVERY 65536 GOSUB 100\n100 RETURN", err: "?ILLEGAL QUANTITY ERROR IN 10"},
+		{src: "10 EVERY 5 GOSUB 999", err: "?UNDEFINED STATEMENT ERROR IN 10: NO LINE 999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, _, err := runTimerProgram(t, tt.src)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestInterpreter_ClearStopsTimers(t *testing.T) {
+	_, output, err := runTimerProgram(t, "10 EVERY 1 GOSUB 100: CLR\n20 FOR I=1 TO 10: NEXT I: PRINT C: END\n100 C=C+1: RETURN")
+	require.NoError(t, err)
+	assert.Equal(t, "0\n", output)
+}
@@ -0,0 +1,140 @@
+// ABOUTME: Optional execution trace recording one event per statement with its variable changes and output
+// ABOUTME: Visualizers replay the ordered events instead of re-running the program; size limits cap long runs
+
+package interpreter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/types"
+)
+
+// TraceEvent records one executed statement and its effects
+type TraceEvent struct {
+	Step      int            `json:"step"`              // 1-based position in the run
+	Line      int            `json:"line"`              // BASIC line number (-1 for an immediate line)
+	Statement int            `json:"statement"`         // Statement index within the line (0-based)
+	Text      string         `json:"text,omitempty"`    // Statement source, filled in by callers that have it
+	Changes   map[string]any `json:"changes,omitempty"` // Variables and array elements assigned, with their new values
+	Output    string         `json:"output,omitempty"`  // Text printed, including newlines
+	Error     string         `json:"error,omitempty"`   // Error that stopped the program here
+}
+
+// TraceLimits bounds how much a trace records; a zero field means no limit
+type TraceLimits struct {
+	MaxEvents int // Statements recorded
+	MaxBytes  int // Approximate encoded size of all events
+}
+
+// DefaultTraceLimits keeps traces of runaway programs to a few megabytes
+var DefaultTraceLimits = TraceLimits{MaxEvents: 100000, MaxBytes: 8 << 20}
+
+// traceEventOverhead approximates the encoded size of an event's fixed fields
+const traceEventOverhead = 64
+
+// Trace is an ordered event stream of an execution. Recording stops once a limit is reached.
+type Trace struct {
+	Events    []TraceEvent `json:"events"`
+	Truncated bool         `json:"truncated"` // A limit was reached and later statements are missing
+
+	limits    TraceLimits
+	bytes     int
+	recording bool // The last event belongs to the statement currently executing
+}
+
+// NewTrace creates an empty trace bounded by limits
+func NewTrace(limits TraceLimits) *Trace {
+	return &Trace{Events: []TraceEvent{}, limits: limits}
+}
+
+// SetTrace records execution into t; nil stops tracing
+func (i *Interpreter) SetTrace(t *Trace) {
+	var hook Hooks
+	if t != nil {
+		hook = &traceHook{interp: i, trace: t}
+	}
+	i.traceHook = i.replaceHooks(i.traceHook, hook)
+	i.trace = t
+}
+
+// begin starts the event of a statement about to execute
+func (t *Trace) begin(step, line, statement int) {
+	t.recording = false
+	if t.Truncated {
+		return
+	}
+	if t.limits.MaxEvents > 0 && len(t.Events) >= t.limits.MaxEvents {
+		t.Truncated = true
+		return
+	}
+	if !t.charge(traceEventOverhead) {
+		return
+	}
+	t.Events = append(t.Events, TraceEvent{Step: step, Line: line, Statement: statement})
+	t.recording = true
+}
+
+// charge accounts for n more bytes, truncating the trace when they exceed the limit
+func (t *Trace) charge(n int) bool {
+	t.bytes += n
+	if t.limits.MaxBytes > 0 && t.bytes > t.limits.MaxBytes {
+		t.Truncated = true
+		t.recording = false
+		return false
+	}
+	return true
+}
+
+// assign records a variable or array element set by the current statement
+func (t *Trace) assign(name string, value types.Value) {
+	if !t.recording {
+		return
+	}
+	var v any = value.String
+	if value.Type == types.NumberType {
+		v = traceNumber(value.Number)
+	}
+	if !t.charge(len(name) + len(value.String) + 16) {
+		return
+	}
+	event := &t.Events[len(t.Events)-1]
+	if event.Changes == nil {
+		event.Changes = make(map[string]any)
+	}
+	event.Changes[name] = v
+}
+
+// output records text printed by the current statement
+func (t *Trace) output(text string) {
+	if !t.recording || !t.charge(len(text)) {
+		return
+	}
+	t.Events[len(t.Events)-1].Output += text
+}
+
+// fail records the error that stopped the current statement
+func (t *Trace) fail(err error) {
+	if !t.recording || !t.charge(len(err.Error())) {
+		return
+	}
+	t.Events[len(t.Events)-1].Error = err.Error()
+}
+
+// traceNumber returns a number for JSON encoding, which cannot represent infinities or NaN
+func traceNumber(n float64) any {
+	if math.IsInf(n, 0) || math.IsNaN(n) {
+		return types.NewNumberValue(n).ToString()
+	}
+	return n
+}
+
+// elementName formats an array element as it is written in BASIC, e.g. A(1,2)
+func elementName(name string, indices []int) string {
+	parts := make([]string, len(indices))
+	for n, index := range indices {
+		parts[n] = strconv.Itoa(index)
+	}
+	return name + "(" + strings.Join(parts, ",") + ")"
+}
@@ -0,0 +1,64 @@
+package interpreter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+func runTraced(t *testing.T, src string, limits TraceLimits) (*Trace, error) {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	trace := NewTrace(limits)
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	interp.SetTrace(trace)
+	return trace, interp.Execute(program)
+}
+
+func TestTrace_RecordsStatements(t *testing.T) {
+	trace, err := runTraced(t, "10 A=1: B$(2)=\"X\"\n20 PRINT A;: PRINT \"!\"\n30 C=1/0", TraceLimits{})
+	require.Error(t, err)
+
+	assert.Equal(t, []TraceEvent{
+		{Step: 1, Line: 10, Statement: 0, Changes: map[string]any{"A": 1.0}},
+		{Step: 2, Line: 10, Statement: 1, Changes: map[string]any{"B$(2)": "X"}},
+		{Step: 3, Line: 20, Statement: 0, Output: "1"},
+		{Step: 4, Line: 20, Statement: 1, Output: "!\n"},
+		{Step: 5, Line: 30, Statement: 0, Error: "?DIVISION BY ZERO ERROR IN 30"},
+	}, trace.Events)
+	assert.False(t, trace.Truncated)
+}
+
+func TestTrace_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits TraceLimits
+		events int
+	}{
+		{"event limit", TraceLimits{MaxEvents: 3}, 3},
+		{"byte limit", TraceLimits{MaxBytes: 2*traceEventOverhead + 20}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace, err := runTraced(t, "10 FOR I=1 TO 10: NEXT I", tt.limits)
+			require.NoError(t, err)
+			assert.Len(t, trace.Events, tt.events)
+			assert.True(t, trace.Truncated)
+		})
+	}
+}
+
+func TestTrace_NonFiniteNumbersEncodeAsText(t *testing.T) {
+	assert.Equal(t, 1.5, traceNumber(1.5))
+	assert.Equal(t, types.NewNumberValue(math.Inf(1)).ToString(), traceNumber(math.Inf(1)))
+}
@@ -0,0 +1,46 @@
+// ABOUTME: TRON and TROFF line tracing: prints [10] [20] ... as lines start executing
+// ABOUTME: Trace output goes to the screen like other BASICs, or to a dedicated writer so tools and tests can keep it apart
+
+package interpreter
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetLineTrace implements TRON (on) and TROFF
+func (i *Interpreter) SetLineTrace(on bool) error {
+	i.lineTrace = on
+	return nil
+}
+
+// LineTrace reports whether TRON is in effect
+func (i *Interpreter) LineTrace() bool {
+	return i.lineTrace
+}
+
+// SetTraceWriter sends TRON output to w, one line per traced line; nil prints it on the screen as [10] [20] ...
+func (i *Interpreter) SetTraceWriter(w io.Writer) {
+	i.traceWriter = w
+}
+
+// SetTraceStatements makes TRON print the source text of each line after its number
+func (i *Interpreter) SetTraceStatements(enabled bool) {
+	i.traceStatements = enabled
+}
+
+// traceLine prints the TRON entry of a line about to start executing
+func (i *Interpreter) traceLine(number int) error {
+	entry := fmt.Sprintf("[%d]", number)
+	if source := i.listing[number]; i.traceStatements && source != "" {
+		entry += " " + source
+	}
+	if i.traceWriter != nil {
+		_, err := io.WriteString(i.traceWriter, entry+"\n")
+		return err
+	}
+	if i.traceStatements {
+		return i.PrintLine(entry)
+	}
+	return i.Print(entry + " ")
+}
@@ -0,0 +1,71 @@
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+const tronProgram = "10 TRON\n20 FOR I=1 TO 2: NEXT\n30 GOSUB 60\n40 TROFF\n50 END\n60 PRINT \"HI\": RETURN"
+
+func TestInterpreter_TronPrintsLineNumbers(t *testing.T) {
+	rt := runtime.NewTestRuntime()
+	interp := NewInterpreter(rt)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, tronProgram)))
+
+	assert.Equal(t, "[20] [30] [60] HI\n[40] ", strings.Join(rt.GetOutput(), ""))
+	assert.False(t, interp.LineTrace())
+}
+
+func TestInterpreter_TronTraceWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements bool
+		want       string
+	}{
+		{"line numbers", false, "[20]\n[30]\n[60]\n[40]\n"},
+		{"statements", true, "[20] FOR I=1 TO 2: NEXT\n[30] GOSUB 60\n[60] PRINT \"HI\": RETURN\n[40] TROFF\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := runtime.NewTestRuntime()
+			var trace strings.Builder
+			interp := NewInterpreter(rt)
+			interp.SetSource(tronProgram)
+			interp.SetTraceWriter(&trace)
+			interp.SetTraceStatements(tt.statements)
+			require.NoError(t, interp.Execute(parsertest.Parse(t, tronProgram)))
+
+			assert.Equal(t, tt.want, trace.String())
+			assert.Equal(t, []string{"HI\n"}, rt.GetOutput())
+		})
+	}
+}
+
+func TestInterpreter_GotoChainsVisitEveryLine(t *testing.T) {
+	src := "10 TRON\n20 GOTO 40\n30 PRINT \"SKIPPED\"\n40 GOTO 50\n50 GOTO 60\n60 PRINT \"DONE\""
+	rt := runtime.NewTestRuntime()
+	var trace strings.Builder
+	coverage := NewCoverage()
+	interp := NewInterpreter(rt)
+	interp.SetTraceWriter(&trace)
+	interp.SetCoverage(coverage)
+	require.NoError(t, interp.Execute(parsertest.Parse(t, src)))
+
+	assert.Equal(t, []string{"DONE\n"}, rt.GetOutput())
+	assert.Equal(t, "[20]\n[40]\n[50]\n[60]\n", trace.String())
+	assert.Equal(t, map[int]int{10: 1, 20: 1, 40: 1, 50: 1, 60: 1}, coverage.Hits)
+	assert.Equal(t, 5, interp.Steps())
+}
+
+func TestInterpreter_NewTurnsTraceOff(t *testing.T) {
+	interp := NewInterpreter(runtime.NewTestRuntime())
+	require.NoError(t, interp.SetLineTrace(true))
+	require.NoError(t, interp.Execute(parsertest.Parse(t, "10 NEW")))
+	assert.False(t, interp.LineTrace())
+}
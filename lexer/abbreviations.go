@@ -0,0 +1,90 @@
+// ABOUTME: C64 keyword abbreviations: ? for PRINT and shifted-letter forms such as gO for GOTO
+// ABOUTME: Only active when enabled on the lexer, since mixed-case names would otherwise be misread
+
+package lexer
+
+import "strings"
+
+// abbreviations maps the upper-cased abbreviation (unshifted letters plus one shifted letter) to its keyword
+var abbreviations = map[string]string{
+	"EN":  "END",
+	"FO":  "FOR",
+	"NE":  "NEXT",
+	"DA":  "DATA",
+	"DI":  "DIM",
+	"RE":  "READ",
+	"LE":  "LET",
+	"GO":  "GOTO",
+	"RU":  "RUN",
+	"GOS": "GOSUB",
+	"RET": "RETURN",
+	"ST":  "STOP",
+	"DE":  "DEF",
+	"CO":  "CONT",
+	"LI":  "LIST",
+	"CL":  "CLR",
+	"GE":  "GET",
+	"TH":  "THEN",
+	"NO":  "NOT",
+	"STE": "STEP",
+	"AN":  "AND",
+	"TA":  "TAB",
+	"AB":  "ABS",
+	"SQ":  "SQR",
+	"RN":  "RND",
+	"EX":  "EXP",
+	"SI":  "SIN",
+	"AT":  "ATN",
+	"STR": "STR$",
+	"VA":  "VAL",
+	"AS":  "ASC",
+	"CH":  "CHR$",
+	"LEF": "LEFT$",
+	"RI":  "RIGHT$",
+	"MI":  "MID$",
+	"PO":  "POKE",
+	"PE":  "PEEK",
+	"OP":  "OPEN",
+	"CLO": "CLOSE",
+}
+
+// maxAbbreviationPrefix is the longest run of unshifted letters before the shifted one
+const maxAbbreviationPrefix = 2
+
+// SetAbbreviations enables C64 keyword abbreviations, as found in listings typed in abbreviated form
+func (l *Lexer) SetAbbreviations(enabled bool) {
+	l.abbreviations = enabled
+}
+
+// isLower checks if character is a lower-case (unshifted) letter
+func isLower(ch byte) bool {
+	return 'a' <= ch && ch <= 'z'
+}
+
+// isUpper checks if character is an upper-case (shifted) letter
+func isUpper(ch byte) bool {
+	return 'A' <= ch && ch <= 'Z'
+}
+
+// readAbbreviation reads an abbreviated keyword at the current position. The token carries the full
+// keyword as its literal; functions come back as identifiers like any other function name.
+func (l *Lexer) readAbbreviation() (Token, bool) {
+	n := 0
+	for n < maxAbbreviationPrefix && l.currentPosition+n < len(l.input) && isLower(l.input[l.currentPosition+n]) {
+		n++
+	}
+	end := l.currentPosition + n
+	if n == 0 || end >= len(l.input) || !isUpper(l.input[end]) {
+		return Token{}, false
+	}
+	keyword, ok := abbreviations[strings.ToUpper(l.input[l.currentPosition:end+1])]
+	if !ok {
+		return Token{}, false
+	}
+	for l.currentPosition <= end {
+		l.readChar()
+	}
+	tokenType := lookupIdent(keyword)
+	l.inData = tokenType == DATA
+	return l.createToken(tokenType, keyword), true
+}
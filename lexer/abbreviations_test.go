@@ -0,0 +1,104 @@
+package lexer
+
+import "testing"
+
+func TestLexer_Abbreviations(t *testing.T) {
+	input := "?\"HI\":gO100:fOi=1 TO 3:nEi\nA$=leF(B$,1):rEx:dA 1,a"
+	l := New(input)
+	l.SetAbbreviations(true)
+
+	tokens := []Token{
+		{Type: PRINT, Literal: "PRINT"},
+		{Type: STRING, Literal: "HI"},
+		{Type: COLON, Literal: ":"},
+		{Type: GOTO, Literal: "GOTO"},
+		{Type: NUMBER, Literal: "100"},
+		{Type: COLON, Literal: ":"},
+		{Type: FOR, Literal: "FOR"},
+		{Type: IDENT, Literal: "i"},
+		{Type: ASSIGN, Literal: "="},
+		{Type: NUMBER, Literal: "1"},
+		{Type: TO, Literal: "TO"},
+		{Type: NUMBER, Literal: "3"},
+		{Type: COLON, Literal: ":"},
+		{Type: NEXT, Literal: "NEXT"},
+		{Type: IDENT, Literal: "i"},
+		{Type: NEWLINE, Literal: "\n"},
+		{Type: IDENT, Literal: "A$"},
+		{Type: ASSIGN, Literal: "="},
+		{Type: IDENT, Literal: "LEFT$"},
+		{Type: LPAREN, Literal: "("},
+		{Type: IDENT, Literal: "B$"},
+		{Type: COMMA, Literal: ","},
+		{Type: NUMBER, Literal: "1"},
+		{Type: RPAREN, Literal: ")"},
+		{Type: COLON, Literal: ":"},
+		{Type: READ, Literal: "READ"},
+		{Type: IDENT, Literal: "x"},
+		{Type: COLON, Literal: ":"},
+		{Type: DATA, Literal: "DATA"},
+		{Type: NUMBER, Literal: "1"},
+		{Type: COMMA, Literal: ","},
+		{Type: STRING, Literal: "a"},
+		{Type: EOF, Literal: ""},
+	}
+
+	for i := range tokens {
+		tok := l.NextToken()
+		tok.Pos = Position{}
+		if tok != tokens[i] {
+			t.Fatalf("unexpected token %d: got %#v want %#v", i, tok, tokens[i])
+		}
+	}
+}
+
+func TestLexer_AbbreviationsOptIn(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Token
+	}{
+		{"gO", Token{Type: IDENT, Literal: "gO"}},
+		{"?", Token{Type: ILLEGAL, Literal: "?"}},
+	}
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		tok.Pos = Position{}
+		if tok != tt.want {
+			t.Errorf("%q: got %#v want %#v", tt.input, tok, tt.want)
+		}
+	}
+
+	l := New("goTo aBc")
+	l.SetAbbreviations(true)
+	want := []Token{
+		{Type: GOTO, Literal: "goTo"},
+		{Type: IDENT, Literal: "ABS"},
+		{Type: IDENT, Literal: "c"},
+	}
+	for i := range want {
+		tok := l.NextToken()
+		tok.Pos = Position{}
+		if tok != want[i] {
+			t.Errorf("token %d: got %#v want %#v", i, tok, want[i])
+		}
+	}
+}
+
+func TestLexer_AbbreviationsListAndCont(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Token
+	}{
+		{"lI", Token{Type: LIST, Literal: "LIST"}},
+		{"cO", Token{Type: IDENT, Literal: "CONT"}},
+	}
+	for _, tt := range tests {
+		l := New(tt.input)
+		l.SetAbbreviations(true)
+		tok := l.NextToken()
+		tok.Pos = Position{}
+		if tok != tt.want {
+			t.Errorf("%q: got %#v want %#v", tt.input, tok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// ABOUTME: Token classification for presentation purposes such as syntax highlighting
+// ABOUTME: Splits source text into classified segments that preserve the original spacing
+
+package lexer
+
+import "strings"
+
+// TokenClass is a coarse category of source text used for display
+type TokenClass int
+
+// Token classes returned by Classify
+const (
+	ClassPlain TokenClass = iota // whitespace, punctuation and anything unclassified
+	ClassKeyword
+	ClassIdentifier
+	ClassNumber
+	ClassString
+	ClassOperator
+	ClassLineNumber
+	ClassComment
+	ClassIllegal
+)
+
+// Segment is a run of source text sharing one class
+type Segment struct {
+	Text  string
+	Class TokenClass
+}
+
+// ClassOf returns the display class of a token type
+func ClassOf(tokenType TokenType) TokenClass {
+	switch tokenType {
+	case NUMBER:
+		return ClassNumber
+	case STRING:
+		return ClassString
+	case IDENT:
+		return ClassIdentifier
+	case ILLEGAL:
+		return ClassIllegal
+	case ASSIGN, PLUS, MINUS, MULTIPLY, DIVIDE, POWER, GT, LT, NE, GE, LE:
+		return ClassOperator
+	case COLON, LPAREN, RPAREN, COMMA, SEMICOLON, NEWLINE, EOF:
+		return ClassPlain
+	}
+	if _, ok := keywords[string(tokenType)]; ok {
+		return ClassKeyword
+	}
+	return ClassPlain
+}
+
+// Classify splits source into segments whose concatenation is exactly the input.
+// A number at the start of a line is a line number and text after REM is a comment.
+func Classify(source string) []Segment {
+	l := New(source)
+	var segments []Segment
+	emit := func(text string, class TokenClass) {
+		if text != "" {
+			segments = append(segments, Segment{Text: text, Class: class})
+		}
+	}
+
+	end := 0
+	lineStart := true
+	for {
+		tok := l.NextToken()
+		emit(source[end:l.tokenStart], ClassPlain)
+		if tok.Type == EOF {
+			break
+		}
+		end = l.currentPosition
+
+		class := ClassOf(tok.Type)
+		if tok.Type == NUMBER && lineStart {
+			class = ClassLineNumber
+		}
+		emit(source[l.tokenStart:end], class)
+		lineStart = tok.Type == NEWLINE
+
+		if tok.Type == REM {
+			// Everything up to the end of the line is comment text
+			stop := strings.IndexByte(source[end:], '\n')
+			if stop < 0 {
+				stop = len(source) - end
+			}
+			if stop > 0 {
+				emit(source[end:end+stop], ClassComment)
+				end += stop
+				l.nextPosition = end
+				l.readChar()
+			}
+		}
+	}
+	return segments
+}
@@ -0,0 +1,80 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Segment
+	}{
+		{
+			name:  "line with keyword, string and line number",
+			input: `10 PRINT "HI"; A`,
+			expected: []Segment{
+				{"10", ClassLineNumber},
+				{" ", ClassPlain},
+				{"PRINT", ClassKeyword},
+				{" ", ClassPlain},
+				{`"HI"`, ClassString},
+				{";", ClassPlain},
+				{" ", ClassPlain},
+				{"A", ClassIdentifier},
+			},
+		},
+		{
+			name:  "numbers after the line number are literals",
+			input: "20 X=X+1.5",
+			expected: []Segment{
+				{"20", ClassLineNumber},
+				{" ", ClassPlain},
+				{"X", ClassIdentifier},
+				{"=", ClassOperator},
+				{"X", ClassIdentifier},
+				{"+", ClassOperator},
+				{"1.5", ClassNumber},
+			},
+		},
+		{
+			name:  "REM text is a comment",
+			input: "30 REM \"HELLO\" : X\n40 END",
+			expected: []Segment{
+				{"30", ClassLineNumber},
+				{" ", ClassPlain},
+				{"REM", ClassKeyword},
+				{` "HELLO" : X`, ClassComment},
+				{"\n", ClassPlain},
+				{"40", ClassLineNumber},
+				{" ", ClassPlain},
+				{"END", ClassKeyword},
+			},
+		},
+		{
+			name:  "unterminated string is illegal",
+			input: `PRINT "OOPS`,
+			expected: []Segment{
+				{"PRINT", ClassKeyword},
+				{" ", ClassPlain},
+				{`"OOPS`, ClassIllegal},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := Classify(tt.input)
+			assert.Equal(t, tt.expected, segments)
+
+			var sb strings.Builder
+			for _, seg := range segments {
+				sb.WriteString(seg.Text)
+			}
+			assert.Equal(t, tt.input, sb.String())
+		})
+	}
+}
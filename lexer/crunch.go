@@ -0,0 +1,142 @@
+// ABOUTME: Keywords written without surrounding spaces, as in crunched C64 listings like FORI=1TO10:PRINTI
+// ABOUTME: Only in the c64 dialect or when enabled; a keyword is split off only where the rest reads as crunched code
+
+package lexer
+
+import (
+	"sort"
+	"strings"
+)
+
+// extensionKeywords are the keywords the extended dialect adds, which C64 BASIC reads as plain letters
+var extensionKeywords = map[string]bool{
+	"LOCATE": true, "WHILE": true, "WEND": true, "DO": true, "LOOP": true, "UNTIL": true, "SWAP": true,
+	"TRON": true, "TROFF": true, "EVERY": true, "AFTER": true, "CONST": true, "OPTION": true,
+}
+
+// IsExtensionKeyword reports whether a keyword belongs to the extended dialect only
+func IsExtensionKeyword(word string) bool {
+	return extensionKeywords[strings.ToUpper(word)]
+}
+
+// SetCrunch splits keywords written without spaces off names (FORI=1TO10) in the extended dialect too; the c64
+// dialect always does, with its own keywords only
+func (l *Lexer) SetCrunch(enabled bool) {
+	l.crunch = enabled
+}
+
+// crunching returns the keywords by initial to split off names, or nil when names are read whole
+func (l *Lexer) crunching() map[byte][]string {
+	switch {
+	case l.dialect == DialectC64:
+		return keywordsByInitial[DialectC64]
+	case l.crunch:
+		return keywordsByInitial[DialectExtended]
+	}
+	return nil
+}
+
+// crunchedWordLength returns the length of the identifier or keyword text starts with. Like the C64
+// tokenizer it finds the keywords given inside runs of letters (FORI is FOR I, ONIGOTO9 is ON I GOTO 9), but
+// only where the text glued to the keyword reads as crunched code, so names like SCORE and TOTAL stay whole.
+// Without keywords the whole run is read.
+func crunchedWordLength(text string, keywords map[byte][]string) int {
+	run := identifierLength(text)
+	if keywords == nil || lookupIdent(text[:run]) != IDENT {
+		return run
+	}
+	if n := keywordIn(text, keywords); n > 0 && crunchedRest(text[n:], keywords) {
+		return n
+	}
+	// A keyword glued behind a C64 variable name: one letter, optionally followed by a digit
+	for p := 1; p <= 2 && p < run; p++ {
+		if p == 2 && !isDigit(text[1]) {
+			break
+		}
+		if n := keywordIn(text[p:], keywords); n > 0 && crunchedRest(text[p+n:], keywords) {
+			return p
+		}
+	}
+	return run
+}
+
+// crunchedRest reports whether the text after a keyword reads as crunched code: a number, another
+// keyword, a name of one or two characters alone or followed by a keyword, or a name called with '('
+func crunchedRest(text string, keywords map[byte][]string) bool {
+	if text == "" {
+		return false
+	}
+	if isDigit(text[0]) || keywordIn(text, keywords) > 0 {
+		return true
+	}
+	if !isLetter(text[0]) {
+		return false
+	}
+	for n := 1; n <= 2 && n <= len(text); n++ {
+		if n == 2 && !isLetter(text[1]) && !isDigit(text[1]) {
+			break
+		}
+		next := text[n:]
+		if strings.HasPrefix(next, "$") {
+			next = next[1:]
+		}
+		if next == "" || !isLetter(next[0]) && !isDigit(next[0]) || keywordIn(next, keywords) > 0 {
+			return true
+		}
+	}
+	run := identifierLength(text)
+	return run < len(text) && text[run] == '('
+}
+
+// identifierLength returns the length of the run of letters and digits text starts with, plus a trailing $
+func identifierLength(text string) int {
+	n := 0
+	for n < len(text) && (isLetter(text[n]) || isDigit(text[n])) {
+		n++
+	}
+	if n < len(text) && text[n] == '$' {
+		n++
+	}
+	return n
+}
+
+// keywordsByInitial lists the keywords of each dialect starting with each upper-case letter, longest first
+var keywordsByInitial = map[Dialect]map[byte][]string{
+	DialectC64:      indexKeywords(func(word string) bool { return !extensionKeywords[word] }),
+	DialectExtended: indexKeywords(func(string) bool { return true }),
+}
+
+// indexKeywords lists the keywords keep accepts by initial, longest first
+func indexKeywords(keep func(word string) bool) map[byte][]string {
+	index := make(map[byte][]string)
+	for _, word := range Keywords() {
+		if keep(word) {
+			index[word[0]] = append(index[word[0]], word)
+		}
+	}
+	for _, words := range index {
+		sort.SliceStable(words, func(a, b int) bool { return len(words[a]) > len(words[b]) })
+	}
+	return index
+}
+
+// keywordIn returns the length of the longest of the keywords (in any case) that text starts with, or 0
+func keywordIn(text string, keywords map[byte][]string) int {
+	if text == "" {
+		return 0
+	}
+	for _, word := range keywords[toUpper(text[0])] {
+		if len(text) >= len(word) && strings.EqualFold(text[:len(word)], word) {
+			return len(word)
+		}
+	}
+	return 0
+}
+
+// toUpper upper-cases an ASCII letter
+func toUpper(ch byte) byte {
+	if 'a' <= ch && ch <= 'z' {
+		return ch - 'a' + 'A'
+	}
+	return ch
+}
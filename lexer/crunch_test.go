@@ -0,0 +1,83 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexer_CrunchedKeywords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"FORI=1TO10", []string{"FOR", "I", "=", "1", "TO", "10"}},
+		{"PRINTI:NEXTI", []string{"PRINT", "I", ":", "NEXT", "I"}},
+		{"GOSUB3910", []string{"GOSUB", "3910"}},
+		{"ONIGOTO2300", []string{"ON", "I", "GOTO", "2300"}},
+		{"IFQ5THEN3370", []string{"IF", "Q5", "THEN", "3370"}},
+		{"IFA$THENPRINTC$", []string{"IF", "A$", "THEN", "PRINT", "C$"}},
+		{"IFABS(X)", []string{"IF", "ABS", "(", "X", ")"}},
+		{"XTHENIFE", []string{"X", "THEN", "IF", "E"}},
+		{"BANDC", []string{"B", "AND", "C"}},
+		{"fori=1to2", []string{"for", "i", "=", "1", "to", "2"}},
+		{"SCORE", []string{"SCORE"}},
+		{"TOTAL", []string{"TOTAL"}},
+		{"CONTENT", []string{"CONTENT"}},
+		{"HAND=1", []string{"HAND", "=", "1"}},
+		{"PRINT", []string{"PRINT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			l.SetCrunch(true)
+			var got []string
+			for tok := l.NextToken(); tok.Type != EOF; tok = l.NextToken() {
+				got = append(got, tok.Literal)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLexer_CrunchingByDialect(t *testing.T) {
+	tests := []struct {
+		input   string
+		dialect Dialect
+		crunch  bool
+		want    []string
+	}{
+		{"DOG=1", DialectExtended, false, []string{"DOG", "=", "1"}},
+		{"TOP=3", DialectExtended, false, []string{"TOP", "=", "3"}},
+		{"ENDS=2", DialectExtended, false, []string{"ENDS", "=", "2"}},
+		{"DONE=1", DialectExtended, false, []string{"DONE", "=", "1"}},
+		{"IFFY=4", DialectExtended, false, []string{"IFFY", "=", "4"}},
+		{"NEXTA=5", DialectExtended, false, []string{"NEXTA", "=", "5"}},
+		{"GOTOX=1", DialectExtended, false, []string{"GOTOX", "=", "1"}},
+		{"STOPX=1", DialectExtended, false, []string{"STOPX", "=", "1"}},
+		{"FORI=1TO2", DialectExtended, false, []string{"FORI", "=", "1", "TO2"}},
+		{"FORI=1TO2", DialectC64, false, []string{"FOR", "I", "=", "1", "TO", "2"}},
+		{"DOX=1", DialectC64, false, []string{"DOX", "=", "1"}},
+		{"WHILEA", DialectC64, false, []string{"WHILEA"}},
+		{"DOX=1", DialectExtended, true, []string{"DO", "X", "=", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect)+" "+tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			l.SetDialect(tt.dialect)
+			l.SetCrunch(tt.crunch)
+			var got []string
+			for tok := l.NextToken(); tok.Type != EOF; tok = l.NextToken() {
+				got = append(got, tok.Literal)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLexer_CrunchedKeywordTypes(t *testing.T) {
+	l := New("FORI")
+	l.SetCrunch(true)
+	assert.Equal(t, Token{Type: FOR, Literal: "FOR", Pos: Position{Line: 1, Column: 1, Offset: 0}}, l.NextToken())
+	assert.Equal(t, Token{Type: IDENT, Literal: "I", Pos: Position{Line: 1, Column: 4, Offset: 3}}, l.NextToken())
+}
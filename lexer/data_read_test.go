@@ -21,7 +21,41 @@ func TestLexer_DataReadAndComma(t *testing.T) {
 
 	for i := range tokens {
 		tok := l.NextToken()
-		if tok != tokens[i] {
+		if tok.Type != tokens[i].Type || tok.Literal != tokens[i].Literal {
+			t.Fatalf("unexpected token %d: got %#v want %#v", i, tok, tokens[i])
+		}
+	}
+}
+
+func TestLexer_DataUnquotedItems(t *testing.T) {
+	input := "DATA HELLO, WORLD , 12AB,-5, .5:PRINT X\nDATA \"A,B\",C D"
+	l := New(input)
+
+	tokens := []Token{
+		{Type: DATA, Literal: "DATA"},
+		{Type: STRING, Literal: "HELLO"},
+		{Type: COMMA, Literal: ","},
+		{Type: STRING, Literal: "WORLD"},
+		{Type: COMMA, Literal: ","},
+		{Type: STRING, Literal: "12AB"},
+		{Type: COMMA, Literal: ","},
+		{Type: NUMBER, Literal: "-5"},
+		{Type: COMMA, Literal: ","},
+		{Type: NUMBER, Literal: ".5"},
+		{Type: COLON, Literal: ":"},
+		{Type: PRINT, Literal: "PRINT"},
+		{Type: IDENT, Literal: "X"},
+		{Type: NEWLINE, Literal: "\n"},
+		{Type: DATA, Literal: "DATA"},
+		{Type: STRING, Literal: "A,B"},
+		{Type: COMMA, Literal: ","},
+		{Type: STRING, Literal: "C D"},
+		{Type: EOF, Literal: ""},
+	}
+
+	for i := range tokens {
+		tok := l.NextToken()
+		if tok.Type != tokens[i].Type || tok.Literal != tokens[i].Literal {
 			t.Fatalf("unexpected token %d: got %#v want %#v", i, tok, tokens[i])
 		}
 	}
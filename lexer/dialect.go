@@ -0,0 +1,37 @@
+// ABOUTME: BASIC dialects: strict Commodore 64 BASIC V2, or the extended dialect this interpreter adds to it
+// ABOUTME: In the extended dialect the lexer also reads hexadecimal literals such as $FF
+
+package lexer
+
+import "strconv"
+
+// Dialect names a BASIC flavour: which extensions the lexer, parser and interpreter accept
+type Dialect string
+
+// Supported dialects
+const (
+	DialectC64      Dialect = "c64"      // Commodore 64 BASIC V2
+	DialectExtended Dialect = "extended" // Structured extensions such as WHILE and CONST
+)
+
+// SetDialect selects the dialect; the default is DialectExtended
+func (l *Lexer) SetDialect(d Dialect) {
+	l.dialect = d
+}
+
+// isHexDigit checks if character is a hexadecimal digit
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || 'A' <= ch && ch <= 'F' || 'a' <= ch && ch <= 'f'
+}
+
+// readHexNumber reads a hexadecimal literal at the current '$' and returns its value in decimal
+func (l *Lexer) readHexNumber() string {
+	l.readChar() // consume '$'
+	value := 0.0
+	for isHexDigit(l.currentChar) {
+		digit, _ := strconv.ParseUint(string(l.currentChar), 16, 8)
+		value = value*16 + float64(digit)
+		l.readChar()
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Escape sequences in string literals of the extended dialect: \n, \", \\ and \xNN
+// ABOUTME: unescape decodes them as readString reads a literal, and Quote writes a value back as a literal
+
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// unescape decodes the escape sequences of a string literal's text. \xNN stands for the byte NN, as CHR$(NN)
+// does; a backslash before any other character is kept as written.
+func unescape(text string) string {
+	var sb strings.Builder
+	for n := 0; n < len(text); n++ {
+		c := text[n]
+		if c != '\\' || n+1 == len(text) {
+			sb.WriteByte(c)
+			continue
+		}
+		switch next := text[n+1]; {
+		case next == 'n':
+			sb.WriteByte('\n')
+			n++
+		case next == '"' || next == '\\':
+			sb.WriteByte(next)
+			n++
+		case next == 'x' && n+3 < len(text) && isHexDigit(text[n+2]) && isHexDigit(text[n+3]):
+			value, _ := strconv.ParseUint(text[n+2:n+4], 16, 8)
+			sb.WriteByte(byte(value))
+			n += 3
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// Quote returns a string literal for value in a dialect. In the extended dialect quotes, backslashes and control
+// characters are escaped; C64 BASIC has no escapes, so there the value is only put between quotes.
+func Quote(value string, d Dialect) string {
+	if d == DialectC64 {
+		return `"` + value + `"`
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for n := 0; n < len(value); {
+		r, size := utf8.DecodeRuneInString(value[n:])
+		switch {
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < ' ' || r == 0x7F || r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&sb, `\x%02X`, value[n])
+		default:
+			sb.WriteString(value[n : n+size])
+		}
+		n += size
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
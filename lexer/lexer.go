@@ -3,7 +3,13 @@
 
 package lexer
 
-import "strings"
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"basic-interpreter/types"
+)
 
 // TokenType represents the type of a token
 type TokenType string
@@ -20,6 +26,8 @@ const (
 	LET       TokenType = "LET"
 	END       TokenType = "END"
 	RUN       TokenType = "RUN"
+	LIST      TokenType = "LIST"
+	LOCATE    TokenType = "LOCATE"
 	STOP      TokenType = "STOP"
 	GOTO      TokenType = "GOTO"
 	INPUT     TokenType = "INPUT"
@@ -43,6 +51,7 @@ const (
 	GE        TokenType = ">="
 	LE        TokenType = "<="
 	SEMICOLON TokenType = ";"
+	HASH      TokenType = "#"
 	FOR       TokenType = "FOR"
 	TO        TokenType = "TO"
 	NEXT      TokenType = "NEXT"
@@ -56,6 +65,24 @@ const (
 	AND       TokenType = "AND"
 	OR        TokenType = "OR"
 	NOT       TokenType = "NOT"
+	WHILE     TokenType = "WHILE"
+	WEND      TokenType = "WEND"
+	DO        TokenType = "DO"
+	LOOP      TokenType = "LOOP"
+	UNTIL     TokenType = "UNTIL"
+	SWAP      TokenType = "SWAP"
+	CLR       TokenType = "CLR"
+	NEW       TokenType = "NEW"
+	TRON      TokenType = "TRON"
+	TROFF     TokenType = "TROFF"
+	POKE      TokenType = "POKE"
+	EVERY     TokenType = "EVERY"
+	AFTER     TokenType = "AFTER"
+	GET       TokenType = "GET"
+	CONST     TokenType = "CONST"
+	OPTION    TokenType = "OPTION"
+	OPEN      TokenType = "OPEN"
+	CLOSE     TokenType = "CLOSE"
 )
 
 // keywords maps BASIC keywords to their token types
@@ -64,6 +91,8 @@ var keywords = map[string]TokenType{
 	"LET":    LET,
 	"END":    END,
 	"RUN":    RUN,
+	"LIST":   LIST,
+	"LOCATE": LOCATE,
 	"STOP":   STOP,
 	"GOTO":   GOTO,
 	"INPUT":  INPUT,
@@ -84,37 +113,86 @@ var keywords = map[string]TokenType{
 	"AND":    AND,
 	"OR":     OR,
 	"NOT":    NOT,
+	"WHILE":  WHILE,
+	"WEND":   WEND,
+	"DO":     DO,
+	"LOOP":   LOOP,
+	"UNTIL":  UNTIL,
+	"SWAP":   SWAP,
+	"CLR":    CLR,
+	"NEW":    NEW,
+	"TRON":   TRON,
+	"TROFF":  TROFF,
+	"POKE":   POKE,
+	"EVERY":  EVERY,
+	"AFTER":  AFTER,
+	"GET":    GET,
+	"CONST":  CONST,
+	"OPTION": OPTION,
+	"OPEN":   OPEN,
+	"CLOSE":  CLOSE,
+}
+
+// Keywords returns all reserved words in alphabetical order
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
-// Position represents a position in the source code
+// Position represents a position in the source code: Line and Column count from 1, Column in characters,
+// and Offset counts bytes from 0
 type Position struct {
 	Line   int
 	Column int
+	Offset int
 }
 
-// Token represents a single token with its type and literal value
+// Token represents a single token with its type, literal value and where it starts
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position
 }
 
 // Lexer represents the lexical analyzer
 type Lexer struct {
 	input           string
-	currentPosition int  // current position in input (points to current char)
-	nextPosition    int  // current reading position in input (after current char)
-	currentChar     byte // current char under examination
+	currentPosition int             // current position in input (points to current char)
+	nextPosition    int             // current reading position in input (after current char)
+	currentChar     byte            // current char under examination
+	tokenStart      int             // position in input where the last token began
+	tokenPos        Position        // line and column of tokenStart
+	line            int             // source line of the current char, from 1
+	lineStart       int             // position in input where the current line begins
+	inData          bool            // inside a DATA statement, where unquoted items are raw text
+	interned        *types.Interner // shares one copy of repeated identifiers and string literals
+	abbreviations   bool            // accept C64 keyword abbreviations such as ? and gO
+	dialect         Dialect         // DialectC64 leaves out extensions such as hex literals
+	crunch          bool            // Split keywords off names in the extended dialect too
 }
 
 // New creates a new lexer instance
 func New(input string) *Lexer {
 	lexer := &Lexer{
-		input: input,
+		input:    input,
+		interned: types.NewInterner(),
+		line:     1,
+		dialect:  DialectExtended,
 	}
 	lexer.readChar()
 	return lexer
 }
 
+// Text returns the input between two byte offsets, such as those of token positions
+func (l *Lexer) Text(from, to int) string {
+	to = min(to, len(l.input))
+	return l.input[min(from, to):to]
+}
+
 // createToken creates a token of the given type with the provided literal
 func (l *Lexer) createToken(tokenType TokenType, literal string) Token {
 	return Token{Type: tokenType, Literal: literal}
@@ -129,6 +207,10 @@ func (l *Lexer) createSingleCharToken(tokenType TokenType) Token {
 
 // readChar reads the next character and advances the position
 func (l *Lexer) readChar() {
+	if l.currentChar == '\n' {
+		l.line++
+		l.lineStart = l.nextPosition
+	}
 	if l.nextPosition >= len(l.input) {
 		l.currentChar = 0 // ASCII NUL represents "EOF"
 	} else {
@@ -140,7 +222,30 @@ func (l *Lexer) readChar() {
 
 // NextToken scans and returns the next token
 func (l *Lexer) NextToken() Token {
+	tok := l.scan()
+	tok.Pos = l.tokenPos
+	return tok
+}
+
+// scan reads the token at the current position
+func (l *Lexer) scan() Token {
 	l.skipWhitespace()
+	l.tokenStart = l.currentPosition
+	l.tokenPos = Position{
+		Line:   l.line,
+		Column: utf8.RuneCountInString(l.input[l.lineStart:min(l.tokenStart, len(l.input))]) + 1,
+		Offset: l.tokenStart,
+	}
+
+	if l.inData {
+		switch l.currentChar {
+		case ',', '"':
+		case ':', '\n', 0:
+			l.inData = false
+		default:
+			return l.readDataItem()
+		}
+	}
 
 	switch l.currentChar {
 	case '=':
@@ -165,13 +270,15 @@ func (l *Lexer) NextToken() Token {
 		return l.createSingleCharToken(COMMA)
 	case ';':
 		return l.createSingleCharToken(SEMICOLON)
+	case '#':
+		return l.createSingleCharToken(HASH)
 	case '<':
 		return l.readComparisonOperator('<')
 	case '>':
 		return l.readComparisonOperator('>')
 	case '"':
 		if literal, terminated := l.readString(); terminated {
-			return l.createToken(STRING, literal)
+			return l.createToken(STRING, l.interned.Intern(literal))
 		} else {
 			return l.createToken(ILLEGAL, "unterminated string")
 		}
@@ -187,16 +294,38 @@ func (l *Lexer) NextToken() Token {
 		}
 		// Otherwise '.' is illegal in this grammar
 		return l.createSingleCharToken(ILLEGAL)
+	case '$':
+		if l.dialect == DialectExtended && isHexDigit(l.peekChar()) {
+			return l.createToken(NUMBER, l.readHexNumber())
+		}
+		return l.createSingleCharToken(ILLEGAL)
 	case '\n':
 		tok := l.createToken(NEWLINE, string(l.currentChar))
 		l.readChar()
 		return tok
 	case 0:
 		return l.createToken(EOF, "")
+	case '?':
+		if l.abbreviations {
+			tok := l.createToken(PRINT, "PRINT")
+			l.readChar()
+			return tok
+		}
+		return l.createSingleCharToken(ILLEGAL)
 	default:
+		if l.abbreviations {
+			if tok, ok := l.readAbbreviation(); ok {
+				return tok
+			}
+		}
 		if isLetter(l.currentChar) {
 			literal := l.readIdentifier()
-			return l.createToken(lookupIdent(literal), literal)
+			tokenType := lookupIdent(literal)
+			l.inData = tokenType == DATA
+			if tokenType == IDENT {
+				literal = l.interned.Intern(literal)
+			}
+			return l.createToken(tokenType, literal)
 		} else if isDigit(l.currentChar) {
 			literal := l.readNumber()
 			return l.createToken(NUMBER, literal)
@@ -215,11 +344,17 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-// readString reads a string literal, returns (content, terminated)
+// readString reads a string literal, decoding escape sequences in the extended dialect; returns (content, terminated)
 func (l *Lexer) readString() (content string, terminated bool) {
 	position := l.currentPosition + 1
+	escaped := false
 	for {
 		l.readChar()
+		if l.currentChar == '\\' && l.dialect == DialectExtended && l.peekChar() != 0 {
+			escaped = true
+			l.readChar() // An escaped quote does not end the string
+			continue
+		}
 		if l.currentChar == '"' || l.currentChar == 0 {
 			break
 		}
@@ -230,18 +365,50 @@ func (l *Lexer) readString() (content string, terminated bool) {
 	}
 
 	result := l.input[position:l.currentPosition]
+	if escaped {
+		result = unescape(result)
+	}
 	l.readChar() // Skip closing quote
 	return result, true
 }
 
-// readIdentifier reads an identifier/keyword
-func (l *Lexer) readIdentifier() string {
+// readDataItem reads an unquoted DATA item up to the next comma, colon or end of line.
+// Items that look like numbers become NUMBER tokens; anything else is a STRING with the raw text.
+func (l *Lexer) readDataItem() Token {
 	position := l.currentPosition
-	for isLetter(l.currentChar) || isDigit(l.currentChar) {
+	for l.currentChar != ',' && l.currentChar != ':' && l.currentChar != '\n' && l.currentChar != 0 {
 		l.readChar()
 	}
-	// Handle string variables ending with $
-	if l.currentChar == '$' {
+	literal := strings.TrimRight(l.input[position:l.currentPosition], " \t\r")
+	if isDataNumber(literal) {
+		return l.createToken(NUMBER, literal)
+	}
+	return l.createToken(STRING, l.interned.Intern(literal))
+}
+
+// isDataNumber reports whether a DATA item is a plain decimal number with an optional sign
+func isDataNumber(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	digits, dots := 0, 0
+	for n := 0; n < len(s); n++ {
+		switch {
+		case isDigit(s[n]):
+			digits++
+		case s[n] == '.':
+			dots++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && dots <= 1
+}
+
+// readIdentifier reads an identifier or keyword, splitting off keywords written without spaces
+func (l *Lexer) readIdentifier() string {
+	position := l.currentPosition
+	for n := crunchedWordLength(l.input[position:], l.crunching()); l.currentPosition < position+n; {
 		l.readChar()
 	}
 	return l.input[position:l.currentPosition]
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,6 +36,22 @@ func TestLexer_NextToken(t *testing.T) {
 				{Type: EOF, Literal: ""},
 			},
 		},
+		{
+			name:  "file statements",
+			input: "OPEN 1:PRINT#1:CLOSE 1",
+			expected: []Token{
+				{Type: OPEN, Literal: "OPEN"},
+				{Type: NUMBER, Literal: "1"},
+				{Type: COLON, Literal: ":"},
+				{Type: PRINT, Literal: "PRINT"},
+				{Type: HASH, Literal: "#"},
+				{Type: NUMBER, Literal: "1"},
+				{Type: COLON, Literal: ":"},
+				{Type: CLOSE, Literal: "CLOSE"},
+				{Type: NUMBER, Literal: "1"},
+				{Type: EOF, Literal: ""},
+			},
+		},
 		{
 			name:  "string literal",
 			input: `"HELLO WORLD"`,
@@ -340,3 +357,116 @@ func TestLexer_ComparisonOperators(t *testing.T) {
 		})
 	}
 }
+
+func TestKeywords(t *testing.T) {
+	words := Keywords()
+	assert.Contains(t, words, "PRINT")
+	assert.Contains(t, words, "GOSUB")
+	assert.True(t, sort.StringsAreSorted(words))
+}
+
+func TestLexer_Positions(t *testing.T) {
+	l := New("10 PRINT \"π\";A\n  20\tEND\n")
+	want := []Position{
+		{Line: 1, Column: 1, Offset: 0},   // 10
+		{Line: 1, Column: 4, Offset: 3},   // PRINT
+		{Line: 1, Column: 10, Offset: 9},  // "π"
+		{Line: 1, Column: 13, Offset: 13}, // ; after a two-byte character
+		{Line: 1, Column: 14, Offset: 14}, // A
+		{Line: 1, Column: 15, Offset: 15}, // newline
+		{Line: 2, Column: 3, Offset: 18},  // 20
+		{Line: 2, Column: 6, Offset: 21},  // END after a tab
+		{Line: 2, Column: 9, Offset: 24},  // newline
+		{Line: 3, Column: 1, Offset: 25},  // EOF
+	}
+	for i, pos := range want {
+		assert.Equal(t, pos, l.NextToken().Pos, "token %d", i)
+	}
+}
+
+func TestLexer_Text(t *testing.T) {
+	l := New("10 REM HI\n")
+	l.NextToken() // 10
+	rem := l.NextToken()
+	l.NextToken() // HI
+	newline := l.NextToken()
+	assert.Equal(t, " HI", l.Text(rem.Pos.Offset+len(rem.Literal), newline.Pos.Offset))
+	assert.Equal(t, "\n", l.Text(newline.Pos.Offset, 100), "offsets past the end stop there")
+	assert.Equal(t, "", l.Text(5, 2))
+}
+
+func TestLexer_HexLiterals(t *testing.T) {
+	tests := []struct {
+		input   string
+		dialect Dialect
+		want    []Token
+	}{
+		{"$FF+$d020", DialectExtended, []Token{{Type: NUMBER, Literal: "255"}, {Type: PLUS, Literal: "+"}, {Type: NUMBER, Literal: "53280"}}},
+		{"A$=$0", DialectExtended, []Token{{Type: IDENT, Literal: "A$"}, {Type: ASSIGN, Literal: "="}, {Type: NUMBER, Literal: "0"}}},
+		{"$G", DialectExtended, []Token{{Type: ILLEGAL, Literal: "$"}, {Type: IDENT, Literal: "G"}}},
+		{"$FF", DialectC64, []Token{{Type: ILLEGAL, Literal: "$"}, {Type: IDENT, Literal: "FF"}}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect)+" "+tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			l.SetDialect(tt.dialect)
+			for _, want := range tt.want {
+				tok := l.NextToken()
+				assert.Equal(t, want.Type, tok.Type)
+				assert.Equal(t, want.Literal, tok.Literal)
+			}
+			assert.Equal(t, EOF, l.NextToken().Type)
+		})
+	}
+}
+
+func TestLexer_StringEscapes(t *testing.T) {
+	tests := []struct {
+		input   string
+		dialect Dialect
+		want    []Token
+	}{
+		{`"A\nB"`, DialectExtended, []Token{{Type: STRING, Literal: "A\nB"}}},
+		{`"SAY \"HI\""`, DialectExtended, []Token{{Type: STRING, Literal: `SAY "HI"`}}},
+		{`"\x41\x0d\\"`, DialectExtended, []Token{{Type: STRING, Literal: "A\r\\"}}},
+		{`"\xFF"`, DialectExtended, []Token{{Type: STRING, Literal: "\xff"}}},
+		{`"\q\x4"`, DialectExtended, []Token{{Type: STRING, Literal: `\q\x4`}}},
+		{`"A\"`, DialectExtended, []Token{{Type: ILLEGAL, Literal: "unterminated string"}}},
+		{`"A\n"`, DialectC64, []Token{{Type: STRING, Literal: `A\n`}}},
+		{`"A\"+"B"`, DialectC64, []Token{{Type: STRING, Literal: `A\`}, {Type: PLUS, Literal: "+"}, {Type: STRING, Literal: "B"}}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect)+" "+tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			l.SetDialect(tt.dialect)
+			for _, want := range tt.want {
+				tok := l.NextToken()
+				assert.Equal(t, want.Type, tok.Type)
+				assert.Equal(t, want.Literal, tok.Literal)
+			}
+			assert.Equal(t, EOF, l.NextToken().Type)
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value   string
+		dialect Dialect
+		want    string
+	}{
+		{"HI", DialectExtended, `"HI"`},
+		{"SAY \"HI\"\n", DialectExtended, `"SAY \"HI\"\n"`},
+		{"\\\x01\x7f\xffπ", DialectExtended, `"\\\x01\x7F\xFFπ"`},
+		{`A\n`, DialectC64, `"A\n"`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect)+" "+tt.want, func(t *testing.T) {
+			got := Quote(tt.value, tt.dialect)
+			assert.Equal(t, tt.want, got)
+			if tt.dialect == DialectExtended {
+				assert.Equal(t, tt.value, New(got).NextToken().Literal, "reads back")
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// ABOUTME: Analysis of an open document: parse diagnostics, GOTO/GOSUB targets, hover text and the symbol outline
+// ABOUTME: Token positions come from the lexer's classified segments, so every column matches the text as typed
+
+package lsp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+// diagnosticSource names the server in diagnostics
+const diagnosticSource = "basic"
+
+// jumpKeywords are followed by line numbers: GOTO 10, GOSUB 10, THEN 10, ELSE 10, RUN 10 and ON X GOTO 10,20
+var jumpKeywords = map[string]bool{"GOTO": true, "GOSUB": true, "THEN": true, "ELSE": true, "RUN": true}
+
+// token is a classified piece of a source line, punctuation included and whitespace left out
+type token struct {
+	text  string
+	class lexer.TokenClass
+	rng   Range
+}
+
+// sourceLine is one line of the document
+type sourceLine struct {
+	text   string
+	number int // BASIC line number, -1 when the line has none
+	tokens []token
+}
+
+// document is the analyzed text of an open file
+type document struct {
+	text  string
+	lines []sourceLine
+	byNum map[int]int // Source line of each BASIC line number; a repeated number keeps the last
+}
+
+// newDocument splits text into source lines of classified tokens
+func newDocument(text string) *document {
+	d := &document{text: text, byNum: make(map[int]int)}
+	for _, line := range strings.Split(text, "\n") {
+		d.lines = append(d.lines, sourceLine{text: strings.TrimSuffix(line, "\r"), number: -1})
+	}
+
+	row, col := 0, 0
+	for _, seg := range lexer.Classify(text) {
+		for n, part := range strings.Split(seg.Text, "\n") {
+			if n > 0 {
+				row, col = row+1, 0
+			}
+			width := utf16Len(part)
+			if strings.TrimSpace(part) != "" && row < len(d.lines) {
+				tok := token{
+					text:  strings.TrimSpace(part),
+					class: seg.Class,
+					rng:   Range{Start: Position{row, col}, End: Position{row, col + width}},
+				}
+				d.lines[row].tokens = append(d.lines[row].tokens, tok)
+			}
+			col += width
+		}
+	}
+
+	for n, line := range d.lines {
+		if len(line.tokens) > 0 && line.tokens[0].class == lexer.ClassLineNumber {
+			if number, err := strconv.Atoi(line.tokens[0].text); err == nil {
+				d.lines[n].number = number
+				d.byNum[number] = n
+			}
+		}
+	}
+	return d
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of LSP columns
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += len(utf16.AppendRune(nil, r))
+	}
+	return n
+}
+
+// diagnostics parses the document and reports each syntax error from the offending token to the end of its line, or
+// over the whole line when the error is at its end
+func (d *document) diagnostics() []Diagnostic {
+	p := parser.New(lexer.New(d.text))
+	p.ParseProgram()
+	diagnostics := []Diagnostic{}
+	for _, e := range p.ParseErrors() {
+		row := max(e.Position.Line-1, 0)
+		start, end := 0, 0
+		if row < len(d.lines) {
+			text := []rune(d.lines[row].text)
+			end = utf16Len(string(text))
+			if column := e.Position.Column - 1; column > 0 && column < len(text) {
+				start = utf16Len(string(text[:column]))
+			}
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Start: Position{row, start}, End: Position{row, end}},
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  e.Message,
+		})
+	}
+	return diagnostics
+}
+
+// tokenAt returns the token under pos, including a cursor just after its last character
+func (d *document) tokenAt(pos Position) (int, bool) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return 0, false
+	}
+	for n, tok := range d.lines[pos.Line].tokens {
+		if tok.rng.Start.Character <= pos.Character && pos.Character <= tok.rng.End.Character {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// jumpTargets returns the tokens of a source line that are line numbers jumped to, by token index
+func (d *document) jumpTargets(row int) map[int]int {
+	targets := make(map[int]int)
+	jumping := false
+	for n, tok := range d.lines[row].tokens {
+		switch {
+		case (tok.class == lexer.ClassKeyword || tok.class == lexer.ClassIdentifier) && jumpKeywords[strings.ToUpper(tok.text)]:
+			jumping = true
+		case jumping && tok.class == lexer.ClassNumber:
+			if number, err := strconv.Atoi(tok.text); err == nil {
+				targets[n] = number
+			}
+		case jumping && tok.text == ",":
+		default:
+			jumping = false
+		}
+	}
+	return targets
+}
+
+// definition returns the line number token of the line a GOTO, GOSUB or THEN at pos jumps to
+func (d *document) definition(pos Position) (Range, bool) {
+	n, ok := d.tokenAt(pos)
+	if !ok {
+		return Range{}, false
+	}
+	target, ok := d.jumpTargets(pos.Line)[n]
+	if !ok {
+		return Range{}, false
+	}
+	row, ok := d.byNum[target]
+	if !ok {
+		return Range{}, false
+	}
+	return d.lines[row].tokens[0].rng, true
+}
+
+// hover documents the statement, keyword or built-in function at pos, or shows the line a jump goes to
+func (d *document) hover(pos Position) (*Hover, bool) {
+	n, ok := d.tokenAt(pos)
+	if !ok {
+		return nil, false
+	}
+	tok := d.lines[pos.Line].tokens[n]
+	var text string
+	if target, ok := d.jumpTargets(pos.Line)[n]; ok {
+		row, ok := d.byNum[target]
+		if !ok {
+			return nil, false
+		}
+		text = fmt.Sprintf("```basic\n%s\n```", strings.TrimSpace(d.lines[row].text))
+	} else {
+		ref, ok := parser.LookupReference(strings.ToUpper(tok.text))
+		if !ok || (tok.class != lexer.ClassKeyword && tok.class != lexer.ClassIdentifier) {
+			return nil, false
+		}
+		text = fmt.Sprintf("```basic\n%s\n```\n%s", ref.Syntax, ref.Summary)
+	}
+	rng := tok.rng
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: text}, Range: &rng}, true
+}
+
+// symbols outlines the program: one symbol per numbered line, with the DEF FN functions it defines as children
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for row, line := range d.lines {
+		if line.number < 0 {
+			continue
+		}
+		end := Position{row, utf16Len(line.text)}
+		symbol := DocumentSymbol{
+			Name:           strconv.Itoa(line.number),
+			Detail:         strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line.text), line.tokens[0].text)),
+			Kind:           SymbolNumber,
+			Range:          Range{Start: Position{row, 0}, End: end},
+			SelectionRange: line.tokens[0].rng,
+		}
+		for n := 1; n+1 < len(line.tokens); n++ {
+			def, name := line.tokens[n], line.tokens[n+1]
+			if strings.EqualFold(def.text, "DEF") && strings.HasPrefix(strings.ToUpper(name.text), "FN") {
+				symbol.Children = append(symbol.Children, DocumentSymbol{
+					Name:           strings.ToUpper(name.text),
+					Kind:           SymbolFunction,
+					Range:          Range{Start: def.rng.Start, End: end},
+					SelectionRange: name.rng,
+				})
+			}
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
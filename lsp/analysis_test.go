@@ -0,0 +1,107 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const program = `10 GOSUB 100: PRINT "DONE"
+20 ON X GOTO 10, 100
+30 IF A THEN 20
+40 DEF FNA(X)=X*2: END
+100 RETURN`
+
+func TestDocument_Diagnostics(t *testing.T) {
+	assert.Empty(t, newDocument(program).diagnostics())
+
+	diagnostics := newDocument("10 PRINT \"OK\"\n20 GOTO\n").diagnostics()
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, Range{Start: Position{1, 0}, End: Position{1, 7}}, diagnostics[0].Range)
+	assert.Equal(t, SeverityError, diagnostics[0].Severity)
+	assert.NotEmpty(t, diagnostics[0].Message)
+
+	diagnostics = newDocument("10 PRINT \"π\" )\n").diagnostics()
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, Range{Start: Position{0, 13}, End: Position{0, 14}}, diagnostics[0].Range)
+
+	diagnostics = newDocument("10 GOTO\n20 PRINT 1\n30 X=: Y=)\n").diagnostics()
+	require.Len(t, diagnostics, 3)
+	assert.Equal(t, Range{Start: Position{0, 0}, End: Position{0, 7}}, diagnostics[0].Range)
+	assert.Equal(t, Range{Start: Position{2, 5}, End: Position{2, 10}}, diagnostics[1].Range)
+	assert.Equal(t, Range{Start: Position{2, 9}, End: Position{2, 10}}, diagnostics[2].Range)
+}
+
+func TestDocument_Definition(t *testing.T) {
+	doc := newDocument(program)
+	line100 := Range{Start: Position{4, 0}, End: Position{4, 3}}
+	line10 := Range{Start: Position{0, 0}, End: Position{0, 2}}
+	line20 := Range{Start: Position{1, 0}, End: Position{1, 2}}
+	tests := []struct {
+		name string
+		pos  Position
+		want *Range
+	}{
+		{"GOSUB target", Position{0, 10}, &line100},
+		{"cursor after the target", Position{0, 12}, &line100},
+		{"first ON target", Position{1, 13}, &line10},
+		{"second ON target", Position{1, 18}, &line100},
+		{"THEN target", Position{2, 13}, &line20},
+		{"GOSUB keyword", Position{0, 5}, nil},
+		{"number that is not a target", Position{3, 16}, nil},
+		{"past the end", Position{9, 0}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng, ok := doc.definition(tt.pos)
+			if tt.want == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, *tt.want, rng)
+		})
+	}
+}
+
+func TestDocument_Hover(t *testing.T) {
+	doc := newDocument("10 PRINT LEN(A$): GOTO 20\n20 END")
+
+	hover, ok := doc.hover(Position{0, 10})
+	require.True(t, ok)
+	assert.Contains(t, hover.Contents.Value, "LEN(")
+	assert.Equal(t, Range{Start: Position{0, 9}, End: Position{0, 12}}, *hover.Range)
+
+	hover, ok = doc.hover(Position{0, 4})
+	require.True(t, ok)
+	assert.Contains(t, hover.Contents.Value, "PRINT")
+
+	hover, ok = doc.hover(Position{0, 24})
+	require.True(t, ok)
+	assert.Equal(t, "```basic\n20 END\n```", hover.Contents.Value)
+
+	_, ok = doc.hover(Position{0, 13})
+	assert.False(t, ok, "variables have no documentation")
+}
+
+func TestDocument_Symbols(t *testing.T) {
+	symbols := newDocument(program).symbols()
+	require.Len(t, symbols, 5)
+	assert.Equal(t, "10", symbols[0].Name)
+	assert.Equal(t, `GOSUB 100: PRINT "DONE"`, symbols[0].Detail)
+	assert.Equal(t, SymbolNumber, symbols[0].Kind)
+
+	require.Len(t, symbols[3].Children, 1)
+	fn := symbols[3].Children[0]
+	assert.Equal(t, "FNA", fn.Name)
+	assert.Equal(t, SymbolFunction, fn.Kind)
+	assert.Equal(t, Range{Start: Position{3, 7}, End: Position{3, 10}}, fn.SelectionRange)
+	assert.Equal(t, "100", symbols[4].Name)
+}
+
+func TestUTF16Len(t *testing.T) {
+	assert.Equal(t, 3, utf16Len("abc"))
+	assert.Equal(t, 1, utf16Len("é"))
+	assert.Equal(t, 2, utf16Len("😀"))
+}
@@ -0,0 +1,186 @@
+// ABOUTME: Language server for BASIC programs speaking LSP over a reader and writer, usually stdin and stdout
+// ABOUTME: Keeps open documents in full-text sync, publishes parse diagnostics and answers definition, hover and symbols
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrExitWithoutShutdown reports an exit notification that was not preceded by a shutdown request
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// textDocumentSyncFull asks the client to send the whole text on every change
+const textDocumentSyncFull = 1
+
+// Server answers one client; it is not safe for concurrent use
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+// NewServer creates a server reading requests from in and writing responses and notifications to out
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, docs: make(map[string]*document)}
+}
+
+// Serve handles messages until the client sends exit or the input ends. Exiting without a shutdown request
+// returns ErrExitWithoutShutdown.
+func (s *Server) Serve() error {
+	for {
+		msg, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *responseError
+		if errors.As(err, &parseErr) {
+			if err := s.reply(nil, nil, parseErr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification; only failures to write are returned
+func (s *Server) handle(msg *message) error {
+	if msg.ID == nil {
+		return s.notification(msg)
+	}
+	result, err := s.request(msg)
+	return s.reply(msg.ID, result, err)
+}
+
+// request answers a request
+func (s *Server) request(msg *message) (any, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":       textDocumentSyncFull,
+				"definitionProvider":     true,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "basic"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/definition":
+		var params positionParams
+		doc, err := s.document(msg.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		if rng, ok := doc.definition(params.Position); ok {
+			return Location{URI: params.TextDocument.URI, Range: rng}, nil
+		}
+		return nil, nil
+	case "textDocument/hover":
+		var params positionParams
+		doc, err := s.document(msg.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		if hover, ok := doc.hover(params.Position); ok {
+			return hover, nil
+		}
+		return nil, nil
+	case "textDocument/documentSymbol":
+		var params documentParams
+		doc, err := s.document(msg.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return doc.symbols(), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %s is not supported", msg.Method)}
+}
+
+// document decodes the parameters of a request and returns the open document they name
+func (s *Server) document(raw json.RawMessage, params any, id *textDocumentIdentifier) (*document, *responseError) {
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	doc, ok := s.docs[id.URI]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidRequest, Message: fmt.Sprintf("document %s is not open", id.URI)}
+	}
+	return doc, nil
+}
+
+// notification handles a notification; unknown ones and malformed parameters are ignored, as nothing can be replied
+func (s *Server) notification(msg *message) error {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			return s.open(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(msg.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			return s.open(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			delete(s.docs, params.TextDocument.URI)
+			return s.publish(params.TextDocument.URI, []Diagnostic{})
+		}
+	}
+	return nil
+}
+
+// open analyzes the text of a document and publishes its diagnostics
+func (s *Server) open(uri, text string) error {
+	doc := newDocument(text)
+	s.docs[uri] = doc
+	return s.publish(uri, doc.diagnostics())
+}
+
+// publish sends the diagnostics of a document
+func (s *Server) publish(uri string, diagnostics []Diagnostic) error {
+	params, err := json.Marshal(publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// reply sends the response to a request; a successful response always has a result, null when there is none
+func (s *Server) reply(id *json.RawMessage, result any, err *responseError) error {
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	msg := &message{ID: id}
+	switch {
+	case err != nil:
+		msg.Error = err
+	case result == nil:
+		msg.Result = json.RawMessage("null")
+	default:
+		msg.Result = result
+	}
+	return writeMessage(s.out, msg)
+}
@@ -0,0 +1,95 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frame encodes client messages the way they arrive on stdin
+func frame(messages ...string) io.Reader {
+	var in bytes.Buffer
+	for _, msg := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	return &in
+}
+
+// responses decodes the messages a server wrote, keeping null results
+func responses(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var messages []map[string]any
+	for _, part := range strings.Split(out.String(), "Content-Length: ")[1:] {
+		_, body, ok := strings.Cut(part, "\r\n\r\n")
+		require.True(t, ok)
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+		messages = append(messages, decoded)
+	}
+	return messages
+}
+
+func TestServer_Session(t *testing.T) {
+	var out bytes.Buffer
+	server := NewServer(frame(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.bas","text":"10 GOTO 20\n20 PRINT"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/definition","params":{"textDocument":{"uri":"file:///a.bas"},"position":{"line":0,"character":9}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.bas"},"contentChanges":[{"text":"10 GOTO\n"}]}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/documentSymbol","params":{"textDocument":{"uri":"file:///a.bas"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///b.bas"},"position":{"line":0,"character":0}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"workspace/symbol","params":{}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	), &out)
+	require.NoError(t, server.Serve())
+
+	messages := responses(t, &out)
+	require.Len(t, messages, 8)
+
+	capabilities := messages[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	assert.Equal(t, true, capabilities["hoverProvider"])
+
+	assert.Equal(t, "textDocument/publishDiagnostics", messages[1]["method"])
+	assert.Empty(t, messages[1]["params"].(map[string]any)["diagnostics"])
+
+	location := messages[2]["result"].(map[string]any)
+	assert.Equal(t, "file:///a.bas", location["uri"])
+	assert.Equal(t, 1.0, location["range"].(map[string]any)["start"].(map[string]any)["line"])
+
+	diagnostics := messages[3]["params"].(map[string]any)["diagnostics"].([]any)
+	assert.Len(t, diagnostics, 1)
+
+	symbols := messages[4]["result"].([]any)
+	assert.Equal(t, "10", symbols[0].(map[string]any)["name"])
+
+	assert.Equal(t, float64(codeInvalidRequest), messages[5]["error"].(map[string]any)["code"])
+	assert.Equal(t, float64(codeMethodNotFound), messages[6]["error"].(map[string]any)["code"])
+
+	assert.Equal(t, 6.0, messages[7]["id"])
+	assert.Contains(t, messages[7], "result")
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	server := NewServer(frame(`{"jsonrpc":"2.0","method":"exit"}`), io.Discard)
+	assert.ErrorIs(t, server.Serve(), ErrExitWithoutShutdown)
+}
+
+func TestServer_MalformedMessages(t *testing.T) {
+	var out bytes.Buffer
+	server := NewServer(frame(`{not json`), &out)
+	require.NoError(t, server.Serve())
+	messages := responses(t, &out)
+	require.Len(t, messages, 1)
+	assert.Equal(t, float64(codeParseError), messages[0]["error"].(map[string]any)["code"])
+
+	server = NewServer(strings.NewReader("Content-Length: x\r\n\r\n"), io.Discard)
+	assert.Error(t, server.Serve())
+}
@@ -0,0 +1,212 @@
+// ABOUTME: Optimizer pass over the AST folding constant subexpressions, such as 2*3+1 or LEN("ABC"), before a run
+// ABOUTME: Number literals become parsed constants, so loops stop re-parsing them; expressions that fail are left to fail at run time
+
+package optimize
+
+import (
+	"math"
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// pureFunctions are the built-in functions whose result depends only on their arguments; RND, PEEK and TAB
+// depend on the machine and are never folded
+var pureFunctions = map[string]bool{
+	"LEN": true, "LEFT$": true, "RIGHT$": true, "MID$": true, "CHR$": true, "ASC": true, "STR$": true, "VAL": true,
+	"ABS": true, "INT": true, "SQR": true, "SIN": true, "COS": true, "TAN": true, "ATN": true, "EXP": true, "LOG": true,
+}
+
+// folder evaluates constant expressions on an interpreter that never runs a program
+type folder struct {
+	ops *interpreter.Interpreter
+}
+
+// Program folds the constant expressions of every statement in program, rewriting it in place. Running the
+// optimized program gives the same output, variables, steps and errors.
+func Program(program *parser.Program) {
+	f := newFolder()
+	for _, line := range program.Lines {
+		f.statements(line.Statements)
+	}
+}
+
+// Expression returns expr with its constant subexpressions folded
+func Expression(expr parser.Expression) parser.Expression {
+	return newFolder().expression(expr)
+}
+
+func newFolder() *folder {
+	return &folder{ops: interpreter.NewInterpreter(runtime.NewTestRuntime())}
+}
+
+// statements folds the expressions of each statement
+func (f *folder) statements(stmts []parser.Statement) {
+	for _, stmt := range stmts {
+		f.statement(stmt)
+	}
+}
+
+// statement folds the expressions a statement holds
+func (f *folder) statement(stmt parser.Statement) {
+	switch s := stmt.(type) {
+	case *parser.PrintStatement:
+		f.list(s.Items)
+		if s.Expression != nil {
+			s.Expression = f.expression(s.Expression)
+		}
+		if s.At != nil {
+			f.statement(s.At)
+		}
+	case *parser.LetStatement:
+		s.Expression = f.expression(s.Expression)
+	case *parser.ConstStatement:
+		s.Expression = f.expression(s.Expression)
+	case *parser.ArraySetStatement:
+		f.list(s.Indexes)
+		s.Expression = f.expression(s.Expression)
+	case *parser.IfStatement:
+		s.Condition = f.expression(s.Condition)
+		f.statements(s.ThenStmts)
+		f.statements(s.ElseStmts)
+	case *parser.ForStatement:
+		s.StartValue = f.expression(s.StartValue)
+		s.EndValue = f.expression(s.EndValue)
+		if s.StepValue != nil {
+			s.StepValue = f.expression(s.StepValue)
+		}
+	case *parser.WhileStatement:
+		s.Condition = f.expression(s.Condition)
+	case *parser.LoopStatement:
+		if s.Condition != nil {
+			s.Condition = f.expression(s.Condition)
+		}
+	case *parser.OnGotoStatement:
+		s.Selector = f.expression(s.Selector)
+	case *parser.OnGosubStatement:
+		s.Selector = f.expression(s.Selector)
+	case *parser.InputStatement:
+		f.targets(s.Targets)
+	case *parser.GetStatement:
+		f.targets(s.Targets)
+	case *parser.ReadStatement:
+		f.targets(s.Targets)
+	case *parser.SwapStatement:
+		f.list(s.Left.Indices)
+		f.list(s.Right.Indices)
+	case *parser.DimStatement:
+		for _, d := range s.Declarations {
+			f.list(d.Sizes)
+		}
+	case *parser.DataStatement:
+		f.list(s.Values)
+	case *parser.DefFnStatement:
+		s.Body = f.expression(s.Body)
+	case *parser.PokeStatement:
+		s.Address = f.expression(s.Address)
+		s.Value = f.expression(s.Value)
+	case *parser.LocateStatement:
+		s.Row = f.expression(s.Row)
+		s.Column = f.expression(s.Column)
+	case *parser.HostStatement:
+		f.list(s.Arguments)
+	case *parser.OpenStatement:
+		s.File = f.expression(s.File)
+		for _, arg := range []*parser.Expression{&s.Device, &s.Channel, &s.Name} {
+			if *arg != nil {
+				*arg = f.expression(*arg)
+			}
+		}
+	case *parser.CloseStatement:
+		s.File = f.expression(s.File)
+	case *parser.PrintFileStatement:
+		s.File = f.expression(s.File)
+		f.list(s.Items)
+	case *parser.InputFileStatement:
+		s.File = f.expression(s.File)
+		f.targets(s.Targets)
+	case *parser.GetFileStatement:
+		s.File = f.expression(s.File)
+		f.targets(s.Targets)
+	case *parser.TimerStatement:
+		s.Ticks = f.expression(s.Ticks)
+		if s.Timer != nil {
+			s.Timer = f.expression(s.Timer)
+		}
+	}
+}
+
+// targets folds the indexes of READ, INPUT and GET targets and those of their file forms
+func (f *folder) targets(targets []parser.ReadTarget) {
+	for _, tgt := range targets {
+		f.list(tgt.Indices)
+	}
+}
+
+// list folds each expression of a list in place
+func (f *folder) list(exprs []parser.Expression) {
+	for n, expr := range exprs {
+		exprs[n] = f.expression(expr)
+	}
+}
+
+// expression folds the operands of expr, then expr itself when all of them are constants
+func (f *folder) expression(expr parser.Expression) parser.Expression {
+	switch e := expr.(type) {
+	case *parser.NumberLiteral:
+		return f.fold(expr)
+	case *parser.BinaryOperation:
+		e.Left, e.Right = f.expression(e.Left), f.expression(e.Right)
+		if constant(e.Left) && constant(e.Right) {
+			return f.fold(expr)
+		}
+	case *parser.ComparisonExpression:
+		e.Left, e.Right = f.expression(e.Left), f.expression(e.Right)
+		if constant(e.Left) && constant(e.Right) {
+			return f.fold(expr)
+		}
+	case *parser.UnaryOperation:
+		e.Right = f.expression(e.Right)
+		if constant(e.Right) {
+			return f.fold(expr)
+		}
+	case *parser.ArrayReference:
+		f.list(e.Indices)
+	case *parser.FunctionCall:
+		f.list(e.Arguments)
+		if !pureFunctions[strings.ToUpper(e.FunctionName)] {
+			return expr
+		}
+		for _, arg := range e.Arguments {
+			if !constant(arg) {
+				return expr
+			}
+		}
+		return f.fold(expr)
+	}
+	return expr
+}
+
+// fold evaluates a constant expression, leaving it alone when it fails or overflows so the run reports it
+func (f *folder) fold(expr parser.Expression) parser.Expression {
+	value, err := expr.Evaluate(f.ops)
+	if err != nil {
+		return expr
+	}
+	if value.Type == types.NumberType && (math.IsInf(value.Number, 0) || math.IsNaN(value.Number)) {
+		return expr
+	}
+	return &parser.Constant{Value: value}
+}
+
+// constant reports whether an expression is a literal or an already folded constant
+func constant(expr parser.Expression) bool {
+	switch expr.(type) {
+	case *parser.Constant, *parser.StringLiteral:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package optimize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// assigned returns the expression of the LET on the first line of src
+func assigned(t *testing.T, src string) parser.Expression {
+	t.Helper()
+	return parsertest.Parse(t, src).Lines[0].Statements[0].(*parser.LetStatement).Expression
+}
+
+func TestExpressionFoldsConstants(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want types.Value
+	}{
+		{"number literal", "10 X=1.5", types.NewNumberValue(1.5)},
+		{"arithmetic", "10 X=2*3+1", types.NewNumberValue(7)},
+		{"precedence and power", "10 X=-2^2+10/4", types.NewNumberValue(-1.5)},
+		{"string function", `10 X=LEN("ABC")`, types.NewNumberValue(3)},
+		{"nested functions", `10 X$=LEFT$("HELLO",2)+CHR$(33)`, types.NewStringValue("HE!")},
+		{"comparison", `10 X=("A"<"B") AND 3`, types.NewNumberValue(1)},
+		{"not", "10 X=NOT 0", types.NewNumberValue(-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, &parser.Constant{Value: tt.want}, Expression(assigned(t, tt.src)))
+		})
+	}
+}
+
+func TestExpressionKeepsWhatCannotFold(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"variable", "10 X=A*2"},
+		{"random", "10 X=RND(1)"},
+		{"memory", "10 X=PEEK(53280)"},
+		{"division by zero", "10 X=1/0"},
+		{"type mismatch", `10 X="A"*2`},
+		{"user function", "10 X=FNA(2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, folded := Expression(assigned(t, tt.src)).(*parser.Constant)
+			assert.False(t, folded)
+		})
+	}
+}
+
+func TestExpressionFoldsInsideVariableExpressions(t *testing.T) {
+	expr := Expression(assigned(t, "10 X=A*(2+3)"))
+	bin, ok := expr.(*parser.BinaryOperation)
+	require.True(t, ok)
+	assert.Equal(t, &parser.Constant{Value: types.NewNumberValue(5)}, bin.Right)
+}
+
+// outcome is what a run leaves behind, compared with and without optimization
+type outcome struct {
+	output    string
+	variables map[string]types.Value
+	steps     int
+	err       string
+}
+
+func run(t *testing.T, src string, optimized bool) outcome {
+	t.Helper()
+	program := parsertest.Parse(t, src)
+	if optimized {
+		Program(program)
+	}
+	rt := runtime.NewTestRuntime()
+	interp := interpreter.NewInterpreter(rt)
+	err := interp.Execute(program)
+	o := outcome{output: strings.Join(rt.GetOutput(), ""), variables: interp.Variables(), steps: interp.Steps()}
+	if err != nil {
+		o.err = err.Error()
+	}
+	return o
+}
+
+func TestProgramRunsTheSame(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"loop", `10 FOR I=1 TO 2*5 STEP 1+1: S=S+I*(3-1): NEXT: PRINT S;I`},
+		{"print and if", `10 IF 2>1 THEN PRINT "A"+"B";LEN("XYZ")*2,TAB(3);1.25`},
+		{"arrays and data", `10 DIM A(2+1): A(1+1)=VAL("4"): READ B: PRINT A(2)*B
+20 DATA -5`},
+		{"def fn", `10 DEF FNA(X)=X*(1+1): PRINT FNA(2^3)`},
+		{"runtime error kept", `10 PRINT "OK": PRINT 1/0`},
+		{"on goto", `10 ON 1+1 GOTO 20,30
+20 PRINT "NO"
+30 PRINT "YES"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, run(t, tt.src, false), run(t, tt.src, true))
+		})
+	}
+}
+
+// loopProgram repeats constant arithmetic, which folding leaves to a single constant
+const loopProgram = `10 FOR I=1 TO 2000: S=S+(2*3+1.5): NEXT I`
+
+func benchmarkLoop(b *testing.B, optimized bool) {
+	program := parsertest.Parse(b, loopProgram)
+	if optimized {
+		Program(program)
+	}
+	for b.Loop() {
+		interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+		interp.SetMaxSteps(0)
+		if err := interp.Execute(program); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnoptimized(b *testing.B) { benchmarkLoop(b, false) }
+
+func BenchmarkOptimized(b *testing.B) { benchmarkLoop(b, true) }
@@ -0,0 +1,213 @@
+// ABOUTME: Resource packs: zip archives bundling a program with its data files, disk images and run configuration
+// ABOUTME: A pack runs the same way everywhere: seeded RND, emulated clock and scripted keyboard input
+
+package pack
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"basic-interpreter/basic"
+	"basic-interpreter/charset"
+	"basic-interpreter/runtime"
+)
+
+// ManifestName is the path of the run configuration inside a pack
+const ManifestName = "manifest.json"
+
+// MaxSize bounds the total unpacked size of a pack
+const MaxSize = 64 << 20
+
+// ErrTooLarge reports a pack whose files unpack to more than MaxSize bytes
+var ErrTooLarge = errors.New("pack too large")
+
+// Manifest is the run configuration of a pack
+type Manifest struct {
+	Program       string `json:"program"`                 // Path of the .bas file in the pack
+	Seed          int64  `json:"seed"`                    // Seed of the RND sequence
+	Input         string `json:"input,omitempty"`         // Typed input: INPUT reads it a line at a time, GET a key at a time
+	Abbreviations bool   `json:"abbreviations,omitempty"` // Accept C64 keyword abbreviations
+	Shims         bool   `json:"shims,omitempty"`         // Accept statements from other 8-bit dialects
+	MaxSteps      int    `json:"maxSteps,omitempty"`      // Statement limit (0 uses basic.DefaultMaxSteps)
+	ScreenWidth   int    `json:"screenWidth,omitempty"`   // Screen width (0 uses basic.DefaultScreenWidth)
+}
+
+// Pack is an opened resource pack
+type Pack struct {
+	Manifest Manifest
+	Source   string            // Program text, converted to UTF-8
+	Files    map[string][]byte // Every other file by path, such as DATA files and .d64 disk images
+}
+
+// Open reads a pack file
+func Open(name string) (*Pack, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+// Read reads a pack from a zip archive of the given size
+func Read(r io.ReaderAt, size int64) (*Pack, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	remaining := int64(MaxSize)
+	for _, f := range archive.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue // Directory entry
+		}
+		name, err := cleanName(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := readFile(f, remaining)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(data))
+		files[name] = data
+	}
+
+	manifest, ok := files[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", ManifestName)
+	}
+	p := &Pack{Files: files}
+	if err := json.Unmarshal(manifest, &p.Manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	delete(files, ManifestName)
+
+	program, err := cleanName(p.Manifest.Program)
+	if err != nil {
+		return nil, fmt.Errorf("%s: program: %w", ManifestName, err)
+	}
+	src, ok := files[program]
+	if !ok {
+		return nil, fmt.Errorf("%s: program %q is not in the pack", ManifestName, p.Manifest.Program)
+	}
+	if p.Source, err = charset.Decode(src, charset.Auto); err != nil {
+		return nil, fmt.Errorf("%s: %w", program, err)
+	}
+	delete(files, program)
+	return p, nil
+}
+
+// readFile reads one archive member, failing when it holds more than limit bytes
+func readFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+// cleanName checks that a path inside a pack is relative and stays inside it
+func cleanName(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if name == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid path %q", name)
+	}
+	return clean, nil
+}
+
+// Write stores the pack as a zip archive: the manifest, the program under Manifest.Program, then the other files
+func (p *Pack) Write(w io.Writer) error {
+	manifest, err := json.MarshalIndent(p.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	program, err := cleanName(p.Manifest.Program)
+	if err != nil {
+		return fmt.Errorf("program: %w", err)
+	}
+
+	archive := zip.NewWriter(w)
+	if err := addFile(archive, ManifestName, append(manifest, '\n')); err != nil {
+		return err
+	}
+	if err := addFile(archive, program, []byte(p.Source)); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(p.Files))
+	for name := range p.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := addFile(archive, name, p.Files[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// addFile stores one file in a zip archive
+func addFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// DiskImages returns the paths of the .d64 disk images in the pack, sorted
+func (p *Pack) DiskImages() []string {
+	var images []string
+	for name := range p.Files {
+		if strings.EqualFold(path.Ext(name), ".d64") {
+			images = append(images, name)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// Options returns the basic options that run the pack's program as its manifest configures
+func (p *Pack) Options() []basic.Option {
+	m := p.Manifest
+	opts := []basic.Option{basic.WithRuntime(runtime.NewDeterministicRuntime(m.Seed, m.Input))}
+	if m.Abbreviations {
+		opts = append(opts, basic.WithAbbreviations())
+	}
+	if m.Shims {
+		opts = append(opts, basic.WithShims())
+	}
+	if m.MaxSteps > 0 {
+		opts = append(opts, basic.WithMaxSteps(m.MaxSteps))
+	}
+	if m.ScreenWidth > 0 {
+		opts = append(opts, basic.WithScreenWidth(m.ScreenWidth))
+	}
+	return opts
+}
+
+// Run parses and runs the pack's program
+func (p *Pack) Run() (basic.Result, error) {
+	return basic.RunString(p.Source, p.Options()...)
+}
@@ -0,0 +1,79 @@
+package pack
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePack(t *testing.T, p *Pack) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, p.Write(&buf))
+	return buf.Bytes()
+}
+
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestPack_RoundTrip(t *testing.T) {
+	original := &Pack{
+		Manifest: Manifest{Program: "game/main.bas", Seed: 7, Input: "ADA\n", Shims: true},
+		Source:   "10 INPUT N$: PRINT \"HI \";N$\n",
+		Files:    map[string][]byte{"data/levels.txt": []byte("1,2,3"), "disks/game.d64": {0, 1, 2}},
+	}
+	data := writePack(t, original)
+
+	p, err := Read(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, original, p)
+	assert.Equal(t, []string{"disks/game.d64"}, p.DiskImages())
+}
+
+func TestPack_RunIsReproducible(t *testing.T) {
+	p := &Pack{
+		Manifest: Manifest{Program: "main.bas", Seed: 42, Input: "3\n"},
+		Source:   "10 INPUT N\n20 FOR I=1 TO N: PRINT INT(RND(1)*100): NEXT I\n",
+	}
+	first, err := p.Run()
+	require.NoError(t, err)
+	second, err := p.Run()
+	require.NoError(t, err)
+	assert.Len(t, first.Output, 3)
+	assert.Equal(t, first.Output, second.Output)
+}
+
+func TestPack_ReadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"no manifest", map[string]string{"main.bas": "10 END"}, "missing manifest.json"},
+		{"bad manifest", map[string]string{ManifestName: "{", "main.bas": "10 END"}, "manifest.json"},
+		{"missing program", map[string]string{ManifestName: `{"program":"main.bas"}`}, `program "main.bas" is not in the pack`},
+		{"escaping path", map[string]string{ManifestName: `{"program":"main.bas"}`, "../main.bas": "10 END"}, "invalid path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := zipOf(t, tt.files)
+			_, err := Read(bytes.NewReader(data), int64(len(data)))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -24,20 +24,55 @@ type InterpreterOperations interface {
 	Print(text string) error
 	PrintLine(text string) error
 	ReadInput(prompt string) (string, error)
+	ReadKey() (string, error)
+	ClearScreen() error
+	// LocateCursor moves the cursor to a 0-based row and column (LOCATE, PRINT AT)
+	LocateCursor(row, col int) error
 
 	// Control flow requests
 	RequestGoto(targetLine int) error
 	RequestEnd() error
 	RequestStop() error
+	// RequestRun implements RUN: clears all variables and restarts at startLine, or at the first line when startLine is negative
+	RequestRun(startLine int) error
 	RequestGosub(targetLine int) error
 	RequestReturn() error
+	// ControlTransferred reports whether the current statement requested a jump, END or STOP
+	ControlTransferred() bool
 
 	// Loop control for FOR/NEXT
 	BeginFor(variable string, end types.Value, step types.Value) error
 	IterateFor(variable string) error
 
+	// Loop control for WHILE/WEND
+	BeginWhile(condition bool) error
+	EndWhile() error
+
+	// Loop control for DO/LOOP; repeat reports whether the LOOP condition asks for another pass
+	BeginDo() error
+	EndDo(repeat bool) error
+
 	// Utility operations
 	NormalizeVariableName(name string) string
+	// ListProgram implements LIST: prints the program lines in range from the program's source text
+	ListProgram(r LineRange) error
+	// ClearVariables implements CLR: forgets variables, arrays, functions, the DATA pointer and all stacks
+	ClearVariables() error
+	// NewProgram implements NEW: forgets the program and all variables and ends the run
+	NewProgram() error
+	// Poke implements POKE: stores a byte in memory
+	Poke(address, value int) error
+	// SetLineTrace implements TRON and TROFF: turns printing of executed line numbers on or off
+	SetLineTrace(on bool) error
+	// SetTimer implements EVERY and AFTER: GOSUB targetLine once ticks jiffies pass, again every ticks
+	// jiffies when repeat is set; 0 ticks stops the timer
+	SetTimer(timer, ticks int, repeat bool, targetLine int) error
+
+	// Output layout for PRINT comma zones
+	PrintZoneWidth() int
+	OutputColumn() int
+	// Precision decides how PRINT shows numbers
+	Precision() types.Precision
 
 	// Data management (READ/DATA)
 	GetNextData() (types.Value, error)
@@ -48,12 +83,27 @@ type InterpreterOperations interface {
 
 	// Function evaluation
 	EvaluateFunction(functionName string, args []Expression) (types.Value, error)
+	// CallStatement runs a statement the host registered with its evaluated arguments
+	CallStatement(name string, args []types.Value) error
 
 	// Array element operations
 	GetArrayElement(name string, indices []int) (types.Value, error)
 	SetArrayElement(name string, indices []int, value types.Value) error
 	// User-defined functions
 	DefineUserFunction(name string, param string, body Expression) error
+
+	// Declarations: LET introduces a variable under OPTION EXPLICIT, CONST defines a read-only one
+	DeclareVariable(name string) error
+	DefineConstant(name string, value types.Value) error
+	SetOption(name string) error
+
+	// Logical files: OPEN and CLOSE a file number on a device, PRINT# to it, INPUT# a line or GET# a character
+	// from it. Reading past the end gives "".
+	OpenFile(file, device, channel int, name string) error
+	CloseFile(file int) error
+	WriteFile(file int, text string) error
+	ReadFileLine(file int) (string, error)
+	ReadFileChar(file int) (string, error)
 }
 
 // (No control error types are used for END/STOP; interpreter handles them statefully.)
@@ -79,6 +129,7 @@ type Program struct {
 type Line struct {
 	Number     int         // BASIC line number (10, 20, etc.)
 	Statements []Statement // Statements on this line
+	Source     string      // Text after the line number as typed, without surrounding whitespace, printed by LIST
 }
 
 // PrintStatement represents a PRINT statement
@@ -87,51 +138,93 @@ type PrintStatement struct {
 	Expression Expression
 	// Items is a list of expressions to print in sequence (semicolon/comma separated)
 	Items []Expression
+	// Separators holds the separator following each item ("", ";" or ","); a comma
+	// advances the output to the next print zone
+	Separators []string
 	// If true, suppress the trailing newline (trailing ';' in PRINT)
 	NoNewline bool
+	// At positions the cursor before printing (PRINT AT row,col; ...), nil otherwise
+	At *LocateStatement
 }
 
 func (ps *PrintStatement) Execute(ops InterpreterOperations) error {
+	if ps.At != nil {
+		if err := ps.At.Execute(ops); err != nil {
+			return err
+		}
+	}
 	// If multiple items are present, concatenate them into a single output string
 	if len(ps.Items) > 0 {
-		var out string
-		var prevType types.ValueType = -1
+		values := make([]types.Value, len(ps.Items))
 		for idx, it := range ps.Items {
 			v, err := it.Evaluate(ops)
 			if err != nil {
 				return err
 			}
-			curr := v.ToString()
-			// Insert a single space between items when either side is numeric,
-			// but avoid double spaces if spacing is already present.
-			if idx > 0 {
-				if v.Type == types.NumberType || prevType == types.NumberType {
-					needSpace := true
-					if len(out) > 0 && out[len(out)-1] == ' ' {
-						needSpace = false
-					}
-					if len(curr) > 0 && (curr[0] == ' ' || curr[0] == ',' || curr[0] == '.' || curr[0] == ';' || curr[0] == ':' || curr[0] == ')') {
-						needSpace = false
-					}
-					if needSpace {
-						out += " "
-					}
-				}
-			}
-			out += curr
-			prevType = v.Type
+			values[idx] = v
 		}
-		if ps.NoNewline {
-			return ops.Print(out)
-		}
-		return ops.PrintLine(out)
+		return PrintValues(ops, values, ps.Separators, ps.NoNewline)
 	}
 	// Legacy behavior: single expression
 	value, err := ps.Expression.Evaluate(ops)
 	if err != nil {
 		return err
 	}
-	return ops.PrintLine(value.ToString())
+	return ops.PrintLine(ops.Precision().Format(value))
+}
+
+// PrintValues prints evaluated PRINT items with their separators, spacing numbers and padding to zones after commas
+func PrintValues(ops InterpreterOperations, values []types.Value, separators []string, noNewline bool) error {
+	out := formatPrintItems(ops, values, separators, ops.OutputColumn())
+	if noNewline {
+		return ops.Print(out)
+	}
+	return ops.PrintLine(out)
+}
+
+// formatPrintItems joins PRINT items into the text printed from column col
+func formatPrintItems(ops InterpreterOperations, values []types.Value, separators []string, col int) string {
+	var out string
+	var prevType types.ValueType = -1
+	for idx, v := range values {
+		curr := ops.Precision().Format(v)
+		// Insert a single space between items when either side is numeric,
+		// but avoid double spaces if spacing is already present.
+		if idx > 0 {
+			if v.Type == types.NumberType || prevType == types.NumberType {
+				needSpace := true
+				if len(out) > 0 && out[len(out)-1] == ' ' {
+					needSpace = false
+				}
+				if len(curr) > 0 && (curr[0] == ' ' || curr[0] == ',' || curr[0] == '.' || curr[0] == ';' || curr[0] == ':' || curr[0] == ')') {
+					needSpace = false
+				}
+				if needSpace {
+					out += " "
+				}
+			}
+		}
+		out += curr
+		prevType = v.Type
+		if idx < len(separators) && separators[idx] == "," {
+			out += zonePadding(ops, out, col)
+		}
+	}
+	return out
+}
+
+// zonePadding returns the spaces needed to move from the end of out, printed from column col, to the next print zone
+func zonePadding(ops InterpreterOperations, out string, col int) string {
+	zone := ops.PrintZoneWidth()
+	if zone <= 0 {
+		return ""
+	}
+	if nl := strings.LastIndexByte(out, '\n'); nl >= 0 {
+		col = len(out) - nl - 1
+	} else {
+		col += len(out)
+	}
+	return strings.Repeat(" ", zone-col%zone)
 }
 
 // StringLiteral represents a string literal expression
@@ -154,6 +247,7 @@ func (es *EndStatement) Execute(ops InterpreterOperations) error {
 type LetStatement struct {
 	Variable   string     // Variable name
 	Expression Expression // Value to assign
+	Declared   bool       // Written with the LET keyword, which introduces the variable under OPTION EXPLICIT
 }
 
 func (ls *LetStatement) Execute(ops InterpreterOperations) error {
@@ -161,9 +255,37 @@ func (ls *LetStatement) Execute(ops InterpreterOperations) error {
 	if err != nil {
 		return err
 	}
+	if ls.Declared {
+		if err := ops.DeclareVariable(ls.Variable); err != nil {
+			return err
+		}
+	}
 	return ops.SetVariable(ls.Variable, value)
 }
 
+// ConstStatement represents CONST <name> = <expression>, defining a read-only variable
+type ConstStatement struct {
+	Name       string
+	Expression Expression
+}
+
+func (cs *ConstStatement) Execute(ops InterpreterOperations) error {
+	value, err := cs.Expression.Evaluate(ops)
+	if err != nil {
+		return err
+	}
+	return ops.DefineConstant(cs.Name, value)
+}
+
+// OptionStatement represents OPTION <name>, switching on an interpreter mode such as EXPLICIT
+type OptionStatement struct {
+	Name string
+}
+
+func (o *OptionStatement) Execute(ops InterpreterOperations) error {
+	return ops.SetOption(o.Name)
+}
+
 // VariableReference represents a variable reference in an expression
 type VariableReference struct {
 	Name string // Variable name
@@ -182,6 +304,15 @@ func (nl *NumberLiteral) Evaluate(ops InterpreterOperations) (types.Value, error
 	return types.ParseValue(nl.Value)
 }
 
+// Constant is a value computed before the run: a number literal parsed once or a folded constant expression
+type Constant struct {
+	Value types.Value
+}
+
+func (c *Constant) Evaluate(ops InterpreterOperations) (types.Value, error) {
+	return c.Value, nil
+}
+
 // BinaryOperation represents a binary arithmetic operation
 type BinaryOperation struct {
 	Left     Expression // Left operand
@@ -239,15 +370,118 @@ func (bo *BinaryOperation) Evaluate(ops InterpreterOperations) (types.Value, err
 	}
 }
 
-// RunStatement represents a RUN statement
-type RunStatement struct{}
+// ClrStatement represents a CLR statement
+type ClrStatement struct{}
+
+func (cs *ClrStatement) Execute(ops InterpreterOperations) error {
+	return ops.ClearVariables()
+}
+
+// NewStatement represents a NEW statement
+type NewStatement struct{}
+
+func (ns *NewStatement) Execute(ops InterpreterOperations) error {
+	return ops.NewProgram()
+}
+
+// PokeStatement represents POKE address, value
+type PokeStatement struct {
+	Address Expression
+	Value   Expression
+}
+
+func (ps *PokeStatement) Execute(ops InterpreterOperations) error {
+	args, err := evaluateIndices(ops, []Expression{ps.Address, ps.Value})
+	if err != nil {
+		return err
+	}
+	return ops.Poke(args[0], args[1])
+}
+
+// TraceStatement represents TRON (On) or TROFF
+type TraceStatement struct {
+	On bool
+}
+
+func (ts *TraceStatement) Execute(ops InterpreterOperations) error {
+	return ops.SetLineTrace(ts.On)
+}
+
+// TimerStatement represents EVERY ticks[,timer] GOSUB line (Repeat) and AFTER ticks[,timer] GOSUB line
+type TimerStatement struct {
+	Repeat     bool
+	Ticks      Expression
+	Timer      Expression // Timer number, nil for timer 0
+	TargetLine int
+}
+
+func (ts *TimerStatement) Execute(ops InterpreterOperations) error {
+	exprs := []Expression{ts.Ticks}
+	if ts.Timer != nil {
+		exprs = append(exprs, ts.Timer)
+	}
+	args, err := evaluateIndices(ops, exprs)
+	if err != nil {
+		return err
+	}
+	timer := 0
+	if len(args) > 1 {
+		timer = args[1]
+	}
+	return ops.SetTimer(timer, args[0], ts.Repeat, ts.TargetLine)
+}
+
+// ClearScreenStatement clears the screen; it is what HOME and CLS from other dialects translate to
+type ClearScreenStatement struct{}
+
+func (cs *ClearScreenStatement) Execute(ops InterpreterOperations) error {
+	return ops.ClearScreen()
+}
+
+// HostStatement is a statement the host registered: NAME [expr[, expr...]]
+type HostStatement struct {
+	Name      string // Upper case
+	Arguments []Expression
+}
+
+func (hs *HostStatement) Execute(ops InterpreterOperations) error {
+	args := make([]types.Value, len(hs.Arguments))
+	for n, arg := range hs.Arguments {
+		value, err := arg.Evaluate(ops)
+		if err != nil {
+			return err
+		}
+		args[n] = value
+	}
+	return ops.CallStatement(hs.Name, args)
+}
+
+// LocateStatement moves the cursor: LOCATE row,col counts from 1 and PRINT AT row,col from 0
+type LocateStatement struct {
+	Row    Expression
+	Column Expression
+	Origin int // Number of the top row and left column: 1 for LOCATE, 0 for PRINT AT
+}
+
+func (ls *LocateStatement) Execute(ops InterpreterOperations) error {
+	pos, err := evaluateIndices(ops, []Expression{ls.Row, ls.Column})
+	if err != nil {
+		return err
+	}
+	return ops.LocateCursor(pos[0]-ls.Origin, pos[1]-ls.Origin)
+}
+
+// RunStatement represents RUN [line]: it clears all variables and restarts the program
+type RunStatement struct {
+	StartLine int  // Line to start at when HasStart is set
+	HasStart  bool // False starts at the first line
+}
 
 func (rs *RunStatement) Execute(ops InterpreterOperations) error {
-	// RUN statement doesn't do anything during normal program execution
-	// In C64 BASIC, RUN would start program execution from the beginning,
-	// but in our current architecture, we're already executing the program
-	// so RUN is effectively a no-op when encountered in program flow
-	return nil
+	if !rs.HasStart {
+		return ops.RequestRun(-1)
+	}
+	return ops.RequestRun(rs.StartLine)
 }
 
 // StopStatement represents a STOP statement
@@ -259,63 +493,210 @@ func (ss *StopStatement) Execute(ops InterpreterOperations) error {
 
 // InputStatement represents an INPUT statement
 type InputStatement struct {
-	Prompt       string       // Optional prompt string (empty for no prompt)
-	Variable     string       // Variable name to read into (if not array)
-	ArrayName    string       // Array name if input targets an array element
-	ArrayIndices []Expression // Index expressions for array input
+	Prompt  string       // Optional prompt string (empty for no prompt)
+	Targets []ReadTarget // Variables or array elements to fill, in order
 }
 
 func (ins *InputStatement) Execute(ops InterpreterOperations) error {
-	input, err := ops.ReadInput(ins.Prompt)
+	names := make([]string, len(ins.Targets))
+	for n, tgt := range ins.Targets {
+		names[n] = tgt.Name
+	}
+	values, err := InputValues(ops, ins.Prompt, names)
 	if err != nil {
 		return err
 	}
+	return ins.assign(ops, values)
+}
+
+// InputValues reads one value for each named target, converted by its type suffix, asking again on bad numbers
+func InputValues(ops InterpreterOperations, prompt string, names []string) ([]types.Value, error) {
+	for {
+		values, err := readValues(ops, prompt, names)
+		if err == types.ErrTypeMismatch {
+			// Like C64 BASIC, bad numeric input restarts the whole INPUT
+			if err := ops.PrintLine("?REDO FROM START"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return values, err
+	}
+}
+
+// readValues reads and converts one value per target, prompting with "??" when a line runs short
+func readValues(ops InterpreterOperations, prompt string, names []string) ([]types.Value, error) {
+	input, err := ops.ReadInput(prompt)
+	if err != nil {
+		return nil, err
+	}
 
-	// If targeting an array element
-	if ins.ArrayName != "" {
-		// Evaluate indices
-		idxs := make([]int, len(ins.ArrayIndices))
-		for i, e := range ins.ArrayIndices {
-			v, err := e.Evaluate(ops)
+	fields := splitInputFields(input)
+	values := make([]types.Value, 0, len(names))
+	for _, name := range names {
+		// Too few values on the line: ask for the rest with "??"
+		if len(fields) == 0 {
+			more, err := ops.ReadInput("?? ")
 			if err != nil {
-				return err
+				return nil, err
 			}
-			if v.Type != types.NumberType {
-				return types.ErrTypeMismatch
+			fields = splitInputFields(more)
+		}
+		field := fields[0]
+		fields = fields[1:]
+
+		value, err := fieldValue(name, field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if len(fields) > 0 {
+		if err := ops.PrintLine("?EXTRA IGNORED"); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// fieldValue converts an input field based on the type suffix of the target named name
+func fieldValue(name, field string) (types.Value, error) {
+	if strings.HasSuffix(name, "$") {
+		return types.NewStringValue(field), nil
+	}
+	parsed, err := types.ParseValue(field)
+	if err != nil || parsed.Type != types.NumberType {
+		return types.Value{}, types.ErrTypeMismatch
+	}
+	return parsed, nil
+}
+
+// assign stores converted INPUT values into their targets
+func (ins *InputStatement) assign(ops InterpreterOperations, values []types.Value) error {
+	return assignTargets(ops, ins.Targets, values)
+}
+
+// assignTargets stores values into targets, in order
+func assignTargets(ops InterpreterOperations, targets []ReadTarget, values []types.Value) error {
+	for n, tgt := range targets {
+		if len(tgt.Indices) > 0 {
+			idxs, err := evaluateIndices(ops, tgt.Indices)
+			if err != nil {
+				return err
 			}
-			n := v.Number
-			if n < 0 || float64(int(n)) != n {
-				return fmt.Errorf("?ILLEGAL QUANTITY ERROR")
+			if err := ops.SetArrayElement(tgt.Name, idxs, values[n]); err != nil {
+				return err
 			}
-			idxs[i] = int(n)
+			continue
 		}
-		// Convert input to appropriate type based on array name suffix
-		var value types.Value
-		if strings.HasSuffix(ins.ArrayName, "$") {
-			value = types.NewStringValue(input)
-		} else {
-			parsed, err := types.ParseValue(input)
-			if err != nil || parsed.Type != types.NumberType {
-				return types.ErrTypeMismatch
-			}
-			value = parsed
+		if err := ops.SetVariable(tgt.Name, values[n]); err != nil {
+			return err
 		}
-		return ops.SetArrayElement(ins.ArrayName, idxs, value)
 	}
+	return nil
+}
 
-	// Parse input based on variable type
-	var value types.Value
-	if strings.HasSuffix(ins.Variable, "$") {
-		value = types.NewStringValue(input)
-	} else {
-		parsed, err := types.ParseValue(input)
-		if err != nil || parsed.Type != types.NumberType {
-			return types.ErrTypeMismatch
+// GetStatement represents GET var[, var...], reading single keystrokes without waiting
+type GetStatement struct {
+	Targets []ReadTarget
+}
+
+func (gs *GetStatement) Execute(ops InterpreterOperations) error {
+	for _, tgt := range gs.Targets {
+		value, err := KeyValue(ops, tgt.Name)
+		if err != nil {
+			return err
 		}
-		value = parsed
+		idxs, err := evaluateIndices(ops, tgt.Indices)
+		if err != nil {
+			return err
+		}
+		if err := setTarget(ops, tgt, idxs, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KeyValue reads a keystroke for GET into a target named name: a string, or for a numeric target a digit
+func KeyValue(ops InterpreterOperations, name string) (types.Value, error) {
+	key, err := ops.ReadKey()
+	if err != nil {
+		return types.Value{}, err
+	}
+	return keyValue(name, key)
+}
+
+// keyValue converts a character read by GET or GET# for a target named name
+func keyValue(name, key string) (types.Value, error) {
+	if strings.HasSuffix(name, "$") {
+		return types.NewStringValue(key), nil
+	}
+	// Numeric GET accepts only digits; no key reads as 0
+	switch {
+	case key == "":
+		return types.NewNumberValue(0), nil
+	case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+		return types.NewNumberValue(float64(key[0] - '0')), nil
 	}
+	return types.Value{}, fmt.Errorf("?SYNTAX ERROR")
+}
+
+// splitInputFields splits an INPUT line on commas; quoted fields may contain commas and keep their spaces
+func splitInputFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	wasQuoted := false
+	flush := func() {
+		text := field.String()
+		if !wasQuoted {
+			text = strings.TrimSpace(text)
+		}
+		fields = append(fields, text)
+		field.Reset()
+		wasQuoted = false
+	}
+
+	for _, ch := range line {
+		switch {
+		case ch == '"':
+			quoted = !quoted
+			if quoted && strings.TrimSpace(field.String()) == "" {
+				field.Reset()
+				wasQuoted = true
+			}
+		case ch == ',' && !quoted:
+			flush()
+		case wasQuoted && !quoted:
+			// Ignore anything after a closing quote
+		default:
+			field.WriteRune(ch)
+		}
+	}
+	flush()
+	return fields
+}
 
-	return ops.SetVariable(ins.Variable, value)
+// evaluateIndices evaluates array index expressions to non-negative integers
+func evaluateIndices(ops InterpreterOperations, exprs []Expression) ([]int, error) {
+	idxs := make([]int, len(exprs))
+	for i, e := range exprs {
+		v, err := e.Evaluate(ops)
+		if err != nil {
+			return nil, err
+		}
+		if v.Type != types.NumberType {
+			return nil, types.ErrTypeMismatch
+		}
+		n := v.Number
+		if n < 0 || float64(int(n)) != n {
+			return nil, fmt.Errorf("?ILLEGAL QUANTITY ERROR")
+		}
+		idxs[i] = int(n)
+	}
+	return idxs, nil
 }
 
 // GotoStatement represents a GOTO statement
@@ -329,8 +710,9 @@ func (gs *GotoStatement) Execute(ops InterpreterOperations) error {
 
 // IfStatement represents an IF...THEN statement
 type IfStatement struct {
-	Condition Expression // The condition to evaluate
-	ThenStmt  Statement  // The statement to execute if condition is true
+	Condition Expression  // The condition to evaluate
+	ThenStmts []Statement // The colon-separated statements to execute if condition is true
+	ElseStmts []Statement // Statements after ELSE to execute if condition is false (dialect shims only)
 }
 
 func (is *IfStatement) Execute(ops InterpreterOperations) error {
@@ -339,8 +721,18 @@ func (is *IfStatement) Execute(ops InterpreterOperations) error {
 		return err
 	}
 
-	if condition.IsTrue() {
-		return is.ThenStmt.Execute(ops)
+	branch := is.ThenStmts
+	if !condition.IsTrue() {
+		branch = is.ElseStmts
+	}
+	for _, stmt := range branch {
+		if err := stmt.Execute(ops); err != nil {
+			return err
+		}
+		// A jump, END or STOP abandons the rest of the branch
+		if ops.ControlTransferred() {
+			return nil
+		}
 	}
 	return nil
 }
@@ -467,6 +859,50 @@ func (ns *NextStatement) Execute(ops InterpreterOperations) error {
 	return ops.IterateFor(ns.Variable)
 }
 
+// WhileStatement represents a WHILE statement; the loop body runs up to the matching WEND
+type WhileStatement struct {
+	Condition Expression // Loop condition, tested before every iteration
+}
+
+func (ws *WhileStatement) Execute(ops InterpreterOperations) error {
+	condition, err := ws.Condition.Evaluate(ops)
+	if err != nil {
+		return err
+	}
+	return ops.BeginWhile(condition.IsTrue())
+}
+
+// WendStatement represents a WEND statement closing the innermost WHILE loop
+type WendStatement struct{}
+
+func (ws *WendStatement) Execute(ops InterpreterOperations) error {
+	return ops.EndWhile()
+}
+
+// DoStatement represents a DO statement opening a post-condition loop
+type DoStatement struct{}
+
+func (ds *DoStatement) Execute(ops InterpreterOperations) error {
+	return ops.BeginDo()
+}
+
+// LoopStatement represents LOOP, LOOP WHILE <condition> or LOOP UNTIL <condition>
+type LoopStatement struct {
+	Condition Expression // Optional; nil loops forever
+	Until     bool       // True for LOOP UNTIL, false for LOOP WHILE
+}
+
+func (ls *LoopStatement) Execute(ops InterpreterOperations) error {
+	if ls.Condition == nil {
+		return ops.EndDo(true)
+	}
+	condition, err := ls.Condition.Evaluate(ops)
+	if err != nil {
+		return err
+	}
+	return ops.EndDo(condition.IsTrue() != ls.Until)
+}
+
 // GosubStatement represents a GOSUB statement
 type GosubStatement struct {
 	TargetLine int // Target line number to call
@@ -549,8 +985,61 @@ func (rs *ReadStatement) Execute(ops InterpreterOperations) error {
 	return nil
 }
 
+// SwapStatement represents SWAP a, b exchanging two variables or array elements of the same type
+type SwapStatement struct {
+	Left  ReadTarget
+	Right ReadTarget
+}
+
+func (ss *SwapStatement) Execute(ops InterpreterOperations) error {
+	if strings.HasSuffix(ss.Left.Name, "$") != strings.HasSuffix(ss.Right.Name, "$") {
+		return types.ErrTypeMismatch
+	}
+
+	leftIdx, err := evaluateIndices(ops, ss.Left.Indices)
+	if err != nil {
+		return err
+	}
+	rightIdx, err := evaluateIndices(ops, ss.Right.Indices)
+	if err != nil {
+		return err
+	}
+
+	leftVal, err := getTarget(ops, ss.Left, leftIdx)
+	if err != nil {
+		return err
+	}
+	rightVal, err := getTarget(ops, ss.Right, rightIdx)
+	if err != nil {
+		return err
+	}
+
+	if err := setTarget(ops, ss.Left, leftIdx, rightVal); err != nil {
+		return err
+	}
+	return setTarget(ops, ss.Right, rightIdx, leftVal)
+}
+
+// getTarget reads a variable or, when it has indices, an array element
+func getTarget(ops InterpreterOperations, tgt ReadTarget, idxs []int) (types.Value, error) {
+	if len(tgt.Indices) > 0 {
+		return ops.GetArrayElement(tgt.Name, idxs)
+	}
+	return ops.GetVariable(tgt.Name)
+}
+
+// setTarget writes a variable or, when it has indices, an array element
+func setTarget(ops InterpreterOperations, tgt ReadTarget, idxs []int, value types.Value) error {
+	if len(tgt.Indices) > 0 {
+		return ops.SetArrayElement(tgt.Name, idxs, value)
+	}
+	return ops.SetVariable(tgt.Name, value)
+}
+
 // RemStatement represents a REM (comment) statement; it is a no-op at runtime
-type RemStatement struct{}
+type RemStatement struct {
+	Text string // Comment after REM as typed, including leading spaces but not trailing ones
+}
 
 func (rs *RemStatement) Execute(ops InterpreterOperations) error { return nil }
 
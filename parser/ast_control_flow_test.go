@@ -3,6 +3,7 @@ package parser
 import (
 	"testing"
 
+	"basic-interpreter/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,6 +34,12 @@ func TestRunStatement_Execute(t *testing.T) {
 	err := stmt.Execute(mock)
 
 	assert.NoError(t, err)
+	assert.True(t, mock.runRequested)
+	assert.Equal(t, -1, mock.runTarget)
+
+	mock = newMockOps()
+	assert.NoError(t, (&RunStatement{StartLine: 200, HasStart: true}).Execute(mock))
+	assert.Equal(t, 200, mock.runTarget)
 }
 
 func TestGotoStatement_Execute(t *testing.T) {
@@ -45,3 +52,37 @@ func TestGotoStatement_Execute(t *testing.T) {
 	assert.True(t, mock.gotoRequested)
 	assert.Equal(t, 50, mock.gotoTarget)
 }
+
+func TestWhileStatement_Execute(t *testing.T) {
+	mock := newMockOps()
+	mock.setVariable("A", types.NewNumberValue(0))
+
+	assert.NoError(t, (&WhileStatement{Condition: &NumberLiteral{Value: "1"}}).Execute(mock))
+	assert.NoError(t, (&WhileStatement{Condition: &VariableReference{Name: "A"}}).Execute(mock))
+	assert.NoError(t, (&WendStatement{}).Execute(mock))
+
+	assert.Equal(t, []bool{true, false}, mock.whileConditions)
+	assert.Equal(t, 1, mock.wendCount)
+}
+
+func TestLoopStatement_Execute(t *testing.T) {
+	tests := []struct {
+		name       string
+		stmt       *LoopStatement
+		wantRepeat bool
+	}{
+		{"bare LOOP repeats", &LoopStatement{}, true},
+		{"LOOP WHILE true repeats", &LoopStatement{Condition: &NumberLiteral{Value: "1"}}, true},
+		{"LOOP WHILE false ends", &LoopStatement{Condition: &NumberLiteral{Value: "0"}}, false},
+		{"LOOP UNTIL true ends", &LoopStatement{Condition: &NumberLiteral{Value: "1"}, Until: true}, false},
+		{"LOOP UNTIL false repeats", &LoopStatement{Condition: &NumberLiteral{Value: "0"}, Until: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockOps()
+			assert.NoError(t, tt.stmt.Execute(mock))
+			assert.Equal(t, []bool{tt.wantRepeat}, mock.loopRepeats)
+		})
+	}
+}
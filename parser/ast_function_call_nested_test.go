@@ -108,7 +108,7 @@ func TestParser_NestedFunctionCalls(t *testing.T) {
 			}
 
 			require.NotNil(t, program)
-			assert.Equal(t, tt.expected, program)
+			assert.Equal(t, tt.expected, statementsOnly(program))
 		})
 	}
 }
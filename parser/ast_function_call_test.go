@@ -14,6 +14,7 @@ func TestParser_FunctionCall(t *testing.T) {
 		name     string
 		input    string
 		expected *Program
+		err      string // Expected parse error, "" when the program parses
 	}{
 		{
 			name:  "LEN function call",
@@ -46,15 +47,13 @@ func TestParser_FunctionCall(t *testing.T) {
 		{
 			name:  "Function call with no arguments",
 			input: `10 PRINT RND()`,
-			expected: program(
-				line(10, 1, printStmt(funcCall("RND", []Expression{}, 1), 1)),
-			),
+			err:   "?SYNTAX ERROR: RND requires exactly 1 argument",
 		},
 		{
 			name:  "Function call in assignment",
 			input: `10 LET L = LEN("TEST")`,
 			expected: program(
-				line(10, 1, letStmt("L", funcCall("LEN", []Expression{str("TEST", 1)}, 1), 1)),
+				line(10, 1, declaredLetStmt("L", funcCall("LEN", []Expression{str("TEST", 1)}, 1))),
 			),
 		},
 	}
@@ -65,13 +64,19 @@ func TestParser_FunctionCall(t *testing.T) {
 			p := New(l)
 			program := p.ParseProgram()
 
+			if tt.err != "" {
+				require.NotNil(t, p.ParseError())
+				assert.Contains(t, p.ParseError().Error(), tt.err)
+				return
+			}
+
 			// Check for parsing errors
 			if p.ParseError() != nil {
 				t.Fatalf("parser error: %v", p.ParseError())
 			}
 
 			require.NotNil(t, program)
-			assert.Equal(t, tt.expected, program)
+			assert.Equal(t, tt.expected, statementsOnly(program))
 		})
 	}
 }
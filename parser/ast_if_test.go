@@ -29,7 +29,7 @@ func TestIfStatement_Execute(t *testing.T) {
 			condition := &VariableReference{Name: "CONDITION"}
 			thenStmt := &PrintStatement{Expression: &StringLiteral{Value: "EXECUTED"}}
 
-			stmt := &IfStatement{Condition: condition, ThenStmt: thenStmt}
+			stmt := &IfStatement{Condition: condition, ThenStmts: []Statement{thenStmt}}
 
 			err := stmt.Execute(mock)
 			assert.NoError(t, err)
@@ -52,7 +52,7 @@ func TestIfStatement_Execute_ErrorCases(t *testing.T) {
 		condition := &VariableReference{Name: "A"}
 		thenStmt := &PrintStatement{Expression: &StringLiteral{Value: "TEST"}}
 
-		stmt := &IfStatement{Condition: condition, ThenStmt: thenStmt}
+		stmt := &IfStatement{Condition: condition, ThenStmts: []Statement{thenStmt}}
 
 		err := stmt.Execute(mock)
 		assert.Error(t, err)
@@ -66,9 +66,40 @@ func TestIfStatement_Execute_ErrorCases(t *testing.T) {
 		condition := &VariableReference{Name: "A"}
 		thenStmt := &PrintStatement{Expression: &StringLiteral{Value: "TEST"}}
 
-		stmt := &IfStatement{Condition: condition, ThenStmt: thenStmt}
+		stmt := &IfStatement{Condition: condition, ThenStmts: []Statement{thenStmt}}
 
 		err := stmt.Execute(mock)
 		assert.Error(t, err)
 	})
 }
+
+func TestIfStatement_Execute_StatementList(t *testing.T) {
+	t.Run("runs every statement when true", func(t *testing.T) {
+		mock := newMockOps()
+		stmt := &IfStatement{
+			Condition: &NumberLiteral{Value: "1"},
+			ThenStmts: []Statement{
+				&LetStatement{Variable: "B", Expression: &NumberLiteral{Value: "1"}},
+				&PrintStatement{Expression: &VariableReference{Name: "B"}},
+			},
+		}
+
+		assert.NoError(t, stmt.Execute(mock))
+		assert.Equal(t, []string{"1"}, mock.getOutput())
+	})
+
+	t.Run("stops after a jump", func(t *testing.T) {
+		mock := newMockOps()
+		stmt := &IfStatement{
+			Condition: &NumberLiteral{Value: "1"},
+			ThenStmts: []Statement{
+				&GotoStatement{TargetLine: 100},
+				&PrintStatement{Expression: &StringLiteral{Value: "SKIPPED"}},
+			},
+		}
+
+		assert.NoError(t, stmt.Execute(mock))
+		assert.True(t, mock.gotoRequested)
+		assert.Empty(t, mock.getOutput())
+	})
+}
@@ -33,12 +33,6 @@ func TestInputStatement_Execute(t *testing.T) {
 			expectedVal:  "HELLO",
 			expectError:  false,
 		},
-		{
-			name:        "invalid numeric input",
-			variable:    "A",
-			input:       "ABC",
-			expectError: true,
-		},
 	}
 
 	for _, tt := range tests {
@@ -46,7 +40,7 @@ func TestInputStatement_Execute(t *testing.T) {
 			mock := newMockOps()
 			mock.setInput([]string{tt.input})
 
-			stmt := &InputStatement{Variable: tt.variable}
+			stmt := &InputStatement{Targets: []ReadTarget{{Name: tt.variable}}}
 
 			err := stmt.Execute(mock)
 
@@ -74,7 +68,7 @@ func TestInputStatement_Execute_ErrorCases(t *testing.T) {
 		mock := newMockOps()
 		mock.readInputError = errors.New("input error")
 
-		stmt := &InputStatement{Variable: "A"}
+		stmt := &InputStatement{Targets: []ReadTarget{{Name: "A"}}}
 
 		err := stmt.Execute(mock)
 		assert.Error(t, err)
@@ -85,9 +79,114 @@ func TestInputStatement_Execute_ErrorCases(t *testing.T) {
 		mock.setInput([]string{"42"})
 		mock.setVariableError = errors.New("set error")
 
-		stmt := &InputStatement{Variable: "A"}
+		stmt := &InputStatement{Targets: []ReadTarget{{Name: "A"}}}
 
 		err := stmt.Execute(mock)
 		assert.Error(t, err)
 	})
 }
+
+func TestInputStatement_Execute_MultipleTargets(t *testing.T) {
+	targets := []ReadTarget{{Name: "A"}, {Name: "B$"}, {Name: "C"}}
+
+	t.Run("one line fills every target", func(t *testing.T) {
+		mock := newMockOps()
+		mock.setInput([]string{"1, HELLO ,3"})
+
+		assert.NoError(t, (&InputStatement{Prompt: "VALUES", Targets: targets}).Execute(mock))
+		assert.Equal(t, types.NewNumberValue(1), mock.variables["A"])
+		assert.Equal(t, types.NewStringValue("HELLO"), mock.variables["B$"])
+		assert.Equal(t, types.NewNumberValue(3), mock.variables["C"])
+		assert.Equal(t, []string{"VALUES"}, mock.prompts)
+	})
+
+	t.Run("too few values re-prompts with ??", func(t *testing.T) {
+		mock := newMockOps()
+		mock.setInput([]string{"1", "X,2"})
+
+		assert.NoError(t, (&InputStatement{Targets: targets}).Execute(mock))
+		assert.Equal(t, types.NewStringValue("X"), mock.variables["B$"])
+		assert.Equal(t, types.NewNumberValue(2), mock.variables["C"])
+		assert.Equal(t, []string{"", "?? "}, mock.prompts)
+	})
+
+	t.Run("extra values are ignored with a warning", func(t *testing.T) {
+		mock := newMockOps()
+		mock.setInput([]string{"1,Y,3,4"})
+
+		assert.NoError(t, (&InputStatement{Targets: targets}).Execute(mock))
+		assert.Equal(t, []string{"?EXTRA IGNORED"}, mock.printedLines)
+	})
+
+	t.Run("array element target", func(t *testing.T) {
+		mock := newMockOps()
+		mock.setInput([]string{"7"})
+
+		stmt := &InputStatement{Targets: []ReadTarget{{Name: "A", Indices: []Expression{&NumberLiteral{Value: "2"}}}}}
+		assert.NoError(t, stmt.Execute(mock))
+	})
+}
+
+func TestSplitInputFields(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{""}},
+		{"42", []string{"42"}},
+		{" 1 , 2 ", []string{"1", "2"}},
+		{`"SMITH, JOHN",30`, []string{"SMITH, JOHN", "30"}},
+		{`" PADDED "`, []string{" PADDED "}},
+		{"A,,B", []string{"A", "", "B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, splitInputFields(tt.input))
+		})
+	}
+}
+
+func TestInputStatement_Execute_RedoFromStart(t *testing.T) {
+	mock := newMockOps()
+	mock.setInput([]string{"ABC", "42"})
+
+	stmt := &InputStatement{Prompt: "N", Targets: []ReadTarget{{Name: "A"}}}
+	assert.NoError(t, stmt.Execute(mock))
+
+	assert.Equal(t, types.NewNumberValue(42), mock.variables["A"])
+	assert.Equal(t, []string{"?REDO FROM START"}, mock.printedLines)
+	assert.Equal(t, []string{"N", "N"}, mock.prompts)
+}
+
+func TestGetStatement_Execute(t *testing.T) {
+	tests := []struct {
+		name        string
+		variable    string
+		keys        []string
+		expected    types.Value
+		expectError bool
+	}{
+		{name: "key into string variable", variable: "K$", keys: []string{"A"}, expected: types.NewStringValue("A")},
+		{name: "no key gives empty string", variable: "K$", expected: types.NewStringValue("")},
+		{name: "digit into numeric variable", variable: "K", keys: []string{"7"}, expected: types.NewNumberValue(7)},
+		{name: "no key gives zero", variable: "K", expected: types.NewNumberValue(0)},
+		{name: "letter into numeric variable", variable: "K", keys: []string{"X"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockOps()
+			mock.keys = tt.keys
+			stmt := &GetStatement{Targets: []ReadTarget{{Name: tt.variable}}}
+
+			err := stmt.Execute(mock)
+			if tt.expectError {
+				assert.ErrorContains(t, err, "?SYNTAX ERROR")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, mock.variables[tt.variable])
+		})
+	}
+}
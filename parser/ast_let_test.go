@@ -83,3 +83,21 @@ func TestLetStatement_Execute_ErrorCases(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestSwapStatement_Execute(t *testing.T) {
+	t.Run("exchanges two variables", func(t *testing.T) {
+		mock := newMockOps()
+		mock.setVariable("A", types.NewNumberValue(1))
+		mock.setVariable("B", types.NewNumberValue(2))
+
+		assert.NoError(t, (&SwapStatement{Left: ReadTarget{Name: "A"}, Right: ReadTarget{Name: "B"}}).Execute(mock))
+		assert.Equal(t, types.NewNumberValue(2), mock.variables["A"])
+		assert.Equal(t, types.NewNumberValue(1), mock.variables["B"])
+	})
+
+	t.Run("rejects mixed types", func(t *testing.T) {
+		mock := newMockOps()
+		err := (&SwapStatement{Left: ReadTarget{Name: "A"}, Right: ReadTarget{Name: "B$"}}).Execute(mock)
+		assert.Equal(t, types.ErrTypeMismatch, err)
+	})
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
 )
 
 func TestPrintStatement_Execute(t *testing.T) {
@@ -78,3 +80,25 @@ func TestPrintStatement_Execute_ErrorCases(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestPrintStatement_CommaZones(t *testing.T) {
+	t.Run("parser records comma separators", func(t *testing.T) {
+		p := New(lexer.New(`10 PRINT "A","B";"C",`))
+		prog := p.ParseProgram()
+		require.Nil(t, p.ParseError())
+		stmt := prog.Lines[0].Statements[0].(*PrintStatement)
+		assert.Equal(t, []string{",", ";", ","}, stmt.Separators)
+		assert.True(t, stmt.NoNewline)
+	})
+
+	t.Run("comma pads to the next zone", func(t *testing.T) {
+		mock := newMockOps()
+		stmt := &PrintStatement{
+			Items:      []Expression{&StringLiteral{Value: "AB"}, &StringLiteral{Value: "C"}},
+			Separators: []string{",", ""},
+		}
+
+		require.NoError(t, stmt.Execute(mock))
+		assert.Equal(t, []string{"AB        C"}, mock.getOutput())
+	})
+}
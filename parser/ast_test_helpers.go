@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"fmt"
 
 	"basic-interpreter/types"
 )
@@ -19,6 +20,23 @@ type MockInterpreterOperations struct {
 	printed      []string
 	inputQueue   []string
 	inputIndex   int
+	prompts      []string
+	keys         []string
+	clears       int
+	listed       []LineRange
+	located      [][2]int
+	lineTrace    bool
+	poked        [][2]int
+	timers       map[int]mockTimer
+	hostCalls    []string        // Host statements run, by name
+	hostArgs     [][]types.Value // Their arguments
+	openFiles    map[int]string  // Names of the open files by number
+	fileOutput   map[int]string  // Text written to each file
+	fileInput    []string        // Lines for INPUT# and characters for GET#, in order
+
+	// Declarations
+	declared []string
+	options  []string
 
 	// Control flow tracking
 	gotoRequested   bool
@@ -27,8 +45,16 @@ type MockInterpreterOperations struct {
 	stopRequested   bool
 	gosubRequested  bool
 	gosubTarget     int
+	runRequested    bool
+	runTarget       int
 	returnRequested bool
 
+	// Loop tracking
+	whileConditions []bool
+	wendCount       int
+	doCount         int
+	loopRepeats     []bool
+
 	// Error injection for testing
 	getVariableError error
 	setVariableError error
@@ -88,6 +114,7 @@ func (m *MockInterpreterOperations) Print(text string) error {
 }
 
 func (m *MockInterpreterOperations) ReadInput(prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
 	if m.readInputError != nil {
 		return "", m.readInputError
 	}
@@ -112,6 +139,12 @@ func (m *MockInterpreterOperations) RequestEnd() error {
 	return nil
 }
 
+func (m *MockInterpreterOperations) RequestRun(startLine int) error {
+	m.runRequested = true
+	m.runTarget = startLine
+	return nil
+}
+
 func (m *MockInterpreterOperations) RequestStop() error {
 	m.stopRequested = true
 	return nil
@@ -128,11 +161,23 @@ func (m *MockInterpreterOperations) RequestReturn() error {
 	return nil
 }
 
+func (m *MockInterpreterOperations) ControlTransferred() bool {
+	return m.gotoRequested || m.gosubRequested || m.returnRequested || m.endRequested || m.stopRequested
+}
+
 func (m *MockInterpreterOperations) NormalizeVariableName(name string) string {
 	// Simple implementation for testing - just return as-is
 	return name
 }
 
+// Output layout stubs: 10-column zones starting at column 0
+func (m *MockInterpreterOperations) PrintZoneWidth() int { return 10 }
+
+func (m *MockInterpreterOperations) OutputColumn() int { return 0 }
+
+// Precision stub: full float64 precision
+func (m *MockInterpreterOperations) Precision() types.Precision { return types.PrecisionDouble }
+
 // Loop control no-ops for AST unit testing
 func (m *MockInterpreterOperations) BeginFor(variable string, end types.Value, step types.Value) error {
 	return nil
@@ -142,6 +187,140 @@ func (m *MockInterpreterOperations) IterateFor(variable string) error {
 	return nil
 }
 
+func (m *MockInterpreterOperations) BeginWhile(condition bool) error {
+	m.whileConditions = append(m.whileConditions, condition)
+	return nil
+}
+
+func (m *MockInterpreterOperations) EndWhile() error {
+	m.wendCount++
+	return nil
+}
+
+func (m *MockInterpreterOperations) BeginDo() error {
+	m.doCount++
+	return nil
+}
+
+func (m *MockInterpreterOperations) EndDo(repeat bool) error {
+	m.loopRepeats = append(m.loopRepeats, repeat)
+	return nil
+}
+
+func (m *MockInterpreterOperations) ReadKey() (string, error) {
+	if len(m.keys) == 0 {
+		return "", nil
+	}
+	key := m.keys[0]
+	m.keys = m.keys[1:]
+	return key, nil
+}
+
+func (m *MockInterpreterOperations) DeclareVariable(name string) error {
+	m.declared = append(m.declared, name)
+	return nil
+}
+
+func (m *MockInterpreterOperations) DefineConstant(name string, value types.Value) error {
+	return m.SetVariable(name, value)
+}
+
+func (m *MockInterpreterOperations) SetOption(name string) error {
+	m.options = append(m.options, name)
+	return nil
+}
+
+func (m *MockInterpreterOperations) ListProgram(r LineRange) error {
+	m.listed = append(m.listed, r)
+	return nil
+}
+
+func (m *MockInterpreterOperations) LocateCursor(row, col int) error {
+	m.located = append(m.located, [2]int{row, col})
+	return nil
+}
+
+func (m *MockInterpreterOperations) CallStatement(name string, args []types.Value) error {
+	m.hostCalls = append(m.hostCalls, name)
+	m.hostArgs = append(m.hostArgs, args)
+	return nil
+}
+
+func (m *MockInterpreterOperations) ClearScreen() error {
+	m.clears++
+	return nil
+}
+
+func (m *MockInterpreterOperations) ClearVariables() error {
+	m.variables = make(map[string]types.Value)
+	return nil
+}
+
+func (m *MockInterpreterOperations) NewProgram() error {
+	m.variables = make(map[string]types.Value)
+	m.endRequested = true
+	return nil
+}
+
+func (m *MockInterpreterOperations) Poke(address, value int) error {
+	m.poked = append(m.poked, [2]int{address, value})
+	return nil
+}
+
+func (m *MockInterpreterOperations) SetLineTrace(on bool) error {
+	m.lineTrace = on
+	return nil
+}
+
+// mockTimer records an EVERY or AFTER statement
+type mockTimer struct {
+	ticks  int
+	repeat bool
+	target int
+}
+
+func (m *MockInterpreterOperations) SetTimer(timer, ticks int, repeat bool, targetLine int) error {
+	if m.timers == nil {
+		m.timers = make(map[int]mockTimer)
+	}
+	m.timers[timer] = mockTimer{ticks: ticks, repeat: repeat, target: targetLine}
+	return nil
+}
+
+func (m *MockInterpreterOperations) OpenFile(file, device, channel int, name string) error {
+	if m.openFiles == nil {
+		m.openFiles = make(map[int]string)
+	}
+	m.openFiles[file] = fmt.Sprintf("%d,%d,%s", device, channel, name)
+	return nil
+}
+
+func (m *MockInterpreterOperations) CloseFile(file int) error {
+	delete(m.openFiles, file)
+	return nil
+}
+
+func (m *MockInterpreterOperations) WriteFile(file int, text string) error {
+	if m.fileOutput == nil {
+		m.fileOutput = make(map[int]string)
+	}
+	m.fileOutput[file] += text
+	return nil
+}
+
+func (m *MockInterpreterOperations) ReadFileLine(file int) (string, error) {
+	return m.ReadFileChar(file)
+}
+
+func (m *MockInterpreterOperations) ReadFileChar(file int) (string, error) {
+	if len(m.fileInput) == 0 {
+		return "", nil
+	}
+	next := m.fileInput[0]
+	m.fileInput = m.fileInput[1:]
+	return next, nil
+}
+
 // Data management stub
 func (m *MockInterpreterOperations) GetNextData() (types.Value, error) {
 	return types.NewNumberValue(0), nil
@@ -21,7 +21,7 @@ func TestParser_ColonSeparatesStatements(t *testing.T) {
 			printStmt(str("B", 1), 1),
 		),
 	)
-	require.Equal(t, expected, got)
+	require.Equal(t, expected, statementsOnly(got))
 }
 
 func TestParser_RemSkipsRestOfLine(t *testing.T) {
@@ -34,11 +34,45 @@ func TestParser_RemSkipsRestOfLine(t *testing.T) {
 	expected := program(
 		line(10, 1,
 			printStmt(str("A", 1), 1),
-			remStmt(1),
+			remStmt(" ignore this: PRINT \"X\"", 1),
 		),
 		line(20, 2,
 			printStmt(str("B", 2), 2),
 		),
 	)
-	require.Equal(t, expected, got)
+	require.Equal(t, expected, statementsOnly(got))
+}
+
+func TestParser_BareRemKeepsNextLine(t *testing.T) {
+	input := "10 REM\n20 PRINT \"B\""
+	l := lexer.New(input)
+	p := New(l)
+	got := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	expected := program(
+		line(10, 1,
+			remStmt("", 1),
+		),
+		line(20, 2,
+			printStmt(str("B", 2), 2),
+		),
+	)
+	require.Equal(t, expected, statementsOnly(got))
+}
+
+func TestParser_KeepsSourceText(t *testing.T) {
+	input := "  10   PRINT  \"A\" : REM  Hi: there  \r\n20\n30 rem\n40 GOTO 10 : REM"
+	p := New(lexer.New(input))
+	got := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	require.Len(t, got.Lines, 4)
+	sources := []string{"PRINT  \"A\" : REM  Hi: there", "", "rem", "GOTO 10 : REM"}
+	for n, l := range got.Lines {
+		require.Equal(t, sources[n], l.Source, "line %d", l.Number)
+	}
+	require.Equal(t, remStmt("  Hi: there", 1), got.Lines[0].Statements[1])
+	require.Equal(t, remStmt("", 3), got.Lines[2].Statements[0])
+	require.Equal(t, remStmt("", 4), got.Lines[3].Statements[1])
 }
@@ -0,0 +1,42 @@
+// ABOUTME: Dialect selection: strict C64 BASIC V2 rejects the statements the extended dialect adds
+// ABOUTME: WHILE, DO, CONST, PRINT AT and the other extensions are syntax errors under DialectC64
+
+package parser
+
+import "basic-interpreter/lexer"
+
+// Dialect names a BASIC flavour an entry belongs to
+type Dialect = lexer.Dialect
+
+// Supported dialects
+const (
+	DialectC64      = lexer.DialectC64      // Commodore 64 BASIC V2
+	DialectExtended = lexer.DialectExtended // Structured extensions such as WHILE and CONST
+)
+
+// extensions maps the statement and keyword tokens of the catalogue missing from C64 BASIC to their names
+var extensions = func() map[lexer.TokenType]string {
+	tokens := map[lexer.TokenType]string{}
+	for _, ref := range references {
+		if ref.Token != "" && !ref.InDialect(DialectC64) {
+			tokens[ref.Token] = ref.Name
+		}
+	}
+	return tokens
+}()
+
+// SetDialect selects the dialect; the default is DialectExtended. Hex literals are the lexer's, set with
+// lexer.SetDialect before New.
+func (p *Parser) SetDialect(d Dialect) {
+	p.dialect = d
+}
+
+// checkDialect reports false with a parse error when the current token is an extension the dialect lacks
+func (p *Parser) checkDialect() bool {
+	name, ok := extensions[p.currentToken.Type]
+	if !ok || p.dialect != DialectC64 {
+		return true
+	}
+	p.addErrorf("%s is not part of the %s dialect", name, p.dialect)
+	return false
+}
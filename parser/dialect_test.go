@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_C64DialectRejectsExtensions(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{"10 WHILE A<3: A=A+1: WEND", "WHILE is not part of the c64 dialect"},
+		{"10 A=1: CONST B=2", "CONST is not part of the c64 dialect"},
+		{"10 DO: A=A+1: LOOP UNTIL A=3", "DO is not part of the c64 dialect"},
+		{"10 IF A THEN SWAP A,B", "SWAP is not part of the c64 dialect"},
+		{"10 LOCATE 1,1", "LOCATE is not part of the c64 dialect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.SetDialect(DialectC64)
+			p.ParseProgram()
+			require.NotNil(t, p.ParseError())
+			assert.Equal(t, tt.message, p.ParseError().Message)
+
+			p = New(lexer.New(tt.input))
+			p.ParseProgram()
+			assert.Nil(t, p.ParseError(), "the extended dialect is the default")
+		})
+	}
+}
+
+func TestParser_C64DialectReadsAtAsVariable(t *testing.T) {
+	p := New(lexer.New("10 PRINT AT"))
+	p.SetDialect(DialectC64)
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+	assert.Equal(t, []Statement{&PrintStatement{Expression: &VariableReference{Name: "AT"}}}, program.Lines[0].Statements)
+}
+
+func TestLexerExtensionKeywordsMatchCatalogue(t *testing.T) {
+	for _, word := range lexer.Keywords() {
+		want := false
+		for _, ref := range references {
+			if ref.Token == lexer.TokenType(word) {
+				want = !ref.InDialect(DialectC64)
+			}
+		}
+		assert.Equal(t, want, lexer.IsExtensionKeyword(word), word)
+	}
+}
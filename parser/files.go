@@ -0,0 +1,253 @@
+// ABOUTME: Logical file statements OPEN, CLOSE, PRINT#, INPUT# and GET#, which work on file numbers opened on devices
+// ABOUTME: The interpreter keeps the open files; statements here evaluate their arguments and format what they move
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/types"
+)
+
+// OpenStatement represents OPEN file[, device[, channel[, name$]]]
+type OpenStatement struct {
+	File    Expression
+	Device  Expression // nil for device 1
+	Channel Expression // Secondary address; nil for 0
+	Name    Expression // nil for no file name
+}
+
+func (op *OpenStatement) Execute(ops InterpreterOperations) error {
+	args := []Expression{op.File, &NumberLiteral{Value: "1"}, &NumberLiteral{Value: "0"}}
+	if op.Device != nil {
+		args[1] = op.Device
+	}
+	if op.Channel != nil {
+		args[2] = op.Channel
+	}
+	numbers, err := evaluateIndices(ops, args)
+	if err != nil {
+		return err
+	}
+	var name string
+	if op.Name != nil {
+		value, err := op.Name.Evaluate(ops)
+		if err != nil {
+			return err
+		}
+		if value.Type != types.StringType {
+			return types.ErrTypeMismatch
+		}
+		name = value.String
+	}
+	return ops.OpenFile(numbers[0], numbers[1], numbers[2], name)
+}
+
+// CloseStatement represents CLOSE file
+type CloseStatement struct {
+	File Expression
+}
+
+func (cs *CloseStatement) Execute(ops InterpreterOperations) error {
+	file, err := fileNumber(ops, cs.File)
+	if err != nil {
+		return err
+	}
+	return ops.CloseFile(file)
+}
+
+// PrintFileStatement represents PRINT#file[, items], writing items as PRINT would show them
+type PrintFileStatement struct {
+	File       Expression
+	Items      []Expression // Empty for a bare PRINT#file, which writes only a newline
+	Separators []string     // As in PrintStatement, set only when a comma separates items
+	NoNewline  bool
+}
+
+func (ps *PrintFileStatement) Execute(ops InterpreterOperations) error {
+	file, err := fileNumber(ops, ps.File)
+	if err != nil {
+		return err
+	}
+	values := make([]types.Value, len(ps.Items))
+	for idx, item := range ps.Items {
+		if values[idx], err = item.Evaluate(ops); err != nil {
+			return err
+		}
+	}
+	// Zones are counted from the start of the record rather than the screen cursor
+	text := formatPrintItems(ops, values, ps.Separators, 0)
+	if !ps.NoNewline {
+		text += "\n"
+	}
+	return ops.WriteFile(file, text)
+}
+
+// InputFileStatement represents INPUT#file, var[, var...], reading fields as INPUT does from lines of a file
+type InputFileStatement struct {
+	File    Expression
+	Targets []ReadTarget
+}
+
+func (ins *InputFileStatement) Execute(ops InterpreterOperations) error {
+	file, err := fileNumber(ops, ins.File)
+	if err != nil {
+		return err
+	}
+	var fields []string
+	values := make([]types.Value, len(ins.Targets))
+	for n, tgt := range ins.Targets {
+		// A line short of fields continues on the next, without the "??" of INPUT
+		if len(fields) == 0 {
+			line, err := ops.ReadFileLine(file)
+			if err != nil {
+				return err
+			}
+			fields = splitInputFields(line)
+		}
+		field := fields[0]
+		fields = fields[1:]
+		// An empty field, as at the end of the file, reads as 0 into a number
+		if field == "" && !strings.HasSuffix(tgt.Name, "$") {
+			field = "0"
+		}
+		if values[n], err = fieldValue(tgt.Name, field); err != nil {
+			return fmt.Errorf("?FILE DATA ERROR")
+		}
+	}
+	return assignTargets(ops, ins.Targets, values)
+}
+
+// GetFileStatement represents GET#file, var[, var...], reading one character of a file per target
+type GetFileStatement struct {
+	File    Expression
+	Targets []ReadTarget
+}
+
+func (gs *GetFileStatement) Execute(ops InterpreterOperations) error {
+	file, err := fileNumber(ops, gs.File)
+	if err != nil {
+		return err
+	}
+	for _, tgt := range gs.Targets {
+		char, err := ops.ReadFileChar(file)
+		if err != nil {
+			return err
+		}
+		value, err := keyValue(tgt.Name, char)
+		if err != nil {
+			return err
+		}
+		idxs, err := evaluateIndices(ops, tgt.Indices)
+		if err != nil {
+			return err
+		}
+		if err := setTarget(ops, tgt, idxs, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileNumber evaluates the file number of a file statement
+func fileNumber(ops InterpreterOperations, expr Expression) (int, error) {
+	numbers, err := evaluateIndices(ops, []Expression{expr})
+	if err != nil {
+		return 0, err
+	}
+	return numbers[0], nil
+}
+
+// parseOpenStatement parses OPEN file[, device[, channel[, name$]]]
+func (p *Parser) parseOpenStatement() *OpenStatement {
+	p.nextToken() // consume OPEN
+	stmt := &OpenStatement{}
+	args := []*Expression{&stmt.File, &stmt.Device, &stmt.Channel, &stmt.Name}
+	for n, arg := range args {
+		if *arg = p.parseExpression(); *arg == nil {
+			return nil
+		}
+		if n == len(args)-1 || p.peekToken.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken() // move to ','
+		p.nextToken() // move to the next argument
+	}
+	return stmt
+}
+
+// parseCloseStatement parses CLOSE file
+func (p *Parser) parseCloseStatement() *CloseStatement {
+	p.nextToken() // consume CLOSE
+	file := p.parseExpression()
+	if file == nil {
+		return nil
+	}
+	return &CloseStatement{File: file}
+}
+
+// parsePrintFileStatement parses PRINT#file[, items]
+func (p *Parser) parsePrintFileStatement() *PrintFileStatement {
+	file := p.parseFileNumber()
+	if file == nil {
+		return nil
+	}
+	stmt := &PrintFileStatement{File: file}
+	if p.peekToken.Type == lexer.NEWLINE || p.peekToken.Type == lexer.EOF || p.peekToken.Type == lexer.COLON {
+		return stmt
+	}
+	if !p.expectFileComma() {
+		return nil
+	}
+	stmt.Items, stmt.Separators, stmt.NoNewline = p.parsePrintItems()
+	if stmt.Items == nil {
+		return nil
+	}
+	return stmt
+}
+
+// parseInputFileStatement parses INPUT#file, var[, var...]
+func (p *Parser) parseInputFileStatement() *InputFileStatement {
+	file := p.parseFileNumber()
+	if file == nil || !p.expectFileComma() {
+		return nil
+	}
+	targets := p.parseTargetList()
+	if targets == nil {
+		return nil
+	}
+	return &InputFileStatement{File: file, Targets: targets}
+}
+
+// parseGetFileStatement parses GET#file, var[, var...]
+func (p *Parser) parseGetFileStatement() *GetFileStatement {
+	file := p.parseFileNumber()
+	if file == nil || !p.expectFileComma() {
+		return nil
+	}
+	targets := p.parseTargetList()
+	if targets == nil {
+		return nil
+	}
+	return &GetFileStatement{File: file, Targets: targets}
+}
+
+// parseFileNumber parses the #file following PRINT, INPUT or GET
+func (p *Parser) parseFileNumber() Expression {
+	p.nextToken() // consume the keyword
+	p.nextToken() // consume '#'
+	return p.parseExpression()
+}
+
+// expectFileComma moves past the comma after a file number to the first token after it
+func (p *Parser) expectFileComma() bool {
+	p.nextToken()
+	if p.currentToken.Type != lexer.COMMA {
+		p.addTokenError("',' after file number", p.currentToken.Type)
+		return false
+	}
+	p.nextToken() // consume ','
+	return true
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_FileStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Statement
+	}{
+		{
+			name:     "OPEN with all arguments",
+			input:    `10 OPEN 1,8,2,"DATA,S,W"`,
+			expected: &OpenStatement{File: num("1", 1), Device: num("8", 8), Channel: num("2", 2), Name: str("DATA,S,W", 0)},
+		},
+		{
+			name:     "OPEN with a file number only",
+			input:    "10 OPEN F",
+			expected: &OpenStatement{File: varRef("F", 0)},
+		},
+		{
+			name:     "CLOSE",
+			input:    "10 CLOSE 1",
+			expected: &CloseStatement{File: num("1", 1)},
+		},
+		{
+			name:  "PRINT# with items",
+			input: `10 PRINT#1,A$;",";B`,
+			expected: &PrintFileStatement{
+				File:  num("1", 1),
+				Items: []Expression{varRef("A$", 0), str(",", 0), varRef("B", 0)},
+			},
+		},
+		{
+			name:     "PRINT# with a trailing comma",
+			input:    "10 PRINT #F+1,X,",
+			expected: &PrintFileStatement{File: binaryOp(varRef("F", 0), "+", num("1", 1), 0), Items: []Expression{varRef("X", 0)}, Separators: []string{","}, NoNewline: true},
+		},
+		{
+			name:     "bare PRINT#",
+			input:    "10 PRINT#1: PRINT",
+			expected: &PrintFileStatement{File: num("1", 1)},
+		},
+		{
+			name:     "INPUT#",
+			input:    "10 INPUT#2,A$,N(I)",
+			expected: &InputFileStatement{File: num("2", 2), Targets: []ReadTarget{{Name: "A$"}, {Name: "N", Indices: []Expression{varRef("I", 0)}}}},
+		},
+		{
+			name:     "GET#",
+			input:    "10 GET#2,C$",
+			expected: &GetFileStatement{File: num("2", 2), Targets: []ReadTarget{{Name: "C$"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+			assert.Equal(t, tt.expected, program.Lines[0].Statements[0])
+		})
+	}
+}
+
+func TestParser_FileStatementErrors(t *testing.T) {
+	for _, input := range []string{"10 OPEN", "10 CLOSE", "10 PRINT#1;A", "10 INPUT#1", "10 INPUT#1 A$", "10 GET#", "10 GET#1,"} {
+		t.Run(input, func(t *testing.T) {
+			p := New(lexer.New(input))
+			p.ParseProgram()
+			assert.NotNil(t, p.ParseError())
+		})
+	}
+}
+
+func TestFileStatements_Execute(t *testing.T) {
+	mock := newMockOps()
+	require.NoError(t, (&OpenStatement{File: num("1", 1), Name: str("F", 0)}).Execute(mock))
+	assert.Equal(t, map[int]string{1: "1,0,F"}, mock.openFiles)
+
+	require.NoError(t, (&PrintFileStatement{File: num("1", 1), Items: []Expression{str("A", 0), num("2", 2)}, Separators: []string{",", ""}}).Execute(mock))
+	require.NoError(t, (&PrintFileStatement{File: num("1", 1)}).Execute(mock))
+	assert.Equal(t, "A         2\n\n", mock.fileOutput[1], "zones count from the start of the record")
+
+	mock.fileInput = []string{`"X, Y",3`, "", "Q"}
+	require.NoError(t, (&InputFileStatement{File: num("1", 1), Targets: []ReadTarget{{Name: "A$"}, {Name: "N"}, {Name: "M"}}}).Execute(mock))
+	assert.Equal(t, "X, Y", mock.variables["A$"].String)
+	assert.Equal(t, 3.0, mock.variables["N"].Number)
+	assert.Equal(t, 0.0, mock.variables["M"].Number, "an empty field reads as 0")
+	require.NoError(t, (&GetFileStatement{File: num("1", 1), Targets: []ReadTarget{{Name: "C$"}, {Name: "D$"}}}).Execute(mock))
+	assert.Equal(t, "Q", mock.variables["C$"].String)
+	assert.Equal(t, "", mock.variables["D$"].String)
+
+	mock.fileInput = []string{"ABC"}
+	err := (&InputFileStatement{File: num("1", 1), Targets: []ReadTarget{{Name: "N"}}}).Execute(mock)
+	assert.EqualError(t, err, "?FILE DATA ERROR")
+
+	require.NoError(t, (&CloseStatement{File: num("1", 1)}).Execute(mock))
+	assert.Empty(t, mock.openFiles)
+}
@@ -0,0 +1,55 @@
+// ABOUTME: Names the host registered with the interpreter: Go functions parse as calls, Go statements as NAME args
+// ABOUTME: A host statement takes comma-separated expressions, evaluated and handed to its Go callback when it runs
+
+package parser
+
+import (
+	"strings"
+
+	"basic-interpreter/lexer"
+)
+
+// SetHostFunctions makes calls to these names parse as function calls rather than array elements,
+// for functions the host registers with the interpreter
+func (p *Parser) SetHostFunctions(names []string) {
+	p.hostFunctions = upperSet(names)
+}
+
+// SetHostStatements makes these names start statements, NAME [expr[, expr...]], for statements the host
+// registers with the interpreter; they can no longer be assigned to as variables
+func (p *Parser) SetHostStatements(names []string) {
+	p.hostStatements = upperSet(names)
+}
+
+// upperSet returns the upper-case forms of names as a set
+func upperSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToUpper(name)] = true
+	}
+	return set
+}
+
+// parseHostStatement parses a host statement: its name and a possibly empty list of argument expressions
+func (p *Parser) parseHostStatement() Statement {
+	stmt := &HostStatement{Name: strings.ToUpper(p.currentToken.Literal)}
+	switch p.peekToken.Type {
+	case lexer.COLON, lexer.NEWLINE, lexer.EOF:
+		return stmt
+	}
+	if p.isElse(p.peekToken) {
+		return stmt
+	}
+	for {
+		p.nextToken() // move to the argument
+		arg := p.parseExpression()
+		if arg == nil {
+			return nil
+		}
+		stmt.Arguments = append(stmt.Arguments, arg)
+		if p.peekToken.Type != lexer.COMMA {
+			return stmt
+		}
+		p.nextToken() // move to ','
+	}
+}
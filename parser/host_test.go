@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/types"
+)
+
+func TestHostStatement_Parsing(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Statement
+	}{
+		{"no arguments", "10 beep", []Statement{&HostStatement{Name: "BEEP"}}},
+		{"arguments", `10 BEEP 440, "A"+B$`, []Statement{&HostStatement{Name: "BEEP", Arguments: []Expression{
+			num("440", 440),
+			&BinaryOperation{Left: &StringLiteral{Value: "A"}, Operator: "+", Right: &VariableReference{Name: "B$"}},
+		}}}},
+		{"in a statement list", "10 BEEP: BEEP 1: END", []Statement{
+			&HostStatement{Name: "BEEP"},
+			&HostStatement{Name: "BEEP", Arguments: []Expression{num("1", 1)}},
+			&EndStatement{},
+		}},
+		{"other names stay variables", "10 BEEPS=1", []Statement{&LetStatement{Variable: "BEEPS", Expression: num("1", 1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.SetHostStatements([]string{"Beep"})
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+			assert.Equal(t, tt.expected, program.Lines[0].Statements)
+		})
+	}
+}
+
+func TestHostStatement_Execute(t *testing.T) {
+	mock := newMockOps()
+	mock.variables["A"] = types.NewNumberValue(2)
+	stmt := &HostStatement{Name: "BEEP", Arguments: []Expression{&VariableReference{Name: "A"}, &StringLiteral{Value: "X"}}}
+
+	require.NoError(t, stmt.Execute(mock))
+	assert.Equal(t, []string{"BEEP"}, mock.hostCalls)
+	assert.Equal(t, [][]types.Value{{types.NewNumberValue(2), types.NewStringValue("X")}}, mock.hostArgs)
+}
@@ -0,0 +1,122 @@
+// ABOUTME: LIST statement and the line ranges shared by LIST in programs, the REPL and the list subcommand
+// ABOUTME: Ranges are written N, N-M, -M or N-; listings print the program's stored source text
+
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/lexer"
+)
+
+// LineRange selects the program lines numbered From through To
+type LineRange struct {
+	From int
+	To   int
+}
+
+// AllLines selects the whole program
+var AllLines = LineRange{From: 0, To: MaxLineNumber}
+
+// Contains reports whether line number n is in the range
+func (r LineRange) Contains(n int) bool {
+	return n >= r.From && n <= r.To
+}
+
+// ParseLineRange parses a LIST range such as "100", "100-200", "-50" or "100-"; "" selects all lines
+func ParseLineRange(s string) (LineRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return AllLines, nil
+	}
+	from, to, isRange := strings.Cut(s, "-")
+	r := AllLines
+	var err error
+	if from = strings.TrimSpace(from); from != "" {
+		if r.From, err = rangeBound(from); err != nil {
+			return LineRange{}, err
+		}
+	}
+	if !isRange {
+		return LineRange{From: r.From, To: r.From}, nil
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		if r.To, err = rangeBound(to); err != nil {
+			return LineRange{}, err
+		}
+	} else if from == "" {
+		return LineRange{}, fmt.Errorf("invalid line range %q", s)
+	}
+	return r, nil
+}
+
+// rangeBound parses one end of a line range
+func rangeBound(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > MaxLineNumber {
+		return 0, fmt.Errorf("invalid line number %q", s)
+	}
+	return n, nil
+}
+
+// SourceLines maps each numbered line of program text to the text after its number; a repeated number keeps the later line
+func SourceLines(src string) map[int]string {
+	lines := make(map[int]string)
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		end := 0
+		for end < len(line) && line[end] >= '0' && line[end] <= '9' {
+			end++
+		}
+		if number, err := strconv.Atoi(line[:end]); err == nil {
+			lines[number] = strings.TrimSpace(line[end:])
+		}
+	}
+	return lines
+}
+
+// ListStatement represents LIST [range]
+type ListStatement struct {
+	Range LineRange
+}
+
+func (ls *ListStatement) Execute(ops InterpreterOperations) error {
+	return ops.ListProgram(ls.Range)
+}
+
+// parseListStatement parses LIST with an optional range: N, N-M, -M or N-
+func (p *Parser) parseListStatement() *ListStatement {
+	stmt := &ListStatement{Range: AllLines}
+	hasFrom := p.peekToken.Type == lexer.NUMBER
+	if hasFrom {
+		p.nextToken()
+		from, ok := p.parseLineNumber()
+		if !ok {
+			return nil
+		}
+		stmt.Range = LineRange{From: from, To: from}
+	}
+	if p.peekToken.Type != lexer.MINUS {
+		return stmt
+	}
+
+	p.nextToken() // move to '-'
+	stmt.Range.To = MaxLineNumber
+	if p.peekToken.Type != lexer.NUMBER {
+		if hasFrom {
+			return stmt // N- lists to the end
+		}
+		p.nextToken()
+		p.addTokenError("line number", p.currentToken.Type)
+		return nil
+	}
+	p.nextToken()
+	to, ok := p.parseLineNumber()
+	if !ok {
+		return nil
+	}
+	stmt.Range.To = to
+	return stmt
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LineRange
+		wantErr bool
+	}{
+		{"", AllLines, false},
+		{"100", LineRange{100, 100}, false},
+		{"100-200", LineRange{100, 200}, false},
+		{" -50", LineRange{0, 50}, false},
+		{"100-", LineRange{100, MaxLineNumber}, false},
+		{"-", LineRange{}, true},
+		{"A-B", LineRange{}, true},
+		{"64000", LineRange{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLineRange(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParser_ListStatement(t *testing.T) {
+	tests := []struct {
+		input string
+		want  LineRange
+	}{
+		{"10 LIST", AllLines},
+		{"10 LIST 100", LineRange{100, 100}},
+		{"10 LIST 100-200", LineRange{100, 200}},
+		{"10 LIST -50", LineRange{0, 50}},
+		{"10 LIST 100-: END", LineRange{100, MaxLineNumber}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+			assert.Equal(t, &ListStatement{Range: tt.want}, program.Lines[0].Statements[0])
+		})
+	}
+
+	p := New(lexer.New("10 LIST -"))
+	p.ParseProgram()
+	assert.NotNil(t, p.ParseError())
+}
+
+func TestSourceLines(t *testing.T) {
+	got := SourceLines("20 PRINT \"B\"\r\n10  REM  A \n\nNOTE\n20 END\n30")
+	assert.Equal(t, map[int]string{10: "REM  A", 20: "END", 30: ""}, got)
+}
+
+func TestListStatement_Execute(t *testing.T) {
+	mock := newMockOps()
+	require.NoError(t, (&ListStatement{Range: LineRange{10, 20}}).Execute(mock))
+	assert.Equal(t, []LineRange{{10, 20}}, mock.listed)
+}
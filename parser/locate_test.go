@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_LocateAndPrintAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Statement
+	}{
+		{
+			name:     "LOCATE counts from 1",
+			input:    "10 LOCATE 5,R+1",
+			expected: &LocateStatement{Row: num("5", 5), Column: &BinaryOperation{Left: &VariableReference{Name: "R"}, Operator: "+", Right: num("1", 1)}, Origin: 1},
+		},
+		{
+			name:  "PRINT AT counts from 0",
+			input: `10 PRINT AT 0,3;"HI"`,
+			expected: &PrintStatement{
+				At:         &LocateStatement{Row: num("0", 0), Column: num("3", 3)},
+				Expression: &StringLiteral{Value: "HI"},
+			},
+		},
+		{
+			name:  "PRINT AT with trailing semicolon keeps the cursor",
+			input: "10 PRINT AT 2,4;",
+			expected: &PrintStatement{
+				At:        &LocateStatement{Row: num("2", 2), Column: num("4", 4)},
+				Items:     []Expression{&StringLiteral{Value: ""}},
+				NoNewline: true,
+			},
+		},
+		{
+			name:     "AT alone is a variable",
+			input:    "10 PRINT AT",
+			expected: &PrintStatement{Expression: &VariableReference{Name: "AT"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+			assert.Equal(t, tt.expected, program.Lines[0].Statements[0])
+		})
+	}
+}
+
+func TestParser_LocateErrors(t *testing.T) {
+	for _, input := range []string{"10 LOCATE 5", "10 LOCATE", `10 PRINT AT 1,2 "X"`} {
+		t.Run(input, func(t *testing.T) {
+			p := New(lexer.New(input))
+			p.ParseProgram()
+			assert.NotNil(t, p.ParseError())
+		})
+	}
+}
+
+func TestLocateStatement_Execute(t *testing.T) {
+	mock := newMockOps()
+	stmt := &PrintStatement{
+		At:         &LocateStatement{Row: num("5", 5), Column: num("10", 10), Origin: 1},
+		Expression: &StringLiteral{Value: "HERE"},
+	}
+	require.NoError(t, stmt.Execute(mock))
+	assert.Equal(t, [][2]int{{4, 9}}, mock.located)
+	assert.Equal(t, []string{"HERE"}, mock.printedLines)
+}
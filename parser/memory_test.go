@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/types"
+)
+
+func TestParser_Poke(t *testing.T) {
+	p := New(lexer.New("10 POKE 1024+I,1"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+	expected := &PokeStatement{
+		Address: &BinaryOperation{Left: num("1024", 1024), Operator: "+", Right: &VariableReference{Name: "I"}},
+		Value:   num("1", 1),
+	}
+	assert.Equal(t, expected, program.Lines[0].Statements[0])
+
+	ops := newMockOps()
+	ops.variables["I"] = types.NewNumberValue(2)
+	require.NoError(t, program.Lines[0].Statements[0].Execute(ops))
+	assert.Equal(t, [][2]int{{1026, 1}}, ops.poked)
+}
+
+func TestParser_PokeErrors(t *testing.T) {
+	for _, input := range []string{"10 POKE 1024", "10 POKE", "10 POKE 1024 1"} {
+		t.Run(input, func(t *testing.T) {
+			p := New(lexer.New(input))
+			p.ParseProgram()
+			assert.NotNil(t, p.ParseError())
+		})
+	}
+}
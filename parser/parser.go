@@ -5,12 +5,16 @@ package parser
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
 	"basic-interpreter/lexer"
 )
 
+// MaxLineNumber is the highest line number C64 BASIC accepts
+const MaxLineNumber = 63999
+
 // ParseError represents an error that occurred during parsing
 type ParseError struct {
 	Message  string
@@ -30,17 +34,21 @@ type Parser struct {
 	currentToken lexer.Token
 	peekToken    lexer.Token
 
-	error             *ParseError
-	currentSourceLine int
+	error          *ParseError     // Error that stopped the statement being parsed, cleared when parsing resumes
+	errors         []*ParseError   // Every error reported, in source order
+	shims          bool            // Accept statements from other 8-bit dialects (see shims.go)
+	dialect        Dialect         // DialectC64 rejects the extensions (see dialect.go)
+	hostFunctions  map[string]bool // Upper-case names of functions the host registered, parsed as calls
+	hostStatements map[string]bool // Upper-case names of statements the host registered (see host.go)
 }
 
 // New creates a new parser instance
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		lexer:             l,
-		precedence:        NewPrecedenceTable(),
-		error:             nil,
-		currentSourceLine: 1,
+		lexer:      l,
+		precedence: NewPrecedenceTable(),
+		error:      nil,
+		dialect:    DialectExtended,
 	}
 
 	// Read two tokens, so currentToken and peekToken are both set
@@ -56,51 +64,65 @@ func (p *Parser) nextToken() {
 	p.peekToken = p.lexer.NextToken()
 }
 
-// ParseError returns the parse error if any
+// ParseError returns the first parse error if any
 func (p *Parser) ParseError() *ParseError {
-	return p.error
+	if len(p.errors) == 0 {
+		return nil
+	}
+	return p.errors[0]
+}
+
+// ParseErrors returns every parse error in source order. After an error the parser skips to the next statement, or to
+// the next line when the line number itself is bad, so one pass finds the errors of every line.
+func (p *Parser) ParseErrors() []*ParseError {
+	return p.errors
 }
 
 // addErrorf adds a formatted error message with current token context
 func (p *Parser) addErrorf(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	p.addErrorAt(p.currentSourceLine, msg)
+	p.addErrorAt(p.currentToken.Pos, msg)
 }
 
 // addTokenError adds an error message with token type context
 func (p *Parser) addTokenError(expected string, got lexer.TokenType) {
-	p.addErrorAt(p.currentSourceLine, fmt.Sprintf("expected %s, got %s (token %q)", expected, got, p.currentToken.Literal))
+	p.addErrorAt(p.currentToken.Pos, fmt.Sprintf("expected %s, got %s (token %q)", expected, got, p.currentToken.Literal))
 }
 
 // addLiteralError adds an error message with token literal context
 func (p *Parser) addLiteralError(prefix string, literal string) {
-	p.addErrorAt(p.currentSourceLine, fmt.Sprintf("%s: %s", prefix, literal))
+	p.addErrorAt(p.currentToken.Pos, fmt.Sprintf("%s: %s", prefix, literal))
 }
 
-// addErrorAt sets a ParseError with an explicit line (only if no error exists yet)
-func (p *Parser) addErrorAt(line int, msg string) {
+// addErrorAt records a ParseError at an explicit position, unless the statement being parsed already has one
+func (p *Parser) addErrorAt(pos lexer.Position, msg string) {
 	if p.error == nil {
-		p.error = &ParseError{
-			Message: msg,
-			Position: lexer.Position{
-				Line:   line,
-				Column: 0, // Column tracking not implemented yet
-			},
+		p.error = &ParseError{Message: msg, Position: pos}
+		p.errors = append(p.errors, p.error)
+	}
+}
+
+// synchronize skips the rest of a failed statement, up to the next colon when atColon is set or else the end of the
+// line, so parsing can resume there
+func (p *Parser) synchronize(atColon bool) {
+	for p.currentToken.Type != lexer.NEWLINE && p.currentToken.Type != lexer.EOF {
+		if atColon && p.currentToken.Type == lexer.COLON {
+			break
 		}
+		p.nextToken()
 	}
+	p.error = nil
 }
 
 // ParseProgram parses the entire program
 func (p *Parser) ParseProgram() *Program {
 	program := &Program{}
 	program.Lines = []*Line{}
-	p.currentSourceLine = 1
 
 	for p.currentToken.Type != lexer.EOF {
 		// Skip newlines
 		if p.currentToken.Type == lexer.NEWLINE {
 			p.nextToken()
-			p.currentSourceLine++
 			continue
 		}
 
@@ -109,16 +131,33 @@ func (p *Parser) ParseProgram() *Program {
 			program.Lines = append(program.Lines, line)
 		}
 
-		// Stop parsing if we encountered any error
+		// A bad line number leaves nothing to resume on this line
 		if p.error != nil {
-			p.error.Position.Line = p.currentSourceLine
-			break
+			p.synchronize(false)
 		}
 	}
 
+	program.Lines = normalizeLines(program.Lines)
 	return program
 }
 
+// normalizeLines orders lines by number like a BASIC editor would store them; when a number
+// appears more than once the later line replaces the earlier one
+func normalizeLines(lines []*Line) []*Line {
+	latest := make(map[int]*Line, len(lines))
+	for _, line := range lines {
+		latest[line.Number] = line
+	}
+	normalized := make([]*Line, 0, len(latest))
+	for _, line := range lines {
+		if latest[line.Number] == line {
+			normalized = append(normalized, line)
+		}
+	}
+	sort.SliceStable(normalized, func(a, b int) bool { return normalized[a].Number < normalized[b].Number })
+	return normalized
+}
+
 // parseLine parses a single BASIC line
 func (p *Parser) parseLine() *Line {
 	if p.currentToken.Type != lexer.NUMBER {
@@ -126,9 +165,8 @@ func (p *Parser) parseLine() *Line {
 		return nil
 	}
 
-	lineNum, err := strconv.Atoi(p.currentToken.Literal)
-	if err != nil {
-		p.addLiteralError("invalid line number", p.currentToken.Literal)
+	lineNum, ok := p.parseLineNumber()
+	if !ok {
 		return nil
 	}
 
@@ -136,7 +174,54 @@ func (p *Parser) parseLine() *Line {
 
 	p.nextToken() // consume line number
 
-	// Parse statements on this line. On first error, skip rest of the line.
+	start := p.currentToken.Pos.Offset
+	line.Statements = p.parseStatementList()
+	line.Source = strings.TrimSpace(p.lexer.Text(start, p.currentToken.Pos.Offset))
+	return line
+}
+
+// parseLineNumber converts the current NUMBER token to a line number, rejecting values outside 0-63999
+func (p *Parser) parseLineNumber() (int, bool) {
+	n, err := strconv.Atoi(p.currentToken.Literal)
+	if err != nil {
+		p.addLiteralError("invalid line number", p.currentToken.Literal)
+		return 0, false
+	}
+	if n > MaxLineNumber {
+		p.addLiteralError(fmt.Sprintf("line number out of range 0-%d", MaxLineNumber), p.currentToken.Literal)
+		return 0, false
+	}
+	return n, true
+}
+
+// ParseStatements parses colon-separated statements entered without a line number (immediate mode)
+func (p *Parser) ParseStatements() []Statement {
+	stmts := p.parseStatementList()
+	if len(p.errors) == 0 && p.currentToken.Type == lexer.NEWLINE {
+		p.addTokenError("end of input", p.currentToken.Type)
+	}
+	return stmts
+}
+
+// ParseExpression parses input holding a single expression, such as a debugger watch
+func (p *Parser) ParseExpression() Expression {
+	expr := p.parseExpression()
+	if p.error != nil || expr == nil {
+		return nil
+	}
+	if p.peekToken.Type != lexer.EOF {
+		p.nextToken()
+		p.addTokenError("end of expression", p.currentToken.Type)
+		return nil
+	}
+	return expr
+}
+
+// parseStatementList parses colon-separated statements up to the end of the line
+func (p *Parser) parseStatementList() []Statement {
+	stmts := []Statement{}
+
+	// Parse statements on this line. After an error, resume at the next statement.
 	for p.currentToken.Type != lexer.NEWLINE && p.currentToken.Type != lexer.EOF {
 		// Support colon-separated statements
 		if p.currentToken.Type == lexer.COLON {
@@ -144,35 +229,73 @@ func (p *Parser) parseLine() *Line {
 			continue
 		}
 		stmt := p.parseStatement()
+		if p.error != nil {
+			p.synchronize(true)
+			continue
+		}
 		if stmt == nil {
-			// An error occurred; stop here
 			break
 		}
-		line.Statements = append(line.Statements, stmt)
+		stmts = append(stmts, stmt)
 		// Advance token after parsing a successful statement
 		p.nextToken()
 	}
 
-	return line
+	return stmts
 }
 
 // parseStatement parses a statement
 func (p *Parser) parseStatement() Statement {
+	if !p.checkDialect() {
+		return nil
+	}
 	switch p.currentToken.Type {
 	case lexer.PRINT:
+		if p.peekToken.Type == lexer.HASH {
+			return p.parsePrintFileStatement()
+		}
 		return p.parsePrintStatement()
 	case lexer.LET:
 		return p.parseAssignmentOrArraySet(true) // LET assignment or array set
 	case lexer.IDENT:
+		if p.hostStatements[strings.ToUpper(p.currentToken.Literal)] {
+			return p.parseHostStatement()
+		}
+		if p.shims {
+			if stmt, ok := p.parseForeignStatement(); ok {
+				return stmt
+			}
+		}
 		return p.parseAssignmentOrArraySet(false) // Direct assignment or array set
 	case lexer.INPUT:
+		if p.peekToken.Type == lexer.HASH {
+			return p.parseInputFileStatement()
+		}
 		return p.parseInputStatement()
 	case lexer.END:
 		return p.parseEndStatement()
 	case lexer.RUN:
 		return p.parseRunStatement()
+	case lexer.LIST:
+		return p.parseListStatement()
+	case lexer.LOCATE:
+		return p.parseLocateStatement()
 	case lexer.STOP:
 		return p.parseStopStatement()
+	case lexer.NEW:
+		return p.parseNewStatement()
+	case lexer.POKE:
+		return p.parsePokeStatement()
+	case lexer.TRON, lexer.TROFF:
+		return p.parseTraceStatement()
+	case lexer.EVERY, lexer.AFTER:
+		return p.parseTimerStatement()
+	case lexer.CLR:
+		return p.parseClrStatement()
+	case lexer.CONST:
+		return p.parseConstStatement()
+	case lexer.OPTION:
+		return p.parseOptionStatement()
 	case lexer.GOTO:
 		return p.parseGotoStatement()
 	case lexer.GOSUB:
@@ -187,12 +310,31 @@ func (p *Parser) parseStatement() Statement {
 		return p.parseForStatement()
 	case lexer.NEXT:
 		return p.parseNextStatement()
+	case lexer.WHILE:
+		return p.parseWhileStatement()
+	case lexer.WEND:
+		return p.parseWendStatement()
+	case lexer.SWAP:
+		return p.parseSwapStatement()
+	case lexer.DO:
+		return p.parseDoStatement()
+	case lexer.LOOP:
+		return p.parseLoopStatement()
 	case lexer.DATA:
 		return p.parseDataStatement()
 	case lexer.READ:
 		return p.parseReadStatement()
+	case lexer.GET:
+		if p.peekToken.Type == lexer.HASH {
+			return p.parseGetFileStatement()
+		}
+		return p.parseGetStatement()
 	case lexer.REM:
 		return p.parseRemStatement()
+	case lexer.OPEN:
+		return p.parseOpenStatement()
+	case lexer.CLOSE:
+		return p.parseCloseStatement()
 	case lexer.DIM:
 		return p.parseDimStatement()
 	case lexer.DEF:
@@ -209,12 +351,23 @@ func (p *Parser) parseStatement() Statement {
 // parseRemStatement parses a REM statement which consumes the rest of the line
 func (p *Parser) parseRemStatement() *RemStatement {
 	stmt := &RemStatement{}
+	// REM is never abbreviated, so its literal is exactly the text it was typed as
+	textStart := p.currentToken.Pos.Offset + len(p.currentToken.Literal)
+	// A bare REM ends here; consuming it would leave the line's NEWLINE as the current token
+	if p.peekToken.Type == lexer.NEWLINE || p.peekToken.Type == lexer.EOF {
+		stmt.Text = strings.TrimRight(p.lexer.Text(textStart, p.peekToken.Pos.Offset), " \t\r")
+		return stmt
+	}
 	// Consume REM token
 	p.nextToken()
+	if !p.parseRequires() {
+		return nil
+	}
 	// Skip tokens until end of line or EOF, but leave currentToken on last non-NEWLINE token
 	for p.peekToken.Type != lexer.NEWLINE && p.peekToken.Type != lexer.EOF {
 		p.nextToken()
 	}
+	stmt.Text = strings.TrimRight(p.lexer.Text(textStart, p.peekToken.Pos.Offset), " \t\r")
 	// Leave currentToken at NEWLINE/EOF so caller can advance appropriately
 	return stmt
 }
@@ -253,15 +406,35 @@ func (p *Parser) parseDataStatement() *DataStatement {
 
 // parseReadStatement parses a READ statement: READ <var>[, <var>...]
 func (p *Parser) parseReadStatement() *ReadStatement {
-	stmt := &ReadStatement{}
 	p.nextToken() // consume READ
 
+	targets := p.parseTargetList()
+	if targets == nil {
+		return nil
+	}
+	return &ReadStatement{Targets: targets}
+}
+
+// parseGetStatement parses a GET statement: GET <var>[, <var>...]
+func (p *Parser) parseGetStatement() *GetStatement {
+	p.nextToken() // consume GET
+
+	targets := p.parseTargetList()
+	if targets == nil {
+		return nil
+	}
+	return &GetStatement{Targets: targets}
+}
+
+// parseTargetList parses a comma-separated list of variables or array elements (as used by READ, INPUT and GET)
+func (p *Parser) parseTargetList() []ReadTarget {
 	// Expect at least one identifier
 	if p.currentToken.Type != lexer.IDENT {
 		p.addTokenError("variable name", p.currentToken.Type)
 		return nil
 	}
 
+	var targets []ReadTarget
 	for p.currentToken.Type == lexer.IDENT {
 		name := p.currentToken.Literal
 		target := ReadTarget{Name: name}
@@ -294,16 +467,129 @@ func (p *Parser) parseReadStatement() *ReadStatement {
 			}
 		}
 		// If we didn't have '(', leave IDENT as currentToken so caller/commas can advance
-		stmt.Targets = append(stmt.Targets, target)
+		targets = append(targets, target)
 		if p.currentToken.Type == lexer.COMMA || p.peekToken.Type == lexer.COMMA {
 			if p.currentToken.Type != lexer.COMMA {
 				p.nextToken()
 			}
 			p.nextToken() // move to next IDENT
+			if p.currentToken.Type != lexer.IDENT {
+				p.addTokenError("variable name", p.currentToken.Type)
+				return nil
+			}
 			continue
 		}
 		break
 	}
+	return targets
+}
+
+// parseSwapStatement parses SWAP <target>, <target>
+func (p *Parser) parseSwapStatement() *SwapStatement {
+	p.nextToken() // consume SWAP
+
+	targets := p.parseTargetList()
+	if targets == nil {
+		return nil
+	}
+	if len(targets) != 2 {
+		p.addErrorf("SWAP needs exactly two variables")
+		return nil
+	}
+	return &SwapStatement{Left: targets[0], Right: targets[1]}
+}
+
+// parseLocateStatement parses LOCATE row,col with the top-left corner at 1,1
+func (p *Parser) parseLocateStatement() *LocateStatement {
+	return p.parsePosition(1)
+}
+
+// isPrintAt reports whether the token after PRINT starts AT row,col rather than a variable named AT, which it
+// always is in the C64 dialect
+func (p *Parser) isPrintAt() bool {
+	return p.dialect == DialectExtended &&
+		p.currentToken.Type == lexer.IDENT && strings.EqualFold(p.currentToken.Literal, "AT") &&
+		(p.peekToken.Type == lexer.NUMBER || p.peekToken.Type == lexer.IDENT)
+}
+
+// parsePrintAt parses the AT row,col of PRINT AT with the top-left corner at 0,0
+func (p *Parser) parsePrintAt() *LocateStatement {
+	return p.parsePosition(0)
+}
+
+// parsePosition parses the row,col following the current token
+func (p *Parser) parsePosition(origin int) *LocateStatement {
+	p.nextToken() // consume LOCATE or AT
+	row := p.parseExpression()
+	if row == nil {
+		return nil
+	}
+	if p.peekToken.Type != lexer.COMMA {
+		p.nextToken()
+		p.addTokenError("',' between row and column", p.currentToken.Type)
+		return nil
+	}
+	p.nextToken() // move to ','
+	p.nextToken() // move to column
+	col := p.parseExpression()
+	if col == nil {
+		return nil
+	}
+	return &LocateStatement{Row: row, Column: col, Origin: origin}
+}
+
+// parsePokeStatement parses POKE address, value
+func (p *Parser) parsePokeStatement() *PokeStatement {
+	p.nextToken() // consume POKE
+	address := p.parseExpression()
+	if address == nil {
+		return nil
+	}
+	if p.peekToken.Type != lexer.COMMA {
+		p.nextToken()
+		p.addTokenError("',' between address and value", p.currentToken.Type)
+		return nil
+	}
+	p.nextToken() // move to ','
+	p.nextToken() // move to value
+	value := p.parseExpression()
+	if value == nil {
+		return nil
+	}
+	return &PokeStatement{Address: address, Value: value}
+}
+
+// parseTimerStatement parses EVERY|AFTER ticks[,timer] GOSUB line
+func (p *Parser) parseTimerStatement() *TimerStatement {
+	stmt := &TimerStatement{Repeat: p.currentToken.Type == lexer.EVERY}
+	p.nextToken() // consume EVERY or AFTER
+	if stmt.Ticks = p.parseExpression(); stmt.Ticks == nil {
+		return nil
+	}
+	if p.peekToken.Type == lexer.COMMA {
+		p.nextToken() // move to ','
+		p.nextToken() // move to the timer number
+		if stmt.Timer = p.parseExpression(); stmt.Timer == nil {
+			return nil
+		}
+	}
+	if p.currentToken.Type != lexer.GOSUB {
+		p.nextToken()
+	}
+	if p.currentToken.Type != lexer.GOSUB {
+		p.addTokenError("GOSUB", p.currentToken.Type)
+		return nil
+	}
+	p.nextToken() // consume GOSUB
+	if p.currentToken.Type != lexer.NUMBER {
+		p.addTokenError("line number", p.currentToken.Type)
+		return nil
+	}
+	target, ok := p.parseLineNumber()
+	if !ok {
+		return nil
+	}
+	stmt.TargetLine = target
 	return stmt
 }
 
@@ -383,18 +669,59 @@ func (p *Parser) parsePrintStatement() *PrintStatement {
 
 	// Consume PRINT and parse first expression
 	p.nextToken()
+	if p.isPrintAt() {
+		if stmt.At = p.parsePrintAt(); stmt.At == nil {
+			return nil
+		}
+		if p.peekToken.Type == lexer.NEWLINE || p.peekToken.Type == lexer.EOF || p.peekToken.Type == lexer.COLON {
+			stmt.Expression = &StringLiteral{Value: ""}
+			return stmt
+		}
+		if p.peekToken.Type != lexer.SEMICOLON {
+			p.nextToken()
+			p.addTokenError("';' after PRINT AT position", p.currentToken.Type)
+			return nil
+		}
+		p.nextToken() // move to ';'
+		if p.peekToken.Type == lexer.NEWLINE || p.peekToken.Type == lexer.EOF || p.peekToken.Type == lexer.COLON {
+			stmt.Items = []Expression{&StringLiteral{Value: ""}}
+			stmt.NoNewline = true
+			return stmt
+		}
+		p.nextToken()
+	}
+	items, separators, noNewline := p.parsePrintItems()
+	if items == nil {
+		return nil
+	}
+
+	// If only one item and no special flags, keep legacy field for compatibility
+	if len(items) == 1 && !noNewline {
+		stmt.Expression = items[0]
+	} else {
+		stmt.Items = items
+		stmt.NoNewline = noNewline
+		stmt.Separators = separators
+	}
+	return stmt
+}
+
+// parsePrintItems parses the items of PRINT or PRINT# from the current token. Separators are returned only when
+// one is a comma; a trailing separator suppresses the newline.
+func (p *Parser) parsePrintItems() (items []Expression, separators []string, noNewline bool) {
 	first := p.parseExpression()
 	if first == nil {
-		return nil
+		return nil, nil, false
 	}
 
 	// Collect additional items separated by ';' or ','
-	items := []Expression{first}
-	noNewline := false
+	items = []Expression{first}
+	separators = []string{""}
 	for {
 		// If next token is a separator, handle it
 		if p.peekToken.Type == lexer.SEMICOLON || p.peekToken.Type == lexer.COMMA {
 			p.nextToken() // move to separator
+			separators[len(separators)-1] = p.currentToken.Literal
 			// If the separator is the last token before end-of-statement, suppress newline
 			if p.peekToken.Type == lexer.NEWLINE || p.peekToken.Type == lexer.EOF || p.peekToken.Type == lexer.COLON {
 				noNewline = true
@@ -404,22 +731,18 @@ func (p *Parser) parsePrintStatement() *PrintStatement {
 			p.nextToken()
 			nextExpr := p.parseExpression()
 			if nextExpr == nil {
-				return nil
+				return nil, nil, false
 			}
 			items = append(items, nextExpr)
+			separators = append(separators, "")
 			continue
 		}
 		break
 	}
-
-	// If only one item and no special flags, keep legacy field for compatibility
-	if len(items) == 1 && !noNewline {
-		stmt.Expression = items[0]
-	} else {
-		stmt.Items = items
-		stmt.NoNewline = noNewline
+	if !strings.Contains(strings.Join(separators, ""), ",") {
+		separators = nil
 	}
-	return stmt
+	return items, separators, noNewline
 }
 
 // parseExpression parses an expression using operator precedence parsing
@@ -531,6 +854,15 @@ func (p *Parser) parsePrimaryExpression() Expression {
 				p.addTokenError("')' after array index", p.currentToken.Type)
 				return nil
 			}
+			// Arrays take numeric indexes, so a string argument means a misspelled function, such as LEFTT$(A$,2)
+			for _, index := range indices {
+				if t, known := staticType(index); known && t == stringArg {
+					if hint := suggestion(nameTok.Literal, p.functionNames()); hint != "" {
+						p.addErrorAt(nameTok.Pos, fmt.Sprintf("unknown function %s%s", nameTok.Literal, hint))
+						return nil
+					}
+				}
+			}
 			// Do not consume ')'; caller will advance
 			return &ArrayReference{Name: nameTok.Literal, Indices: indices}
 		}
@@ -566,7 +898,8 @@ func (p *Parser) parseGroupedExpression() Expression {
 	}
 
 	if p.peekToken.Type != lexer.RPAREN {
-		p.addErrorAt(p.currentSourceLine, "expected ')' after grouped expression")
+		p.nextToken()
+		p.addErrorf("expected ')' after grouped expression")
 		return nil
 	}
 
@@ -577,8 +910,31 @@ func (p *Parser) parseGroupedExpression() Expression {
 // parseEndStatement parses an END statement
 func (p *Parser) parseEndStatement() *EndStatement { return &EndStatement{} }
 
-// parseRunStatement parses a RUN statement
-func (p *Parser) parseRunStatement() *RunStatement { return &RunStatement{} }
+// parseRunStatement parses RUN with an optional starting line number
+func (p *Parser) parseRunStatement() *RunStatement {
+	stmt := &RunStatement{}
+	if p.peekToken.Type != lexer.NUMBER {
+		return stmt
+	}
+	p.nextToken()
+	line, ok := p.parseLineNumber()
+	if !ok {
+		return nil
+	}
+	stmt.StartLine, stmt.HasStart = line, true
+	return stmt
+}
+
+// parseClrStatement parses a CLR statement
+func (p *Parser) parseClrStatement() *ClrStatement { return &ClrStatement{} }
+
+// parseNewStatement parses a NEW statement
+func (p *Parser) parseNewStatement() *NewStatement { return &NewStatement{} }
+
+// parseTraceStatement parses a TRON or TROFF statement
+func (p *Parser) parseTraceStatement() *TraceStatement {
+	return &TraceStatement{On: p.currentToken.Type == lexer.TRON}
+}
 
 // parseStopStatement parses a STOP statement
 func (p *Parser) parseStopStatement() *StopStatement { return &StopStatement{} }
@@ -596,9 +952,8 @@ func (p *Parser) parseGotoStatement() *GotoStatement {
 	}
 
 	// Parse the target line number
-	targetLine, err := strconv.Atoi(p.currentToken.Literal)
-	if err != nil {
-		p.addErrorf("invalid line number: %s", p.currentToken.Literal)
+	targetLine, ok := p.parseLineNumber()
+	if !ok {
 		return nil
 	}
 
@@ -619,9 +974,8 @@ func (p *Parser) parseGosubStatement() *GosubStatement {
 	}
 
 	// Parse the target line number
-	targetLine, err := strconv.Atoi(p.currentToken.Literal)
-	if err != nil {
-		p.addErrorf("invalid line number: %s", p.currentToken.Literal)
+	targetLine, ok := p.parseLineNumber()
+	if !ok {
 		return nil
 	}
 
@@ -671,9 +1025,8 @@ func (p *Parser) parseOnStatement() Statement {
 			p.addTokenError("line number", p.currentToken.Type)
 			return nil
 		}
-		n, err := strconv.Atoi(p.currentToken.Literal)
-		if err != nil {
-			p.addErrorf("invalid line number: %s", p.currentToken.Literal)
+		n, ok := p.parseLineNumber()
+		if !ok {
 			return nil
 		}
 		targets = append(targets, n)
@@ -706,9 +1059,7 @@ func (p *Parser) parseIfStatement() *IfStatement {
 	// Support optional THEN if followed directly by GOTO (e.g., IF A=B GOTO 100)
 	if p.peekToken.Type == lexer.GOTO {
 		p.nextToken() // move to GOTO
-		// Parse the statement to execute (GOTO ...)
-		stmt.ThenStmt = p.parseStatement()
-		if stmt.ThenStmt == nil {
+		if !p.parseThenStatements(stmt) {
 			return nil
 		}
 		return stmt
@@ -727,26 +1078,59 @@ func (p *Parser) parseIfStatement() *IfStatement {
 
 	p.nextToken() // consume THEN
 
-	// Support short form: THEN <lineNumber> meaning GOTO <lineNumber>
-	if p.currentToken.Type == lexer.NUMBER {
-		targetLine, err := strconv.Atoi(p.currentToken.Literal)
-		if err != nil {
-			p.addErrorf("invalid line number: %s", p.currentToken.Literal)
-			return nil
-		}
-		stmt.ThenStmt = &GotoStatement{TargetLine: targetLine}
-		return stmt
-	}
-
-	// Parse the statement to execute when condition is true (regular form)
-	stmt.ThenStmt = p.parseStatement()
-	if stmt.ThenStmt == nil {
+	if !p.parseThenStatements(stmt) {
 		return nil
 	}
-
 	return stmt
 }
 
+// parseThenStatements parses the colon-separated statements that follow THEN up to end of line.
+// A bare line number (THEN 100) is shorthand for GOTO 100. Under the shims, ELSE starts the false branch.
+func (p *Parser) parseThenStatements(stmt *IfStatement) bool {
+	branch := &stmt.ThenStmts
+	for {
+		var then Statement
+		if p.currentToken.Type == lexer.NUMBER {
+			targetLine, ok := p.parseLineNumber()
+			if !ok {
+				return false
+			}
+			then = &GotoStatement{TargetLine: targetLine}
+		} else {
+			then = p.parseStatement()
+			if then == nil {
+				return false
+			}
+		}
+		*branch = append(*branch, then)
+
+		// ELSE may follow a THEN statement directly or after a colon
+		if branch == &stmt.ThenStmts && p.isElse(p.peekToken) {
+			p.nextToken() // move to ELSE
+			p.nextToken() // move to first ELSE statement
+			branch = &stmt.ElseStmts
+			continue
+		}
+
+		// Continue with the next statement if a colon follows
+		if p.peekToken.Type != lexer.COLON {
+			return true
+		}
+		p.nextToken() // move to ':'
+		for p.peekToken.Type == lexer.COLON {
+			p.nextToken()
+		}
+		if p.peekToken.Type == lexer.NEWLINE || p.peekToken.Type == lexer.EOF {
+			return true // trailing colon
+		}
+		p.nextToken() // move to next statement
+		if branch == &stmt.ThenStmts && p.isElse(p.currentToken) {
+			p.nextToken() // move to first ELSE statement
+			branch = &stmt.ElseStmts
+		}
+	}
+}
+
 // parseStringLiteral parses a string literal
 func (p *Parser) parseStringLiteral() *StringLiteral {
 	return &StringLiteral{Value: p.currentToken.Literal}
@@ -805,20 +1189,45 @@ func (p *Parser) parseFunctionCall() *FunctionCall {
 		return nil
 	}
 
+	if !p.checkBuiltinCall(functionCall) {
+		return nil
+	}
+
 	// Don't consume the closing parenthesis here - let the caller handle token advancement
 	return functionCall
 }
 
-// isBuiltinFunction checks if a name is a known built-in function (for disambiguating array refs)
+// builtinFunctions lists the names of the built-in functions, in the order of the reference catalogue
+var builtinFunctions = builtinNames()
+
+// builtinNames collects the names of the functions in the reference catalogue
+func builtinNames() []string {
+	var names []string
+	for _, ref := range references {
+		if ref.Kind == KindFunction {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// BuiltinFunctions returns the names of all built-in functions
+func BuiltinFunctions() []string {
+	return append([]string(nil), builtinFunctions...)
+}
+
+// isBuiltinFunction checks if a name is a known built-in or host function (for disambiguating array refs)
 func (p *Parser) isBuiltinFunction(name string) bool {
 	n := strings.ToUpper(name)
-	switch n {
-	case "LEN", "LEFT$", "RIGHT$", "MID$", "CHR$", "ASC", "STR$", "VAL", "RND",
-		"ABS", "INT", "SQR", "TAB", "SIN", "COS", "TAN", "ATN", "EXP", "LOG":
+	if p.hostFunctions[n] {
 		return true
-	default:
-		return false
 	}
+	for _, f := range builtinFunctions {
+		if f == n {
+			return true
+		}
+	}
+	return false
 }
 
 // parseDefFnStatement parses: DEF FNx(param) = expr
@@ -887,7 +1296,8 @@ func (p *Parser) parseAssignmentOrArraySet(hasLet bool) Statement {
 		return nil
 	}
 
-	name := p.currentToken.Literal
+	nameTok := p.currentToken
+	name := nameTok.Literal
 	p.nextToken() // consume name
 
 	// Array element assignment: IDENT '(' expr[,expr...] ')' '=' expr
@@ -919,7 +1329,8 @@ func (p *Parser) parseAssignmentOrArraySet(hasLet bool) Statement {
 		}
 		// Expect '='
 		if p.peekToken.Type != lexer.ASSIGN {
-			p.addTokenError("'=' after array reference", p.peekToken.Type)
+			p.nextToken()
+			p.addTokenError("'=' after array reference", p.currentToken.Type)
 			return nil
 		}
 		p.nextToken() // move to '='
@@ -933,6 +1344,11 @@ func (p *Parser) parseAssignmentOrArraySet(hasLet bool) Statement {
 
 	// Simple variable assignment
 	if p.currentToken.Type != lexer.ASSIGN {
+		// A name that is not assigned to may be a misspelled statement, such as PRNT
+		if hint := suggestion(name, p.statementNames()); hint != "" && !hasLet {
+			p.addErrorAt(nameTok.Pos, fmt.Sprintf("unrecognized statement %s%s", name, hint))
+			return nil
+		}
 		p.addTokenError("'=' after variable name", p.currentToken.Type)
 		return nil
 	}
@@ -941,7 +1357,38 @@ func (p *Parser) parseAssignmentOrArraySet(hasLet bool) Statement {
 	if expr == nil {
 		return nil
 	}
-	return &LetStatement{Variable: name, Expression: expr}
+	return &LetStatement{Variable: name, Expression: expr, Declared: hasLet}
+}
+
+// parseConstStatement parses CONST <name> = <expression>
+func (p *Parser) parseConstStatement() *ConstStatement {
+	p.nextToken() // consume CONST
+	if p.currentToken.Type != lexer.IDENT {
+		p.addTokenError("constant name", p.currentToken.Type)
+		return nil
+	}
+	name := p.currentToken.Literal
+	p.nextToken() // consume name
+	if p.currentToken.Type != lexer.ASSIGN {
+		p.addTokenError("'=' after constant name", p.currentToken.Type)
+		return nil
+	}
+	p.nextToken() // consume '='
+	expr := p.parseExpression()
+	if expr == nil {
+		return nil
+	}
+	return &ConstStatement{Name: name, Expression: expr}
+}
+
+// parseOptionStatement parses OPTION EXPLICIT
+func (p *Parser) parseOptionStatement() *OptionStatement {
+	p.nextToken() // consume OPTION
+	if p.currentToken.Type != lexer.IDENT || strings.ToUpper(p.currentToken.Literal) != "EXPLICIT" {
+		p.addTokenError("EXPLICIT", p.currentToken.Type)
+		return nil
+	}
+	return &OptionStatement{Name: "EXPLICIT"}
 }
 
 // parseInputStatement parses an INPUT statement
@@ -962,46 +1409,11 @@ func (p *Parser) parseInputStatement() *InputStatement {
 		p.nextToken() // consume semicolon
 	}
 
-	// Expect identifier (variable or array)
-	if p.currentToken.Type != lexer.IDENT {
-		p.addTokenError("variable name", p.currentToken.Type)
+	// One or more variables or array elements
+	stmt.Targets = p.parseTargetList()
+	if stmt.Targets == nil {
 		return nil
 	}
-
-	name := p.currentToken.Literal
-	// Array element?
-	if p.peekToken.Type == lexer.LPAREN {
-		p.nextToken() // consume IDENT
-		p.nextToken() // consume '('
-		// Parse indices (one or more)
-		idx := p.parseExpression()
-		if idx == nil {
-			return nil
-		}
-		stmt.ArrayName = name
-		stmt.ArrayIndices = append(stmt.ArrayIndices, idx)
-		for p.peekToken.Type == lexer.COMMA {
-			p.nextToken() // to comma
-			p.nextToken() // to next expr
-			idx = p.parseExpression()
-			if idx == nil {
-				return nil
-			}
-			stmt.ArrayIndices = append(stmt.ArrayIndices, idx)
-		}
-		// Align to the array-closing ')'
-		if p.peekToken.Type == lexer.RPAREN {
-			p.nextToken()
-		}
-		if p.currentToken.Type != lexer.RPAREN {
-			p.addTokenError("')' after array index", p.currentToken.Type)
-			return nil
-		}
-		return stmt
-	}
-
-	// Simple variable input
-	stmt.Variable = name
 	return stmt
 }
 
@@ -1070,14 +1482,47 @@ func (p *Parser) parseForStatement() *ForStatement {
 	return stmt
 }
 
+// parseWhileStatement parses a WHILE statement: WHILE <condition>
+func (p *Parser) parseWhileStatement() *WhileStatement {
+	p.nextToken() // consume WHILE
+
+	condition := p.parseExpression()
+	if condition == nil {
+		return nil
+	}
+	return &WhileStatement{Condition: condition}
+}
+
+// parseWendStatement parses a WEND statement
+func (p *Parser) parseWendStatement() *WendStatement { return &WendStatement{} }
+
+// parseDoStatement parses a DO statement
+func (p *Parser) parseDoStatement() *DoStatement { return &DoStatement{} }
+
+// parseLoopStatement parses a LOOP statement: LOOP [WHILE|UNTIL <condition>]
+func (p *Parser) parseLoopStatement() *LoopStatement {
+	stmt := &LoopStatement{}
+	if p.peekToken.Type != lexer.WHILE && p.peekToken.Type != lexer.UNTIL {
+		return stmt
+	}
+	p.nextToken() // move to WHILE/UNTIL
+	stmt.Until = p.currentToken.Type == lexer.UNTIL
+	p.nextToken() // consume WHILE/UNTIL
+
+	stmt.Condition = p.parseExpression()
+	if stmt.Condition == nil {
+		return nil
+	}
+	return stmt
+}
+
 // parseNextStatement parses a NEXT statement: NEXT I or NEXT
 func (p *Parser) parseNextStatement() *NextStatement {
 	stmt := &NextStatement{}
 
-	p.nextToken() // consume NEXT
-
 	// Check if there's a variable name (optional in NEXT)
-	if p.currentToken.Type == lexer.IDENT {
+	if p.peekToken.Type == lexer.IDENT {
+		p.nextToken() // consume NEXT
 		stmt.Variable = p.currentToken.Literal
 		// Token will be consumed by the main parser loop
 	}
@@ -29,6 +29,23 @@ func TestParser_StatementParsing(t *testing.T) {
 				line(20, 2, printStmt(str("LINE2", 2), 2)),
 			),
 		},
+		{
+			name:  "lines sorted by number",
+			input: "30 PRINT \"C\"\n10 PRINT \"A\"\n20 PRINT \"B\"",
+			expected: program(
+				line(10, 2, printStmt(str("A", 2), 2)),
+				line(20, 3, printStmt(str("B", 3), 3)),
+				line(30, 1, printStmt(str("C", 1), 1)),
+			),
+		},
+		{
+			name:  "duplicate line number replaced by the later line",
+			input: "20 PRINT \"OLD\"\n10 PRINT \"A\"\n20 PRINT \"NEW\"",
+			expected: program(
+				line(10, 2, printStmt(str("A", 2), 2)),
+				line(20, 3, printStmt(str("NEW", 3), 3)),
+			),
+		},
 		{
 			name:     "empty string",
 			input:    `10 PRINT ""`,
@@ -49,7 +66,7 @@ func TestParser_StatementParsing(t *testing.T) {
 		{
 			name:     "LET assignment",
 			input:    `10 LET A = 42`,
-			expected: program(line(10, 1, letStmt("A", num("42", 1), 1))),
+			expected: program(line(10, 1, declaredLetStmt("A", num("42", 1)))),
 		},
 		{
 			name:     "assignment without LET",
@@ -59,7 +76,7 @@ func TestParser_StatementParsing(t *testing.T) {
 		{
 			name:     "string variable assignment with LET",
 			input:    `10 LET A$ = "HELLO"`,
-			expected: program(line(10, 1, letStmt("A$", str("HELLO", 1), 1))),
+			expected: program(line(10, 1, declaredLetStmt("A$", str("HELLO", 1)))),
 		},
 		{
 			name:     "string variable assignment without LET",
@@ -67,6 +84,18 @@ func TestParser_StatementParsing(t *testing.T) {
 			expected: program(line(10, 1, letStmt("NAME$", str("JOHN DOE", 1), 1))),
 		},
 
+		// Declarations
+		{
+			name:     "CONST definition",
+			input:    `10 CONST PI = 3.14159`,
+			expected: program(line(10, 1, &ConstStatement{Name: "PI", Expression: num("3.14159", 1)})),
+		},
+		{
+			name:     "OPTION EXPLICIT",
+			input:    `10 option explicit`,
+			expected: program(line(10, 1, &OptionStatement{Name: "EXPLICIT"})),
+		},
+
 		// END statement
 		{
 			name:     "END statement",
@@ -89,6 +118,11 @@ func TestParser_StatementParsing(t *testing.T) {
 			input:    "10 RUN",
 			expected: program(line(10, 1, runStmt(1))),
 		},
+		{
+			name:     "RUN with a starting line",
+			input:    "10 RUN 200",
+			expected: program(line(10, 1, &RunStatement{StartLine: 200, HasStart: true})),
+		},
 		{
 			name:     "STOP statement",
 			input:    "10 STOP",
@@ -154,6 +188,63 @@ func TestParser_StatementParsing(t *testing.T) {
 			),
 		},
 
+		// WHILE/WEND statements
+		{
+			name:  "WHILE and WEND on one line",
+			input: "10 WHILE I < 3: I = I + 1: WEND",
+			expected: program(line(10, 1,
+				&WhileStatement{Condition: &ComparisonExpression{Left: varRef("I", 1), Operator: "<", Right: num("3", 1)}},
+				letStmt("I", binaryOp(varRef("I", 1), "+", num("1", 1), 1), 1),
+				&WendStatement{},
+			)),
+		},
+
+		// DO/LOOP statements
+		{
+			name:  "DO with LOOP UNTIL",
+			input: "10 DO: LOOP UNTIL A$ = \"Y\"",
+			expected: program(line(10, 1,
+				&DoStatement{},
+				&LoopStatement{Condition: &ComparisonExpression{Left: varRef("A$", 1), Operator: "=", Right: str("Y", 1)}, Until: true},
+			)),
+		},
+		{
+			name:     "bare LOOP",
+			input:    "10 LOOP",
+			expected: program(line(10, 1, &LoopStatement{})),
+		},
+
+		// SWAP statements
+		{
+			name:  "SWAP variable and array element",
+			input: "10 SWAP A, B(I)",
+			expected: program(line(10, 1, &SwapStatement{
+				Left:  ReadTarget{Name: "A"},
+				Right: ReadTarget{Name: "B", Indices: []Expression{varRef("I", 1)}},
+			})),
+		},
+
+		// CLR statement
+		{
+			name:     "CLR statement",
+			input:    "10 CLR",
+			expected: program(line(10, 1, &ClrStatement{})),
+		},
+
+		// NEW statement
+		{
+			name:     "NEW statement",
+			input:    "10 NEW",
+			expected: program(line(10, 1, &NewStatement{})),
+		},
+
+		// GET statement
+		{
+			name:     "GET statement",
+			input:    "10 GET K$",
+			expected: program(line(10, 1, &GetStatement{Targets: []ReadTarget{{Name: "K$"}}})),
+		},
+
 		// INPUT statements
 		{
 			name:     "INPUT without prompt",
@@ -170,6 +261,15 @@ func TestParser_StatementParsing(t *testing.T) {
 			input:    "10 INPUT \"WHAT IS YOUR NAME\"; NAME$",
 			expected: program(line(10, 1, inputStmt("WHAT IS YOUR NAME", "NAME$", 1))),
 		},
+		{
+			name:  "INPUT with several targets",
+			input: "10 INPUT \"DATA\"; A, B$, C(1)",
+			expected: program(line(10, 1, &InputStatement{Prompt: "DATA", Targets: []ReadTarget{
+				{Name: "A"},
+				{Name: "B$"},
+				{Name: "C", Indices: []Expression{num("1", 1)}},
+			}})),
+		},
 	}
 
 	for _, tt := range tests {
@@ -182,7 +282,7 @@ func TestParser_StatementParsing(t *testing.T) {
 			require.NotNil(t, program, "ParseProgram() returned nil")
 			require.Nil(t, p.ParseError(), "Parser error: %v", p.ParseError())
 
-			assert.Equal(t, tt.expected, program)
+			assert.Equal(t, tt.expected, statementsOnly(program))
 		})
 	}
 }
@@ -203,6 +303,41 @@ func TestParser_ParseErrors(t *testing.T) {
 			input:       `PRINT "HELLO"`,
 			expectError: true,
 		},
+		{
+			name:        "line number above 63999",
+			input:       `64000 PRINT "HELLO"`,
+			expectError: true,
+		},
+		{
+			name:        "highest line number",
+			input:       `63999 GOTO 63999`,
+			expectError: false,
+		},
+		{
+			name:        "GOTO target above 63999",
+			input:       `10 GOTO 64000`,
+			expectError: true,
+		},
+		{
+			name:        "GOSUB target above 63999",
+			input:       `10 GOSUB 99999`,
+			expectError: true,
+		},
+		{
+			name:        "ON GOTO target above 63999",
+			input:       `10 ON X GOTO 10,70000`,
+			expectError: true,
+		},
+		{
+			name:        "THEN line number above 63999",
+			input:       `10 IF X THEN 65535`,
+			expectError: true,
+		},
+		{
+			name:        "SWAP with one variable",
+			input:       `10 SWAP A`,
+			expectError: true,
+		},
 		{
 			name:        "invalid syntax",
 			input:       `10 INVALID "HELLO"`,
@@ -267,3 +402,103 @@ func TestParser_ArithmeticExpressions(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ParseStatements(t *testing.T) {
+	p := New(lexer.New(`PRINT "A": X = 1`))
+	stmts := p.ParseStatements()
+	require.Nil(t, p.ParseError())
+	assert.Equal(t, []Statement{
+		printStmt(str("A", 1), 1),
+		letStmt("X", num("1", 1), 1),
+	}, stmts)
+
+	p = New(lexer.New("PRINT 1\nPRINT 2"))
+	p.ParseStatements()
+	assert.NotNil(t, p.ParseError())
+}
+
+func TestParser_ParseExpression(t *testing.T) {
+	p := New(lexer.New("A*2+LEN(B$)"))
+	expr := p.ParseExpression()
+	require.Nil(t, p.ParseError())
+	assert.IsType(t, &BinaryOperation{}, expr)
+
+	for _, input := range []string{"", "A B", "(1", "A=1: PRINT"} {
+		p := New(lexer.New(input))
+		assert.Nil(t, p.ParseExpression(), input)
+		assert.NotNil(t, p.ParseError(), input)
+	}
+}
+
+func TestParser_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  lexer.Position
+	}{
+		{"10 PRINT )", lexer.Position{Line: 1, Column: 10, Offset: 9}},
+		{"10 PRINT 1\n\n  30 GOTO X", lexer.Position{Line: 3, Column: 11, Offset: 22}},
+		{"10 FOR I=1 TO 2\n20 X=(1+2", lexer.Position{Line: 2, Column: 10, Offset: 25}},
+		{"10 PRINT \"é\" ]", lexer.Position{Line: 1, Column: 14, Offset: 14}},
+		{"99999 END", lexer.Position{Line: 1, Column: 1, Offset: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.ParseProgram()
+			require.NotNil(t, p.ParseError())
+			assert.Equal(t, tt.want, p.ParseError().Position)
+		})
+	}
+}
+
+func TestParser_ErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []lexer.Position
+		lines map[int]int // statements kept on each line
+	}{
+		{
+			"one error per line",
+			"10 PRINT (1\n20 GOTO\n30 PRINT \"OK\"",
+			[]lexer.Position{{Line: 1, Column: 12, Offset: 11}, {Line: 2, Column: 8, Offset: 19}},
+			map[int]int{10: 0, 20: 0, 30: 1},
+		},
+		{
+			"statements after an error on the same line",
+			"10 X=: PRINT 1: Y=)",
+			[]lexer.Position{{Line: 1, Column: 6, Offset: 5}, {Line: 1, Column: 19, Offset: 18}},
+			map[int]int{10: 1},
+		},
+		{
+			"bad line numbers skip their line",
+			"PRINT 1: PRINT 2\n70000 END\n20 END",
+			[]lexer.Position{{Line: 1, Column: 1, Offset: 0}, {Line: 2, Column: 1, Offset: 17}},
+			map[int]int{20: 1},
+		},
+		{
+			"one error per statement",
+			"10 PRINT (((",
+			[]lexer.Position{{Line: 1, Column: 13, Offset: 12}},
+			map[int]int{10: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			var got []lexer.Position
+			for _, e := range p.ParseErrors() {
+				got = append(got, e.Position)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Same(t, p.ParseErrors()[0], p.ParseError())
+
+			lines := map[int]int{}
+			for _, line := range program.Lines {
+				lines[line.Number] = len(line.Statements)
+			}
+			assert.Equal(t, tt.lines, lines)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// ABOUTME: Test helper shared by the packages whose tests start from BASIC source text
+// ABOUTME: Parse turns a program into its AST and fails the test on any syntax error
+
+package parsertest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+// Parse parses src in the extended dialect with the shims for other 8-bit BASICs on, failing t on a syntax error
+func Parse(t testing.TB, src string) *parser.Program {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	p.SetShims(true)
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+	return program
+}
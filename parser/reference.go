@@ -0,0 +1,147 @@
+// ABOUTME: Reference metadata for BASIC statements, keywords and built-in functions
+// ABOUTME: The one authoritative registry behind REPL HELP, completion and generated documentation
+
+package parser
+
+import (
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/types"
+)
+
+// Kinds of reference entries
+const (
+	KindStatement = "statement"
+	KindKeyword   = "keyword"
+	KindFunction  = "function"
+)
+
+// Dialect sets used by the catalogue
+var (
+	allDialects  = []Dialect{DialectC64, DialectExtended}
+	extendedOnly = []Dialect{DialectExtended}
+)
+
+// Reference documents one statement, keyword or built-in function
+type Reference struct {
+	Name     string            `json:"name"`            // Keyword or function name, upper case
+	Kind     string            `json:"kind"`            // KindStatement, KindKeyword or KindFunction
+	Token    lexer.TokenType   `json:"token,omitempty"` // Lexer token for statements and keywords, "" for functions
+	Syntax   string            `json:"syntax"`          // Syntax summary
+	Summary  string            `json:"summary"`         // One-line description
+	Example  string            `json:"example"`         // Small runnable program demonstrating the entry
+	Dialects []Dialect         `json:"dialects"`        // Dialects that accept the entry
+	Args     []types.ValueType `json:"args,omitempty"`  // Argument types of a function, checked as calls are parsed
+}
+
+// InDialect reports whether the entry is available in the given dialect
+func (r Reference) InDialect(d Dialect) bool {
+	for _, have := range r.Dialects {
+		if have == d {
+			return true
+		}
+	}
+	return false
+}
+
+// statement builds the entry for a statement keyword
+func statement(name, syntax, summary, example string, dialects []Dialect) Reference {
+	return Reference{name, KindStatement, lexer.TokenType(name), syntax, summary, example, dialects, nil}
+}
+
+// keyword builds the entry for a keyword used inside other statements or expressions
+func keyword(name, syntax, summary, example string, dialects []Dialect) Reference {
+	return Reference{name, KindKeyword, lexer.TokenType(name), syntax, summary, example, dialects, nil}
+}
+
+// function builds the entry for a built-in function taking arguments of the given types
+func function(name, syntax, summary, example string, dialects []Dialect, args ...types.ValueType) Reference {
+	return Reference{name, KindFunction, "", syntax, summary, example, dialects, args}
+}
+
+// references is the catalogue of statements, keywords and functions, in that order
+var references = []Reference{
+	statement("PRINT", "PRINT [expr][;|,]...", "Write values to the screen; ';' joins, ',' moves to the next print zone", "10 PRINT \"A\";1,\"B\"", allDialects),
+	statement("LET", "[LET] var = expr", "Assign a value to a variable (LET is optional)", "10 LET A=2: B$=\"HI\"\n20 PRINT A;B$", allDialects),
+	statement("INPUT", "INPUT [\"prompt\";] var", "Read a value typed by the user", "10 INPUT \"NAME\";N$\n20 PRINT \"HELLO \";N$", allDialects),
+	statement("GET", "GET var[,var...]", "Read one keystroke without waiting; \"\" (or 0) when no key is pressed", "10 GET K$: IF K$=\"\" THEN 10\n20 PRINT \"KEY \";K$", allDialects),
+	statement("IF", "IF cond THEN stmt[:stmt...] | IF cond THEN line | IF cond GOTO line", "Run the rest of the line only when the condition is true", "10 A=5\n20 IF A>3 THEN PRINT \"BIG\": PRINT \"DONE\"", allDialects),
+	statement("GOTO", "GOTO line", "Continue execution at another line", "10 GOTO 30\n20 PRINT \"SKIPPED\"\n30 PRINT \"HERE\"", allDialects),
+	statement("GOSUB", "GOSUB line", "Call a subroutine that ends with RETURN", "10 GOSUB 100\n20 PRINT \"BACK\"\n30 END\n100 PRINT \"IN SUB\"\n110 RETURN", allDialects),
+	statement("RETURN", "RETURN", "Return from the most recent GOSUB", "10 GOSUB 100\n20 END\n100 PRINT \"SUB\"\n110 RETURN", allDialects),
+	statement("ON", "ON expr GOTO|GOSUB line[,line...]", "Jump to the n-th line of the list", "10 N=2\n20 ON N GOTO 30,40\n30 PRINT \"ONE\": END\n40 PRINT \"TWO\"", allDialects),
+	statement("FOR", "FOR var = start TO end [STEP n]", "Start a counted loop closed by NEXT", "10 FOR I=1 TO 3: PRINT I: NEXT I", allDialects),
+	statement("NEXT", "NEXT [var]", "Close a FOR loop", "10 FOR I=10 TO 0 STEP -5: PRINT I: NEXT", allDialects),
+	statement("WHILE", "WHILE cond ... WEND", "Repeat a block while the condition is true", "10 I=1\n20 WHILE I<4: PRINT I: I=I+1: WEND", extendedOnly),
+	statement("WEND", "WEND", "Close a WHILE loop", "10 WHILE I<2: I=I+1: PRINT I: WEND", extendedOnly),
+	statement("DO", "DO ... LOOP [WHILE|UNTIL cond]", "Start a loop whose body runs at least once", "10 DO: I=I+1: PRINT I: LOOP UNTIL I=3", extendedOnly),
+	statement("LOOP", "LOOP [WHILE|UNTIL cond]", "Close a DO loop, repeating while/until the condition holds", "10 DO: I=I+1: LOOP WHILE I<5\n20 PRINT I", extendedOnly),
+	statement("SWAP", "SWAP var, var", "Exchange the values of two variables or array elements of the same type", "10 A=1: B=2: SWAP A,B\n20 PRINT A;B", extendedOnly),
+	statement("CLR", "CLR", "Forget all variables, arrays, functions and loops, and rewind DATA", "10 A=5: CLR\n20 PRINT A", allDialects),
+	statement("TRON", "TRON", "Print the number of each line as it runs, as [10] [20]; TROFF stops", "10 TRON: PRINT \"A\"\n20 TROFF: PRINT \"B\"", extendedOnly),
+	statement("TROFF", "TROFF", "Stop printing line numbers started by TRON", "10 TRON: PRINT \"A\"\n20 TROFF: PRINT \"B\"", extendedOnly),
+	statement("EVERY", "EVERY ticks[,timer] GOSUB line", "Call a subroutine every ticks jiffies (1/60 s) between statements; timers 0-3, 3 first; 0 ticks stops the timer", "10 EVERY 30 GOSUB 100\n20 IF N<3 THEN 20\n30 END\n100 N=N+1: PRINT N: RETURN", extendedOnly),
+	statement("AFTER", "AFTER ticks[,timer] GOSUB line", "Call a subroutine once, ticks jiffies (1/60 s) from now; shares the timers of EVERY", "10 AFTER 60 GOSUB 100\n20 IF D=0 THEN 20\n30 END\n100 PRINT \"ONE SECOND\": D=1: RETURN", extendedOnly),
+	statement("NEW", "NEW", "Delete the program and all variables; in a program NEW also ends the run", "10 PRINT \"BYE\": NEW", allDialects),
+	statement("DIM", "DIM name(size[,size...])", "Declare an array; indexes run from 0 to size (arrays used without DIM get size 10)", "10 DIM A(3)\n20 A(3)=7: PRINT A(3)", allDialects),
+	statement("CONST", "CONST name = expr", "Define a read-only variable", "10 CONST PI=3.14159\n20 PRINT PI*2", extendedOnly),
+	statement("OPTION", "OPTION EXPLICIT", "Require variables to be introduced with LET, DIM or CONST before use", "10 OPTION EXPLICIT\n20 LET A=1: PRINT A", extendedOnly),
+	statement("DATA", "DATA const[,const...]", "Store constants to be read by READ; unquoted words are strings", "10 DATA 1,\"TWO\"\n20 READ A,B$: PRINT A;B$", allDialects),
+	statement("READ", "READ var[,var...]", "Assign the next DATA values to variables", "10 READ X,Y: PRINT X+Y\n20 DATA 3,4", allDialects),
+	statement("DEF", "DEF FNname(param) = expr", "Define a one-line numeric function", "10 DEF FNSQ(X)=X*X\n20 PRINT FNSQ(4)", allDialects),
+	statement("REM", "REM text", "Comment; the rest of the line is ignored. REM #REQUIRES extended, shims stops a program loading where a listed feature is missing", "10 REM THIS IS IGNORED\n20 PRINT \"OK\"", allDialects),
+	statement("END", "END", "Stop the program normally", "10 PRINT \"BYE\": END: PRINT \"NOT REACHED\"", allDialects),
+	statement("STOP", "STOP", "Halt the program", "10 PRINT \"HALT\": STOP", allDialects),
+	statement("RUN", "RUN [line]", "Clear all variables and run the program from the beginning or from a line", "10 PRINT \"RUNNING\"", allDialects),
+	statement("LIST", "LIST [from][-[to]]", "List the program, one line or a range such as 100-200, -50 or 100-; in a program LIST ends the run", "10 PRINT \"LISTING\"\n20 LIST 10", allDialects),
+	statement("POKE", "POKE address, value", "Store a byte (0-255) in memory (0-65535); screen codes POKEd to 1024-2023 appear on the screen", "10 POKE 1024,1: PRINT PEEK(1024)", allDialects),
+	statement("OPEN", "OPEN file[, device[, channel[, name$]]]", "Open a file number (1-255) on a device: 0 keyboard, 3 screen, 8 disk; PRINT#, INPUT# and GET# then use it and ST reports its status", "10 OPEN 1,3\n20 PRINT#1,\"TO THE SCREEN\"\n30 CLOSE 1", allDialects),
+	statement("CLOSE", "CLOSE file", "Close a file number opened by OPEN, finishing what PRINT# wrote", "10 OPEN 2,3: PRINT#2,\"A\";\"B\": CLOSE 2", allDialects),
+	statement("LOCATE", "LOCATE row, col", "Move the cursor to a row (1-25) and column (1-40) before printing; PRINT AT row,col; does the same counting from 0", "10 LOCATE 5,10: PRINT \"HERE\"", extendedOnly),
+
+	keyword("THEN", "IF cond THEN stmt|line", "Introduce what IF runs when its condition is true", "10 IF 1<2 THEN PRINT \"YES\"", allDialects),
+	keyword("TO", "FOR var = start TO end", "Give the final value of a FOR loop", "10 FOR I=1 TO 2: PRINT I: NEXT I", allDialects),
+	keyword("STEP", "FOR var = start TO end STEP n", "Give the increment of a FOR loop (default 1)", "10 FOR I=10 TO 1 STEP -3: PRINT I: NEXT I", allDialects),
+	keyword("AND", "a AND b", "Bitwise and; with comparisons, true only when both are true", "10 PRINT 1<2 AND 2<3", allDialects),
+	keyword("OR", "a OR b", "Bitwise or; with comparisons, true when either is true", "10 PRINT 1>2 OR 2<3", allDialects),
+	keyword("NOT", "NOT a", "Bitwise complement; NOT 0 is -1 (true)", "10 PRINT NOT 0", allDialects),
+	keyword("UNTIL", "LOOP UNTIL cond", "Repeat a DO loop until the condition becomes true", "10 DO: I=I+1: LOOP UNTIL I=3\n20 PRINT I", extendedOnly),
+
+	function("LEN", "LEN(s$)", "Number of characters in a string", "10 PRINT LEN(\"HELLO\")", allDialects, stringArg),
+	function("LEFT$", "LEFT$(s$, n)", "First n characters of a string", "10 PRINT LEFT$(\"HELLO\",2)", allDialects, stringArg, numberArg),
+	function("RIGHT$", "RIGHT$(s$, n)", "Last n characters of a string", "10 PRINT RIGHT$(\"HELLO\",3)", allDialects, stringArg, numberArg),
+	function("MID$", "MID$(s$, start, n)", "Substring starting at position start (1-based)", "10 PRINT MID$(\"HELLO\",2,3)", allDialects, stringArg, numberArg, numberArg),
+	function("CHR$", "CHR$(code)", "Character with the given code", "10 PRINT CHR$(65)", allDialects, numberArg),
+	function("ASC", "ASC(s$)", "Code of the first character", "10 PRINT ASC(\"A\")", allDialects, stringArg),
+	function("STR$", "STR$(n)", "Number converted to a string", "10 PRINT STR$(42)+\"!\"", allDialects, numberArg),
+	function("VAL", "VAL(s$)", "String converted to a number", "10 PRINT VAL(\"12\")+1", allDialects, stringArg),
+	function("RND", "RND(n)", "Random number between 0 and 1", "10 PRINT INT(RND(1)*6)+1", allDialects, numberArg),
+	function("ABS", "ABS(n)", "Absolute value", "10 PRINT ABS(-3)", allDialects, numberArg),
+	function("INT", "INT(n)", "Largest integer not greater than n", "10 PRINT INT(3.7);INT(-3.7)", allDialects, numberArg),
+	function("SQR", "SQR(n)", "Square root", "10 PRINT SQR(16)", allDialects, numberArg),
+	function("PEEK", "PEEK(address)", "Byte stored in memory at an address (0-65535)", "10 POKE 828,42: PRINT PEEK(828)", allDialects, numberArg),
+	function("TAB", "TAB(n)", "Spaces for aligning PRINT output", "10 PRINT \"A\";TAB(5);\"B\"", allDialects, numberArg),
+	function("SIN", "SIN(x)", "Sine of an angle in radians", "10 PRINT SIN(0)", allDialects, numberArg),
+	function("COS", "COS(x)", "Cosine of an angle in radians", "10 PRINT COS(0)", allDialects, numberArg),
+	function("TAN", "TAN(x)", "Tangent of an angle in radians", "10 PRINT TAN(0)", allDialects, numberArg),
+	function("ATN", "ATN(x)", "Arctangent in radians", "10 PRINT ATN(1)*4", allDialects, numberArg),
+	function("EXP", "EXP(x)", "e raised to the power x", "10 PRINT EXP(1)", allDialects, numberArg),
+	function("LOG", "LOG(x)", "Natural logarithm", "10 PRINT LOG(EXP(2))", allDialects, numberArg),
+}
+
+// References returns every documented statement, keyword and built-in function
+func References() []Reference {
+	return append([]Reference(nil), references...)
+}
+
+// LookupReference finds the reference entry for a keyword or function name (case-insensitive)
+func LookupReference(name string) (Reference, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for _, ref := range references {
+		if ref.Name == name {
+			return ref, true
+		}
+	}
+	return Reference{}, false
+}
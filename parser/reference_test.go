@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"basic-interpreter/lexer"
+)
+
+func TestReferences_CoverBuiltinsAndParse(t *testing.T) {
+	for _, name := range BuiltinFunctions() {
+		ref, ok := LookupReference(name)
+		assert.True(t, ok, "missing reference for %s", name)
+		assert.Equal(t, KindFunction, ref.Kind, name)
+	}
+
+	for _, ref := range References() {
+		p := New(lexer.New(ref.Example))
+		p.ParseProgram()
+		assert.Nil(t, p.ParseError(), "example for %s does not parse", ref.Name)
+		assert.NotEmpty(t, ref.Syntax, ref.Name)
+		assert.NotEmpty(t, ref.Summary, ref.Name)
+	}
+}
+
+func TestLookupReference(t *testing.T) {
+	ref, ok := LookupReference(" print ")
+	assert.True(t, ok)
+	assert.Equal(t, "PRINT", ref.Name)
+	assert.Equal(t, KindStatement, ref.Kind)
+
+	_, ok = LookupReference("PRNT")
+	assert.False(t, ok)
+}
+
+func TestReferences_CoverKeywords(t *testing.T) {
+	for _, word := range lexer.Keywords() {
+		ref, ok := LookupReference(word)
+		if !assert.True(t, ok, "missing reference for %s", word) {
+			continue
+		}
+		assert.Equal(t, lexer.New(word).NextToken().Type, ref.Token, word)
+	}
+	for _, ref := range References() {
+		assert.NotEmpty(t, ref.Dialects, ref.Name)
+	}
+}
+
+func TestReference_InDialect(t *testing.T) {
+	printRef, _ := LookupReference("PRINT")
+	assert.True(t, printRef.InDialect(DialectC64))
+	assert.True(t, printRef.InDialect(DialectExtended))
+
+	whileRef, _ := LookupReference("WHILE")
+	assert.False(t, whileRef.InDialect(DialectC64))
+	assert.True(t, whileRef.InDialect(DialectExtended))
+}
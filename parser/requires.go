@@ -0,0 +1,61 @@
+// ABOUTME: REM #REQUIRES pragma: a program names the interpreter features it needs, checked when it is parsed
+// ABOUTME: A program needing a missing feature fails to load with a message naming it, instead of failing mid-run
+
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"basic-interpreter/lexer"
+)
+
+// RequiresPragma is the REM text that starts a feature requirement: 10 REM #REQUIRES extended, shims
+const RequiresPragma = "#REQUIRES"
+
+// Features beyond the dialects
+const (
+	featureShims = "shims" // Provided only when the shims are enabled
+	featureFiles = "files" // OPEN, CLOSE, PRINT#, INPUT# and GET#, in every dialect
+)
+
+// Features returns the features this parser provides, sorted: the dialects it accepts programs of, files, plus
+// shims when enabled. The extended dialect accepts C64 programs as well.
+func (p *Parser) Features() []string {
+	features := []string{string(DialectC64), featureFiles}
+	if p.dialect == DialectExtended {
+		features = append(features, string(DialectExtended))
+	}
+	if p.shims {
+		features = append(features, featureShims)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// parseRequires checks the feature list of a REM #REQUIRES pragma at the current token, the # after REM.
+// It reports false with a parse error when a required feature is missing.
+func (p *Parser) parseRequires() bool {
+	if p.currentToken.Literal != "#" || !strings.EqualFold(p.peekToken.Literal, RequiresPragma[1:]) {
+		return true
+	}
+	p.nextToken() // consume #
+	provided := p.Features()
+	var missing []string
+	for p.peekToken.Type != lexer.NEWLINE && p.peekToken.Type != lexer.EOF {
+		p.nextToken()
+		if p.currentToken.Type == lexer.COMMA {
+			continue
+		}
+		feature := strings.ToLower(p.currentToken.Literal)
+		if i := sort.SearchStrings(provided, feature); i == len(provided) || provided[i] != feature {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) > 0 {
+		p.addErrorf("program requires %s, not supported by this interpreter (supported: %s)",
+			strings.Join(missing, ", "), strings.Join(provided, ", "))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_RequiresPragma(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		shims   bool
+		wantErr string
+	}{
+		{name: "provided features", input: "10 REM #REQUIRES c64, extended"},
+		{name: "files", input: "10 REM #REQUIRES extended, files"},
+		{name: "lowercase", input: "10 rem #requires extended"},
+		{name: "after a statement", input: "10 PRINT 1: REM #REQUIRES extended"},
+		{name: "empty list", input: "10 REM #REQUIRES"},
+		{name: "plain comment", input: "10 REM #1 FILES"},
+		{name: "shims enabled", input: "10 REM #REQUIRES shims", shims: true},
+		{name: "shims disabled", input: "10 REM #REQUIRES shims",
+			wantErr: "program requires shims, not supported by this interpreter (supported: c64, extended, files)"},
+		{name: "several missing", input: "10 REM #REQUIRES sound, extended, sprites",
+			wantErr: "program requires sound, sprites, not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.SetShims(tt.shims)
+			program := p.ParseProgram()
+			if tt.wantErr != "" {
+				require.NotNil(t, p.ParseError())
+				assert.Contains(t, p.ParseError().Message, tt.wantErr)
+				assert.Equal(t, 1, p.ParseError().Position.Line)
+				return
+			}
+			require.Nil(t, p.ParseError())
+			require.Len(t, program.Lines, 1)
+		})
+	}
+}
+
+func TestParser_Features(t *testing.T) {
+	p := New(lexer.New(""))
+	assert.Equal(t, []string{"c64", "extended", "files"}, p.Features())
+	p.SetShims(true)
+	assert.Equal(t, []string{"c64", "extended", "files", "shims"}, p.Features())
+	p.SetDialect(DialectC64)
+	assert.Equal(t, []string{"c64", "files", "shims"}, p.Features())
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Compatibility shims for listings written for other 8-bit BASICs (Apple II, ZX Spectrum, BBC Micro)
+// ABOUTME: Foreign statements become existing AST nodes; those without an equivalent get a parse error naming the machine
+
+package parser
+
+import (
+	"strings"
+
+	"basic-interpreter/lexer"
+)
+
+// foreignStatement describes a statement word from another dialect
+type foreignStatement struct {
+	machine     string
+	unsupported string // Why the statement cannot be translated, "" when it can
+}
+
+// foreignStatements lists the statement words the shims recognize; they stay ordinary names otherwise
+var foreignStatements = map[string]foreignStatement{
+	"HOME":   {machine: "Apple II"},
+	"CLS":    {machine: "ZX Spectrum and BBC Micro"},
+	"HTAB":   {machine: "Apple II", unsupported: "use LOCATE row,column instead"},
+	"VTAB":   {machine: "Apple II", unsupported: "use LOCATE row,column instead"},
+	"BORDER": {machine: "ZX Spectrum", unsupported: "colours are not supported"},
+	"PAPER":  {machine: "ZX Spectrum", unsupported: "colours are not supported"},
+	"INK":    {machine: "ZX Spectrum", unsupported: "colours are not supported"},
+	"COLOUR": {machine: "BBC Micro", unsupported: "colours are not supported"},
+	"MODE":   {machine: "BBC Micro", unsupported: "screen modes are not supported"},
+	"VDU":    {machine: "BBC Micro", unsupported: "VDU codes are not supported"},
+	"BEEP":   {machine: "ZX Spectrum", unsupported: "sound is not supported"},
+	"SOUND":  {machine: "BBC Micro", unsupported: "sound is not supported"},
+}
+
+// SetShims accepts statements from other 8-bit dialects: HOME and CLS clear the screen and IF takes an ELSE branch
+func (p *Parser) SetShims(enabled bool) {
+	p.shims = enabled
+}
+
+// parseForeignStatement translates a foreign statement at the current identifier.
+// It reports false when the identifier starts an ordinary assignment instead.
+func (p *Parser) parseForeignStatement() (Statement, bool) {
+	word := strings.ToUpper(p.currentToken.Literal)
+	foreign, ok := foreignStatements[word]
+	if !ok || p.peekToken.Type == lexer.ASSIGN || p.peekToken.Type == lexer.LPAREN {
+		return nil, false
+	}
+	if foreign.unsupported != "" {
+		p.addErrorf("%s (%s) is not translated: %s", word, foreign.machine, foreign.unsupported)
+		return nil, true
+	}
+	return &ClearScreenStatement{}, true
+}
+
+// isElse reports whether tok is the ELSE of an IF statement, part of the extended dialect and of the shims
+func (p *Parser) isElse(tok lexer.Token) bool {
+	return (p.shims || p.dialect == DialectExtended) && tok.Type == lexer.IDENT && strings.EqualFold(tok.Literal, "ELSE")
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/types"
+)
+
+func parseWithShims(input string) (*Program, *ParseError) {
+	p := New(lexer.New(input))
+	p.SetShims(true)
+	program := p.ParseProgram()
+	return program, p.ParseError()
+}
+
+func TestShims_Translations(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Statement
+	}{
+		{"HOME", "10 HOME", []Statement{&ClearScreenStatement{}}},
+		{"CLS in a statement list", "10 cls: END", []Statement{&ClearScreenStatement{}, &EndStatement{}}},
+		{"foreign word as variable", "10 CLS=1", []Statement{&LetStatement{Variable: "CLS", Expression: num("1", 1)}}},
+		{
+			name:  "ELSE after a THEN statement",
+			input: `10 IF A THEN PRINT "Y" ELSE B=2: GOTO 10`,
+			expected: []Statement{&IfStatement{
+				Condition: &VariableReference{Name: "A"},
+				ThenStmts: []Statement{&PrintStatement{Expression: &StringLiteral{Value: "Y"}}},
+				ElseStmts: []Statement{&LetStatement{Variable: "B", Expression: num("2", 1)}, &GotoStatement{TargetLine: 10}},
+			}},
+		},
+		{
+			name:  "ELSE after a colon with line numbers",
+			input: "10 IF A THEN 100: ELSE 200",
+			expected: []Statement{&IfStatement{
+				Condition: &VariableReference{Name: "A"},
+				ThenStmts: []Statement{&GotoStatement{TargetLine: 100}},
+				ElseStmts: []Statement{&GotoStatement{TargetLine: 200}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := parseWithShims(tt.input)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, program.Lines[0].Statements)
+		})
+	}
+}
+
+func TestShims_UntranslatableStatements(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{"10 HTAB 5", "HTAB (Apple II) is not translated: use LOCATE row,column instead"},
+		{"10 BORDER 0", "BORDER (ZX Spectrum) is not translated: colours are not supported"},
+		{"10 vdu 7", "VDU (BBC Micro) is not translated: VDU codes are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseWithShims(tt.input)
+			require.NotNil(t, err)
+			assert.Equal(t, tt.message, err.Message)
+		})
+	}
+}
+
+func TestShims_OffByDefault(t *testing.T) {
+	p := New(lexer.New("10 HOME"))
+	p.ParseProgram()
+	assert.NotNil(t, p.ParseError())
+}
+
+func TestShims_ElseInC64Dialect(t *testing.T) {
+	input := "10 IF A THEN PRINT 1 ELSE PRINT 2"
+	p := New(lexer.New(input))
+	p.SetDialect(DialectC64)
+	p.ParseProgram()
+	assert.NotNil(t, p.ParseError(), "ELSE is an extension")
+
+	p = New(lexer.New(input))
+	p.SetDialect(DialectC64)
+	p.SetShims(true)
+	p.ParseProgram()
+	assert.Nil(t, p.ParseError(), "the shims accept ELSE")
+}
+
+func TestIfStatement_ExecutesElseBranch(t *testing.T) {
+	mock := newMockOps()
+	mock.setVariable("A", types.NewNumberValue(0))
+	stmt := &IfStatement{
+		Condition: &VariableReference{Name: "A"},
+		ThenStmts: []Statement{&PrintStatement{Expression: &StringLiteral{Value: "THEN"}}},
+		ElseStmts: []Statement{&PrintStatement{Expression: &StringLiteral{Value: "ELSE"}}, &ClearScreenStatement{}},
+	}
+
+	require.NoError(t, stmt.Execute(mock))
+	assert.Equal(t, []string{"ELSE"}, mock.getOutput())
+	assert.Equal(t, 1, mock.clears)
+}
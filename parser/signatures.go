@@ -0,0 +1,100 @@
+// ABOUTME: Argument signatures of the built-in functions, from the reference catalogue, checked as calls are parsed
+// ABOUTME: Wrong argument counts and literal arguments of the wrong type are reported before the program runs
+
+package parser
+
+import (
+	"strings"
+
+	"basic-interpreter/types"
+)
+
+// Argument types of built-in function signatures
+const (
+	stringArg = types.StringType
+	numberArg = types.NumberType
+)
+
+// signatures gives the argument types of each built-in function, from its reference entry
+var signatures = builtinSignatures()
+
+// builtinSignatures collects the argument types of the functions in the reference catalogue
+func builtinSignatures() map[string][]types.ValueType {
+	sigs := make(map[string][]types.ValueType)
+	for _, ref := range references {
+		if ref.Kind == KindFunction {
+			sigs[ref.Name] = ref.Args
+		}
+	}
+	return sigs
+}
+
+// checkBuiltinCall reports a call to a built-in function with the wrong number of arguments, or with an
+// argument whose type is known without running the program and does not match
+func (p *Parser) checkBuiltinCall(call *FunctionCall) bool {
+	name := strings.ToUpper(call.FunctionName)
+	params, ok := signatures[name]
+	if !ok {
+		return true
+	}
+	if len(call.Arguments) != len(params) {
+		plural := "s"
+		if len(params) == 1 {
+			plural = ""
+		}
+		p.addErrorf("?SYNTAX ERROR: %s requires exactly %d argument%s", name, len(params), plural)
+		return false
+	}
+	for n, arg := range call.Arguments {
+		if t, known := staticType(arg); known && t != params[n] {
+			p.addErrorf("?TYPE MISMATCH ERROR: %s argument %d must be %s", name, n+1, typeName(params[n]))
+			return false
+		}
+	}
+	return true
+}
+
+// staticType returns the type of an expression when it is known without evaluating it:
+// literals, negations, variables and array elements (by their $ suffix) and built-in function calls (by their name)
+func staticType(expr Expression) (types.ValueType, bool) {
+	switch e := expr.(type) {
+	case *StringLiteral:
+		return stringArg, true
+	case *NumberLiteral:
+		return numberArg, true
+	case *Constant:
+		return e.Value.Type, true
+	case *VariableReference:
+		return nameType(e.Name), true
+	case *ArrayReference:
+		return nameType(e.Name), true
+	case *UnaryOperation:
+		if e.Operator == "-" {
+			return staticType(e.Right)
+		}
+	case *FunctionCall:
+		if e == nil {
+			break // The call failed to parse
+		}
+		if _, builtin := signatures[strings.ToUpper(e.FunctionName)]; builtin {
+			return nameType(e.FunctionName), true
+		}
+	}
+	return 0, false
+}
+
+// nameType returns the type of a variable or built-in function from its name
+func nameType(name string) types.ValueType {
+	if strings.HasSuffix(name, "$") {
+		return stringArg
+	}
+	return numberArg
+}
+
+// typeName names a value type in diagnostics
+func typeName(t types.ValueType) string {
+	if t == stringArg {
+		return "a string"
+	}
+	return "a number"
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_BuiltinSignatures(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string // "" when the program parses
+	}{
+		{name: "matching arguments", input: `10 PRINT MID$(A$,2,3)+LEFT$("AB",1)`},
+		{name: "types known only at run time", input: `10 PRINT LEN(A$+B$),ABS(FNA(1)),LEN(X$(2))`},
+		{name: "string function result", input: `10 PRINT LEN(STR$(5)),VAL(CHR$(65))`},
+		{name: "too few arguments", input: `10 PRINT MID$("HELLO",2)`, err: "?SYNTAX ERROR: MID$ requires exactly 3 arguments"},
+		{name: "too many arguments", input: `10 PRINT LEN("A","B")`, err: "?SYNTAX ERROR: LEN requires exactly 1 argument"},
+		{name: "no arguments", input: `10 PRINT RND()`, err: "?SYNTAX ERROR: RND requires exactly 1 argument"},
+		{name: "number literal for string", input: `10 PRINT LEN(5)`, err: "?TYPE MISMATCH ERROR: LEN argument 1 must be a string"},
+		{name: "negative number for string", input: `10 PRINT ASC(-1)`, err: "?TYPE MISMATCH ERROR: ASC argument 1 must be a string"},
+		{name: "string literal for number", input: `10 PRINT PEEK("A")`, err: "?TYPE MISMATCH ERROR: PEEK argument 1 must be a number"},
+		{name: "string variable for number", input: `10 PRINT LEFT$("AB",N$)`, err: "?TYPE MISMATCH ERROR: LEFT$ argument 2 must be a number"},
+		{name: "numeric array for string", input: `10 PRINT VAL(A(1))`, err: "?TYPE MISMATCH ERROR: VAL argument 1 must be a string"},
+		{name: "nested call result", input: `10 PRINT LEN(ASC("A"))`, err: "?TYPE MISMATCH ERROR: LEN argument 1 must be a string"},
+		{name: "reported on its line", input: "10 PRINT 1\n20 X=INT(\"A\")", err: "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			if tt.err == "" {
+				require.Nil(t, p.ParseError())
+				require.NotNil(t, program)
+				return
+			}
+			require.NotNil(t, p.ParseError())
+			assert.Contains(t, p.ParseError().Error(), tt.err)
+		})
+	}
+}
+
+func TestBuiltinSignatures_CoverEveryBuiltin(t *testing.T) {
+	for _, name := range builtinFunctions {
+		assert.Contains(t, signatures, name)
+	}
+	assert.Len(t, signatures, len(builtinFunctions))
+}
@@ -0,0 +1,78 @@
+// ABOUTME: "Did you mean" hints for misspelled statements and built-in functions in parse errors
+// ABOUTME: The closest known name by edit distance is suggested when it is within one edit per three characters
+
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// suggestion returns " (did you mean NAME?)" for the candidate closest to name, or "" when none is close enough.
+// Ties go to the earlier candidate.
+func suggestion(name string, candidates []string) string {
+	name = strings.ToUpper(name)
+	best, bestDistance := "", len(name)/3+1
+	for _, candidate := range candidates {
+		if d := editDistance(name, candidate); d > 0 && d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of adjacent characters that turn a into b
+func editDistance(a, b string) int {
+	// rows[i][j] is the distance between the first i characters of a and the first j of b
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// statementNames lists the statements a misspelled one may be meant as: the catalogue's, then the host's
+func (p *Parser) statementNames() []string {
+	var names []string
+	for _, ref := range references {
+		if ref.Kind == KindStatement {
+			names = append(names, ref.Name)
+		}
+	}
+	return append(names, sortedNames(p.hostStatements)...)
+}
+
+// functionNames lists the built-in functions, then the host's
+func (p *Parser) functionNames() []string {
+	return append(BuiltinFunctions(), sortedNames(p.hostFunctions)...)
+}
+
+// sortedNames returns the keys of a name set in order, so suggestions do not depend on map order
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"PRINT", "PRINT", 0},
+		{"PRNT", "PRINT", 1},
+		{"PIRNT", "PRINT", 1},
+		{"LEFTT$", "LEFT$", 1},
+		{"GOSUP", "GOSUB", 1},
+		{"", "END", 3},
+		{"SCORE", "STOP", 3},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, editDistance(tt.a, tt.b), "%s to %s", tt.a, tt.b)
+	}
+}
+
+func TestSuggestion(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"prnt", " (did you mean PRINT?)"},
+		{"RETRUN", " (did you mean RETURN?)"},
+		{"I", ""},     // too short to be a misspelling
+		{"SCORE", ""}, // nothing close
+		{"PRINT", ""}, // already a known name
+		{"DIMM", " (did you mean DIM?)"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, suggestion(tt.name, New(lexer.New("")).statementNames()), tt.name)
+	}
+}
+
+func TestParser_Suggestions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		col   int
+	}{
+		{"10 X=1: PRNT X", "unrecognized statement PRNT (did you mean PRINT?)", 9},
+		{"10 NXT I", "unrecognized statement NXT (did you mean NEXT?)", 4},
+		{"10 PRINT LEFTT$(A$,2)", "unknown function LEFTT$ (did you mean LEFT$?)", 10},
+		{"10 A$=MIDD$(\"ABC\",2,1)", "unknown function MIDD$ (did you mean MID$?)", 7},
+		{"10 SCORE 5", "expected '=' after variable name, got NUMBER (token \"5\")", 10},
+		{"10 LET PRNT 5", "expected '=' after variable name, got NUMBER (token \"5\")", 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.ParseProgram()
+			require.NotNil(t, p.ParseError())
+			assert.Equal(t, tt.want, p.ParseError().Message)
+			assert.Equal(t, tt.col, p.ParseError().Position.Column)
+		})
+	}
+}
+
+func TestParser_ArraysCloseToFunctionNamesStillParse(t *testing.T) {
+	p := New(lexer.New("10 DIM INTT(5): INTT(1)=2: PRINT INTT(1);LEFTT$(1)"))
+	p.ParseProgram()
+	assert.Nil(t, p.ParseError())
+}
+
+func TestParser_SuggestsHostNames(t *testing.T) {
+	p := New(lexer.New("10 BEEEP\n20 X=SOUNDD(\"A\")"))
+	p.SetHostStatements([]string{"BEEP"})
+	p.SetHostFunctions([]string{"SOUND"})
+	p.ParseProgram()
+	require.Len(t, p.ParseErrors(), 2)
+	assert.Equal(t, "unrecognized statement BEEEP (did you mean BEEP?)", p.ParseErrors()[0].Message)
+	assert.Equal(t, "unknown function SOUNDD (did you mean SOUND?)", p.ParseErrors()[1].Message)
+}
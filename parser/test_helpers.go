@@ -7,12 +7,25 @@ func program(lines ...*Line) *Program { return &Program{Lines: lines} }
 
 func line(num int, _ int, stmts ...Statement) *Line { return &Line{Number: num, Statements: stmts} }
 
+// statementsOnly clears the source text of parsed lines, for tests comparing only their statements
+func statementsOnly(p *Program) *Program {
+	for _, l := range p.Lines {
+		l.Source = ""
+	}
+	return p
+}
+
 func printStmt(expr Expression, _ int) *PrintStatement { return &PrintStatement{Expression: expr} }
 
 func letStmt(variable string, expr Expression, _ int) *LetStatement {
 	return &LetStatement{Variable: variable, Expression: expr}
 }
 
+// declaredLetStmt is an assignment written with the LET keyword
+func declaredLetStmt(variable string, expr Expression) *LetStatement {
+	return &LetStatement{Variable: variable, Expression: expr, Declared: true}
+}
+
 func endStmt(_ int) *EndStatement { return &EndStatement{} }
 
 func runStmt(_ int) *RunStatement { return &RunStatement{} }
@@ -26,11 +39,11 @@ func gosubStmt(targetLine int, _ int) *GosubStatement { return &GosubStatement{T
 func returnStmt(_ int) *ReturnStatement { return &ReturnStatement{} }
 
 func ifStmt(condition Expression, thenStmt Statement, _ int) *IfStatement {
-	return &IfStatement{Condition: condition, ThenStmt: thenStmt}
+	return &IfStatement{Condition: condition, ThenStmts: []Statement{thenStmt}}
 }
 
 func inputStmt(prompt string, variable string, _ int) *InputStatement {
-	return &InputStatement{Prompt: prompt, Variable: variable}
+	return &InputStatement{Prompt: prompt, Targets: []ReadTarget{{Name: variable}}}
 }
 
 func str(value string, _ int) *StringLiteral { return &StringLiteral{Value: value} }
@@ -43,7 +56,7 @@ func binaryOp(left Expression, operator string, right Expression, _ int) *Binary
 	return &BinaryOperation{Left: left, Operator: operator, Right: right}
 }
 
-func remStmt(_ int) *RemStatement { return &RemStatement{} }
+func remStmt(text string, _ int) *RemStatement { return &RemStatement{Text: text} }
 
 func funcCall(name string, args []Expression, _ int) *FunctionCall {
 	return &FunctionCall{FunctionName: name, Arguments: args}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_TimerStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *TimerStatement
+		timer    int
+		ticks    int
+	}{
+		{
+			input:    "10 EVERY 50 GOSUB 1000",
+			expected: &TimerStatement{Repeat: true, Ticks: num("50", 50), TargetLine: 1000},
+			ticks:    50,
+		},
+		{
+			input:    "10 AFTER 2*T,3 GOSUB 200",
+			expected: &TimerStatement{Ticks: &BinaryOperation{Left: num("2", 2), Operator: "*", Right: &VariableReference{Name: "T"}}, Timer: num("3", 3), TargetLine: 200},
+			timer:    3,
+			ticks:    0,
+		},
+		{
+			input:    "10 AFTER (4+5) GOSUB 200",
+			expected: &TimerStatement{Ticks: &BinaryOperation{Left: num("4", 4), Operator: "+", Right: num("5", 5)}, TargetLine: 200},
+			ticks:    9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			require.Nil(t, p.ParseError())
+			stmt := program.Lines[0].Statements[0]
+			assert.Equal(t, tt.expected, stmt)
+
+			ops := newMockOps()
+			require.NoError(t, stmt.Execute(ops))
+			assert.Equal(t, mockTimer{ticks: tt.ticks, repeat: tt.expected.Repeat, target: tt.expected.TargetLine}, ops.timers[tt.timer])
+		})
+	}
+}
+
+func TestParser_TimerStatementErrors(t *testing.T) {
+	for _, input := range []string{"10 EVERY 50", "10 EVERY 50 GOTO 100", "10 AFTER GOSUB 100", "10 AFTER 5,1 GOSUB", "10 EVERY 5, GOSUB 100"} {
+		t.Run(input, func(t *testing.T) {
+			p := New(lexer.New(input))
+			p.ParseProgram()
+			assert.NotNil(t, p.ParseError())
+		})
+	}
+}
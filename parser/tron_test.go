@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+func TestParser_TronTroff(t *testing.T) {
+	p := New(lexer.New("10 TRON: TROFF"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+	assert.Equal(t, []Statement{&TraceStatement{On: true}, &TraceStatement{On: false}}, program.Lines[0].Statements)
+
+	ops := newMockOps()
+	require.NoError(t, program.Lines[0].Statements[0].Execute(ops))
+	assert.True(t, ops.lineTrace)
+	require.NoError(t, program.Lines[0].Statements[1].Execute(ops))
+	assert.False(t, ops.lineTrace)
+}
@@ -0,0 +1,164 @@
+// ABOUTME: Syntax tree traversal: Walk calls a Visitor on every node in source order, Inspect calls a function
+// ABOUTME: Linters and analyses use these rather than their own recursive switch over every node type
+
+package parser
+
+import "fmt"
+
+// Node is a *Program, a *Line, a Statement or an Expression
+type Node any
+
+// A Visitor's Visit method is called for each node Walk encounters. If it returns a non-nil visitor w, Walk visits
+// each child of the node with w, then calls w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a syntax tree depth first, children in the order they appear in the source. The cursor of
+// PRINT AT is visited as its row and column expressions, not as a LOCATE statement; IF visits its condition, then
+// its THEN and ELSE statements. A nil node is skipped.
+func Walk(node Node, v Visitor) {
+	if node == nil {
+		return
+	}
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, line := range n.Lines {
+			Walk(line, v)
+		}
+	case *Line:
+		walkStatements(v, n.Statements...)
+
+	// Statements
+	case *PrintStatement:
+		if n.At != nil {
+			walkExpressions(v, n.At.Row, n.At.Column)
+		}
+		walkExpressions(v, n.Expression)
+		walkExpressions(v, n.Items...)
+	case *LetStatement:
+		walkExpressions(v, n.Expression)
+	case *ConstStatement:
+		walkExpressions(v, n.Expression)
+	case *ArraySetStatement:
+		walkExpressions(v, n.Indexes...)
+		walkExpressions(v, n.Expression)
+	case *ForStatement:
+		walkExpressions(v, n.StartValue, n.EndValue, n.StepValue)
+	case *IfStatement:
+		walkExpressions(v, n.Condition)
+		walkStatements(v, n.ThenStmts...)
+		walkStatements(v, n.ElseStmts...)
+	case *WhileStatement:
+		walkExpressions(v, n.Condition)
+	case *LoopStatement:
+		walkExpressions(v, n.Condition)
+	case *OnGotoStatement:
+		walkExpressions(v, n.Selector)
+	case *OnGosubStatement:
+		walkExpressions(v, n.Selector)
+	case *LocateStatement:
+		walkExpressions(v, n.Row, n.Column)
+	case *PokeStatement:
+		walkExpressions(v, n.Address, n.Value)
+	case *TimerStatement:
+		walkExpressions(v, n.Ticks, n.Timer)
+	case *DimStatement:
+		for _, d := range n.Declarations {
+			walkExpressions(v, d.Sizes...)
+		}
+	case *HostStatement:
+		walkExpressions(v, n.Arguments...)
+	case *InputStatement:
+		walkTargets(v, n.Targets...)
+	case *ReadStatement:
+		walkTargets(v, n.Targets...)
+	case *GetStatement:
+		walkTargets(v, n.Targets...)
+	case *OpenStatement:
+		walkExpressions(v, n.File, n.Device, n.Channel, n.Name)
+	case *CloseStatement:
+		walkExpressions(v, n.File)
+	case *PrintFileStatement:
+		walkExpressions(v, n.File)
+		walkExpressions(v, n.Items...)
+	case *InputFileStatement:
+		walkExpressions(v, n.File)
+		walkTargets(v, n.Targets...)
+	case *GetFileStatement:
+		walkExpressions(v, n.File)
+		walkTargets(v, n.Targets...)
+	case *SwapStatement:
+		walkTargets(v, n.Left, n.Right)
+	case *DataStatement:
+		walkExpressions(v, n.Values...)
+	case *DefFnStatement:
+		walkExpressions(v, n.Body)
+	case *GotoStatement, *GosubStatement, *ReturnStatement, *EndStatement, *StopStatement, *RemStatement,
+		*ClrStatement, *NewStatement, *RunStatement, *ListStatement, *TraceStatement, *ClearScreenStatement,
+		*NextStatement, *WendStatement, *DoStatement, *OptionStatement:
+		// No children
+
+	// Expressions
+	case *BinaryOperation:
+		walkExpressions(v, n.Left, n.Right)
+	case *ComparisonExpression:
+		walkExpressions(v, n.Left, n.Right)
+	case *UnaryOperation:
+		walkExpressions(v, n.Right)
+	case *FunctionCall:
+		walkExpressions(v, n.Arguments...)
+	case *ArrayReference:
+		walkExpressions(v, n.Indices...)
+	case *VariableReference, *NumberLiteral, *StringLiteral, *Constant:
+		// No children
+
+	default:
+		panic(fmt.Sprintf("parser.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+// walkStatements walks each statement of a list
+func walkStatements(v Visitor, stmts ...Statement) {
+	for _, stmt := range stmts {
+		Walk(stmt, v)
+	}
+}
+
+// walkExpressions walks expressions, skipping absent ones such as a FOR without STEP
+func walkExpressions(v Visitor, exprs ...Expression) {
+	for _, expr := range exprs {
+		if expr != nil {
+			Walk(expr, v)
+		}
+	}
+}
+
+// walkTargets walks the index expressions of array elements filled by INPUT, READ, GET, their file forms and SWAP
+func walkTargets(v Visitor, targets ...ReadTarget) {
+	for _, t := range targets {
+		walkExpressions(v, t.Indices...)
+	}
+}
+
+// inspector adapts a function to the Visitor interface
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a syntax tree in the order of Walk, calling f(node) for each node; when f returns true, Inspect
+// goes on to the node's children, then calls f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, inspector(f))
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+)
+
+// nodeNames lists the nodes Inspect visits under node, by type and name or value
+func nodeNames(node Node) []string {
+	var names []string
+	Inspect(node, func(n Node) bool {
+		switch n := n.(type) {
+		case nil:
+		case *VariableReference:
+			names = append(names, n.Name)
+		case *ArrayReference:
+			names = append(names, n.Name+"()")
+		case *NumberLiteral:
+			names = append(names, n.Value)
+		case *StringLiteral:
+			names = append(names, fmt.Sprintf("%q", n.Value))
+		default:
+			names = append(names, strings.TrimPrefix(fmt.Sprintf("%T", n), "*parser."))
+		}
+		return true
+	})
+	return names
+}
+
+func TestInspect_SourceOrder(t *testing.T) {
+	p := New(lexer.New("10 FOR I=1 TO N STEP 2: PRINT AT 3,4; A(I)+1\n20 IF X THEN PRINT \"Y\" ELSE 10"))
+	p.SetShims(true)
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	assert.Equal(t, []string{
+		"Program",
+		"Line", "ForStatement", "1", "N", "2", "PrintStatement", "3", "4", "BinaryOperation", "A()", "I", "1",
+		"Line", "IfStatement", "X", "PrintStatement", `"Y"`, "GotoStatement",
+	}, nodeNames(program))
+}
+
+func TestInspect_SkipsChildrenWhenToldTo(t *testing.T) {
+	p := New(lexer.New("10 DEF FNA(X)=X*Y: PRINT FNA(Z)"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	var read []string
+	Inspect(program, func(n Node) bool {
+		if v, ok := n.(*VariableReference); ok {
+			read = append(read, v.Name)
+		}
+		_, isDef := n.(*DefFnStatement)
+		return !isDef
+	})
+	assert.Equal(t, []string{"Z"}, read)
+}
+
+// depthVisitor records the depth of each node, returning to its parent's depth on Visit(nil)
+type depthVisitor struct {
+	depth  *int
+	depths *[]int
+}
+
+func (v depthVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		*v.depth--
+		return nil
+	}
+	*v.depths = append(*v.depths, *v.depth)
+	*v.depth++
+	return v
+}
+
+func TestWalk_CallsVisitNilAfterChildren(t *testing.T) {
+	p := New(lexer.New("10 PRINT -(1+2)"))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	depth, depths := 0, []int{}
+	Walk(program, depthVisitor{&depth, &depths})
+	// Program, Line, PRINT, negation, sum, 1, 2
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 5}, depths)
+	assert.Zero(t, depth)
+}
+
+// TestWalk_VisitsEveryNodeType walks a program using every statement and expression
+func TestWalk_VisitsEveryNodeType(t *testing.T) {
+	src := strings.Join([]string{
+		`10 PRINT "A";1,X: PRINT AT 1,2;"B": LET A=1: B=2: CONST C=3: OPTION EXPLICIT: A(1)=-A`,
+		`20 FOR I=1 TO 2 STEP 1: NEXT I: IF A<B AND C THEN GOTO 10 ELSE GOSUB 10`,
+		`30 WHILE A: WEND: DO: LOOP UNTIL B: ON A GOTO 10: ON A GOSUB 10: LOCATE 1,1: POKE 1,2`,
+		`40 EVERY 1 GOSUB 10: DIM D(2): BEEP 1: INPUT A(1): READ B: GET C$: SWAP A,B: DATA 1,"X"`,
+		`50 DEF FNF(X)=LEN(X$)+A(1): RETURN: END: STOP: CLR: NEW: RUN: LIST: TRON: HOME: REM HI`,
+	}, "\n")
+	p := New(lexer.New(src))
+	p.SetShims(true)
+	p.SetHostStatements([]string{"BEEP"})
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	seen := map[string]bool{}
+	Inspect(program, func(n Node) bool {
+		if n != nil {
+			seen[fmt.Sprintf("%T", n)] = true
+		}
+		return true
+	})
+	// Program, Line, the 37 statements and every expression but Constant, which only the optimizer creates
+	assert.Len(t, seen, 2+37+8)
+}
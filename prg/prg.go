@@ -0,0 +1,237 @@
+// ABOUTME: Commodore 64 tokenized program files (.prg): load address, linked lines and one-byte keyword tokens
+// ABOUTME: Encode crunches program text the way the C64 editor does, and Decode lists a .prg file back as text
+
+package prg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/parser"
+)
+
+// LoadAddress is where BASIC programs start in C64 memory
+const LoadAddress = 0x0801
+
+// MaxProgramSize is the number of bytes free for a BASIC program on a C64 (38911 BASIC BYTES FREE)
+const MaxProgramSize = 38911
+
+// Errors in C64 style
+var (
+	ErrOutOfMemory = errors.New("?OUT OF MEMORY ERROR")
+	ErrBadFile     = errors.New("?FILE DATA ERROR")
+)
+
+// Token bytes that change how the rest of a line is crunched
+const (
+	tokenData  = 0x83
+	tokenRem   = 0x8F
+	tokenPrint = 0x99
+	pi         = 0xFF
+)
+
+// keywords lists BASIC V2 keywords in token order, starting at 0x80. Crunching takes the first entry that matches,
+// so INPUT# comes before INPUT and GOTO before GO, as in the ROM.
+var keywords = []string{
+	"END", "FOR", "NEXT", "DATA", "INPUT#", "INPUT", "DIM", "READ", "LET", "GOTO", "RUN", "IF", "RESTORE", "GOSUB",
+	"RETURN", "REM", "STOP", "ON", "WAIT", "LOAD", "SAVE", "VERIFY", "DEF", "POKE", "PRINT#", "PRINT", "CONT", "LIST",
+	"CLR", "CMD", "SYS", "OPEN", "CLOSE", "GET", "NEW", "TAB(", "TO", "FN", "SPC(", "THEN", "NOT", "STEP", "+", "-",
+	"*", "/", "^", "AND", "OR", ">", "=", "<", "SGN", "INT", "ABS", "USR", "FRE", "POS", "SQR", "RND", "LOG", "EXP",
+	"COS", "SIN", "TAN", "ATN", "PEEK", "LEN", "STR$", "VAL", "ASC", "CHR$", "LEFT$", "RIGHT$", "MID$", "GO",
+}
+
+// Encode tokenizes program text into a .prg file. Statements this interpreter adds to BASIC V2, such as WHILE, have
+// no tokens and are stored as typed, so a C64 reports them as syntax errors. Lower-case letters become upper case,
+// as the C64 has no lower case in its default character set; lines with nothing after the number are left out.
+func Encode(src string) ([]byte, error) {
+	return encode(parser.SourceLines(src))
+}
+
+// EncodeProgram tokenizes a parsed program from the text its lines kept, such as one loaded from its JSON form
+func EncodeProgram(program *parser.Program) ([]byte, error) {
+	lines := make(map[int]string, len(program.Lines))
+	for _, line := range program.Lines {
+		lines[line.Number] = line.Source
+	}
+	return encode(lines)
+}
+
+// encode tokenizes program text by line number
+func encode(lines map[int]string) ([]byte, error) {
+	numbers := make([]int, 0, len(lines))
+	for n, text := range lines {
+		if text != "" {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	out := binary.LittleEndian.AppendUint16(nil, LoadAddress)
+	for _, n := range numbers {
+		body, err := crunch(lines[n])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		// The link holds the address of the next line: past this line's link, number, body and terminator
+		next := LoadAddress + len(out) - 2 + 4 + len(body) + 1
+		out = binary.LittleEndian.AppendUint16(out, uint16(next))
+		out = binary.LittleEndian.AppendUint16(out, uint16(n))
+		out = append(out, body...)
+		out = append(out, 0)
+	}
+	out = append(out, 0, 0)
+	if len(out)-2 > MaxProgramSize {
+		return nil, ErrOutOfMemory
+	}
+	return out, nil
+}
+
+// crunch converts the text of one line to PETSCII with keywords replaced by their tokens
+func crunch(text string) ([]byte, error) {
+	var body []byte
+	inQuotes, inData := false, false
+	runes := []rune(text)
+	for n := 0; n < len(runes); n++ {
+		c, err := petscii(runes[n])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == ':' && inData:
+			inData = false
+		case inData || c == ' ' || c == pi || (c >= '0' && c <= ';'):
+		case c == '?':
+			c = tokenPrint
+		default:
+			if token, length, ok := keywordAt(runes[n:]); ok {
+				c = token
+				n += length - 1
+				inData = token == tokenData
+				if token == tokenRem {
+					rest, err := encodeText(runes[n+1:])
+					if err != nil {
+						return nil, err
+					}
+					return append(append(body, c), rest...), nil
+				}
+			}
+		}
+		body = append(body, c)
+	}
+	return body, nil
+}
+
+// keywordAt returns the token of the first keyword that text starts with, and its length
+func keywordAt(text []rune) (byte, int, bool) {
+	for n, keyword := range keywords {
+		if hasKeyword(text, keyword) {
+			return byte(0x80 + n), len(keyword), true
+		}
+	}
+	return 0, 0, false
+}
+
+// hasKeyword reports whether text starts with keyword, ignoring the case of ASCII letters only
+func hasKeyword(text []rune, keyword string) bool {
+	if len(text) < len(keyword) {
+		return false
+	}
+	for n := range len(keyword) {
+		r := text[n]
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if r != rune(keyword[n]) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeText converts characters to PETSCII without crunching keywords
+func encodeText(text []rune) ([]byte, error) {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		c, err := petscii(r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// petscii converts one character to its PETSCII code in the upper-case character set
+func petscii(r rune) (byte, error) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return byte(r - 'a' + 'A'), nil
+	case r >= ' ' && r <= ']' && r != '\\':
+		return byte(r), nil
+	case r == '^':
+		return 0x5E, nil
+	case r == '£':
+		return 0x5C, nil
+	case r == '←':
+		return 0x5F, nil
+	case r == 'π':
+		return pi, nil
+	}
+	return 0, fmt.Errorf("%q has no PETSCII equivalent", r)
+}
+
+// Decode lists a .prg file as program text, one "N text" line per program line, as LIST shows it on a C64.
+// Line links are not trusted; lines are read up to their terminating zero, as the C64 relinks them after loading.
+func Decode(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", ErrBadFile
+	}
+	var sb strings.Builder
+	rest := data[2:]
+	for {
+		if len(rest) < 2 {
+			return "", ErrBadFile
+		}
+		if binary.LittleEndian.Uint16(rest) == 0 {
+			return sb.String(), nil
+		}
+		if len(rest) < 4 {
+			return "", ErrBadFile
+		}
+		end := bytes.IndexByte(rest[4:], 0)
+		if end < 0 {
+			return "", ErrBadFile
+		}
+		fmt.Fprintf(&sb, "%d %s\n", binary.LittleEndian.Uint16(rest[2:]), list(rest[4:4+end]))
+		rest = rest[4+end+1:]
+	}
+}
+
+// list expands the tokens of one line outside quotes; other bytes are converted from PETSCII
+func list(body []byte) string {
+	var sb strings.Builder
+	inQuotes := false
+	for _, c := range body {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			sb.WriteByte(c)
+		case !inQuotes && c >= 0x80 && int(c-0x80) < len(keywords):
+			sb.WriteString(keywords[c-0x80])
+		case c == pi:
+			sb.WriteRune('π')
+		default:
+			text, _ := charset.Decode([]byte{c}, charset.PETSCII)
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
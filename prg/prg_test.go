@@ -0,0 +1,131 @@
+package prg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+func TestEncodeFile(t *testing.T) {
+	got, err := Encode("20 GOTO 10\n10 PRINT \"HI\"\n30\n")
+	require.NoError(t, err)
+	want := []byte{
+		0x01, 0x08, // load address
+		0x0C, 0x08, 0x0A, 0x00, 0x99, ' ', '"', 'H', 'I', '"', 0x00, // 10 PRINT "HI"
+		0x15, 0x08, 0x14, 0x00, 0x89, ' ', '1', '0', 0x00, // 20 GOTO 10
+		0x00, 0x00, // end of program
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestCrunch(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []byte
+	}{
+		{"operators", "A=B+1", []byte{'A', 0xB2, 'B', 0xAA, '1'}},
+		{"question mark", "?X", []byte{0x99, 'X'}},
+		{"lower case", "print a", []byte{0x99, ' ', 'A'}},
+		{"first match wins", "INPUT#1,A:GOTO9", []byte{0x84, '1', ',', 'A', ':', 0x89, '9'}},
+		{"keywords inside names", "DONE=1", []byte{'D', 0x91, 'E', 0xB2, '1'}},
+		{"functions keep their parenthesis", "TAB(3)", []byte{0xA3, '3', ')'}},
+		{"strings", "PRINT\"GOTO ?\"", []byte{0x99, '"', 'G', 'O', 'T', 'O', ' ', '?', '"'}},
+		{"data until colon", "DATA TO,\"A:B\":TO", []byte{0x83, ' ', 'T', 'O', ',', '"', 'A', ':', 'B', '"', ':', 0xA4}},
+		{"rem to end of line", "REM IF:IF", []byte{0x8F, ' ', 'I', 'F', ':', 'I', 'F'}},
+		{"special characters", "PRINT\"£←π\"^π", []byte{0x99, '"', 0x5C, 0x5F, 0xFF, '"', 0xAE, 0xFF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := crunch(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	_, err := Encode("10 PRINT \"{}\"")
+	assert.EqualError(t, err, "line 10: '{' has no PETSCII equivalent")
+
+	var big strings.Builder
+	for n := 0; n < 500; n++ {
+		fmt.Fprintf(&big, "%d PRINT \"%s\"\n", n, strings.Repeat("X", 80))
+	}
+	_, err = Encode(big.String())
+	assert.ErrorIs(t, err, ErrOutOfMemory)
+}
+
+func TestDecode(t *testing.T) {
+	data := []byte{
+		0x01, 0x08,
+		0x01, 0x01, 0x0A, 0x00, 0x99, '"', 0xAA, 0x5E, '"', 0xAA, 0xFF, 0x00, // links only need to be non-zero
+		0x01, 0x01, 0x14, 0x00, 0x80, 0x00,
+		0x00, 0x00,
+	}
+	got, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT\"\uFFFD^\"+π\n20 END\n", got, "tokens inside quotes are graphics characters")
+
+	for _, bad := range [][]byte{nil, {0x01, 0x08}, {0x01, 0x08, 0x01, 0x08, 0x0A, 0x00, 0x99}} {
+		_, err := Decode(bad)
+		assert.ErrorIs(t, err, ErrBadFile, "% x", bad)
+	}
+}
+
+// TestRoundTrip lists the tokenized test programs back to their text, upper-cased as on a C64
+func TestRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../testdata/*.bas")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			data, err := Encode(string(content))
+			require.NoError(t, err)
+			got, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, listing(string(content)), got)
+		})
+	}
+}
+
+// listing is the text a C64 lists for a program: its non-empty lines in order, in upper case
+func listing(src string) string {
+	lines := parser.SourceLines(src)
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	var sb strings.Builder
+	for _, n := range numbers {
+		if lines[n] != "" {
+			fmt.Fprintf(&sb, "%d %s\n", n, strings.ToUpper(lines[n]))
+		}
+	}
+	return sb.String()
+}
+
+func TestEncodeProgram(t *testing.T) {
+	src := "10 PRINT \"HI\" : REM  SAY  HI\n20 GOTO 10\n30\n"
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+
+	got, err := EncodeProgram(program)
+	require.NoError(t, err)
+	want, err := Encode(src)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Replays inputs through the lexer, parser and interpreter, turning panics into errors
+// ABOUTME: Backs the crash corpus in testdata/crashers and the pipeline fuzz target
+
+package regression
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+)
+
+// Replay limits keeping every input fast and deterministic
+const (
+	MaxSteps  = 10000 // Statements executed before infinite loop protection stops the program
+	MaxTokens = 1 << 16
+)
+
+// Panic reports a panic raised while replaying an input
+type Panic struct {
+	Stage string // "lex", "parse" or "run"
+	Value any    // The recovered panic value
+	Stack string // Goroutine stack at the panic
+}
+
+func (p *Panic) Error() string {
+	return fmt.Sprintf("panic during %s: %v\n%s", p.Stage, p.Value, p.Stack)
+}
+
+// Replay runs source through every stage of the pipeline with a deterministic runtime. BASIC errors are
+// expected for arbitrary input and ignored; only a panic is returned, as a *Panic.
+func Replay(source string) (err error) {
+	stage := "lex"
+	defer func() {
+		if r := recover(); r != nil {
+			err = &Panic{Stage: stage, Value: r, Stack: string(debug.Stack())}
+		}
+	}()
+
+	l := lexer.New(source)
+	for n := 0; n < MaxTokens && l.NextToken().Type != lexer.EOF; n++ {
+	}
+
+	stage = "parse"
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if p.ParseError() != nil {
+		return nil
+	}
+
+	stage = "run"
+	interp := interpreter.NewInterpreter(runtime.NewDeterministicRuntime(1, "Y\n1\n2\n"))
+	interp.SetMaxSteps(MaxSteps)
+	_ = interp.Execute(program)
+	return nil
+}
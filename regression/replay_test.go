@@ -0,0 +1,55 @@
+package regression
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/examples"
+)
+
+// crasherFiles lists the checked-in inputs that crashed, or came close to crashing, the pipeline
+func crasherFiles(t testing.TB) []string {
+	files, err := filepath.Glob(filepath.Join("testdata", "crashers", "*.bas"))
+	require.NoError(t, err)
+	return files
+}
+
+func TestCrashersStayFixed(t *testing.T) {
+	files := crasherFiles(t)
+	require.NotEmpty(t, files, "crash corpus is missing")
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			source, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.NoError(t, Replay(string(source)))
+		})
+	}
+}
+
+func TestReplayIgnoresBasicErrors(t *testing.T) {
+	for _, source := range []string{"10 PRINT 1/0", "10 GOTO 10", "10 INPUT A: INPUT B: INPUT C: INPUT D", "PRINT \"A"} {
+		assert.NoError(t, Replay(source), source)
+	}
+}
+
+// FuzzPipeline feeds arbitrary programs through the whole pipeline. New failures found with
+// `go test ./regression -fuzz FuzzPipeline` should be minimised and added to testdata/crashers.
+func FuzzPipeline(f *testing.F) {
+	for _, file := range crasherFiles(f) {
+		source, err := os.ReadFile(file)
+		require.NoError(f, err)
+		f.Add(string(source))
+	}
+	for _, example := range examples.List() {
+		f.Add(example.Source)
+	}
+	f.Fuzz(func(t *testing.T, source string) {
+		if err := Replay(source); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,3 @@
+10 DIM A(99999,99999)
+20 DIM B(-1)
+30 C(11)=1
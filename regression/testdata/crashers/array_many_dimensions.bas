@@ -0,0 +1,3 @@
+10 DIM A(1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1)
+20 A(1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,2)=1
+30 PRINT A(0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,-1)
@@ -0,0 +1,4 @@
+10 DATA
+20 DATA ,,
+30 READ A$,B$,C$,D$
+40 DATA "unterminated
\ No newline at end of file
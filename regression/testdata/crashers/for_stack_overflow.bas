@@ -0,0 +1 @@
+10 FOR A=1 TO 2: GOTO 10
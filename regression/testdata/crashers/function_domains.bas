@@ -0,0 +1,2 @@
+10 X$=MID$("A",0)+LEFT$("A",-1)+CHR$(-1)+STR$(1E308*10)
+20 PRINT ASC(""),VAL(""),SQR(-1),LOG(0)
@@ -0,0 +1 @@
+10 GOSUB 10
@@ -0,0 +1,2 @@
+10 GOTO 20
+20 GOTO 10
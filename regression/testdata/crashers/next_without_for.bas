@@ -0,0 +1,3 @@
+10 FOR I=1 TO 2: NEXT: NEXT
+20 NEXT J
+30 FOR K=1 TO 1 STEP 0
@@ -0,0 +1,6 @@
+10 PRINT "
+20 ?
+30 IF THEN
+40 ON X GOTO
+50 DEF FN
+60 NEXT,
@@ -0,0 +1,100 @@
+// ABOUTME: Renumbers program lines with a start and step, rewriting the line numbers jumps refer to
+// ABOUTME: GOTO, GOSUB, THEN, ELSE, RUN, ON...GOTO lists and RESTORE targets follow their lines; REM text and strings are untouched
+
+package renum
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+// Default start and step, as in RENUM with no arguments
+const (
+	DefaultStart = 10
+	DefaultStep  = 10
+)
+
+// ErrIllegalQuantity reports a negative start, a step below 1 or new numbers above parser.MaxLineNumber
+var ErrIllegalQuantity = errors.New("?ILLEGAL QUANTITY ERROR")
+
+// jumpKeywords are followed by line numbers, possibly a comma-separated list of them
+var jumpKeywords = map[string]bool{"GOTO": true, "GOSUB": true, "THEN": true, "ELSE": true, "RUN": true, "RESTORE": true}
+
+// Lines renumbers program lines, keyed by line number with the text after it, in their current order.
+// Jumps to lines that do not exist are left as they are.
+func Lines(lines map[int]string, start, step int) (map[int]string, error) {
+	if start < 0 || step <= 0 {
+		return nil, ErrIllegalQuantity
+	}
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	if len(numbers) > 0 && start+(len(numbers)-1)*step > parser.MaxLineNumber {
+		return nil, ErrIllegalQuantity
+	}
+
+	renumbered := make(map[int]int, len(numbers))
+	for i, n := range numbers {
+		renumbered[n] = start + i*step
+	}
+	result := make(map[int]string, len(numbers))
+	for _, n := range numbers {
+		result[renumbered[n]] = rewrite(lines[n], renumbered)
+	}
+	return result, nil
+}
+
+// Source renumbers program text, returning one "N text" line per program line in order
+func Source(src string, start, step int) (string, error) {
+	lines, err := Lines(parser.SourceLines(src), start, step)
+	if err != nil {
+		return "", err
+	}
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var sb strings.Builder
+	for _, n := range numbers {
+		if lines[n] == "" {
+			fmt.Fprintf(&sb, "%d\n", n)
+		} else {
+			fmt.Fprintf(&sb, "%d %s\n", n, lines[n])
+		}
+	}
+	return sb.String(), nil
+}
+
+// rewrite replaces the jump targets in the text of one line, keeping everything else as typed
+func rewrite(text string, renumbered map[int]int) string {
+	var sb strings.Builder
+	jumping := false
+	for _, seg := range lexer.Classify(text) {
+		switch {
+		case strings.TrimSpace(seg.Text) == "":
+		case (seg.Class == lexer.ClassKeyword || seg.Class == lexer.ClassIdentifier) && jumpKeywords[strings.ToUpper(seg.Text)]:
+			jumping = true
+		case jumping && (seg.Class == lexer.ClassNumber || seg.Class == lexer.ClassLineNumber):
+			if n, err := strconv.Atoi(seg.Text); err == nil {
+				if target, ok := renumbered[n]; ok {
+					seg.Text = strconv.Itoa(target)
+				}
+			}
+		case jumping && seg.Text == ",":
+		default:
+			jumping = false
+		}
+		sb.WriteString(seg.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package renum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		start int
+		step  int
+		want  string
+	}{
+		{"defaults", "1 PRINT\n2 GOTO 1", DefaultStart, DefaultStep, "10 PRINT\n20 GOTO 10\n"},
+		{"start and step", "5 END\n7 END\n9 END", 100, 5, "100 END\n105 END\n110 END\n"},
+		{"out of order and repeated", "30 B\n10 A\n30 C", 1, 1, "1 A\n2 C\n"},
+		{"gosub and then", "1 GOSUB 3: IF X THEN 1\n3 if x then 1 else 3", 10, 10, "10 GOSUB 20: IF X THEN 10\n20 if x then 10 else 20\n"},
+		{"on lists keep spacing", "1 ON X GOTO 1, 2 ,3\n2 ON X GOSUB 3,2\n3 END", 10, 10, "10 ON X GOTO 10, 20 ,30\n20 ON X GOSUB 30,20\n30 END\n"},
+		{"restore and run", "1 RESTORE 3: RUN 1\n3 DATA 1", 10, 10, "10 RESTORE 20: RUN 10\n20 DATA 1\n"},
+		{"timers", "1 EVERY 50,1 GOSUB 2\n2 RETURN", 10, 10, "10 EVERY 50,1 GOSUB 20\n20 RETURN\n"},
+		{"missing target kept", "1 GOTO 99", 10, 10, "10 GOTO 99\n"},
+		{"other numbers kept", "1 PRINT 1,2: X=2: GOTO 2: PRINT 1\n2 FOR I=1 TO 2", 10, 10, "10 PRINT 1,2: X=2: GOTO 20: PRINT 1\n20 FOR I=1 TO 2\n"},
+		{"strings and remarks kept", "1 PRINT \"GOTO 1\": REM GOTO 1\n2 GOTO 1", 10, 10, "10 PRINT \"GOTO 1\": REM GOTO 1\n20 GOTO 10\n"},
+		{"empty line", "1\n2 GOTO 1", 10, 10, "10\n20 GOTO 10\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source(tt.src, tt.start, tt.step)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceRejectsIllegalQuantities(t *testing.T) {
+	for _, args := range [][2]int{{-1, 10}, {10, 0}, {63990, 10}} {
+		_, err := Source("1 END\n2 END", args[0], args[1])
+		assert.ErrorIs(t, err, ErrIllegalQuantity, "start %d step %d", args[0], args[1])
+	}
+}
+
+// TestSourceComposes renumbers each test program twice and checks the result matches renumbering it once, so every
+// jump followed its line; programs that parsed still parse to as many lines
+func TestSourceComposes(t *testing.T) {
+	files, err := filepath.Glob("../testdata/*.bas")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			src := string(content)
+
+			spread, err := Source(src, 1000, 7)
+			require.NoError(t, err)
+			twice, err := Source(spread, 1, 1)
+			require.NoError(t, err)
+			once, err := Source(src, 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, once, twice)
+
+			p := parser.New(lexer.New(src))
+			p.SetShims(true)
+			if original := p.ParseProgram(); p.ParseError() == nil {
+				assert.Equal(t, len(original.Lines), len(parsertest.Parse(t, spread).Lines))
+			}
+		})
+	}
+}
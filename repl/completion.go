@@ -0,0 +1,60 @@
+// ABOUTME: Keyword and function name completion for the interactive REPL
+// ABOUTME: Completes the word under the cursor from the parser's reference registry and REPL commands
+
+package repl
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"basic-interpreter/parser"
+)
+
+// commands lists the REPL commands that are not BASIC statements
+var commands = []string{"CONT", "RENUM", "LOAD", "SAVE", "HELP", "EXAMPLE", "TRANSCRIPT", "QUIT", "EXIT"}
+
+// completionWords returns the sorted candidate words for completion
+func completionWords() []string {
+	words := append([]string(nil), commands...)
+	for _, ref := range parser.References() {
+		if !slices.Contains(words, ref.Name) {
+			words = append(words, ref.Name)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Complete returns the line with its last word completed as far as unambiguous,
+// along with all candidates matching that word
+func Complete(line string) (string, []string) {
+	start := strings.LastIndexFunc(line, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r == '$')
+	}) + 1
+	word := strings.ToUpper(line[start:])
+	if word == "" {
+		return line, nil
+	}
+
+	var matches []string
+	for _, w := range completionWords() {
+		if strings.HasPrefix(w, word) {
+			matches = append(matches, w)
+		}
+	}
+	if len(matches) == 0 {
+		return line, nil
+	}
+
+	prefix := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(matches) == 1 && !strings.HasSuffix(prefix, "$") {
+		prefix += " "
+	}
+	return line[:start] + prefix, matches
+}
@@ -0,0 +1,31 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		completed  string
+		candidates []string
+	}{
+		{"unique keyword", "10 PRI", "10 PRINT ", []string{"PRINT"}},
+		{"lowercase prefix", "go", "GO", []string{"GOSUB", "GOTO"}},
+		{"string function keeps no trailing space", "PRINT LEF", "PRINT LEFT$", []string{"LEFT$"}},
+		{"word after punctuation", `PRINT "A";CH`, `PRINT "A";CHR$`, []string{"CHR$"}},
+		{"no match", "10 XYZ", "10 XYZ", nil},
+		{"empty word", "10 ", "10 ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completed, candidates := Complete(tt.line)
+			assert.Equal(t, tt.completed, completed)
+			assert.Equal(t, tt.candidates, candidates)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// ABOUTME: Line editor for the interactive REPL with history navigation and completion
+// ABOUTME: Edits a line in raw terminal mode, falling back to plain line reading when input is not a terminal
+
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl+C
+var ErrInterrupted = errors.New("interrupted")
+
+// Key codes understood by the line editor
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyEnter     = 13
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// LineReader reads one line of input after showing a prompt
+type LineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// Editor reads lines with cursor movement, history and completion when attached to a terminal
+type Editor struct {
+	in      io.Reader
+	out     io.Writer
+	fd      int
+	raw     bool
+	history *History
+}
+
+// NewEditor creates an editor reading from in and echoing to out.
+// Raw-mode editing is used only when in is a terminal.
+func NewEditor(in io.Reader, out io.Writer, history *History) *Editor {
+	e := &Editor{in: in, out: out, fd: -1, history: history}
+	if f, ok := in.(*os.File); ok {
+		e.fd = int(f.Fd())
+		if restore, err := makeRaw(e.fd); err == nil {
+			restore()
+			e.raw = true
+		}
+	}
+	return e
+}
+
+// ReadLine shows the prompt and returns the edited line without its terminator
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.raw {
+		return e.readCookedLine(prompt)
+	}
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return e.readCookedLine(prompt)
+	}
+	defer restore()
+
+	st := newLineState(e.history)
+	fmt.Fprint(e.out, prompt)
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+		done, err := st.handleKey(key)
+		if err != nil {
+			fmt.Fprint(e.out, "\r\n")
+			return "", err
+		}
+		if done {
+			fmt.Fprint(e.out, "\r\n")
+			return string(st.buf), nil
+		}
+		if len(st.candidates) > 1 {
+			fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(st.candidates, "  "))
+			st.candidates = nil
+		}
+		e.render(prompt, st)
+	}
+}
+
+// render redraws the prompt and buffer and positions the cursor
+func (e *Editor) render(prompt string, st *lineState) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(st.buf))
+	if back := len(st.buf) - st.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+// readKey reads one key, decoding ANSI escape sequences for the arrow and delete keys
+func (e *Editor) readKey() (string, error) {
+	b, err := e.readByte()
+	if err != nil {
+		return "", err
+	}
+	if b != keyEscape {
+		return string([]byte{b}), nil
+	}
+	next, err := e.readByte()
+	if err != nil {
+		return "", err
+	}
+	seq := []byte{b, next}
+	switch next {
+	case '[':
+		// CSI sequence: parameters followed by a final byte in '@'..'~'
+		for {
+			c, err := e.readByte()
+			if err != nil {
+				return "", err
+			}
+			seq = append(seq, c)
+			if c >= '@' && c <= '~' {
+				return string(seq), nil
+			}
+		}
+	case 'O':
+		// SS3 sequence: a single final byte
+		c, err := e.readByte()
+		if err != nil {
+			return "", err
+		}
+		return string(append(seq, c)), nil
+	default:
+		return string(seq), nil
+	}
+}
+
+// readByte reads a single byte without buffering, so no input is taken from later readers
+func (e *Editor) readByte() (byte, error) {
+	var b [1]byte
+	for {
+		n, err := e.in.Read(b[:])
+		if n == 1 {
+			return b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// readCookedLine reads a line byte by byte for non-terminal input
+func (e *Editor) readCookedLine(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	var sb strings.Builder
+	for {
+		b, err := e.readByte()
+		if err != nil {
+			if err == io.EOF && sb.Len() > 0 {
+				return sb.String(), nil
+			}
+			return "", err
+		}
+		if b == '\n' {
+			return strings.TrimSuffix(sb.String(), "\r"), nil
+		}
+		sb.WriteByte(b)
+	}
+}
+
+// lineState is the editing state of the line being entered
+type lineState struct {
+	buf        []rune
+	pos        int
+	history    *History
+	histIdx    int      // index into history while browsing; history.Len() means the new line
+	draft      string   // line being typed before browsing history
+	candidates []string // completion candidates to show, if ambiguous
+}
+
+// newLineState creates an empty editing state
+func newLineState(history *History) *lineState {
+	st := &lineState{history: history}
+	if history != nil {
+		st.histIdx = history.Len()
+	}
+	return st
+}
+
+// handleKey applies a key and reports whether the line is complete
+func (st *lineState) handleKey(key string) (bool, error) {
+	switch key {
+	case string(rune(keyEnter)), string(rune(keyLineFeed)):
+		return true, nil
+	case string(rune(keyCtrlC)):
+		return false, ErrInterrupted
+	case string(rune(keyCtrlD)):
+		if len(st.buf) == 0 {
+			return false, io.EOF
+		}
+		st.deleteAtCursor()
+	case string(rune(keyBackspace)), string(rune(keyDelete)):
+		if st.pos > 0 {
+			st.buf = append(st.buf[:st.pos-1], st.buf[st.pos:]...)
+			st.pos--
+		}
+	case string(rune(keyCtrlA)), "\x1b[H", "\x1bOH", "\x1b[1~":
+		st.pos = 0
+	case string(rune(keyCtrlE)), "\x1b[F", "\x1bOF", "\x1b[4~":
+		st.pos = len(st.buf)
+	case string(rune(keyCtrlK)):
+		st.buf = st.buf[:st.pos]
+	case string(rune(keyCtrlU)):
+		st.buf = st.buf[st.pos:]
+		st.pos = 0
+	case "\x1b[D", "\x1bOD":
+		if st.pos > 0 {
+			st.pos--
+		}
+	case "\x1b[C", "\x1bOC":
+		if st.pos < len(st.buf) {
+			st.pos++
+		}
+	case "\x1b[3~":
+		st.deleteAtCursor()
+	case "\x1b[A", "\x1bOA":
+		st.browseHistory(-1)
+	case "\x1b[B", "\x1bOB":
+		st.browseHistory(1)
+	case string(rune(keyTab)):
+		st.complete()
+	default:
+		for _, r := range key {
+			if r < ' ' || r == keyDelete {
+				return false, nil // ignore other control keys and unknown sequences
+			}
+		}
+		runes := []rune(key)
+		st.buf = append(st.buf[:st.pos], append(runes, st.buf[st.pos:]...)...)
+		st.pos += len(runes)
+	}
+	return false, nil
+}
+
+// deleteAtCursor removes the character under the cursor
+func (st *lineState) deleteAtCursor() {
+	if st.pos < len(st.buf) {
+		st.buf = append(st.buf[:st.pos], st.buf[st.pos+1:]...)
+	}
+}
+
+// browseHistory moves through history by delta entries, keeping the draft line at the end
+func (st *lineState) browseHistory(delta int) {
+	if st.history == nil {
+		return
+	}
+	next := st.histIdx + delta
+	if next < 0 || next > st.history.Len() {
+		return
+	}
+	if st.histIdx == st.history.Len() {
+		st.draft = string(st.buf)
+	}
+	st.histIdx = next
+	if next == st.history.Len() {
+		st.buf = []rune(st.draft)
+	} else {
+		st.buf = []rune(st.history.At(next))
+	}
+	st.pos = len(st.buf)
+}
+
+// complete completes the word before the cursor
+func (st *lineState) complete() {
+	head := string(st.buf[:st.pos])
+	completed, matches := Complete(head)
+	tail := st.buf[st.pos:]
+	st.buf = append([]rune(completed), tail...)
+	st.pos = len([]rune(completed))
+	if len(matches) > 1 {
+		st.candidates = matches
+	}
+}
@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeKeys(st *lineState, keys ...string) (bool, error) {
+	for _, k := range keys {
+		done, err := st.handleKey(k)
+		if done || err != nil {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
+func TestLineState_Editing(t *testing.T) {
+	st := newLineState(nil)
+	done, err := typeKeys(st, "P", "R", "N", "T", "\x1b[D", "\x1b[D", "I", "\x1b[C", "\x1b[C", "\x7f", "T", "\r")
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, "PRINT", string(st.buf))
+}
+
+func TestLineState_HomeEndAndKill(t *testing.T) {
+	st := newLineState(nil)
+	_, _ = typeKeys(st, "A", "B", "C", "\x01", "X", "\x05", "Y", "\x01", "\x0b")
+	assert.Equal(t, "", string(st.buf))
+
+	st = newLineState(nil)
+	_, _ = typeKeys(st, "A", "B", "C", "\x1b[D", "\x15")
+	assert.Equal(t, "C", string(st.buf))
+	assert.Equal(t, 0, st.pos)
+}
+
+func TestLineState_HistoryNavigation(t *testing.T) {
+	h := NewHistory(10)
+	h.Add("FIRST")
+	h.Add("SECOND")
+
+	st := newLineState(h)
+	_, _ = typeKeys(st, "D", "\x1b[A")
+	assert.Equal(t, "SECOND", string(st.buf))
+	_, _ = typeKeys(st, "\x1b[A", "\x1b[A")
+	assert.Equal(t, "FIRST", string(st.buf))
+	_, _ = typeKeys(st, "\x1b[B", "\x1b[B")
+	assert.Equal(t, "D", string(st.buf))
+}
+
+func TestLineState_Completion(t *testing.T) {
+	st := newLineState(nil)
+	_, _ = typeKeys(st, "G", "O", "\t")
+	assert.Equal(t, []string{"GOSUB", "GOTO"}, st.candidates)
+
+	st = newLineState(nil)
+	_, _ = typeKeys(st, "R", "E", "T", "\t")
+	assert.Equal(t, "RETURN ", string(st.buf))
+	assert.Nil(t, st.candidates)
+}
+
+func TestLineState_ControlKeys(t *testing.T) {
+	_, err := typeKeys(newLineState(nil), "A", "\x03")
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	_, err = typeKeys(newLineState(nil), "\x04")
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestEditor_CookedInput(t *testing.T) {
+	var out bytes.Buffer
+	e := NewEditor(strings.NewReader("10 PRINT\r\nRUN"), &out, nil)
+
+	line, err := e.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT", line)
+
+	line, err = e.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "RUN", line)
+
+	_, err = e.ReadLine("> ")
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "> > > ", out.String())
+}
@@ -0,0 +1,53 @@
+// ABOUTME: LOAD and SAVE commands moving the REPL program buffer to and from pluggable storage
+// ABOUTME: Names are resolved by the configured storage.Store, e.g. "HTTP:host/prog.bas"
+
+package repl
+
+import (
+	"fmt"
+
+	"basic-interpreter/runtime"
+	"basic-interpreter/storage"
+)
+
+// SetStorage sets the backend used by LOAD and SAVE
+func (r *REPL) SetStorage(store storage.Store) {
+	r.store = store
+}
+
+// SetFiles sets the devices programs OPEN files on, such as a storage.DiskStore serving drive 8
+func (r *REPL) SetFiles(files runtime.Files) {
+	r.files = files
+	r.interp.SetFiles(files)
+}
+
+// loadProgram replaces the program buffer with a program from storage
+func (r *REPL) loadProgram(name string) {
+	if r.store == nil {
+		fmt.Fprintln(r.out, "?DEVICE NOT PRESENT ERROR")
+		return
+	}
+	source, err := r.store.Load(name)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	r.lines = parseSourceLines(source)
+	r.program = nil
+	r.clearVariables()
+	r.autosave()
+	fmt.Fprintf(r.out, "LOADED %s\n", name)
+}
+
+// saveProgram writes the program buffer to storage
+func (r *REPL) saveProgram(name string) {
+	if r.store == nil {
+		fmt.Fprintln(r.out, "?DEVICE NOT PRESENT ERROR")
+		return
+	}
+	if err := r.store.Save(name, r.source()); err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	fmt.Fprintf(r.out, "SAVED %s\n", name)
+}
@@ -0,0 +1,41 @@
+package repl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/storage"
+)
+
+func TestREPL_SaveAndLoad(t *testing.T) {
+	store := storage.NewMemoryStore()
+	var out bytes.Buffer
+	r := New(&scriptedReader{lines: []string{
+		`10 PRINT "STORED"`,
+		`SAVE "demo"`,
+		`10 PRINT "CHANGED"`,
+		`LOAD "DEMO"`,
+		"RUN",
+	}}, &out, nil)
+	r.SetStorage(store)
+	require.NoError(t, r.Run())
+
+	assert.Equal(t, "READY.\nSAVED demo\nREADY.\nLOADED DEMO\nREADY.\nSTORED\nREADY.\n", out.String())
+	source, err := store.Load("demo")
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT \"STORED\"\n", source)
+}
+
+func TestREPL_LoadErrors(t *testing.T) {
+	out := runSession(t, `LOAD "X"`)
+	assert.Contains(t, out, "?DEVICE NOT PRESENT ERROR")
+
+	var buf bytes.Buffer
+	r := New(&scriptedReader{lines: []string{`LOAD "MISSING"`}}, &buf, nil)
+	r.SetStorage(storage.NewMemoryStore())
+	require.NoError(t, r.Run())
+	assert.Contains(t, buf.String(), "?FILE NOT FOUND ERROR")
+}
@@ -0,0 +1,71 @@
+// ABOUTME: HELP and EXAMPLE commands for the REPL
+// ABOUTME: Shows syntax and examples from the parser's reference metadata and runs examples on demand
+
+package repl
+
+import (
+	"fmt"
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+// showHelp prints the topic list, or the syntax, summary and example for one topic
+func (r *REPL) showHelp(topic string) {
+	if topic == "" {
+		var statements, keywords, functions []string
+		for _, ref := range parser.References() {
+			switch ref.Kind {
+			case parser.KindFunction:
+				functions = append(functions, ref.Name)
+			case parser.KindKeyword:
+				keywords = append(keywords, ref.Name)
+			default:
+				statements = append(statements, ref.Name)
+			}
+		}
+		fmt.Fprintf(r.out, "STATEMENTS: %s\n", strings.Join(statements, " "))
+		fmt.Fprintf(r.out, "KEYWORDS: %s\n", strings.Join(keywords, " "))
+		fmt.Fprintf(r.out, "FUNCTIONS: %s\n", strings.Join(functions, " "))
+		fmt.Fprintf(r.out, "COMMANDS: %s\n", strings.Join(commands, " "))
+		fmt.Fprintln(r.out, "TYPE HELP <NAME> FOR DETAILS")
+		return
+	}
+
+	ref, ok := parser.LookupReference(topic)
+	if !ok {
+		fmt.Fprintf(r.out, "hint: no help for %s; type HELP for a list of topics\n", topic)
+		return
+	}
+	fmt.Fprintf(r.out, "%s\n  %s\n", ref.Syntax, ref.Summary)
+	if !ref.InDialect(parser.DialectC64) {
+		fmt.Fprintln(r.out, "  (extended dialect, not in C64 BASIC)")
+	}
+	fmt.Fprintln(r.out, "EXAMPLE:")
+	for _, line := range strings.Split(ref.Example, "\n") {
+		fmt.Fprintf(r.out, "  %s\n", line)
+	}
+	fmt.Fprintf(r.out, "TYPE EXAMPLE %s TO RUN IT\n", ref.Name)
+}
+
+// runExample runs the example for a topic in a fresh interpreter, leaving the stored program untouched
+func (r *REPL) runExample(topic string) {
+	ref, ok := parser.LookupReference(topic)
+	if !ok {
+		fmt.Fprintf(r.out, "hint: no example for %s; type HELP for a list of topics\n", topic)
+		return
+	}
+
+	p := parser.New(lexer.New(ref.Example))
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		fmt.Fprintf(r.out, "?SYNTAX ERROR: %v\n", e)
+		return
+	}
+	interp := r.newInterpreter()
+	interp.SetSource(ref.Example)
+	if err := interp.Execute(program); err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+}
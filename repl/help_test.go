@@ -0,0 +1,53 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"basic-interpreter/parser"
+)
+
+func TestREPL_HelpListsTopics(t *testing.T) {
+	out := runSession(t, "HELP")
+	assert.Contains(t, out, "STATEMENTS: PRINT LET")
+	assert.Contains(t, out, "KEYWORDS: THEN TO STEP")
+	assert.Contains(t, out, "FUNCTIONS: LEN LEFT$")
+	assert.Contains(t, out, " RUN LIST")
+	assert.Contains(t, out, "COMMANDS: CONT RENUM LOAD SAVE HELP EXAMPLE TRANSCRIPT QUIT EXIT")
+}
+
+func TestREPL_HelpTopic(t *testing.T) {
+	out := runSession(t, "help mid$")
+	assert.Equal(t, "READY.\n"+
+		"MID$(s$, start, n)\n"+
+		"  Substring starting at position start (1-based)\n"+
+		"EXAMPLE:\n"+
+		"  10 PRINT MID$(\"HELLO\",2,3)\n"+
+		"TYPE EXAMPLE MID$ TO RUN IT\n"+
+		"READY.\n", out)
+
+	out = runSession(t, "HELP WEND")
+	assert.Contains(t, out, "  (extended dialect, not in C64 BASIC)\n")
+
+	out = runSession(t, "HELP PRNT")
+	assert.Contains(t, out, "hint: no help for PRNT")
+}
+
+func TestREPL_ExampleRunsWithoutTouchingProgram(t *testing.T) {
+	out := runSession(t,
+		`10 PRINT "MINE"`,
+		"EXAMPLE LEN",
+		"RUN",
+	)
+	assert.Equal(t, "READY.\n5\nREADY.\nMINE\nREADY.\n", out)
+}
+
+func TestREPL_EveryExampleRuns(t *testing.T) {
+	for _, ref := range parser.References() {
+		t.Run(ref.Name, func(t *testing.T) {
+			out := runSession(t, "EXAMPLE "+ref.Name, "") // blank line answers INPUT
+			assert.NotContains(t, out, "ERROR", "example output: %s", out)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Command history for the interactive REPL
+// ABOUTME: Keeps entered lines in memory and persists them to a history file between sessions
+
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// DefaultHistorySize is the maximum number of history entries kept
+const DefaultHistorySize = 500
+
+// History stores previously entered lines, oldest first
+type History struct {
+	entries []string
+	maxSize int
+}
+
+// NewHistory creates an empty history holding at most maxSize entries
+func NewHistory(maxSize int) *History {
+	return &History{maxSize: maxSize}
+}
+
+// Add appends a line, skipping blanks and immediate repeats of the last entry
+func (h *History) Add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+	h.entries = append(h.entries, line)
+	if h.maxSize > 0 && len(h.entries) > h.maxSize {
+		h.entries = h.entries[len(h.entries)-h.maxSize:]
+	}
+}
+
+// Len returns the number of entries
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At returns the entry at index idx (0 is the oldest)
+func (h *History) At(idx int) string {
+	return h.entries[idx]
+}
+
+// Load reads history entries from a file; a missing file is not an error
+func (h *History) Load(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Save writes all entries to a file, one per line
+func (h *History) Save(path string) error {
+	var sb strings.Builder
+	for _, e := range h.entries {
+		sb.WriteString(e)
+		sb.WriteByte('\n')
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0o600)
+}
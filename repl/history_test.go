@@ -0,0 +1,41 @@
+package repl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_Add(t *testing.T) {
+	h := NewHistory(3)
+	h.Add("A")
+	h.Add("A")
+	h.Add("  ")
+	h.Add("B")
+	h.Add("C")
+	h.Add("D")
+
+	require.Equal(t, 3, h.Len())
+	assert.Equal(t, "B", h.At(0))
+	assert.Equal(t, "D", h.At(2))
+}
+
+func TestHistory_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := NewHistory(10)
+	h.Add(`10 PRINT "HI"`)
+	h.Add("RUN")
+	require.NoError(t, h.Save(path))
+
+	loaded := NewHistory(10)
+	require.NoError(t, loaded.Load(path))
+	require.Equal(t, 2, loaded.Len())
+	assert.Equal(t, `10 PRINT "HI"`, loaded.At(0))
+
+	missing := NewHistory(10)
+	assert.NoError(t, missing.Load(filepath.Join(t.TempDir(), "missing")))
+	assert.Equal(t, 0, missing.Len())
+}
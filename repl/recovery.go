@@ -0,0 +1,77 @@
+// ABOUTME: Auto-save of the REPL program buffer and recovery of it on the next start
+// ABOUTME: Every edit rewrites a recovery file; a leftover file is offered for restore when a session begins
+
+package repl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetRecoveryFile enables auto-saving the program buffer to path ("" disables it)
+func (r *REPL) SetRecoveryFile(path string) {
+	r.recoveryPath = path
+}
+
+// autosave writes the program buffer to the recovery file, removing the file once the buffer is empty
+func (r *REPL) autosave() {
+	if r.recoveryPath == "" {
+		return
+	}
+	if len(r.lines) == 0 {
+		_ = os.Remove(r.recoveryPath)
+		return
+	}
+	if err := writeFileAtomic(r.recoveryPath, []byte(r.source())); err != nil {
+		fmt.Fprintf(r.out, "hint: autosave failed: %v\n", err)
+	}
+}
+
+// offerRecovery asks whether to restore a program left in the recovery file by an earlier session
+func (r *REPL) offerRecovery() error {
+	if r.recoveryPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.recoveryPath)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+
+	recovered := parseSourceLines(string(data))
+	if len(recovered) == 0 {
+		return nil
+	}
+
+	answer, err := r.reader.ReadLine(fmt.Sprintf("RESTORE UNSAVED PROGRAM (%d LINES)? (Y/N) ", len(recovered)))
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(answer)), "Y") {
+		r.lines = recovered
+		r.program = nil
+		fmt.Fprintln(r.out, "PROGRAM RESTORED")
+		return nil
+	}
+	_ = os.Remove(r.recoveryPath)
+	return nil
+}
+
+// writeFileAtomic replaces path with data via a temporary file so a crash never leaves it half written
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
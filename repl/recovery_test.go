@@ -0,0 +1,61 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runRecoverySession(t *testing.T, path string, lines ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	r := New(&scriptedReader{lines: lines}, &out, nil)
+	r.SetRecoveryFile(path)
+	require.NoError(t, r.Run())
+	return out.String()
+}
+
+func TestREPL_AutosaveWritesProgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.bas")
+	runRecoverySession(t, path, `20 PRINT "B"`, `10 PRINT "A"`)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT \"A\"\n20 PRINT \"B\"\n", string(data))
+
+	// Deleting every line removes the recovery file
+	runRecoverySession(t, path, "Y", "10", "20")
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestREPL_RecoveryRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.bas")
+	require.NoError(t, os.WriteFile(path, []byte("10 PRINT \"SAVED\"\n"), 0644))
+
+	out := runRecoverySession(t, path, "y", "RUN")
+	assert.Equal(t, "PROGRAM RESTORED\nREADY.\nSAVED\nREADY.\n", out)
+}
+
+func TestREPL_RecoveryDeclined(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.bas")
+	require.NoError(t, os.WriteFile(path, []byte("10 PRINT \"SAVED\"\n"), 0644))
+
+	out := runRecoverySession(t, path, "N", "LIST")
+	assert.Equal(t, "READY.\nREADY.\n", out)
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestREPL_NoRecoveryPrompt(t *testing.T) {
+	var out bytes.Buffer
+	reader := &scriptedReader{lines: []string{"LIST"}}
+	r := New(reader, &out, nil)
+	r.SetRecoveryFile(filepath.Join(t.TempDir(), "none.bas"))
+	require.NoError(t, r.Run())
+	assert.Equal(t, []string{"", ""}, reader.prompts)
+}
@@ -0,0 +1,469 @@
+// ABOUTME: Interactive read-eval-print loop for entering, editing and running BASIC programs
+// ABOUTME: Stores numbered lines as program text and executes unnumbered lines immediately
+
+package repl
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"basic-interpreter/highlight"
+	"basic-interpreter/interpreter"
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+	"basic-interpreter/renum"
+	"basic-interpreter/runtime"
+	"basic-interpreter/storage"
+)
+
+// Banner is the screen a C64 shows when switched on, up to the READY. prompt
+const Banner = "\n    **** COMMODORE 64 BASIC V2 ****\n\n 64K RAM SYSTEM  38911 BASIC BYTES FREE\n\n"
+
+// REPL is an interactive BASIC session
+type REPL struct {
+	reader   LineReader
+	out      io.Writer
+	history  *History
+	lines    map[int]string  // Program source text by line number
+	program  *parser.Program // Parsed program, nil when lines were edited since the last parse
+	interp   *interpreter.Interpreter
+	rt       *consoleRuntime
+	maxSteps int
+	color    bool // Colorize LIST output
+	keepVars bool // Keep variables across RUN and program edits for debugging
+	banner   bool // Print Banner before the first READY.
+
+	transcript   *transcript      // Active TRANSCRIPT capture, nil when off
+	recoveryPath string           // Auto-save file for the program buffer, "" when disabled
+	store        storage.Store    // Backend for LOAD and SAVE, nil when unavailable
+	files        runtime.Files    // Devices OPEN reaches, nil when only the keyboard and screen exist
+	clock        func() time.Time // Time source for transcript timestamps
+}
+
+// New creates a REPL reading commands from reader and writing to out.
+// Entered commands are added to history when it is not nil.
+func New(reader LineReader, out io.Writer, history *History) *REPL {
+	r := &REPL{
+		history: history,
+		lines:   make(map[int]string),
+		clock:   time.Now,
+	}
+	// All I/O passes through the transcript wrappers so TRANSCRIPT can mirror it
+	r.reader = &transcriptReader{reader: reader, repl: r}
+	r.out = &transcriptWriter{out: out, repl: r}
+	r.rt = &consoleRuntime{
+		reader: r.reader,
+		out:    r.out,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	r.interp = r.newInterpreter()
+	return r
+}
+
+// SetMaxSteps sets the step limit applied to RUN and immediate statements (0 disables it)
+func (r *REPL) SetMaxSteps(maxSteps int) {
+	r.maxSteps = maxSteps
+	r.interp.SetMaxSteps(maxSteps)
+}
+
+// SetKeepVars makes RUN, LOAD and line edits keep simple variables instead of clearing them as the C64 does
+func (r *REPL) SetKeepVars(keep bool) {
+	r.keepVars = keep
+}
+
+// SetBanner makes Run start with the C64 startup banner
+func (r *REPL) SetBanner(banner bool) {
+	r.banner = banner
+}
+
+// SetInterrupt sets the channel the host sends on when the user presses Ctrl+C during RUN, CONT or an immediate
+// statement; the program stops with BREAK IN and CONT resumes it
+func (r *REPL) SetInterrupt(interrupt <-chan struct{}) {
+	r.interp.SetInterrupt(interrupt)
+}
+
+// SetColor enables or disables syntax highlighting in LIST output
+func (r *REPL) SetColor(color bool) {
+	r.color = color
+}
+
+// Run reads and handles lines until QUIT/EXIT or end of input
+func (r *REPL) Run() error {
+	defer r.stopTranscript()
+	if err := r.offerRecovery(); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	if r.banner {
+		fmt.Fprint(r.out, Banner)
+	}
+	fmt.Fprintln(r.out, "READY.")
+	for {
+		line, err := r.reader.ReadLine("")
+		if err == ErrInterrupted {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if r.history != nil {
+			r.history.Add(line)
+		}
+		if quit := r.HandleLine(line); quit {
+			return nil
+		}
+	}
+}
+
+// HandleLine processes one entered line and reports whether the session should end
+func (r *REPL) HandleLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+
+	if number, text, ok := splitLineNumber(trimmed); ok {
+		if number > parser.MaxLineNumber {
+			fmt.Fprintln(r.out, "?SYNTAX ERROR")
+			return false
+		}
+		r.storeLine(number, text)
+		return false
+	}
+
+	command, arg, _ := strings.Cut(strings.ToUpper(trimmed), " ")
+	arg = strings.TrimSpace(arg)
+	switch {
+	case (command == "QUIT" || command == "EXIT") && arg == "":
+		return true
+	case command == "RUN":
+		r.runProgram(arg)
+	case command == "CONT" && arg == "":
+		r.continueProgram()
+	case command == "LIST":
+		r.listProgram(arg)
+	case command == "RENUM":
+		r.renumberProgram(arg)
+	case command == "HELP":
+		r.showHelp(arg)
+	case command == "EXAMPLE" && arg != "":
+		r.runExample(arg)
+	case command == "LOAD":
+		r.loadProgram(quotedArgument(trimmed[len(command):]))
+	case command == "SAVE":
+		r.saveProgram(quotedArgument(trimmed[len(command):]))
+	case command == "TRANSCRIPT":
+		r.toggleTranscript(quotedArgument(trimmed[len(command):]))
+	default:
+		r.executeImmediate(trimmed)
+	}
+	fmt.Fprintln(r.out, "READY.")
+	return false
+}
+
+// quotedArgument extracts a command's file name argument, with or without quotes
+func quotedArgument(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, `"`) {
+		arg = strings.TrimPrefix(arg, `"`)
+		if end := strings.IndexByte(arg, '"'); end >= 0 {
+			arg = arg[:end]
+		}
+	}
+	return arg
+}
+
+// splitLineNumber separates a leading line number from the rest of the line
+func splitLineNumber(line string) (int, string, bool) {
+	end := 0
+	for end < len(line) && line[end] >= '0' && line[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, "", false
+	}
+	number, err := strconv.Atoi(line[:end])
+	if err != nil {
+		return 0, "", false
+	}
+	return number, strings.TrimSpace(line[end:]), true
+}
+
+// storeLine adds, replaces or (when text is empty) deletes a program line, hinting at syntax errors
+func (r *REPL) storeLine(number int, text string) {
+	r.program = nil
+	r.clearVariables()
+	defer r.autosave()
+	if text == "" {
+		delete(r.lines, number)
+		return
+	}
+	r.lines[number] = text
+
+	p := parser.New(lexer.New(fmt.Sprintf("%d %s", number, text)))
+	p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		fmt.Fprintf(r.out, "hint: %s\n", e.Message)
+	}
+}
+
+// parseSourceLines splits program text into numbered lines, skipping blank and unnumbered ones
+func parseSourceLines(text string) map[int]string {
+	lines := make(map[int]string)
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if number, body, ok := splitLineNumber(strings.TrimSpace(line)); ok && body != "" {
+			lines[number] = body
+		}
+	}
+	return lines
+}
+
+// source returns the stored program as text, ordered by line number
+func (r *REPL) source() string {
+	return r.sourceIn(parser.AllLines)
+}
+
+// sourceIn returns the stored program lines in a range as text, ordered by line number
+func (r *REPL) sourceIn(lines parser.LineRange) string {
+	numbers := make([]int, 0, len(r.lines))
+	for n := range r.lines {
+		if lines.Contains(n) {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	var sb strings.Builder
+	for _, n := range numbers {
+		fmt.Fprintf(&sb, "%d %s\n", n, r.lines[n])
+	}
+	return sb.String()
+}
+
+// listProgram prints the stored program lines in a LIST range such as 100-200, highlighted when color is enabled
+func (r *REPL) listProgram(arg string) {
+	lines, err := parser.ParseLineRange(arg)
+	if err != nil {
+		fmt.Fprintln(r.out, "?SYNTAX ERROR")
+		return
+	}
+	listing := r.sourceIn(lines)
+	if r.color {
+		listing = highlight.Source(listing)
+	}
+	fmt.Fprint(r.out, listing)
+}
+
+// renumberProgram renumbers the stored program from the start and step in arg (RENUM 100,5), 10,10 by default
+func (r *REPL) renumberProgram(arg string) {
+	start, step := renum.DefaultStart, renum.DefaultStep
+	if arg != "" {
+		first, second, hasStep := strings.Cut(arg, ",")
+		var err error
+		if start, err = strconv.Atoi(strings.TrimSpace(first)); err != nil {
+			fmt.Fprintln(r.out, "?SYNTAX ERROR")
+			return
+		}
+		if hasStep {
+			if step, err = strconv.Atoi(strings.TrimSpace(second)); err != nil {
+				fmt.Fprintln(r.out, "?SYNTAX ERROR")
+				return
+			}
+		}
+	}
+	lines, err := renum.Lines(r.lines, start, step)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return
+	}
+	r.lines = lines
+	r.program = nil
+	r.clearVariables()
+	r.autosave()
+}
+
+// parseProgram parses the stored program, reusing the previous parse when nothing changed
+func (r *REPL) parseProgram() (*parser.Program, error) {
+	if r.program != nil {
+		return r.program, nil
+	}
+	source := r.source()
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		return nil, e
+	}
+	r.program = program
+	r.interp.SetSource(source)
+	return program, nil
+}
+
+// newInterpreter creates an interpreter wired to the console runtime
+func (r *REPL) newInterpreter() *interpreter.Interpreter {
+	interp := interpreter.NewInterpreter(r.rt)
+	interp.SetMaxSteps(r.maxSteps)
+	interp.SetFiles(r.files)
+	return interp
+}
+
+// runProgram executes the stored program from the start, or from the line number in arg (RUN 200),
+// with fresh variables, or with the last run's when keepVars is set
+func (r *REPL) runProgram(arg string) {
+	start := -1
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n > parser.MaxLineNumber {
+			fmt.Fprintln(r.out, "?SYNTAX ERROR")
+			return
+		}
+		start = n
+	}
+	program, err := r.parseProgram()
+	if err != nil {
+		fmt.Fprintf(r.out, "?SYNTAX ERROR: %v\n", err)
+		return
+	}
+	if r.keepVars {
+		r.interp.Restart()
+	} else {
+		r.interp.Reset()
+	}
+	if start >= 0 {
+		err = r.interp.ExecuteFrom(program, start)
+	} else {
+		err = r.interp.Execute(program)
+	}
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+	r.finishRun()
+}
+
+// continueProgram resumes the stored program after STOP; editing it since makes CONT fail
+func (r *REPL) continueProgram() {
+	program, err := r.parseProgram()
+	if err != nil {
+		fmt.Fprintln(r.out, interpreter.ErrCantContinue)
+		return
+	}
+	if err := r.interp.Continue(program); err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+	r.finishRun()
+}
+
+// finishRun reports a STOP as BREAK IN and deletes the stored program after NEW
+func (r *REPL) finishRun() {
+	if line, ok := r.interp.BreakLine(); ok {
+		fmt.Fprintf(r.out, "BREAK IN %d\n", line)
+	}
+	if !r.interp.ProgramErased() {
+		return
+	}
+	r.lines = make(map[int]string)
+	r.program = nil
+	r.autosave()
+}
+
+// clearVariables forgets variables after the program changes, as the C64 does, unless keepVars is set
+func (r *REPL) clearVariables() {
+	if !r.keepVars {
+		_ = r.interp.ClearVariables()
+	}
+}
+
+// executeImmediate parses and runs a line entered without a line number
+func (r *REPL) executeImmediate(line string) {
+	p := parser.New(lexer.New(line))
+	stmts := p.ParseStatements()
+	if e := p.ParseError(); e != nil {
+		fmt.Fprintf(r.out, "?SYNTAX ERROR\nhint: %s\n", e.Message)
+		return
+	}
+
+	program, err := r.parseProgram()
+	if err != nil {
+		program = &parser.Program{}
+	}
+	if err := r.interp.ExecuteImmediate(program, stmts); err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+	r.finishRun()
+}
+
+// consoleRuntime performs program I/O through the REPL's reader and writer
+type consoleRuntime struct {
+	reader LineReader
+	out    io.Writer
+	rng    *rand.Rand
+	keys   []rune // Typed characters not yet consumed by GET
+}
+
+// Print writes text without a newline
+func (c *consoleRuntime) Print(value string) error {
+	_, err := fmt.Fprint(c.out, value)
+	return err
+}
+
+// PrintLine writes text followed by a newline
+func (c *consoleRuntime) PrintLine(value string) error {
+	_, err := fmt.Fprintln(c.out, value)
+	return err
+}
+
+// Input reads a line through the REPL's line reader
+func (c *consoleRuntime) Input(prompt string) (string, error) {
+	line, err := c.reader.ReadLine(prompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Clear is a no-op on the console
+func (c *consoleRuntime) Clear() error {
+	return nil
+}
+
+// Locate moves the terminal cursor with an ANSI escape sequence
+func (c *consoleRuntime) Locate(row, col int) error {
+	seq, err := runtime.LocateSequence(row, col)
+	if err != nil {
+		return err
+	}
+	return c.Print(seq)
+}
+
+// Random returns a pseudo-random float64 in [0,1)
+func (c *consoleRuntime) Random() float64 {
+	return c.rng.Float64()
+}
+
+// Now returns the wall-clock time
+func (c *consoleRuntime) Now() time.Time {
+	return time.Now()
+}
+
+// GetKey returns the next typed character; like the console, input is line buffered and ends with RETURN
+func (c *consoleRuntime) GetKey() (string, error) {
+	if len(c.keys) == 0 {
+		line, err := c.reader.ReadLine("")
+		if err != nil {
+			return "", err
+		}
+		c.keys = []rune(strings.TrimRight(line, "\r\n") + "\r")
+	}
+	key := c.keys[0]
+	c.keys = c.keys[1:]
+	return string(key), nil
+}
@@ -0,0 +1,303 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedReader returns predefined lines, then io.EOF
+type scriptedReader struct {
+	lines   []string
+	prompts []string
+}
+
+func (s *scriptedReader) ReadLine(prompt string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.lines) == 0 {
+		return "", io.EOF
+	}
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+	return line, nil
+}
+
+func runSession(t *testing.T, lines ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	r := New(&scriptedReader{lines: lines}, &out, nil)
+	require.NoError(t, r.Run())
+	return out.String()
+}
+
+func TestREPL_StoresAndRunsProgram(t *testing.T) {
+	out := runSession(t,
+		`20 PRINT "WORLD"`,
+		`10 PRINT "HELLO"`,
+		"RUN",
+	)
+	assert.Equal(t, "READY.\nHELLO\nWORLD\nREADY.\n", out)
+}
+
+func TestREPL_Banner(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&scriptedReader{lines: []string{"PRINT 1"}}, &out, nil)
+	r.SetBanner(true)
+	require.NoError(t, r.Run())
+	assert.Equal(t, "\n    **** COMMODORE 64 BASIC V2 ****\n\n 64K RAM SYSTEM  38911 BASIC BYTES FREE\n\nREADY.\n1\nREADY.\n", out.String())
+}
+
+func TestREPL_InterruptBreaksAndContinues(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&scriptedReader{}, &out, nil)
+	interrupt, stop, stopped := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// Interrupts sent before the run starts are dropped, so keep sending until it breaks
+		for {
+			select {
+			case interrupt <- struct{}{}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	r.SetInterrupt(interrupt)
+
+	r.HandleLine("10 IF S=0 THEN 10")
+	r.HandleLine(`20 PRINT "DONE"`)
+	r.HandleLine("RUN")
+	close(stop)
+	<-stopped
+	r.HandleLine("S=1")
+	r.HandleLine("CONT")
+	assert.Equal(t, "BREAK IN 10\nREADY.\nREADY.\nDONE\nREADY.\n", out.String())
+}
+
+func TestREPL_ReplacesAndDeletesLines(t *testing.T) {
+	out := runSession(t,
+		`10 PRINT "OLD"`,
+		`10 PRINT "NEW"`,
+		`20 PRINT "GONE"`,
+		"20",
+		"RUN",
+	)
+	assert.Equal(t, "READY.\nNEW\nREADY.\n", out)
+}
+
+func TestREPL_RejectsLineNumbersAbove63999(t *testing.T) {
+	out := runSession(t,
+		`64000 PRINT "HIGH"`,
+		`63999 PRINT "LAST"`,
+		"RUN",
+	)
+	assert.Equal(t, "READY.\n?SYNTAX ERROR\nLAST\nREADY.\n", out)
+}
+
+func TestREPL_ListRanges(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"LIST", "10 REM A\n20 REM B\n30 REM C\n"},
+		{"LIST 20", "20 REM B\n"},
+		{"LIST 15-30", "20 REM B\n30 REM C\n"},
+		{"LIST -20", "10 REM A\n20 REM B\n"},
+		{"LIST 20-", "20 REM B\n30 REM C\n"},
+		{"LIST X", "?SYNTAX ERROR\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			out := runSession(t, "30 REM C", "10 REM A", "20 REM B", tt.command)
+			assert.Equal(t, "READY.\n"+tt.expected+"READY.\n", out)
+		})
+	}
+}
+
+func TestREPL_Renum(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"RENUM", "READY.\n10 GOSUB 30\n20 GOTO 10\n30 RETURN\n"},
+		{"RENUM 100", "READY.\n100 GOSUB 120\n110 GOTO 100\n120 RETURN\n"},
+		{"RENUM 100, 5", "READY.\n100 GOSUB 110\n105 GOTO 100\n110 RETURN\n"},
+		{"RENUM X", "?SYNTAX ERROR\nREADY.\n3 GOSUB 9\n5 GOTO 3\n9 RETURN\n"},
+		{"RENUM 1,0", "?ILLEGAL QUANTITY ERROR\nREADY.\n3 GOSUB 9\n5 GOTO 3\n9 RETURN\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			out := runSession(t, "3 GOSUB 9", "5 GOTO 3", "9 RETURN", tt.command, "LIST")
+			assert.Equal(t, "READY.\n"+tt.expected+"READY.\n", out)
+		})
+	}
+}
+
+func TestREPL_New(t *testing.T) {
+	t.Run("immediate NEW deletes the program and variables", func(t *testing.T) {
+		out := runSession(t, "10 PRINT \"OLD\"", "A=5", "NEW", "LIST", "PRINT A", "RUN")
+		assert.Equal(t, "READY.\nREADY.\nREADY.\nREADY.\n0\nREADY.\nREADY.\n", out)
+	})
+
+	t.Run("NEW in a program ends the run and deletes the program", func(t *testing.T) {
+		out := runSession(t, "10 A=5: PRINT \"BYE\": NEW", "20 PRINT \"NOT REACHED\"", "RUN", "LIST", "PRINT A")
+		assert.Equal(t, "READY.\nBYE\nREADY.\nREADY.\n0\nREADY.\n", out)
+	})
+}
+
+func TestREPL_Cont(t *testing.T) {
+	t.Run("resumes inside a loop after STOP", func(t *testing.T) {
+		out := runSession(t, "10 FOR I=1 TO 3: PRINT I: IF I=2 THEN STOP", "20 NEXT I: PRINT \"DONE\";A", "RUN", "PRINT I", "A=7", "CONT")
+		assert.Equal(t, "READY.\n1\n2\nBREAK IN 10\nREADY.\n2\nREADY.\nREADY.\n3\nDONE 7\nREADY.\n", out)
+	})
+
+	t.Run("editing the program prevents CONT", func(t *testing.T) {
+		out := runSession(t, "10 STOP", "20 PRINT \"AFTER\"", "RUN", "30 END", "CONT")
+		assert.Equal(t, "READY.\nBREAK IN 10\nREADY.\n?CAN'T CONTINUE ERROR\nREADY.\n", out)
+	})
+
+	t.Run("nothing to continue", func(t *testing.T) {
+		out := runSession(t, "10 PRINT \"HI\"", "RUN", "CONT")
+		assert.Equal(t, "READY.\nHI\nREADY.\n?CAN'T CONTINUE ERROR\nREADY.\n", out)
+	})
+}
+
+func TestREPL_RunFromLine(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"RUN 20", "B\nREADY.\n"},
+		{"RUN 15", "?UNDEFINED STATEMENT ERROR: NO LINE 15\nREADY.\n"},
+		{"RUN X", "?SYNTAX ERROR\nREADY.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			out := runSession(t, "10 PRINT \"A\"", "20 PRINT \"B\"", tt.command)
+			assert.Equal(t, "READY.\n"+tt.expected, out)
+		})
+	}
+}
+
+func TestREPL_ImmediateModeSharesVariablesWithLastRun(t *testing.T) {
+	out := runSession(t,
+		"10 A=42",
+		"RUN",
+		"PRINT A+1",
+	)
+	assert.Equal(t, "READY.\nREADY.\n43\nREADY.\n", out)
+}
+
+func TestREPL_VariablePersistence(t *testing.T) {
+	tests := []struct {
+		name     string
+		keepVars bool
+		lines    []string
+		expected string
+	}{
+		{
+			name:     "RUN clears variables set in immediate mode",
+			lines:    []string{"10 PRINT A", "A=5", "RUN"},
+			expected: "READY.\nREADY.\n0\nREADY.\n",
+		},
+		{
+			name:     "failed run leaves its variables to inspect",
+			lines:    []string{"10 A=7:B=0:PRINT C(20)", "RUN", "PRINT A"},
+			expected: "READY.\n?BAD SUBSCRIPT ERROR IN 10\nREADY.\n7\nREADY.\n",
+		},
+		{
+			name:     "editing a line clears variables",
+			lines:    []string{"A=5", "10 REM", "PRINT A"},
+			expected: "READY.\nREADY.\n0\nREADY.\n",
+		},
+		{
+			name:     "keep-vars seeds the next run",
+			keepVars: true,
+			lines:    []string{"10 A=A+1:PRINT A", "RUN", "RUN", "A=10", "RUN"},
+			expected: "READY.\n1\nREADY.\n2\nREADY.\nREADY.\n11\nREADY.\n",
+		},
+		{
+			name:     "keep-vars keeps variables across edits",
+			keepVars: true,
+			lines:    []string{"A=5", "10 PRINT A", "RUN"},
+			expected: "READY.\nREADY.\n5\nREADY.\n",
+		},
+		{
+			name:     "keep-vars still clears arrays so DIM runs again",
+			keepVars: true,
+			lines:    []string{"10 DIM B(3):B(1)=B(1)+1:PRINT B(1)", "RUN", "RUN"},
+			expected: "READY.\n1\nREADY.\n1\nREADY.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			r := New(&scriptedReader{lines: tt.lines}, &out, nil)
+			r.SetKeepVars(tt.keepVars)
+			require.NoError(t, r.Run())
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestREPL_ImmediateGotoContinuesIntoProgram(t *testing.T) {
+	out := runSession(t,
+		`10 PRINT "TEN"`,
+		`20 PRINT "TWENTY"`,
+		"GOTO 20",
+	)
+	assert.Equal(t, "READY.\nTWENTY\nREADY.\n", out)
+}
+
+func TestREPL_SyntaxHints(t *testing.T) {
+	t.Run("numbered line is stored with a hint", func(t *testing.T) {
+		out := runSession(t, "10 IF A PRINT")
+		assert.Contains(t, out, "hint: expected THEN")
+	})
+
+	t.Run("immediate line reports syntax error", func(t *testing.T) {
+		out := runSession(t, "PRNT")
+		assert.Contains(t, out, "?SYNTAX ERROR\nhint:")
+	})
+}
+
+func TestREPL_RuntimeErrorsAreReported(t *testing.T) {
+	out := runSession(t, "10 PRINT 1/0", "RUN")
+	assert.Contains(t, out, "?DIVISION BY ZERO ERROR IN 10\n")
+}
+
+func TestREPL_InputUsesLineReader(t *testing.T) {
+	reader := &scriptedReader{lines: []string{"10 INPUT \"NAME\"; N$", "20 PRINT N$", "RUN", "ADA"}}
+	var out bytes.Buffer
+	require.NoError(t, New(reader, &out, nil).Run())
+	assert.Contains(t, reader.prompts, "NAME")
+	assert.Contains(t, out.String(), "ADA\n")
+}
+
+func TestREPL_QuitAndHistory(t *testing.T) {
+	history := NewHistory(10)
+	reader := &scriptedReader{lines: []string{"PRINT 1", "QUIT", "PRINT 2"}}
+	var out bytes.Buffer
+	require.NoError(t, New(reader, &out, history).Run())
+	assert.Equal(t, "READY.\n1\nREADY.\n", out.String())
+	assert.Equal(t, 2, history.Len())
+	assert.Equal(t, "QUIT", history.At(1))
+}
+
+func TestREPL_List(t *testing.T) {
+	out := runSession(t,
+		`20 PRINT "B"`,
+		`10 PRINT "A"`,
+		"LIST",
+	)
+	assert.Equal(t, "READY.\n10 PRINT \"A\"\n20 PRINT \"B\"\nREADY.\n", out)
+
+	var buf bytes.Buffer
+	r := New(&scriptedReader{lines: []string{"10 END", "LIST"}}, &buf, nil)
+	r.SetColor(true)
+	require.NoError(t, r.Run())
+	assert.Contains(t, buf.String(), "\x1b[33m10\x1b[0m \x1b[1;34mEND\x1b[0m")
+}
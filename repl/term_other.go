@@ -0,0 +1,13 @@
+//go:build !linux
+
+// ABOUTME: Fallback terminal handling for platforms without raw mode support
+// ABOUTME: Reports raw mode as unavailable so the line editor reads cooked lines
+
+package repl
+
+import "errors"
+
+// makeRaw is not supported on this platform
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode not supported")
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Session transcripts for the REPL: timestamped capture of typed input and printed output
+// ABOUTME: Wraps the REPL's line reader and writer so every exchange is mirrored to the transcript file
+
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// transcript appends timestamped IN/OUT records to a file
+type transcript struct {
+	file    *os.File
+	path    string
+	clock   func() time.Time
+	pending strings.Builder // Output not yet terminated by a newline
+}
+
+// openTranscript opens (appending to) the transcript file at path
+func openTranscript(path string, clock func() time.Time) (*transcript, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &transcript{file: file, path: path, clock: clock}, nil
+}
+
+// record writes one record with the given direction tag
+func (t *transcript) record(tag, text string) {
+	fmt.Fprintf(t.file, "%s %-3s %s\n", t.clock().UTC().Format(time.RFC3339), tag, text)
+}
+
+// input records a line typed by the user, preceded by any partial output such as a prompt
+func (t *transcript) input(prompt, line string) {
+	if t.pending.Len() > 0 {
+		prompt = t.pending.String() + prompt
+		t.pending.Reset()
+	}
+	t.record("IN", prompt+line)
+}
+
+// output records printed text one complete line at a time
+func (t *transcript) output(text string) {
+	t.pending.WriteString(text)
+	buffered := t.pending.String()
+	lines := strings.Split(buffered, "\n")
+	for _, line := range lines[:len(lines)-1] {
+		t.record("OUT", line)
+	}
+	t.pending.Reset()
+	t.pending.WriteString(lines[len(lines)-1])
+}
+
+// close flushes any partial output line and closes the file
+func (t *transcript) close() error {
+	if t.pending.Len() > 0 {
+		t.record("OUT", t.pending.String())
+		t.pending.Reset()
+	}
+	return t.file.Close()
+}
+
+// transcriptWriter forwards output and mirrors it into the REPL's active transcript
+type transcriptWriter struct {
+	out  io.Writer
+	repl *REPL
+}
+
+// Write implements io.Writer
+func (w *transcriptWriter) Write(p []byte) (int, error) {
+	if t := w.repl.transcript; t != nil {
+		t.output(string(p))
+	}
+	return w.out.Write(p)
+}
+
+// transcriptReader reads lines and mirrors them into the REPL's active transcript
+type transcriptReader struct {
+	reader LineReader
+	repl   *REPL
+}
+
+// ReadLine implements LineReader
+func (r *transcriptReader) ReadLine(prompt string) (string, error) {
+	line, err := r.reader.ReadLine(prompt)
+	if t := r.repl.transcript; t != nil && err == nil {
+		t.input(prompt, line)
+	}
+	return line, err
+}
+
+// toggleTranscript starts a transcript to path, or stops the active one when path is empty
+func (r *REPL) toggleTranscript(path string) {
+	if r.transcript != nil {
+		name := r.transcript.path
+		r.stopTranscript()
+		fmt.Fprintf(r.out, "TRANSCRIPT OFF: %s\n", name)
+	}
+	if path == "" {
+		return
+	}
+
+	t, err := openTranscript(path, r.clock)
+	if err != nil {
+		fmt.Fprintf(r.out, "?FILE ERROR: %v\n", err)
+		return
+	}
+	r.transcript = t
+	fmt.Fprintf(r.out, "TRANSCRIPT ON: %s\n", path)
+}
+
+// stopTranscript closes the active transcript, if any
+func (r *REPL) stopTranscript() {
+	if r.transcript != nil {
+		_ = r.transcript.close()
+		r.transcript = nil
+	}
+}
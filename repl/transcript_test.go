@@ -0,0 +1,57 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestREPL_Transcript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Session.txt")
+	var out bytes.Buffer
+	r := New(&scriptedReader{lines: []string{
+		`PRINT "BEFORE"`,
+		`TRANSCRIPT "` + path + `"`,
+		`10 INPUT "NAME"; N$`,
+		`20 PRINT "HI "; N$`,
+		"RUN",
+		"ADA",
+		"TRANSCRIPT",
+		`PRINT "AFTER"`,
+	}}, &out, nil)
+	r.clock = func() time.Time { return time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) }
+	require.NoError(t, r.Run())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"2026-10-16T09:30:00Z OUT TRANSCRIPT ON: "+path+"\n"+
+		"2026-10-16T09:30:00Z OUT READY.\n"+
+		"2026-10-16T09:30:00Z IN  10 INPUT \"NAME\"; N$\n"+
+		"2026-10-16T09:30:00Z IN  20 PRINT \"HI \"; N$\n"+
+		"2026-10-16T09:30:00Z IN  RUN\n"+
+		"2026-10-16T09:30:00Z IN  NAMEADA\n"+
+		"2026-10-16T09:30:00Z OUT HI ADA\n"+
+		"2026-10-16T09:30:00Z OUT READY.\n"+
+		"2026-10-16T09:30:00Z IN  TRANSCRIPT\n", string(data))
+
+	assert.Contains(t, out.String(), "TRANSCRIPT OFF: "+path)
+	assert.Contains(t, out.String(), "AFTER")
+}
+
+func TestREPL_TranscriptBadPath(t *testing.T) {
+	out := runSession(t, `TRANSCRIPT "`+filepath.Join(t.TempDir(), "missing", "x.txt")+`"`)
+	assert.Contains(t, out, "?FILE ERROR")
+}
+
+func TestQuotedArgument(t *testing.T) {
+	assert.Equal(t, "a.txt", quotedArgument(` "a.txt"`))
+	assert.Equal(t, "My Log.txt", quotedArgument(`"My Log.txt`))
+	assert.Equal(t, "b.txt", quotedArgument(" b.txt "))
+	assert.Equal(t, "", quotedArgument(""))
+}
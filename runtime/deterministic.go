@@ -0,0 +1,80 @@
+// ABOUTME: Deterministic simulation runtime with a virtual clock, seeded RNG and scripted keystrokes
+// ABOUTME: Lets interactive and game programs using TI, TIMER, RND and GET be replayed frame for frame in tests
+
+package runtime
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Jiffy is one tick of the C64 system clock (1/60 s), the unit of TI
+const Jiffy = time.Second / 60
+
+// DeterministicEpoch is the virtual time a DeterministicRuntime starts at
+var DeterministicEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DeterministicRuntime captures output like TestRuntime but fixes time, randomness and input.
+// The virtual clock advances by one jiffy every time it is read, so busy-wait loops on TI terminate.
+// The script is consumed keystroke by keystroke by GET and line by line (up to "\n") by INPUT.
+type DeterministicRuntime struct {
+	*TestRuntime
+	now    time.Time
+	script []rune
+}
+
+// NewDeterministicRuntime creates a runtime whose RND sequence comes from seed and whose keys come from script
+func NewDeterministicRuntime(seed int64, script string) *DeterministicRuntime {
+	test := NewTestRuntime()
+	test.rng = rand.New(rand.NewSource(seed))
+	return &DeterministicRuntime{
+		TestRuntime: test,
+		now:         DeterministicEpoch,
+		script:      []rune(strings.ReplaceAll(script, "\r\n", "\n")),
+	}
+}
+
+// Now returns the virtual time and then advances it by one jiffy
+func (d *DeterministicRuntime) Now() time.Time {
+	now := d.now
+	d.now = d.now.Add(Jiffy)
+	return now
+}
+
+// Advance moves the virtual clock forward without reading it
+func (d *DeterministicRuntime) Advance(elapsed time.Duration) {
+	d.now = d.now.Add(elapsed)
+}
+
+// GetKey returns the next scripted keystroke, "\r" for a newline, or "" once the script is used up
+func (d *DeterministicRuntime) GetKey() (string, error) {
+	if len(d.script) == 0 {
+		return "", nil
+	}
+	ch := d.script[0]
+	d.script = d.script[1:]
+	if ch == '\n' {
+		return "\r", nil
+	}
+	return string(ch), nil
+}
+
+// Input returns the scripted keystrokes up to the next newline
+func (d *DeterministicRuntime) Input(prompt string) (string, error) {
+	if prompt != "" {
+		d.outputBuffer = append(d.outputBuffer, prompt)
+	}
+	if len(d.script) == 0 {
+		return "", fmt.Errorf("no more input available in script")
+	}
+	line := string(d.script)
+	if nl := strings.IndexRune(line, '\n'); nl >= 0 {
+		line = line[:nl]
+		d.script = d.script[len([]rune(line))+1:]
+	} else {
+		d.script = nil
+	}
+	return line, nil
+}
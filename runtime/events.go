@@ -0,0 +1,75 @@
+// ABOUTME: Event runtime emitting typed output events (Print, PrintLine, InputRequest, Clear) to a callback or channel
+// ABOUTME: Lets web frontends and GUIs render output as it is produced; input, keys, time and RND come from a wrapped runtime
+
+package runtime
+
+// EventKind tells what an output event does
+type EventKind int
+
+const (
+	EventPrint        EventKind = iota // Text printed without a newline
+	EventPrintLine                     // Text printed followed by a newline
+	EventInputRequest                  // INPUT waits for a line; Text is the prompt
+	EventClear                         // The screen is cleared
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventPrint:
+		return "Print"
+	case EventPrintLine:
+		return "PrintLine"
+	case EventInputRequest:
+		return "InputRequest"
+	case EventClear:
+		return "Clear"
+	}
+	return "Unknown"
+}
+
+// Event is one piece of output
+type Event struct {
+	Kind EventKind
+	Text string // Text printed, without the newline of PrintLine, or the INPUT prompt
+}
+
+// EventRuntime hands each piece of output to a function as an Event instead of printing it.
+// Lines for INPUT, keys, time and random numbers come from the wrapped runtime, whose output is not used.
+type EventRuntime struct {
+	Runtime // Source of input, keys, time and random numbers
+	emit    func(Event)
+}
+
+// NewEventRuntime creates a runtime calling emit for each event, on the goroutine running the program
+func NewEventRuntime(inner Runtime, emit func(Event)) *EventRuntime {
+	return &EventRuntime{Runtime: inner, emit: emit}
+}
+
+// NewChannelRuntime creates a runtime sending each event on events; the program waits while the channel is full
+func NewChannelRuntime(inner Runtime, events chan<- Event) *EventRuntime {
+	return NewEventRuntime(inner, func(e Event) { events <- e })
+}
+
+// Print emits a Print event
+func (e *EventRuntime) Print(value string) error {
+	e.emit(Event{Kind: EventPrint, Text: value})
+	return nil
+}
+
+// PrintLine emits a PrintLine event
+func (e *EventRuntime) PrintLine(value string) error {
+	e.emit(Event{Kind: EventPrintLine, Text: value})
+	return nil
+}
+
+// Input emits an InputRequest event with the prompt, then reads the line from the wrapped runtime
+func (e *EventRuntime) Input(prompt string) (string, error) {
+	e.emit(Event{Kind: EventInputRequest, Text: prompt})
+	return e.Runtime.Input("")
+}
+
+// Clear emits a Clear event
+func (e *EventRuntime) Clear() error {
+	e.emit(Event{Kind: EventClear})
+	return nil
+}
@@ -0,0 +1,44 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRuntime_Events(t *testing.T) {
+	inner := NewTestRuntime()
+	inner.SetInput([]string{"42"})
+	var events []Event
+	rt := NewEventRuntime(inner, func(e Event) { events = append(events, e) })
+
+	require.NoError(t, rt.Print("A"))
+	require.NoError(t, rt.PrintLine("B"))
+	line, err := rt.Input("? ")
+	require.NoError(t, err)
+	assert.Equal(t, "42", line)
+	require.NoError(t, rt.Clear())
+
+	assert.Equal(t, []Event{
+		{Kind: EventPrint, Text: "A"},
+		{Kind: EventPrintLine, Text: "B"},
+		{Kind: EventInputRequest, Text: "? "},
+		{Kind: EventClear},
+	}, events)
+	assert.Empty(t, inner.GetOutput(), "the wrapped runtime prints nothing")
+}
+
+func TestChannelRuntime_SendsEvents(t *testing.T) {
+	events := make(chan Event, 2)
+	rt := NewChannelRuntime(NewTestRuntime(), events)
+	require.NoError(t, rt.PrintLine("HI"))
+	require.NoError(t, rt.Clear())
+	assert.Equal(t, Event{Kind: EventPrintLine, Text: "HI"}, <-events)
+	assert.Equal(t, Event{Kind: EventClear}, <-events)
+}
+
+func TestEventKind_String(t *testing.T) {
+	assert.Equal(t, "InputRequest", EventInputRequest.String())
+	assert.Equal(t, "Unknown", EventKind(99).String())
+}
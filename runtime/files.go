@@ -0,0 +1,136 @@
+// ABOUTME: File API for OPEN, CLOSE, PRINT#, INPUT# and GET#: runtimes with devices such as disk drive 8 open files
+// ABOUTME: ParseFileName reads the C64 "0:NAME,S,W" form of a file name into a plain name and a mode
+
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// DiskDevice is the device number of the first disk drive
+const DiskDevice = 8
+
+// ErrDeviceNotPresent is returned by Files.OpenFile for a device the runtime does not have
+var ErrDeviceNotPresent = errors.New("device not present")
+
+// FileMode is how OPEN uses a file
+type FileMode int
+
+// File modes
+const (
+	FileRead   FileMode = iota // Read from the start (",R", the default)
+	FileWrite                  // Create or replace the file (",W", and secondary address 1)
+	FileAppend                 // Write after the end of an existing file (",A")
+)
+
+// File is a file opened on a device; only the side its mode allows is used
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+}
+
+// Stream is implemented by files whose data arrives while they are open, such as a link to another program.
+// Reading one looks ahead for the end of the file, to set ST, only when that would not wait for data.
+type Stream interface {
+	File
+	// Ready reports whether a read would return without waiting: data has arrived or the sender closed the file
+	Ready() bool
+}
+
+// Files is implemented by runtimes with storage devices. The keyboard (device 0) and the screen (device 3) are
+// the interpreter's own; other devices are opened here.
+type Files interface {
+	// OpenFile opens name on a device with a secondary address (channel). A missing file fails with an error
+	// matching fs.ErrNotExist, a device the runtime lacks with ErrDeviceNotPresent.
+	OpenFile(device, channel int, name string, mode FileMode) (File, error)
+}
+
+// ParseFileName splits the file name given to OPEN, such as "@0:SCORES,S,W", into the name and its mode. A drive
+// number and the @ of replacing are dropped, as is the file type; without a mode letter, secondary address 1
+// writes and any other reads.
+func ParseFileName(name string, channel int) (string, FileMode) {
+	mode := FileRead
+	if channel == 1 {
+		mode = FileWrite
+	}
+	name = strings.TrimPrefix(name, "@")
+	if colon := strings.IndexByte(name, ':'); colon >= 0 && strings.Trim(name[:colon], "0123456789") == "" {
+		name = name[colon+1:]
+	}
+	parts := strings.Split(name, ",")
+	for _, part := range parts[1:] {
+		switch strings.ToUpper(strings.TrimSpace(part)) {
+		case "R":
+			mode = FileRead
+		case "W":
+			mode = FileWrite
+		case "A":
+			mode = FileAppend
+		}
+	}
+	return parts[0], mode
+}
+
+// Disk is an in-memory disk drive 8 holding files by name. Embed it to give a runtime OPEN on the disk.
+type Disk struct {
+	files map[string][]byte // Allocated on the first write
+}
+
+// OpenFile implements Files for device 8
+func (d *Disk) OpenFile(device, channel int, name string, mode FileMode) (File, error) {
+	if device != DiskDevice {
+		return nil, ErrDeviceNotPresent
+	}
+	data, exists := d.files[name]
+	switch {
+	case mode == FileRead && exists:
+		return &diskFile{reader: bytes.NewReader(data)}, nil
+	case mode == FileWrite:
+		d.SetFile(name, "")
+	case !exists:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &diskFile{disk: d, name: name}, nil
+}
+
+// SetFile stores a file on the disk, replacing any file of that name
+func (d *Disk) SetFile(name, content string) {
+	if d.files == nil {
+		d.files = make(map[string][]byte)
+	}
+	d.files[name] = []byte(content)
+}
+
+// FileContent returns a file on the disk and whether it exists
+func (d *Disk) FileContent(name string) (string, bool) {
+	data, ok := d.files[name]
+	return string(data), ok
+}
+
+// diskFile is a file open on a Disk: reading a copy of its content, or appending to it
+type diskFile struct {
+	reader *bytes.Reader
+	disk   *Disk
+	name   string
+}
+
+func (f *diskFile) Read(p []byte) (int, error) {
+	if f.reader == nil {
+		return 0, io.EOF
+	}
+	return f.reader.Read(p)
+}
+
+func (f *diskFile) Write(p []byte) (int, error) {
+	f.disk.files[f.name] = append(f.disk.files[f.name], p...)
+	return len(p), nil
+}
+
+func (f *diskFile) Close() error {
+	return nil
+}
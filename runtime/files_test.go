@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel int
+		want    string
+		mode    FileMode
+	}{
+		{"SCORES", 2, "SCORES", FileRead},
+		{"SCORES", 1, "SCORES", FileWrite},
+		{"0:SCORES,S,W", 2, "SCORES", FileWrite},
+		{"@0:SCORES,S,W", 2, "SCORES", FileWrite},
+		{"SCORES,SEQ,A", 2, "SCORES", FileAppend},
+		{"SCORES,S,R", 1, "SCORES", FileRead},
+		{"A:B", 2, "A:B", FileRead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, mode := ParseFileName(tt.name, tt.channel)
+			assert.Equal(t, tt.want, name)
+			assert.Equal(t, tt.mode, mode)
+		})
+	}
+}
+
+func TestDisk_OpenFile(t *testing.T) {
+	var d Disk
+	_, err := d.OpenFile(DiskDevice, 2, "MISSING", FileRead)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = d.OpenFile(DiskDevice, 2, "MISSING", FileAppend)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = d.OpenFile(4, 0, "", FileWrite)
+	assert.ErrorIs(t, err, ErrDeviceNotPresent)
+
+	w, err := d.OpenFile(DiskDevice, 2, "F", FileWrite)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "ONE\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	a, err := d.OpenFile(DiskDevice, 2, "F", FileAppend)
+	require.NoError(t, err)
+	_, err = io.WriteString(a, "TWO\n")
+	require.NoError(t, err)
+
+	r, err := d.OpenFile(DiskDevice, 2, "F", FileRead)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\nTWO\n", string(data))
+
+	_, err = d.OpenFile(DiskDevice, 2, "F", FileWrite)
+	require.NoError(t, err)
+	content, ok := d.FileContent("F")
+	assert.True(t, ok)
+	assert.Equal(t, "", content, "writing replaces the file")
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Emulated C64 memory for PEEK and POKE, with the 1000-byte screen RAM at 1024 and screen code conversion
+// ABOUTME: Tests decode the screen RAM of programs that draw with POKE 1024+... into text rows
+
+package runtime
+
+import "strings"
+
+// Memory layout of the emulated machine
+const (
+	MemorySize    = 65536                      // Addresses run from 0 to MemorySize-1
+	ScreenRAM     = 1024                       // Address of the top-left character of the screen
+	ScreenRAMSize = ScreenRows * ScreenColumns // Bytes of screen RAM, one screen code per character
+	blankCode     = 32                         // Screen code of a space
+	unknownCode   = 63                         // Screen code of ?, stored for characters with no screen code
+)
+
+// Memory is implemented by runtimes with emulated RAM, for PEEK and POKE.
+// Addresses are within 0..MemorySize-1; callers check the range.
+type Memory interface {
+	Peek(address int) byte
+	Poke(address int, value byte)
+}
+
+// RAM is 64K of emulated memory; the screen RAM starts out blank. Embed it to give a runtime PEEK and POKE.
+type RAM struct {
+	bytes []byte // Allocated on the first POKE
+}
+
+// Peek returns the byte at address
+func (m *RAM) Peek(address int) byte {
+	if m.bytes == nil {
+		if address >= ScreenRAM && address < ScreenRAM+ScreenRAMSize {
+			return blankCode
+		}
+		return 0
+	}
+	return m.bytes[address]
+}
+
+// Poke stores value at address
+func (m *RAM) Poke(address int, value byte) {
+	if m.bytes == nil {
+		m.bytes = make([]byte, MemorySize)
+		for a := ScreenRAM; a < ScreenRAM+ScreenRAMSize; a++ {
+			m.bytes[a] = blankCode
+		}
+	}
+	m.bytes[address] = value
+}
+
+// ScreenMemory returns a copy of the screen RAM, row by row
+func (m *RAM) ScreenMemory() []byte {
+	screen := make([]byte, ScreenRAMSize)
+	for n := range screen {
+		screen[n] = m.Peek(ScreenRAM + n)
+	}
+	return screen
+}
+
+// ScreenText decodes the screen RAM into 25 rows of text without trailing spaces
+func (m *RAM) ScreenText() []string {
+	return DecodeScreen(m.ScreenMemory())
+}
+
+// DecodeScreen decodes screen codes into rows of 40 characters, trimming trailing spaces
+func DecodeScreen(codes []byte) []string {
+	var rows []string
+	for start := 0; start < len(codes); start += ScreenColumns {
+		end := min(start+ScreenColumns, len(codes))
+		var row strings.Builder
+		for _, code := range codes[start:end] {
+			row.WriteRune(ScreenCodeRune(code))
+		}
+		rows = append(rows, strings.TrimRight(row.String(), " "))
+	}
+	return rows
+}
+
+// ScreenCodeRune returns the character a screen code shows in the upper case character set.
+// Reverse video codes (128 and up) show their normal character; graphics characters are U+FFFD.
+func ScreenCodeRune(code byte) rune {
+	code &= 0x7F
+	switch {
+	case code == 0:
+		return '@'
+	case code <= 26:
+		return rune('A' + code - 1)
+	case code < 32:
+		return []rune("[£]↑←")[code-27]
+	case code < 64:
+		return rune(code)
+	default:
+		return '�'
+	}
+}
+
+// RuneScreenCode returns the screen code showing ch; letters of either case give the upper case letter
+// and characters the screen cannot show give the code of ?
+func RuneScreenCode(ch rune) byte {
+	switch {
+	case ch == '@':
+		return 0
+	case ch >= 'A' && ch <= 'Z':
+		return byte(ch-'A') + 1
+	case ch >= 'a' && ch <= 'z':
+		return byte(ch-'a') + 1
+	case ch >= ' ' && ch < '@':
+		return byte(ch)
+	}
+	switch ch {
+	case '[':
+		return 27
+	case '£':
+		return 28
+	case ']':
+		return 29
+	case '↑', '^':
+		return 30
+	case '←', '_':
+		return 31
+	}
+	return unknownCode
+}
@@ -0,0 +1,70 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAM_PeekPoke(t *testing.T) {
+	var m RAM
+	assert.Equal(t, byte(0), m.Peek(0))
+	assert.Equal(t, byte(32), m.Peek(ScreenRAM), "the screen starts blank")
+
+	m.Poke(53280, 6)
+	m.Poke(ScreenRAM+41, 8)
+	assert.Equal(t, byte(6), m.Peek(53280))
+	assert.Equal(t, byte(32), m.Peek(ScreenRAM))
+
+	text := m.ScreenText()
+	require.Len(t, text, ScreenRows)
+	assert.Equal(t, " H", text[1])
+	assert.Equal(t, "", text[0])
+}
+
+func TestTestRuntime_ScreenText(t *testing.T) {
+	rt := NewTestRuntime()
+	for n, ch := range "HELLO" {
+		rt.Poke(ScreenRAM+n, RuneScreenCode(ch))
+	}
+	assert.Equal(t, "HELLO", rt.ScreenText()[0])
+	assert.Equal(t, []byte{8, 5, 12, 12, 15, 32}, rt.ScreenMemory()[:6])
+}
+
+func TestScreenCodes(t *testing.T) {
+	tests := []struct {
+		code byte
+		ch   rune
+	}{
+		{0, '@'}, {1, 'A'}, {26, 'Z'}, {27, '['}, {28, '£'}, {29, ']'}, {30, '↑'}, {31, '←'},
+		{32, ' '}, {49, '1'}, {63, '?'},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ch, ScreenCodeRune(tt.code), "code %d", tt.code)
+		assert.Equal(t, tt.code, RuneScreenCode(tt.ch), "character %q", tt.ch)
+	}
+	assert.Equal(t, 'A', ScreenCodeRune(129), "reverse video shows the normal character")
+	assert.Equal(t, '�', ScreenCodeRune(81), "graphics characters")
+	assert.Equal(t, byte(1), RuneScreenCode('a'))
+	assert.Equal(t, byte(30), RuneScreenCode('^'))
+	assert.Equal(t, byte(63), RuneScreenCode('é'))
+}
+
+func TestScreenRuntime_PokeDrawsAndPeekReadsPrintedText(t *testing.T) {
+	s := NewScreenRuntime(NewTestRuntime())
+	require.NoError(t, s.Print("AB"))
+	s.Poke(ScreenRAM+ScreenColumns, 3)     // C at the start of row 1
+	s.Poke(ScreenRAM+ScreenColumns+1, 129) // Reverse A
+
+	assert.Equal(t, []string{"AB", "CA"}, s.Lines()[:2])
+	assert.Equal(t, byte(1), s.Peek(ScreenRAM))
+	assert.Equal(t, byte(129), s.Peek(ScreenRAM+ScreenColumns+1), "POKEd codes read back unchanged")
+	assert.Equal(t, []string{"AB", "CA"}, DecodeScreen(s.ScreenMemory())[:2])
+
+	require.NoError(t, s.Clear())
+	assert.Equal(t, byte(32), s.Peek(ScreenRAM+ScreenColumns), "clearing the screen blanks the screen RAM")
+
+	s.Poke(2040, 13)
+	assert.Equal(t, byte(13), s.Peek(2040), "memory past the screen RAM")
+}
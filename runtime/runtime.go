@@ -3,6 +3,11 @@
 
 package runtime
 
+import (
+	"context"
+	"time"
+)
+
 // Runtime provides an interface for all I/O operations
 // This allows the interpreter to work with different environments (console, test, etc.)
 type Runtime interface {
@@ -21,4 +26,22 @@ type Runtime interface {
 	// Random returns a pseudo-random float64 in [0,1).
 	// Implementations may be deterministic (TestRuntime) or seeded (StandardRuntime).
 	Random() float64
+
+	// Now returns the current time, used by TI, TI$ and TIMER
+	Now() time.Time
+
+	// GetKey returns the next key pressed as a one-character string, or "" when none is waiting (GET)
+	GetKey() (string, error)
+}
+
+// ContextInput is implemented by runtimes whose INPUT can stop waiting for a line when a run is canceled or its
+// time limit passes; InputContext then returns ctx.Err()
+type ContextInput interface {
+	InputContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Sleeper is implemented by runtimes with their own clock, such as headless recorders running in emulated time.
+// Paced execution waits through Sleep and reads time from Now instead of the wall clock.
+type Sleeper interface {
+	Sleep(d time.Duration)
 }
@@ -4,7 +4,11 @@
 package runtime
 
 import (
+	"context"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -136,3 +140,112 @@ func TestTestRuntime_Input(t *testing.T) {
 		})
 	}
 }
+
+func TestDeterministicRuntime(t *testing.T) {
+	t.Run("clock starts at the epoch and ticks one jiffy per read", func(t *testing.T) {
+		rt := NewDeterministicRuntime(1, "")
+		assert.Equal(t, DeterministicEpoch, rt.Now())
+		assert.Equal(t, DeterministicEpoch.Add(Jiffy), rt.Now())
+		rt.Advance(time.Second)
+		assert.Equal(t, DeterministicEpoch.Add(2*Jiffy+time.Second), rt.Now())
+	})
+
+	t.Run("same seed gives the same random sequence", func(t *testing.T) {
+		a, b := NewDeterministicRuntime(42, ""), NewDeterministicRuntime(42, "")
+		for n := 0; n < 5; n++ {
+			assert.Equal(t, a.Random(), b.Random())
+		}
+		assert.NotEqual(t, NewDeterministicRuntime(7, "").Random(), NewDeterministicRuntime(42, "").Random())
+	})
+
+	t.Run("script feeds GET and INPUT", func(t *testing.T) {
+		rt := NewDeterministicRuntime(1, "AB\nHELLO\nW")
+		for _, want := range []string{"A", "B", "\r"} {
+			key, err := rt.GetKey()
+			require.NoError(t, err)
+			assert.Equal(t, want, key)
+		}
+		line, err := rt.Input("? ")
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO", line)
+		line, err = rt.Input("")
+		require.NoError(t, err)
+		assert.Equal(t, "W", line)
+
+		key, err := rt.GetKey()
+		require.NoError(t, err)
+		assert.Equal(t, "", key)
+		_, err = rt.Input("")
+		assert.Error(t, err)
+		assert.Equal(t, []string{"? "}, rt.GetOutput())
+	})
+}
+
+func TestCRLFLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "newline", value: "A\nB\n", expected: "A\r\nB\r\n"},
+		{name: "CHR$(13)", value: "A\rB", expected: "A\r\nB"},
+		{name: "already CRLF", value: "A\r\n", expected: "A\r\n"},
+		{name: "CHR$(13) before a newline", value: "A\r\r\n", expected: "A\r\n\r\n"},
+		{name: "no line ending", value: "A", expected: "A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CRLFLines(tt.value))
+		})
+	}
+}
+
+func TestTestRuntime_KeepsLineEndings(t *testing.T) {
+	runtime := NewTestRuntime()
+	require.NoError(t, runtime.PrintLine("A\rB"))
+	assert.Equal(t, []string{"A\rB\n"}, runtime.GetOutput())
+}
+
+func TestStandardRuntime_ReaderAndWriters(t *testing.T) {
+	var out, errOut strings.Builder
+	std := NewStandardRuntimeIO(strings.NewReader(" 42 \nXY"), &out, &errOut)
+	std.SetCRLF(false)
+
+	require.NoError(t, std.Print("A"))
+	require.NoError(t, std.PrintLine("B"))
+	line, err := std.Input("? ")
+	require.NoError(t, err)
+	assert.Equal(t, "42", line)
+	key, err := std.GetKey()
+	require.NoError(t, err)
+	assert.Equal(t, "X", key)
+
+	std.SetCRLF(true)
+	require.NoError(t, std.PrintLine("C"))
+	assert.Equal(t, "AB\n? C\r\n", out.String())
+	assert.Same(t, &errOut, std.ErrorOutput())
+	assert.Empty(t, errOut.String())
+}
+
+func TestStandardRuntime_InputContext(t *testing.T) {
+	in, typist := io.Pipe()
+	std := NewStandardRuntimeIO(in, io.Discard, io.Discard)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := std.InputContext(ctx, "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The line typed after the wait was given up goes to the next read
+	go func() { _, _ = io.WriteString(typist, "AB\n7\n") }()
+	key, err := std.GetKey()
+	require.NoError(t, err)
+	assert.Equal(t, "A", key)
+	line, err := std.Input("")
+	require.NoError(t, err)
+	assert.Equal(t, "B", line)
+	line, err = std.InputContext(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "7", line)
+}
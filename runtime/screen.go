@@ -0,0 +1,181 @@
+// ABOUTME: Screen-model runtime rendering output into a 25x40 character grid with a movable cursor
+// ABOUTME: Supports LOCATE, PRINT AT and POKEs to the screen RAM, and snapshots of the screen for tests; input, keys, time and randomness come from a wrapped runtime
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Screen dimensions of the C64 text screen
+const (
+	ScreenRows    = 25
+	ScreenColumns = 40
+)
+
+// ErrOffScreen reports a cursor position outside the screen
+var ErrOffScreen = errors.New("?ILLEGAL QUANTITY ERROR")
+
+// Cursor is implemented by runtimes that can move the cursor, for LOCATE and PRINT AT
+type Cursor interface {
+	// Locate moves the cursor to a 0-based row and column
+	Locate(row, col int) error
+}
+
+// LocateSequence returns the ANSI escape sequence moving a terminal cursor to a 0-based row and column,
+// or ErrOffScreen when the position is outside the 25x40 screen
+func LocateSequence(row, col int) (string, error) {
+	if row < 0 || row >= ScreenRows || col < 0 || col >= ScreenColumns {
+		return "", ErrOffScreen
+	}
+	return fmt.Sprintf("\x1b[%d;%dH", row+1, col+1), nil
+}
+
+// ScreenRuntime draws printed text on a character grid like the C64 screen editor: text wraps at the
+// right edge and the screen scrolls up when the cursor moves past the bottom row
+type ScreenRuntime struct {
+	Runtime // Source of input, keys, time and random numbers
+	cells   [ScreenRows][ScreenColumns]rune
+	row     int
+	col     int // May equal ScreenColumns after the last column is written, wrapping on the next character
+	ram     RAM // Memory for PEEK and POKE; the screen RAM mirrors cells
+}
+
+// NewScreenRuntime creates a blank screen taking input from inner; inner's output is not used
+func NewScreenRuntime(inner Runtime) *ScreenRuntime {
+	s := &ScreenRuntime{Runtime: inner}
+	s.blank()
+	return s
+}
+
+// Print draws text at the cursor
+func (s *ScreenRuntime) Print(value string) error {
+	for _, ch := range value {
+		s.put(ch)
+	}
+	return nil
+}
+
+// PrintLine draws text at the cursor and moves to the start of the next row
+func (s *ScreenRuntime) PrintLine(value string) error {
+	return s.Print(value + "\n")
+}
+
+// Input draws the prompt, reads a line from the wrapped runtime and echoes it as if typed
+func (s *ScreenRuntime) Input(prompt string) (string, error) {
+	if err := s.Print(prompt); err != nil {
+		return "", err
+	}
+	line, err := s.Runtime.Input("")
+	if err != nil {
+		return "", err
+	}
+	return line, s.PrintLine(line)
+}
+
+// Clear blanks the screen and homes the cursor
+func (s *ScreenRuntime) Clear() error {
+	s.blank()
+	s.row, s.col = 0, 0
+	return nil
+}
+
+// Locate moves the cursor to a 0-based row and column
+func (s *ScreenRuntime) Locate(row, col int) error {
+	if row < 0 || row >= ScreenRows || col < 0 || col >= ScreenColumns {
+		return ErrOffScreen
+	}
+	s.row, s.col = row, col
+	return nil
+}
+
+// Screen returns a copy of the 25x40 character grid, one slice per row; blank cells are spaces
+func (s *ScreenRuntime) Screen() [][]rune {
+	screen := make([][]rune, ScreenRows)
+	for r := range s.cells {
+		screen[r] = append([]rune(nil), s.cells[r][:]...)
+	}
+	return screen
+}
+
+// CursorPosition returns the 0-based row and column where the next character is drawn.
+// The column is ScreenColumns after the last column of a row was written; the next character wraps.
+func (s *ScreenRuntime) CursorPosition() (row, col int) {
+	return s.row, s.col
+}
+
+// Lines returns the screen rows as text without trailing spaces
+func (s *ScreenRuntime) Lines() []string {
+	lines := make([]string, ScreenRows)
+	for r := range s.cells {
+		lines[r] = strings.TrimRight(string(s.cells[r][:]), " ")
+	}
+	return lines
+}
+
+// Peek returns the byte at address; in the screen RAM it is the screen code of the character shown
+func (s *ScreenRuntime) Peek(address int) byte {
+	n := address - ScreenRAM
+	if n < 0 || n >= ScreenRAMSize {
+		return s.ram.Peek(address)
+	}
+	// Keep the POKEd code when it still shows, so reverse video and graphics codes read back unchanged
+	if code := s.ram.Peek(address); ScreenCodeRune(code) == s.cells[n/ScreenColumns][n%ScreenColumns] {
+		return code
+	}
+	return RuneScreenCode(s.cells[n/ScreenColumns][n%ScreenColumns])
+}
+
+// Poke stores value at address; in the screen RAM it draws the character with that screen code
+func (s *ScreenRuntime) Poke(address int, value byte) {
+	s.ram.Poke(address, value)
+	if n := address - ScreenRAM; n >= 0 && n < ScreenRAMSize {
+		s.cells[n/ScreenColumns][n%ScreenColumns] = ScreenCodeRune(value)
+	}
+}
+
+// ScreenMemory returns the screen codes of the characters shown, row by row, as PEEK reads them
+func (s *ScreenRuntime) ScreenMemory() []byte {
+	screen := make([]byte, ScreenRAMSize)
+	for n := range screen {
+		screen[n] = s.Peek(ScreenRAM + n)
+	}
+	return screen
+}
+
+// put draws one character, handling newlines, wrapping and scrolling
+func (s *ScreenRuntime) put(ch rune) {
+	if ch == '\n' {
+		s.newline()
+		return
+	}
+	if s.col == ScreenColumns {
+		s.newline()
+	}
+	s.cells[s.row][s.col] = ch
+	s.col++
+}
+
+// newline moves the cursor to the start of the next row, scrolling at the bottom
+func (s *ScreenRuntime) newline() {
+	s.col = 0
+	if s.row < ScreenRows-1 {
+		s.row++
+		return
+	}
+	copy(s.cells[:], s.cells[1:])
+	for c := range s.cells[ScreenRows-1] {
+		s.cells[ScreenRows-1][c] = ' '
+	}
+}
+
+// blank fills the screen with spaces
+func (s *ScreenRuntime) blank() {
+	for r := range s.cells {
+		for c := range s.cells[r] {
+			s.cells[r][c] = ' '
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenRuntime_LocateAndPrint(t *testing.T) {
+	s := NewScreenRuntime(NewTestRuntime())
+	require.NoError(t, s.Locate(2, 5))
+	require.NoError(t, s.PrintLine("MENU"))
+	require.NoError(t, s.Print("NEXT"))
+
+	lines := s.Lines()
+	assert.Equal(t, "     MENU", lines[2])
+	assert.Equal(t, "NEXT", lines[3])
+	assert.Equal(t, "", lines[0])
+}
+
+func TestScreenRuntime_LocateBounds(t *testing.T) {
+	s := NewScreenRuntime(NewTestRuntime())
+	assert.NoError(t, s.Locate(ScreenRows-1, ScreenColumns-1))
+	assert.Equal(t, ErrOffScreen, s.Locate(ScreenRows, 0))
+	assert.Equal(t, ErrOffScreen, s.Locate(0, ScreenColumns))
+	assert.Equal(t, ErrOffScreen, s.Locate(-1, 0))
+}
+
+func TestScreenRuntime_WrapsAndScrolls(t *testing.T) {
+	s := NewScreenRuntime(NewTestRuntime())
+	require.NoError(t, s.Locate(ScreenRows-1, ScreenColumns-2))
+	require.NoError(t, s.Print("ABC"))
+
+	lines := s.Lines()
+	assert.Equal(t, strings.Repeat(" ", ScreenColumns-2)+"AB", lines[ScreenRows-2])
+	assert.Equal(t, "C", lines[ScreenRows-1])
+}
+
+func TestScreenRuntime_InputEchoes(t *testing.T) {
+	inner := NewTestRuntime()
+	inner.SetInput([]string{"42"})
+	s := NewScreenRuntime(inner)
+
+	line, err := s.Input("? ")
+	require.NoError(t, err)
+	assert.Equal(t, "42", line)
+	assert.Equal(t, "? 42", s.Lines()[0])
+
+	require.NoError(t, s.Clear())
+	assert.Equal(t, "", s.Lines()[0])
+}
+
+func TestLocateSequence(t *testing.T) {
+	seq, err := LocateSequence(0, 9)
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[1;10H", seq)
+
+	_, err = LocateSequence(25, 0)
+	assert.Equal(t, ErrOffScreen, err)
+}
+
+func TestScreenRuntime_SnapshotAndCursor(t *testing.T) {
+	s := NewScreenRuntime(NewTestRuntime())
+	require.NoError(t, s.Locate(1, 2))
+	require.NoError(t, s.Print("HI"))
+
+	screen := s.Screen()
+	require.Len(t, screen, ScreenRows)
+	assert.Len(t, screen[1], ScreenColumns)
+	assert.Equal(t, "  HI", strings.TrimRight(string(screen[1]), " "))
+	row, col := s.CursorPosition()
+	assert.Equal(t, 1, row)
+	assert.Equal(t, 4, col)
+
+	screen[1][2] = 'X'
+	assert.Equal(t, 'H', s.Screen()[1][2], "snapshot is a copy")
+}
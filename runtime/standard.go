@@ -1,55 +1,132 @@
 // ABOUTME: Standard runtime implementation for console I/O operations
-// ABOUTME: Production runtime that uses os.Stdout and os.Stdin for real console interaction
+// ABOUTME: Production runtime reading and writing the console, or any reader and writers; lines end in CRLF on Windows
 
 package runtime
 
 import (
 	"bufio"
-	"fmt"
+	"context"
+	"io"
 	"math/rand"
 	"os"
+	goruntime "runtime"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// DefaultCRLF reports whether console output ends lines with "\r\n" unless configured otherwise, as on Windows
+var DefaultCRLF = goruntime.GOOS == "windows"
+
+// crlfReplacer translates "\n" and a lone "\r" (CHR$(13), RETURN on the C64) to "\r\n"
+var crlfReplacer = strings.NewReplacer("\r\n", "\r\n", "\r", "\r\n", "\n", "\r\n")
+
+// CRLFLines returns printed text with every line ending, "\n" or "\r", written as "\r\n"
+func CRLFLines(s string) string {
+	return crlfReplacer.Replace(s)
+}
+
 // StandardRuntime implements Runtime interface for console I/O
 type StandardRuntime struct {
 	reader *bufio.Reader
+	out    io.Writer
+	errOut io.Writer
 	rng    *rand.Rand
+	crlf   bool          // Write line endings as "\r\n"
+	lines  chan lineRead // Line still being read after InputContext stopped waiting for it, nil when none
+	unread string        // Rest of a line GET took the first character of
+	RAM                  // Emulated memory for PEEK and POKE; POKEs to the screen RAM are not displayed
 }
 
-// NewStandardRuntime creates a new StandardRuntime instance
+// lineRead is the result of reading a line of input
+type lineRead struct {
+	line string
+	err  error
+}
+
+// NewStandardRuntime creates a StandardRuntime on stdin, stdout and stderr
 func NewStandardRuntime() *StandardRuntime {
+	return NewStandardRuntimeIO(os.Stdin, os.Stdout, os.Stderr)
+}
+
+// NewStandardRuntimeIO creates a StandardRuntime reading INPUT lines and GET keys from in and printing to out.
+// errOut is for diagnostics such as the TRON trace; the runtime itself never writes to it.
+func NewStandardRuntimeIO(in io.Reader, out, errOut io.Writer) *StandardRuntime {
 	return &StandardRuntime{
-		reader: bufio.NewReader(os.Stdin),
+		reader: bufio.NewReader(in),
+		out:    out,
+		errOut: errOut,
 		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		crlf:   DefaultCRLF,
 	}
 }
 
-// Print outputs a string to stdout without a newline
+// ErrorOutput returns the writer for diagnostics
+func (std *StandardRuntime) ErrorOutput() io.Writer {
+	return std.errOut
+}
+
+// SetCRLF chooses "\r\n" line endings (see CRLFLines) or text written as printed; the default is DefaultCRLF
+func (std *StandardRuntime) SetCRLF(enabled bool) {
+	std.crlf = enabled
+}
+
+// Print outputs a string without a newline
 func (std *StandardRuntime) Print(value string) error {
-	_, err := fmt.Print(value)
+	if std.crlf {
+		value = CRLFLines(value)
+	}
+	_, err := io.WriteString(std.out, value)
 	return err
 }
 
-// PrintLine outputs a string to stdout with a newline
+// PrintLine outputs a string with a newline
 func (std *StandardRuntime) PrintLine(value string) error {
-	_, err := fmt.Println(value)
-	return err
+	return std.Print(value + "\n")
 }
 
 // Input prompts for user input and returns the entered string
 func (std *StandardRuntime) Input(prompt string) (string, error) {
+	return std.InputContext(context.Background(), prompt)
+}
+
+// InputContext implements ContextInput. A line that is still being read when ctx is done is kept for the next
+// INPUT or GET, so no typed text is lost.
+func (std *StandardRuntime) InputContext(ctx context.Context, prompt string) (string, error) {
 	if prompt != "" {
-		fmt.Print(prompt)
+		if _, err := io.WriteString(std.out, prompt); err != nil {
+			return "", err
+		}
 	}
-
-	line, err := std.reader.ReadString('\n')
-	if err != nil {
-		return "", err
+	if std.unread != "" {
+		line, _, _ := strings.Cut(std.unread, "\n")
+		std.unread = ""
+		return strings.TrimSpace(line), nil
+	}
+	if std.lines == nil && ctx.Done() == nil {
+		line, err := std.reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+	if std.lines == nil {
+		std.lines = make(chan lineRead, 1)
+		go func(lines chan<- lineRead) {
+			line, err := std.reader.ReadString('\n')
+			lines <- lineRead{line: line, err: err}
+		}(std.lines)
+	}
+	select {
+	case read := <-std.lines:
+		std.lines = nil
+		if read.err != nil {
+			return "", read.err
+		}
+		return strings.TrimSpace(read.line), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
-
-	return strings.TrimSpace(line), nil
 }
 
 // Clear clears the screen (not implemented for console)
@@ -57,7 +134,48 @@ func (std *StandardRuntime) Clear() error {
 	return nil
 }
 
+// Locate moves the terminal cursor with an ANSI escape sequence
+func (std *StandardRuntime) Locate(row, col int) error {
+	seq, err := LocateSequence(row, col)
+	if err != nil {
+		return err
+	}
+	return std.Print(seq)
+}
+
 // Random returns a random float64 in [0,1)
 func (std *StandardRuntime) Random() float64 {
 	return std.rng.Float64()
 }
+
+// Now returns the wall-clock time
+func (std *StandardRuntime) Now() time.Time {
+	return time.Now()
+}
+
+// GetKey reads the next character of input; the console is line buffered, so it waits for RETURN
+func (std *StandardRuntime) GetKey() (string, error) {
+	if std.lines != nil {
+		read := <-std.lines
+		std.lines = nil
+		if read.err != nil {
+			return "", read.err
+		}
+		std.unread += read.line
+	}
+	var ch rune
+	if std.unread != "" {
+		var size int
+		ch, size = utf8.DecodeRuneInString(std.unread)
+		std.unread = std.unread[size:]
+	} else {
+		var err error
+		if ch, _, err = std.reader.ReadRune(); err != nil {
+			return "", err
+		}
+	}
+	if ch == '\n' {
+		return "\r", nil
+	}
+	return string(ch), nil
+}
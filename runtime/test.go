@@ -6,15 +6,18 @@ package runtime
 import (
 	"fmt"
 	"math/rand"
+	"time"
 )
 
 // TestRuntime implements Runtime interface for testing
-// It captures all output and provides scripted input
+// It captures all output as printed, with "\n" line endings on every platform, and provides scripted input
 type TestRuntime struct {
 	outputBuffer []string
 	inputQueue   []string
 	inputIndex   int
 	rng          *rand.Rand
+	RAM          // Emulated memory for PEEK and POKE; ScreenText decodes what was POKEd to the screen
+	Disk         // In-memory disk drive 8 for OPEN; SetFile and FileContent reach its files
 }
 
 // NewTestRuntime creates a new TestRuntime instance
@@ -75,3 +78,13 @@ func (test *TestRuntime) SetInput(inputs []string) {
 func (test *TestRuntime) Random() float64 {
 	return test.rng.Float64()
 }
+
+// Now returns the wall-clock time; use DeterministicRuntime for a fixed clock
+func (test *TestRuntime) Now() time.Time {
+	return time.Now()
+}
+
+// GetKey reports that no key is pressed
+func (test *TestRuntime) GetKey() (string, error) {
+	return "", nil
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Source maps from generated code positions (bytecode offsets, Go lines) back to BASIC lines
+// ABOUTME: Alternate backends embed one so runtime errors and profiler samples report original line numbers
+
+package sourcemap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entry maps the start of a range of generated positions to the BASIC statement it came from
+type Entry struct {
+	Generated int // First generated position (instruction offset, Go source line, ...) of the range
+	Line      int // BASIC line number
+	Statement int // Statement index within the BASIC line (0-based)
+}
+
+// Map translates generated positions to BASIC lines; a position belongs to the nearest entry at or before it
+type Map struct {
+	entries []Entry
+	sorted  bool
+}
+
+// New creates an empty source map
+func New() *Map {
+	return &Map{sorted: true}
+}
+
+// Add records that generated code from position generated onwards implements a BASIC statement
+func (m *Map) Add(generated, line, statement int) {
+	if n := len(m.entries); n > 0 && m.entries[n-1].Generated > generated {
+		m.sorted = false
+	}
+	m.entries = append(m.entries, Entry{Generated: generated, Line: line, Statement: statement})
+}
+
+// Lookup returns the BASIC statement a generated position belongs to
+func (m *Map) Lookup(generated int) (Entry, bool) {
+	if !m.sorted {
+		sort.SliceStable(m.entries, func(a, b int) bool { return m.entries[a].Generated < m.entries[b].Generated })
+		m.sorted = true
+	}
+	n := sort.Search(len(m.entries), func(k int) bool { return m.entries[k].Generated > generated })
+	if n == 0 {
+		return Entry{}, false
+	}
+	return m.entries[n-1], true
+}
+
+// Entries returns a copy of the entries
+func (m *Map) Entries() []Entry {
+	return append([]Entry(nil), m.entries...)
+}
+
+// WrapError adds the BASIC line of a generated position to a C64-style error ("?X ERROR IN 10")
+func (m *Map) WrapError(err error, generated int) error {
+	if err == nil {
+		return nil
+	}
+	entry, ok := m.Lookup(generated)
+	if !ok || strings.Contains(err.Error(), " IN ") {
+		return err
+	}
+	return fmt.Errorf("%w IN %d", err, entry.Line)
+}
+
+// Encode serialises the map as "generated:line.statement" entries separated by ";", for embedding in generated code
+func (m *Map) Encode() string {
+	parts := make([]string, len(m.entries))
+	for n, e := range m.entries {
+		parts[n] = fmt.Sprintf("%d:%d.%d", e.Generated, e.Line, e.Statement)
+	}
+	return strings.Join(parts, ";")
+}
+
+// Decode parses a map produced by Encode
+func Decode(encoded string) (*Map, error) {
+	m := New()
+	if encoded == "" {
+		return m, nil
+	}
+	for _, part := range strings.Split(encoded, ";") {
+		gen, rest, ok1 := strings.Cut(part, ":")
+		line, stmt, ok2 := strings.Cut(rest, ".")
+		g, err1 := strconv.Atoi(gen)
+		l, err2 := strconv.Atoi(line)
+		s, err3 := strconv.Atoi(stmt)
+		if !ok1 || !ok2 || err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("invalid source map entry %q", part)
+		}
+		m.Add(g, l, s)
+	}
+	return m, nil
+}
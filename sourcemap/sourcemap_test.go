@@ -0,0 +1,77 @@
+package sourcemap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap_Lookup(t *testing.T) {
+	m := New()
+	m.Add(0, 10, 0)
+	m.Add(4, 10, 1)
+	m.Add(9, 20, 0)
+
+	tests := []struct {
+		generated int
+		want      Entry
+	}{
+		{0, Entry{0, 10, 0}},
+		{3, Entry{0, 10, 0}},
+		{4, Entry{4, 10, 1}},
+		{100, Entry{9, 20, 0}},
+	}
+	for _, tt := range tests {
+		got, ok := m.Lookup(tt.generated)
+		require.True(t, ok)
+		assert.Equal(t, tt.want, got, "position %d", tt.generated)
+	}
+
+	_, ok := m.Lookup(-1)
+	assert.False(t, ok)
+}
+
+func TestMap_LookupAfterOutOfOrderAdds(t *testing.T) {
+	m := New()
+	m.Add(50, 30, 0)
+	m.Add(10, 10, 0)
+
+	got, ok := m.Lookup(20)
+	require.True(t, ok)
+	assert.Equal(t, 10, got.Line)
+}
+
+func TestMap_WrapError(t *testing.T) {
+	m := New()
+	m.Add(0, 10, 0)
+	m.Add(7, 40, 2)
+
+	base := errors.New("?DIVISION BY ZERO ERROR")
+	err := m.WrapError(base, 8)
+	assert.EqualError(t, err, "?DIVISION BY ZERO ERROR IN 40")
+	assert.ErrorIs(t, err, base)
+
+	already := errors.New("?SYNTAX ERROR IN 5")
+	assert.Equal(t, already, m.WrapError(already, 8))
+	assert.NoError(t, m.WrapError(nil, 8))
+}
+
+func TestMap_EncodeDecode(t *testing.T) {
+	m := New()
+	m.Add(0, 10, 0)
+	m.Add(12, 20, 3)
+	assert.Equal(t, "0:10.0;12:20.3", m.Encode())
+
+	decoded, err := Decode(m.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, m.Entries(), decoded.Entries())
+
+	empty, err := Decode("")
+	require.NoError(t, err)
+	assert.Empty(t, empty.Entries())
+
+	_, err = Decode("0:10")
+	assert.Error(t, err)
+}
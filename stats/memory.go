@@ -0,0 +1,119 @@
+// ABOUTME: Estimates C64 memory use of a program: text, variables, arrays and the string heap
+// ABOUTME: Compared with the 38911 bytes free on a stock C64 to warn about ?OUT OF MEMORY on real hardware
+
+package stats
+
+import (
+	"strconv"
+	"strings"
+
+	"basic-interpreter/parser"
+)
+
+// C64MemoryBytes is the memory BASIC reports free on a stock C64 ("38911 BASIC BYTES FREE")
+const C64MemoryBytes = 38911
+
+// Sizes of C64 BASIC variable storage
+const (
+	variableBytes      = 7  // Two-byte name plus a five-byte value or string descriptor
+	arrayHeaderBytes   = 5  // Two-byte name, two-byte total size and the dimension count
+	dimensionBytes     = 2  // Element count of one dimension
+	numberElementBytes = 5  // Floating point element
+	stringElementBytes = 3  // String descriptor: length and pointer
+	defaultArraySize   = 10 // Arrays used without DIM have indices 0 to 10
+)
+
+// MemoryBytes returns the estimated total memory the program needs on a C64
+func (r Report) MemoryBytes() int {
+	return r.TokenizedBytes + r.VariableBytes + r.ArrayBytes + r.StringBytes
+}
+
+// ExceedsC64 reports whether the estimate is more than a stock C64 provides
+func (r Report) ExceedsC64() bool {
+	return r.MemoryBytes() > C64MemoryBytes
+}
+
+// estimateMemory fills in the variable, array and string estimates
+func (r *Report) estimateMemory(program *parser.Program, arrays map[string]bool) {
+	functions := 0
+	dims := map[string][]int{}
+	forEachStatement(program.Lines, func(stmt parser.Statement) {
+		switch s := stmt.(type) {
+		case *parser.DefFnStatement:
+			functions++
+		case *parser.DimStatement:
+			for _, decl := range s.Declarations {
+				sizes := make([]int, len(decl.Sizes))
+				for n, expr := range decl.Sizes {
+					sizes[n] = constantSize(expr)
+				}
+				dims[significantName(strings.ToUpper(decl.Name))] = sizes
+			}
+		case *parser.LetStatement:
+			r.StringBytes += builtStringBytes(s.Variable, s.Expression)
+		case *parser.ArraySetStatement:
+			r.StringBytes += builtStringBytes(s.Name, s.Expression)
+		}
+	})
+
+	r.VariableBytes = (r.Variables + functions) * variableBytes
+	for name := range arrays {
+		sizes, ok := dims[name]
+		if !ok {
+			sizes = []int{defaultArraySize}
+		}
+		r.ArrayBytes += arraySize(name, sizes)
+	}
+}
+
+// arraySize returns the bytes an array with the given maximum indexes occupies
+func arraySize(name string, sizes []int) int {
+	elements := 1
+	for _, size := range sizes {
+		elements *= size + 1
+	}
+	elementBytes := numberElementBytes
+	if strings.HasSuffix(name, "$") {
+		elementBytes = stringElementBytes
+	}
+	return arrayHeaderBytes + dimensionBytes*len(sizes) + elementBytes*elements
+}
+
+// constantSize returns a DIM size given as a number literal, or the default size for computed sizes
+func constantSize(expr parser.Expression) int {
+	if lit, ok := expr.(*parser.NumberLiteral); ok {
+		if n, err := strconv.ParseFloat(lit.Value, 64); err == nil && n >= 0 {
+			return int(n)
+		}
+	}
+	return defaultArraySize
+}
+
+// builtStringBytes estimates the heap used by assigning expr to a string variable.
+// A plain literal stays in the program text; anything computed is copied to the heap.
+func builtStringBytes(target string, expr parser.Expression) int {
+	if !strings.HasSuffix(target, "$") {
+		return 0
+	}
+	if _, ok := expr.(*parser.StringLiteral); ok {
+		return 0
+	}
+	return literalBytes(expr)
+}
+
+// literalBytes sums the lengths of the string literals in an expression
+func literalBytes(expr parser.Expression) int {
+	switch e := expr.(type) {
+	case *parser.StringLiteral:
+		return len(e.Value)
+	case *parser.BinaryOperation:
+		return literalBytes(e.Left) + literalBytes(e.Right)
+	case *parser.FunctionCall:
+		total := 0
+		for _, arg := range e.Arguments {
+			total += literalBytes(arg)
+		}
+		return total
+	}
+	return 0
+}
@@ -0,0 +1,270 @@
+// ABOUTME: Static program statistics: size in C64 memory, variable counts, GOSUB depth and jump complexity
+// ABOUTME: Used by `basic stats` to help authors targeting the limits of real hardware
+
+package stats
+
+import (
+	"strings"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+// Per-line overhead of a tokenized C64 program: link pointer, line number and terminating zero
+const (
+	lineHeaderBytes  = 4
+	lineEndBytes     = 1
+	programEndBytes  = 2
+	keywordTokenSize = 1
+)
+
+// Report summarises the size and structure of a program
+type Report struct {
+	Lines          int  // Numbered program lines
+	TokenizedBytes int  // Size of the program in C64 memory, keywords crunched to one byte
+	Variables      int  // Distinct simple variables (two significant characters)
+	Arrays         int  // Distinct arrays
+	GosubDepth     int  // Deepest static GOSUB nesting
+	Recursive      bool // A subroutine can reach itself, so GosubDepth is a lower bound
+	Jumps          int  // GOTO, GOSUB and IF...THEN line targets, plus every ON target
+	Complexity     int  // 1 + decision points (IF, ON targets, FOR, WHILE, conditional LOOP)
+
+	// Estimated C64 memory use beyond the program text
+	VariableBytes int // Simple variables and DEF FN definitions
+	ArrayBytes    int // Array headers and elements
+	StringBytes   int // String heap for strings built at runtime
+}
+
+// Analyze parses source and computes its statistics
+func Analyze(source string) (Report, error) {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if e := p.ParseError(); e != nil {
+		return Report{}, e
+	}
+
+	report := Report{Lines: len(program.Lines), TokenizedBytes: tokenizedSize(source)}
+	variables, arrays := scanVariables(source)
+	report.Variables, report.Arrays = len(variables), len(arrays)
+	report.Jumps, report.Complexity = countJumps(program)
+	report.GosubDepth, report.Recursive = gosubDepth(program)
+	report.estimateMemory(program, arrays)
+	return report, nil
+}
+
+// tokenizedSize estimates the bytes a program occupies once typed into a C64
+func tokenizedSize(source string) int {
+	size := programEndBytes
+	for _, text := range strings.Split(source, "\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		size += lineHeaderBytes + lineEndBytes
+		afterNumber := false
+		for _, seg := range lexer.Classify(text) {
+			switch seg.Class {
+			case lexer.ClassLineNumber:
+				afterNumber = true
+				continue // Stored as a two-byte binary number in the header
+			case lexer.ClassKeyword:
+				size += keywordTokenSize
+			case lexer.ClassIdentifier:
+				size += identifierSize(seg.Text)
+			default:
+				text := seg.Text
+				if afterNumber {
+					// Spaces typed after the line number are not stored
+					text = strings.TrimLeft(text, " \t")
+				}
+				size += len(strings.TrimRight(text, "\r"))
+			}
+			afterNumber = false
+		}
+	}
+	return size
+}
+
+// identifierSize returns the stored size of a name; built-in function names and FN are tokens
+func identifierSize(name string) int {
+	upper := strings.ToUpper(name)
+	for _, f := range parser.BuiltinFunctions() {
+		if f == upper {
+			return keywordTokenSize
+		}
+	}
+	if strings.HasPrefix(upper, "FN") {
+		return keywordTokenSize + len(name) - 2
+	}
+	return len(name)
+}
+
+// scanVariables collects the distinct simple variables and arrays by scanning tokens
+func scanVariables(source string) (map[string]bool, map[string]bool) {
+	var tokens []lexer.Token
+	l := lexer.New(source)
+	for tok := l.NextToken(); tok.Type != lexer.EOF; tok = l.NextToken() {
+		tokens = append(tokens, tok)
+	}
+
+	variables := map[string]bool{}
+	arrays := map[string]bool{}
+	for n := 0; n < len(tokens); n++ {
+		tok := tokens[n]
+		if tok.Type == lexer.REM {
+			// Skip the comment up to the end of the line
+			for n+1 < len(tokens) && tokens[n+1].Type != lexer.NEWLINE {
+				n++
+			}
+			continue
+		}
+		if tok.Type != lexer.IDENT {
+			continue
+		}
+		name := strings.ToUpper(tok.Literal)
+		if isReserved(name) {
+			continue
+		}
+		if n+1 < len(tokens) && tokens[n+1].Type == lexer.LPAREN {
+			arrays[significantName(name)] = true
+		} else {
+			variables[significantName(name)] = true
+		}
+	}
+	return variables, arrays
+}
+
+// isReserved reports whether a name is a function or clock variable rather than a user variable
+func isReserved(name string) bool {
+	if strings.HasPrefix(name, "FN") || name == "TIMER" || strings.TrimSuffix(name, "$") == "TI" {
+		return true
+	}
+	for _, f := range parser.BuiltinFunctions() {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// significantName keeps the two characters C64 BASIC distinguishes plus the type suffix
+func significantName(name string) string {
+	suffix := ""
+	if strings.HasSuffix(name, "$") {
+		suffix = "$"
+		name = strings.TrimSuffix(name, "$")
+	}
+	if len(name) > 2 {
+		name = name[:2]
+	}
+	return name + suffix
+}
+
+// countJumps counts jump targets and decision points across all statements
+func countJumps(program *parser.Program) (int, int) {
+	jumps, complexity := 0, 1
+	forEachStatement(program.Lines, func(stmt parser.Statement) {
+		switch s := stmt.(type) {
+		case *parser.GotoStatement, *parser.GosubStatement:
+			jumps++
+		case *parser.OnGotoStatement:
+			jumps += len(s.TargetLines)
+			complexity += len(s.TargetLines)
+		case *parser.OnGosubStatement:
+			jumps += len(s.TargetLines)
+			complexity += len(s.TargetLines)
+		case *parser.IfStatement, *parser.ForStatement, *parser.WhileStatement:
+			complexity++
+		case *parser.LoopStatement:
+			if s.Condition != nil {
+				complexity++
+			}
+		}
+	})
+	return jumps, complexity
+}
+
+// gosubDepth estimates the deepest GOSUB nesting. A subroutine runs from its target line
+// to the first line containing RETURN; calls inside that range nest one level deeper.
+func gosubDepth(program *parser.Program) (int, bool) {
+	linePos := make(map[int]int, len(program.Lines))
+	for idx, line := range program.Lines {
+		linePos[line.Number] = idx
+	}
+
+	depths := map[int]int{}
+	visiting := map[int]bool{}
+	recursive := false
+	var depth func(target int) int
+	depth = func(target int) int {
+		if d, ok := depths[target]; ok {
+			return d
+		}
+		start, ok := linePos[target]
+		if !ok || visiting[target] {
+			recursive = recursive || visiting[target]
+			return 1
+		}
+		visiting[target] = true
+		deepest := 0
+		for _, callee := range gosubTargets(subroutineLines(program.Lines[start:])) {
+			if d := depth(callee); d > deepest {
+				deepest = d
+			}
+		}
+		visiting[target] = false
+		depths[target] = 1 + deepest
+		return depths[target]
+	}
+
+	deepest := 0
+	for _, target := range gosubTargets(program.Lines) {
+		if d := depth(target); d > deepest {
+			deepest = d
+		}
+	}
+	return deepest, recursive
+}
+
+// subroutineLines returns the lines from the start of a subroutine up to its first RETURN
+func subroutineLines(lines []*parser.Line) []*parser.Line {
+	for n := range lines {
+		returns := false
+		forEachStatement(lines[n:n+1], func(stmt parser.Statement) {
+			if _, ok := stmt.(*parser.ReturnStatement); ok {
+				returns = true
+			}
+		})
+		if returns {
+			return lines[:n+1]
+		}
+	}
+	return lines
+}
+
+// gosubTargets lists the lines called by GOSUB and ON...GOSUB in the given lines
+func gosubTargets(lines []*parser.Line) []int {
+	var targets []int
+	forEachStatement(lines, func(stmt parser.Statement) {
+		switch s := stmt.(type) {
+		case *parser.GosubStatement:
+			targets = append(targets, s.TargetLine)
+		case *parser.OnGosubStatement:
+			targets = append(targets, s.TargetLines...)
+		}
+	})
+	return targets
+}
+
+// forEachStatement calls fn for every statement, including those nested in IF branches
+func forEachStatement(lines []*parser.Line, fn func(parser.Statement)) {
+	for _, line := range lines {
+		parser.Inspect(line, func(node parser.Node) bool {
+			if stmt, ok := node.(parser.Statement); ok {
+				fn(stmt)
+			}
+			_, isExpression := node.(parser.Expression)
+			return !isExpression
+		})
+	}
+}
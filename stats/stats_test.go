@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected Report
+	}{
+		{
+			name:   "tokenized size crunches keywords and functions",
+			source: "10 PRINT LEN(\"AB\")\n",
+			// header 4 + PRINT 1 + space 1 + LEN 1 + ("AB") 6 + end 1, plus 2 for the program end
+			expected: Report{Lines: 1, TokenizedBytes: 16, Complexity: 1},
+		},
+		{
+			name:     "variables use two significant characters",
+			source:   "10 SCORE=1: SC=2: N$=\"A\": DIM A(3): A(1)=LEN(N$)\n20 REM IGNORED WORDS\n",
+			expected: Report{Lines: 2, TokenizedBytes: 68, Variables: 2, Arrays: 1, Complexity: 1, VariableBytes: 14, ArrayBytes: 27},
+		},
+		{
+			name: "jumps and complexity",
+			source: "10 FOR I=1 TO 3\n20 IF I=2 THEN 40\n30 ON I GOTO 40,50\n40 NEXT I\n" +
+				"50 GOTO 10\n",
+			expected: Report{Lines: 5, TokenizedBytes: 64, Variables: 1, Jumps: 4, Complexity: 5, VariableBytes: 7},
+		},
+		{
+			name: "nested GOSUB depth",
+			source: "10 GOSUB 100: END\n100 GOSUB 200\n110 RETURN\n" +
+				"200 GOSUB 300: RETURN\n300 RETURN\n",
+			expected: Report{Lines: 5, TokenizedBytes: 50, GosubDepth: 3, Jumps: 3, Complexity: 1},
+		},
+		{
+			name:     "recursive GOSUB",
+			source:   "10 GOSUB 100\n100 IF N<3 THEN N=N+1: GOSUB 100\n110 RETURN\n",
+			expected: Report{Lines: 3, TokenizedBytes: 43, Variables: 1, GosubDepth: 2, Recursive: true, Jumps: 2, Complexity: 2, VariableBytes: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Analyze(tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, report)
+		})
+	}
+}
+
+func TestAnalyze_ParseError(t *testing.T) {
+	_, err := Analyze("10 PRINT \"OOPS")
+	assert.Error(t, err)
+}
+
+func TestAnalyze_MemoryEstimate(t *testing.T) {
+	report, err := Analyze("10 DIM B$(2,3): DEF FNA(X)=X\n20 A$=\"HI\": C$=A$+\"THERE\": D(1)=1\n")
+	require.NoError(t, err)
+	assert.Equal(t, 4*variableBytes, report.VariableBytes, "A$, C$, X and FNA")
+	assert.Equal(t, (5+2*2+3*12)+(5+2+5*11), report.ArrayBytes, "DIM B$(2,3) and undimensioned D()")
+	assert.Equal(t, len("THERE"), report.StringBytes)
+	assert.Equal(t, report.TokenizedBytes+report.VariableBytes+report.ArrayBytes+report.StringBytes, report.MemoryBytes())
+	assert.False(t, report.ExceedsC64())
+
+	report, err = Analyze("10 DIM A(100,100)\n")
+	require.NoError(t, err)
+	assert.True(t, report.ExceedsC64())
+}
@@ -0,0 +1,115 @@
+// ABOUTME: Local disk program store rooted at a directory, which names cannot leave; it also serves OPEN on drive 8
+// ABOUTME: Names without an extension get ".bas" appended; ".prg" names are C64 tokenized files
+
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"basic-interpreter/charset"
+	"basic-interpreter/prg"
+	"basic-interpreter/runtime"
+)
+
+// DiskStore reads and writes program files below Dir. Names are relative paths with forward slashes; absolute
+// names, ".." and symbolic links leading out of Dir are refused.
+type DiskStore struct {
+	Dir      string
+	ReadOnly bool // Refuse SAVE and files opened for writing with ErrReadOnly
+}
+
+// NewDiskStore creates a store rooted at dir
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{Dir: dir}
+}
+
+// Load implements Store
+func (s *DiskStore) Load(name string) (string, error) {
+	f, err := s.open(programName(name), os.O_RDONLY)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrFileNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	if isPRG(name) {
+		return prg.Decode(data)
+	}
+	return charset.Decode(data, charset.Auto)
+}
+
+// Save implements Store
+func (s *DiskStore) Save(name string, source string) error {
+	data := []byte(source)
+	if isPRG(name) {
+		var err error
+		if data, err = prg.Encode(source); err != nil {
+			return err
+		}
+	}
+	f, err := s.open(programName(name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// OpenFile implements runtime.Files, giving OPEN the files of Dir as drive 8. Names are used as they are.
+func (s *DiskStore) OpenFile(device, channel int, name string, mode runtime.FileMode) (runtime.File, error) {
+	if device != runtime.DiskDevice {
+		return nil, runtime.ErrDeviceNotPresent
+	}
+	flag := os.O_RDONLY
+	switch mode {
+	case runtime.FileWrite:
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	case runtime.FileAppend:
+		flag = os.O_WRONLY | os.O_APPEND
+	}
+	return s.open(name, flag)
+}
+
+// open opens a file below Dir, refusing writes to a read-only store and names that would leave Dir. The os.Root
+// also stops symbolic links from leading out.
+func (s *DiskStore) open(name string, flag int) (*os.File, error) {
+	if s.ReadOnly && flag != os.O_RDONLY {
+		return nil, ErrReadOnly
+	}
+	path, err := filepath.Localize(name)
+	if err != nil {
+		return nil, ErrBadName
+	}
+	root, err := os.OpenRoot(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	return root.OpenFile(path, flag, 0644)
+}
+
+// isPRG reports whether a program name has the .prg extension of tokenized files
+func isPRG(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".prg")
+}
+
+// programName defaults the extension of a program name to .bas
+func programName(name string) string {
+	if filepath.Ext(name) == "" {
+		name += ".bas"
+	}
+	return name
+}
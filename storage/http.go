@@ -0,0 +1,83 @@
+// ABOUTME: Read-only HTTP program store for loading programs from URLs
+// ABOUTME: Names are host/path strings; the scheme comes from the store
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"basic-interpreter/charset"
+)
+
+// maxHTTPProgramSize bounds downloaded programs
+const maxHTTPProgramSize = 1 << 20
+
+// DefaultHTTPTimeout bounds a download when the store sets no Timeout
+const DefaultHTTPTimeout = 30 * time.Second
+
+// ErrProgramTooLarge is returned for a download longer than a program can be
+var ErrProgramTooLarge = errors.New("?PROGRAM TOO LARGE ERROR")
+
+// HTTPStore loads programs over HTTP(S); saving is not supported
+type HTTPStore struct {
+	Scheme  string        // "http" or "https"
+	Client  *http.Client  // nil uses http.DefaultClient
+	Timeout time.Duration // Limit on a whole download; 0 uses DefaultHTTPTimeout
+}
+
+// NewHTTPStore creates a read-only store for the given URL scheme
+func NewHTTPStore(scheme string) *HTTPStore {
+	return &HTTPStore{Scheme: scheme}
+}
+
+// Load implements Store by fetching scheme://name
+func (s *HTTPStore) Load(name string) (string, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	url := s.Scheme + "://" + strings.TrimPrefix(name, "//")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("?DEVICE NOT PRESENT ERROR: %s", resp.Status)
+	}
+	// One byte past the limit tells a program that is too large from one that just fits
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPProgramSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxHTTPProgramSize {
+		return "", ErrProgramTooLarge
+	}
+	return charset.Decode(data, charset.Auto)
+}
+
+// Save implements Store; HTTP storage is read-only
+func (s *HTTPStore) Save(name string, source string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,42 @@
+// ABOUTME: In-memory program store for tests and embedded environments
+// ABOUTME: Keeps sources in a map guarded by a mutex
+
+package storage
+
+import (
+	"strings"
+	"sync"
+)
+
+// MemoryStore keeps programs in memory; names are case-insensitive
+type MemoryStore struct {
+	mu       sync.Mutex
+	programs map[string]string
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{programs: make(map[string]string)}
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source, ok := s.programs[strings.ToUpper(name)]
+	if !ok {
+		return "", ErrFileNotFound
+	}
+	return source, nil
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(name string, source string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrMissingName
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programs[strings.ToUpper(name)] = source
+	return nil
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Pluggable program storage used by LOAD and SAVE
+// ABOUTME: Defines the Store interface plus a Mux that routes names like "HTTP:host/file" to backends
+
+package storage
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors reported by stores, in C64 style
+var (
+	ErrFileNotFound = errors.New("?FILE NOT FOUND ERROR")
+	ErrReadOnly     = errors.New("?WRITE PROTECT ERROR")
+	ErrMissingName  = errors.New("?MISSING FILE NAME ERROR")
+	ErrBadName      = errors.New("?SYNTAX ERROR") // A name a store cannot use, as a disk drive reports it
+)
+
+// Store loads and saves program source by name
+type Store interface {
+	Load(name string) (string, error)
+	Save(name string, source string) error
+}
+
+// Mux dispatches to a backend chosen by a case-insensitive name prefix such as "HTTP:"
+type Mux struct {
+	fallback Store
+	prefixes []string
+	stores   map[string]Store
+}
+
+// NewMux creates a Mux that uses fallback for names without a registered prefix
+func NewMux(fallback Store) *Mux {
+	return &Mux{fallback: fallback, stores: make(map[string]Store)}
+}
+
+// Handle routes names starting with prefix (e.g. "HTTP:") to store; the prefix is stripped before the call
+func (m *Mux) Handle(prefix string, store Store) {
+	prefix = strings.ToUpper(prefix)
+	if _, exists := m.stores[prefix]; !exists {
+		m.prefixes = append(m.prefixes, prefix)
+	}
+	m.stores[prefix] = store
+}
+
+// Load implements Store
+func (m *Mux) Load(name string) (string, error) {
+	store, rest, err := m.route(name)
+	if err != nil {
+		return "", err
+	}
+	return store.Load(rest)
+}
+
+// Save implements Store
+func (m *Mux) Save(name string, source string) error {
+	store, rest, err := m.route(name)
+	if err != nil {
+		return err
+	}
+	return store.Save(rest, source)
+}
+
+// route picks the store for name and returns the name with its prefix removed
+func (m *Mux) route(name string) (Store, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", ErrMissingName
+	}
+	upper := strings.ToUpper(name)
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return m.stores[prefix], name[len(prefix):], nil
+		}
+	}
+	if m.fallback == nil {
+		return nil, "", ErrFileNotFound
+	}
+	return m.fallback, name, nil
+}
+
+// NewDefault returns a Mux storing programs on disk, with read-only "HTTP:" and "HTTPS:" prefixes
+func NewDefault(disk *DiskStore) *Mux {
+	m := NewMux(disk)
+	m.Handle("HTTP:", NewHTTPStore("http"))
+	m.Handle("HTTPS:", NewHTTPStore("https"))
+	return m
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/runtime"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	_, err := s.Load("PROG")
+	assert.Equal(t, ErrFileNotFound, err)
+
+	require.NoError(t, s.Save("prog", "10 END\n"))
+	source, err := s.Load("PROG")
+	require.NoError(t, err)
+	assert.Equal(t, "10 END\n", source)
+
+	assert.Equal(t, ErrMissingName, s.Save(" ", "10 END\n"))
+}
+
+func TestDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiskStore(dir)
+
+	require.NoError(t, s.Save("hello", "10 PRINT 1\n"))
+	data, err := os.ReadFile(filepath.Join(dir, "hello.bas"))
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT 1\n", string(data))
+
+	source, err := s.Load("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT 1\n", source)
+
+	_, err = s.Load("missing.txt")
+	assert.Equal(t, ErrFileNotFound, err)
+
+	// Downloaded Latin-1 listings are converted to UTF-8
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pound.bas"), []byte("10 PRINT \"\xA3\"\n"), 0644))
+	source, err = s.Load("pound")
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT \"£\"\n", source)
+}
+
+func TestDiskStoreStaysInItsDirectory(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "disk")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "secret.bas"), []byte("10 REM SECRET\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(parent, "secret.bas"), filepath.Join(dir, "link.bas")))
+	s := NewDiskStore(dir)
+
+	tests := []struct {
+		name string
+		want error
+	}{
+		{name: "../secret", want: ErrBadName},
+		{name: filepath.Join(parent, "secret"), want: ErrBadName},
+		{name: "sub/../../secret", want: ErrBadName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Load(tt.name)
+			assert.Equal(t, tt.want, err)
+			assert.Equal(t, tt.want, s.Save(tt.name, "10 END\n"))
+		})
+	}
+
+	_, err := s.Load("link")
+	assert.Error(t, err, "a symbolic link out of the directory is not followed")
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "games"), 0755))
+	require.NoError(t, s.Save("games/hi", "10 END\n"))
+	source, err := s.Load("games/hi")
+	require.NoError(t, err)
+	assert.Equal(t, "10 END\n", source)
+}
+
+func TestDiskStoreReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prog.bas"), []byte("10 END\n"), 0644))
+	s := &DiskStore{Dir: dir, ReadOnly: true}
+
+	source, err := s.Load("prog")
+	require.NoError(t, err)
+	assert.Equal(t, "10 END\n", source)
+	assert.Equal(t, ErrReadOnly, s.Save("prog", "20 END\n"))
+
+	_, err = s.OpenFile(runtime.DiskDevice, 2, "DATA", runtime.FileWrite)
+	assert.Equal(t, ErrReadOnly, err)
+	_, err = os.Stat(filepath.Join(dir, "DATA"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestDiskStoreOpenFile(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiskStore(dir)
+
+	f, err := s.OpenFile(runtime.DiskDevice, 2, "SCORES", runtime.FileWrite)
+	require.NoError(t, err)
+	_, err = io.WriteString(f, "10\r")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	f, err = s.OpenFile(runtime.DiskDevice, 2, "SCORES", runtime.FileAppend)
+	require.NoError(t, err)
+	_, err = io.WriteString(f, "20\r")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	f, err = s.OpenFile(runtime.DiskDevice, 2, "SCORES", runtime.FileRead)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "10\r20\r", string(data))
+
+	_, err = s.OpenFile(runtime.DiskDevice, 2, "MISSING", runtime.FileRead)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = s.OpenFile(runtime.DiskDevice, 2, "../SCORES", runtime.FileWrite)
+	assert.Equal(t, ErrBadName, err)
+	_, err = s.OpenFile(9, 2, "SCORES", runtime.FileRead)
+	assert.Equal(t, runtime.ErrDeviceNotPresent, err)
+}
+
+func TestDiskStoreTokenizesPRGFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDiskStore(dir)
+
+	require.NoError(t, s.Save("game.PRG", "10 print \"hi\"\n"))
+	data, err := os.ReadFile(filepath.Join(dir, "game.PRG"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x08, 0x0C, 0x08, 0x0A, 0x00, 0x99, ' ', '"', 'H', 'I', '"', 0x00, 0x00, 0x00}, data)
+
+	source, err := s.Load("game.PRG")
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT \"HI\"\n", source)
+
+	assert.Error(t, s.Save("bad.prg", "10 PRINT \"{\"\n"))
+}
+
+func TestHTTPStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/demo.bas" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("10 PRINT \"NET\"\n"))
+	}))
+	defer server.Close()
+
+	s := &HTTPStore{Scheme: "http", Client: server.Client()}
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	source, err := s.Load(host + "/demo.bas")
+	require.NoError(t, err)
+	assert.Equal(t, "10 PRINT \"NET\"\n", source)
+
+	_, err = s.Load(host + "/other.bas")
+	assert.Equal(t, ErrFileNotFound, err)
+
+	assert.Equal(t, ErrReadOnly, s.Save(host+"/demo.bas", "10 END"))
+}
+
+func TestHTTPStoreLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fits.bas":
+			_, _ = w.Write([]byte(strings.Repeat("A", maxHTTPProgramSize)))
+		case "/large.bas":
+			_, _ = w.Write([]byte(strings.Repeat("A", maxHTTPProgramSize+1)))
+		case "/slow.bas":
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	s := &HTTPStore{Scheme: "http", Client: server.Client(), Timeout: 100 * time.Millisecond}
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	source, err := s.Load(host + "/fits.bas")
+	require.NoError(t, err)
+	assert.Len(t, source, maxHTTPProgramSize)
+
+	_, err = s.Load(host + "/large.bas")
+	assert.Equal(t, ErrProgramTooLarge, err)
+
+	_, err = s.Load(host + "/slow.bas")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMux(t *testing.T) {
+	disk := NewMemoryStore()
+	web := NewMemoryStore()
+	m := NewMux(disk)
+	m.Handle("http:", web)
+
+	require.NoError(t, m.Save("LOCAL", "10 REM LOCAL"))
+	require.NoError(t, web.Save("//site/x.bas", "10 REM WEB"))
+
+	source, err := m.Load("LOCAL")
+	require.NoError(t, err)
+	assert.Equal(t, "10 REM LOCAL", source)
+
+	source, err = m.Load("HTTP://site/x.bas")
+	require.NoError(t, err)
+	assert.Equal(t, "10 REM WEB", source)
+
+	_, err = m.Load("")
+	assert.Equal(t, ErrMissingName, err)
+}
+
+func TestNewDefaultRoutesHTTPReadOnly(t *testing.T) {
+	m := NewDefault(NewDiskStore(t.TempDir()))
+	assert.Equal(t, ErrReadOnly, m.Save("HTTPS:example.com/x.bas", "10 END"))
+	require.NoError(t, m.Save("local", "10 END\n"))
+}
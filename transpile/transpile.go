@@ -0,0 +1,656 @@
+// ABOUTME: Translates a parsed program into a standalone Go main package for basic build
+// ABOUTME: Lines become labels, variables package-level values and expressions Go calls; I/O goes through the compiled package
+
+package transpile
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// Options configure the generated program
+type Options struct {
+	Name        string         // Name of the BASIC file, mentioned in the generated header
+	ScreenWidth int            // Screen width in columns for line wrapping and TAB bounds (0 disables wrapping)
+	Dialect     parser.Dialect // Dialect deciding which characters of a name count, "" for the extended dialect
+}
+
+// UnsupportedError reports a program using a statement that has no Go translation
+type UnsupportedError struct {
+	Line int
+	What string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("line %d: %s is not supported by basic build", e.Line, e.What)
+}
+
+// generator holds the state of one translation
+type generator struct {
+	program   *parser.Program
+	out       strings.Builder // Body of the program function
+	functions strings.Builder // DEF FN functions
+	variables map[string]bool // Go variable name to whether it starts as a string
+	lines     map[int]bool    // Line numbers in the program
+	targets   map[int]bool    // Lines something jumps to, which get labels
+	returns   int             // Return points handed out to GOSUB
+	loops     int             // Loop bodies handed out to FOR
+	fns       int             // DEF FN functions generated
+	ifs       int             // IF statements translated, which number their labels
+	hasReturn bool            // Whether RETURN appears, so return points need labels
+	hasNext   bool            // Whether NEXT appears, so loop bodies need labels
+	dialect   parser.Dialect
+	line      int
+}
+
+// Go translates program to gofmt-formatted Go source for a main package. Programs using statements with no
+// translation, such as WHILE, DO, RUN, CONST or EVERY, fail with an *UnsupportedError.
+func Go(program *parser.Program, opts Options) ([]byte, error) {
+	g := &generator{
+		program:   program,
+		variables: make(map[string]bool),
+		lines:     make(map[int]bool),
+		targets:   make(map[int]bool),
+		dialect:   opts.Dialect,
+	}
+	if g.dialect == "" {
+		g.dialect = parser.DialectExtended
+	}
+	for _, line := range program.Lines {
+		g.lines[line.Number] = true
+		for _, stmt := range line.Statements {
+			g.scan(stmt)
+		}
+	}
+
+	if data := g.data(); data != "" {
+		g.emit("m.Data(%s)", data)
+	}
+	for _, line := range program.Lines {
+		g.line = line.Number
+		if g.targets[line.Number] {
+			g.emit("%s:", lineLabel(line.Number))
+		}
+		g.emit("m.Line = %d", line.Number)
+		for _, stmt := range line.Statements {
+			if err := g.statement(stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	g.emit("return")
+	if g.hasReturn {
+		g.dispatch("returned", "m.Return()", "return", g.returns)
+	}
+	if g.hasNext {
+		g.dispatch("looped", "m.Loop()", "loop", g.loops)
+	}
+	return format.Source([]byte(g.source(opts)))
+}
+
+// scan records the lines jumped to and whether RETURN and NEXT appear
+func (g *generator) scan(stmt parser.Statement) {
+	switch s := stmt.(type) {
+	case *parser.GotoStatement:
+		g.targets[s.TargetLine] = true
+	case *parser.GosubStatement:
+		g.targets[s.TargetLine] = true
+	case *parser.OnGotoStatement:
+		for _, line := range s.TargetLines {
+			g.targets[line] = true
+		}
+	case *parser.OnGosubStatement:
+		for _, line := range s.TargetLines {
+			g.targets[line] = true
+		}
+	case *parser.ReturnStatement:
+		g.hasReturn = true
+	case *parser.NextStatement:
+		g.hasNext = true
+	case *parser.IfStatement:
+		for _, branch := range [][]parser.Statement{s.ThenStmts, s.ElseStmts} {
+			for _, inner := range branch {
+				g.scan(inner)
+			}
+		}
+	}
+}
+
+// source assembles the generated file around the program body
+func (g *generator) source(opts Options) string {
+	var src strings.Builder
+	fmt.Fprintf(&src, "// Code generated by basic build from %s. DO NOT EDIT.\n\n", opts.Name)
+	src.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t\"basic-interpreter/compiled\"\n\t\"basic-interpreter/runtime\"\n")
+	if len(g.variables) > 0 || strings.Contains(g.out.String()+g.functions.String(), "types.") {
+		src.WriteString("\t\"basic-interpreter/types\"\n")
+	}
+	src.WriteString(")\n\n")
+
+	if len(g.variables) > 0 {
+		names := make([]string, 0, len(g.variables))
+		for name := range g.variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		src.WriteString("// Variables of the BASIC program\nvar (\n")
+		for _, name := range names {
+			fmt.Fprintf(&src, "%s = %s\n", name, zero(g.variables[name]))
+		}
+		src.WriteString(")\n\n")
+	}
+
+	fmt.Fprintf(&src, "func main() {\nm := compiled.New(runtime.NewStandardRuntime())\nm.SetScreenWidth(%d)\n", opts.ScreenWidth)
+	if g.dialect != parser.DialectExtended {
+		fmt.Fprintf(&src, "m.SetDialect(%q)\n", g.dialect)
+	}
+	src.WriteString("if err := m.Run(program); err != nil {\nfmt.Fprintln(os.Stderr, err)\nos.Exit(1)\n}\n}\n\n")
+	src.WriteString("// program runs the BASIC program; lines jumped to are labels, and RETURN and NEXT continue through a switch\n")
+	src.WriteString("func program(m *compiled.Machine) {\n")
+	src.WriteString(g.out.String())
+	src.WriteString("}\n")
+	src.WriteString(g.functions.String())
+	return src.String()
+}
+
+// statement translates one statement
+func (g *generator) statement(stmt parser.Statement) error {
+	switch s := stmt.(type) {
+	case *parser.RemStatement, *parser.DataStatement:
+		return nil
+	case *parser.EndStatement:
+		g.emit("return")
+	case *parser.StopStatement:
+		g.emit("m.Stop()")
+		g.emit("return")
+	case *parser.LetStatement:
+		value, err := g.expression(s.Expression)
+		if err != nil {
+			return err
+		}
+		g.emit("%s", g.assign(s.Variable, value))
+	case *parser.ArraySetStatement:
+		idxs, err := g.indexes(s.Indexes)
+		if err != nil {
+			return err
+		}
+		value, err := g.expression(s.Expression)
+		if err != nil {
+			return err
+		}
+		g.emit("m.SetElement(%q, %s, %s)", s.Name, idxs, value)
+	case *parser.GotoStatement:
+		g.jump(s.TargetLine)
+	case *parser.GosubStatement:
+		g.returns++
+		g.emit("m.Gosub(%d)", g.returns)
+		g.jump(s.TargetLine)
+		g.resumeAt("return", g.returns, g.hasReturn)
+	case *parser.ReturnStatement:
+		g.emit("goto returned")
+	case *parser.OnGotoStatement:
+		selector, err := g.expression(s.Selector)
+		if err != nil {
+			return err
+		}
+		g.emit("switch m.On(%s, %d) {", selector, len(s.TargetLines))
+		g.cases(s.TargetLines)
+	case *parser.OnGosubStatement:
+		selector, err := g.expression(s.Selector)
+		if err != nil {
+			return err
+		}
+		g.returns++
+		g.emit("switch m.OnGosub(%s, %d, %d) {", selector, len(s.TargetLines), g.returns)
+		g.cases(s.TargetLines)
+		g.resumeAt("return", g.returns, g.hasReturn)
+	case *parser.IfStatement:
+		return g.ifStatement(s)
+	case *parser.ForStatement:
+		return g.forStatement(s)
+	case *parser.NextStatement:
+		variable, name := "nil", ""
+		if s.Variable != "" {
+			variable, name = "&"+g.variable(s.Variable), interpreter.VariableName(s.Variable, g.dialect)
+		}
+		g.emit("if m.Next(%s, %q) {\ngoto looped\n}", variable, name)
+	case *parser.PrintStatement:
+		return g.printStatement(s)
+	case *parser.InputStatement:
+		names := make([]string, len(s.Targets))
+		for n, tgt := range s.Targets {
+			names[n] = strconv.Quote(tgt.Name)
+		}
+		g.emit("{\nin := m.Input(%s)", strings.Join(append([]string{strconv.Quote(s.Prompt)}, names...), ", "))
+		for n, tgt := range s.Targets {
+			if err := g.store(tgt, fmt.Sprintf("in[%d]", n)); err != nil {
+				return err
+			}
+		}
+		g.emit("}")
+	case *parser.GetStatement:
+		return g.readInto(s.Targets, func(name string) string { return fmt.Sprintf("m.Key(%q)", name) })
+	case *parser.ReadStatement:
+		return g.readInto(s.Targets, func(string) string { return "m.Read()" })
+	case *parser.DimStatement:
+		for _, d := range s.Declarations {
+			sizes, err := g.expressions(d.Sizes)
+			if err != nil {
+				return err
+			}
+			g.emit("m.Dim(%q, %s)", d.Name, sizes)
+		}
+	case *parser.SwapStatement:
+		return g.swapStatement(s)
+	case *parser.PokeStatement:
+		args, err := g.expressions([]parser.Expression{s.Address, s.Value})
+		if err != nil {
+			return err
+		}
+		g.emit("m.Poke(%s)", args)
+	case *parser.LocateStatement:
+		return g.locate(s)
+	case *parser.ClearScreenStatement:
+		g.emit("m.ClearScreen()")
+	case *parser.DefFnStatement:
+		return g.defFn(s)
+	default:
+		return &UnsupportedError{Line: g.line, What: statementName(stmt)}
+	}
+	return nil
+}
+
+// ifStatement translates IF with its branches inline, as the interpreter lays them out, so a GOSUB returns and
+// a NEXT loops back to the statement after them within the branch. Go cannot jump into a block, so the branches
+// are joined by gotos: a false condition continues at the ELSE statements or the end of the line.
+func (g *generator) ifStatement(s *parser.IfStatement) error {
+	condition, err := g.expression(s.Condition)
+	if err != nil {
+		return err
+	}
+	g.ifs++
+	id := g.ifs
+	g.emit("if !%s.IsTrue() {\ngoto else%d\n}", condition, id)
+	if err := g.statements(s.ThenStmts); err != nil {
+		return err
+	}
+	if len(s.ElseStmts) == 0 {
+		g.emit("else%d:", id)
+		return nil
+	}
+	g.emit("goto endif%d\nelse%d:", id, id)
+	if err := g.statements(s.ElseStmts); err != nil {
+		return err
+	}
+	g.emit("endif%d:", id)
+	return nil
+}
+
+// statements translates the statements of an IF branch
+func (g *generator) statements(stmts []parser.Statement) error {
+	for _, stmt := range stmts {
+		if err := g.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forStatement translates FOR; the loop body starts at the label that follows
+func (g *generator) forStatement(s *parser.ForStatement) error {
+	if interpreter.IsReservedVariable(s.Variable, g.dialect) {
+		return &UnsupportedError{Line: g.line, What: "FOR on a reserved variable"}
+	}
+	bounds, err := g.expressions([]parser.Expression{s.StartValue, s.EndValue})
+	if err != nil {
+		return err
+	}
+	step := "types.NewNumberValue(1)"
+	if s.StepValue != nil {
+		if step, err = g.expression(s.StepValue); err != nil {
+			return err
+		}
+	}
+	g.loops++
+	g.emit("m.For(&%s, %q, %s, %s, %d)", g.variable(s.Variable), interpreter.VariableName(s.Variable, g.dialect), bounds, step, g.loops)
+	g.resumeAt("loop", g.loops, g.hasNext)
+	return nil
+}
+
+// printStatement translates PRINT, positioning the cursor first for PRINT AT
+func (g *generator) printStatement(s *parser.PrintStatement) error {
+	if s.At != nil {
+		if err := g.locate(s.At); err != nil {
+			return err
+		}
+	}
+	if len(s.Items) == 0 {
+		value, err := g.expression(s.Expression)
+		if err != nil {
+			return err
+		}
+		g.emit("m.PrintLine(%s)", value)
+		return nil
+	}
+	items, err := g.expressions(s.Items)
+	if err != nil {
+		return err
+	}
+	separators := "nil"
+	if len(s.Separators) > 0 {
+		quoted := make([]string, len(s.Separators))
+		for n, sep := range s.Separators {
+			quoted[n] = strconv.Quote(sep)
+		}
+		separators = "[]string{" + strings.Join(quoted, ", ") + "}"
+	}
+	g.emit("m.Print(%s, %t, %s)", separators, s.NoNewline, items)
+	return nil
+}
+
+// locate translates LOCATE and the AT of PRINT AT
+func (g *generator) locate(s *parser.LocateStatement) error {
+	pos, err := g.expressions([]parser.Expression{s.Row, s.Column})
+	if err != nil {
+		return err
+	}
+	g.emit("m.Locate(%s, %d)", pos, s.Origin)
+	return nil
+}
+
+// readInto translates READ and GET, which take a value before evaluating the target's indexes
+func (g *generator) readInto(targets []parser.ReadTarget, read func(name string) string) error {
+	for _, tgt := range targets {
+		value := read(tgt.Name)
+		if len(tgt.Indices) == 0 {
+			g.emit("%s", g.assign(tgt.Name, value))
+			continue
+		}
+		g.emit("{\nvalue := %s", value)
+		if err := g.store(tgt, "value"); err != nil {
+			return err
+		}
+		g.emit("}")
+	}
+	return nil
+}
+
+// swapStatement translates SWAP, evaluating both targets' indexes before exchanging them
+func (g *generator) swapStatement(s *parser.SwapStatement) error {
+	if strings.HasSuffix(s.Left.Name, "$") != strings.HasSuffix(s.Right.Name, "$") {
+		g.emit("m.Fail(%q)", types.ErrTypeMismatch.Error())
+		return nil
+	}
+	g.emit("{")
+	targets := []parser.ReadTarget{s.Left, s.Right}
+	values := make([]string, 2)
+	for n, tgt := range targets {
+		if len(tgt.Indices) == 0 {
+			values[n] = g.variable(tgt.Name)
+		} else {
+			idxs, err := g.indexes(tgt.Indices)
+			if err != nil {
+				return err
+			}
+			g.emit("idx%d := %s", n, idxs)
+			values[n] = fmt.Sprintf("m.Element(%q, idx%d)", tgt.Name, n)
+		}
+	}
+	g.emit("left, right := %s, %s", values[0], values[1])
+	for n, value := range []string{"right", "left"} {
+		tgt := targets[n]
+		if len(tgt.Indices) > 0 {
+			g.emit("m.SetElement(%q, idx%d, %s)", tgt.Name, n, value)
+			continue
+		}
+		g.emit("%s", g.assign(tgt.Name, value))
+	}
+	g.emit("}")
+	return nil
+}
+
+// defFn translates DEF FN into a Go function that binds its parameter while the body runs
+func (g *generator) defFn(s *parser.DefFnStatement) error {
+	if interpreter.IsReservedVariable(s.Param, g.dialect) {
+		return &UnsupportedError{Line: g.line, What: "DEF FN with a reserved variable parameter"}
+	}
+	body, err := g.expression(s.Body)
+	if err != nil {
+		return err
+	}
+	g.fns++
+	name := fmt.Sprintf("fn%d", g.fns)
+	param := g.variable(s.Param)
+	fmt.Fprintf(&g.functions, "\n// %s is DEF %s(%s) on line %d\nfunc %s(m *compiled.Machine, arg types.Value) types.Value {\n", name, s.Name, s.Param, g.line, name)
+	fmt.Fprintf(&g.functions, "saved := %s\n%s\ndefer func() { %s = saved }()\nreturn %s\n}\n", param, g.assign(s.Param, "arg"), param, body)
+	g.emit("m.Define(%q, %s)", s.Name, name)
+	return nil
+}
+
+// store assigns value to a variable or array element
+func (g *generator) store(tgt parser.ReadTarget, value string) error {
+	if len(tgt.Indices) == 0 {
+		g.emit("%s", g.assign(tgt.Name, value))
+		return nil
+	}
+	idxs, err := g.indexes(tgt.Indices)
+	if err != nil {
+		return err
+	}
+	g.emit("m.SetElement(%q, %s, %s)", tgt.Name, idxs, value)
+	return nil
+}
+
+// assign returns the statement storing value in a variable, checking it has the variable's type
+func (g *generator) assign(name, value string) string {
+	if interpreter.IsReservedVariable(name, g.dialect) {
+		return fmt.Sprintf("m.SetVariable(%q, %s)", name, value)
+	}
+	check := "Number"
+	if strings.HasSuffix(name, "$") {
+		check = "String"
+	}
+	return fmt.Sprintf("%s = compiled.%s(%s)", g.variable(name), check, value)
+}
+
+// jump emits a GOTO to a line; like the interpreter a missing line is only reported if the jump runs
+func (g *generator) jump(line int) {
+	if !g.lines[line] {
+		g.emit("m.NoLine(%d)", line)
+		return
+	}
+	g.emit("goto %s", lineLabel(line))
+}
+
+// cases emits the cases of an ON switch and closes it
+func (g *generator) cases(lines []int) {
+	for n, line := range lines {
+		g.emit("case %d:", n+1)
+		g.jump(line)
+	}
+	g.emit("}")
+}
+
+// resumeAt places the label a GOSUB returns to or a loop body starts at. Labels nothing jumps to are left out,
+// as Go rejects them.
+func (g *generator) resumeAt(kind string, id int, used bool) {
+	if !used {
+		return
+	}
+	g.emit("%s%d:", kind, id)
+}
+
+// dispatch emits the switch RETURN or NEXT jump to, continuing at the label picked by next
+func (g *generator) dispatch(label, next, kind string, count int) {
+	g.emit("%s:\nswitch %s {", label, next)
+	for id := 1; id <= count; id++ {
+		g.emit("case %d:\ngoto %s%d", id, kind, id)
+	}
+	g.emit("}")
+}
+
+// data returns the program's DATA values as Go arguments
+func (g *generator) data() string {
+	// DATA holds constants, so the interpreter evaluates them here as it would before a run
+	interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+	var values []string
+	for _, line := range g.program.Lines {
+		for _, stmt := range line.Statements {
+			ds, ok := stmt.(*parser.DataStatement)
+			if !ok {
+				continue
+			}
+			for _, expr := range ds.Values {
+				if value, err := expr.Evaluate(interp); err == nil {
+					values = append(values, literal(value))
+				}
+			}
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// expression translates an expression to a Go expression of type types.Value
+func (g *generator) expression(expr parser.Expression) (string, error) {
+	switch e := expr.(type) {
+	case *parser.NumberLiteral:
+		value, err := types.ParseValue(e.Value)
+		if err != nil {
+			return fmt.Sprintf("m.Fail(%q)", err.Error()), nil
+		}
+		return literal(value), nil
+	case *parser.StringLiteral:
+		return literal(types.NewStringValue(e.Value)), nil
+	case *parser.Constant:
+		return literal(e.Value), nil
+	case *parser.VariableReference:
+		if interpreter.IsReservedVariable(e.Name, g.dialect) {
+			return fmt.Sprintf("m.Variable(%q)", e.Name), nil
+		}
+		return g.variable(e.Name), nil
+	case *parser.ArrayReference:
+		idxs, err := g.indexes(e.Indices)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("m.Element(%q, %s)", e.Name, idxs), nil
+	case *parser.BinaryOperation:
+		op, ok := binaryOps[e.Operator]
+		if !ok {
+			return "", &UnsupportedError{Line: g.line, What: "operator " + e.Operator}
+		}
+		operands, err := g.expressions([]parser.Expression{e.Left, e.Right})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("compiled.%s(%s)", op, operands), nil
+	case *parser.ComparisonExpression:
+		operands, err := g.expressions([]parser.Expression{e.Left, e.Right})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("compiled.Compare(%s, %q)", operands, e.Operator), nil
+	case *parser.UnaryOperation:
+		op, ok := unaryOps[e.Operator]
+		if !ok {
+			return "", &UnsupportedError{Line: g.line, What: "operator " + e.Operator}
+		}
+		operand, err := g.expression(e.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("compiled.%s(%s)", op, operand), nil
+	case *parser.FunctionCall:
+		args, err := g.expressions(e.Arguments)
+		if err != nil {
+			return "", err
+		}
+		call := "m.Call"
+		if strings.HasPrefix(strings.ToUpper(e.FunctionName), "FN") {
+			call = "m.CallFn"
+		}
+		if args == "" {
+			return fmt.Sprintf("%s(%q)", call, e.FunctionName), nil
+		}
+		return fmt.Sprintf("%s(%q, %s)", call, e.FunctionName, args), nil
+	}
+	return "", &UnsupportedError{Line: g.line, What: fmt.Sprintf("expression %T", expr)}
+}
+
+// Operators translated to functions of the compiled package
+var (
+	binaryOps = map[string]string{"+": "Add", "-": "Subtract", "*": "Multiply", "/": "Divide", "^": "Power", "AND": "And", "OR": "Or"}
+	unaryOps  = map[string]string{"-": "Negate", "+": "Plus", "NOT": "Not"}
+)
+
+// expressions translates expressions to a comma-separated argument list, evaluated left to right
+func (g *generator) expressions(exprs []parser.Expression) (string, error) {
+	parts := make([]string, len(exprs))
+	for n, expr := range exprs {
+		part, err := g.expression(expr)
+		if err != nil {
+			return "", err
+		}
+		parts[n] = part
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// indexes translates array indexes to a checked []int
+func (g *generator) indexes(exprs []parser.Expression) (string, error) {
+	args, err := g.expressions(exprs)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("m.Indexes(%s)", args), nil
+}
+
+// variable returns the Go variable holding a BASIC variable, declaring it on first use
+func (g *generator) variable(name string) string {
+	norm := interpreter.VariableName(name, g.dialect)
+	goName := "v" + strings.ReplaceAll(norm, "$", "_s")
+	if _, ok := g.variables[goName]; !ok {
+		g.variables[goName] = strings.HasSuffix(name, "$")
+	}
+	return goName
+}
+
+// emit appends a line of Go to the program body
+func (g *generator) emit(format string, args ...any) {
+	fmt.Fprintf(&g.out, format, args...)
+	g.out.WriteByte('\n')
+}
+
+// lineLabel is the Go label of a BASIC line
+func lineLabel(line int) string {
+	return fmt.Sprintf("line%d", line)
+}
+
+// literal returns a Go expression for a constant value
+func literal(value types.Value) string {
+	if value.Type == types.StringType {
+		return fmt.Sprintf("types.NewStringValue(%s)", strconv.Quote(value.String))
+	}
+	return fmt.Sprintf("types.NewNumberValue(%s)", strconv.FormatFloat(value.Number, 'g', -1, 64))
+}
+
+// zero returns the Go expression for a variable's value before it is assigned
+func zero(isString bool) string {
+	if isString {
+		return `types.NewStringValue("")`
+	}
+	return "types.NewNumberValue(0)"
+}
+
+// statementName names a statement type for an UnsupportedError, e.g. WHILE for *parser.WhileStatement
+func statementName(stmt parser.Statement) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*parser.")
+	return strings.ToUpper(strings.TrimSuffix(name, "Statement"))
+}
@@ -0,0 +1,206 @@
+package transpile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+)
+
+// runTree runs a program on the tree walker, returning its output and error message
+func runTree(t *testing.T, src string, inputs []string) (string, string) {
+	t.Helper()
+	rt := runtime.NewTestRuntime()
+	rt.SetInput(inputs)
+	interp := interpreter.NewInterpreter(rt)
+	interp.SetMaxSteps(0)
+	interp.SetScreenWidth(40)
+	message := ""
+	if err := interp.Execute(parsertest.Parse(t, src)); err != nil {
+		message = err.Error()
+	}
+	return strings.Join(rt.GetOutput(), ""), message
+}
+
+// buildAll writes the generated programs to one module next to this repository and builds them together,
+// returning the directory holding an executable named after each program's index
+func buildAll(t *testing.T, sources [][]byte) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds Go programs")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	repo, err := filepath.Abs("..")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	gomod := fmt.Sprintf("module generated\n\ngo 1.24\n\nrequire basic-interpreter v0.0.0\n\nreplace basic-interpreter => %s\n", repo)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644))
+	if sum, err := os.ReadFile(filepath.Join(repo, "go.sum")); err == nil {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0644))
+	}
+	for n, src := range sources {
+		pkg := filepath.Join(dir, fmt.Sprintf("p%d", n))
+		require.NoError(t, os.Mkdir(pkg, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pkg, "main.go"), src, 0644))
+	}
+	build := exec.Command("go", "build", "-o", "bin/", "./...")
+	build.Dir = dir
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return filepath.Join(dir, "bin")
+}
+
+func TestGeneratedProgramsMatchInterpreter(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		inputs []string
+	}{
+		{"arithmetic", `10 A=2+3*4-1: B=A/2^2: C=-A: D=NOT 0: E=6 AND 3 OR 8
+20 PRINT A;B;C;D;E`, nil},
+		{"strings", `10 A$="HELLO": B$=A$+" WORLD"
+20 PRINT LEFT$(B$,5);LEN(B$);MID$(B$,7,3);CHR$(65);ASC("A")`, nil},
+		{"comparisons", `10 PRINT 1<2;2<1;"A"<"B";3=3;3<>3;2>=2;1<=0`, nil},
+		{"for loop", `10 FOR I=1 TO 10 STEP 3: S=S+I: NEXT I
+20 PRINT S;I`, nil},
+		{"nested loops", `10 FOR I=1 TO 3: FOR J=1 TO I: C=C+1: NEXT J: NEXT I
+20 PRINT C`, nil},
+		{"next unwinds inner loops", `10 FOR I=1 TO 2: FOR J=1 TO 5: NEXT I
+20 PRINT I;J`, nil},
+		{"for reenters stale loop", `10 N=N+1: FOR I=1 TO 3
+20 IF N<3 THEN 10
+30 NEXT: PRINT N;I`, nil},
+		{"step zero", `10 FOR I=1 TO 3 STEP 0`, nil},
+		{"next for loop closed by outer next", `10 FOR I=1 TO 2
+20 FOR J=1 TO 2
+30 NEXT I
+40 NEXT J`, nil},
+		{"next after loop finished", `10 FOR I=1 TO 2: NEXT I
+20 NEXT I`, nil},
+		{"bare next after loop finished", `10 FOR K=1 TO 2
+20 NEXT
+30 NEXT`, nil},
+		{"gosub inside if returns into the branch", `10 X=1
+20 IF X THEN GOSUB 100: PRINT "A"
+30 PRINT "B"
+40 END
+100 PRINT "SUB": RETURN`, nil},
+		{"for inside if loops within the branch", `10 IF 1 THEN FOR I=1 TO 3: PRINT "X";
+20 PRINT I;: NEXT`, nil},
+		{"gosubs inside nested if and else", `10 FOR I=1 TO 2: IF I THEN IF I=1 THEN ON I GOSUB 100: PRINT "BACK" ELSE GOSUB 100: PRINT "ELSE"
+20 NEXT I: END
+100 PRINT "SUB";I: RETURN`, nil},
+		{"next inside if", `10 IF 1 THEN FOR I=1 TO 3: PRINT I: NEXT I
+20 PRINT "END"`, nil},
+		{"if else", `10 IF 0 THEN PRINT "A" ELSE PRINT "B"
+20 PRINT "C"`, nil},
+		{"on goto and gosub", `10 FOR K=0 TO 3: ON K GOSUB 100,200: ON K GOTO 50,50: PRINT "OUT";K
+50 NEXT: END
+100 PRINT "ONE": RETURN
+200 PRINT "TWO": RETURN`, nil},
+		{"error reports line after return", `10 GOSUB 100: A$=1
+100 RETURN`, nil},
+		{"undefined line", `10 PRINT "A": GOTO 999`, nil},
+		{"return without gosub", `10 RETURN`, nil},
+		{"division by zero", `10 PRINT 1/0`, nil},
+		{"arrays", `10 DIM A(3,3): FOR I=0 TO 3: A(I,I)=I*I: NEXT
+20 PRINT A(2,2)+A(3,3);B(5): B(5)=7: PRINT B(5)`, nil},
+		{"read data", `10 READ A,B$: PRINT A;B$: READ C
+20 DATA 5,"X"`, nil},
+		{"input", `10 INPUT "NAME";N$: INPUT A,B: PRINT N$;A*B`, []string{"BOB", "X", "6,7"}},
+		{"swap", `10 A=1: B=2: DIM C(2): C(1)=9: SWAP A,B: SWAP C(1),A: PRINT A;B;C(1)`, nil},
+		{"def fn", `10 DEF FNS(X)=X*X+Y: Y=1: X=5: PRINT FNS(3);X
+20 DEF FNS(X)=X+1: PRINT FNS(3)`, nil},
+		{"long names share a variable", `10 COUNT=1: COLOR=2: PRINT COUNT`, nil},
+		{"print zones and tab", `10 PRINT "A","B";TAB(15);"C": PRINT SPC(3);"D"`, nil},
+		{"stop", `10 PRINT "A": STOP: PRINT "B"`, nil},
+	}
+	sources := make([][]byte, len(tests))
+	for n, tt := range tests {
+		src, err := Go(parsertest.Parse(t, tt.src), Options{Name: tt.name, ScreenWidth: 40})
+		require.NoError(t, err, tt.name)
+		sources[n] = src
+	}
+	bin := buildAll(t, sources)
+
+	for n, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantOutput, wantErr := runTree(t, tt.src, tt.inputs)
+			if tt.name == "stop" {
+				// The interpreter leaves the BREAK message to its caller
+				wantOutput += "BREAK IN 10\n"
+			}
+			cmd := exec.Command(filepath.Join(bin, fmt.Sprintf("p%d", n)))
+			cmd.Stdin = strings.NewReader(strings.Join(tt.inputs, "\n") + "\n")
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout, cmd.Stderr = &stdout, &stderr
+			err := cmd.Run()
+			assert.Equal(t, wantOutput, stdout.String())
+			assert.Equal(t, wantErr, strings.TrimSpace(stderr.String()))
+			assert.Equal(t, wantErr != "", err != nil)
+		})
+	}
+}
+
+func TestGoRejectsUnsupportedStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"while", "10 WHILE 0\n20 WEND", "line 10: WHILE is not supported by basic build"},
+		{"timer", "10 EVERY 60 GOSUB 100\n100 RETURN", "line 10: TIMER is not supported by basic build"},
+		{"run", "10 RUN", "line 10: RUN is not supported by basic build"},
+		{"nested in if", "10 IF 1 THEN CLR", "line 10: CLR is not supported by basic build"},
+		{"for on clock", "10 FOR TI=1 TO 2: NEXT", "line 10: FOR on a reserved variable is not supported by basic build"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Go(parsertest.Parse(t, tt.src), Options{})
+			var unsupported *UnsupportedError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestGoLabelsOnlyLinesJumpedTo(t *testing.T) {
+	src, err := Go(parsertest.Parse(t, "10 PRINT 1\n20 GOTO 40\n30 PRINT 2\n40 END"), Options{Name: "jump.bas"})
+	require.NoError(t, err)
+	code := string(src)
+	assert.Contains(t, code, "// Code generated by basic build from jump.bas. DO NOT EDIT.")
+	assert.Contains(t, code, "goto line40")
+	assert.Contains(t, code, "\nline40:\n")
+	assert.NotContains(t, code, "line30:")
+	assert.NotContains(t, code, "returned:")
+}
+
+func TestGoNamesVariablesByDialect(t *testing.T) {
+	program := parsertest.Parse(t, "10 SCORE=1: SCALE=2: NAME$=\"A\": NA=3")
+	src, err := Go(program, Options{})
+	require.NoError(t, err)
+	code := string(src)
+	assert.Contains(t, code, "vSCORE = compiled.Number(")
+	assert.NotContains(t, code, "SetDialect")
+
+	src, err = Go(program, Options{Dialect: parser.DialectC64})
+	require.NoError(t, err)
+	code = string(src)
+	assert.Contains(t, code, "vSC = compiled.Number(")
+	assert.Contains(t, code, "vNA_s = compiled.String(")
+	assert.NotContains(t, code, "vSCORE")
+	assert.Contains(t, code, `m.SetDialect("c64")`)
+}
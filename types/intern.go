@@ -0,0 +1,52 @@
+// ABOUTME: String interning so repeated literals, variable names and stored strings share one copy
+// ABOUTME: Interned strings that are equal also share memory, which makes comparing them a pointer check
+
+package types
+
+import "strings"
+
+// Interning limits; longer strings, and new strings once the table is full, are returned unchanged
+const (
+	MaxInternLength  = 64
+	MaxInternEntries = 1 << 14
+)
+
+// Interner hands out one canonical copy of each distinct short string
+type Interner struct {
+	table map[string]string
+}
+
+// NewInterner creates an empty interner
+func NewInterner() *Interner {
+	return &Interner{table: make(map[string]string)}
+}
+
+// Intern returns the canonical copy of s. The first copy is cloned so it does not pin a larger buffer,
+// such as the program source s was sliced from.
+func (in *Interner) Intern(s string) string {
+	if len(s) > MaxInternLength {
+		return s
+	}
+	if canonical, ok := in.table[s]; ok {
+		return canonical
+	}
+	if len(in.table) >= MaxInternEntries {
+		return s
+	}
+	s = strings.Clone(s)
+	in.table[s] = s
+	return s
+}
+
+// InternValue interns the text of a string value
+func (in *Interner) InternValue(v Value) Value {
+	if v.Type == StringType {
+		v.String = in.Intern(v.String)
+	}
+	return v
+}
+
+// Len returns the number of distinct strings interned
+func (in *Interner) Len() int {
+	return len(in.table)
+}
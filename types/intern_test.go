@@ -0,0 +1,45 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterner_SharesEqualStrings(t *testing.T) {
+	in := NewInterner()
+	source := `PRINT "CAVE": PRINT "CAVE"`
+
+	first := in.Intern(source[7:11])
+	second := in.Intern(source[21:25])
+
+	assert.Equal(t, "CAVE", first)
+	assert.Same(t, unsafe.StringData(first), unsafe.StringData(second))
+	assert.NotSame(t, unsafe.StringData(source[7:11]), unsafe.StringData(first), "the canonical copy must not pin the source")
+	assert.Equal(t, 1, in.Len())
+}
+
+func TestInterner_Limits(t *testing.T) {
+	in := NewInterner()
+	long := strings.Repeat("X", MaxInternLength+1)
+	assert.Equal(t, long, in.Intern(long))
+	assert.Equal(t, 0, in.Len())
+
+	for n := 0; n < MaxInternEntries; n++ {
+		in.Intern(strconv.Itoa(n))
+	}
+	assert.Equal(t, MaxInternEntries, in.Len())
+	assert.Equal(t, "FULL", in.Intern("FULL"))
+	assert.Equal(t, MaxInternEntries, in.Len())
+}
+
+func TestInterner_InternValue(t *testing.T) {
+	in := NewInterner()
+	a := in.InternValue(NewStringValue(strings.Repeat("AB", 2)))
+	b := in.InternValue(NewStringValue("AB" + "AB"))
+	assert.Same(t, unsafe.StringData(a.String), unsafe.StringData(b.String))
+	assert.Equal(t, NewNumberValue(3), in.InternValue(NewNumberValue(3)))
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Commodore 64 floating point: 5-byte MFLPT numbers with an 8-bit exponent and a 32-bit mantissa
+// ABOUTME: Precision selects whether numbers keep float64 precision or are rounded and printed as a C64 does
+
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxMFLPT is the largest number a C64 holds, 1.70141183E+38
+const MaxMFLPT = (1 - 0x1p-32) * 0x1p127
+
+// MFLPT is a number in the C64's 5-byte format: the exponent biased by 128 (0 for zero), then a big-endian mantissa
+// in [0.5, 1) whose always-set top bit holds the sign instead
+type MFLPT [5]byte
+
+// ToMFLPT converts a number to MFLPT, rounding the mantissa to 32 bits; numbers too small to hold become zero
+func ToMFLPT(n float64) (MFLPT, error) {
+	var m MFLPT
+	if math.IsNaN(n) || math.Abs(n) > MaxMFLPT {
+		return m, ErrOverflow
+	}
+	frac, exp := math.Frexp(math.Abs(n))
+	mantissa := uint64(math.Floor(frac*0x1p32 + 0.5))
+	if mantissa == 1<<32 {
+		mantissa, exp = 1<<31, exp+1
+	}
+	if frac == 0 || exp+128 < 1 {
+		return m, nil
+	}
+	m[0] = byte(exp + 128)
+	for i := 4; i >= 1; i-- {
+		m[i] = byte(mantissa)
+		mantissa >>= 8
+	}
+	m[1] &^= 0x80
+	if n < 0 {
+		m[1] |= 0x80
+	}
+	return m, nil
+}
+
+// Float64 returns the number an MFLPT holds
+func (m MFLPT) Float64() float64 {
+	if m[0] == 0 {
+		return 0
+	}
+	mantissa := uint64(m[1]|0x80)<<24 | uint64(m[2])<<16 | uint64(m[3])<<8 | uint64(m[4])
+	n := math.Ldexp(float64(mantissa), int(m[0])-128-32)
+	if m[1]&0x80 != 0 {
+		return -n
+	}
+	return n
+}
+
+// Precision selects how numbers are stored and printed
+type Precision int
+
+// Supported precisions
+const (
+	PrecisionDouble Precision = iota // float64, printed with as many digits as it takes (the default)
+	PrecisionC64                     // MFLPT, printed to 9 significant digits
+)
+
+// Round returns n as the precision stores it; in PrecisionC64, numbers beyond MaxMFLPT are returned unchanged
+func (p Precision) Round(n float64) float64 {
+	if p != PrecisionC64 {
+		return n
+	}
+	m, err := ToMFLPT(n)
+	if err != nil {
+		return n
+	}
+	return m.Float64()
+}
+
+// Format returns the text PRINT and STR$ show for a value: numbers as the C64 ROM writes them in PrecisionC64
+// (see formatC64), so 0.1+0.2 prints as 0.3
+func (p Precision) Format(v Value) string {
+	if p != PrecisionC64 || v.Type != NumberType {
+		return v.ToString()
+	}
+	return formatC64(p.Round(v.Number))
+}
+
+// formatC64 writes a number rounded to 9 significant digits, without trailing zeros. As in the ROM, numbers from
+// .01 up to 999999999 are written out and others take an E with a signed two-digit exponent: 1E+09, 1.23E-04.
+func formatC64(n float64) string {
+	if n == 0 {
+		return "0"
+	}
+	digits, exp, _ := strings.Cut(strconv.FormatFloat(n, 'e', 8, 64), "e")
+	e, _ := strconv.Atoi(exp)
+	if e >= -2 && e <= 8 {
+		rounded, _ := strconv.ParseFloat(digits+"e"+exp, 64)
+		return strconv.FormatFloat(rounded, 'f', -1, 64)
+	}
+	digits = strings.TrimSuffix(strings.TrimRight(digits, "0"), ".")
+	return fmt.Sprintf("%sE%+03d", digits, e)
+}
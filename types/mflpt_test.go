@@ -0,0 +1,105 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToMFLPT(t *testing.T) {
+	// Bytes of constants in the C64 ROM (1, 0.5, 10, SQR(2), 1E9, -32768, 2*π), or worked out by hand
+	tests := []struct {
+		n    float64
+		want MFLPT
+	}{
+		{0, MFLPT{0x00, 0x00, 0x00, 0x00, 0x00}},
+		{1, MFLPT{0x81, 0x00, 0x00, 0x00, 0x00}},
+		{-1, MFLPT{0x81, 0x80, 0x00, 0x00, 0x00}},
+		{0.5, MFLPT{0x80, 0x00, 0x00, 0x00, 0x00}},
+		{10, MFLPT{0x84, 0x20, 0x00, 0x00, 0x00}},
+		{0.1, MFLPT{0x7D, 0x4C, 0xCC, 0xCC, 0xCD}},
+		{2 * math.Pi, MFLPT{0x83, 0x49, 0x0F, 0xDA, 0xA2}},
+		{math.Sqrt2, MFLPT{0x81, 0x35, 0x04, 0xF3, 0x34}},
+		{1e9, MFLPT{0x9E, 0x6E, 0x6B, 0x28, 0x00}},
+		{-32768, MFLPT{0x90, 0x80, 0x00, 0x00, 0x00}},
+		{MaxMFLPT, MFLPT{0xFF, 0x7F, 0xFF, 0xFF, 0xFF}},
+		{1e-40, MFLPT{}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			got, err := ToMFLPT(tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ToMFLPT(1e39)
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = ToMFLPT(math.Inf(-1))
+	assert.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestMFLPT_Float64(t *testing.T) {
+	assert.Equal(t, 0.0, MFLPT{}.Float64())
+	assert.Equal(t, -0.5, MFLPT{0x80, 0x80, 0x00, 0x00, 0x00}.Float64())
+	largest := NewNumberValue(MFLPT{0xFF, 0x7F, 0xFF, 0xFF, 0xFF}.Float64())
+	assert.Equal(t, "1.70141183E+38", PrecisionC64.Format(largest))
+	assert.InDelta(t, math.Ln2, MFLPT{0x80, 0x31, 0x72, 0x17, 0xF8}.Float64(), 1e-10)
+	for _, n := range []float64{1, -1, 10, 1e9, 0.25, -32768, MaxMFLPT} {
+		m, err := ToMFLPT(n)
+		require.NoError(t, err)
+		assert.Equal(t, n, m.Float64(), "%v survives the round trip", n)
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	tenth := 0.1
+	tests := []struct {
+		name      string
+		precision Precision
+		value     Value
+		want      string
+	}{
+		{"double keeps every digit", PrecisionDouble, NewNumberValue(tenth + 0.2), "0.30000000000000004"},
+		{"c64 sum", PrecisionC64, NewNumberValue(tenth + 0.2), "0.3"},
+		{"c64 third", PrecisionC64, NewNumberValue(1.0 / 3), "0.333333333"},
+		{"c64 integer", PrecisionC64, NewNumberValue(123456789), "123456789"},
+		{"c64 large", PrecisionC64, NewNumberValue(1234567890), "1.23456789E+09"},
+		{"c64 largest written out", PrecisionC64, NewNumberValue(999999999), "999999999"},
+		{"c64 rounds up into E notation", PrecisionC64, NewNumberValue(999999999.6), "1E+09"},
+		{"c64 power of ten", PrecisionC64, NewNumberValue(1e9), "1E+09"},
+		{"c64 eleven digits", PrecisionC64, NewNumberValue(123456789012), "1.23456789E+11"},
+		{"c64 negative", PrecisionC64, NewNumberValue(-1.5e12), "-1.5E+12"},
+		{"c64 hundredth written out", PrecisionC64, NewNumberValue(0.0123), "0.0123"},
+		{"c64 thousandth", PrecisionC64, NewNumberValue(0.001), "1E-03"},
+		{"c64 small", PrecisionC64, NewNumberValue(0.000123), "1.23E-04"},
+		{"c64 tiny", PrecisionC64, NewNumberValue(-2.5e-30), "-2.5E-30"},
+		{"c64 pi", PrecisionC64, NewNumberValue(math.Pi), "3.14159265"},
+		{"strings unchanged", PrecisionC64, NewStringValue("0.1"), "0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.precision.Format(tt.value))
+		})
+	}
+}
+
+func TestPrecision_Round(t *testing.T) {
+	assert.Equal(t, 0.1, PrecisionDouble.Round(0.1))
+	assert.Equal(t, 0.10000000000582077, PrecisionC64.Round(0.1))
+	assert.Equal(t, 1e39, PrecisionC64.Round(1e39), "out of range numbers are left for the caller to report")
+
+	// Adding 0.1 a thousand times drifts within the 9 digits printed, as it does on a C64
+	sum := 0.0
+	for range 1000 {
+		sum = PrecisionC64.Round(sum + PrecisionC64.Round(0.1))
+		m, err := ToMFLPT(sum)
+		require.NoError(t, err)
+		require.Equal(t, sum, m.Float64(), "every stored sum is an MFLPT number")
+	}
+	assert.InDelta(t, 100, sum, 1e-5)
+	assert.NotEqual(t, "100", PrecisionC64.Format(NewNumberValue(sum)))
+}
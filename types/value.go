@@ -5,6 +5,7 @@ package types
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"strconv"
 )
@@ -17,17 +18,42 @@ const (
 	StringType
 )
 
+// MarshalText encodes the type as "number" or "string"
+func (t ValueType) MarshalText() ([]byte, error) {
+	switch t {
+	case NumberType:
+		return []byte("number"), nil
+	case StringType:
+		return []byte("string"), nil
+	}
+	return nil, fmt.Errorf("unknown value type %d", int(t))
+}
+
+// UnmarshalText decodes a type encoded by MarshalText
+func (t *ValueType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "number":
+		*t = NumberType
+	case "string":
+		*t = StringType
+	default:
+		return fmt.Errorf("unknown value type %q", text)
+	}
+	return nil
+}
+
 // Value represents a BASIC value with type information
 type Value struct {
-	Type   ValueType
-	Number float64
-	String string
+	Type   ValueType `json:"type"`
+	Number float64   `json:"number,omitempty"`
+	String string    `json:"string,omitempty"`
 }
 
 // Predefined errors for consistent C64 error messages
 var (
 	ErrTypeMismatch   = errors.New("?TYPE MISMATCH ERROR")
 	ErrDivisionByZero = errors.New("?DIVISION BY ZERO ERROR")
+	ErrOverflow       = errors.New("?OVERFLOW ERROR") // A number beyond MaxMFLPT
 )
 
 // NewNumberValue creates a numeric value
@@ -72,6 +98,9 @@ func (v Value) ToNumber() (float64, error) {
 	case NumberType:
 		return v.Number, nil
 	case StringType:
+		if !mayBeNumber(v.String) {
+			return 0, ErrTypeMismatch
+		}
 		num, err := strconv.ParseFloat(v.String, 64)
 		if err != nil {
 			return 0, ErrTypeMismatch
@@ -82,6 +111,18 @@ func (v Value) ToNumber() (float64, error) {
 	}
 }
 
+// mayBeNumber cheaply rejects text strconv.ParseFloat can never accept, sparing the error it would allocate
+func mayBeNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	switch c := s[0]; {
+	case c >= '0' && c <= '9', c == '+', c == '-', c == '.', c == 'i', c == 'I', c == 'n', c == 'N':
+		return true
+	}
+	return false
+}
+
 // IsNumber returns true if the value is numeric
 func (v Value) IsNumber() bool {
 	return v.Type == NumberType
@@ -102,7 +143,7 @@ func (v Value) binaryArithmeticOp(other Value, operation func(float64, float64)
 	if err != nil {
 		return Value{}, err
 	}
-	return NewNumberValue(operation(left, right)), nil
+	return checkRange(operation(left, right))
 }
 
 // binaryArithmeticOpWithError performs a binary arithmetic operation that can return an error
@@ -119,7 +160,15 @@ func (v Value) binaryArithmeticOpWithError(other Value, operation func(float64,
 	if err != nil {
 		return Value{}, err
 	}
-	return NewNumberValue(result), nil
+	return checkRange(result)
+}
+
+// checkRange returns a result as a value, or ErrOverflow when it is beyond the largest number a C64 holds
+func checkRange(n float64) (Value, error) {
+	if math.Abs(n) > MaxMFLPT {
+		return Value{}, ErrOverflow
+	}
+	return NewNumberValue(n), nil
 }
 
 // Add performs addition on two values
@@ -132,7 +181,7 @@ func (v Value) Add(other Value) (Value, error) {
 
 		// If both can be converted to numbers, do numeric addition
 		if leftErr == nil && rightErr == nil {
-			return NewNumberValue(leftNum + rightNum), nil
+			return checkRange(leftNum + rightNum)
 		}
 
 		// Otherwise, do string concatenation
@@ -81,6 +81,10 @@ func TestValue_ToNumber(t *testing.T) {
 		{"string float", NewStringValue("42.5"), 42.5, false},
 		{"invalid string", NewStringValue("abc"), 0, true},
 		{"mixed string", NewStringValue("42abc"), 0, true},
+		{"signed string", NewStringValue("-7"), -7, false},
+		{"leading dot string", NewStringValue(".5"), 0.5, false},
+		{"empty string", NewStringValue(""), 0, true},
+		{"leading space string", NewStringValue(" 1"), 0, true},
 	}
 
 	for _, tt := range tests {
@@ -160,6 +164,24 @@ func TestValue_ArithmeticOperations(t *testing.T) {
 		_, err := v1.Add(v2)
 		assert.Error(t, err)
 	})
+
+	t.Run("overflow", func(t *testing.T) {
+		big := NewNumberValue(1e38)
+		_, err := big.Multiply(NewNumberValue(2))
+		assert.ErrorIs(t, err, ErrOverflow)
+		_, err = NewNumberValue(-1e38).Subtract(big)
+		assert.ErrorIs(t, err, ErrOverflow)
+		_, err = NewNumberValue(10).Power(NewNumberValue(39))
+		assert.ErrorIs(t, err, ErrOverflow)
+		_, err = big.Divide(NewNumberValue(1e-10))
+		assert.ErrorIs(t, err, ErrOverflow)
+		_, err = NewStringValue("1E38").Add(NewStringValue("1E38"))
+		assert.ErrorIs(t, err, ErrOverflow)
+
+		result, err := NewNumberValue(1.7e38).Add(NewNumberValue(1e30))
+		require.NoError(t, err)
+		assert.Equal(t, NewNumberValue(1.7e38+1e30), result, "up to 1.70141183E+38 is in range")
+	})
 }
 
 func TestValue_IsTrue(t *testing.T) {
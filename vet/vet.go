@@ -0,0 +1,643 @@
+// ABOUTME: Static checks for basic vet: missing jump targets, unreachable lines, NEXT without FOR, stray RETURN,
+// ABOUTME: variables read before any assignment and long names that C64 BASIC truncates to the same variable
+
+package vet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+)
+
+// Check names the kind of problem a finding reports
+type Check string
+
+// Checks, in the order findings on the same line are reported
+const (
+	UndefinedTarget Check = "undefined-target"  // GOTO, GOSUB, THEN, ON, RUN or a timer names a missing line
+	Unreachable     Check = "unreachable"       // No path from the first line runs the line
+	NextWithoutFor  Check = "next-without-for"  // NEXT with no FOR for its variable before it
+	StrayReturn     Check = "stray-return"      // RETURN on a line no GOSUB leads to
+	ReadBeforeWrite Check = "read-before-write" // A variable read where no path has assigned it yet
+	NameCollision   Check = "name-collision"    // Different names that share the two characters BASIC keeps
+)
+
+var checkOrder = map[Check]int{UndefinedTarget: 0, Unreachable: 1, NextWithoutFor: 2, StrayReturn: 3, ReadBeforeWrite: 4, NameCollision: 5}
+
+// Finding is one problem, on a BASIC line
+type Finding struct {
+	Line    int
+	Check   Check
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", f.Line, f.Message, f.Check)
+}
+
+// analysis holds the control flow of one program. Lines are known by their position in program.Lines.
+type analysis struct {
+	lines    []*parser.Line
+	index    map[int]int     // Position of each line number
+	succ     [][]int         // Lines control can move to after each line
+	subs     []int           // Lines called by GOSUB, ON...GOSUB and timers
+	assigned map[int]nameSet // Variables assigned on the lines reachable from a subroutine's first line
+	dialect  parser.Dialect  // Dialect deciding which characters of a name count
+	findings []Finding
+}
+
+// nameSet is a set of significant variable names
+type nameSet map[string]bool
+
+// Program checks a parsed program as it runs in a dialect and returns its findings ordered by line. Names that
+// only the c64 dialect treats as one variable are reported in either dialect, as they break when switching to it.
+func Program(program *parser.Program, dialect parser.Dialect) []Finding {
+	a := &analysis{
+		lines:    program.Lines,
+		index:    make(map[int]int, len(program.Lines)),
+		succ:     make([][]int, len(program.Lines)),
+		assigned: make(map[int]nameSet),
+		dialect:  dialect,
+	}
+	for pos, line := range program.Lines {
+		a.index[line.Number] = pos
+	}
+	a.loops()
+	for pos, line := range a.lines {
+		if a.flow(pos, line.Statements) && pos+1 < len(a.lines) {
+			a.edge(pos, pos+1)
+		}
+	}
+	reached := a.reach(0)
+	a.unreachable(reached)
+	a.returns()
+	a.readBeforeWrite()
+	a.collisions()
+
+	sort.SliceStable(a.findings, func(x, y int) bool {
+		fx, fy := a.findings[x], a.findings[y]
+		if fx.Line != fy.Line {
+			return fx.Line < fy.Line
+		}
+		return checkOrder[fx.Check] < checkOrder[fy.Check]
+	})
+	return a.findings
+}
+
+func (a *analysis) report(pos int, check Check, format string, args ...any) {
+	a.findings = append(a.findings, Finding{Line: a.lines[pos].Number, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+func (a *analysis) edge(from, to int) {
+	a.succ[from] = append(a.succ[from], to)
+}
+
+// jump adds an edge to a target line, reporting it when the line does not exist
+func (a *analysis) jump(pos int, keyword string, target int) (int, bool) {
+	to, ok := a.index[target]
+	if !ok {
+		a.report(pos, UndefinedTarget, "%s %d: there is no line %d", keyword, target, target)
+		return 0, false
+	}
+	a.edge(pos, to)
+	return to, true
+}
+
+// call adds an edge to the first line of a subroutine
+func (a *analysis) call(pos int, keyword string, target int) {
+	if to, ok := a.jump(pos, keyword, target); ok {
+		a.subs = append(a.subs, to)
+	}
+}
+
+// flow adds the jumps of a statement list and reports whether control can continue past its end
+func (a *analysis) flow(pos int, stmts []parser.Statement) bool {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *parser.GotoStatement:
+			a.jump(pos, "GOTO", s.TargetLine)
+			return false
+		case *parser.GosubStatement:
+			a.call(pos, "GOSUB", s.TargetLine)
+		case *parser.OnGotoStatement:
+			for _, target := range s.TargetLines {
+				a.jump(pos, "ON...GOTO", target)
+			}
+		case *parser.OnGosubStatement:
+			for _, target := range s.TargetLines {
+				a.call(pos, "ON...GOSUB", target)
+			}
+		case *parser.TimerStatement:
+			a.call(pos, "GOSUB", s.TargetLine)
+		case *parser.RunStatement:
+			if s.HasStart {
+				a.jump(pos, "RUN", s.StartLine)
+			} else if len(a.lines) > 0 {
+				a.edge(pos, 0)
+			}
+			return false
+		case *parser.IfStatement:
+			// A false condition skips the rest of the line, unless an ELSE branch takes over
+			thenFalls := a.flow(pos, s.ThenStmts)
+			if len(s.ElseStmts) == 0 {
+				return true
+			}
+			elseFalls := a.flow(pos, s.ElseStmts)
+			return thenFalls || elseFalls
+		case *parser.LoopStatement:
+			if s.Condition == nil {
+				return false
+			}
+		case *parser.EndStatement, *parser.StopStatement, *parser.NewStatement, *parser.ReturnStatement:
+			return false
+		}
+	}
+	return true
+}
+
+// loops adds the edges of FOR...NEXT, WHILE...WEND and DO...LOOP and reports each NEXT with no FOR before it
+func (a *analysis) loops() {
+	forLines := map[string]int{} // Last FOR line of each loop variable
+	lastFor := -1
+	var whiles, dos []int
+	for pos, line := range a.lines {
+		eachStatement(line.Statements, func(stmt parser.Statement) {
+			switch s := stmt.(type) {
+			case *parser.ForStatement:
+				forLines[a.significant(s.Variable)] = pos
+				lastFor = pos
+			case *parser.NextStatement:
+				from, ok := lastFor, lastFor >= 0
+				if s.Variable != "" {
+					from, ok = forLines[a.significant(s.Variable)]
+				}
+				switch {
+				case ok:
+					a.edge(pos, from)
+				case s.Variable != "":
+					a.report(pos, NextWithoutFor, "NEXT %s has no FOR %s before it", s.Variable, s.Variable)
+				default:
+					a.report(pos, NextWithoutFor, "NEXT has no FOR before it")
+				}
+			case *parser.WhileStatement:
+				whiles = append(whiles, pos)
+			case *parser.WendStatement:
+				if n := len(whiles); n > 0 {
+					a.edge(pos, whiles[n-1])
+					a.edge(whiles[n-1], pos) // A false condition skips to the WEND
+					whiles = whiles[:n-1]
+				}
+			case *parser.DoStatement:
+				dos = append(dos, pos)
+			case *parser.LoopStatement:
+				if n := len(dos); n > 0 {
+					a.edge(pos, dos[n-1])
+					dos = dos[:n-1]
+				}
+			}
+		})
+	}
+}
+
+// reach returns the lines reachable from the given ones
+func (a *analysis) reach(from ...int) []bool {
+	reached := make([]bool, len(a.lines))
+	work := []int{}
+	for _, pos := range from {
+		if pos < len(a.lines) && !reached[pos] {
+			reached[pos] = true
+			work = append(work, pos)
+		}
+	}
+	for len(work) > 0 {
+		pos := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, next := range a.succ[pos] {
+			if !reached[next] {
+				reached[next] = true
+				work = append(work, next)
+			}
+		}
+	}
+	return reached
+}
+
+// unreachable reports each run of lines that never run; lines holding only REM and DATA are skipped, as they need not run
+func (a *analysis) unreachable(reached []bool) {
+	start, end := -1, -1
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		if start == end {
+			a.report(start, Unreachable, "line %d can never run", a.lines[start].Number)
+		} else {
+			a.report(start, Unreachable, "lines %d-%d can never run", a.lines[start].Number, a.lines[end].Number)
+		}
+		start = -1
+	}
+	for pos, line := range a.lines {
+		switch {
+		case !executable(line):
+		case reached[pos]:
+			flush()
+		default:
+			if start < 0 {
+				start = pos
+			}
+			end = pos
+		}
+	}
+	flush()
+}
+
+// executable reports whether a line holds a statement other than REM and DATA
+func executable(line *parser.Line) bool {
+	for _, stmt := range line.Statements {
+		switch stmt.(type) {
+		case *parser.RemStatement, *parser.DataStatement:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// returns reports RETURN statements on lines that no subroutine call leads to
+func (a *analysis) returns() {
+	inSub := a.reach(a.subs...)
+	for pos, line := range a.lines {
+		if inSub[pos] {
+			continue
+		}
+		found := false
+		eachStatement(line.Statements, func(stmt parser.Statement) {
+			if _, ok := stmt.(*parser.ReturnStatement); ok {
+				found = true
+			}
+		})
+		if found {
+			a.report(pos, StrayReturn, "RETURN is not reached from any GOSUB")
+		}
+	}
+}
+
+// readBeforeWrite reports, once per variable, a read on a line that no path from the first line reaches with
+// the variable assigned. A variable assigned on any path counts, so loops and branches do not cause reports.
+func (a *analysis) readBeforeWrite() {
+	if len(a.lines) == 0 {
+		return
+	}
+	in := make([]nameSet, len(a.lines)) // Variables assigned on some path to each line, nil for lines never reached
+	in[0] = nameSet{}
+	work := []int{0}
+	for len(work) > 0 {
+		pos := work[len(work)-1]
+		work = work[:len(work)-1]
+		out := a.transfer(pos, in[pos], nil)
+		for _, next := range a.succ[pos] {
+			grew := in[next] == nil
+			if grew {
+				in[next] = nameSet{}
+			}
+			for name := range out {
+				if !in[next][name] {
+					in[next][name] = true
+					grew = true
+				}
+			}
+			if grew {
+				work = append(work, next)
+			}
+		}
+	}
+
+	reported := nameSet{}
+	for pos := range a.lines {
+		if in[pos] == nil {
+			continue
+		}
+		a.transfer(pos, in[pos], func(name string) {
+			if reported[a.significant(name)] {
+				return
+			}
+			reported[a.significant(name)] = true
+			value := "0"
+			if strings.HasSuffix(name, "$") {
+				value = `""`
+			}
+			a.report(pos, ReadBeforeWrite, "%s is read before anything is assigned to it, so it is %s", name, value)
+		})
+	}
+}
+
+// transfer returns the variables assigned after a line runs, given those assigned before; read is called with
+// each variable read while unassigned
+func (a *analysis) transfer(pos int, before nameSet, read func(name string)) nameSet {
+	set := make(nameSet, len(before))
+	for name := range before {
+		set[name] = true
+	}
+	a.statements(a.lines[pos].Statements, set, read)
+	return set
+}
+
+// statements updates set with the assignments of a statement list
+func (a *analysis) statements(stmts []parser.Statement, set nameSet, read func(name string)) {
+	reads := func(exprs ...parser.Expression) {
+		for _, e := range exprs {
+			a.variables(e, func(name string) {
+				if read != nil && !set[a.significant(name)] {
+					read(name)
+				}
+			})
+		}
+	}
+	assign := func(name string) { set[a.significant(name)] = true }
+	targets := func(targets ...parser.ReadTarget) {
+		for _, t := range targets {
+			reads(t.Indices...)
+			if len(t.Indices) == 0 {
+				assign(t.Name)
+			}
+		}
+	}
+	called := func(target int) {
+		if pos, ok := a.index[target]; ok {
+			for name := range a.subroutineAssigns(pos) {
+				set[name] = true
+			}
+		}
+	}
+
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *parser.LetStatement:
+			reads(s.Expression)
+			assign(s.Variable)
+		case *parser.ConstStatement:
+			reads(s.Expression)
+			assign(s.Name)
+		case *parser.ForStatement:
+			reads(s.StartValue, s.EndValue, s.StepValue)
+			assign(s.Variable)
+		case *parser.InputStatement:
+			targets(s.Targets...)
+		case *parser.ReadStatement:
+			targets(s.Targets...)
+		case *parser.GetStatement:
+			targets(s.Targets...)
+		case *parser.InputFileStatement:
+			reads(s.File)
+			targets(s.Targets...)
+		case *parser.GetFileStatement:
+			reads(s.File)
+			targets(s.Targets...)
+		case *parser.SwapStatement:
+			targets(s.Left, s.Right)
+		case *parser.IfStatement:
+			reads(s.Condition)
+			a.statements(s.ThenStmts, set, read)
+			a.statements(s.ElseStmts, set, read)
+		case *parser.GosubStatement:
+			called(s.TargetLine)
+		case *parser.OnGosubStatement:
+			reads(s.Selector)
+			for _, target := range s.TargetLines {
+				called(target)
+			}
+		case *parser.TimerStatement:
+			reads(s.Ticks, s.Timer)
+			called(s.TargetLine)
+		case *parser.DefFnStatement:
+			// The body reads variables when the function is called, not here
+		default:
+			reads(expressions(stmt)...)
+		}
+	}
+}
+
+// subroutineAssigns returns the variables assigned on the lines reachable from the first line of a subroutine
+func (a *analysis) subroutineAssigns(pos int) nameSet {
+	if set, ok := a.assigned[pos]; ok {
+		return set
+	}
+	set := nameSet{}
+	a.assigned[pos] = set // Recursive calls see the names found so far
+	for n, reached := range a.reach(pos) {
+		if reached {
+			eachStatement(a.lines[n].Statements, func(stmt parser.Statement) {
+				for _, name := range assignedNames(stmt) {
+					set[a.significant(name)] = true
+				}
+			})
+		}
+	}
+	return set
+}
+
+// assignedNames returns the simple variables a statement assigns, not counting nested statements
+func assignedNames(stmt parser.Statement) []string {
+	var targets []parser.ReadTarget
+	switch s := stmt.(type) {
+	case *parser.LetStatement:
+		return []string{s.Variable}
+	case *parser.ConstStatement:
+		return []string{s.Name}
+	case *parser.ForStatement:
+		return []string{s.Variable}
+	case *parser.InputStatement:
+		targets = s.Targets
+	case *parser.ReadStatement:
+		targets = s.Targets
+	case *parser.GetStatement:
+		targets = s.Targets
+	case *parser.InputFileStatement:
+		targets = s.Targets
+	case *parser.GetFileStatement:
+		targets = s.Targets
+	case *parser.SwapStatement:
+		targets = []parser.ReadTarget{s.Left, s.Right}
+	}
+	var list []string
+	for _, t := range targets {
+		if len(t.Indices) == 0 {
+			list = append(list, t.Name)
+		}
+	}
+	return list
+}
+
+// collisions reports different names that C64 BASIC treats as one variable or one array, where the second appears
+func (a *analysis) collisions() {
+	first := map[string]string{} // Significant name, with "(" for arrays, to the first name seen
+	reported := map[string]bool{}
+	where := ""
+	if a.dialect != parser.DialectC64 {
+		where = " in the c64 dialect"
+	}
+	see := func(pos int, name string, array bool) {
+		if interpreter.IsReservedVariable(name, parser.DialectC64) {
+			return
+		}
+		key, kind := interpreter.VariableName(name, parser.DialectC64), "variable"
+		if array {
+			key, kind = key+"(", "array"
+		}
+		seen, ok := first[key]
+		switch {
+		case !ok:
+			first[key] = name
+		case seen != name && !reported[key+" "+name]:
+			reported[key+" "+name] = true
+			a.report(pos, NameCollision, "%s and %s are the same %s%s: only the first two characters of a name count", seen, name, kind, where)
+		}
+	}
+	for pos, line := range a.lines {
+		eachStatement(line.Statements, func(stmt parser.Statement) {
+			for _, e := range expressions(stmt) {
+				names(e, func(name string, array bool) { see(pos, name, array) })
+			}
+			for _, name := range assignedNames(stmt) {
+				see(pos, name, false)
+			}
+			switch s := stmt.(type) {
+			case *parser.ArraySetStatement:
+				see(pos, s.Name, true)
+			case *parser.DimStatement:
+				for _, d := range s.Declarations {
+					see(pos, d.Name, true)
+				}
+			}
+			for _, t := range readTargets(stmt) {
+				if len(t.Indices) > 0 {
+					see(pos, t.Name, true)
+				}
+			}
+		})
+	}
+}
+
+// significant returns the name a variable is stored under in the dialect being checked
+func (a *analysis) significant(name string) string {
+	return interpreter.VariableName(name, a.dialect)
+}
+
+// eachStatement calls fn for each statement, including those in IF branches
+func eachStatement(stmts []parser.Statement, fn func(parser.Statement)) {
+	for _, stmt := range stmts {
+		parser.Inspect(stmt, func(node parser.Node) bool {
+			nested, ok := node.(parser.Statement)
+			if ok {
+				fn(nested)
+			}
+			return ok // Expressions hold no statements
+		})
+	}
+}
+
+// readTargets returns the variables and array elements INPUT, READ, GET, their file forms and SWAP fill
+func readTargets(stmt parser.Statement) []parser.ReadTarget {
+	switch s := stmt.(type) {
+	case *parser.InputStatement:
+		return s.Targets
+	case *parser.ReadStatement:
+		return s.Targets
+	case *parser.GetStatement:
+		return s.Targets
+	case *parser.InputFileStatement:
+		return s.Targets
+	case *parser.GetFileStatement:
+		return s.Targets
+	case *parser.SwapStatement:
+		return []parser.ReadTarget{s.Left, s.Right}
+	}
+	return nil
+}
+
+// expressions returns the expressions a statement evaluates itself, not those of nested statements or a DEF FN body
+func expressions(stmt parser.Statement) []parser.Expression {
+	var exprs []parser.Expression
+	switch s := stmt.(type) {
+	case *parser.PrintStatement:
+		if s.At != nil {
+			exprs = append(exprs, s.At.Row, s.At.Column)
+		}
+		exprs = append(exprs, s.Expression)
+		exprs = append(exprs, s.Items...)
+	case *parser.LetStatement:
+		exprs = append(exprs, s.Expression)
+	case *parser.ArraySetStatement:
+		exprs = append(exprs, s.Indexes...)
+		exprs = append(exprs, s.Expression)
+	case *parser.ConstStatement:
+		exprs = append(exprs, s.Expression)
+	case *parser.ForStatement:
+		exprs = append(exprs, s.StartValue, s.EndValue, s.StepValue)
+	case *parser.IfStatement:
+		exprs = append(exprs, s.Condition)
+	case *parser.WhileStatement:
+		exprs = append(exprs, s.Condition)
+	case *parser.LoopStatement:
+		exprs = append(exprs, s.Condition)
+	case *parser.OnGotoStatement:
+		exprs = append(exprs, s.Selector)
+	case *parser.OnGosubStatement:
+		exprs = append(exprs, s.Selector)
+	case *parser.LocateStatement:
+		exprs = append(exprs, s.Row, s.Column)
+	case *parser.PokeStatement:
+		exprs = append(exprs, s.Address, s.Value)
+	case *parser.TimerStatement:
+		exprs = append(exprs, s.Ticks, s.Timer)
+	case *parser.DimStatement:
+		for _, d := range s.Declarations {
+			exprs = append(exprs, d.Sizes...)
+		}
+	case *parser.HostStatement:
+		exprs = append(exprs, s.Arguments...)
+	case *parser.OpenStatement:
+		exprs = append(exprs, s.File, s.Device, s.Channel, s.Name)
+	case *parser.CloseStatement:
+		exprs = append(exprs, s.File)
+	case *parser.PrintFileStatement:
+		exprs = append(exprs, s.File)
+		exprs = append(exprs, s.Items...)
+	case *parser.InputFileStatement:
+		exprs = append(exprs, s.File)
+	case *parser.GetFileStatement:
+		exprs = append(exprs, s.File)
+	}
+	for _, t := range readTargets(stmt) {
+		exprs = append(exprs, t.Indices...)
+	}
+	return exprs
+}
+
+// names calls fn for each variable and array named in an expression
+func names(expr parser.Expression, fn func(name string, array bool)) {
+	if expr == nil {
+		return
+	}
+	parser.Inspect(expr, func(node parser.Node) bool {
+		switch e := node.(type) {
+		case *parser.VariableReference:
+			fn(e.Name, false)
+		case *parser.ArrayReference:
+			fn(e.Name, true)
+		}
+		return true
+	})
+}
+
+// variables calls fn for each simple variable an expression reads, leaving out reserved variables such as TI and ST
+func (a *analysis) variables(expr parser.Expression, fn func(name string)) {
+	names(expr, func(name string, array bool) {
+		if !array && !interpreter.IsReservedVariable(name, a.dialect) {
+			fn(name)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package vet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/lexer"
+	"basic-interpreter/parser"
+)
+
+func check(t *testing.T, src string, dialect parser.Dialect) []Finding {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	p.SetShims(true)
+	program := p.ParseProgram()
+	require.Nil(t, p.ParseError())
+	return Program(program, dialect)
+}
+
+func TestProgram(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		dialect parser.Dialect
+		want    []Finding
+	}{
+		{
+			name: "clean program",
+			src:  "10 FOR I=1 TO 3: GOSUB 100: NEXT I\n20 INPUT N$: IF N$=\"\" THEN 10\n30 END\n100 PRINT I: RETURN\n200 DATA 1\n210 REM NOTES",
+		},
+		{
+			name: "undefined targets",
+			src:  "10 GOSUB 500: ON X GOTO 10,600\n20 IF X THEN 700\n30 RUN 800",
+			want: []Finding{
+				{10, UndefinedTarget, "GOSUB 500: there is no line 500"},
+				{10, UndefinedTarget, "ON...GOTO 600: there is no line 600"},
+				{10, ReadBeforeWrite, "X is read before anything is assigned to it, so it is 0"},
+				{20, UndefinedTarget, "GOTO 700: there is no line 700"},
+				{30, UndefinedTarget, "RUN 800: there is no line 800"},
+			},
+		},
+		{
+			name: "unreachable after END and GOTO",
+			src:  "10 GOTO 40\n20 PRINT 1\n25 REM SKIPPED\n30 PRINT 2\n40 END\n50 PRINT 3",
+			want: []Finding{
+				{20, Unreachable, "lines 20-30 can never run"},
+				{50, Unreachable, "line 50 can never run"},
+			},
+		},
+		{
+			name: "if falls through unless both branches jump",
+			src:  "10 IF A=0 THEN END\n20 A=1: IF A THEN 40 ELSE END\n30 PRINT \"NEVER\"\n40 END",
+			want: []Finding{
+				{10, ReadBeforeWrite, "A is read before anything is assigned to it, so it is 0"},
+				{30, Unreachable, "line 30 can never run"},
+			},
+		},
+		{
+			name: "endless loop",
+			src:  "10 DO: PRINT 1: LOOP\n20 PRINT 2",
+			want: []Finding{{20, Unreachable, "line 20 can never run"}},
+		},
+		{
+			name: "next without for",
+			src:  "10 NEXT\n20 FOR I=1 TO 2: NEXT J\n30 NEXT I",
+			want: []Finding{
+				{10, NextWithoutFor, "NEXT has no FOR before it"},
+				{20, NextWithoutFor, "NEXT J has no FOR J before it"},
+			},
+		},
+		{
+			name: "return without gosub",
+			src:  "10 GOSUB 100\n20 END\n30 RETURN\n100 RETURN",
+			want: []Finding{
+				{30, Unreachable, "line 30 can never run"},
+				{30, StrayReturn, "RETURN is not reached from any GOSUB"},
+			},
+		},
+		{
+			name: "timer handlers are subroutines",
+			src:  "10 EVERY 50 GOSUB 100\n20 GOTO 20\n100 RETURN",
+		},
+		{
+			name: "read before write",
+			src:  "10 PRINT A$;B\n20 B=1: PRINT B;LEN(A$)\n30 PRINT C(1);TI",
+			want: []Finding{{10, ReadBeforeWrite, "A$ is read before anything is assigned to it, so it is \"\""}, {10, ReadBeforeWrite, "B is read before anything is assigned to it, so it is 0"}},
+		},
+		{
+			name: "assigned on some path or in a subroutine",
+			src:  "10 IF X THEN 30\n20 Y=1\n30 PRINT Y: GOSUB 100: PRINT Z\n40 FOR I=1 TO 2: IF I=2 THEN PRINT W\n50 W=I: NEXT I: END\n100 Z=1: RETURN",
+			want: []Finding{{10, ReadBeforeWrite, "X is read before anything is assigned to it, so it is 0"}},
+		},
+		{
+			name:    "name collisions",
+			src:     "10 LONGNAME=1: LO=2: SCORE$=\"\": SC$=\"X\"\n20 DIM TABLE(3): TA(1)=TABLE(2)+LONGNAME+LONGER",
+			dialect: parser.DialectC64,
+			want: []Finding{
+				{10, NameCollision, "LONGNAME and LO are the same variable: only the first two characters of a name count"},
+				{10, NameCollision, "SCORE$ and SC$ are the same variable: only the first two characters of a name count"},
+				{20, NameCollision, "LONGNAME and LONGER are the same variable: only the first two characters of a name count"},
+				{20, NameCollision, "TABLE and TA are the same array: only the first two characters of a name count"},
+			},
+		},
+		{
+			name: "name collisions when switching to the c64 dialect",
+			src:  "10 SCORE=1: SCALE=2: TITLE$=\"X\": TIME=3\n20 PRINT SCORE;SCALE;TITLE$;TIME",
+			want: []Finding{
+				{10, NameCollision, "SCORE and SCALE are the same variable in the c64 dialect: only the first two characters of a name count"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialect := tt.dialect
+			if dialect == "" {
+				dialect = parser.DialectExtended
+			}
+			assert.Equal(t, tt.want, check(t, tt.src, dialect))
+		})
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	f := Finding{Line: 30, Check: StrayReturn, Message: "RETURN is not reached from any GOSUB"}
+	assert.Equal(t, "line 30: RETURN is not reached from any GOSUB (stray-return)", f.String())
+}
@@ -0,0 +1,452 @@
+// ABOUTME: Compiler lowering a parsed program to flat bytecode with resolved jump targets and slot-indexed variables
+// ABOUTME: Control flow and expressions become VM instructions; statements with side effects run through the interpreter
+
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/sourcemap"
+	"basic-interpreter/types"
+)
+
+// opcode identifies a VM instruction
+type opcode uint8
+
+const (
+	opStatement    opcode = iota // Start of a statement on line arg: counts a step
+	opConst                      // Push consts[arg]
+	opLoad                       // Push slot arg; n is 1 for a string variable, which reads "" before it is set
+	opStore                      // Pop into slot arg; n is 1 for a string variable
+	opLoadNamed                  // Push the interpreter's variable names[arg] (TI, TI$, TIMER)
+	opStoreNamed                 // Pop into the interpreter's variable names[arg]
+	opLoadElement                // Pop n indexes and push element of array names[arg]
+	opStoreElement               // Pop a value and n indexes and store it in array names[arg]
+	opAdd
+	opSubtract
+	opMultiply
+	opDivide
+	opPower
+	opAnd
+	opOr
+	opCompare   // Pop two values and push 1 or 0 for comparison operator names[arg]
+	opNegate    // Unary minus
+	opPlus      // Unary plus
+	opNot       // Bitwise NOT
+	opCall      // Pop n arguments and push the result of function names[arg]
+	opJump      // Continue at arg
+	opJumpFalse // Pop a condition and continue at arg when it is false
+	opGosub     // Continue at arg, returning to n
+	opReturn    // Continue after the innermost GOSUB
+	opOnGoto    // Pop a selector and jump through tables[arg]; out of range falls through
+	opOnGosub   // Pop a selector and call through tables[arg], returning to n
+	opFor       // Pop step, end and start, set slot arg and open a loop whose body starts at n
+	opNext      // Step the loop of slot arg (-1 for the innermost) and jump back to its body
+	opExec      // Run statements[arg] through the interpreter
+	opFail      // Stop with errs[arg]
+	opEnd       // Stop the program
+)
+
+// instruction is one VM instruction; the meaning of arg and n depends on op
+type instruction struct {
+	op  opcode
+	arg int
+	n   int
+}
+
+// target is an entry of an ON jump table
+type target struct {
+	pc   int
+	line int
+	ok   bool // False when the line does not exist
+}
+
+// Program is a compiled BASIC program
+type Program struct {
+	code       []instruction
+	consts     []types.Value
+	names      []string           // Variable, array, function and operator names used by instructions
+	slots      []string           // Normalized variable name of each slot
+	statements []parser.Statement // Statements run through the interpreter
+	tables     [][]target
+	errs       []error
+	source     *parser.Program
+	lines      *sourcemap.Map // Instruction offsets to BASIC statements
+	dialect    parser.Dialect // Dialect deciding which characters of a name count
+}
+
+// SourceMap maps instruction offsets to the BASIC statements they implement
+func (p *Program) SourceMap() *sourcemap.Map {
+	return p.lines
+}
+
+// UnsupportedError reports a program using a statement the VM cannot run; run it on the tree walker instead
+type UnsupportedError struct {
+	Line int
+	What string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("line %d: %s is not supported by the VM", e.Line, e.What)
+}
+
+// compiler holds the state of one compilation
+type compiler struct {
+	prog       *Program
+	slotOf     map[string]int
+	nameOf     map[string]int
+	lineLabels map[int]int // Line number to the index of its first instruction, once emitted
+	lineIndex  map[int]int // Line number to its position in the program
+	fixups     []fixup
+	line       int
+	position   int // Statement position within the line, counted as the interpreter lays IF branches out
+}
+
+// fixup is a jump whose target line is resolved once all lines are emitted
+type fixup struct {
+	pc   int
+	line int
+}
+
+// Compile lowers program to bytecode. Programs using statements the VM does not run, such as WHILE, DO,
+// RUN, STOP, CONST or EVERY, fail with an *UnsupportedError. Variables are named as the dialect names them; the
+// machine running the program switches its interpreter to that dialect.
+func Compile(program *parser.Program, dialect parser.Dialect) (*Program, error) {
+	c := &compiler{
+		prog:       &Program{source: program, lines: sourcemap.New(), dialect: dialect},
+		slotOf:     make(map[string]int),
+		nameOf:     make(map[string]int),
+		lineLabels: make(map[int]int),
+		lineIndex:  make(map[int]int),
+	}
+	for idx, line := range program.Lines {
+		c.lineIndex[line.Number] = idx
+	}
+	for _, line := range program.Lines {
+		c.line = line.Number
+		c.position = 0
+		c.lineLabels[line.Number] = len(c.prog.code)
+		if err := c.statements(line.Statements); err != nil {
+			return nil, err
+		}
+	}
+	c.emit(opEnd, 0, 0)
+
+	for _, f := range c.fixups {
+		c.prog.code[f.pc].arg = c.lineLabels[c.threaded(f.line)]
+	}
+	for _, table := range c.prog.tables {
+		for n, t := range table {
+			if t.ok {
+				table[n].pc = c.lineLabels[c.threaded(t.line)]
+			}
+		}
+	}
+	return c.prog, nil
+}
+
+// statement compiles one statement
+func (c *compiler) statement(stmt parser.Statement) error {
+	switch s := stmt.(type) {
+	case *parser.RemStatement, *parser.DataStatement:
+		return nil
+	case *parser.EndStatement:
+		c.emit(opEnd, 0, 0)
+	case *parser.LetStatement:
+		if err := c.expression(s.Expression); err != nil {
+			return err
+		}
+		c.store(s.Variable)
+	case *parser.ArraySetStatement:
+		for _, idx := range s.Indexes {
+			if err := c.expression(idx); err != nil {
+				return err
+			}
+		}
+		if err := c.expression(s.Expression); err != nil {
+			return err
+		}
+		c.emit(opStoreElement, c.name(s.Name), len(s.Indexes))
+	case *parser.GotoStatement:
+		c.jump(opJump, s.TargetLine, 0)
+	case *parser.GosubStatement:
+		pc := c.jump(opGosub, s.TargetLine, 0)
+		c.prog.code[pc].n = pc + 1
+	case *parser.ReturnStatement:
+		c.emit(opReturn, 0, 0)
+	case *parser.OnGotoStatement:
+		if err := c.expression(s.Selector); err != nil {
+			return err
+		}
+		c.emit(opOnGoto, c.table(s.TargetLines), 0)
+	case *parser.OnGosubStatement:
+		if err := c.expression(s.Selector); err != nil {
+			return err
+		}
+		pc := c.emit(opOnGosub, c.table(s.TargetLines), 0)
+		c.prog.code[pc].n = pc + 1
+	case *parser.IfStatement:
+		return c.ifStatement(s)
+	case *parser.ForStatement:
+		if interpreter.IsReservedVariable(s.Variable, c.prog.dialect) {
+			return &UnsupportedError{Line: c.line, What: "FOR on a reserved variable"}
+		}
+		for _, expr := range []parser.Expression{s.StartValue, s.EndValue} {
+			if err := c.expression(expr); err != nil {
+				return err
+			}
+		}
+		if s.StepValue != nil {
+			if err := c.expression(s.StepValue); err != nil {
+				return err
+			}
+		} else {
+			c.emit(opConst, c.constant(types.NewNumberValue(1)), 0)
+		}
+		pc := c.emit(opFor, c.slot(s.Variable), 0)
+		c.prog.code[pc].n = pc + 1
+	case *parser.NextStatement:
+		slot := -1
+		if s.Variable != "" {
+			slot = c.slot(s.Variable)
+		}
+		c.emit(opNext, slot, 0)
+	case *parser.PrintStatement, *parser.InputStatement, *parser.GetStatement, *parser.ReadStatement,
+		*parser.DimStatement, *parser.SwapStatement, *parser.PokeStatement, *parser.LocateStatement,
+		*parser.ClearScreenStatement, *parser.DefFnStatement, *parser.HostStatement:
+		c.prog.statements = append(c.prog.statements, stmt)
+		c.emit(opExec, len(c.prog.statements)-1, 0)
+	default:
+		return &UnsupportedError{Line: c.line, What: statementName(stmt)}
+	}
+	return nil
+}
+
+// ifStatement compiles IF with its branches inline, as the tree walker lays them out, so a GOSUB returns
+// and a NEXT loops back to the statement after them within the branch. A false condition continues at the
+// ELSE statements or the end of the line.
+func (c *compiler) ifStatement(s *parser.IfStatement) error {
+	if err := c.expression(s.Condition); err != nil {
+		return err
+	}
+	jumpFalse := c.emit(opJumpFalse, 0, 0)
+	if err := c.statements(s.ThenStmts); err != nil {
+		return err
+	}
+	if len(s.ElseStmts) == 0 {
+		c.prog.code[jumpFalse].arg = len(c.prog.code)
+		return nil
+	}
+	skip := c.emit(opJump, 0, 0)
+	c.position++ // The end of the THEN branch holds a position of its own
+	c.prog.code[jumpFalse].arg = len(c.prog.code)
+	if err := c.statements(s.ElseStmts); err != nil {
+		return err
+	}
+	c.prog.code[skip].arg = len(c.prog.code)
+	return nil
+}
+
+// statements compiles statements at the next positions of the line
+func (c *compiler) statements(stmts []parser.Statement) error {
+	for _, stmt := range stmts {
+		c.prog.lines.Add(c.emit(opStatement, 0, 0), c.line, c.position)
+		c.position++
+		if err := c.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expression compiles an expression leaving its value on the stack
+func (c *compiler) expression(expr parser.Expression) error {
+	switch e := expr.(type) {
+	case *parser.NumberLiteral:
+		value, err := types.ParseValue(e.Value)
+		if err != nil {
+			c.fail(err)
+			return nil
+		}
+		c.emit(opConst, c.constant(value), 0)
+	case *parser.StringLiteral:
+		c.emit(opConst, c.constant(types.NewStringValue(e.Value)), 0)
+	case *parser.Constant:
+		c.emit(opConst, c.constant(e.Value), 0)
+	case *parser.VariableReference:
+		if interpreter.IsReservedVariable(e.Name, c.prog.dialect) {
+			c.emit(opLoadNamed, c.name(e.Name), 0)
+			return nil
+		}
+		c.emit(opLoad, c.slot(e.Name), stringFlag(e.Name))
+	case *parser.ArrayReference:
+		for _, idx := range e.Indices {
+			if err := c.expression(idx); err != nil {
+				return err
+			}
+		}
+		c.emit(opLoadElement, c.name(e.Name), len(e.Indices))
+	case *parser.BinaryOperation:
+		op, ok := binaryOps[e.Operator]
+		if !ok {
+			return &UnsupportedError{Line: c.line, What: "operator " + e.Operator}
+		}
+		if err := c.operands(e.Left, e.Right); err != nil {
+			return err
+		}
+		c.emit(op, 0, 0)
+	case *parser.ComparisonExpression:
+		if err := c.operands(e.Left, e.Right); err != nil {
+			return err
+		}
+		c.emit(opCompare, c.name(e.Operator), 0)
+	case *parser.UnaryOperation:
+		op, ok := unaryOps[e.Operator]
+		if !ok {
+			return &UnsupportedError{Line: c.line, What: "operator " + e.Operator}
+		}
+		if err := c.expression(e.Right); err != nil {
+			return err
+		}
+		c.emit(op, 0, 0)
+	case *parser.FunctionCall:
+		for _, arg := range e.Arguments {
+			if err := c.expression(arg); err != nil {
+				return err
+			}
+		}
+		c.emit(opCall, c.name(e.FunctionName), len(e.Arguments))
+	default:
+		return &UnsupportedError{Line: c.line, What: fmt.Sprintf("expression %T", expr)}
+	}
+	return nil
+}
+
+// Operators compiled to their own instruction
+var (
+	binaryOps = map[string]opcode{"+": opAdd, "-": opSubtract, "*": opMultiply, "/": opDivide, "^": opPower, "AND": opAnd, "OR": opOr}
+	unaryOps  = map[string]opcode{"-": opNegate, "+": opPlus, "NOT": opNot}
+)
+
+// operands compiles the two sides of a binary expression, left first
+func (c *compiler) operands(left, right parser.Expression) error {
+	if err := c.expression(left); err != nil {
+		return err
+	}
+	return c.expression(right)
+}
+
+// store pops the top of the stack into a variable
+func (c *compiler) store(name string) {
+	if interpreter.IsReservedVariable(name, c.prog.dialect) {
+		c.emit(opStoreNamed, c.name(name), 0)
+		return
+	}
+	c.emit(opStore, c.slot(name), stringFlag(name))
+}
+
+// jump emits a jump to a line, resolved once every line has an address, and returns its index
+func (c *compiler) jump(op opcode, line, n int) int {
+	if _, ok := c.lineIndex[line]; !ok {
+		// Like the tree walker the missing line is only reported if the jump runs
+		return c.fail(fmt.Errorf("%w: NO LINE %d", interpreter.ErrUndefinedStatement, line))
+	}
+	pc := c.emit(op, 0, n)
+	c.fixups = append(c.fixups, fixup{pc: pc, line: line})
+	return pc
+}
+
+// threaded follows lines holding nothing but a GOTO to where the chain ends, so a jump into a GOTO chain
+// lands on its last line in one step; this is the only jump optimization, with no basic-block analysis.
+// Only compiled programs are threaded: traced, covered and debugged runs stay on the tree walker, which visits
+// every line of the chain.
+// Chains that loop are left alone so infinite loop protection sees every step.
+func (c *compiler) threaded(line int) int {
+	seen := map[int]bool{line: true}
+	current := line
+	for {
+		stmts := c.prog.source.Lines[c.lineIndex[current]].Statements
+		if len(stmts) != 1 {
+			return current
+		}
+		g, ok := stmts[0].(*parser.GotoStatement)
+		if !ok {
+			return current
+		}
+		if _, ok := c.lineIndex[g.TargetLine]; !ok {
+			return current
+		}
+		if seen[g.TargetLine] {
+			return line
+		}
+		seen[g.TargetLine] = true
+		current = g.TargetLine
+	}
+}
+
+// table adds an ON jump table and returns its index
+func (c *compiler) table(lines []int) int {
+	table := make([]target, len(lines))
+	for n, line := range lines {
+		_, ok := c.lineIndex[line]
+		table[n] = target{line: line, ok: ok}
+	}
+	c.prog.tables = append(c.prog.tables, table)
+	return len(c.prog.tables) - 1
+}
+
+// fail emits an instruction stopping with err and returns its index
+func (c *compiler) fail(err error) int {
+	c.prog.errs = append(c.prog.errs, err)
+	return c.emit(opFail, len(c.prog.errs)-1, 0)
+}
+
+// emit appends an instruction and returns its index
+func (c *compiler) emit(op opcode, arg, n int) int {
+	c.prog.code = append(c.prog.code, instruction{op: op, arg: arg, n: n})
+	return len(c.prog.code) - 1
+}
+
+// constant adds a constant and returns its index
+func (c *compiler) constant(value types.Value) int {
+	c.prog.consts = append(c.prog.consts, value)
+	return len(c.prog.consts) - 1
+}
+
+// name interns a name used by an instruction and returns its index
+func (c *compiler) name(name string) int {
+	if idx, ok := c.nameOf[name]; ok {
+		return idx
+	}
+	c.prog.names = append(c.prog.names, name)
+	c.nameOf[name] = len(c.prog.names) - 1
+	return len(c.prog.names) - 1
+}
+
+// slot returns the slot of a variable, shared by the names the dialect treats as one
+func (c *compiler) slot(name string) int {
+	norm := interpreter.VariableName(name, c.prog.dialect)
+	if idx, ok := c.slotOf[norm]; ok {
+		return idx
+	}
+	c.prog.slots = append(c.prog.slots, norm)
+	c.slotOf[norm] = len(c.prog.slots) - 1
+	return len(c.prog.slots) - 1
+}
+
+// stringFlag is 1 for a string variable name and 0 for a numeric one
+func stringFlag(name string) int {
+	if strings.HasSuffix(name, "$") {
+		return 1
+	}
+	return 0
+}
+
+// statementName names a statement type for an UnsupportedError, e.g. WHILE for *parser.WhileStatement
+func statementName(stmt parser.Statement) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*parser.")
+	return strings.ToUpper(strings.TrimSuffix(name, "Statement"))
+}
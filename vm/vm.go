@@ -0,0 +1,558 @@
+// ABOUTME: Bytecode VM running compiled programs: a stack machine with variables in slots and a flat instruction loop
+// ABOUTME: PRINT, INPUT, READ, DIM and other side effects run through an interpreter seeing the VM's variables
+
+package vm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/types"
+)
+
+// maxDepth bounds open FOR loops and GOSUB calls, like the tree walker
+const maxDepth = 100
+
+// forLoop is an open FOR loop
+type forLoop struct {
+	slot int
+	end  types.Value
+	step types.Value
+	body int // Instruction the loop jumps back to
+	line int // BASIC line of the FOR statement, for diagnostics
+}
+
+// Machine runs compiled programs. Arrays, DATA, DEF FN functions, output and input live in the interpreter
+// it was created with; simple variables live in the machine's slots.
+type Machine struct {
+	interp *interpreter.Interpreter
+	ops    *operations
+	prog   *Program
+	names  []string // Normalized variable name of each slot
+	slots  []types.Value
+	set    []bool // Slots assigned during the run
+	slotOf map[string]int
+	stack  []types.Value
+	fors   []forLoop
+	closed interpreter.ClosedLoops // Loops that left fors, explaining NEXT WITHOUT FOR like the tree walker
+	calls  []int
+	steps  int
+	pc     int // Instruction executing, or where the last run stopped
+}
+
+// New creates a machine whose statements run against interp, honouring its step limit
+func New(interp *interpreter.Interpreter) *Machine {
+	m := &Machine{interp: interp, pc: -1}
+	m.ops = &operations{Interpreter: interp, m: m}
+	return m
+}
+
+// Run executes a compiled program from its first line
+func (m *Machine) Run(prog *Program) error {
+	return m.RunContext(context.Background(), prog)
+}
+
+// RunContext executes a compiled program like Run, stopping it like the interpreter's ExecuteContext when ctx is
+// done or the interpreter's time limit passes
+func (m *Machine) RunContext(ctx context.Context, prog *Program) error {
+	return m.interp.Within(ctx, func(ctx context.Context) error { return m.run(ctx, prog) })
+}
+
+// run executes a compiled program until it ends, fails or ctx is done
+func (m *Machine) run(ctx context.Context, prog *Program) error {
+	m.prog = prog
+	m.interp.SetDialect(prog.dialect)
+	m.names = append([]string(nil), prog.slots...)
+	m.slots = make([]types.Value, len(prog.slots))
+	m.set = make([]bool, len(prog.slots))
+	m.slotOf = make(map[string]int, len(prog.slots))
+	for idx, name := range prog.slots {
+		m.slotOf[name] = idx
+	}
+	m.stack = m.stack[:0]
+	m.fors = m.fors[:0]
+	m.closed = interpreter.ClosedLoops{}
+	m.calls = m.calls[:0]
+	m.steps = 0
+	m.pc = 0
+	m.interp.Start(prog.source)
+
+	err := m.loop(ctx)
+	switch {
+	case err == nil:
+		return nil
+	case err == interpreter.ErrInfiniteLoop || err == interpreter.ErrCanceled || err == interpreter.ErrTimeLimit:
+		return interpreter.AtLine(err, interpreter.NoLine, m.currentStatement())
+	}
+	return interpreter.AtLine(err, m.CurrentLine(), m.currentStatement())
+}
+
+// Variables returns the simple variables set by the last run, by normalized name
+func (m *Machine) Variables() map[string]types.Value {
+	vars := m.interp.Variables()
+	for idx, name := range m.names {
+		if m.set[idx] {
+			vars[name] = m.slots[idx]
+		}
+	}
+	return vars
+}
+
+// Steps returns the number of statements executed by the last run
+func (m *Machine) Steps() int {
+	return m.steps
+}
+
+// CurrentLine returns the BASIC line being executed, or the line execution stopped on; -1 before a run
+func (m *Machine) CurrentLine() int {
+	if m.prog == nil {
+		return -1
+	}
+	entry, ok := m.prog.lines.Lookup(m.pc)
+	if !ok {
+		return -1
+	}
+	return entry.Line
+}
+
+// currentStatement returns the index within its line of the statement being executed, or -1 before a run
+func (m *Machine) currentStatement() int {
+	if m.prog == nil {
+		return -1
+	}
+	entry, ok := m.prog.lines.Lookup(m.pc)
+	if !ok {
+		return -1
+	}
+	return entry.Statement
+}
+
+// loop executes instructions until the program ends, fails or ctx is done, leaving m.pc at the last one
+func (m *Machine) loop(ctx context.Context) error {
+	code := m.prog.code
+	maxSteps := m.interp.MaxSteps()
+	done := ctx.Done()
+	interrupt := m.interp.Interrupt()
+	for pc := 0; ; {
+		m.pc = pc
+		in := code[pc]
+		pc++
+		switch in.op {
+		case opStatement:
+			if interrupt != nil {
+				select {
+				case <-interrupt:
+					return interpreter.CodeBreak
+				default:
+				}
+			}
+			m.steps++
+			if maxSteps > 0 && m.steps > maxSteps {
+				return interpreter.ErrInfiniteLoop
+			}
+			if done != nil {
+				select {
+				case <-done:
+					return interpreter.ContextError(ctx)
+				default:
+				}
+			}
+		case opConst:
+			m.push(m.prog.consts[in.arg])
+		case opLoad:
+			if m.set[in.arg] {
+				m.push(m.slots[in.arg])
+			} else if in.n == 1 {
+				m.push(types.NewStringValue(""))
+			} else {
+				m.push(types.NewNumberValue(0))
+			}
+		case opStore:
+			if err := m.store(in.arg, in.n == 1, m.pop()); err != nil {
+				return err
+			}
+		case opLoadNamed:
+			value, err := m.interp.GetVariable(m.prog.names[in.arg])
+			if err != nil {
+				return err
+			}
+			m.push(value)
+		case opStoreNamed:
+			if err := m.interp.SetVariable(m.prog.names[in.arg], m.pop()); err != nil {
+				return err
+			}
+		case opLoadElement:
+			idxs, err := m.indexes(in.n)
+			if err != nil {
+				return err
+			}
+			value, err := m.interp.GetArrayElement(m.prog.names[in.arg], idxs)
+			if err != nil {
+				return err
+			}
+			m.push(value)
+		case opStoreElement:
+			value := m.pop()
+			idxs, err := m.indexes(in.n)
+			if err != nil {
+				return err
+			}
+			if err := m.interp.SetArrayElement(m.prog.names[in.arg], idxs, value); err != nil {
+				return err
+			}
+		case opAdd, opSubtract, opMultiply, opDivide, opPower, opAnd, opOr:
+			right := m.pop()
+			result, err := arithmetic(in.op, m.pop(), right)
+			if err != nil {
+				return err
+			}
+			m.push(result)
+		case opCompare:
+			right := m.pop()
+			result, err := m.pop().Compare(right, m.prog.names[in.arg])
+			if err != nil {
+				return err
+			}
+			m.push(truth(result))
+		case opNegate, opPlus, opNot:
+			result, err := unary(in.op, m.pop())
+			if err != nil {
+				return err
+			}
+			m.push(result)
+		case opCall:
+			args := m.stack[len(m.stack)-in.n:]
+			result, err := m.ops.call(m.prog.names[in.arg], args)
+			if err != nil {
+				return err
+			}
+			m.stack = m.stack[:len(m.stack)-in.n]
+			m.push(result)
+		case opJump:
+			pc = in.arg
+		case opJumpFalse:
+			if !m.pop().IsTrue() {
+				pc = in.arg
+			}
+		case opGosub:
+			if len(m.calls) >= maxDepth {
+				return interpreter.ErrStackOverflow
+			}
+			m.calls = append(m.calls, in.n)
+			pc = in.arg
+		case opReturn:
+			if len(m.calls) == 0 {
+				return interpreter.ErrReturnWithoutGosub
+			}
+			pc = m.calls[len(m.calls)-1]
+			m.calls = m.calls[:len(m.calls)-1]
+		case opOnGoto, opOnGosub:
+			t, ok, err := m.selected(m.prog.tables[in.arg])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break // Out of range: no jump
+			}
+			if !t.ok {
+				return fmt.Errorf("%w: NO LINE %d", interpreter.ErrUndefinedStatement, t.line)
+			}
+			if in.op == opOnGosub {
+				if len(m.calls) >= maxDepth {
+					return interpreter.ErrStackOverflow
+				}
+				m.calls = append(m.calls, in.n)
+			}
+			pc = t.pc
+		case opFor:
+			if err := m.beginFor(in.arg, in.n); err != nil {
+				return err
+			}
+		case opNext:
+			body, err := m.next(in.arg)
+			if err != nil {
+				return err
+			}
+			if body >= 0 {
+				pc = body
+			}
+		case opExec:
+			if err := m.prog.statements[in.arg].Execute(m.ops); err != nil {
+				return err
+			}
+		case opFail:
+			return m.prog.errs[in.arg]
+		case opEnd:
+			return nil
+		}
+	}
+}
+
+// push pushes a value on the stack
+func (m *Machine) push(value types.Value) {
+	m.stack = append(m.stack, value)
+}
+
+// pop removes and returns the top of the stack
+func (m *Machine) pop() types.Value {
+	value := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return value
+}
+
+// store assigns a slot, checking that string variables get strings and numeric ones numbers, and rounds numbers to
+// the interpreter's precision
+func (m *Machine) store(slot int, isString bool, value types.Value) error {
+	if isString != (value.Type == types.StringType) {
+		return types.ErrTypeMismatch
+	}
+	if !isString {
+		value.Number = m.interp.Precision().Round(value.Number)
+	}
+	m.slots[slot] = value
+	m.set[slot] = true
+	return nil
+}
+
+// indexes pops n array indexes, which must be whole numbers of zero or more
+func (m *Machine) indexes(n int) ([]int, error) {
+	idxs := make([]int, n)
+	values := m.stack[len(m.stack)-n:]
+	m.stack = m.stack[:len(m.stack)-n]
+	for i, value := range values {
+		if value.Type != types.NumberType {
+			return nil, types.ErrTypeMismatch
+		}
+		if value.Number < 0 || float64(int(value.Number)) != value.Number {
+			return nil, fmt.Errorf("?ILLEGAL QUANTITY ERROR")
+		}
+		idxs[i] = int(value.Number)
+	}
+	return idxs, nil
+}
+
+// selected pops an ON selector and returns its table entry; false when it is out of range
+func (m *Machine) selected(table []target) (target, bool, error) {
+	selector := m.pop()
+	if selector.Type != types.NumberType {
+		return target{}, false, types.ErrTypeMismatch
+	}
+	idx := int(selector.Number)
+	if idx <= 0 || idx > len(table) {
+		return target{}, false, nil
+	}
+	return table[idx-1], true, nil
+}
+
+// beginFor pops step, end and start, sets the loop variable and opens the loop.
+// Like the tree walker a FOR on a variable whose loop is still open replaces that loop and those inside it.
+func (m *Machine) beginFor(slot, body int) error {
+	step, end, start := m.pop(), m.pop(), m.pop()
+	if step.Type != types.NumberType {
+		return fmt.Errorf("TYPE MISMATCH ERROR")
+	}
+	name := m.names[slot]
+	if err := m.store(slot, strings.HasSuffix(name, "$"), start); err != nil {
+		return err
+	}
+	if step.Number == 0 {
+		return fmt.Errorf("%w: FOR %s WITH STEP 0", interpreter.ErrIllegalQuantity, name)
+	}
+	for n := len(m.fors) - 1; n >= 0; n-- {
+		if m.fors[n].slot == slot {
+			m.fors = m.fors[:n]
+			break
+		}
+	}
+	if len(m.fors) >= maxDepth {
+		return fmt.Errorf("%w: TOO MANY OPEN FOR LOOPS AT FOR %s", interpreter.ErrStackOverflow, name)
+	}
+	m.fors = append(m.fors, forLoop{slot: slot, end: end, step: step, body: body, line: m.CurrentLine()})
+	m.closed.Open(name)
+	return nil
+}
+
+// next steps the loop of slot (-1 for the innermost), dropping loops opened inside it. It returns where
+// the loop body starts, or -1 when the loop is finished.
+func (m *Machine) next(slot int) (int, error) {
+	n := len(m.fors) - 1
+	if slot >= 0 {
+		for n >= 0 && m.fors[n].slot != slot {
+			n--
+		}
+	}
+	if n < 0 {
+		if slot < 0 {
+			return -1, m.closed.NextWithoutFor("")
+		}
+		return -1, m.closed.NextWithoutFor(m.names[slot])
+	}
+	for k := len(m.fors) - 1; k > n; k-- {
+		m.closed.Close(m.names[m.fors[k].slot], m.fors[k].line, m.names[m.fors[n].slot], m.CurrentLine())
+	}
+	m.fors = m.fors[:n+1]
+	loop := m.fors[n]
+
+	current := m.slots[loop.slot]
+	value, err := current.Add(loop.step)
+	if err != nil {
+		return -1, err
+	}
+	value.Number = m.interp.Precision().Round(value.Number) // Compared as stored, as the C64 does
+	cmp := "<="
+	if loop.step.Number < 0 {
+		cmp = ">="
+	}
+	more, err := value.Compare(loop.end, cmp)
+	if err != nil {
+		return -1, err
+	}
+	// The variable keeps the stepped value even when the loop ends
+	m.slots[loop.slot] = value
+	if more {
+		return loop.body, nil
+	}
+	m.closed.Close(m.names[loop.slot], loop.line, "", m.CurrentLine())
+	m.fors = m.fors[:n]
+	return -1, nil
+}
+
+// arithmetic applies a binary operator
+func arithmetic(op opcode, left, right types.Value) (types.Value, error) {
+	switch op {
+	case opAdd:
+		return left.Add(right)
+	case opSubtract:
+		return left.Subtract(right)
+	case opMultiply:
+		return left.Multiply(right)
+	case opDivide:
+		return left.Divide(right)
+	case opPower:
+		return left.Power(right)
+	}
+	ln, err := left.ToNumber()
+	if err != nil {
+		return types.Value{}, err
+	}
+	rn, err := right.ToNumber()
+	if err != nil {
+		return types.Value{}, err
+	}
+	if op == opAnd {
+		return types.NewNumberValue(float64(int(ln) & int(rn))), nil
+	}
+	return types.NewNumberValue(float64(int(ln) | int(rn))), nil
+}
+
+// unary applies a unary operator
+func unary(op opcode, operand types.Value) (types.Value, error) {
+	if operand.Type != types.NumberType {
+		switch op {
+		case opNegate:
+			return types.Value{}, fmt.Errorf("cannot negate non-numeric value")
+		case opPlus:
+			return types.Value{}, fmt.Errorf("cannot apply unary plus to non-numeric value")
+		}
+		return types.Value{}, types.ErrTypeMismatch
+	}
+	switch op {
+	case opNegate:
+		return types.NewNumberValue(-operand.Number), nil
+	case opNot:
+		return types.NewNumberValue(float64(^int(operand.Number))), nil
+	}
+	return operand, nil
+}
+
+// truth converts a comparison result to BASIC's 1 or 0
+func truth(b bool) types.Value {
+	if b {
+		return types.NewNumberValue(1)
+	}
+	return types.NewNumberValue(0)
+}
+
+// operations lets statements the VM hands to the interpreter see the machine's variables
+type operations struct {
+	*interpreter.Interpreter
+	m *Machine
+}
+
+// GetVariable reads a slot; reserved variables and names the program never assigns go to the interpreter
+func (o *operations) GetVariable(name string) (types.Value, error) {
+	if slot, ok := o.m.slotOf[o.NormalizeVariableName(name)]; ok && !interpreter.IsReservedVariable(name, o.Dialect()) {
+		if o.m.set[slot] {
+			return o.m.slots[slot], nil
+		}
+	}
+	return o.Interpreter.GetVariable(name)
+}
+
+// SetVariable assigns a slot, adding one for names first assigned outside compiled code (INPUT, READ)
+func (o *operations) SetVariable(name string, value types.Value) error {
+	if interpreter.IsReservedVariable(name, o.Dialect()) {
+		return o.Interpreter.SetVariable(name, value)
+	}
+	norm := o.NormalizeVariableName(name)
+	slot, ok := o.m.slotOf[norm]
+	if !ok {
+		o.m.names = append(o.m.names, norm)
+		o.m.slots = append(o.m.slots, types.Value{})
+		o.m.set = append(o.m.set, false)
+		slot = len(o.m.slots) - 1
+		o.m.slotOf[norm] = slot
+	}
+	return o.m.store(slot, strings.HasSuffix(name, "$"), value)
+}
+
+// EvaluateFunction evaluates arguments against the machine's variables before calling the function
+func (o *operations) EvaluateFunction(functionName string, args []parser.Expression) (types.Value, error) {
+	values := make([]types.Value, len(args))
+	for idx, arg := range args {
+		value, err := arg.Evaluate(o)
+		if err != nil {
+			return types.Value{}, err
+		}
+		values[idx] = value
+	}
+	return o.call(functionName, values)
+}
+
+// call calls a built-in or DEF FN function with evaluated arguments
+func (o *operations) call(functionName string, args []types.Value) (types.Value, error) {
+	if !strings.HasPrefix(strings.ToUpper(functionName), "FN") {
+		return o.CallFunction(functionName, args)
+	}
+	fn, ok := o.LookupFunction(functionName)
+	if !ok {
+		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: undefined function %s", functionName)
+	}
+	if len(args) != 1 {
+		return types.Value{}, fmt.Errorf("?SYNTAX ERROR: %s expects 1 argument", functionName)
+	}
+	// Bind the parameter for the body and restore it afterwards, as the tree walker does
+	norm := o.NormalizeVariableName(fn.Param)
+	slot, hadSlot := o.m.slotOf[norm]
+	var saved types.Value
+	wasSet := hadSlot && o.m.set[slot]
+	if wasSet {
+		saved = o.m.slots[slot]
+	}
+	if err := o.SetVariable(fn.Param, args[0]); err != nil {
+		return types.Value{}, err
+	}
+	slot = o.m.slotOf[norm]
+	result, err := fn.Body.Evaluate(o)
+	o.m.slots[slot], o.m.set[slot] = saved, wasSet
+	if err != nil {
+		return types.Value{}, err
+	}
+	if result.Type != types.NumberType {
+		return types.Value{}, types.ErrTypeMismatch
+	}
+	return result, nil
+}
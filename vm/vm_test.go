@@ -0,0 +1,296 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"basic-interpreter/interpreter"
+	"basic-interpreter/parser"
+	"basic-interpreter/parser/parsertest"
+	"basic-interpreter/runtime"
+	"basic-interpreter/types"
+)
+
+// outcome is what a run leaves behind, compared between the engines
+type outcome struct {
+	output    string
+	variables map[string]types.Value
+	steps     int
+	err       string
+}
+
+func runTree(t testing.TB, src string, inputs []string) outcome {
+	t.Helper()
+	rt := runtime.NewTestRuntime()
+	rt.SetInput(inputs)
+	interp := interpreter.NewInterpreter(rt)
+	err := interp.Execute(parsertest.Parse(t, src))
+	return finish(rt, interp.Variables(), interp.Steps(), err)
+}
+
+func runVM(t testing.TB, src string, inputs []string) outcome {
+	t.Helper()
+	compiled, err := Compile(parsertest.Parse(t, src), parser.DialectExtended)
+	require.NoError(t, err)
+	rt := runtime.NewTestRuntime()
+	rt.SetInput(inputs)
+	m := New(interpreter.NewInterpreter(rt))
+	err = m.Run(compiled)
+	return finish(rt, m.Variables(), m.Steps(), err)
+}
+
+func finish(rt *runtime.TestRuntime, variables map[string]types.Value, steps int, err error) outcome {
+	o := outcome{output: strings.Join(rt.GetOutput(), ""), variables: variables, steps: steps}
+	if err != nil {
+		o.err = err.Error()
+	}
+	return o
+}
+
+func TestVMMatchesTreeWalker(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		inputs []string
+	}{
+		{"arithmetic", `10 A=2+3*4-1: B=A/2^2: C=-A: D=NOT 0: E=6 AND 3 OR 8
+20 PRINT A;B;C;D;E`, nil},
+		{"strings", `10 A$="HELLO": B$=A$+" WORLD"
+20 PRINT LEFT$(B$,5);LEN(B$);MID$(B$,7,3);CHR$(65);ASC("A")`, nil},
+		{"comparisons", `10 PRINT 1<2;2<1;"A"<"B";3=3;3<>3;2>=2;1<=0`, nil},
+		{"for loop", `10 FOR I=1 TO 10 STEP 3: S=S+I: NEXT I
+20 PRINT S;I`, nil},
+		{"negative step", `10 FOR I=10 TO 1 STEP -2: PRINT I;: NEXT: PRINT`, nil},
+		{"nested loops", `10 FOR I=1 TO 3: FOR J=1 TO I: C=C+1: NEXT J: NEXT I
+20 PRINT C`, nil},
+		{"next unwinds inner loops", `10 FOR I=1 TO 2: FOR J=1 TO 5: NEXT I
+20 PRINT I;J`, nil},
+		{"for reenters stale loop", `10 N=N+1: FOR I=1 TO 3
+20 IF N<3 THEN 10
+30 NEXT: PRINT N;I`, nil},
+		{"step zero", `10 FOR I=1 TO 3 STEP 0`, nil},
+		{"next without for", `10 NEXT`, nil},
+		{"next for loop closed by outer next", `10 FOR I=1 TO 2
+20 FOR J=1 TO 2
+30 NEXT I
+40 NEXT J`, nil},
+		{"next after loop finished", `10 FOR I=1 TO 2: NEXT I
+20 NEXT I`, nil},
+		{"bare next after loop finished", `10 FOR K=1 TO 2
+20 NEXT
+30 NEXT`, nil},
+		{"goto", `10 GOTO 30
+20 PRINT "SKIPPED"
+30 PRINT "DONE"`, nil},
+		{"gosub and return", `10 GOSUB 100: PRINT "BACK": END
+100 PRINT "SUB": RETURN`, nil},
+		{"gosub inside if returns into the branch", `10 X=1
+20 IF X THEN GOSUB 100: PRINT "A"
+30 PRINT "B"
+40 END
+100 PRINT "SUB": RETURN`, nil},
+		{"for inside if loops within the branch", `10 IF 1 THEN FOR I=1 TO 3: PRINT "X";
+20 PRINT I;: NEXT`, nil},
+		{"gosubs inside nested if and else", `10 FOR I=1 TO 2: IF I THEN IF I=1 THEN ON I GOSUB 100: PRINT "BACK" ELSE GOSUB 100: PRINT "ELSE"
+20 NEXT I: END
+100 PRINT "SUB";I: RETURN`, nil},
+		{"next inside if", `10 IF 1 THEN FOR I=1 TO 3: PRINT I: NEXT I
+20 PRINT "END"`, nil},
+		{"loops inside else", `10 X=0
+20 IF X THEN PRINT "NO" ELSE FOR I=1 TO 2: GOSUB 100: NEXT: PRINT "DONE"
+30 END
+100 PRINT "SUB";I: RETURN`, nil},
+		{"if else falls through", `10 IF 0 THEN PRINT "A" ELSE PRINT "B"
+20 PRINT "C"`, nil},
+		{"on goto and gosub", `10 FOR K=0 TO 3: ON K GOSUB 100,200: ON K GOTO 50,50: PRINT "OUT";K
+50 NEXT: END
+100 PRINT "ONE": RETURN
+200 PRINT "TWO": RETURN`, nil},
+		{"undefined line", `10 PRINT "A": GOTO 999`, nil},
+		{"return without gosub", `10 RETURN`, nil},
+		{"type mismatch", `10 A$=5`, nil},
+		{"division by zero", `10 PRINT 1/0`, nil},
+		{"arrays", `10 DIM A(3,3): FOR I=0 TO 3: A(I,I)=I*I: NEXT
+20 PRINT A(2,2)+A(3,3);B(5): B(5)=7: PRINT B(5)`, nil},
+		{"bad subscript", `10 DIM A(2): A(3)=1`, nil},
+		{"read data", `10 READ A,B$: PRINT A;B$: READ C
+20 DATA 5,"X"`, nil},
+		{"input", `10 INPUT "NAME";N$: INPUT A: PRINT N$;A*2`, []string{"BOB", "21"}},
+		{"swap", `10 A=1: B=2: SWAP A,B: PRINT A;B`, nil},
+		{"def fn", `10 DEF FNS(X)=X*X+Y: Y=1: X=5: PRINT FNS(3);X`, nil},
+		{"long names share a slot", `10 COUNT=1: COLOR=2: PRINT COUNT`, nil},
+		{"print zones and tab", `10 PRINT "A","B";TAB(15);"C": PRINT SPC(3);"D"`, nil},
+		{"infinite loop", `10 GOTO 20
+20 GOTO 10`, nil},
+		{"end stops", `10 PRINT "A": END: PRINT "B"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, runTree(t, tt.src, tt.inputs), runVM(t, tt.src, tt.inputs))
+		})
+	}
+}
+
+func TestVMThreadsGotoChains(t *testing.T) {
+	src := `10 GOTO 30
+20 PRINT "SKIPPED"
+30 GOTO 40
+40 GOTO 50
+50 PRINT "DONE"`
+	tree, compiled := runTree(t, src, nil), runVM(t, src, nil)
+	assert.Equal(t, "DONE\n", compiled.output)
+	assert.Equal(t, tree.output, compiled.output)
+	assert.Equal(t, 4, tree.steps)
+	assert.Equal(t, 2, compiled.steps)
+}
+
+func TestCompileRejectsUnsupportedStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"while", "10 WHILE 0\n20 WEND", "line 10: WHILE is not supported by the VM"},
+		{"do", "10 DO: LOOP", "line 10: DO is not supported by the VM"},
+		{"stop", "10 PRINT 1\n20 STOP", "line 20: STOP is not supported by the VM"},
+		{"timer", "10 EVERY 60 GOSUB 100\n100 RETURN", "line 10: TIMER is not supported by the VM"},
+		{"run", "10 RUN", "line 10: RUN is not supported by the VM"},
+		{"nested in if", "10 IF 1 THEN CLR", "line 10: CLR is not supported by the VM"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(parsertest.Parse(t, tt.src), parser.DialectExtended)
+			var unsupported *UnsupportedError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestCompileRecordsSourceMap(t *testing.T) {
+	compiled, err := Compile(parsertest.Parse(t, "10 A=1: B=2\n20 PRINT A+B"), parser.DialectExtended)
+	require.NoError(t, err)
+	var statements [][2]int
+	for _, e := range compiled.SourceMap().Entries() {
+		statements = append(statements, [2]int{e.Line, e.Statement})
+		assert.Equal(t, opStatement, compiled.code[e.Generated].op)
+	}
+	assert.Equal(t, [][2]int{{10, 0}, {10, 1}, {20, 0}}, statements)
+}
+
+func TestVMReadsClockVariables(t *testing.T) {
+	compiled, err := Compile(parsertest.Parse(t, `10 TI$="010203": A$=TI$: T=TI`), parser.DialectExtended)
+	require.NoError(t, err)
+	rt := runtime.NewDeterministicRuntime(1, "")
+	m := New(interpreter.NewInterpreter(rt))
+	require.NoError(t, m.Run(compiled))
+	vars := m.Variables()
+	assert.Equal(t, "010203", vars["A$"].String)
+	assert.NotContains(t, vars, "TI")
+}
+
+// loopProgram is a tight FOR loop of arithmetic, where the VM saves the most over the tree walker
+const loopProgram = `10 FOR I=1 TO 2000: S=S+I*2-1: NEXT I`
+
+func BenchmarkTreeWalker(b *testing.B) {
+	program := parsertest.Parse(b, loopProgram)
+	for b.Loop() {
+		interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+		interp.SetMaxSteps(0)
+		if err := interp.Execute(program); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVM(b *testing.B) {
+	compiled, err := Compile(parsertest.Parse(b, loopProgram), parser.DialectExtended)
+	require.NoError(b, err)
+	for b.Loop() {
+		interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+		interp.SetMaxSteps(0)
+		if err := New(interp).Run(compiled); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// spaghettiProgram hops through a long GOTO chain on every iteration, like listings built by patching lines
+func spaghettiProgram() string {
+	var sb strings.Builder
+	sb.WriteString("10 FOR I = 1 TO 200\n20 GOTO 1000\n30 NEXT I\n40 END\n")
+	for n := 1000; n < 1100; n += 10 {
+		fmt.Fprintf(&sb, "%d GOTO %d\n", n, n+10)
+	}
+	sb.WriteString("1100 GOTO 30\n")
+	return sb.String()
+}
+
+func BenchmarkTreeWalkerGotoChains(b *testing.B) {
+	program := parsertest.Parse(b, spaghettiProgram())
+	for b.Loop() {
+		interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+		interp.SetMaxSteps(0)
+		if err := interp.Execute(program); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVMGotoChains(b *testing.B) {
+	compiled, err := Compile(parsertest.Parse(b, spaghettiProgram()), parser.DialectExtended)
+	require.NoError(b, err)
+	for b.Loop() {
+		interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+		interp.SetMaxSteps(0)
+		if err := New(interp).Run(compiled); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRunContextStopsRun(t *testing.T) {
+	compiled, err := Compile(parsertest.Parse(t, "10 A=A+1: GOTO 10"), parser.DialectExtended)
+	require.NoError(t, err)
+	interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+	interp.SetMaxSteps(0)
+	m := New(interp)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, m.RunContext(ctx, compiled), interpreter.ErrCanceled)
+
+	interp.SetTimeLimit(10 * time.Millisecond)
+	assert.ErrorIs(t, m.Run(compiled), interpreter.ErrTimeLimit)
+	assert.Equal(t, 10, m.CurrentLine())
+}
+
+func TestRunStopsOnInterrupt(t *testing.T) {
+	compiled, err := Compile(parsertest.Parse(t, "10 A=A+1: GOTO 10"), parser.DialectExtended)
+	require.NoError(t, err)
+	interp := interpreter.NewInterpreter(runtime.NewTestRuntime())
+	interp.SetMaxSteps(0)
+	interrupt, stop := make(chan struct{}), make(chan struct{})
+	go func() {
+		// Interrupts sent before the run starts are dropped, so keep sending
+		for {
+			select {
+			case interrupt <- struct{}{}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	defer close(stop)
+	interp.SetInterrupt(interrupt)
+
+	err = New(interp).Run(compiled)
+	require.ErrorIs(t, err, interpreter.CodeBreak)
+	assert.EqualError(t, err, "BREAK IN 10")
+}